@@ -5,10 +5,20 @@ package api4
 
 import (
 	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
 )
 
 func (api *API) InitDataRetention() {
 	api.BaseRoutes.DataRetention.Handle("/policy", api.ApiSessionRequired(getPolicy)).Methods("GET")
+	api.BaseRoutes.DataRetention.Handle("/policies/teams", api.ApiSessionRequired(getTeamRetentionPolicies)).Methods("GET")
+	api.BaseRoutes.DataRetention.Handle("/policies/teams/{team_id:[A-Za-z0-9]+}", api.ApiSessionRequired(getTeamRetentionPolicy)).Methods("GET")
+	api.BaseRoutes.DataRetention.Handle("/policies/teams/{team_id:[A-Za-z0-9]+}", api.ApiSessionRequired(saveTeamRetentionPolicy)).Methods("PUT")
+	api.BaseRoutes.DataRetention.Handle("/policies/teams/{team_id:[A-Za-z0-9]+}", api.ApiSessionRequired(deleteTeamRetentionPolicy)).Methods("DELETE")
+	api.BaseRoutes.DataRetention.Handle("/policies/channels", api.ApiSessionRequired(getChannelRetentionPolicies)).Methods("GET")
+	api.BaseRoutes.DataRetention.Handle("/policies/channels/{channel_id:[A-Za-z0-9]+}", api.ApiSessionRequired(getChannelRetentionPolicy)).Methods("GET")
+	api.BaseRoutes.DataRetention.Handle("/policies/channels/{channel_id:[A-Za-z0-9]+}", api.ApiSessionRequired(saveChannelRetentionPolicy)).Methods("PUT")
+	api.BaseRoutes.DataRetention.Handle("/policies/channels/{channel_id:[A-Za-z0-9]+}", api.ApiSessionRequired(deleteChannelRetentionPolicy)).Methods("DELETE")
 }
 
 func getPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -22,3 +32,167 @@ func getPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	w.Write([]byte(policy.ToJson()))
 }
+
+func getTeamRetentionPolicies(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policies, err := c.App.GetTeamRetentionPoliciesPage(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.TeamRetentionPolicyListToJson(policies)))
+}
+
+func getTeamRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policy, err := c.App.GetTeamRetentionPolicy(c.Params.TeamId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(policy.ToJson()))
+}
+
+func saveTeamRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	policy := model.TeamRetentionPolicyFromJson(r.Body)
+	if policy == nil || policy.TeamId != c.Params.TeamId {
+		c.SetInvalidParam("policy")
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policy, err := c.App.SaveTeamRetentionPolicy(policy)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("team_id=" + policy.TeamId)
+	w.Write([]byte(policy.ToJson()))
+}
+
+func deleteTeamRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := c.App.DeleteTeamRetentionPolicy(c.Params.TeamId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("team_id=" + c.Params.TeamId)
+	ReturnStatusOK(w)
+}
+
+func getChannelRetentionPolicies(c *Context, w http.ResponseWriter, r *http.Request) {
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policies, err := c.App.GetChannelRetentionPoliciesPage(c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ChannelRetentionPolicyListToJson(policies)))
+}
+
+func getChannelRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policy, err := c.App.GetChannelRetentionPolicy(c.Params.ChannelId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(policy.ToJson()))
+}
+
+func saveChannelRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	policy := model.ChannelRetentionPolicyFromJson(r.Body)
+	if policy == nil || policy.ChannelId != c.Params.ChannelId {
+		c.SetInvalidParam("policy")
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	policy, err := c.App.SaveChannelRetentionPolicy(policy)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("channel_id=" + policy.ChannelId)
+	w.Write([]byte(policy.ToJson()))
+}
+
+func deleteChannelRetentionPolicy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionTo(c.App.Session, model.PERMISSION_MANAGE_SYSTEM) {
+		c.SetPermissionError(model.PERMISSION_MANAGE_SYSTEM)
+		return
+	}
+
+	if err := c.App.DeleteChannelRetentionPolicy(c.Params.ChannelId); err != nil {
+		c.Err = err
+		return
+	}
+
+	c.LogAudit("channel_id=" + c.Params.ChannelId)
+	ReturnStatusOK(w)
+}
@@ -5,6 +5,10 @@ package api4
 
 import (
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
 )
 
 func TestDataRetentionGetPolicy(t *testing.T) {
@@ -14,3 +18,81 @@ func TestDataRetentionGetPolicy(t *testing.T) {
 	_, resp := th.Client.GetDataRetentionPolicy()
 	CheckNotImplementedStatus(t, resp)
 }
+
+func TestTeamRetentionPolicies(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	policy := &model.TeamRetentionPolicy{TeamId: th.BasicTeam.Id, MessageRetentionDays: 30, FileRetentionDays: 10}
+
+	_, resp := Client.SaveTeamRetentionPolicy(policy)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.GetTeamRetentionPolicy(th.BasicTeam.Id)
+	CheckNotFoundStatus(t, resp)
+
+	saved, resp := th.SystemAdminClient.SaveTeamRetentionPolicy(policy)
+	CheckNoError(t, resp)
+	assert.Equal(t, 30, saved.MessageRetentionDays)
+
+	_, resp = th.SystemAdminClient.SaveTeamRetentionPolicy(&model.TeamRetentionPolicy{TeamId: model.NewId(), MessageRetentionDays: 1})
+	CheckNotFoundStatus(t, resp)
+
+	_, resp = th.SystemAdminClient.SaveTeamRetentionPolicy(&model.TeamRetentionPolicy{TeamId: th.BasicTeam.Id, MessageRetentionDays: -1})
+	CheckBadRequestStatus(t, resp)
+
+	received, resp := th.SystemAdminClient.GetTeamRetentionPolicy(th.BasicTeam.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, saved, received)
+
+	policies, resp := th.SystemAdminClient.GetTeamRetentionPolicies(0, 100)
+	CheckNoError(t, resp)
+	assert.Contains(t, policies, saved)
+
+	_, resp = Client.GetTeamRetentionPolicies(0, 100)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.DeleteTeamRetentionPolicy(th.BasicTeam.Id)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := th.SystemAdminClient.DeleteTeamRetentionPolicy(th.BasicTeam.Id)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	_, resp = th.SystemAdminClient.DeleteTeamRetentionPolicy(th.BasicTeam.Id)
+	CheckNotFoundStatus(t, resp)
+}
+
+func TestChannelRetentionPolicies(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	policy := &model.ChannelRetentionPolicy{ChannelId: th.BasicChannel.Id, MessageRetentionDays: 0, FileRetentionDays: 5}
+
+	_, resp := Client.SaveChannelRetentionPolicy(policy)
+	CheckForbiddenStatus(t, resp)
+
+	saved, resp := th.SystemAdminClient.SaveChannelRetentionPolicy(policy)
+	CheckNoError(t, resp)
+	assert.Equal(t, 5, saved.FileRetentionDays)
+
+	_, resp = th.SystemAdminClient.SaveChannelRetentionPolicy(&model.ChannelRetentionPolicy{ChannelId: model.NewId()})
+	CheckNotFoundStatus(t, resp)
+
+	received, resp := th.SystemAdminClient.GetChannelRetentionPolicy(th.BasicChannel.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, saved, received)
+
+	policies, resp := th.SystemAdminClient.GetChannelRetentionPolicies(0, 100)
+	CheckNoError(t, resp)
+	assert.Contains(t, policies, saved)
+
+	ok, resp := th.SystemAdminClient.DeleteChannelRetentionPolicy(th.BasicChannel.Id)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	_, resp = th.SystemAdminClient.GetChannelRetentionPolicy(th.BasicChannel.Id)
+	CheckNotFoundStatus(t, resp)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func (api *API) InitDraft() {
+	api.BaseRoutes.Drafts.Handle("", api.ApiSessionRequired(upsertDraft)).Methods("POST")
+	api.BaseRoutes.User.Handle("/teams/{team_id:[A-Za-z0-9]+}/drafts", api.ApiSessionRequired(getDraftsForUser)).Methods("GET")
+	api.BaseRoutes.ChannelForUser.Handle("/drafts", api.ApiSessionRequired(deleteDraft)).Methods("DELETE")
+	api.BaseRoutes.ChannelForUser.Handle("/drafts/{post_id:[A-Za-z0-9]+}", api.ApiSessionRequired(deleteDraft)).Methods("DELETE")
+}
+
+func upsertDraft(c *Context, w http.ResponseWriter, r *http.Request) {
+	draft := model.DraftFromJson(r.Body)
+	if draft == nil {
+		c.SetInvalidParam("draft")
+		return
+	}
+
+	draft.UserId = c.App.Session.UserId
+
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, draft.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	draft, err := c.App.UpsertDraft(draft)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(draft.ToJson()))
+}
+
+func getDraftsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(c.App.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	drafts, err := c.App.GetDraftsForUser(c.Params.UserId, c.Params.TeamId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.DraftListToJson(drafts)))
+}
+
+func deleteDraft(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireChannelId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := c.App.DeleteDraft(c.Params.UserId, c.Params.ChannelId, c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestDrafts(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	require.Nil(t, err)
+	WebSocketClient.Listen()
+
+	waitForDraftEvent := func(event string) *model.Draft {
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case resp := <-WebSocketClient.EventChannel:
+				if resp.Event == event {
+					return model.DraftFromJson(strings.NewReader(resp.Data["draft"].(string)))
+				}
+			case <-timeout:
+				require.Fail(t, "timed out waiting for "+event)
+				return nil
+			}
+		}
+	}
+
+	draft, resp := Client.UpsertDraft(&model.Draft{ChannelId: th.BasicChannel.Id, Message: "draft"})
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+	assert.Equal(t, th.BasicUser.Id, draft.UserId)
+	assert.Equal(t, "draft", waitForDraftEvent(model.WEBSOCKET_EVENT_DRAFT_CREATED).Message)
+
+	draft.Message = "edited"
+	draft, resp = Client.UpsertDraft(draft)
+	CheckNoError(t, resp)
+	assert.Equal(t, "edited", draft.Message)
+	assert.Equal(t, "edited", waitForDraftEvent(model.WEBSOCKET_EVENT_DRAFT_UPDATED).Message)
+
+	reply, resp := Client.UpsertDraft(&model.Draft{ChannelId: th.BasicChannel.Id, RootId: th.BasicPost.Id, Message: "reply"})
+	CheckNoError(t, resp)
+
+	_, resp = Client.UpsertDraft(&model.Draft{ChannelId: th.BasicChannel.Id, RootId: model.NewId(), Message: "reply"})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.UpsertDraft(&model.Draft{ChannelId: th.BasicPrivateChannel.Id})
+	CheckBadRequestStatus(t, resp)
+
+	drafts, resp := Client.GetDraftsForUser(th.BasicUser.Id, th.BasicTeam.Id)
+	CheckNoError(t, resp)
+	require.Len(t, drafts, 2)
+	assert.ElementsMatch(t, []string{draft.Message, reply.Message}, []string{drafts[0].Message, drafts[1].Message})
+
+	_, resp = Client.GetDraftsForUser(th.BasicUser2.Id, th.BasicTeam.Id)
+	CheckForbiddenStatus(t, resp)
+
+	t.Run("other users", func(t *testing.T) {
+		th.LoginBasic2()
+		defer th.LoginBasic()
+
+		drafts, resp := Client.GetDraftsForUser(th.BasicUser2.Id, th.BasicTeam.Id)
+		CheckNoError(t, resp)
+		assert.Empty(t, drafts)
+
+		_, resp = Client.DeleteDraft(th.BasicUser.Id, th.BasicChannel.Id, "")
+		CheckForbiddenStatus(t, resp)
+	})
+
+	ok, resp := Client.DeleteDraft(th.BasicUser.Id, th.BasicChannel.Id, th.BasicPost.Id)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+	deleted := waitForDraftEvent(model.WEBSOCKET_EVENT_DRAFT_DELETED)
+	assert.Equal(t, th.BasicPost.Id, deleted.RootId)
+
+	_, resp = Client.DeleteDraft(th.BasicUser.Id, th.BasicChannel.Id, th.BasicPost.Id)
+	CheckNotFoundStatus(t, resp)
+
+	t.Run("posting deletes the draft", func(t *testing.T) {
+		_, resp := Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, Message: "edited"})
+		CheckNoError(t, resp)
+
+		deleted := waitForDraftEvent(model.WEBSOCKET_EVENT_DRAFT_DELETED)
+		assert.Equal(t, th.BasicChannel.Id, deleted.ChannelId)
+		assert.Empty(t, deleted.RootId)
+
+		drafts, resp := Client.GetDraftsForUser(th.BasicUser.Id, th.BasicTeam.Id)
+		CheckNoError(t, resp)
+		assert.Empty(t, drafts)
+	})
+}
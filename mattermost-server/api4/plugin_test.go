@@ -4,9 +4,15 @@
 package api4
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -14,6 +20,7 @@ import (
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/utils/fileutils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPlugin(t *testing.T) {
@@ -178,3 +185,132 @@ func TestPlugin(t *testing.T) {
 	_, resp = th.SystemAdminClient.RemovePlugin("bad.id")
 	CheckBadRequestStatus(t, resp)
 }
+
+func TestMarketplacePlugins(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	manifest := `{"id": "testplugin", "version": "99.0.0", "webapp": {"bundle_path": "webapp/testplugin_bundle.js"}}`
+	require.Nil(t, tw.WriteHeader(&tar.Header{Name: "plugin.json", Mode: 0600, Size: int64(len(manifest))}))
+	_, err := tw.Write([]byte(manifest))
+	require.Nil(t, err)
+	require.Nil(t, tw.Close())
+	require.Nil(t, gz.Close())
+	bundle := buf.Bytes()
+
+	checksum := sha256.Sum256(bundle)
+
+	var index *model.MarketplaceIndex
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(index.ToJson()))
+		case "/testplugin.tar.gz":
+			w.Write(bundle)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	index = &model.MarketplaceIndex{
+		Plugins: []*model.MarketplacePlugin{
+			{Id: "testplugin", Version: "99.0.0", DownloadUrl: server.URL + "/testplugin.tar.gz", Checksum: hex.EncodeToString(checksum[:])},
+		},
+	}
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.EnableUploads = true
+		*cfg.PluginSettings.MarketplaceUrl = server.URL + "/index.json"
+		*cfg.ServiceSettings.EnableInsecureOutgoingConnections = true
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "127.0.0.1"
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		_, resp := th.Client.GetMarketplacePlugins()
+		CheckForbiddenStatus(t, resp)
+
+		_, resp = th.Client.GetMarketplacePluginUpgrades()
+		CheckForbiddenStatus(t, resp)
+
+		_, resp = th.Client.InstallMarketplacePlugin("testplugin", "")
+		CheckForbiddenStatus(t, resp)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		listed, resp := th.SystemAdminClient.GetMarketplacePlugins()
+		CheckNoError(t, resp)
+		require.Len(t, listed.Plugins, 1)
+		assert.Equal(t, "testplugin", listed.Plugins[0].Id)
+	})
+
+	t.Run("https only", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MarketplaceUrl = "http://" + server.Listener.Addr().String() + "/index.json"
+		})
+		defer th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MarketplaceUrl = server.URL + "/index.json"
+		})
+
+		_, resp := th.SystemAdminClient.GetMarketplacePlugins()
+		CheckBadRequestStatus(t, resp)
+		CheckErrorMessage(t, resp, "app.plugin.download.invalid_url.app_error")
+	})
+
+	t.Run("https only download", func(t *testing.T) {
+		index.Plugins[0].DownloadUrl = "http://" + server.Listener.Addr().String() + "/testplugin.tar.gz"
+		defer func() { index.Plugins[0].DownloadUrl = server.URL + "/testplugin.tar.gz" }()
+
+		_, resp := th.SystemAdminClient.InstallMarketplacePlugin("testplugin", "")
+		CheckBadRequestStatus(t, resp)
+		CheckErrorMessage(t, resp, "app.plugin.download.invalid_url.app_error")
+	})
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		index.Plugins[0].Checksum = "invalid"
+		defer func() { index.Plugins[0].Checksum = hex.EncodeToString(checksum[:]) }()
+
+		_, resp := th.SystemAdminClient.InstallMarketplacePlugin("testplugin", "")
+		CheckBadRequestStatus(t, resp)
+		CheckErrorMessage(t, resp, "app.plugin.marketplace.checksum.app_error")
+	})
+
+	t.Run("manifest mismatch", func(t *testing.T) {
+		index.Plugins = append(index.Plugins, &model.MarketplacePlugin{Id: "other", Version: "99.0.0", DownloadUrl: server.URL + "/testplugin.tar.gz", Checksum: hex.EncodeToString(checksum[:])})
+		index.Plugins = append(index.Plugins, &model.MarketplacePlugin{Id: "testplugin", Version: "98.0.0", DownloadUrl: server.URL + "/testplugin.tar.gz", Checksum: hex.EncodeToString(checksum[:])})
+		defer func() { index.Plugins = index.Plugins[:1] }()
+
+		_, resp := th.SystemAdminClient.InstallMarketplacePlugin("other", "")
+		CheckBadRequestStatus(t, resp)
+		CheckErrorMessage(t, resp, "app.plugin.marketplace.manifest_mismatch.app_error")
+
+		_, resp = th.SystemAdminClient.InstallMarketplacePlugin("testplugin", "98.0.0")
+		CheckBadRequestStatus(t, resp)
+		CheckErrorMessage(t, resp, "app.plugin.marketplace.manifest_mismatch.app_error")
+	})
+
+	t.Run("install", func(t *testing.T) {
+		_, resp := th.SystemAdminClient.InstallMarketplacePlugin("", "")
+		CheckBadRequestStatus(t, resp)
+
+		installed, resp := th.SystemAdminClient.InstallMarketplacePlugin("testplugin", "")
+		defer os.RemoveAll("plugins/testplugin")
+		CheckCreatedStatus(t, resp)
+		assert.Equal(t, "testplugin", installed.Id)
+		assert.Equal(t, "99.0.0", installed.Version)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PluginSettings.Enable = false })
+
+		_, resp := th.SystemAdminClient.GetMarketplacePlugins()
+		CheckNotImplementedStatus(t, resp)
+
+		_, resp = th.SystemAdminClient.InstallMarketplacePlugin("testplugin", "")
+		CheckNotImplementedStatus(t, resp)
+	})
+}
@@ -17,10 +17,18 @@ func (api *API) InitPost() {
 	api.BaseRoutes.Post.Handle("", api.ApiSessionRequired(getPost)).Methods("GET")
 	api.BaseRoutes.Post.Handle("", api.ApiSessionRequired(deletePost)).Methods("DELETE")
 	api.BaseRoutes.Posts.Handle("/ephemeral", api.ApiSessionRequired(createEphemeralPost)).Methods("POST")
+	api.BaseRoutes.Posts.Handle("/scheduled", api.ApiSessionRequired(createScheduledPost)).Methods("POST")
+	api.BaseRoutes.Posts.Handle("/scheduled/{post_id:[A-Za-z0-9]+}", api.ApiSessionRequired(patchScheduledPost)).Methods("PUT")
+	api.BaseRoutes.Posts.Handle("/scheduled/{post_id:[A-Za-z0-9]+}", api.ApiSessionRequired(deleteScheduledPost)).Methods("DELETE")
 	api.BaseRoutes.Post.Handle("/thread", api.ApiSessionRequired(getPostThread)).Methods("GET")
 	api.BaseRoutes.Post.Handle("/files/info", api.ApiSessionRequired(getFileInfosForPost)).Methods("GET")
+	api.BaseRoutes.Post.Handle("/seen_by", api.ApiSessionRequired(getPostSeenBy)).Methods("GET")
 	api.BaseRoutes.PostsForChannel.Handle("", api.ApiSessionRequired(getPostsForChannel)).Methods("GET")
 	api.BaseRoutes.PostsForUser.Handle("/flagged", api.ApiSessionRequired(getFlaggedPostsForUser)).Methods("GET")
+	api.BaseRoutes.PostsForUser.Handle("/scheduled", api.ApiSessionRequired(getScheduledPostsForUser)).Methods("GET")
+	api.BaseRoutes.PostsForUser.Handle("/reminders", api.ApiSessionRequired(getPostRemindersForUser)).Methods("GET")
+	api.BaseRoutes.PostForUser.Handle("/reminder", api.ApiSessionRequired(setPostReminder)).Methods("POST")
+	api.BaseRoutes.PostForUser.Handle("/reminder", api.ApiSessionRequired(deletePostReminder)).Methods("DELETE")
 
 	api.BaseRoutes.Team.Handle("/posts/search", api.ApiSessionRequired(searchPosts)).Methods("POST")
 	api.BaseRoutes.Post.Handle("", api.ApiSessionRequired(updatePost)).Methods("PUT")
@@ -65,6 +73,7 @@ func createPost(c *Context, w http.ResponseWriter, r *http.Request) {
 
 	c.App.SetStatusOnline(c.App.Session.UserId, false)
 	c.App.UpdateLastActivityAtIfNeeded(c.App.Session)
+	c.App.DeleteDraftForPost(rp)
 
 	w.WriteHeader(http.StatusCreated)
 
@@ -102,6 +111,107 @@ func createEphemeralPost(c *Context, w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(rp.ToJson()))
 }
 
+func createScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	scheduledPost := model.ScheduledPostFromJson(r.Body)
+	if scheduledPost == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	scheduledPost.UserId = c.App.Session.UserId
+
+	if !c.App.SessionHasPermissionToChannel(c.App.Session, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		c.SetPermissionError(model.PERMISSION_CREATE_POST)
+		return
+	}
+
+	rsp, err := c.App.CreateScheduledPost(scheduledPost)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rsp.ToJson()))
+}
+
+func getScheduledPostsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	scheduledPosts, err := c.App.GetScheduledPostsForUser(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ScheduledPostListToJson(scheduledPosts)))
+}
+
+func patchScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	patch := model.ScheduledPostPatchFromJson(r.Body)
+	if patch == nil {
+		c.SetInvalidParam("scheduled_post")
+		return
+	}
+
+	scheduledPost, err := c.App.GetScheduledPost(c.Params.PostId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, scheduledPost.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	rsp, err := c.App.PatchScheduledPost(c.Params.PostId, patch)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(rsp.ToJson()))
+}
+
+func deleteScheduledPost(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	scheduledPost, err := c.App.GetScheduledPost(c.Params.PostId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, scheduledPost.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := c.App.DeleteScheduledPost(c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
 func getPostsForChannel(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequireChannelId()
 	if c.Err != nil {
@@ -303,6 +413,103 @@ func deletePost(c *Context, w http.ResponseWriter, r *http.Request) {
 	ReturnStatusOK(w)
 }
 
+func getPostSeenBy(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(c.App.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	post, err := c.App.GetSinglePost(c.Params.PostId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	userIds, err := c.App.GetPostSeenBy(post)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.ArrayToJson(userIds)))
+}
+
+func setPostReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	reminder := model.PostReminderFromJson(r.Body)
+	if reminder == nil {
+		c.SetInvalidParam("post_reminder")
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(c.App.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	rreminder, err := c.App.SetPostReminder(c.Params.UserId, c.Params.PostId, reminder.TargetTime)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.Write([]byte(rreminder.ToJson()))
+}
+
+func getPostRemindersForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	reminders, err := c.App.GetPostRemindersForUser(c.Params.UserId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.PostReminderListToJson(reminders)))
+}
+
+func deletePostReminder(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if err := c.App.DeletePostReminder(c.Params.UserId, c.Params.PostId); err != nil {
+		c.Err = err
+		return
+	}
+
+	ReturnStatusOK(w)
+}
+
 func getPostThread(c *Context, w http.ResponseWriter, r *http.Request) {
 	c.RequirePostId()
 	if c.Err != nil {
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/mattermost/mattermost-server/app"
 	"github.com/mattermost/mattermost-server/model"
@@ -1849,3 +1850,179 @@ func TestGetFileInfosForPost(t *testing.T) {
 	_, resp = th.SystemAdminClient.GetFileInfosForPost(th.BasicPost.Id, "")
 	CheckNoError(t, resp)
 }
+
+func TestScheduledPosts(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	scheduledAt := model.GetMillis() + 60*60*1000
+	scheduledPost := &model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "later", ScheduledAt: scheduledAt}
+
+	created, resp := Client.CreateScheduledPost(scheduledPost)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+	assert.Equal(t, th.BasicUser.Id, created.UserId)
+	assert.Equal(t, scheduledAt, created.ScheduledAt)
+
+	_, resp = Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, Message: "past", ScheduledAt: model.GetMillis() - 1000})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicChannel.Id, RootId: model.NewId(), Message: "reply", ScheduledAt: scheduledAt})
+	CheckBadRequestStatus(t, resp)
+
+	scheduledPosts, resp := Client.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	require.Len(t, scheduledPosts, 1)
+	assert.Equal(t, created.Id, scheduledPosts[0].Id)
+
+	_, resp = Client.GetScheduledPostsForUser(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	message := "edited"
+	patched, resp := Client.PatchScheduledPost(created.Id, &model.ScheduledPostPatch{Message: &message})
+	CheckNoError(t, resp)
+	assert.Equal(t, "edited", patched.Message)
+	assert.Equal(t, scheduledAt, patched.ScheduledAt)
+
+	past := model.GetMillis() - 1000
+	_, resp = Client.PatchScheduledPost(created.Id, &model.ScheduledPostPatch{ScheduledAt: &past})
+	CheckBadRequestStatus(t, resp)
+
+	_, resp = Client.PatchScheduledPost(model.NewId(), &model.ScheduledPostPatch{Message: &message})
+	CheckNotFoundStatus(t, resp)
+
+	t.Run("other users", func(t *testing.T) {
+		th.LoginBasic2()
+		defer th.LoginBasic()
+
+		_, resp := Client.PatchScheduledPost(created.Id, &model.ScheduledPostPatch{Message: &message})
+		CheckForbiddenStatus(t, resp)
+
+		_, resp = Client.DeleteScheduledPost(created.Id)
+		CheckForbiddenStatus(t, resp)
+
+		_, resp = Client.CreateScheduledPost(&model.ScheduledPost{ChannelId: th.BasicPrivateChannel.Id, Message: "private", ScheduledAt: scheduledAt})
+		CheckForbiddenStatus(t, resp)
+	})
+
+	scheduledPosts, resp = th.SystemAdminClient.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	assert.Len(t, scheduledPosts, 1)
+
+	ok, resp := Client.DeleteScheduledPost(created.Id)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	_, resp = Client.DeleteScheduledPost(created.Id)
+	CheckNotFoundStatus(t, resp)
+
+	scheduledPosts, resp = Client.GetScheduledPostsForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	assert.Empty(t, scheduledPosts)
+
+	// Posts to publish are created through the regular post creation path.
+	due, err := th.App.Srv.Store.ScheduledPost().Save(&model.ScheduledPost{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "due " + model.NewId(), ScheduledAt: model.GetMillis() - 1000})
+	require.Nil(t, err)
+
+	_, err = th.App.PublishDueScheduledPosts(model.GetMillis(), 100)
+	require.Nil(t, err)
+
+	posts, resp := Client.GetPostsForChannel(th.BasicChannel.Id, 0, 10, "")
+	CheckNoError(t, resp)
+	assert.Equal(t, due.Message, posts.Posts[posts.Order[0]].Message)
+}
+
+func TestGetPostSeenBy(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	WebSocketClient, err := th.CreateWebSocketClient()
+	require.Nil(t, err)
+	WebSocketClient.Listen()
+
+	channel := th.CreateDmChannel(th.BasicUser2)
+	post, resp := Client.CreatePost(&model.Post{ChannelId: channel.Id, Message: "hello"})
+	CheckNoError(t, resp)
+
+	seenBy, resp := Client.GetPostSeenBy(post.Id)
+	CheckNoError(t, resp)
+	assert.Empty(t, seenBy)
+
+	Client2 := th.CreateClient()
+	th.LoginBasic2WithClient(Client2)
+	_, resp = Client2.ViewChannel(th.BasicUser2.Id, &model.ChannelView{ChannelId: channel.Id})
+	CheckNoError(t, resp)
+
+	timeout := time.After(2 * time.Second)
+	waiting := true
+	for waiting {
+		select {
+		case event := <-WebSocketClient.EventChannel:
+			if event.Event == model.WEBSOCKET_EVENT_POST_SEEN && event.Data["user_id"] == th.BasicUser2.Id {
+				assert.Equal(t, channel.Id, event.Broadcast.ChannelId)
+				assert.True(t, event.Data["last_viewed_at"].(float64) >= float64(post.CreateAt))
+				waiting = false
+			}
+		case <-timeout:
+			require.Fail(t, "should have received post_seen event")
+		}
+	}
+
+	seenBy, resp = Client.GetPostSeenBy(post.Id)
+	CheckNoError(t, resp)
+	assert.Equal(t, []string{th.BasicUser2.Id}, seenBy)
+
+	_, resp = Client.GetPostSeenBy(th.BasicPost.Id)
+	CheckBadRequestStatus(t, resp)
+
+	otherPost := th.CreatePostWithClient(Client, th.CreateDmChannel(th.CreateUser()))
+	th.LoginBasic2()
+	_, resp = Client.GetPostSeenBy(otherPost.Id)
+	CheckForbiddenStatus(t, resp)
+}
+
+func TestPostReminders(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	targetTime := model.GetMillis() + 60*60*1000
+
+	reminder, resp := Client.SetPostReminder(th.BasicUser.Id, th.BasicPost.Id, targetTime)
+	CheckNoError(t, resp)
+	CheckCreatedStatus(t, resp)
+	assert.Equal(t, th.BasicPost.Id, reminder.PostId)
+	assert.Equal(t, th.BasicUser.Id, reminder.UserId)
+	assert.Equal(t, targetTime, reminder.TargetTime)
+
+	_, resp = Client.SetPostReminder(th.BasicUser.Id, th.BasicPost.Id, model.GetMillis()-1000)
+	CheckBadRequestStatus(t, resp)
+
+	reminders, resp := Client.GetPostRemindersForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, th.BasicPost.Id, reminders[0].PostId)
+
+	_, resp = Client.GetPostRemindersForUser(th.BasicUser2.Id)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.SetPostReminder(th.BasicUser2.Id, th.BasicPost.Id, targetTime)
+	CheckForbiddenStatus(t, resp)
+
+	privatePost := th.CreatePostWithClient(th.SystemAdminClient, th.CreateChannelWithClient(th.SystemAdminClient, model.CHANNEL_PRIVATE))
+	_, resp = Client.SetPostReminder(th.BasicUser.Id, privatePost.Id, targetTime)
+	CheckForbiddenStatus(t, resp)
+
+	ok, resp := Client.DeletePostReminder(th.BasicUser.Id, th.BasicPost.Id)
+	CheckNoError(t, resp)
+	assert.True(t, ok)
+
+	_, resp = Client.DeletePostReminder(th.BasicUser.Id, th.BasicPost.Id)
+	CheckNotFoundStatus(t, resp)
+
+	reminders, resp = Client.GetPostRemindersForUser(th.BasicUser.Id)
+	CheckNoError(t, resp)
+	assert.Empty(t, reminders)
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func (api *API) InitThread() {
+	api.BaseRoutes.TeamForUser.Handle("/threads", api.ApiSessionRequired(getThreadsForUser)).Methods("GET")
+	api.BaseRoutes.TeamForUser.Handle("/threads/{post_id:[A-Za-z0-9]+}/following", api.ApiSessionRequired(followThread)).Methods("PUT")
+	api.BaseRoutes.TeamForUser.Handle("/threads/{post_id:[A-Za-z0-9]+}/following", api.ApiSessionRequired(unfollowThread)).Methods("DELETE")
+	api.BaseRoutes.TeamForUser.Handle("/threads/{post_id:[A-Za-z0-9]+}/read", api.ApiSessionRequired(markThreadAsRead)).Methods("PUT")
+}
+
+func getThreadsForUser(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToTeam(c.App.Session, c.Params.TeamId, model.PERMISSION_VIEW_TEAM) {
+		c.SetPermissionError(model.PERMISSION_VIEW_TEAM)
+		return
+	}
+
+	threads, err := c.App.GetThreadsForUser(c.Params.UserId, c.Params.TeamId, c.Params.Page, c.Params.PerPage)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(model.UserThreadListToJson(threads)))
+}
+
+func followThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	updateThreadFollowing(c, w, true)
+}
+
+func unfollowThread(c *Context, w http.ResponseWriter, r *http.Request) {
+	updateThreadFollowing(c, w, false)
+}
+
+func updateThreadFollowing(c *Context, w http.ResponseWriter, following bool) {
+	c.RequireUserId().RequireTeamId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(c.App.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	membership, err := c.App.UpdateThreadFollowing(c.Params.UserId, c.Params.PostId, following)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(membership.ToJson()))
+}
+
+func markThreadAsRead(c *Context, w http.ResponseWriter, r *http.Request) {
+	c.RequireUserId().RequireTeamId().RequirePostId()
+	if c.Err != nil {
+		return
+	}
+
+	if !c.App.SessionHasPermissionToUser(c.App.Session, c.Params.UserId) {
+		c.SetPermissionError(model.PERMISSION_EDIT_OTHER_USERS)
+		return
+	}
+
+	if !c.App.SessionHasPermissionToChannelByPost(c.App.Session, c.Params.PostId, model.PERMISSION_READ_CHANNEL) {
+		c.SetPermissionError(model.PERMISSION_READ_CHANNEL)
+		return
+	}
+
+	membership, err := c.App.MarkThreadAsRead(c.Params.UserId, c.Params.PostId)
+	if err != nil {
+		c.Err = err
+		return
+	}
+
+	w.Write([]byte(membership.ToJson()))
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package api4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestThreads(t *testing.T) {
+	th := Setup().InitBasic()
+	defer th.TearDown()
+	Client := th.Client
+
+	threads, resp := Client.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+	CheckNoError(t, resp)
+	assert.Empty(t, threads)
+
+	th.LoginBasic2()
+	_, resp = Client.CreatePost(&model.Post{ChannelId: th.BasicChannel.Id, RootId: th.BasicPost.Id, Message: "reply"})
+	CheckNoError(t, resp)
+	th.LoginBasic()
+
+	// Memberships are updated in the background once the reply is posted.
+	for i := 0; i < 20 && len(threads) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+		threads, resp = Client.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+		CheckNoError(t, resp)
+	}
+	require.Len(t, threads, 1)
+	assert.Equal(t, th.BasicPost.Id, threads[0].PostId)
+	assert.Equal(t, int64(1), threads[0].ReplyCount)
+	assert.Equal(t, int64(1), threads[0].UnreadReplies)
+	assert.Equal(t, th.BasicPost.Message, threads[0].Post.Message)
+
+	_, resp = Client.GetThreadsForUser(th.BasicUser2.Id, th.BasicTeam.Id, 0, 10)
+	CheckForbiddenStatus(t, resp)
+
+	membership, resp := Client.MarkThreadAsRead(th.BasicUser.Id, th.BasicTeam.Id, th.BasicPost.Id)
+	CheckNoError(t, resp)
+	assert.Zero(t, membership.UnreadReplies)
+
+	membership, resp = Client.UpdateThreadFollowing(th.BasicUser.Id, th.BasicTeam.Id, th.BasicPost.Id, false)
+	CheckNoError(t, resp)
+	assert.False(t, membership.Following)
+
+	threads, resp = Client.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+	CheckNoError(t, resp)
+	assert.Empty(t, threads)
+
+	membership, resp = Client.UpdateThreadFollowing(th.BasicUser.Id, th.BasicTeam.Id, th.BasicPost.Id, true)
+	CheckNoError(t, resp)
+	assert.True(t, membership.Following)
+
+	_, resp = Client.UpdateThreadFollowing(th.BasicUser.Id, th.BasicTeam.Id, model.NewId(), true)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.UpdateThreadFollowing(th.BasicUser2.Id, th.BasicTeam.Id, th.BasicPost.Id, true)
+	CheckForbiddenStatus(t, resp)
+
+	_, resp = Client.MarkThreadAsRead(th.BasicUser.Id, th.BasicTeam.Id, th.CreatePost().Id)
+	CheckNotFoundStatus(t, resp)
+
+	t.Run("left channel", func(t *testing.T) {
+		channel := th.CreatePrivateChannel()
+		post := th.CreatePostWithClient(Client, channel)
+
+		_, resp := Client.UpdateThreadFollowing(th.BasicUser.Id, th.BasicTeam.Id, post.Id, true)
+		CheckNoError(t, resp)
+
+		_, resp = Client.RemoveUserFromChannel(channel.Id, th.BasicUser.Id)
+		CheckNoError(t, resp)
+
+		threads, resp := Client.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+		CheckNoError(t, resp)
+		for _, thread := range threads {
+			assert.NotEqual(t, post.Id, thread.PostId)
+		}
+
+		_, resp = Client.MarkThreadAsRead(th.BasicUser.Id, th.BasicTeam.Id, post.Id)
+		CheckForbiddenStatus(t, resp)
+	})
+}
@@ -94,6 +94,12 @@ func (s *Server) initJobs() {
 	if jobsPluginsInterface != nil {
 		s.Jobs.Plugins = jobsPluginsInterface(s.FakeApp())
 	}
+	if jobsScheduledPostsInterface != nil {
+		s.Jobs.ScheduledPosts = jobsScheduledPostsInterface(s.FakeApp())
+	}
+	if jobsPostRemindersInterface != nil {
+		s.Jobs.PostReminders = jobsPostRemindersInterface(s.FakeApp())
+	}
 	s.Jobs.Workers = s.Jobs.InitWorkers()
 	s.Jobs.Schedulers = s.Jobs.InitSchedulers()
 }
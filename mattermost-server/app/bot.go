@@ -4,12 +4,22 @@
 package app
 
 import (
+	"net/http"
+
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
 )
 
 // CreateBot creates the given bot and corresponding user.
 func (a *App) CreateBot(bot *model.Bot) (*model.Bot, *model.AppError) {
+	if model.IsReservedUsername(bot.Username) {
+		return nil, model.NewAppError("CreateBot", "app.user.username_reserved.app_error", nil, "username="+bot.Username, http.StatusBadRequest)
+	}
+
+	return a.createBot(bot)
+}
+
+func (a *App) createBot(bot *model.Bot) (*model.Bot, *model.AppError) {
 	result := <-a.Srv.Store.User().Save(model.UserFromBot(bot))
 	if result.Err != nil {
 		return nil, result.Err
@@ -32,6 +42,10 @@ func (a *App) PatchBot(botUserId string, botPatch *model.BotPatch) (*model.Bot,
 		return nil, err
 	}
 
+	if botPatch.Username != nil && *botPatch.Username != bot.Username && model.IsReservedUsername(*botPatch.Username) {
+		return nil, model.NewAppError("PatchBot", "app.user.username_reserved.app_error", nil, "username="+*botPatch.Username, http.StatusBadRequest)
+	}
+
 	bot.Patch(botPatch)
 
 	user, err := a.Srv.Store.User().Get(botUserId)
@@ -66,6 +80,35 @@ func (a *App) GetBot(botUserId string, includeDeleted bool) (*model.Bot, *model.
 	return result.Data.(*model.Bot), nil
 }
 
+// GetSystemBot returns the bot the server posts its own messages as, creating it on first use.
+// The bot is found by the user id stored when it was created, since its username could
+// otherwise be taken by an account that predates the reservation.
+func (a *App) GetSystemBot() (*model.Bot, *model.AppError) {
+	if system, err := a.Srv.Store.System().GetByName(model.SYSTEM_BOT_USER_ID_KEY); err == nil {
+		return a.GetBot(system.Value, true)
+	}
+
+	bot, err := a.createBot(&model.Bot{
+		Username:    model.BOT_SYSTEM_BOT_USERNAME,
+		DisplayName: "System",
+		Description: "Sends messages on behalf of the server.",
+		OwnerId:     model.BOT_SYSTEM_BOT_USERNAME,
+	})
+	if err != nil {
+		// Another server may have created the bot in the meantime.
+		if system, appErr := a.Srv.Store.System().GetByName(model.SYSTEM_BOT_USER_ID_KEY); appErr == nil {
+			return a.GetBot(system.Value, true)
+		}
+		return nil, err
+	}
+
+	if err := a.Srv.Store.System().Save(&model.System{Name: model.SYSTEM_BOT_USER_ID_KEY, Value: bot.UserId}); err != nil {
+		return nil, err
+	}
+
+	return bot, nil
+}
+
 // GetBots returns the requested page of bots.
 func (a *App) GetBots(options *model.BotGetOptions) (model.BotList, *model.AppError) {
 	result := <-a.Srv.Store.Bot().GetAll(options)
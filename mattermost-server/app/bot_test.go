@@ -59,6 +59,19 @@ func TestCreateBot(t *testing.T) {
 		assert.Equal(t, th.BasicUser.Id, bot.OwnerId)
 	})
 
+	t.Run("create bot, username reserved", func(t *testing.T) {
+		th := Setup(t).InitBasic()
+		defer th.TearDown()
+
+		_, err := th.App.CreateBot(&model.Bot{
+			Username:    model.BOT_SYSTEM_BOT_USERNAME,
+			Description: "a bot",
+			OwnerId:     th.BasicUser.Id,
+		})
+		require.NotNil(t, err)
+		require.Equal(t, "app.user.username_reserved.app_error", err.Id)
+	})
+
 	t.Run("create bot, username already used by a non-bot user", func(t *testing.T) {
 		th := Setup(t).InitBasic()
 		defer th.TearDown()
@@ -235,6 +248,41 @@ func TestGetBot(t *testing.T) {
 	})
 }
 
+func TestGetSystemBot(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	bot, err := th.App.GetSystemBot()
+	require.Nil(t, err)
+	assert.Equal(t, model.BOT_SYSTEM_BOT_USERNAME, bot.Username)
+
+	system, err := th.App.Srv.Store.System().GetByName(model.SYSTEM_BOT_USER_ID_KEY)
+	require.Nil(t, err)
+	assert.Equal(t, bot.UserId, system.Value)
+
+	t.Run("found by the stored id", func(t *testing.T) {
+		patched, err := th.App.PatchBot(bot.UserId, &model.BotPatch{Username: model.NewString("renamed-system-bot")})
+		require.Nil(t, err)
+
+		again, err := th.App.GetSystemBot()
+		require.Nil(t, err)
+		assert.Equal(t, bot.UserId, again.UserId)
+		assert.Equal(t, patched.Username, again.Username)
+	})
+
+	t.Run("reserved username", func(t *testing.T) {
+		user := th.CreateUser()
+		user.Username = model.BOT_SYSTEM_BOT_USERNAME
+		_, err := th.App.UpdateUser(user, false)
+		require.NotNil(t, err)
+		require.Equal(t, "app.user.username_reserved.app_error", err.Id)
+
+		_, err = th.App.CreateUser(&model.User{Email: "success+" + model.NewId() + "@simulator.amazonses.com", Username: model.BOT_SYSTEM_BOT_USERNAME, Password: "passwd1"})
+		require.NotNil(t, err)
+		require.Equal(t, "app.user.username_reserved.app_error", err.Id)
+	})
+}
+
 func TestGetBots(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
@@ -494,6 +494,18 @@ func (a *App) GetGroupChannel(userIds []string) (*model.Channel, *model.AppError
 }
 
 func (a *App) UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppError) {
+	pluginsEnvironment := a.GetPluginsEnvironment()
+
+	// Callers usually modify the channel they loaded, so the previous version is read again for
+	// the plugins. Failing to read it only keeps the plugins from being notified.
+	var oldChannel *model.Channel
+	if pluginsEnvironment != nil {
+		var err *model.AppError
+		if oldChannel, err = a.Srv.Store.Channel().Get(channel.Id, true); err != nil {
+			mlog.Error("Failed to get the channel before its update for plugins", mlog.String("channel_id", channel.Id), mlog.Err(err))
+		}
+	}
+
 	_, err := a.Srv.Store.Channel().Update(channel)
 	if err != nil {
 		return nil, err
@@ -501,6 +513,16 @@ func (a *App) UpdateChannel(channel *model.Channel) (*model.Channel, *model.AppE
 
 	a.InvalidateCacheForChannel(channel)
 
+	if pluginsEnvironment != nil && oldChannel != nil {
+		a.Srv.Go(func() {
+			pluginContext := a.PluginContext()
+			pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+				hooks.ChannelHasBeenUpdated(pluginContext, channel, oldChannel)
+				return true
+			}, plugin.ChannelHasBeenUpdatedId)
+		})
+	}
+
 	messageWs := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_CHANNEL_UPDATED, "", channel.Id, "", nil)
 	messageWs.Add("channel", channel.ToJson())
 	a.Publish(messageWs)
@@ -880,6 +902,19 @@ func (a *App) DeleteChannel(channel *model.Channel, userId string) *model.AppErr
 	message.Add("delete_at", deleteAt)
 	a.Publish(message)
 
+	if pluginsEnvironment := a.GetPluginsEnvironment(); pluginsEnvironment != nil {
+		archivedChannel := *channel
+		archivedChannel.DeleteAt = deleteAt
+
+		a.Srv.Go(func() {
+			pluginContext := a.PluginContext()
+			pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+				hooks.ChannelHasBeenArchived(pluginContext, &archivedChannel, user)
+				return true
+			}, plugin.ChannelHasBeenArchivedId)
+		})
+	}
+
 	return nil
 }
 
@@ -1742,9 +1777,7 @@ func (a *App) UpdateChannelLastViewedAt(channelIds []string, userId string) *mod
 func (a *App) AutocompleteChannels(teamId string, term string) (*model.ChannelList, *model.AppError) {
 	includeDeleted := *a.Config().TeamSettings.ExperimentalViewArchivedChannels
 
-	esInterface := a.Elasticsearch
-	license := a.License()
-	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableAutocomplete && license != nil && *license.Features.Elasticsearch {
+	if *a.Config().ElasticsearchSettings.EnableAutocomplete && a.isSearchEngineAvailable() {
 		channelIds, err := a.Elasticsearch.SearchChannels(teamId, term)
 		if err != nil {
 			return nil, err
@@ -1864,6 +1897,9 @@ func (a *App) MarkChannelsAsViewed(channelIds []string, userId string, currentSe
 	for _, channelId := range channelsToClearPushNotifications {
 		a.ClearPushNotification(currentSessionId, userId, channelId)
 	}
+	a.Srv.Go(func() {
+		a.publishPostsSeen(userId, times)
+	})
 	return times, nil
 }
 
@@ -14,6 +14,7 @@ import (
 	goi18n "github.com/mattermost/go-i18n/i18n"
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/mattermost/mattermost-server/utils"
 )
@@ -150,7 +151,62 @@ func (a *App) ListAllCommands(teamId string, T goi18n.TranslateFunc) ([]*model.C
 	return commands, nil
 }
 
+// checkCommandChannelPermissions checks that the session running the command may use slash
+// commands in its channel and team, as done when the command is first received.
+func (a *App) checkCommandChannelPermissions(args *model.CommandArgs) *model.AppError {
+	if !a.SessionHasPermissionToChannel(args.Session, args.ChannelId, model.PERMISSION_USE_SLASH_COMMANDS) {
+		return a.MakePermissionError(model.PERMISSION_USE_SLASH_COMMANDS)
+	}
+
+	channel, err := a.GetChannel(args.ChannelId)
+	if err != nil {
+		return err
+	}
+
+	if channel.Type != model.CHANNEL_DIRECT && channel.Type != model.CHANNEL_GROUP {
+		args.TeamId = channel.TeamId
+	} else if args.Session.GetTeamByTeamId(args.TeamId) == nil && !a.SessionHasPermissionTo(args.Session, model.PERMISSION_USE_SLASH_COMMANDS) {
+		return a.MakePermissionError(model.PERMISSION_USE_SLASH_COMMANDS)
+	}
+
+	return nil
+}
+
 func (a *App) ExecuteCommand(args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
+	if pluginsEnvironment := a.GetPluginsEnvironment(); pluginsEnvironment != nil {
+		channelId, teamId := args.ChannelId, args.TeamId
+
+		var rejectionError *model.AppError
+		pluginContext := a.PluginContext()
+		pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+			replacementArgs, rejectionReason := hooks.CommandWillBeExecuted(pluginContext, args)
+			if rejectionReason != "" {
+				rejectionError = model.NewAppError("ExecuteCommand", "api.command.execute_command.rejected_by_plugin.app_error", map[string]interface{}{"Reason": rejectionReason}, "", http.StatusForbidden)
+				return false
+			}
+			if replacementArgs != nil {
+				// Plugins can't change who runs the command, nor send back the translations.
+				replacementArgs.UserId = args.UserId
+				replacementArgs.T = args.T
+				replacementArgs.Session = args.Session
+				args = replacementArgs
+			}
+
+			return true
+		}, plugin.CommandWillBeExecutedId)
+		if rejectionError != nil {
+			return nil, rejectionError
+		}
+
+		// A plugin moving the command to another channel or team mustn't let the user run commands
+		// where they aren't allowed to.
+		if args.ChannelId != channelId || args.TeamId != teamId {
+			if appErr := a.checkCommandChannelPermissions(args); appErr != nil {
+				return nil, appErr
+			}
+		}
+	}
+
 	parts := strings.Split(args.Command, " ")
 	trigger := parts[0][1:]
 	trigger = strings.ToLower(trigger)
@@ -16,3 +16,51 @@ func (a *App) GetDataRetentionPolicy() (*model.DataRetentionPolicy, *model.AppEr
 
 	return a.DataRetention.GetPolicy()
 }
+
+func (a *App) GetTeamRetentionPolicy(teamId string) (*model.TeamRetentionPolicy, *model.AppError) {
+	return a.Srv.Store.DataRetentionPolicy().GetTeamPolicy(teamId)
+}
+
+func (a *App) GetTeamRetentionPoliciesPage(page, perPage int) ([]*model.TeamRetentionPolicy, *model.AppError) {
+	return a.Srv.Store.DataRetentionPolicy().GetTeamPolicies(page*perPage, perPage)
+}
+
+func (a *App) SaveTeamRetentionPolicy(policy *model.TeamRetentionPolicy) (*model.TeamRetentionPolicy, *model.AppError) {
+	if _, err := a.GetTeam(policy.TeamId); err != nil {
+		return nil, err
+	}
+
+	return a.Srv.Store.DataRetentionPolicy().SaveTeamPolicy(policy)
+}
+
+func (a *App) DeleteTeamRetentionPolicy(teamId string) *model.AppError {
+	if _, err := a.GetTeamRetentionPolicy(teamId); err != nil {
+		return err
+	}
+
+	return a.Srv.Store.DataRetentionPolicy().DeleteTeamPolicy(teamId)
+}
+
+func (a *App) GetChannelRetentionPolicy(channelId string) (*model.ChannelRetentionPolicy, *model.AppError) {
+	return a.Srv.Store.DataRetentionPolicy().GetChannelPolicy(channelId)
+}
+
+func (a *App) GetChannelRetentionPoliciesPage(page, perPage int) ([]*model.ChannelRetentionPolicy, *model.AppError) {
+	return a.Srv.Store.DataRetentionPolicy().GetChannelPolicies(page*perPage, perPage)
+}
+
+func (a *App) SaveChannelRetentionPolicy(policy *model.ChannelRetentionPolicy) (*model.ChannelRetentionPolicy, *model.AppError) {
+	if _, err := a.GetChannel(policy.ChannelId); err != nil {
+		return nil, err
+	}
+
+	return a.Srv.Store.DataRetentionPolicy().SaveChannelPolicy(policy)
+}
+
+func (a *App) DeleteChannelRetentionPolicy(channelId string) *model.AppError {
+	if _, err := a.GetChannelRetentionPolicy(channelId); err != nil {
+		return err
+	}
+
+	return a.Srv.Store.DataRetentionPolicy().DeleteChannelPolicy(channelId)
+}
@@ -429,8 +429,9 @@ func (a *App) trackConfig() {
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_PRIVACY, map[string]interface{}{
-		"show_email_address": cfg.PrivacySettings.ShowEmailAddress,
-		"show_full_name":     cfg.PrivacySettings.ShowFullName,
+		"show_email_address":   cfg.PrivacySettings.ShowEmailAddress,
+		"show_full_name":       cfg.PrivacySettings.ShowFullName,
+		"enable_read_receipts": *cfg.PrivacySettings.EnableReadReceipts,
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_THEME, map[string]interface{}{
@@ -441,9 +442,11 @@ func (a *App) trackConfig() {
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_OAUTH, map[string]interface{}{
-		"enable_gitlab":    cfg.GitLabSettings.Enable,
-		"enable_google":    cfg.GoogleSettings.Enable,
-		"enable_office365": cfg.Office365Settings.Enable,
+		"enable_gitlab":      cfg.GitLabSettings.Enable,
+		"enable_google":      cfg.GoogleSettings.Enable,
+		"enable_office365":   cfg.Office365Settings.Enable,
+		"enable_openid":      cfg.OpenIdSettings.Enable,
+		"openid_enable_pkce": cfg.OpenIdSettings.EnablePKCE,
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_SUPPORT, map[string]interface{}{
@@ -571,16 +574,25 @@ func (a *App) trackConfig() {
 		"live_indexing_batch_size":          *cfg.ElasticsearchSettings.LiveIndexingBatchSize,
 		"bulk_indexing_time_window_seconds": *cfg.ElasticsearchSettings.BulkIndexingTimeWindowSeconds,
 		"request_timeout_seconds":           *cfg.ElasticsearchSettings.RequestTimeoutSeconds,
+		"isdefault_bleve_index_dir":         isDefault(*cfg.BleveSettings.IndexDir, model.BLEVE_SETTINGS_DEFAULT_INDEX_DIR),
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_PLUGIN, map[string]interface{}{
-		"enable_jira":         pluginActivated(cfg.PluginSettings.PluginStates, "jira"),
-		"enable_nps":          pluginActivated(cfg.PluginSettings.PluginStates, "com.mattermost.nps"),
-		"enable_nps_survey":   pluginSetting(&cfg.PluginSettings, "com.mattermost.nps", "enablesurvey", false),
-		"enable_zoom":         pluginActivated(cfg.PluginSettings.PluginStates, "zoom"),
-		"enable":              *cfg.PluginSettings.Enable,
-		"enable_uploads":      *cfg.PluginSettings.EnableUploads,
-		"enable_health_check": *cfg.PluginSettings.EnableHealthCheck,
+		"enable_jira":                pluginActivated(cfg.PluginSettings.PluginStates, "jira"),
+		"enable_nps":                 pluginActivated(cfg.PluginSettings.PluginStates, "com.mattermost.nps"),
+		"enable_nps_survey":          pluginSetting(&cfg.PluginSettings, "com.mattermost.nps", "enablesurvey", false),
+		"enable_zoom":                pluginActivated(cfg.PluginSettings.PluginStates, "zoom"),
+		"enable":                     *cfg.PluginSettings.Enable,
+		"enable_uploads":             *cfg.PluginSettings.EnableUploads,
+		"enable_health_check":        *cfg.PluginSettings.EnableHealthCheck,
+		"require_plugin_signature":   *cfg.PluginSettings.RequirePluginSignature,
+		"signature_public_key_files": len(cfg.PluginSettings.SignaturePublicKeyFiles),
+		"allowed_unsigned_plugins":   len(cfg.PluginSettings.AllowedUnsignedPlugins),
+		"isdefault_marketplace_url":  isDefault(*cfg.PluginSettings.MarketplaceUrl, ""),
+		"max_memory_mb":              *cfg.PluginSettings.MaxMemoryMB,
+		"max_cpu_percent":            *cfg.PluginSettings.MaxCPUPercent,
+		"resource_limits":            len(cfg.PluginSettings.ResourceLimits),
+		"isdefault_cgroup_path":      isDefault(*cfg.PluginSettings.ResourceLimitsCgroupPath, ""),
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_DATA_RETENTION, map[string]interface{}{
@@ -589,6 +601,7 @@ func (a *App) trackConfig() {
 		"message_retention_days":  *cfg.DataRetentionSettings.MessageRetentionDays,
 		"file_retention_days":     *cfg.DataRetentionSettings.FileRetentionDays,
 		"deletion_job_start_time": *cfg.DataRetentionSettings.DeletionJobStartTime,
+		"batch_size":              *cfg.DataRetentionSettings.BatchSize,
 	})
 
 	a.SendDiagnostic(TRACK_CONFIG_MESSAGE_EXPORT, map[string]interface{}{
@@ -0,0 +1,91 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// UpsertDraft saves the draft of a user for a channel or thread, replacing the previous one, and
+// lets the other sessions of the user know.
+func (a *App) UpsertDraft(draft *model.Draft) (*model.Draft, *model.AppError) {
+	draft.PreSave()
+	if err := draft.IsValid(a.MaxPostSize()); err != nil {
+		return nil, err
+	}
+
+	channel, err := a.GetChannel(draft.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.DeleteAt != 0 {
+		return nil, model.NewAppError("UpsertDraft", "api.post.create_post.can_not_post_to_deleted.error", nil, "", http.StatusBadRequest)
+	}
+
+	if draft.RootId != "" {
+		root, err := a.GetSinglePost(draft.RootId)
+		if err != nil || root.ChannelId != draft.ChannelId {
+			return nil, model.NewAppError("UpsertDraft", "api.post.create_post.root_id.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	event := model.WEBSOCKET_EVENT_DRAFT_UPDATED
+	if _, err := a.Srv.Store.Draft().Get(draft.UserId, draft.ChannelId, draft.RootId); err != nil {
+		event = model.WEBSOCKET_EVENT_DRAFT_CREATED
+	}
+
+	saved, err := a.Srv.Store.Draft().Save(draft)
+	if err != nil {
+		return nil, err
+	}
+
+	a.publishDraftEvent(event, saved)
+
+	return saved, nil
+}
+
+func (a *App) GetDraftsForUser(userId, teamId string) ([]*model.Draft, *model.AppError) {
+	return a.Srv.Store.Draft().GetForUser(userId, teamId)
+}
+
+func (a *App) DeleteDraft(userId, channelId, rootId string) *model.AppError {
+	deleted, err := a.Srv.Store.Draft().Delete(userId, channelId, rootId)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return model.NewAppError("DeleteDraft", "store.sql_draft.get.app_error", nil, "channel_id="+channelId+", root_id="+rootId, http.StatusNotFound)
+	}
+
+	a.publishDraftEvent(model.WEBSOCKET_EVENT_DRAFT_DELETED, &model.Draft{UserId: userId, ChannelId: channelId, RootId: rootId})
+
+	return nil
+}
+
+// DeleteDraftForPost removes, in the background, the draft a post was written from once its
+// author sent it, so that it doesn't linger on their other devices.
+func (a *App) DeleteDraftForPost(post *model.Post) {
+	a.Srv.Go(func() {
+		deleted, err := a.Srv.Store.Draft().Delete(post.UserId, post.ChannelId, post.RootId)
+		if err != nil {
+			mlog.Warn("Failed to delete draft of post", mlog.String("post_id", post.Id), mlog.Err(err))
+			return
+		}
+
+		if deleted {
+			a.publishDraftEvent(model.WEBSOCKET_EVENT_DRAFT_DELETED, &model.Draft{UserId: post.UserId, ChannelId: post.ChannelId, RootId: post.RootId})
+		}
+	})
+}
+
+func (a *App) publishDraftEvent(event string, draft *model.Draft) {
+	message := model.NewWebSocketEvent(event, "", "", draft.UserId, nil)
+	message.Add("draft", draft.ToJson())
+	a.Publish(message)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestUpsertDraft(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	draft, err := th.App.UpsertDraft(&model.Draft{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "draft"})
+	require.Nil(t, err)
+	assert.NotZero(t, draft.CreateAt)
+
+	_, err = th.App.UpsertDraft(&model.Draft{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "edited"})
+	require.Nil(t, err)
+
+	drafts, err := th.App.GetDraftsForUser(th.BasicUser.Id, th.BasicTeam.Id)
+	require.Nil(t, err)
+	require.Len(t, drafts, 1)
+	assert.Equal(t, "edited", drafts[0].Message)
+
+	_, err = th.App.UpsertDraft(&model.Draft{UserId: th.BasicUser.Id, ChannelId: model.NewId(), Message: "missing channel"})
+	assert.NotNil(t, err)
+
+	otherChannel := th.CreateChannel(th.BasicTeam)
+	_, err = th.App.UpsertDraft(&model.Draft{UserId: th.BasicUser.Id, ChannelId: otherChannel.Id, RootId: th.BasicPost.Id, Message: "reply elsewhere"})
+	assert.NotNil(t, err)
+
+	require.Nil(t, th.App.DeleteChannel(otherChannel, th.BasicUser.Id))
+	_, err = th.App.UpsertDraft(&model.Draft{UserId: th.BasicUser.Id, ChannelId: otherChannel.Id, Message: "archived"})
+	assert.NotNil(t, err)
+
+	require.Nil(t, th.App.DeleteDraft(th.BasicUser.Id, th.BasicChannel.Id, ""))
+	assert.NotNil(t, th.App.DeleteDraft(th.BasicUser.Id, th.BasicChannel.Id, ""))
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// claimDueItem deletes a due item before it's handled and reports whether this call deleted it.
+// Only one server handles an item this way, and an item its user removed in the meantime is
+// skipped.
+func claimDueItem(deleteItem func() (bool, *model.AppError), kind string, fields ...mlog.Field) bool {
+	deleted, err := deleteItem()
+	if err != nil {
+		mlog.Error("Failed to claim "+kind, append(fields, mlog.Err(err))...)
+		return false
+	}
+
+	return deleted
+}
@@ -6,6 +6,7 @@ package app
 import (
 	"net/http"
 
+	"github.com/mattermost/mattermost-server/einterfaces"
 	"github.com/mattermost/mattermost-server/model"
 )
 
@@ -43,3 +44,18 @@ func (a *App) PurgeElasticsearchIndexes() *model.AppError {
 
 	return nil
 }
+
+// isSearchEngineAvailable returns whether the search engine may be used for searching and
+// autocompletion. Embedded engines are always available while external ones require a license.
+func (a *App) isSearchEngineAvailable() bool {
+	if a.Elasticsearch == nil {
+		return false
+	}
+
+	if embedded, ok := a.Elasticsearch.(einterfaces.EmbeddedSearchInterface); ok && embedded.IsEmbedded() {
+		return true
+	}
+
+	license := a.License()
+	return license != nil && *license.Features.Elasticsearch
+}
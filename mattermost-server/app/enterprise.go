@@ -83,6 +83,18 @@ func RegisterJobsPluginsJobInterface(f func(*App) tjobs.PluginsJobInterface) {
 	jobsPluginsInterface = f
 }
 
+var jobsScheduledPostsInterface func(*App) tjobs.ScheduledPostsJobInterface
+
+func RegisterJobsScheduledPostsJobInterface(f func(*App) tjobs.ScheduledPostsJobInterface) {
+	jobsScheduledPostsInterface = f
+}
+
+var jobsPostRemindersInterface func(*App) tjobs.PostRemindersJobInterface
+
+func RegisterJobsPostRemindersJobInterface(f func(*App) tjobs.PostRemindersJobInterface) {
+	jobsPostRemindersInterface = f
+}
+
 var ldapInterface func(*App) einterfaces.LdapInterface
 
 func RegisterLdapInterface(f func(*App) einterfaces.LdapInterface) {
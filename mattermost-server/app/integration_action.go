@@ -14,11 +14,20 @@
 // for the relevant user, telling them to display the dialog.
 // 7. The user fills in the dialog and submits it, where SubmitInteractiveDialog will submit it back to the
 // integration for handling.
+//
+// Plugins may instead handle their interactive messages and dialogs in-process: when the integration URL is
+// model.PluginIntegrationURL of a plugin, steps 4 and 7 invoke its PostActionTriggered and DialogSubmitted hooks,
+// and the plugin opens dialogs through the plugin API. Since anybody can post an interactive message, only those
+// posted by one of the plugin's bots are routed to it. The URL of the dialogs opened by a plugin is signed along
+// with the user, callback ID and state of the dialog, so that no other dialog can be submitted to it.
 
 package app
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -27,6 +36,7 @@ import (
 	"strings"
 
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/utils"
 )
 
@@ -54,6 +64,8 @@ func (a *App) DoPostActionWithCookie(postId, actionId, userId, selectedOption st
 	datasource := ""
 	upstreamURL := ""
 	rootPostId := ""
+	authorId := ""
+	ephemeral := false
 	upstreamRequest := &model.PostActionIntegrationRequest{
 		UserId: userId,
 		PostId: postId,
@@ -85,6 +97,10 @@ func (a *App) DoPostActionWithCookie(postId, actionId, userId, selectedOption st
 		remove = cookie.RemoveProps
 		rootPostId = cookie.RootPostId
 		upstreamURL = cookie.Integration.URL
+		authorId = cookie.UserId
+
+		// Only ephemeral posts, which exist solely on the clients, are described by a cookie alone.
+		ephemeral = true
 	} else {
 		// Get action metadata from the database
 		post := result.Data.(*model.Post)
@@ -127,6 +143,7 @@ func (a *App) DoPostActionWithCookie(postId, actionId, userId, selectedOption st
 		}
 
 		upstreamURL = action.Integration.URL
+		authorId = post.UserId
 	}
 
 	if upstreamRequest.Type == model.POST_ACTION_TYPE_SELECT {
@@ -144,16 +161,20 @@ func (a *App) DoPostActionWithCookie(postId, actionId, userId, selectedOption st
 		return "", appErr
 	}
 
-	resp, appErr := a.DoActionRequest(upstreamURL, upstreamRequest.ToJson())
+	var response *model.PostActionIntegrationResponse
+	if upstreamURL == model.POST_REMINDER_INTEGRATION_URL {
+		response, appErr = a.doPostReminderAction(upstreamRequest)
+	} else if pluginId := model.PluginIdFromIntegrationURL(upstreamURL); pluginId != "" {
+		if !a.isPluginBot(pluginId, authorId) {
+			return "", model.NewAppError("DoPostAction", "api.post.do_action.plugin_author.app_error", map[string]interface{}{"PluginId": pluginId}, "post_id="+postId, http.StatusForbidden)
+		}
+		response, appErr = a.doPluginPostAction(pluginId, upstreamRequest)
+	} else {
+		response, appErr = a.doHTTPPostAction(upstreamURL, upstreamRequest)
+	}
 	if appErr != nil {
 		return "", appErr
 	}
-	defer resp.Body.Close()
-
-	var response model.PostActionIntegrationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", model.NewAppError("DoPostAction", "api.post.do_action.action_integration.app_error", nil, "err="+err.Error(), http.StatusBadRequest)
-	}
 
 	if response.Update != nil {
 		response.Update.Id = postId
@@ -169,11 +190,22 @@ func (a *App) DoPostActionWithCookie(postId, actionId, userId, selectedOption st
 				delete(response.Update.Props, key)
 			}
 		}
-		response.Update.IsPinned = originalIsPinned
-		response.Update.HasReactions = originalHasReactions
 
-		if _, appErr = a.UpdatePost(response.Update, false); appErr != nil {
-			return "", appErr
+		if ephemeral {
+			// The author of the post decides where its actions are routed, so it can't be changed.
+			response.Update.UserId = authorId
+			response.Update.ChannelId = upstreamRequest.ChannelId
+			if rootPostId != postId {
+				response.Update.RootId = rootPostId
+			}
+			a.UpdateEphemeralPost(userId, response.Update)
+		} else {
+			response.Update.IsPinned = originalIsPinned
+			response.Update.HasReactions = originalHasReactions
+
+			if _, appErr = a.UpdatePost(response.Update, false); appErr != nil {
+				return "", appErr
+			}
 		}
 	}
 
@@ -193,6 +225,60 @@ func (a *App) DoPostActionWithCookie(postId, actionId, userId, selectedOption st
 	return clientTriggerId, nil
 }
 
+func (a *App) doHTTPPostAction(upstreamURL string, upstreamRequest *model.PostActionIntegrationRequest) (*model.PostActionIntegrationResponse, *model.AppError) {
+	resp, appErr := a.DoActionRequest(upstreamURL, upstreamRequest.ToJson())
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer resp.Body.Close()
+
+	var response model.PostActionIntegrationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, model.NewAppError("DoPostAction", "api.post.do_action.action_integration.app_error", nil, "err="+err.Error(), http.StatusBadRequest)
+	}
+
+	return &response, nil
+}
+
+func (a *App) doPluginPostAction(pluginId string, upstreamRequest *model.PostActionIntegrationRequest) (*model.PostActionIntegrationResponse, *model.AppError) {
+	hooks, appErr := a.hooksForIntegration(pluginId)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	response := hooks.PostActionTriggered(a.PluginContext(), upstreamRequest)
+	if response == nil {
+		response = &model.PostActionIntegrationResponse{}
+	}
+
+	return response, nil
+}
+
+// isPluginBot returns whether the given user is a bot owned by the given plugin.
+func (a *App) isPluginBot(pluginId, userId string) bool {
+	if userId == "" {
+		return false
+	}
+
+	bot, appErr := a.GetBot(userId, false)
+	return appErr == nil && bot.OwnerId == pluginId
+}
+
+// hooksForIntegration returns the hooks of the plugin handling an interactive message or dialog.
+func (a *App) hooksForIntegration(pluginId string) (plugin.Hooks, *model.AppError) {
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return nil, model.NewAppError("hooksForIntegration", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	hooks, err := pluginsEnvironment.HooksForPlugin(pluginId)
+	if err != nil {
+		return nil, model.NewAppError("hooksForIntegration", "app.plugin.integration.plugin_inactive.app_error", map[string]interface{}{"PluginId": pluginId}, err.Error(), http.StatusBadRequest)
+	}
+
+	return hooks, nil
+}
+
 // Perform an HTTP POST request to an integration's action endpoint.
 // Caller must consume and close returned http.Response as necessary.
 func (a *App) DoActionRequest(rawURL string, body []byte) (*http.Response, *model.AppError) {
@@ -228,6 +314,12 @@ func (a *App) DoActionRequest(rawURL string, body []byte) (*http.Response, *mode
 }
 
 func (a *App) OpenInteractiveDialog(request model.OpenDialogRequest) *model.AppError {
+	return a.OpenPluginInteractiveDialog("", request)
+}
+
+// OpenPluginInteractiveDialog opens a dialog on behalf of the given plugin. Only dialogs opened by
+// a plugin may be submitted to it.
+func (a *App) OpenPluginInteractiveDialog(pluginId string, request model.OpenDialogRequest) *model.AppError {
 	clientTriggerId, userId, err := request.DecodeAndVerifyTriggerId(a.AsymmetricSigningKey())
 	if err != nil {
 		return err
@@ -235,6 +327,29 @@ func (a *App) OpenInteractiveDialog(request model.OpenDialogRequest) *model.AppE
 
 	request.TriggerId = clientTriggerId
 
+	return a.publishOpenDialog(pluginId, userId, request)
+}
+
+// OpenInteractiveDialogForUser opens a dialog on the clients of a user on behalf of the given
+// plugin, without the trigger ID of an interaction of the user. It's only available to plugins.
+func (a *App) OpenInteractiveDialogForUser(pluginId, userId string, request model.OpenDialogRequest) *model.AppError {
+	if request.URL == "" {
+		return model.NewAppError("OpenInteractiveDialogForUser", "app.open_interactive_dialog.url.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	request.TriggerId = model.NewId()
+
+	return a.publishOpenDialog(pluginId, userId, request)
+}
+
+func (a *App) publishOpenDialog(pluginId, userId string, request model.OpenDialogRequest) *model.AppError {
+	if urlPluginId := model.PluginIdFromIntegrationURL(request.URL); urlPluginId != "" {
+		if urlPluginId != pluginId {
+			return model.NewAppError("publishOpenDialog", "app.open_interactive_dialog.plugin_url.app_error", map[string]interface{}{"PluginId": urlPluginId}, "", http.StatusForbidden)
+		}
+		request.URL = a.signedPluginDialogURL(pluginId, userId, request.Dialog.CallbackId, request.Dialog.State)
+	}
+
 	jsonRequest, _ := json.Marshal(request)
 
 	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_OPEN_DIALOG, "", "", userId, nil)
@@ -244,11 +359,53 @@ func (a *App) OpenInteractiveDialog(request model.OpenDialogRequest) *model.AppE
 	return nil
 }
 
+func (a *App) pluginDialogSignature(pluginId, userId, callbackId, state string) []byte {
+	data, _ := json.Marshal([]string{pluginId, userId, callbackId, state})
+
+	mac := hmac.New(sha256.New, a.PostActionCookieSecret())
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// signedPluginDialogURL returns the URL through which the given user can submit a dialog to a
+// plugin.
+func (a *App) signedPluginDialogURL(pluginId, userId, callbackId, state string) string {
+	signature := a.pluginDialogSignature(pluginId, userId, callbackId, state)
+	return model.PluginIntegrationURL(pluginId) + "?" + url.Values{"signature": {base64.RawURLEncoding.EncodeToString(signature)}}.Encode()
+}
+
+// verifyPluginDialogURL checks that the submitted dialog was opened by the plugin for the user.
+func (a *App) verifyPluginDialogURL(rawURL, pluginId string, request model.SubmitDialogRequest) bool {
+	i := strings.Index(rawURL, "?")
+	if i < 0 {
+		return false
+	}
+
+	query, err := url.ParseQuery(rawURL[i+1:])
+	if err != nil {
+		return false
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(query.Get("signature"))
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(signature, a.pluginDialogSignature(pluginId, request.UserId, request.CallbackId, request.State))
+}
+
 func (a *App) SubmitInteractiveDialog(request model.SubmitDialogRequest) (*model.SubmitDialogResponse, *model.AppError) {
 	url := request.URL
 	request.URL = ""
 	request.Type = "dialog_submission"
 
+	if pluginId := model.PluginIdFromIntegrationURL(url); pluginId != "" {
+		if !a.verifyPluginDialogURL(url, pluginId, request) {
+			return nil, model.NewAppError("SubmitInteractiveDialog", "app.submit_interactive_dialog.plugin_signature.app_error", map[string]interface{}{"PluginId": pluginId}, "", http.StatusForbidden)
+		}
+		return a.submitPluginInteractiveDialog(pluginId, request)
+	}
+
 	b, jsonErr := json.Marshal(request)
 	if jsonErr != nil {
 		return nil, model.NewAppError("SubmitInteractiveDialog", "app.submit_interactive_dialog.json_error", nil, jsonErr.Error(), http.StatusBadRequest)
@@ -269,3 +426,17 @@ func (a *App) SubmitInteractiveDialog(request model.SubmitDialogRequest) (*model
 
 	return &response, nil
 }
+
+func (a *App) submitPluginInteractiveDialog(pluginId string, request model.SubmitDialogRequest) (*model.SubmitDialogResponse, *model.AppError) {
+	hooks, appErr := a.hooksForIntegration(pluginId)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	response := hooks.DialogSubmitted(a.PluginContext(), &request)
+	if response == nil {
+		response = &model.SubmitDialogResponse{}
+	}
+
+	return response, nil
+}
@@ -55,7 +55,7 @@ func TestPostActionInvalidURL(t *testing.T) {
 		},
 	}
 
-	post, err := th.App.CreatePostAsUser(&interactivePost, "")
+	post, err := th.App.CreatePost(interactivePost.Clone(), th.BasicChannel, false)
 	require.Nil(t, err)
 	attachments, ok := post.Props["attachments"].([]*model.SlackAttachment)
 	require.True(t, ok)
@@ -452,3 +452,184 @@ func TestSubmitInteractiveDialog(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Nil(t, resp)
 }
+
+func TestPluginPostAction(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIds, activationErrors := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) PostActionTriggered(c *plugin.Context, request *model.PostActionIntegrationRequest) *model.PostActionIntegrationResponse {
+			return &model.PostActionIntegrationResponse{
+				Update:        &model.Post{Message: "clicked " + request.Context["name"].(string)},
+				EphemeralText: "done",
+			}
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+	require.Nil(t, activationErrors[0])
+
+	bot, err := th.App.CreateBot(&model.Bot{
+		Username: "pluginbot",
+		OwnerId:  pluginIds[0],
+	})
+	require.Nil(t, err)
+
+	interactivePost := model.Post{
+		Message:   "Interactive post",
+		ChannelId: th.BasicChannel.Id,
+		UserId:    bot.UserId,
+		Props: model.StringInterface{
+			"attachments": []*model.SlackAttachment{
+				{
+					Text: "hello",
+					Actions: []*model.PostAction{
+						{
+							Integration: &model.PostActionIntegration{
+								URL:     model.PluginIntegrationURL(pluginIds[0]),
+								Context: model.StringInterface{"name": "button"},
+							},
+							Name: "action",
+							Type: model.POST_ACTION_TYPE_BUTTON,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	post, err := th.App.CreatePostAsUser(&interactivePost, "")
+	require.Nil(t, err)
+	attachments, ok := post.Props["attachments"].([]*model.SlackAttachment)
+	require.True(t, ok)
+	require.NotEmpty(t, attachments[0].Actions)
+
+	_, err = th.App.DoPostAction(post.Id, attachments[0].Actions[0].Id, th.BasicUser.Id, "")
+	require.Nil(t, err)
+
+	updatedPost, err := th.App.GetSinglePost(post.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "clicked button", updatedPost.Message)
+
+	t.Run("not posted by a bot of the plugin", func(t *testing.T) {
+		userPost := interactivePost.Clone()
+		userPost.UserId = th.BasicUser.Id
+		post, err := th.App.CreatePostAsUser(userPost, "")
+		require.Nil(t, err)
+		attachments, ok := post.Props["attachments"].([]*model.SlackAttachment)
+		require.True(t, ok)
+
+		_, err = th.App.DoPostAction(post.Id, attachments[0].Actions[0].Id, th.BasicUser.Id, "")
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	})
+
+	t.Run("inactive plugin", func(t *testing.T) {
+		attachments[0].Actions[0].Integration.URL = model.PluginIntegrationURL("missing")
+		post, err := th.App.CreatePost(&model.Post{
+			Message:   "Interactive post",
+			ChannelId: th.BasicChannel.Id,
+			UserId:    bot.UserId,
+			Props:     model.StringInterface{"attachments": attachments},
+		}, th.BasicChannel, false)
+		require.Nil(t, err)
+		attachments, ok := post.Props["attachments"].([]*model.SlackAttachment)
+		require.True(t, ok)
+
+		_, err = th.App.DoPostAction(post.Id, attachments[0].Actions[0].Id, th.BasicUser.Id, "")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPluginSubmitInteractiveDialog(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIds, activationErrors := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) DialogSubmitted(c *plugin.Context, request *model.SubmitDialogRequest) *model.SubmitDialogResponse {
+			if request.Submission["name1"] != "value1" {
+				return &model.SubmitDialogResponse{Errors: map[string]string{"name1": "unexpected value"}}
+			}
+			return &model.SubmitDialogResponse{Errors: map[string]string{"name1": request.CallbackId + " " + request.State}}
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+	require.Nil(t, activationErrors[0])
+
+	submit := model.SubmitDialogRequest{
+		URL:        th.App.signedPluginDialogURL(pluginIds[0], th.BasicUser.Id, "someid", "somestate"),
+		UserId:     th.BasicUser.Id,
+		ChannelId:  th.BasicChannel.Id,
+		TeamId:     th.BasicTeam.Id,
+		CallbackId: "someid",
+		State:      "somestate",
+		Submission: map[string]interface{}{
+			"name1": "value1",
+		},
+	}
+
+	resp, err := th.App.SubmitInteractiveDialog(submit)
+	require.Nil(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, "someid somestate", resp.Errors["name1"])
+
+	t.Run("not opened by the plugin", func(t *testing.T) {
+		unsigned := submit
+		unsigned.URL = model.PluginIntegrationURL(pluginIds[0])
+
+		_, err := th.App.SubmitInteractiveDialog(unsigned)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	})
+
+	t.Run("opened for another user", func(t *testing.T) {
+		other := submit
+		other.UserId = th.BasicUser2.Id
+
+		_, err := th.App.SubmitInteractiveDialog(other)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	})
+
+	t.Run("with another state", func(t *testing.T) {
+		other := submit
+		other.State = "otherstate"
+
+		_, err := th.App.SubmitInteractiveDialog(other)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+	})
+}
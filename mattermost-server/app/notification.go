@@ -166,6 +166,21 @@ func (a *App) SendNotifications(post *model.Post, team *model.Team, channel *mod
 		}
 	}
 
+	if len(post.RootId) > 0 && parentPostList != nil {
+		if rootPost, ok := parentPostList.Posts[post.RootId]; ok {
+			explicitlyMentionedUserIds := []string{}
+			for id, explicit := range mentionedUserIds {
+				if explicit {
+					explicitlyMentionedUserIds = append(explicitlyMentionedUserIds, id)
+				}
+			}
+
+			a.Srv.Go(func() {
+				a.updateThreadMembershipsForReply(post, rootPost, explicitlyMentionedUserIds)
+			})
+		}
+	}
+
 	mentionedUsersList := make([]string, 0, len(mentionedUserIds))
 	for id := range mentionedUserIds {
 		mentionedUsersList = append(mentionedUsersList, id)
@@ -18,6 +18,7 @@ import (
 	"github.com/mattermost/mattermost-server/einterfaces"
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/services/openid"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/mattermost/mattermost-server/utils"
 )
@@ -643,8 +644,11 @@ func (a *App) GetOAuthStateToken(token string) (*model.Token, *model.AppError) {
 }
 
 func (a *App) GetAuthorizationCode(w http.ResponseWriter, r *http.Request, service string, props map[string]string, loginHint string) (string, *model.AppError) {
-	sso := a.Config().GetSSOService(service)
-	if sso == nil || !*sso.Enable {
+	sso, appErr := a.getSSOSettings(service)
+	if appErr != nil {
+		return "", appErr
+	}
+	if sso == nil {
 		return "", model.NewAppError("GetAuthorizationCode", "api.user.get_authorization_code.unsupported.app_error", nil, "service="+service, http.StatusNotImplemented)
 	}
 
@@ -674,6 +678,21 @@ func (a *App) GetAuthorizationCode(w http.ResponseWriter, r *http.Request, servi
 	scope := *sso.Scope
 
 	tokenExtra := generateOAuthStateTokenExtra(props["email"], props["action"], cookieValue)
+
+	// The OpenID Connect nonce and PKCE code verifier are kept with the state token, so that they
+	// can't be tampered with, and the code verifier never leaves the server.
+	nonce := ""
+	codeVerifier := ""
+	if service == model.SERVICE_OPENID {
+		nonce = model.NewId()
+
+		if a.usePKCE() {
+			codeVerifier = openid.NewCodeVerifier()
+		}
+
+		tokenExtra += ":" + nonce + ":" + codeVerifier
+	}
+
 	stateToken, err := a.CreateOAuthStateToken(tokenExtra)
 	if err != nil {
 		return "", err
@@ -699,12 +718,23 @@ func (a *App) GetAuthorizationCode(w http.ResponseWriter, r *http.Request, servi
 		authUrl += "&login_hint=" + utils.UrlEncode(loginHint)
 	}
 
+	if len(nonce) > 0 {
+		authUrl += "&nonce=" + url.QueryEscape(nonce)
+	}
+
+	if len(codeVerifier) > 0 {
+		authUrl += "&code_challenge=" + openid.CodeChallenge(codeVerifier) + "&code_challenge_method=S256"
+	}
+
 	return authUrl, nil
 }
 
 func (a *App) AuthorizeOAuthUser(w http.ResponseWriter, r *http.Request, service, code, state, redirectUri string) (io.ReadCloser, string, map[string]string, *model.AppError) {
-	sso := a.Config().GetSSOService(service)
-	if sso == nil || !*sso.Enable {
+	sso, appErr := a.getSSOSettings(service)
+	if appErr != nil {
+		return nil, "", nil, appErr
+	}
+	if sso == nil {
 		return nil, "", nil, model.NewAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.unsupported.app_error", nil, "service="+service, http.StatusNotImplemented)
 	}
 
@@ -734,6 +764,18 @@ func (a *App) AuthorizeOAuthUser(w http.ResponseWriter, r *http.Request, service
 	}
 
 	expectedTokenExtra := generateOAuthStateTokenExtra(stateEmail, stateAction, cookie.Value)
+
+	nonce := ""
+	codeVerifier := ""
+	if service == model.SERVICE_OPENID && strings.HasPrefix(expectedToken.Extra, expectedTokenExtra+":") {
+		openIdExtra := strings.SplitN(strings.TrimPrefix(expectedToken.Extra, expectedTokenExtra+":"), ":", 2)
+		if len(openIdExtra) == 2 {
+			nonce = openIdExtra[0]
+			codeVerifier = openIdExtra[1]
+			expectedTokenExtra = expectedToken.Extra
+		}
+	}
+
 	if expectedTokenExtra != expectedToken.Extra {
 		return nil, "", stateProps, model.NewAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.invalid_state.app_error", nil, "", http.StatusBadRequest)
 	}
@@ -763,6 +805,9 @@ func (a *App) AuthorizeOAuthUser(w http.ResponseWriter, r *http.Request, service
 	p.Set("code", code)
 	p.Set("grant_type", model.ACCESS_TOKEN_GRANT_TYPE)
 	p.Set("redirect_uri", redirectUri)
+	if len(codeVerifier) > 0 {
+		p.Set("code_verifier", codeVerifier)
+	}
 
 	req, requestErr := http.NewRequest("POST", *sso.TokenEndpoint, strings.NewReader(p.Encode()))
 	if requestErr != nil {
@@ -772,7 +817,9 @@ func (a *App) AuthorizeOAuthUser(w http.ResponseWriter, r *http.Request, service
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := a.HTTPService.MakeClient(true).Do(req)
+	// The OpenID Connect token endpoint comes from the provider's discovery document rather than
+	// from the configuration, so it isn't trusted.
+	resp, err := a.HTTPService.MakeClient(service != model.SERVICE_OPENID).Do(req)
 	if err != nil {
 		return nil, "", stateProps, model.NewAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.token_failed.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
@@ -794,6 +841,16 @@ func (a *App) AuthorizeOAuthUser(w http.ResponseWriter, r *http.Request, service
 		return nil, "", stateProps, model.NewAppError("AuthorizeOAuthUser", "api.user.authorize_oauth_user.missing.app_error", nil, "response_body="+buf.String(), http.StatusInternalServerError)
 	}
 
+	if service == model.SERVICE_OPENID {
+		body, appErr := a.getOpenIdUser(buf.Bytes(), ar.AccessToken, nonce)
+		if appErr != nil {
+			return nil, "", stateProps, appErr
+		}
+
+		// Note that body is not closed here, so it must be closed by the caller
+		return body, teamId, stateProps, nil
+	}
+
 	p = url.Values{}
 	p.Set("access_token", ar.AccessToken)
 	req, requestErr = http.NewRequest("GET", *sso.UserApiEndpoint, strings.NewReader(""))
@@ -0,0 +1,121 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+	oauthopenid "github.com/mattermost/mattermost-server/model/openid"
+	"github.com/mattermost/mattermost-server/services/openid"
+)
+
+// getOpenIdProvider returns the configured OpenID Connect provider, keeping its discovery document
+// and signing keys cached until the discovery endpoint changes. The endpoints it talks to come from
+// the discovery document, so its requests are subject to the untrusted internal connection rules.
+func (a *App) getOpenIdProvider() *openid.Provider {
+	endpoint := *a.Config().OpenIdSettings.DiscoveryEndpoint
+
+	a.Srv.openIdProviderMutex.Lock()
+	defer a.Srv.openIdProviderMutex.Unlock()
+
+	if a.Srv.openIdProvider == nil || a.Srv.openIdProvider.DiscoveryEndpoint() != endpoint {
+		a.Srv.openIdProvider = openid.NewProvider(a.HTTPService.MakeClient(false), endpoint)
+	}
+
+	return a.Srv.openIdProvider
+}
+
+// getSSOSettings returns the settings of the given OAuth login service, or nil if it isn't enabled.
+// The endpoints of the OpenID Connect provider come from its discovery document.
+func (a *App) getSSOSettings(service string) (*model.SSOSettings, *model.AppError) {
+	if service != model.SERVICE_OPENID {
+		sso := a.Config().GetSSOService(service)
+		if sso == nil || !*sso.Enable {
+			return nil, nil
+		}
+		return sso, nil
+	}
+
+	settings := a.Config().OpenIdSettings
+	if !*settings.Enable {
+		return nil, nil
+	}
+
+	document, err := a.getOpenIdProvider().Discover()
+	if err != nil {
+		return nil, model.NewAppError("getSSOSettings", "api.user.openid.discovery.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return &model.SSOSettings{
+		Enable:          settings.Enable,
+		Secret:          settings.Secret,
+		Id:              settings.Id,
+		Scope:           settings.Scope,
+		AuthEndpoint:    model.NewString(document.AuthorizationEndpoint),
+		TokenEndpoint:   model.NewString(document.TokenEndpoint),
+		UserApiEndpoint: model.NewString(document.UserinfoEndpoint),
+	}, nil
+}
+
+// usePKCE reports whether authorization requests to the OpenID Connect provider should carry a
+// PKCE code challenge.
+func (a *App) usePKCE() bool {
+	if !*a.Config().OpenIdSettings.EnablePKCE {
+		return false
+	}
+
+	document, err := a.getOpenIdProvider().Discover()
+	return err == nil && document.SupportsPKCE()
+}
+
+// getOpenIdUser validates the ID token returned by the OpenID Connect provider along with the access
+// token, completes its claims from the user info endpoint and maps them to the user data expected
+// by the OpenID OAuth provider.
+func (a *App) getOpenIdUser(tokenResponse []byte, accessToken, nonce string) (io.ReadCloser, *model.AppError) {
+	settings := a.Config().OpenIdSettings
+	provider := a.getOpenIdProvider()
+
+	var response struct {
+		IdToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(tokenResponse, &response); err != nil || response.IdToken == "" {
+		return nil, model.NewAppError("getOpenIdUser", "api.user.openid.missing_id_token.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	claims, err := provider.VerifyIdToken(response.IdToken, *settings.Id, nonce)
+	if err != nil {
+		return nil, model.NewAppError("getOpenIdUser", "api.user.openid.invalid_id_token.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	userInfo, err := provider.UserInfo(accessToken)
+	if err != nil {
+		return nil, model.NewAppError("getOpenIdUser", "api.user.authorize_oauth_user.service.app_error", map[string]interface{}{"Service": model.SERVICE_OPENID}, err.Error(), http.StatusInternalServerError)
+	}
+
+	if userInfo != nil {
+		// The user info must describe the user the ID token was issued for, or it could have been
+		// substituted. See section 5.3.2 of OpenID Connect Core.
+		if userInfo["sub"] != claims["sub"] {
+			return nil, model.NewAppError("getOpenIdUser", "api.user.openid.subject_mismatch.app_error", nil, "", http.StatusUnauthorized)
+		}
+
+		for name, value := range userInfo {
+			if _, ok := claims[name]; !ok {
+				claims[name] = value
+			}
+		}
+	}
+
+	user := oauthopenid.UserFromClaims(claims, &settings)
+	if !user.IsValid() {
+		return nil, model.NewAppError("getOpenIdUser", "api.user.openid.missing_claims.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return ioutil.NopCloser(strings.NewReader(user.ToJson())), nil
+}
@@ -4,6 +4,7 @@
 package app
 
 import (
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -56,8 +57,9 @@ func (a *App) SyncPluginsActiveState() {
 			return
 		}
 
-		// Deactivate any plugins that have been disabled.
-		for _, plugin := range pluginsEnvironment.Active() {
+		// Deactivate any plugins that have been disabled, along with the plugins requiring them.
+		activePlugins := pluginsEnvironment.Active()
+		for _, plugin := range activePlugins {
 			// Determine if plugin is enabled
 			pluginId := plugin.Manifest.Id
 			pluginEnabled := false
@@ -67,16 +69,19 @@ func (a *App) SyncPluginsActiveState() {
 
 			// If it's not enabled we need to deactivate it
 			if !pluginEnabled {
-				deactivated := pluginsEnvironment.Deactivate(pluginId)
-				if deactivated && plugin.Manifest.HasClient() {
-					message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_DISABLED, "", "", "", nil)
-					message.Add("manifest", plugin.Manifest.ClientManifest())
-					a.Publish(message)
-				}
+				pluginsEnvironment.Deactivate(pluginId)
+			}
+		}
+
+		for _, plugin := range activePlugins {
+			if !pluginsEnvironment.IsActive(plugin.Manifest.Id) && plugin.Manifest.HasClient() {
+				message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_PLUGIN_DISABLED, "", "", "", nil)
+				message.Add("manifest", plugin.Manifest.ClientManifest())
+				a.Publish(message)
 			}
 		}
 
-		// Activate any plugins that have been enabled
+		// Activate any plugins that have been enabled, each after the plugins it requires
 		for _, plugin := range availablePlugins {
 			if plugin.Manifest == nil {
 				plugin.WrapLogger(a.Log).Error("Plugin manifest could not be loaded", mlog.Err(plugin.ManifestError))
@@ -144,6 +149,7 @@ func (a *App) InitPlugins(pluginDir, webappPluginDir string) {
 		mlog.Error("Failed to start up plugins", mlog.Err(err))
 		return
 	}
+	env.SetResourceLimits(a.pluginResourceLimits)
 	a.SetPluginsEnvironment(env)
 
 	prepackagedPluginsDir, found := fileutils.FindDir("prepackaged_plugins")
@@ -153,9 +159,20 @@ func (a *App) InitPlugins(pluginDir, webappPluginDir string) {
 				return nil
 			}
 
-			if fileReader, err := os.Open(walkPath); err != nil {
+			bundle, err := ioutil.ReadFile(walkPath)
+			if err != nil {
 				mlog.Error("Failed to open prepackaged plugin", mlog.Err(err), mlog.String("path", walkPath))
-			} else if _, err := a.InstallPlugin(fileReader, true); err != nil {
+				return nil
+			}
+
+			// Prepackaged plugins are signed like any other plugin, with the signature alongside.
+			signature, err := ioutil.ReadFile(walkPath + PLUGIN_SIGNATURE_EXTENSION)
+			if err != nil {
+				signature = nil
+			}
+
+			// Every server ships with the prepackaged plugins, so they aren't kept in the file store.
+			if _, err := a.installPluginLocally(bundle, signature, true); err != nil {
 				mlog.Error("Failed to unpack prepackaged plugin", mlog.Err(err), mlog.String("path", walkPath))
 			}
 
@@ -165,6 +182,12 @@ func (a *App) InitPlugins(pluginDir, webappPluginDir string) {
 		}
 	}
 
+	a.verifyInstalledPlugins()
+
+	if err := a.SyncPluginsFromFileStore(); err != nil {
+		mlog.Error("Failed to sync plugins from the file store", mlog.Err(err))
+	}
+
 	// Sync plugin active state when config changes. Also notify plugins.
 	a.Srv.PluginsLock.Lock()
 	a.RemoveConfigListener(a.Srv.PluginConfigListenerId)
@@ -324,3 +347,15 @@ func (a *App) GetPlugins() (*model.PluginsResponse, *model.AppError) {
 
 	return resp, nil
 }
+
+// pluginResourceLimits returns the resource limits of the plugin with the given id, as configured.
+func (a *App) pluginResourceLimits(id string) plugin.ResourceLimits {
+	settings := a.Config().PluginSettings
+	limits := settings.ResourceLimitsForPlugin(id)
+
+	return plugin.ResourceLimits{
+		MemoryBytes: int64(*limits.MaxMemoryMB) * 1024 * 1024,
+		CPUPercent:  *limits.MaxCPUPercent,
+		CgroupPath:  *settings.ResourceLimitsCgroupPath,
+	}
+}
@@ -589,7 +589,17 @@ func (api *PluginAPI) SetTeamIcon(teamId string, data []byte) *model.AppError {
 }
 
 func (api *PluginAPI) OpenInteractiveDialog(dialog model.OpenDialogRequest) *model.AppError {
-	return api.app.OpenInteractiveDialog(dialog)
+	if dialog.URL == "" {
+		dialog.URL = model.PluginIntegrationURL(api.id)
+	}
+	return api.app.OpenPluginInteractiveDialog(api.id, dialog)
+}
+
+func (api *PluginAPI) OpenInteractiveDialogForUser(userId string, dialog model.OpenDialogRequest) *model.AppError {
+	if dialog.URL == "" {
+		dialog.URL = model.PluginIntegrationURL(api.id)
+	}
+	return api.app.OpenInteractiveDialogForUser(api.id, userId, dialog)
 }
 
 func (api *PluginAPI) RemoveTeamIcon(teamId string) *model.AppError {
@@ -656,6 +666,22 @@ func (api *PluginAPI) GetPluginStatus(id string) (*model.PluginStatus, *model.Ap
 	return api.app.GetPluginStatus(id)
 }
 
+func (api *PluginAPI) CallPlugin(pluginId, method string, payload []byte) ([]byte, *model.AppError) {
+	return api.app.CallPlugin(api.id, pluginId, method, payload)
+}
+
+func (api *PluginAPI) RegisterScheduledJob(job *model.PluginScheduledJob) *model.AppError {
+	return api.app.RegisterPluginScheduledJob(api.id, job)
+}
+
+func (api *PluginAPI) UnregisterScheduledJob(name string) *model.AppError {
+	return api.app.UnregisterPluginScheduledJob(api.id, name)
+}
+
+func (api *PluginAPI) GetScheduledJobs() ([]*model.PluginScheduledJob, *model.AppError) {
+	return api.app.GetPluginScheduledJobs(api.id)
+}
+
 // KV Store Section
 
 func (api *PluginAPI) KVSet(key string, value []byte) *model.AppError {
@@ -666,6 +692,14 @@ func (api *PluginAPI) KVCompareAndSet(key string, oldValue, newValue []byte) (bo
 	return api.app.CompareAndSetPluginKey(api.id, key, oldValue, newValue)
 }
 
+func (api *PluginAPI) KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError) {
+	return api.app.CompareAndDeletePluginKey(api.id, key, oldValue)
+}
+
+func (api *PluginAPI) KVSetMany(values map[string][]byte) *model.AppError {
+	return api.app.SetPluginKeys(api.id, values)
+}
+
 func (api *PluginAPI) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError {
 	return api.app.SetPluginKeyWithExpiry(api.id, key, value, expireInSeconds)
 }
@@ -678,6 +712,10 @@ func (api *PluginAPI) KVDelete(key string) *model.AppError {
 	return api.app.DeletePluginKey(api.id, key)
 }
 
+func (api *PluginAPI) KVDeleteMany(keys []string) *model.AppError {
+	return api.app.DeletePluginKeys(api.id, keys)
+}
+
 func (api *PluginAPI) KVDeleteAll() *model.AppError {
 	return api.app.DeleteAllKeysForPlugin(api.id)
 }
@@ -686,6 +724,10 @@ func (api *PluginAPI) KVList(page, perPage int) ([]string, *model.AppError) {
 	return api.app.ListPluginKeys(api.id, page, perPage)
 }
 
+func (api *PluginAPI) KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError) {
+	return api.app.ListPluginKeysWithPrefix(api.id, prefix, page, perPage)
+}
+
 func (api *PluginAPI) PublishWebSocketEvent(event string, payload map[string]interface{}, broadcast *model.WebsocketBroadcast) {
 	api.app.Publish(&model.WebSocketEvent{
 		Event:     fmt.Sprintf("custom_%v_%v", api.id, event),
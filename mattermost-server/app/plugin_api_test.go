@@ -12,6 +12,7 @@ import (
 	"image/color"
 	"image/png"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -756,6 +757,74 @@ func TestPluginAPIKVCompareAndSet(t *testing.T) {
 	}
 }
 
+func TestPluginAPIKVCompareAndDelete(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	require.Nil(t, api.KVSet("key", []byte("value")))
+
+	// Attempt delete using an incorrect old value
+	deleted, err := api.KVCompareAndDelete("key", []byte("incorrect"))
+	require.Nil(t, err)
+	require.False(t, deleted)
+
+	value, err := api.KVGet("key")
+	require.Nil(t, err)
+	require.Equal(t, []byte("value"), value)
+
+	// Delete using the correct old value
+	deleted, err = api.KVCompareAndDelete("key", []byte("value"))
+	require.Nil(t, err)
+	require.True(t, deleted)
+
+	value, err = api.KVGet("key")
+	require.Nil(t, err)
+	require.Nil(t, value)
+
+	// Attempt delete of a non-existent key
+	deleted, err = api.KVCompareAndDelete("key", []byte("value"))
+	require.Nil(t, err)
+	require.False(t, deleted)
+}
+
+func TestPluginAPIKVMany(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+	api := th.SetupPluginAPI()
+
+	require.Nil(t, api.KVSetMany(map[string][]byte{
+		"user_1": []byte("one"),
+		"user_2": []byte("two"),
+		"team_1": []byte("team"),
+	}))
+
+	value, err := api.KVGet("user_2")
+	require.Nil(t, err)
+	assert.Equal(t, []byte("two"), value)
+
+	keys, err := api.KVListWithPrefix("user_", 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"user_1", "user_2"}, keys)
+
+	require.Nil(t, api.KVDeleteMany([]string{"user_1", "team_1"}))
+
+	keys, err = api.KVList(0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"user_2"}, keys)
+
+	t.Run("nothing is set if a key is invalid", func(t *testing.T) {
+		assert.NotNil(t, api.KVSetMany(map[string][]byte{
+			"valid": []byte("value"),
+			"":      []byte("invalid"),
+		}))
+
+		value, err := api.KVGet("valid")
+		require.Nil(t, err)
+		assert.Nil(t, value)
+	})
+}
+
 func TestPluginCreateBot(t *testing.T) {
 	th := Setup(t)
 	defer th.TearDown()
@@ -777,3 +846,84 @@ func TestPluginCreateBot(t *testing.T) {
 	require.NotNil(t, err)
 
 }
+
+func TestPluginAPICallPlugin(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	setupPluginApiTest(t,
+		`
+		package main
+
+		import (
+			"fmt"
+
+			"github.com/mattermost/mattermost-server/model"
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) OnPluginCall(c *plugin.Context, sourcePluginId, method string, payload []byte) ([]byte, *model.AppError) {
+			if method == "fail" {
+				return nil, model.NewAppError("OnPluginCall", "failed", nil, "", 400)
+			}
+			return []byte(fmt.Sprintf("%s:%s:%s", sourcePluginId, method, payload)), nil
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+		`,
+		`{"id": "testcallplugin", "backend": {"executable": "backend.exe", "exports": ["echo", "fail"]}}`, "testcallplugin", th.App)
+
+	api := th.App.NewPluginAPI(&model.Manifest{Id: "caller"})
+
+	result, err := api.CallPlugin("testcallplugin", "echo", []byte("hello"))
+	require.Nil(t, err)
+	assert.Equal(t, "caller:echo:hello", string(result))
+
+	_, err = api.CallPlugin("testcallplugin", "fail", nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "failed", err.Id)
+
+	_, err = api.CallPlugin("testcallplugin", "secret", nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.call.not_exported.app_error", err.Id)
+
+	_, err = api.CallPlugin("unknown", "echo", nil)
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.call.not_active.app_error", err.Id)
+}
+
+func TestPluginAPICallPluginNotImplemented(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	setupPluginApiTest(t,
+		`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+		`,
+		`{"id": "testcallplugin", "backend": {"executable": "backend.exe", "exports": ["echo"]}}`, "testcallplugin", th.App)
+
+	api := th.App.NewPluginAPI(&model.Manifest{Id: "caller"})
+
+	_, err := api.CallPlugin("testcallplugin", "echo", []byte("hello"))
+	require.NotNil(t, err)
+	assert.Equal(t, "app.plugin.call.not_implemented.app_error", err.Id)
+	assert.Equal(t, http.StatusNotImplemented, err.StatusCode)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/plugin"
+)
+
+// CallPlugin invokes a method exported by the plugin with the given id on behalf of another plugin.
+// The call is only routed to the plugin if it is active and lists the method in its manifest.
+func (a *App) CallPlugin(sourcePluginId, pluginId, method string, payload []byte) ([]byte, *model.AppError) {
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return nil, model.NewAppError("CallPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	var manifest *model.Manifest
+	for _, info := range pluginsEnvironment.Active() {
+		if info.Manifest != nil && info.Manifest.Id == pluginId {
+			manifest = info.Manifest
+			break
+		}
+	}
+
+	if manifest == nil {
+		return nil, model.NewAppError("CallPlugin", "app.plugin.call.not_active.app_error", map[string]interface{}{"PluginId": pluginId}, "", http.StatusNotFound)
+	}
+
+	if !manifest.ExportsMethod(method) {
+		return nil, model.NewAppError("CallPlugin", "app.plugin.call.not_exported.app_error", map[string]interface{}{"PluginId": pluginId, "Method": method}, "", http.StatusForbidden)
+	}
+
+	// The RPC client can't tell a missing hook from a call that returned nothing.
+	if !pluginsEnvironment.PluginImplements(pluginId, plugin.OnPluginCallId) {
+		return nil, model.NewAppError("CallPlugin", "app.plugin.call.not_implemented.app_error", map[string]interface{}{"PluginId": pluginId}, "", http.StatusNotImplemented)
+	}
+
+	hooks, err := pluginsEnvironment.HooksForPlugin(pluginId)
+	if err != nil {
+		return nil, model.NewAppError("CallPlugin", "app.plugin.call.not_active.app_error", map[string]interface{}{"PluginId": pluginId}, err.Error(), http.StatusNotFound)
+	}
+
+	mlog.Debug("Calling plugin method", mlog.String("source_plugin_id", sourcePluginId), mlog.String("plugin_id", pluginId), mlog.String("method", method))
+
+	return hooks.OnPluginCall(a.PluginContext(), sourcePluginId, method, payload)
+}
@@ -852,6 +852,296 @@ func TestUserHasBeenCreated(t *testing.T) {
 	require.Equal(t, "plugin-callback-success", user.Nickname)
 }
 
+func TestReactionHooks(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIds, _ := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) ReactionHasBeenAdded(c *plugin.Context, reaction *model.Reaction) {
+			p.API.KVSet("added", []byte(reaction.EmojiName))
+		}
+
+		func (p *MyPlugin) ReactionHasBeenRemoved(c *plugin.Context, reaction *model.Reaction) {
+			p.API.KVSet("removed", []byte(reaction.EmojiName))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+
+	reaction := &model.Reaction{
+		UserId:    th.BasicUser.Id,
+		PostId:    th.BasicPost.Id,
+		EmojiName: "smile",
+	}
+	_, err := th.App.SaveReactionForPost(reaction)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err := th.App.GetPluginKey(pluginIds[0], "added")
+	require.Nil(t, err)
+	assert.Equal(t, "smile", string(value))
+
+	err = th.App.DeleteReactionForPost(reaction)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err = th.App.GetPluginKey(pluginIds[0], "removed")
+	require.Nil(t, err)
+	assert.Equal(t, "smile", string(value))
+}
+
+func TestChannelHooks(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIds, _ := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) ChannelHasBeenUpdated(c *plugin.Context, newChannel, oldChannel *model.Channel) {
+			p.API.KVSet("updated", []byte(oldChannel.Header+" -> "+newChannel.Header))
+		}
+
+		func (p *MyPlugin) ChannelHasBeenArchived(c *plugin.Context, channel *model.Channel, actor *model.User) {
+			if channel.DeleteAt > 0 && actor != nil {
+				p.API.KVSet("archived", []byte(channel.Id+" by "+actor.Id))
+			}
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+
+	channel := th.CreateChannel(th.BasicTeam)
+	oldHeader := channel.Header
+
+	header := "new header"
+	_, err := th.App.PatchChannel(channel, &model.ChannelPatch{Header: &header}, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err := th.App.GetPluginKey(pluginIds[0], "updated")
+	require.Nil(t, err)
+	assert.Equal(t, oldHeader+" -> new header", string(value))
+
+	err = th.App.DeleteChannel(channel, th.BasicUser.Id)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err = th.App.GetPluginKey(pluginIds[0], "archived")
+	require.Nil(t, err)
+	assert.Equal(t, channel.Id+" by "+th.BasicUser.Id, string(value))
+}
+
+func TestUserHooks(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	tearDown, pluginIds, _ := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) UserHasBeenUpdated(c *plugin.Context, newUser, oldUser *model.User) {
+			p.API.KVSet("nickname", []byte(oldUser.Nickname+" -> "+newUser.Nickname))
+			p.API.KVSet("roles", []byte(oldUser.Roles+" -> "+newUser.Roles))
+		}
+
+		func (p *MyPlugin) UserHasBeenDeactivated(c *plugin.Context, user *model.User) {
+			if user.DeleteAt > 0 {
+				p.API.KVSet("deactivated", []byte(user.Id))
+			}
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+
+	user := th.CreateUser()
+	oldNickname := user.Nickname
+
+	user.Nickname = "updated nickname"
+	_, err := th.App.UpdateUser(user, false)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err := th.App.GetPluginKey(pluginIds[0], "nickname")
+	require.Nil(t, err)
+	assert.Equal(t, oldNickname+" -> updated nickname", string(value))
+
+	_, err = th.App.UpdateUserRoles(user.Id, model.SYSTEM_USER_ROLE_ID+" "+model.SYSTEM_ADMIN_ROLE_ID, false)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err = th.App.GetPluginKey(pluginIds[0], "roles")
+	require.Nil(t, err)
+	assert.Equal(t, model.SYSTEM_USER_ROLE_ID+" -> "+model.SYSTEM_USER_ROLE_ID+" "+model.SYSTEM_ADMIN_ROLE_ID, string(value))
+
+	err = th.App.UpdateUserActive(user.Id, false)
+	require.Nil(t, err)
+
+	time.Sleep(1 * time.Second)
+
+	value, err = th.App.GetPluginKey(pluginIds[0], "deactivated")
+	require.Nil(t, err)
+	assert.Equal(t, user.Id, string(value))
+}
+
+func TestHookCommandWillBeExecuted(t *testing.T) {
+	pluginCode := `
+		package main
+
+		import (
+			"strings"
+
+			"github.com/mattermost/mattermost-server/plugin"
+			"github.com/mattermost/mattermost-server/model"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) CommandWillBeExecuted(c *plugin.Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+			if strings.HasPrefix(args.Command, "/leave") {
+				return nil, "leaving is not allowed"
+			}
+			if strings.HasPrefix(args.Command, "/shout ") {
+				args.Command = "/me " + strings.ToUpper(strings.TrimPrefix(args.Command, "/shout "))
+				return args, ""
+			}
+			if strings.HasPrefix(args.Command, "/impersonate ") {
+				args.Command = "/me " + strings.TrimPrefix(args.Command, "/impersonate ")
+				args.UserId = "USER_ID"
+				return args, ""
+			}
+			if strings.HasPrefix(args.Command, "/move ") {
+				args.Command = "/me " + strings.TrimPrefix(args.Command, "/move ")
+				args.ChannelId = "CHANNEL_ID"
+				return args, ""
+			}
+			return nil, ""
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`
+
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	otherChannel := th.createChannelWithAnotherUser(th.BasicTeam, model.CHANNEL_PRIVATE, th.BasicUser2.Id)
+	pluginCode = strings.Replace(pluginCode, "USER_ID", th.BasicUser2.Id, 1)
+	pluginCode = strings.Replace(pluginCode, "CHANNEL_ID", otherChannel.Id, 1)
+
+	tearDown, _, _ := SetAppEnvironmentWithPlugins(t, []string{pluginCode}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+
+	session, err := th.App.CreateSession(&model.Session{UserId: th.BasicUser.Id, Roles: th.BasicUser.GetRawRoles()})
+	require.Nil(t, err)
+
+	newArgs := func(command string) *model.CommandArgs {
+		return &model.CommandArgs{
+			Command:   command,
+			UserId:    th.BasicUser.Id,
+			ChannelId: th.BasicChannel.Id,
+			TeamId:    th.BasicTeam.Id,
+			T:         utils.T,
+			Session:   *session,
+		}
+	}
+
+	t.Run("rejected", func(t *testing.T) {
+		_, err := th.App.ExecuteCommand(newArgs("/leave"))
+		if assert.NotNil(t, err) {
+			assert.Equal(t, "api.command.execute_command.rejected_by_plugin.app_error", err.Id)
+		}
+
+		_, err = th.App.GetChannelMember(th.BasicChannel.Id, th.BasicUser.Id)
+		assert.Nil(t, err)
+	})
+
+	t.Run("rewritten", func(t *testing.T) {
+		response, err := th.App.ExecuteCommand(newArgs("/shout hello"))
+		require.Nil(t, err)
+		assert.Equal(t, "*HELLO*", response.Text)
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		response, err := th.App.ExecuteCommand(newArgs("/me hello"))
+		require.Nil(t, err)
+		assert.Equal(t, "*hello*", response.Text)
+	})
+
+	t.Run("user can't be changed", func(t *testing.T) {
+		_, err := th.App.ExecuteCommand(newArgs("/impersonate hello"))
+		require.Nil(t, err)
+
+		posts, err := th.App.GetPostsPage(th.BasicChannel.Id, 0, 1)
+		require.Nil(t, err)
+		require.Len(t, posts.Order, 1)
+		assert.Equal(t, th.BasicUser.Id, posts.Posts[posts.Order[0]].UserId)
+	})
+
+	t.Run("moved to a channel without permission", func(t *testing.T) {
+		_, err := th.App.ExecuteCommand(newArgs("/move hello"))
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+		posts, err := th.App.GetPostsPage(otherChannel.Id, 0, 10)
+		require.Nil(t, err)
+		for _, post := range posts.Posts {
+			assert.NotEqual(t, "*hello*", post.Message)
+		}
+	})
+}
+
 func TestErrorString(t *testing.T) {
 	th := Setup(t).InitBasic()
 	defer th.TearDown()
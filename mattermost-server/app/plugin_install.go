@@ -4,6 +4,7 @@
 package app
 
 import (
+	"bytes"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -16,44 +17,64 @@ import (
 	"github.com/mattermost/mattermost-server/utils"
 )
 
-// InstallPlugin unpacks and installs a plugin but does not enable or activate it.
+// InstallPlugin unpacks and installs a plugin but does not enable or activate it. The bundle is kept
+// in the file store so that every server in the cluster installs it as well.
 func (a *App) InstallPlugin(pluginFile io.Reader, replace bool) (*model.Manifest, *model.AppError) {
-	return a.installPlugin(pluginFile, replace)
+	return a.installPlugin(pluginFile, nil, replace)
 }
 
-func (a *App) installPlugin(pluginFile io.Reader, replace bool) (*model.Manifest, *model.AppError) {
-	pluginsEnvironment := a.GetPluginsEnvironment()
-	if pluginsEnvironment == nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
-	}
+// InstallPluginWithSignature unpacks and installs a plugin after verifying its detached signature,
+// but does not enable or activate it. The bundle is kept in the file store so that every server in
+// the cluster installs it as well.
+func (a *App) InstallPluginWithSignature(pluginFile, signature io.Reader, replace bool) (*model.Manifest, *model.AppError) {
+	return a.installPlugin(pluginFile, signature, replace)
+}
 
-	tmpDir, err := ioutil.TempDir("", "plugintmp")
+func (a *App) installPlugin(pluginFile, signature io.Reader, replace bool) (*model.Manifest, *model.AppError) {
+	bundle, err := ioutil.ReadAll(pluginFile)
 	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+		return nil, model.NewAppError("installPlugin", "app.plugin.extract.app_error", nil, err.Error(), http.StatusBadRequest)
 	}
-	defer os.RemoveAll(tmpDir)
 
-	if err = utils.ExtractTarGz(pluginFile, tmpDir); err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.extract.app_error", nil, err.Error(), http.StatusBadRequest)
+	var signatureData []byte
+	if signature != nil {
+		if signatureData, err = ioutil.ReadAll(signature); err != nil {
+			return nil, model.NewAppError("installPlugin", "app.plugin.signature.invalid.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
 	}
 
-	tmpPluginDir := tmpDir
-	dir, err := ioutil.ReadDir(tmpDir)
-	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	manifest, appErr := a.installPluginLocally(bundle, signatureData, replace)
+	if appErr != nil {
+		return nil, appErr
 	}
 
-	if len(dir) == 1 && dir[0].IsDir() {
-		tmpPluginDir = filepath.Join(tmpPluginDir, dir[0].Name())
+	if appErr = a.storePluginBundle(manifest, bundle, signatureData); appErr != nil {
+		if err := a.removePluginLocally(manifest.Id); err != nil {
+			mlog.Error("Failed to remove plugin that couldn't be stored", mlog.String("plugin_id", manifest.Id), mlog.Err(err))
+		}
+		return nil, appErr
 	}
 
-	manifest, _, err := model.FindManifest(tmpPluginDir)
-	if err != nil {
-		return nil, model.NewAppError("installPlugin", "app.plugin.manifest.app_error", nil, err.Error(), http.StatusBadRequest)
+	a.notifyClusterPluginsChanged()
+
+	return manifest, nil
+}
+
+// installPluginLocally unpacks and installs a plugin on this server only.
+func (a *App) installPluginLocally(bundle, signatureData []byte, replace bool) (*model.Manifest, *model.AppError) {
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return nil, model.NewAppError("installPlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
 	}
 
-	if !plugin.IsValidId(manifest.Id) {
-		return nil, model.NewAppError("installPlugin", "app.plugin.invalid_id.app_error", map[string]interface{}{"Min": plugin.MinIdLength, "Max": plugin.MaxIdLength, "Regex": plugin.ValidIdRegex}, "", http.StatusBadRequest)
+	manifest, tmpDir, tmpPluginDir, appErr := extractPluginBundle(bundle)
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if appErr := a.verifyPluginSignature(manifest.Id, bundle, signatureData); appErr != nil {
+		return nil, appErr
 	}
 
 	// Stash the previous state of the plugin, if available
@@ -71,7 +92,7 @@ func (a *App) installPlugin(pluginFile io.Reader, replace bool) (*model.Manifest
 				return nil, model.NewAppError("installPlugin", "app.plugin.install_id.app_error", nil, "", http.StatusBadRequest)
 			}
 
-			if err := a.RemovePlugin(manifest.Id); err != nil {
+			if err := a.removePluginLocally(manifest.Id); err != nil {
 				return nil, model.NewAppError("installPlugin", "app.plugin.install_id_failed_remove.app_error", nil, "", http.StatusBadRequest)
 			}
 		}
@@ -83,6 +104,11 @@ func (a *App) installPlugin(pluginFile io.Reader, replace bool) (*model.Manifest
 		return nil, model.NewAppError("installPlugin", "app.plugin.mvdir.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
+	if err = a.savePluginBundle(manifest.Id, bundle, signatureData); err != nil {
+		os.RemoveAll(pluginPath)
+		return nil, model.NewAppError("installPlugin", "app.plugin.mvdir.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
 	if stashed != nil && stashed.Enable {
 		a.EnablePlugin(manifest.Id)
 	}
@@ -94,11 +120,73 @@ func (a *App) installPlugin(pluginFile io.Reader, replace bool) (*model.Manifest
 	return manifest, nil
 }
 
+// extractPluginBundle unpacks a plugin bundle into a temporary directory, which the caller must
+// remove, and returns the manifest of the plugin along with the directory containing it.
+func extractPluginBundle(bundle []byte) (manifest *model.Manifest, tmpDir, tmpPluginDir string, appErr *model.AppError) {
+	tmpDir, err := ioutil.TempDir("", "plugintmp")
+	if err != nil {
+		return nil, "", "", model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer func() {
+		if appErr != nil {
+			os.RemoveAll(tmpDir)
+		}
+	}()
+
+	if err = utils.ExtractTarGz(bytes.NewReader(bundle), tmpDir); err != nil {
+		return nil, "", "", model.NewAppError("installPlugin", "app.plugin.extract.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	tmpPluginDir = tmpDir
+	dir, err := ioutil.ReadDir(tmpDir)
+	if err != nil {
+		return nil, "", "", model.NewAppError("installPlugin", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(dir) == 1 && dir[0].IsDir() {
+		tmpPluginDir = filepath.Join(tmpPluginDir, dir[0].Name())
+	}
+
+	manifest, _, err = model.FindManifest(tmpPluginDir)
+	if err != nil {
+		return nil, "", "", model.NewAppError("installPlugin", "app.plugin.manifest.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	if !plugin.IsValidId(manifest.Id) {
+		return nil, "", "", model.NewAppError("installPlugin", "app.plugin.invalid_id.app_error", map[string]interface{}{"Min": plugin.MinIdLength, "Max": plugin.MaxIdLength, "Regex": plugin.ValidIdRegex}, "", http.StatusBadRequest)
+	}
+
+	if appErr = manifest.IsValid(); appErr != nil {
+		return nil, "", "", appErr
+	}
+
+	return manifest, tmpDir, tmpPluginDir, nil
+}
+
+// RemovePlugin removes a plugin from this server as well as from the file store, so that every
+// server in the cluster removes it as well.
 func (a *App) RemovePlugin(id string) *model.AppError {
 	return a.removePlugin(id)
 }
 
 func (a *App) removePlugin(id string) *model.AppError {
+	if appErr := a.removePluginLocally(id); appErr != nil {
+		return appErr
+	}
+
+	if appErr := a.removeStoredPluginBundle(id); appErr != nil {
+		return appErr
+	}
+
+	a.cancelAllPluginScheduledJobs(id)
+
+	a.notifyClusterPluginsChanged()
+
+	return nil
+}
+
+// removePluginLocally removes a plugin from this server only.
+func (a *App) removePluginLocally(id string) *model.AppError {
 	pluginsEnvironment := a.GetPluginsEnvironment()
 	if pluginsEnvironment == nil {
 		return model.NewAppError("removePlugin", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
@@ -137,5 +225,7 @@ func (a *App) removePlugin(id string) *model.AppError {
 		return model.NewAppError("removePlugin", "app.plugin.remove.app_error", nil, err.Error(), http.StatusInternalServerError)
 	}
 
+	a.removePluginBundle(id)
+
 	return nil
 }
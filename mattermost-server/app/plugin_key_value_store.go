@@ -68,6 +68,47 @@ func (a *App) CompareAndSetPluginKey(pluginId string, key string, oldValue, newV
 	return updated, nil
 }
 
+// SetPluginKeys saves several key-value pairs of a plugin in a single transaction.
+func (a *App) SetPluginKeys(pluginId string, values map[string][]byte) *model.AppError {
+	kvs := make([]*model.PluginKeyValue, 0, len(values))
+	hashedKeys := make([]string, 0, len(values))
+	for key, value := range values {
+		kvs = append(kvs, &model.PluginKeyValue{
+			PluginId: pluginId,
+			Key:      key,
+			Value:    value,
+		})
+		hashedKeys = append(hashedKeys, getKeyHash(key))
+	}
+
+	if err := a.Srv.Store.Plugin().SaveOrUpdateMany(kvs); err != nil {
+		mlog.Error("Failed to set plugin key values", mlog.String("plugin_id", pluginId), mlog.Int("count", len(kvs)), mlog.Err(err))
+		return err
+	}
+
+	// Clean up previous entries using the hashed keys, if they exist.
+	if err := a.Srv.Store.Plugin().DeleteMany(pluginId, hashedKeys); err != nil {
+		mlog.Error("Failed to clean up previously hashed plugin key values", mlog.String("plugin_id", pluginId), mlog.Err(err))
+	}
+
+	return nil
+}
+
+func (a *App) CompareAndDeletePluginKey(pluginId string, key string, oldValue []byte) (bool, *model.AppError) {
+	kv := &model.PluginKeyValue{
+		PluginId: pluginId,
+		Key:      key,
+	}
+
+	deleted, err := a.Srv.Store.Plugin().CompareAndDelete(kv, oldValue)
+	if err != nil {
+		mlog.Error("Failed to compare and delete plugin key value", mlog.String("plugin_id", pluginId), mlog.String("key", key), mlog.Err(err))
+		return deleted, err
+	}
+
+	return deleted, nil
+}
+
 func (a *App) GetPluginKey(pluginId string, key string) ([]byte, *model.AppError) {
 	if result := <-a.Srv.Store.Plugin().Get(pluginId, key); result.Err == nil {
 		return result.Data.(*model.PluginKeyValue).Value, nil
@@ -102,6 +143,21 @@ func (a *App) DeletePluginKey(pluginId string, key string) *model.AppError {
 	return nil
 }
 
+// DeletePluginKeys deletes several keys of a plugin at once, along with their hashed versions.
+func (a *App) DeletePluginKeys(pluginId string, keys []string) *model.AppError {
+	allKeys := make([]string, 0, 2*len(keys))
+	for _, key := range keys {
+		allKeys = append(allKeys, key, getKeyHash(key))
+	}
+
+	if err := a.Srv.Store.Plugin().DeleteMany(pluginId, allKeys); err != nil {
+		mlog.Error("Failed to delete plugin key values", mlog.String("plugin_id", pluginId), mlog.Int("count", len(keys)), mlog.Err(err))
+		return err
+	}
+
+	return nil
+}
+
 func (a *App) DeleteAllKeysForPlugin(pluginId string) *model.AppError {
 	if result := <-a.Srv.Store.Plugin().DeleteAllForPlugin(pluginId); result.Err != nil {
 		mlog.Error("Failed to delete all plugin key values", mlog.String("plugin_id", pluginId), mlog.Err(result.Err))
@@ -134,3 +190,13 @@ func (a *App) ListPluginKeys(pluginId string, page, perPage int) ([]string, *mod
 
 	return result.Data.([]string), nil
 }
+
+func (a *App) ListPluginKeysWithPrefix(pluginId, prefix string, page, perPage int) ([]string, *model.AppError) {
+	keys, err := a.Srv.Store.Plugin().ListWithPrefix(pluginId, prefix, page*perPage, perPage)
+	if err != nil {
+		mlog.Error("Failed to list plugin key values with prefix", mlog.String("prefix", prefix), mlog.Int("page", page), mlog.Int("perPage", perPage), mlog.Err(err))
+		return nil, err
+	}
+
+	return keys, nil
+}
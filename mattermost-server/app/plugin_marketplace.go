@@ -0,0 +1,173 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// MAXIMUM_PLUGIN_DOWNLOAD_SIZE limits the size of the plugin bundles and marketplace indexes
+// downloaded by the server.
+const MAXIMUM_PLUGIN_DOWNLOAD_SIZE = 50 * 1024 * 1024
+
+// downloadPluginFile fetches the file at the given HTTPS URL, subject to the rules applied to
+// untrusted outgoing connections. If optional is true, a missing file isn't an error and nil is
+// returned instead.
+func (a *App) downloadPluginFile(downloadUrl string, optional bool) ([]byte, *model.AppError) {
+	u, err := url.Parse(downloadUrl)
+	if err != nil || strings.ToLower(u.Scheme) != "https" || u.Host == "" {
+		return nil, model.NewAppError("downloadPluginFile", "app.plugin.download.invalid_url.app_error", map[string]interface{}{"Url": downloadUrl}, "", http.StatusBadRequest)
+	}
+
+	resp, err := a.HTTPService.MakeClient(false).Get(u.String())
+	if err != nil {
+		return nil, model.NewAppError("downloadPluginFile", "app.plugin.download.app_error", map[string]interface{}{"Url": downloadUrl}, err.Error(), http.StatusBadRequest)
+	}
+	defer resp.Body.Close()
+
+	if optional && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, model.NewAppError("downloadPluginFile", "app.plugin.download.app_error", map[string]interface{}{"Url": downloadUrl}, resp.Status, http.StatusBadRequest)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MAXIMUM_PLUGIN_DOWNLOAD_SIZE+1))
+	if err != nil {
+		return nil, model.NewAppError("downloadPluginFile", "app.plugin.download.app_error", map[string]interface{}{"Url": downloadUrl}, err.Error(), http.StatusBadRequest)
+	}
+
+	if len(data) > MAXIMUM_PLUGIN_DOWNLOAD_SIZE {
+		return nil, model.NewAppError("downloadPluginFile", "app.plugin.download.too_large.app_error", map[string]interface{}{"Url": downloadUrl}, "", http.StatusRequestEntityTooLarge)
+	}
+
+	return data, nil
+}
+
+// InstallPluginFromUrl downloads a plugin bundle over HTTPS and installs it, but does not enable or
+// activate it. A detached signature found alongside the bundle, e.g. at bundle.tar.gz.sig, is
+// verified along with it.
+func (a *App) InstallPluginFromUrl(downloadUrl string, replace bool) (*model.Manifest, *model.AppError) {
+	bundle, appErr := a.downloadPluginFile(downloadUrl, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	var signature io.Reader
+	if u, err := url.Parse(downloadUrl); err == nil {
+		u.Path += PLUGIN_SIGNATURE_EXTENSION
+		signatureData, appErr := a.downloadPluginFile(u.String(), true)
+		if appErr != nil {
+			return nil, appErr
+		}
+		if signatureData != nil {
+			signature = bytes.NewReader(signatureData)
+		}
+	}
+
+	return a.installPlugin(bytes.NewReader(bundle), signature, replace)
+}
+
+// GetMarketplaceIndex fetches the marketplace index configured in PluginSettings.MarketplaceUrl.
+func (a *App) GetMarketplaceIndex() (*model.MarketplaceIndex, *model.AppError) {
+	marketplaceUrl := *a.Config().PluginSettings.MarketplaceUrl
+	if marketplaceUrl == "" {
+		return nil, model.NewAppError("GetMarketplaceIndex", "app.plugin.marketplace.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	data, appErr := a.downloadPluginFile(marketplaceUrl, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	index := model.MarketplaceIndexFromJson(bytes.NewReader(data))
+	if index == nil {
+		return nil, model.NewAppError("GetMarketplaceIndex", "app.plugin.marketplace.invalid_index.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	return index, nil
+}
+
+// GetMarketplacePluginUpgrades returns the installed plugins for which the marketplace lists a newer
+// version supported by this server.
+func (a *App) GetMarketplacePluginUpgrades() ([]*model.MarketplacePluginUpgrade, *model.AppError) {
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return nil, model.NewAppError("GetMarketplacePluginUpgrades", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	index, appErr := a.GetMarketplaceIndex()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	plugins, err := pluginsEnvironment.Available()
+	if err != nil {
+		return nil, model.NewAppError("GetMarketplacePluginUpgrades", "app.plugin.sync.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	var installed []*model.Manifest
+	for _, plugin := range plugins {
+		if plugin.Manifest != nil {
+			installed = append(installed, plugin.Manifest)
+		}
+	}
+
+	return index.Upgrades(installed, model.CurrentVersion), nil
+}
+
+// InstallMarketplacePlugin installs the given version of a plugin listed in the marketplace, or its
+// latest version supported by this server if the version is empty, replacing any installed version.
+// The downloaded bundle must match the checksum listed in the marketplace.
+func (a *App) InstallMarketplacePlugin(id, version string) (*model.Manifest, *model.AppError) {
+	index, appErr := a.GetMarketplaceIndex()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	marketplacePlugin := index.Find(id, version, model.CurrentVersion)
+	if marketplacePlugin == nil {
+		return nil, model.NewAppError("InstallMarketplacePlugin", "app.plugin.marketplace.not_found.app_error", map[string]interface{}{"PluginId": id, "Version": version}, "", http.StatusNotFound)
+	}
+
+	bundle, appErr := a.downloadPluginFile(marketplacePlugin.DownloadUrl, false)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !strings.EqualFold(pluginBundleChecksum(bundle), marketplacePlugin.Checksum) {
+		return nil, model.NewAppError("InstallMarketplacePlugin", "app.plugin.marketplace.checksum.app_error", map[string]interface{}{"PluginId": marketplacePlugin.Id}, "", http.StatusBadRequest)
+	}
+
+	// The plugin is installed over any previous version, so it must be the plugin that was asked for.
+	manifest, tmpDir, _, appErr := extractPluginBundle(bundle)
+	if appErr != nil {
+		return nil, appErr
+	}
+	os.RemoveAll(tmpDir)
+
+	if manifest.Id != marketplacePlugin.Id || manifest.Version != marketplacePlugin.Version {
+		return nil, model.NewAppError("InstallMarketplacePlugin", "app.plugin.marketplace.manifest_mismatch.app_error", map[string]interface{}{"PluginId": marketplacePlugin.Id, "Version": marketplacePlugin.Version}, "manifest_id="+manifest.Id+", manifest_version="+manifest.Version, http.StatusBadRequest)
+	}
+
+	var signature io.Reader
+	if marketplacePlugin.SignatureUrl != "" {
+		signatureData, appErr := a.downloadPluginFile(marketplacePlugin.SignatureUrl, false)
+		if appErr != nil {
+			return nil, appErr
+		}
+		signature = bytes.NewReader(signatureData)
+	}
+
+	return a.installPlugin(bytes.NewReader(bundle), signature, true)
+}
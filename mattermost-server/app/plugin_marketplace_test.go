@@ -0,0 +1,141 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils/fileutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makePluginBundle(t *testing.T, manifest string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	require.Nil(t, tw.WriteHeader(&tar.Header{Name: "plugin.json", Mode: 0600, Size: int64(len(manifest))}))
+	_, err := tw.Write([]byte(manifest))
+	require.Nil(t, err)
+
+	require.Nil(t, tw.Close())
+	require.Nil(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func TestPluginMarketplace(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	path, _ := fileutils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.Nil(t, err)
+
+	versionedBundle := makePluginBundle(t, `{"id": "testplugin", "version": "99.0.0", "webapp": {"bundle_path": "webapp/testplugin_bundle.js"}}`)
+
+	var index *model.MarketplaceIndex
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(index.ToJson()))
+		case "/testplugin.tar.gz":
+			w.Write(bundle)
+		case "/testplugin-99.0.0.tar.gz":
+			w.Write(versionedBundle)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	fileDir, err := ioutil.TempDir("", "pluginstore")
+	require.Nil(t, err)
+	defer os.RemoveAll(fileDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = filepath.Join(th.tempWorkspace, "plugins")
+		*cfg.PluginSettings.MarketplaceUrl = server.URL + "/index.json"
+		*cfg.FileSettings.DriverName = model.IMAGE_DRIVER_LOCAL
+		*cfg.FileSettings.Directory = fileDir
+		*cfg.ServiceSettings.EnableInsecureOutgoingConnections = true
+		*cfg.ServiceSettings.AllowedUntrustedInternalConnections = "127.0.0.1"
+	})
+
+	t.Run("install from url", func(t *testing.T) {
+		manifest, appErr := th.App.InstallPluginFromUrl(server.URL+"/testplugin.tar.gz", true)
+		require.Nil(t, appErr)
+		assert.Equal(t, "testplugin", manifest.Id)
+
+		_, appErr = th.App.InstallPluginFromUrl(server.URL+"/missing.tar.gz", true)
+		assert.NotNil(t, appErr)
+
+		_, appErr = th.App.InstallPluginFromUrl("http://example.com/testplugin.tar.gz", true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.download.invalid_url.app_error", appErr.Id)
+	})
+
+	t.Run("upgrades", func(t *testing.T) {
+		index = &model.MarketplaceIndex{
+			Plugins: []*model.MarketplacePlugin{
+				{Id: "testplugin", Version: "99.0.0", DownloadUrl: server.URL + "/testplugin-99.0.0.tar.gz", Checksum: pluginBundleChecksum(versionedBundle)},
+				{Id: "testplugin", Version: "98.0.0", DownloadUrl: server.URL + "/testplugin.tar.gz", Checksum: pluginBundleChecksum(bundle)},
+				{Id: "other", Version: "1.0.0", DownloadUrl: server.URL + "/testplugin-99.0.0.tar.gz", Checksum: pluginBundleChecksum(versionedBundle)},
+			},
+		}
+
+		upgrades, appErr := th.App.GetMarketplacePluginUpgrades()
+		require.Nil(t, appErr)
+		require.Len(t, upgrades, 1)
+		assert.Equal(t, "testplugin", upgrades[0].Id)
+		assert.Equal(t, "99.0.0", upgrades[0].Latest.Version)
+	})
+
+	t.Run("install from marketplace", func(t *testing.T) {
+		manifest, appErr := th.App.InstallMarketplacePlugin("testplugin", "")
+		require.Nil(t, appErr)
+		assert.Equal(t, "testplugin", manifest.Id)
+
+		assert.Equal(t, "99.0.0", manifest.Version)
+
+		_, appErr = th.App.InstallMarketplacePlugin("missing", "")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.marketplace.not_found.app_error", appErr.Id)
+
+		// The bundle must contain the plugin and version listed in the marketplace.
+		_, appErr = th.App.InstallMarketplacePlugin("testplugin", "98.0.0")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.marketplace.manifest_mismatch.app_error", appErr.Id)
+
+		_, appErr = th.App.InstallMarketplacePlugin("other", "")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.marketplace.manifest_mismatch.app_error", appErr.Id)
+
+		index.Plugins[0].Checksum = "invalid"
+		_, appErr = th.App.InstallMarketplacePlugin("testplugin", "")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.marketplace.checksum.app_error", appErr.Id)
+	})
+
+	t.Run("marketplace not configured", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			*cfg.PluginSettings.MarketplaceUrl = ""
+		})
+
+		_, appErr := th.App.GetMarketplaceIndex()
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.marketplace.disabled.app_error", appErr.Id)
+	})
+}
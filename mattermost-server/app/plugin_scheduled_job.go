@@ -0,0 +1,191 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// pendingPluginScheduledJob is the next run of a job registered by a plugin, saved ahead of time
+// as a pending job.
+type pendingPluginScheduledJob struct {
+	job          *model.Job
+	scheduledJob *model.PluginScheduledJob
+}
+
+// getPendingPluginScheduledJobs returns the next runs of the jobs registered by the given plugin.
+func (a *App) getPendingPluginScheduledJobs(pluginId string) ([]*pendingPluginScheduledJob, *model.AppError) {
+	result := <-a.Srv.Store.Job().GetAllByStatus(model.JOB_STATUS_PENDING)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	var pending []*pendingPluginScheduledJob
+	for _, job := range result.Data.([]*model.Job) {
+		if scheduledJob, _ := model.PluginScheduledJobFromJob(job); scheduledJob != nil && scheduledJob.PluginId == pluginId {
+			pending = append(pending, &pendingPluginScheduledJob{job: job, scheduledJob: scheduledJob})
+		}
+	}
+
+	return pending, nil
+}
+
+// schedulePluginJob saves the first run of the scheduled job after the given time. Since every
+// server of the cluster computes the same id for a run, it's only saved once.
+func (a *App) schedulePluginJob(scheduledJob *model.PluginScheduledJob, after time.Time) *model.AppError {
+	runAt, err := scheduledJob.NextRunTime(after)
+	if err != nil {
+		return model.NewAppError("schedulePluginJob", "app.plugin.scheduled_job.schedule.app_error", map[string]interface{}{"Name": scheduledJob.Name}, err.Error(), http.StatusBadRequest)
+	}
+
+	job := scheduledJob.ToJob(model.GetMillisForTime(runAt))
+	result := <-a.Srv.Store.Job().Save(job)
+	if result.Err == nil {
+		return nil
+	}
+
+	existing, appErr := a.GetJob(job.Id)
+	if appErr != nil {
+		return result.Err
+	}
+
+	// The run was canceled by an earlier change of schedule, which has since been reverted.
+	if existing.Status == model.JOB_STATUS_CANCELED {
+		if result := <-a.Srv.Store.Job().UpdateStatusOptimistically(job.Id, model.JOB_STATUS_CANCELED, model.JOB_STATUS_PENDING); result.Err != nil {
+			return result.Err
+		}
+	}
+
+	return nil
+}
+
+func (a *App) cancelPluginScheduledJob(job *model.Job) *model.AppError {
+	result := <-a.Srv.Store.Job().UpdateStatusOptimistically(job.Id, model.JOB_STATUS_PENDING, model.JOB_STATUS_CANCELED)
+	return result.Err
+}
+
+// RegisterPluginScheduledJob registers a job of the given plugin, replacing the schedule of the
+// job with the same name, if any.
+func (a *App) RegisterPluginScheduledJob(pluginId string, scheduledJob *model.PluginScheduledJob) *model.AppError {
+	scheduledJob.PluginId = pluginId
+	if appErr := scheduledJob.IsValid(); appErr != nil {
+		return appErr
+	}
+
+	pending, appErr := a.getPendingPluginScheduledJobs(pluginId)
+	if appErr != nil {
+		return appErr
+	}
+
+	scheduled := false
+	for _, p := range pending {
+		if p.scheduledJob.Name != scheduledJob.Name {
+			continue
+		}
+
+		if p.scheduledJob.SameSchedule(scheduledJob) && !scheduled {
+			scheduled = true
+			continue
+		}
+
+		if appErr := a.cancelPluginScheduledJob(p.job); appErr != nil {
+			return appErr
+		}
+	}
+
+	if scheduled {
+		return nil
+	}
+
+	return a.schedulePluginJob(scheduledJob, time.Now())
+}
+
+// UnregisterPluginScheduledJob cancels the future runs of a job of the given plugin.
+func (a *App) UnregisterPluginScheduledJob(pluginId, name string) *model.AppError {
+	pending, appErr := a.getPendingPluginScheduledJobs(pluginId)
+	if appErr != nil {
+		return appErr
+	}
+
+	for _, p := range pending {
+		if p.scheduledJob.Name == name {
+			if appErr := a.cancelPluginScheduledJob(p.job); appErr != nil {
+				return appErr
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetPluginScheduledJobs returns the jobs registered by the given plugin.
+func (a *App) GetPluginScheduledJobs(pluginId string) ([]*model.PluginScheduledJob, *model.AppError) {
+	pending, appErr := a.getPendingPluginScheduledJobs(pluginId)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	scheduledJobs := []*model.PluginScheduledJob{}
+	seen := map[string]bool{}
+	for _, p := range pending {
+		if !seen[p.scheduledJob.Name] {
+			seen[p.scheduledJob.Name] = true
+			scheduledJobs = append(scheduledJobs, p.scheduledJob)
+		}
+	}
+
+	return scheduledJobs, nil
+}
+
+// cancelAllPluginScheduledJobs cancels the future runs of every job of a plugin being removed.
+func (a *App) cancelAllPluginScheduledJobs(pluginId string) {
+	pending, appErr := a.getPendingPluginScheduledJobs(pluginId)
+	if appErr != nil {
+		mlog.Error("Failed to cancel the scheduled jobs of the plugin", mlog.String("plugin_id", pluginId), mlog.Err(appErr))
+		return
+	}
+
+	for _, p := range pending {
+		if appErr := a.cancelPluginScheduledJob(p.job); appErr != nil {
+			mlog.Error("Failed to cancel scheduled job", mlog.String("plugin_id", pluginId), mlog.String("job_id", p.job.Id), mlog.Err(appErr))
+		}
+	}
+}
+
+// RunPluginScheduledJob runs a job registered by a plugin, once claimed by a worker. The next run
+// is saved first, so that the schedule survives this server going down mid-run. Once the plugin
+// is disabled, the job isn't scheduled anymore until the plugin registers it again.
+func (a *App) RunPluginScheduledJob(job *model.Job) *model.AppError {
+	scheduledJob, _ := model.PluginScheduledJobFromJob(job)
+	if scheduledJob == nil {
+		return model.NewAppError("RunPluginScheduledJob", "app.plugin.scheduled_job.invalid.app_error", nil, "id="+job.Id, http.StatusBadRequest)
+	}
+
+	cfg := a.Config()
+	if state, ok := cfg.PluginSettings.PluginStates[scheduledJob.PluginId]; !*cfg.PluginSettings.Enable || !ok || !state.Enable {
+		return model.NewAppError("RunPluginScheduledJob", "app.plugin.scheduled_job.plugin_disabled.app_error", map[string]interface{}{"PluginId": scheduledJob.PluginId}, "", http.StatusNotImplemented)
+	}
+
+	if appErr := a.schedulePluginJob(scheduledJob, time.Now()); appErr != nil {
+		mlog.Error("Failed to schedule the next run of the job", mlog.String("plugin_id", scheduledJob.PluginId), mlog.String("name", scheduledJob.Name), mlog.Err(appErr))
+	}
+
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return model.NewAppError("RunPluginScheduledJob", "app.plugin.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	hooks, err := pluginsEnvironment.HooksForPlugin(scheduledJob.PluginId)
+	if err != nil {
+		return model.NewAppError("RunPluginScheduledJob", "app.plugin.scheduled_job.plugin_inactive.app_error", map[string]interface{}{"PluginId": scheduledJob.PluginId}, err.Error(), http.StatusInternalServerError)
+	}
+
+	hooks.RunScheduledJob(a.PluginContext(), scheduledJob.Name)
+
+	return nil
+}
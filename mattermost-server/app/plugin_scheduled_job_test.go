@@ -0,0 +1,123 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestPluginScheduledJobs(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	pluginId := "scheduled" + model.NewId()
+	defer th.App.cancelAllPluginScheduledJobs(pluginId)
+
+	t.Run("register", func(t *testing.T) {
+		require.Nil(t, th.App.RegisterPluginScheduledJob(pluginId, &model.PluginScheduledJob{Name: "hourly", Cron: "@hourly"}))
+		require.Nil(t, th.App.RegisterPluginScheduledJob(pluginId, &model.PluginScheduledJob{Name: "interval", Interval: 600}))
+
+		// Registering again, as every server of the cluster does, keeps a single run.
+		require.Nil(t, th.App.RegisterPluginScheduledJob(pluginId, &model.PluginScheduledJob{Name: "hourly", Cron: "@hourly"}))
+
+		pending, err := th.App.getPendingPluginScheduledJobs(pluginId)
+		require.Nil(t, err)
+		assert.Len(t, pending, 2)
+
+		scheduledJobs, err := th.App.GetPluginScheduledJobs(pluginId)
+		require.Nil(t, err)
+		assert.ElementsMatch(t, []*model.PluginScheduledJob{
+			{PluginId: pluginId, Name: "hourly", Cron: "@hourly"},
+			{PluginId: pluginId, Name: "interval", Interval: 600},
+		}, scheduledJobs)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.NotNil(t, th.App.RegisterPluginScheduledJob(pluginId, &model.PluginScheduledJob{Name: "invalid", Cron: "not cron"}))
+		assert.NotNil(t, th.App.RegisterPluginScheduledJob(pluginId, &model.PluginScheduledJob{Name: "invalid", Interval: 1}))
+	})
+
+	t.Run("change schedule", func(t *testing.T) {
+		require.Nil(t, th.App.RegisterPluginScheduledJob(pluginId, &model.PluginScheduledJob{Name: "hourly", Cron: "30 * * * *"}))
+
+		scheduledJobs, err := th.App.GetPluginScheduledJobs(pluginId)
+		require.Nil(t, err)
+		assert.Contains(t, scheduledJobs, &model.PluginScheduledJob{PluginId: pluginId, Name: "hourly", Cron: "30 * * * *"})
+		assert.NotContains(t, scheduledJobs, &model.PluginScheduledJob{PluginId: pluginId, Name: "hourly", Cron: "@hourly"})
+	})
+
+	t.Run("unregister", func(t *testing.T) {
+		require.Nil(t, th.App.UnregisterPluginScheduledJob(pluginId, "hourly"))
+
+		scheduledJobs, err := th.App.GetPluginScheduledJobs(pluginId)
+		require.Nil(t, err)
+		assert.Equal(t, []*model.PluginScheduledJob{{PluginId: pluginId, Name: "interval", Interval: 600}}, scheduledJobs)
+	})
+}
+
+func TestRunPluginScheduledJob(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	tearDown, pluginIds, activationErrors := SetAppEnvironmentWithPlugins(t,
+		[]string{
+			`
+		package main
+
+		import (
+			"github.com/mattermost/mattermost-server/plugin"
+		)
+
+		type MyPlugin struct {
+			plugin.MattermostPlugin
+		}
+
+		func (p *MyPlugin) RunScheduledJob(c *plugin.Context, jobName string) {
+			p.API.KVSet("ran", []byte(jobName))
+		}
+
+		func main() {
+			plugin.ClientMain(&MyPlugin{})
+		}
+	`}, th.App, th.App.NewPluginAPI)
+	defer tearDown()
+	require.Nil(t, activationErrors[0])
+
+	pluginId := pluginIds[0]
+	defer th.App.cancelAllPluginScheduledJobs(pluginId)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		cfg.PluginSettings.PluginStates[pluginId] = &model.PluginState{Enable: true}
+	})
+
+	scheduledJob := &model.PluginScheduledJob{PluginId: pluginId, Name: "report", Interval: 3600}
+	job := scheduledJob.ToJob(model.GetMillis())
+	require.Nil(t, (<-th.App.Srv.Store.Job().Save(job)).Err)
+
+	require.Nil(t, th.App.RunPluginScheduledJob(job))
+
+	value, err := th.App.GetPluginKey(pluginId, "ran")
+	require.Nil(t, err)
+	assert.Equal(t, "report", string(value))
+
+	// The next run is scheduled.
+	next, nextErr := scheduledJob.NextRunTime(time.Now())
+	require.Nil(t, nextErr)
+	_, err = th.App.GetJob(scheduledJob.JobId(model.GetMillisForTime(next)))
+	assert.Nil(t, err)
+
+	t.Run("plugin disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.PluginStates[pluginId] = &model.PluginState{Enable: false}
+		})
+
+		assert.NotNil(t, th.App.RunPluginScheduledJob(scheduledJob.ToJob(model.GetMillis())))
+	})
+}
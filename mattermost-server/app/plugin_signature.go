@@ -0,0 +1,195 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+const (
+	// PLUGIN_SIGNATURE_EXTENSION is appended to the name of a plugin bundle to find its detached
+	// signature, e.g. bundle.tar.gz.sig.
+	PLUGIN_SIGNATURE_EXTENSION = ".sig"
+
+	// PLUGIN_BUNDLES_DIRECTORY is where the bundles of installed plugins are kept, along with their
+	// signatures, so that plugins can be verified again on startup. Being hidden, it's ignored when
+	// scanning the plugin directory for plugins.
+	PLUGIN_BUNDLES_DIRECTORY = ".bundles"
+)
+
+// getPluginPublicKeys loads the public keys trusted to sign plugins.
+func (a *App) getPluginPublicKeys() ([]crypto.PublicKey, *model.AppError) {
+	var publicKeys []crypto.PublicKey
+	for _, name := range a.Config().PluginSettings.SignaturePublicKeyFiles {
+		data, err := a.GetConfigFile(name)
+		if err != nil {
+			return nil, model.NewAppError("getPluginPublicKeys", "app.plugin.signature.public_key.app_error", map[string]interface{}{"Name": name}, err.Error(), http.StatusInternalServerError)
+		}
+
+		publicKey, err := utils.ParsePluginPublicKey(data)
+		if err != nil {
+			return nil, model.NewAppError("getPluginPublicKeys", "app.plugin.signature.public_key.app_error", map[string]interface{}{"Name": name}, err.Error(), http.StatusInternalServerError)
+		}
+
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	return publicKeys, nil
+}
+
+// verifyPluginSignature checks that the bundle of the plugin with the given id was signed by a
+// trusted key, unless signatures aren't required for the plugin. A nil signature means that the
+// bundle isn't signed.
+func (a *App) verifyPluginSignature(id string, bundle, signature []byte) *model.AppError {
+	if a.Config().PluginSettings.AllowsUnsignedPlugin(id) {
+		return nil
+	}
+
+	if signature == nil {
+		return model.NewAppError("verifyPluginSignature", "app.plugin.signature.missing.app_error", map[string]interface{}{"PluginId": id}, "", http.StatusBadRequest)
+	}
+
+	publicKeys, appErr := a.getPluginPublicKeys()
+	if appErr != nil {
+		return appErr
+	}
+
+	if err := utils.VerifyPluginSignature(publicKeys, bytes.NewReader(bundle), signature); err != nil {
+		return model.NewAppError("verifyPluginSignature", "app.plugin.signature.invalid.app_error", map[string]interface{}{"PluginId": id}, err.Error(), http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// pluginBundlePaths returns where the bundle of the plugin with the given id and its signature are
+// kept.
+func (a *App) pluginBundlePaths(id string) (bundlePath, signaturePath string) {
+	bundlePath = filepath.Join(*a.Config().PluginSettings.Directory, PLUGIN_BUNDLES_DIRECTORY, id+".tar.gz")
+	return bundlePath, bundlePath + PLUGIN_SIGNATURE_EXTENSION
+}
+
+// savePluginBundle keeps the bundle of an installed plugin along with its signature, if any.
+func (a *App) savePluginBundle(id string, bundle, signature []byte) error {
+	bundlePath, signaturePath := a.pluginBundlePaths(id)
+
+	if err := os.MkdirAll(filepath.Dir(bundlePath), 0700); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(bundlePath, bundle, 0600); err != nil {
+		return err
+	}
+
+	if signature == nil {
+		if err := os.Remove(signaturePath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return ioutil.WriteFile(signaturePath, signature, 0600)
+}
+
+// removePluginBundle removes the bundle of a plugin that is being uninstalled.
+func (a *App) removePluginBundle(id string) {
+	bundlePath, signaturePath := a.pluginBundlePaths(id)
+
+	for _, path := range []string{bundlePath, signaturePath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			mlog.Warn("Failed to remove plugin bundle", mlog.String("plugin_id", id), mlog.String("path", path), mlog.Err(err))
+		}
+	}
+}
+
+// verifyInstalledPlugins removes the installed plugins whose bundle can't be verified, such as
+// plugins copied to the plugin directory by hand or installed before signatures were required.
+// Those plugins must be installed again with a valid signature. The other plugins are unpacked
+// again from their verified bundle, undoing any change made to their files since they were
+// installed.
+func (a *App) verifyInstalledPlugins() {
+	if !*a.Config().PluginSettings.RequirePluginSignature {
+		return
+	}
+
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return
+	}
+
+	// Don't remove every plugin over a misconfigured keyring.
+	if _, appErr := a.getPluginPublicKeys(); appErr != nil {
+		mlog.Error("Failed to verify installed plugins", mlog.Err(appErr))
+		return
+	}
+
+	plugins, err := pluginsEnvironment.Available()
+	if err != nil {
+		mlog.Error("Failed to verify installed plugins", mlog.Err(err))
+		return
+	}
+
+	for _, plugin := range plugins {
+		if plugin.Manifest == nil || a.Config().PluginSettings.AllowsUnsignedPlugin(plugin.Manifest.Id) {
+			continue
+		}
+
+		bundlePath, signaturePath := a.pluginBundlePaths(plugin.Manifest.Id)
+
+		var signature []byte
+		bundle, err := ioutil.ReadFile(bundlePath)
+		if err == nil {
+			signature, err = ioutil.ReadFile(signaturePath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			mlog.Error("Failed to read plugin bundle", mlog.String("plugin_id", plugin.Manifest.Id), mlog.Err(err))
+			continue
+		}
+
+		appErr := a.verifyPluginSignature(plugin.Manifest.Id, bundle, signature)
+		if appErr == nil {
+			appErr = restorePluginFromBundle(plugin.Manifest.Id, filepath.Dir(plugin.ManifestPath), bundle)
+			if appErr == nil {
+				continue
+			}
+		}
+
+		mlog.Error("Removing plugin that can't be verified, install it again with a valid signature", mlog.String("plugin_id", plugin.Manifest.Id), mlog.Err(appErr))
+		if appErr = a.removePluginLocally(plugin.Manifest.Id); appErr != nil {
+			mlog.Error("Failed to remove plugin", mlog.String("plugin_id", plugin.Manifest.Id), mlog.Err(appErr))
+		}
+	}
+}
+
+// restorePluginFromBundle replaces the files of the plugin installed at pluginPath with those of
+// its bundle.
+func restorePluginFromBundle(id, pluginPath string, bundle []byte) *model.AppError {
+	manifest, tmpDir, tmpPluginDir, appErr := extractPluginBundle(bundle)
+	if appErr != nil {
+		return appErr
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if manifest.Id != id {
+		return model.NewAppError("restorePluginFromBundle", "app.plugin.signature.bundle_mismatch.app_error", map[string]interface{}{"PluginId": id}, "bundle_plugin_id="+manifest.Id, http.StatusInternalServerError)
+	}
+
+	if err := os.RemoveAll(pluginPath); err != nil {
+		return model.NewAppError("restorePluginFromBundle", "app.plugin.filesystem.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := utils.CopyDir(tmpPluginDir, pluginPath); err != nil {
+		return model.NewAppError("restorePluginFromBundle", "app.plugin.mvdir.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils/fileutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallPluginSignature(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	path, _ := fileutils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.Nil(t, err)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.Nil(t, err)
+	require.Nil(t, th.App.Srv.configStore.SetFile("plugin-signing.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+
+	digest := sha256.Sum256(bundle)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.Nil(t, err)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = filepath.Join(th.tempWorkspace, "plugins")
+		*cfg.PluginSettings.RequirePluginSignature = true
+		cfg.PluginSettings.SignaturePublicKeyFiles = []string{"plugin-signing.pem"}
+	})
+
+	pluginPath := filepath.Join(th.tempWorkspace, "plugins", "testplugin")
+	bundlePath, signaturePath := th.App.pluginBundlePaths("testplugin")
+
+	t.Run("unsigned", func(t *testing.T) {
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle), true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.signature.missing.app_error", appErr.Id)
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		_, appErr := th.App.InstallPluginWithSignature(bytes.NewReader(bundle), bytes.NewReader([]byte("invalid")), true)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "app.plugin.signature.invalid.app_error", appErr.Id)
+	})
+
+	t.Run("valid signature", func(t *testing.T) {
+		manifest, appErr := th.App.InstallPluginWithSignature(bytes.NewReader(bundle), bytes.NewReader(signature), true)
+		require.Nil(t, appErr)
+		assert.Equal(t, "testplugin", manifest.Id)
+
+		_, err := os.Stat(bundlePath)
+		assert.Nil(t, err)
+		_, err = os.Stat(signaturePath)
+		assert.Nil(t, err)
+
+		// Changes to the files of the plugin are undone on startup.
+		manifestPath := filepath.Join(pluginPath, "plugin.json")
+		manifestData, err := ioutil.ReadFile(manifestPath)
+		require.Nil(t, err)
+		require.Nil(t, ioutil.WriteFile(manifestPath, []byte(`{"id": "testplugin", "version": "0.0.1", "server": {"executable": "tampered"}}`), 0600))
+		require.Nil(t, ioutil.WriteFile(filepath.Join(pluginPath, "tampered"), []byte("tampered"), 0600))
+
+		th.App.verifyInstalledPlugins()
+		restoredData, err := ioutil.ReadFile(manifestPath)
+		require.Nil(t, err)
+		assert.Equal(t, manifestData, restoredData)
+		_, err = os.Stat(filepath.Join(pluginPath, "tampered"))
+		assert.True(t, os.IsNotExist(err))
+
+		require.Nil(t, th.App.RemovePlugin("testplugin"))
+		_, err = os.Stat(bundlePath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("allowed unsigned", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.AllowedUnsignedPlugins = []string{"testplugin"}
+		})
+
+		_, appErr := th.App.InstallPlugin(bytes.NewReader(bundle), true)
+		require.Nil(t, appErr)
+
+		th.App.verifyInstalledPlugins()
+		_, err := os.Stat(pluginPath)
+		assert.Nil(t, err)
+
+		// Once no longer allowed, the unsigned plugin is removed on startup.
+		th.App.UpdateConfig(func(cfg *model.Config) {
+			cfg.PluginSettings.AllowedUnsignedPlugins = []string{}
+		})
+
+		th.App.verifyInstalledPlugins()
+		_, err = os.Stat(pluginPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
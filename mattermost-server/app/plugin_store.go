@@ -0,0 +1,257 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	// PLUGIN_STORE_DIRECTORY is where the bundles of installed plugins are kept in the file store,
+	// each in its own directory along with its signature and index entry. Giving each plugin its
+	// own index entry lets servers install different plugins at the same time without overwriting
+	// each other's changes.
+	PLUGIN_STORE_DIRECTORY = "plugins"
+
+	PLUGIN_STORE_BUNDLE = "bundle.tar.gz"
+	PLUGIN_STORE_ENTRY  = "entry.json"
+)
+
+func pluginStoreBundlePaths(id string) (bundlePath, signaturePath string) {
+	bundlePath = path.Join(PLUGIN_STORE_DIRECTORY, id, PLUGIN_STORE_BUNDLE)
+	return bundlePath, bundlePath + PLUGIN_SIGNATURE_EXTENSION
+}
+
+func pluginStoreEntryPath(id string) string {
+	return path.Join(PLUGIN_STORE_DIRECTORY, id, PLUGIN_STORE_ENTRY)
+}
+
+func pluginBundleChecksum(bundle []byte) string {
+	checksum := sha256.Sum256(bundle)
+	return hex.EncodeToString(checksum[:])
+}
+
+// getPluginIndex reads the index entries of the plugin bundles kept in the file store.
+func (a *App) getPluginIndex() (*model.PluginIndex, *model.AppError) {
+	index := model.NewPluginIndex()
+
+	backend, appErr := a.FileBackend()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	dirs, appErr := backend.ListDirectory(PLUGIN_STORE_DIRECTORY + "/")
+	if appErr != nil {
+		// Listing a missing directory only fails with the local file backend.
+		if exists, existsErr := a.FileExists(PLUGIN_STORE_DIRECTORY); existsErr == nil && !exists {
+			return index, nil
+		}
+		return nil, appErr
+	}
+
+	for _, dir := range *dirs {
+		entryPath := path.Join(dir, PLUGIN_STORE_ENTRY)
+
+		// The entry is written last, so a plugin that is still being stored doesn't have one yet.
+		exists, appErr := a.FileExists(entryPath)
+		if appErr != nil {
+			return nil, appErr
+		}
+		if !exists {
+			continue
+		}
+
+		data, appErr := a.ReadFile(entryPath)
+		if appErr != nil {
+			return nil, appErr
+		}
+
+		entry := model.PluginIndexEntryFromJson(bytes.NewReader(data))
+		if entry == nil {
+			return nil, model.NewAppError("getPluginIndex", "app.plugin.store.index.app_error", nil, "path="+entryPath, http.StatusInternalServerError)
+		}
+
+		index.Add(entry)
+	}
+
+	return index, nil
+}
+
+func (a *App) savePluginIndexEntry(entry *model.PluginIndexEntry) *model.AppError {
+	_, appErr := a.WriteFile(bytes.NewReader([]byte(entry.ToJson())), pluginStoreEntryPath(entry.Id))
+	return appErr
+}
+
+// storePluginBundle keeps the bundle of an installed plugin, along with its signature if any, in
+// the file store.
+func (a *App) storePluginBundle(manifest *model.Manifest, bundle, signature []byte) *model.AppError {
+	bundlePath, signaturePath := pluginStoreBundlePaths(manifest.Id)
+
+	if _, appErr := a.WriteFile(bytes.NewReader(bundle), bundlePath); appErr != nil {
+		return appErr
+	}
+
+	if signature != nil {
+		if _, appErr := a.WriteFile(bytes.NewReader(signature), signaturePath); appErr != nil {
+			return appErr
+		}
+	} else if appErr := a.removeStoredFileIfExists(signaturePath); appErr != nil {
+		return appErr
+	}
+
+	return a.savePluginIndexEntry(&model.PluginIndexEntry{
+		Id:       manifest.Id,
+		Version:  manifest.Version,
+		Checksum: pluginBundleChecksum(bundle),
+		Signed:   signature != nil,
+		UpdateAt: model.GetMillis(),
+	})
+}
+
+// removeStoredPluginBundle removes the bundle of a plugin from the file store, keeping its index
+// entry to record the removal.
+func (a *App) removeStoredPluginBundle(id string) *model.AppError {
+	now := model.GetMillis()
+	if appErr := a.savePluginIndexEntry(&model.PluginIndexEntry{
+		Id:       id,
+		UpdateAt: now,
+		DeleteAt: now,
+	}); appErr != nil {
+		return appErr
+	}
+
+	bundlePath, signaturePath := pluginStoreBundlePaths(id)
+	for _, path := range []string{bundlePath, signaturePath} {
+		if appErr := a.removeStoredFileIfExists(path); appErr != nil {
+			return appErr
+		}
+	}
+
+	return nil
+}
+
+func (a *App) removeStoredFileIfExists(path string) *model.AppError {
+	exists, appErr := a.FileExists(path)
+	if appErr != nil {
+		return appErr
+	}
+	if !exists {
+		return nil
+	}
+
+	return a.RemoveFile(path)
+}
+
+// SyncPluginsFromFileStore installs the plugins kept in the file store that are missing or out of
+// date on this server, and removes the plugins that were removed from the file store. It doesn't
+// activate or deactivate plugins, which is up to SyncPluginsActiveState.
+func (a *App) SyncPluginsFromFileStore() *model.AppError {
+	pluginsEnvironment := a.GetPluginsEnvironment()
+	if pluginsEnvironment == nil {
+		return nil
+	}
+
+	index, appErr := a.getPluginIndex()
+	if appErr != nil {
+		return appErr
+	}
+
+	plugins, err := pluginsEnvironment.Available()
+	if err != nil {
+		return model.NewAppError("SyncPluginsFromFileStore", "app.plugin.sync.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	installed := make(map[string]bool, len(plugins))
+	for _, plugin := range plugins {
+		if plugin.Manifest != nil {
+			installed[plugin.Manifest.Id] = true
+		}
+	}
+
+	for id := range index.RemovedPlugins {
+		if !installed[id] {
+			continue
+		}
+
+		mlog.Info("Removing plugin removed from the file store", mlog.String("plugin_id", id))
+		if appErr := a.removePluginLocally(id); appErr != nil {
+			mlog.Error("Failed to remove plugin", mlog.String("plugin_id", id), mlog.Err(appErr))
+		}
+	}
+
+	ids := make([]string, 0, len(index.Plugins))
+	for id := range index.Plugins {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		entry := index.Plugins[id]
+
+		localBundlePath, _ := a.pluginBundlePaths(id)
+		if installed[id] {
+			if localBundle, err := ioutil.ReadFile(localBundlePath); err == nil && pluginBundleChecksum(localBundle) == entry.Checksum {
+				continue
+			} else if err != nil && !os.IsNotExist(err) {
+				mlog.Error("Failed to read plugin bundle", mlog.String("plugin_id", id), mlog.Err(err))
+			}
+		}
+
+		if appErr := a.installPluginFromFileStore(entry); appErr != nil {
+			mlog.Error("Failed to install plugin from the file store", mlog.String("plugin_id", id), mlog.Err(appErr))
+			continue
+		}
+
+		mlog.Info("Installed plugin from the file store", mlog.String("plugin_id", id), mlog.String("version", entry.Version))
+	}
+
+	return nil
+}
+
+func (a *App) installPluginFromFileStore(entry *model.PluginIndexEntry) *model.AppError {
+	bundlePath, signaturePath := pluginStoreBundlePaths(entry.Id)
+
+	bundle, appErr := a.ReadFile(bundlePath)
+	if appErr != nil {
+		return appErr
+	}
+
+	if pluginBundleChecksum(bundle) != entry.Checksum {
+		return model.NewAppError("installPluginFromFileStore", "app.plugin.sync.checksum.app_error", map[string]interface{}{"PluginId": entry.Id}, "", http.StatusInternalServerError)
+	}
+
+	var signature []byte
+	if entry.Signed {
+		if signature, appErr = a.ReadFile(signaturePath); appErr != nil {
+			return appErr
+		}
+	}
+
+	_, appErr = a.installPluginLocally(bundle, signature, true)
+	return appErr
+}
+
+// notifyClusterPluginsChanged asks the other servers in the cluster to sync their plugins from the
+// file store.
+func (a *App) notifyClusterPluginsChanged() {
+	if a.Cluster == nil {
+		return
+	}
+
+	a.Cluster.SendClusterMessage(&model.ClusterMessage{
+		Event:            model.CLUSTER_EVENT_SYNC_PLUGINS,
+		SendType:         model.CLUSTER_SEND_RELIABLE,
+		WaitForAllToSend: true,
+	})
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils/fileutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncPluginsFromFileStore(t *testing.T) {
+	th := Setup(t)
+	defer th.TearDown()
+
+	path, _ := fileutils.FindDir("tests")
+	bundle, err := ioutil.ReadFile(filepath.Join(path, "testplugin.tar.gz"))
+	require.Nil(t, err)
+
+	fileDir, err := ioutil.TempDir("", "pluginstore")
+	require.Nil(t, err)
+	defer os.RemoveAll(fileDir)
+
+	th.App.UpdateConfig(func(cfg *model.Config) {
+		*cfg.PluginSettings.Enable = true
+		*cfg.PluginSettings.Directory = filepath.Join(th.tempWorkspace, "plugins")
+		*cfg.FileSettings.DriverName = model.IMAGE_DRIVER_LOCAL
+		*cfg.FileSettings.Directory = fileDir
+	})
+
+	pluginPath := filepath.Join(th.tempWorkspace, "plugins", "testplugin")
+
+	manifest, appErr := th.App.InstallPlugin(bytes.NewReader(bundle), true)
+	require.Nil(t, appErr)
+
+	index, appErr := th.App.getPluginIndex()
+	require.Nil(t, appErr)
+	require.NotNil(t, index.Plugins["testplugin"])
+	assert.Equal(t, manifest.Version, index.Plugins["testplugin"].Version)
+	assert.Equal(t, pluginBundleChecksum(bundle), index.Plugins["testplugin"].Checksum)
+	assert.False(t, index.Plugins["testplugin"].Signed)
+
+	t.Run("index entries are kept per plugin", func(t *testing.T) {
+		require.Nil(t, th.App.savePluginIndexEntry(&model.PluginIndexEntry{Id: "otherplugin", Version: "1.0.0", Checksum: "abc"}))
+		defer th.App.RemoveFile(pluginStoreEntryPath("otherplugin"))
+
+		index, appErr := th.App.getPluginIndex()
+		require.Nil(t, appErr)
+		assert.NotNil(t, index.Plugins["testplugin"])
+		assert.NotNil(t, index.Plugins["otherplugin"])
+	})
+
+	t.Run("install missing plugin", func(t *testing.T) {
+		require.Nil(t, th.App.removePluginLocally("testplugin"))
+		_, err := os.Stat(pluginPath)
+		require.True(t, os.IsNotExist(err))
+
+		require.Nil(t, th.App.SyncPluginsFromFileStore())
+		_, err = os.Stat(pluginPath)
+		assert.Nil(t, err)
+	})
+
+	t.Run("up to date plugin", func(t *testing.T) {
+		marker := filepath.Join(pluginPath, "marker")
+		require.Nil(t, ioutil.WriteFile(marker, []byte{}, 0600))
+
+		require.Nil(t, th.App.SyncPluginsFromFileStore())
+		_, err := os.Stat(marker)
+		assert.Nil(t, err)
+	})
+
+	t.Run("remove plugin", func(t *testing.T) {
+		require.Nil(t, th.App.RemovePlugin("testplugin"))
+
+		index, appErr := th.App.getPluginIndex()
+		require.Nil(t, appErr)
+		assert.Nil(t, index.Plugins["testplugin"])
+		assert.Contains(t, index.RemovedPlugins, "testplugin")
+
+		// A server that missed the removal removes its own copy when syncing.
+		_, appErr = th.App.installPluginLocally(bundle, nil, true)
+		require.Nil(t, appErr)
+
+		require.Nil(t, th.App.SyncPluginsFromFileStore())
+		_, err := os.Stat(pluginPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -858,9 +858,7 @@ func (a *App) SearchPostsInTeamForUser(terms string, userId string, teamId strin
 	paramsList := model.ParseSearchParams(terms, timeZoneOffset)
 	includeDeleted := includeDeletedChannels && *a.Config().TeamSettings.ExperimentalViewArchivedChannels
 
-	esInterface := a.Elasticsearch
-	license := a.License()
-	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableSearching && license != nil && *license.Features.Elasticsearch {
+	if *a.Config().ElasticsearchSettings.EnableSearching && a.isSearchEngineAvailable() {
 		finalParamsList := []*model.SearchParams{}
 
 		for _, params := range paramsList {
@@ -0,0 +1,245 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/utils"
+)
+
+// SetPostReminder reminds a user about a post at the given time, replacing any reminder they
+// already set on it.
+func (a *App) SetPostReminder(userId, postId string, targetTime int64) (*model.PostReminder, *model.AppError) {
+	if targetTime <= model.GetMillis() {
+		return nil, model.NewAppError("SetPostReminder", "app.post_reminder.target_time.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if _, err := a.GetSinglePost(postId); err != nil {
+		return nil, err
+	}
+
+	reminder := &model.PostReminder{
+		PostId:     postId,
+		UserId:     userId,
+		TargetTime: targetTime,
+	}
+
+	return a.Srv.Store.PostReminder().Save(reminder)
+}
+
+func (a *App) GetPostRemindersForUser(userId string) ([]*model.PostReminder, *model.AppError) {
+	return a.Srv.Store.PostReminder().GetForUser(userId)
+}
+
+func (a *App) DeletePostReminder(userId, postId string) *model.AppError {
+	deleted, err := a.Srv.Store.PostReminder().Delete(postId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return model.NewAppError("DeletePostReminder", "store.sql_post_reminder.get.app_error", nil, "post_id="+postId+", user_id="+userId, http.StatusNotFound)
+	}
+
+	return nil
+}
+
+// HasDuePostReminders returns whether any reminder is due at the given time.
+func (a *App) HasDuePostReminders(now int64) (bool, *model.AppError) {
+	reminders, err := a.Srv.Store.PostReminder().GetDue(now, 1)
+	if err != nil {
+		return false, err
+	}
+
+	return len(reminders) > 0, nil
+}
+
+// SendDuePostReminders sends up to limit reminders due at the given time, and returns how many
+// were handled, whether sent or dropped.
+func (a *App) SendDuePostReminders(now int64, limit int) (int, *model.AppError) {
+	reminders, err := a.Srv.Store.PostReminder().GetDue(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(reminders) == 0 {
+		return 0, nil
+	}
+
+	bot, err := a.GetSystemBot()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, reminder := range reminders {
+		a.sendPostReminder(bot, reminder)
+	}
+
+	return len(reminders), nil
+}
+
+func (a *App) sendPostReminder(bot *model.Bot, reminder *model.PostReminder) {
+	if !claimDueItem(func() (bool, *model.AppError) {
+		return a.Srv.Store.PostReminder().Delete(reminder.PostId, reminder.UserId)
+	}, "post reminder", mlog.String("post_id", reminder.PostId), mlog.String("user_id", reminder.UserId)) {
+		return
+	}
+
+	user, err := a.GetUser(reminder.UserId)
+	if err != nil || user.DeleteAt != 0 {
+		return
+	}
+
+	post, err := a.GetSinglePost(reminder.PostId)
+	if err != nil {
+		return
+	}
+
+	// The user may have left the channel since setting the reminder.
+	if !a.HasPermissionToChannel(user.Id, post.ChannelId, model.PERMISSION_READ_CHANNEL) {
+		return
+	}
+
+	teamName, err := a.getPostReminderTeamName(user.Id, post.ChannelId)
+	if err != nil {
+		mlog.Error("Failed to get team for post reminder", mlog.String("post_id", reminder.PostId), mlog.String("user_id", reminder.UserId), mlog.Err(err))
+		return
+	}
+
+	channel, err := a.GetOrCreateDirectChannel(bot.UserId, user.Id)
+	if err != nil {
+		mlog.Error("Failed to get direct channel for post reminder", mlog.String("post_id", reminder.PostId), mlog.String("user_id", reminder.UserId), mlog.Err(err))
+		return
+	}
+
+	T := utils.GetUserTranslations(user.Locale)
+	permalink := a.GetSiteURL() + "/" + teamName + "/pl/" + post.Id
+
+	reminderPost := &model.Post{
+		UserId:    bot.UserId,
+		ChannelId: channel.Id,
+		Message:   T("app.post_reminder.message", map[string]interface{}{"Permalink": permalink}),
+	}
+	reminderPost.AddProp(model.POST_PROPS_REMINDED_POST_ID, post.Id)
+	model.ParseSlackAttachment(reminderPost, []*model.SlackAttachment{{
+		Actions: []*model.PostAction{
+			postReminderAction(T("app.post_reminder.snooze"), model.POST_REMINDER_ACTION_SNOOZE, post.Id),
+			postReminderAction(T("app.post_reminder.complete"), model.POST_REMINDER_ACTION_COMPLETE, post.Id),
+		},
+	}})
+
+	if _, err := a.CreatePost(reminderPost, channel, false); err != nil {
+		mlog.Error("Failed to send post reminder", mlog.String("post_id", reminder.PostId), mlog.String("user_id", reminder.UserId), mlog.Err(err))
+	}
+}
+
+// getPostReminderTeamName returns the team to link to the post through, which for direct and
+// group channels is any team of the user.
+func (a *App) getPostReminderTeamName(userId, channelId string) (string, *model.AppError) {
+	channel, err := a.GetChannel(channelId)
+	if err != nil {
+		return "", err
+	}
+
+	if channel.TeamId != "" {
+		team, err := a.GetTeam(channel.TeamId)
+		if err != nil {
+			return "", err
+		}
+		return team.Name, nil
+	}
+
+	teams, err := a.GetTeamsForUser(userId)
+	if err != nil {
+		return "", err
+	}
+
+	if len(teams) == 0 {
+		return "", model.NewAppError("getPostReminderTeamName", "app.post_reminder.no_team.app_error", nil, "user_id="+userId, http.StatusBadRequest)
+	}
+
+	return teams[0].Name, nil
+}
+
+func postReminderAction(name, action, postId string) *model.PostAction {
+	return &model.PostAction{
+		Name: name,
+		Type: model.POST_ACTION_TYPE_BUTTON,
+		Integration: &model.PostActionIntegration{
+			URL: model.POST_REMINDER_INTEGRATION_URL,
+			Context: map[string]interface{}{
+				"action":  action,
+				"post_id": postId,
+			},
+		},
+	}
+}
+
+// doPostReminderAction handles the buttons of a reminder, and replaces them with what was done.
+func (a *App) doPostReminderAction(upstreamRequest *model.PostActionIntegrationRequest) (*model.PostActionIntegrationResponse, *model.AppError) {
+	action, _ := upstreamRequest.Context["action"].(string)
+	postId, _ := upstreamRequest.Context["post_id"].(string)
+
+	user, err := a.GetUser(upstreamRequest.UserId)
+	if err != nil {
+		return nil, err
+	}
+	T := utils.GetUserTranslations(user.Locale)
+
+	reminderPost, err := a.GetSinglePost(upstreamRequest.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.checkPostReminderPost(reminderPost, user.Id); err != nil {
+		return nil, err
+	}
+
+	var status string
+	switch action {
+	case model.POST_REMINDER_ACTION_SNOOZE:
+		targetTime := model.GetMillis() + int64(model.POST_REMINDER_SNOOZE_MINUTES*time.Minute/time.Millisecond)
+		if _, err := a.SetPostReminder(user.Id, postId, targetTime); err != nil {
+			return nil, err
+		}
+		status = T("app.post_reminder.snoozed")
+	case model.POST_REMINDER_ACTION_COMPLETE:
+		status = T("app.post_reminder.completed")
+	default:
+		return nil, model.NewAppError("doPostReminderAction", "api.post.do_action.action_integration.app_error", nil, "action="+action, http.StatusBadRequest)
+	}
+
+	update := reminderPost.Clone()
+	update.Props = model.StringInterface{}
+	update.AddProp(model.POST_PROPS_REMINDED_POST_ID, postId)
+	model.ParseSlackAttachment(update, []*model.SlackAttachment{{Text: status}})
+
+	return &model.PostActionIntegrationResponse{Update: update}, nil
+}
+
+// checkPostReminderPost checks that a post is a reminder sent to the given user, since anybody can
+// post buttons with the integration URL of reminders.
+func (a *App) checkPostReminderPost(post *model.Post, userId string) *model.AppError {
+	bot, err := a.GetSystemBot()
+	if err != nil {
+		return err
+	}
+
+	if post.UserId == bot.UserId {
+		channel, err := a.GetChannel(post.ChannelId)
+		if err != nil {
+			return err
+		}
+
+		if channel.Type == model.CHANNEL_DIRECT && channel.Name == model.GetDMNameFromIds(bot.UserId, userId) {
+			return nil
+		}
+	}
+
+	return model.NewAppError("doPostReminderAction", "api.post.do_action.post_reminder.app_error", nil, "post_id="+post.Id+", user_id="+userId, http.StatusForbidden)
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestSetPostReminder(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	targetTime := model.GetMillis() + 60*60*1000
+
+	reminder, err := th.App.SetPostReminder(th.BasicUser.Id, th.BasicPost.Id, targetTime)
+	require.Nil(t, err)
+	assert.Equal(t, targetTime, reminder.TargetTime)
+
+	_, err = th.App.SetPostReminder(th.BasicUser.Id, th.BasicPost.Id, targetTime+1000)
+	require.Nil(t, err)
+
+	reminders, err := th.App.GetPostRemindersForUser(th.BasicUser.Id)
+	require.Nil(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, targetTime+1000, reminders[0].TargetTime)
+
+	_, err = th.App.SetPostReminder(th.BasicUser.Id, th.BasicPost.Id, model.GetMillis()-1000)
+	assert.NotNil(t, err)
+
+	_, err = th.App.SetPostReminder(th.BasicUser.Id, model.NewId(), targetTime)
+	assert.NotNil(t, err)
+
+	require.Nil(t, th.App.DeletePostReminder(th.BasicUser.Id, th.BasicPost.Id))
+	assert.NotNil(t, th.App.DeletePostReminder(th.BasicUser.Id, th.BasicPost.Id))
+}
+
+func TestSendDuePostReminders(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	remind := func() *model.Post {
+		_, err := th.App.Srv.Store.PostReminder().Save(&model.PostReminder{
+			PostId:     th.BasicPost.Id,
+			UserId:     th.BasicUser.Id,
+			TargetTime: model.GetMillis() - 1000,
+		})
+		require.Nil(t, err)
+
+		count, err := th.App.SendDuePostReminders(model.GetMillis(), 100)
+		require.Nil(t, err)
+		require.Equal(t, 1, count)
+
+		bot, err := th.App.GetSystemBot()
+		require.Nil(t, err)
+
+		channel, err := th.App.GetOrCreateDirectChannel(bot.UserId, th.BasicUser.Id)
+		require.Nil(t, err)
+
+		posts, err := th.App.GetPostsPage(channel.Id, 0, 1)
+		require.Nil(t, err)
+		require.Len(t, posts.Order, 1)
+
+		post := posts.Posts[posts.Order[0]]
+		assert.Equal(t, th.BasicPost.Id, post.Props[model.POST_PROPS_REMINDED_POST_ID])
+		assert.Contains(t, post.Message, "/"+th.BasicTeam.Name+"/pl/"+th.BasicPost.Id)
+
+		reminders, err := th.App.GetPostRemindersForUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Empty(t, reminders)
+
+		return post
+	}
+
+	doAction := func(post *model.Post, name string) {
+		for _, action := range post.Attachments()[0].Actions {
+			if action.Integration.Context["action"] == name {
+				_, err := th.App.DoPostActionWithCookie(post.Id, action.Id, th.BasicUser.Id, "", nil)
+				require.Nil(t, err)
+			}
+		}
+
+		updated, err := th.App.GetSinglePost(post.Id)
+		require.Nil(t, err)
+		require.Len(t, updated.Attachments(), 1)
+		assert.Empty(t, updated.Attachments()[0].Actions)
+		assert.Equal(t, th.BasicPost.Id, updated.Props[model.POST_PROPS_REMINDED_POST_ID])
+	}
+
+	t.Run("snooze", func(t *testing.T) {
+		post := remind()
+		doAction(post, model.POST_REMINDER_ACTION_SNOOZE)
+
+		reminders, err := th.App.GetPostRemindersForUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		require.Len(t, reminders, 1)
+		assert.True(t, reminders[0].TargetTime > model.GetMillis())
+
+		require.Nil(t, th.App.DeletePostReminder(th.BasicUser.Id, th.BasicPost.Id))
+	})
+
+	t.Run("complete", func(t *testing.T) {
+		post := remind()
+		doAction(post, model.POST_REMINDER_ACTION_COMPLETE)
+
+		reminders, err := th.App.GetPostRemindersForUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Empty(t, reminders)
+	})
+
+	t.Run("not sent by the system bot", func(t *testing.T) {
+		forged := &model.Post{
+			UserId:    th.BasicUser2.Id,
+			ChannelId: th.BasicChannel.Id,
+			Message:   "reminder",
+		}
+		model.ParseSlackAttachment(forged, []*model.SlackAttachment{{
+			Actions: []*model.PostAction{
+				postReminderAction("snooze", model.POST_REMINDER_ACTION_SNOOZE, th.BasicPost.Id),
+			},
+		}})
+		forged, err := th.App.CreatePostAsUser(forged, "")
+		require.Nil(t, err)
+
+		_, err = th.App.DoPostActionWithCookie(forged.Id, forged.Attachments()[0].Actions[0].Id, th.BasicUser.Id, "", nil)
+		require.NotNil(t, err)
+		assert.Equal(t, http.StatusForbidden, err.StatusCode)
+
+		reminders, err := th.App.GetPostRemindersForUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Empty(t, reminders)
+	})
+
+	t.Run("dropped without access to the post", func(t *testing.T) {
+		otherChannel := th.CreatePrivateChannel(th.BasicTeam)
+		otherPost := th.CreatePost(otherChannel)
+		require.Nil(t, th.App.RemoveUserFromChannel(th.BasicUser.Id, th.BasicUser.Id, otherChannel))
+
+		_, err := th.App.Srv.Store.PostReminder().Save(&model.PostReminder{
+			PostId:     otherPost.Id,
+			UserId:     th.BasicUser.Id,
+			TargetTime: model.GetMillis() - 1000,
+		})
+		require.Nil(t, err)
+
+		count, err := th.App.SendDuePostReminders(model.GetMillis(), 100)
+		require.Nil(t, err)
+		assert.Equal(t, 1, count)
+
+		reminders, err := th.App.GetPostRemindersForUser(th.BasicUser.Id)
+		require.Nil(t, err)
+		assert.Empty(t, reminders)
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// GetPostSeenBy returns the members of a direct or group channel, other than the author of a post,
+// who viewed the channel since the post was made. The members who opted out of read receipts are
+// left out.
+func (a *App) GetPostSeenBy(post *model.Post) ([]string, *model.AppError) {
+	channel, err := a.GetChannel(post.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.Type != model.CHANNEL_DIRECT && channel.Type != model.CHANNEL_GROUP {
+		return nil, model.NewAppError("GetPostSeenBy", "app.read_receipt.channel_type.app_error", nil, "channel_id="+channel.Id, http.StatusBadRequest)
+	}
+
+	seenBy := []string{}
+	if !*a.Config().PrivacySettings.EnableReadReceipts {
+		return seenBy, nil
+	}
+
+	result := <-a.Srv.Store.Channel().GetMembers(channel.Id, 0, model.CHANNEL_GROUP_MAX_USERS)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	for _, member := range *result.Data.(*model.ChannelMembers) {
+		if member.UserId == post.UserId || member.LastViewedAt < post.CreateAt {
+			continue
+		}
+
+		if a.sendsReadReceipts(member.UserId) {
+			seenBy = append(seenBy, member.UserId)
+		}
+	}
+
+	return seenBy, nil
+}
+
+// publishPostsSeen lets the other members of the direct and group channels a user just viewed know
+// about it. A single event per channel covers every post made up to the time it was viewed at.
+func (a *App) publishPostsSeen(userId string, viewedAt map[string]int64) {
+	if !*a.Config().PrivacySettings.EnableReadReceipts || !a.sendsReadReceipts(userId) {
+		return
+	}
+
+	for channelId, lastViewedAt := range viewedAt {
+		channel, err := a.Srv.Store.Channel().Get(channelId, true)
+		if err != nil {
+			mlog.Warn("Failed to get channel to send read receipts", mlog.String("channel_id", channelId), mlog.Err(err))
+			continue
+		}
+
+		if channel.Type != model.CHANNEL_DIRECT && channel.Type != model.CHANNEL_GROUP {
+			continue
+		}
+
+		message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_POST_SEEN, "", channelId, "", map[string]bool{userId: true})
+		message.Add("user_id", userId)
+		message.Add("last_viewed_at", lastViewedAt)
+		a.Publish(message)
+	}
+}
+
+// sendsReadReceipts returns whether a user lets the other members of their direct and group
+// channels know which posts they saw.
+func (a *App) sendsReadReceipts(userId string) bool {
+	preference, err := a.Srv.Store.Preference().Get(userId, model.PREFERENCE_CATEGORY_DISPLAY_SETTINGS, model.PREFERENCE_NAME_SEND_READ_RECEIPTS)
+	if err != nil {
+		return true
+	}
+
+	return preference.Value != "false"
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestGetPostSeenBy(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	channel := th.CreateDmChannel(th.BasicUser2)
+	post := th.CreatePost(channel)
+
+	seenBy, err := th.App.GetPostSeenBy(post)
+	require.Nil(t, err)
+	assert.Empty(t, seenBy)
+
+	_, err = th.App.MarkChannelsAsViewed([]string{channel.Id}, th.BasicUser2.Id, "")
+	require.Nil(t, err)
+
+	seenBy, err = th.App.GetPostSeenBy(post)
+	require.Nil(t, err)
+	assert.Equal(t, []string{th.BasicUser2.Id}, seenBy)
+
+	t.Run("disabled", func(t *testing.T) {
+		th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PrivacySettings.EnableReadReceipts = false })
+		defer th.App.UpdateConfig(func(cfg *model.Config) { *cfg.PrivacySettings.EnableReadReceipts = true })
+
+		seenBy, err := th.App.GetPostSeenBy(post)
+		require.Nil(t, err)
+		assert.Empty(t, seenBy)
+	})
+
+	t.Run("opted out", func(t *testing.T) {
+		preference := model.Preference{UserId: th.BasicUser2.Id, Category: model.PREFERENCE_CATEGORY_DISPLAY_SETTINGS, Name: model.PREFERENCE_NAME_SEND_READ_RECEIPTS, Value: "false"}
+		require.Nil(t, th.App.UpdatePreferences(th.BasicUser2.Id, model.Preferences{preference}))
+		defer th.App.DeletePreferences(th.BasicUser2.Id, model.Preferences{preference})
+
+		seenBy, err := th.App.GetPostSeenBy(post)
+		require.Nil(t, err)
+		assert.Empty(t, seenBy)
+	})
+
+	t.Run("not a direct or group channel", func(t *testing.T) {
+		_, err := th.App.GetPostSeenBy(th.BasicPost)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// The author of a scheduled post may have been deleted for good since, in which case the post is
+// dropped rather than kept with an error.
+const scheduledPostUserNotFound = "user_not_found"
+
+func (a *App) CreateScheduledPost(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	scheduledPost.Id = ""
+	scheduledPost.ErrorCode = ""
+	scheduledPost.PreSave()
+
+	if scheduledPost.ScheduledAt <= model.GetMillis() {
+		return nil, model.NewAppError("CreateScheduledPost", "app.scheduled_post.scheduled_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if err := scheduledPost.IsValid(a.MaxPostSize()); err != nil {
+		return nil, err
+	}
+
+	channel, err := a.GetChannel(scheduledPost.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if channel.DeleteAt != 0 {
+		return nil, model.NewAppError("CreateScheduledPost", "api.post.create_post.can_not_post_to_deleted.error", nil, "", http.StatusBadRequest)
+	}
+
+	if scheduledPost.RootId != "" {
+		root, err := a.GetSinglePost(scheduledPost.RootId)
+		if err != nil || root.ChannelId != scheduledPost.ChannelId {
+			return nil, model.NewAppError("CreateScheduledPost", "api.post.create_post.root_id.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	return a.Srv.Store.ScheduledPost().Save(scheduledPost)
+}
+
+func (a *App) GetScheduledPost(scheduledPostId string) (*model.ScheduledPost, *model.AppError) {
+	return a.Srv.Store.ScheduledPost().Get(scheduledPostId)
+}
+
+func (a *App) GetScheduledPostsForUser(userId string) ([]*model.ScheduledPost, *model.AppError) {
+	return a.Srv.Store.ScheduledPost().GetForUser(userId)
+}
+
+// PatchScheduledPost changes a scheduled post. A post that failed to be published is retried once
+// edited, right away if it was already due.
+func (a *App) PatchScheduledPost(scheduledPostId string, patch *model.ScheduledPostPatch) (*model.ScheduledPost, *model.AppError) {
+	scheduledPost, err := a.GetScheduledPost(scheduledPostId)
+	if err != nil {
+		return nil, err
+	}
+
+	if patch.ScheduledAt != nil && *patch.ScheduledAt != scheduledPost.ScheduledAt && *patch.ScheduledAt <= model.GetMillis() {
+		return nil, model.NewAppError("PatchScheduledPost", "app.scheduled_post.scheduled_at.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	scheduledPost.Patch(patch)
+	scheduledPost.ErrorCode = ""
+
+	if err := scheduledPost.IsValid(a.MaxPostSize()); err != nil {
+		return nil, err
+	}
+
+	return a.Srv.Store.ScheduledPost().Update(scheduledPost)
+}
+
+func (a *App) DeleteScheduledPost(scheduledPostId string) *model.AppError {
+	deleted, err := a.Srv.Store.ScheduledPost().Delete(scheduledPostId)
+	if err != nil {
+		return err
+	}
+
+	if !deleted {
+		return model.NewAppError("DeleteScheduledPost", "store.sql_scheduled_post.get.app_error", nil, "id="+scheduledPostId, http.StatusNotFound)
+	}
+
+	return nil
+}
+
+// HasDueScheduledPosts returns whether any scheduled post is due at the given time.
+func (a *App) HasDueScheduledPosts(now int64) (bool, *model.AppError) {
+	scheduledPosts, err := a.Srv.Store.ScheduledPost().GetDue(now, 1)
+	if err != nil {
+		return false, err
+	}
+
+	return len(scheduledPosts) > 0, nil
+}
+
+// PublishDueScheduledPosts posts up to limit scheduled posts due at the given time, and returns
+// how many were handled, whether published or failed.
+func (a *App) PublishDueScheduledPosts(now int64, limit int) (int, *model.AppError) {
+	scheduledPosts, err := a.Srv.Store.ScheduledPost().GetDue(now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, scheduledPost := range scheduledPosts {
+		a.publishScheduledPost(scheduledPost)
+	}
+
+	return len(scheduledPosts), nil
+}
+
+func (a *App) publishScheduledPost(scheduledPost *model.ScheduledPost) {
+	if !claimDueItem(func() (bool, *model.AppError) {
+		return a.Srv.Store.ScheduledPost().Delete(scheduledPost.Id)
+	}, "scheduled post", mlog.String("scheduled_post_id", scheduledPost.Id)) {
+		return
+	}
+
+	errorCode := a.checkScheduledPostCanBePublished(scheduledPost)
+	if errorCode == "" {
+		_, err := a.CreatePostAsUser(scheduledPost.ToPost(), "")
+		if err == nil {
+			return
+		}
+
+		mlog.Warn("Failed to publish scheduled post", mlog.String("scheduled_post_id", scheduledPost.Id), mlog.Err(err))
+		if err.Id == "api.post.create_post.root_id.app_error" || err.Id == "api.post.create_post.channel_root_id.app_error" {
+			errorCode = model.SCHEDULED_POST_ERROR_THREAD_NOT_FOUND
+		} else {
+			errorCode = model.SCHEDULED_POST_ERROR_UNKNOWN
+		}
+	}
+
+	if errorCode == scheduledPostUserNotFound {
+		return
+	}
+
+	// The post is kept for its author to edit or delete.
+	scheduledPost.ErrorCode = errorCode
+	if _, err := a.Srv.Store.ScheduledPost().Save(scheduledPost); err != nil {
+		mlog.Error("Failed to save scheduled post that couldn't be published", mlog.String("scheduled_post_id", scheduledPost.Id), mlog.Err(err))
+		return
+	}
+
+	message := model.NewWebSocketEvent(model.WEBSOCKET_EVENT_SCHEDULED_POST_FAILED, "", "", scheduledPost.UserId, nil)
+	message.Add("scheduled_post", scheduledPost.ToJson())
+	a.Publish(message)
+}
+
+// checkScheduledPostCanBePublished returns why the scheduled post can't be published anymore, if
+// anything changed since it was written.
+func (a *App) checkScheduledPostCanBePublished(scheduledPost *model.ScheduledPost) string {
+	user, err := a.GetUser(scheduledPost.UserId)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return scheduledPostUserNotFound
+		}
+		return model.SCHEDULED_POST_ERROR_UNKNOWN
+	}
+
+	if user.DeleteAt != 0 {
+		return model.SCHEDULED_POST_ERROR_USER_DEACTIVATED
+	}
+
+	channel, err := a.GetChannel(scheduledPost.ChannelId)
+	if err != nil {
+		if err.StatusCode == http.StatusNotFound {
+			return model.SCHEDULED_POST_ERROR_CHANNEL_NOT_FOUND
+		}
+		return model.SCHEDULED_POST_ERROR_UNKNOWN
+	}
+
+	if channel.DeleteAt != 0 {
+		return model.SCHEDULED_POST_ERROR_CHANNEL_ARCHIVED
+	}
+
+	if !a.HasPermissionToChannel(scheduledPost.UserId, scheduledPost.ChannelId, model.PERMISSION_CREATE_POST) {
+		return model.SCHEDULED_POST_ERROR_NO_PERMISSION
+	}
+
+	return ""
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestCreateScheduledPost(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	scheduledAt := model.GetMillis() + 60*60*1000
+
+	scheduledPost, err := th.App.CreateScheduledPost(&model.ScheduledPost{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, RootId: th.BasicPost.Id, Message: "reply", ScheduledAt: scheduledAt})
+	require.Nil(t, err)
+	assert.NotEmpty(t, scheduledPost.Id)
+
+	_, err = th.App.CreateScheduledPost(&model.ScheduledPost{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, Message: "past", ScheduledAt: model.GetMillis() - 1000})
+	assert.NotNil(t, err)
+
+	_, err = th.App.CreateScheduledPost(&model.ScheduledPost{UserId: th.BasicUser.Id, ChannelId: model.NewId(), Message: "missing channel", ScheduledAt: scheduledAt})
+	assert.NotNil(t, err)
+
+	otherChannel := th.CreateChannel(th.BasicTeam)
+	_, err = th.App.CreateScheduledPost(&model.ScheduledPost{UserId: th.BasicUser.Id, ChannelId: otherChannel.Id, RootId: th.BasicPost.Id, Message: "reply elsewhere", ScheduledAt: scheduledAt})
+	assert.NotNil(t, err)
+
+	t.Run("edit retries failed post", func(t *testing.T) {
+		scheduledPost.ErrorCode = model.SCHEDULED_POST_ERROR_NO_PERMISSION
+		_, err := th.App.Srv.Store.ScheduledPost().Update(scheduledPost)
+		require.Nil(t, err)
+
+		message := "edited"
+		patched, err := th.App.PatchScheduledPost(scheduledPost.Id, &model.ScheduledPostPatch{Message: &message})
+		require.Nil(t, err)
+		assert.Equal(t, "edited", patched.Message)
+		assert.Empty(t, patched.ErrorCode)
+	})
+
+	require.Nil(t, th.App.DeleteScheduledPost(scheduledPost.Id))
+	assert.NotNil(t, th.App.DeleteScheduledPost(scheduledPost.Id))
+}
+
+func TestPublishDueScheduledPosts(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	schedule := func(userId, channelId string) *model.ScheduledPost {
+		scheduledPost, err := th.App.Srv.Store.ScheduledPost().Save(&model.ScheduledPost{
+			UserId:      userId,
+			ChannelId:   channelId,
+			Message:     "scheduled " + model.NewId(),
+			ScheduledAt: model.GetMillis() - 1000,
+		})
+		require.Nil(t, err)
+		return scheduledPost
+	}
+
+	publish := func() {
+		_, err := th.App.PublishDueScheduledPosts(model.GetMillis(), 100)
+		require.Nil(t, err)
+	}
+
+	lastMessage := func(channelId string) string {
+		posts, err := th.App.GetPostsPage(channelId, 0, 1)
+		require.Nil(t, err)
+		return posts.Posts[posts.Order[0]].Message
+	}
+
+	t.Run("published", func(t *testing.T) {
+		tearDown, _, activationErrors := SetAppEnvironmentWithPlugins(t, []string{
+			`
+			package main
+
+			import (
+				"github.com/mattermost/mattermost-server/plugin"
+				"github.com/mattermost/mattermost-server/model"
+			)
+
+			type MyPlugin struct {
+				plugin.MattermostPlugin
+			}
+
+			func (p *MyPlugin) MessageWillBePosted(c *plugin.Context, post *model.Post) (*model.Post, string) {
+				post.Message = post.Message + " (seen by plugin)"
+				return post, ""
+			}
+
+			func main() {
+				plugin.ClientMain(&MyPlugin{})
+			}
+			`,
+		}, th.App, th.App.NewPluginAPI)
+		defer tearDown()
+		require.Nil(t, activationErrors[0])
+
+		scheduledPost := schedule(th.BasicUser.Id, th.BasicChannel.Id)
+
+		due, err := th.App.HasDueScheduledPosts(model.GetMillis())
+		require.Nil(t, err)
+		assert.True(t, due)
+
+		publish()
+
+		assert.Equal(t, scheduledPost.Message+" (seen by plugin)", lastMessage(th.BasicChannel.Id))
+
+		due, err = th.App.HasDueScheduledPosts(model.GetMillis())
+		require.Nil(t, err)
+		assert.False(t, due)
+
+		_, err = th.App.GetScheduledPost(scheduledPost.Id)
+		assert.NotNil(t, err, "published posts shouldn't be scheduled anymore")
+	})
+
+	t.Run("archived channel", func(t *testing.T) {
+		channel := th.CreateChannel(th.BasicTeam)
+		scheduledPost := schedule(th.BasicUser.Id, channel.Id)
+		require.Nil(t, th.App.DeleteChannel(channel, th.BasicUser.Id))
+
+		publish()
+
+		failed, err := th.App.GetScheduledPost(scheduledPost.Id)
+		require.Nil(t, err)
+		assert.Equal(t, model.SCHEDULED_POST_ERROR_CHANNEL_ARCHIVED, failed.ErrorCode)
+
+		// Failed posts aren't retried until edited.
+		publish()
+		failed, err = th.App.GetScheduledPost(scheduledPost.Id)
+		require.Nil(t, err)
+		assert.Equal(t, model.SCHEDULED_POST_ERROR_CHANNEL_ARCHIVED, failed.ErrorCode)
+	})
+
+	t.Run("revoked membership", func(t *testing.T) {
+		channel := th.CreatePrivateChannel(th.BasicTeam)
+		th.AddUserToChannel(th.BasicUser2, channel)
+		scheduledPost := schedule(th.BasicUser2.Id, channel.Id)
+		require.Nil(t, th.App.RemoveUserFromChannel(th.BasicUser2.Id, th.BasicUser.Id, channel))
+
+		publish()
+
+		failed, err := th.App.GetScheduledPost(scheduledPost.Id)
+		require.Nil(t, err)
+		assert.Equal(t, model.SCHEDULED_POST_ERROR_NO_PERMISSION, failed.ErrorCode)
+		assert.NotEqual(t, scheduledPost.Message, lastMessage(channel.Id))
+	})
+
+	t.Run("deactivated user", func(t *testing.T) {
+		user := th.CreateUser()
+		th.LinkUserToTeam(user, th.BasicTeam)
+		th.AddUserToChannel(user, th.BasicChannel)
+		scheduledPost := schedule(user.Id, th.BasicChannel.Id)
+		_, err := th.App.UpdateActive(user, false)
+		require.Nil(t, err)
+
+		publish()
+
+		failed, err := th.App.GetScheduledPost(scheduledPost.Id)
+		require.Nil(t, err)
+		assert.Equal(t, model.SCHEDULED_POST_ERROR_USER_DEACTIVATED, failed.ErrorCode)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		scheduledPost := schedule(model.NewId(), th.BasicChannel.Id)
+
+		publish()
+
+		_, err := th.App.GetScheduledPost(scheduledPost.Id)
+		assert.NotNil(t, err, "the posts of deleted users should be dropped")
+	})
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/mattermost/mattermost-server/plugin"
 	"github.com/mattermost/mattermost-server/services/httpservice"
 	"github.com/mattermost/mattermost-server/services/imageproxy"
+	"github.com/mattermost/mattermost-server/services/openid"
 	"github.com/mattermost/mattermost-server/services/timezones"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/mattermost/mattermost-server/utils"
@@ -106,6 +107,9 @@ type Server struct {
 
 	HTTPService httpservice.HTTPService
 
+	openIdProviderMutex sync.Mutex
+	openIdProvider      *openid.Provider
+
 	ImageProxy *imageproxy.ImageProxy
 
 	Log              *mlog.Logger
@@ -725,6 +729,11 @@ func (s *Server) StartElasticsearch() {
 	})
 
 	s.AddLicenseListener(func() {
+		// Embedded search engines don't depend on the license.
+		if embedded, ok := s.Elasticsearch.(einterfaces.EmbeddedSearchInterface); ok && embedded.IsEmbedded() {
+			return
+		}
+
 		if s.License() != nil {
 			s.Go(func() {
 				if err := s.Elasticsearch.Start(); err != nil {
@@ -767,6 +767,11 @@ func (a *App) OldImportUser(team *model.Team, user *model.User) *model.User {
 
 	user.Roles = model.SYSTEM_USER_ROLE_ID
 
+	if model.IsReservedUsername(user.Username) {
+		mlog.Error("Error saving user, the username is reserved.", mlog.String("username", user.Username))
+		return nil
+	}
+
 	result := <-a.Srv.Store.User().Save(user)
 	if result.Err != nil {
 		mlog.Error(fmt.Sprintf("Error saving user. err=%v", result.Err))
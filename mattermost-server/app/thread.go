@@ -0,0 +1,177 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// GetThreadsForUser returns a page of the threads a user follows in a team, including direct and
+// group channels, most recently updated first.
+func (a *App) GetThreadsForUser(userId, teamId string, page, perPage int) ([]*model.UserThread, *model.AppError) {
+	memberships, err := a.Srv.Store.Thread().GetFollowedForUser(userId, teamId, page*perPage, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	threads := []*model.UserThread{}
+	if len(memberships) == 0 {
+		return threads, nil
+	}
+
+	postIds := make([]string, 0, len(memberships))
+	for _, membership := range memberships {
+		postIds = append(postIds, membership.PostId)
+	}
+
+	result := <-a.Srv.Store.Post().GetPostsByIds(postIds)
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	posts := make(map[string]*model.Post, len(postIds))
+	for _, post := range result.Data.([]*model.Post) {
+		posts[post.Id] = post
+	}
+
+	replyCounts, err := a.Srv.Store.Thread().GetReplyCounts(postIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, membership := range memberships {
+		post, ok := posts[membership.PostId]
+		if !ok {
+			continue
+		}
+
+		threads = append(threads, &model.UserThread{
+			ThreadMembership: *membership,
+			ReplyCount:       replyCounts[membership.PostId],
+			Post:             a.PreparePostForClient(post, false, false),
+		})
+	}
+
+	return threads, nil
+}
+
+// UpdateThreadFollowing starts or stops a user following a thread.
+func (a *App) UpdateThreadFollowing(userId, postId string, following bool) (*model.ThreadMembership, *model.AppError) {
+	post, err := a.GetSinglePost(postId)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.RootId != "" {
+		return nil, model.NewAppError("UpdateThreadFollowing", "app.thread.not_root_post.app_error", nil, "post_id="+postId, http.StatusBadRequest)
+	}
+
+	membership, err := a.Srv.Store.Thread().GetMembership(postId, userId)
+	if err != nil {
+		if err.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+
+		now := model.GetMillis()
+		membership = &model.ThreadMembership{PostId: postId, UserId: userId, LastViewed: now, LastUpdated: now}
+	}
+
+	membership.Following = following
+
+	membership, err = a.Srv.Store.Thread().SaveMembership(membership)
+	if err != nil {
+		return nil, err
+	}
+
+	a.publishThreadEvent(model.WEBSOCKET_EVENT_THREAD_FOLLOW_CHANGED, membership)
+
+	return membership, nil
+}
+
+// MarkThreadAsRead clears the unread replies and mentions of a thread for a user.
+func (a *App) MarkThreadAsRead(userId, postId string) (*model.ThreadMembership, *model.AppError) {
+	membership, err := a.Srv.Store.Thread().GetMembership(postId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.MarkRead(model.GetMillis())
+
+	membership, err = a.Srv.Store.Thread().SaveMembership(membership)
+	if err != nil {
+		return nil, err
+	}
+
+	a.publishThreadEvent(model.WEBSOCKET_EVENT_THREAD_READ_CHANGED, membership)
+
+	return membership, nil
+}
+
+// updateThreadMembershipsForReply makes the author of a reply, the author of the thread and the
+// users the reply mentions follow the thread, unless they stopped following it, and counts the
+// reply as unread for the other followers.
+func (a *App) updateThreadMembershipsForReply(post *model.Post, rootPost *model.Post, mentionedUserIds []string) {
+	memberships, err := a.Srv.Store.Thread().GetMembershipsForThread(post.RootId)
+	if err != nil {
+		mlog.Error("Failed to get thread memberships", mlog.String("post_id", post.RootId), mlog.Err(err))
+		return
+	}
+
+	existing := make(map[string]*model.ThreadMembership, len(memberships))
+	for _, membership := range memberships {
+		existing[membership.UserId] = membership
+	}
+
+	// Replying follows the thread again, and reads it.
+	author, ok := existing[post.UserId]
+	if !ok {
+		author = &model.ThreadMembership{PostId: post.RootId, UserId: post.UserId}
+	}
+	author.Following = true
+	author.LastUpdated = post.CreateAt
+	author.MarkRead(post.CreateAt)
+	if _, err := a.Srv.Store.Thread().SaveMembership(author); err != nil {
+		mlog.Error("Failed to save thread membership", mlog.String("post_id", post.RootId), mlog.String("user_id", post.UserId), mlog.Err(err))
+	}
+
+	newFollowerIds := append([]string{rootPost.UserId}, mentionedUserIds...)
+	for _, userId := range newFollowerIds {
+		if _, ok := existing[userId]; ok || userId == post.UserId {
+			continue
+		}
+
+		membership := &model.ThreadMembership{PostId: post.RootId, UserId: userId, Following: true, LastViewed: rootPost.CreateAt}
+		if _, err := a.Srv.Store.Thread().SaveMembership(membership); err != nil {
+			mlog.Error("Failed to save thread membership", mlog.String("post_id", post.RootId), mlog.String("user_id", userId), mlog.Err(err))
+			continue
+		}
+		existing[userId] = membership
+	}
+
+	if err := a.Srv.Store.Thread().IncrementUnreads(post.RootId, post.UserId, mentionedUserIds, post.CreateAt); err != nil {
+		mlog.Error("Failed to count reply as unread", mlog.String("post_id", post.Id), mlog.Err(err))
+		return
+	}
+
+	memberships, err = a.Srv.Store.Thread().GetMembershipsForThread(post.RootId)
+	if err != nil {
+		mlog.Error("Failed to get thread memberships", mlog.String("post_id", post.RootId), mlog.Err(err))
+		return
+	}
+
+	for _, membership := range memberships {
+		if membership.Following {
+			a.publishThreadEvent(model.WEBSOCKET_EVENT_THREAD_UPDATED, membership)
+		}
+	}
+}
+
+func (a *App) publishThreadEvent(event string, membership *model.ThreadMembership) {
+	message := model.NewWebSocketEvent(event, "", "", membership.UserId, nil)
+	message.Add("thread_membership", membership.ToJson())
+	a.Publish(message)
+}
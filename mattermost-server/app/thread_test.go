@@ -0,0 +1,125 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestUpdateThreadMembershipsForReply(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	th.AddUserToChannel(th.BasicUser2, th.BasicChannel)
+	user3 := th.CreateUser()
+	th.LinkUserToTeam(user3, th.BasicTeam)
+	th.AddUserToChannel(user3, th.BasicChannel)
+
+	rootPost := th.BasicPost
+
+	reply := &model.Post{Id: model.NewId(), UserId: th.BasicUser2.Id, ChannelId: th.BasicChannel.Id, RootId: rootPost.Id, CreateAt: model.GetMillis()}
+	th.App.updateThreadMembershipsForReply(reply, rootPost, nil)
+
+	author, err := th.App.Srv.Store.Thread().GetMembership(rootPost.Id, th.BasicUser2.Id)
+	require.Nil(t, err)
+	assert.True(t, author.Following)
+	assert.Zero(t, author.UnreadReplies)
+	assert.Equal(t, reply.CreateAt, author.LastViewed)
+
+	starter, err := th.App.Srv.Store.Thread().GetMembership(rootPost.Id, rootPost.UserId)
+	require.Nil(t, err)
+	assert.True(t, starter.Following)
+	assert.Equal(t, int64(1), starter.UnreadReplies)
+	assert.Zero(t, starter.UnreadMentions)
+
+	t.Run("mentions follow the thread", func(t *testing.T) {
+		reply := &model.Post{Id: model.NewId(), UserId: th.BasicUser2.Id, ChannelId: th.BasicChannel.Id, RootId: rootPost.Id, CreateAt: model.GetMillis()}
+		th.App.updateThreadMembershipsForReply(reply, rootPost, []string{user3.Id, rootPost.UserId})
+
+		mentioned, err := th.App.Srv.Store.Thread().GetMembership(rootPost.Id, user3.Id)
+		require.Nil(t, err)
+		assert.True(t, mentioned.Following)
+		assert.Equal(t, int64(1), mentioned.UnreadReplies)
+		assert.Equal(t, int64(1), mentioned.UnreadMentions)
+
+		starter, err := th.App.Srv.Store.Thread().GetMembership(rootPost.Id, rootPost.UserId)
+		require.Nil(t, err)
+		assert.Equal(t, int64(2), starter.UnreadReplies)
+		assert.Equal(t, int64(1), starter.UnreadMentions)
+	})
+
+	t.Run("unfollowed threads aren't followed again by mentions", func(t *testing.T) {
+		_, err := th.App.UpdateThreadFollowing(user3.Id, rootPost.Id, false)
+		require.Nil(t, err)
+
+		reply := &model.Post{Id: model.NewId(), UserId: th.BasicUser2.Id, ChannelId: th.BasicChannel.Id, RootId: rootPost.Id, CreateAt: model.GetMillis()}
+		th.App.updateThreadMembershipsForReply(reply, rootPost, []string{user3.Id})
+
+		membership, err := th.App.Srv.Store.Thread().GetMembership(rootPost.Id, user3.Id)
+		require.Nil(t, err)
+		assert.False(t, membership.Following)
+		assert.Equal(t, int64(1), membership.UnreadMentions)
+	})
+
+	t.Run("replying follows the thread again", func(t *testing.T) {
+		reply := &model.Post{Id: model.NewId(), UserId: user3.Id, ChannelId: th.BasicChannel.Id, RootId: rootPost.Id, CreateAt: model.GetMillis()}
+		th.App.updateThreadMembershipsForReply(reply, rootPost, nil)
+
+		membership, err := th.App.Srv.Store.Thread().GetMembership(rootPost.Id, user3.Id)
+		require.Nil(t, err)
+		assert.True(t, membership.Following)
+		assert.Zero(t, membership.UnreadMentions)
+	})
+}
+
+func TestGetThreadsForUser(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	threads, err := th.App.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, threads)
+
+	result := <-th.App.Srv.Store.Post().Save(&model.Post{UserId: th.BasicUser.Id, ChannelId: th.BasicChannel.Id, RootId: th.BasicPost.Id, ParentId: th.BasicPost.Id, Message: "reply"})
+	require.Nil(t, result.Err)
+
+	_, err = th.App.UpdateThreadFollowing(th.BasicUser.Id, th.BasicPost.Id, true)
+	require.Nil(t, err)
+
+	threads, err = th.App.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, threads, 1)
+	assert.Equal(t, th.BasicPost.Id, threads[0].PostId)
+	assert.Equal(t, th.BasicPost.Id, threads[0].Post.Id)
+	assert.Equal(t, int64(1), threads[0].ReplyCount)
+
+	_, err = th.App.UpdateThreadFollowing(th.BasicUser.Id, th.BasicPost.Id, false)
+	require.Nil(t, err)
+
+	threads, err = th.App.GetThreadsForUser(th.BasicUser.Id, th.BasicTeam.Id, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, threads)
+}
+
+func TestMarkThreadAsRead(t *testing.T) {
+	th := Setup(t).InitBasic()
+	defer th.TearDown()
+
+	_, err := th.App.MarkThreadAsRead(th.BasicUser.Id, th.BasicPost.Id)
+	assert.NotNil(t, err)
+
+	_, err = th.App.Srv.Store.Thread().SaveMembership(&model.ThreadMembership{PostId: th.BasicPost.Id, UserId: th.BasicUser.Id, Following: true, UnreadReplies: 3, UnreadMentions: 1})
+	require.Nil(t, err)
+
+	membership, err := th.App.MarkThreadAsRead(th.BasicUser.Id, th.BasicPost.Id)
+	require.Nil(t, err)
+	assert.Zero(t, membership.UnreadReplies)
+	assert.Zero(t, membership.UnreadMentions)
+	assert.NotZero(t, membership.LastViewed)
+}
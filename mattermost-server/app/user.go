@@ -292,6 +292,10 @@ func (a *App) createUserOrGuest(user *model.User, guest bool) (*model.User, *mod
 func (a *App) createUser(user *model.User) (*model.User, *model.AppError) {
 	user.MakeNonNil()
 
+	if model.IsReservedUsername(user.Username) {
+		return nil, model.NewAppError("createUser", "app.user.username_reserved.app_error", nil, "username="+user.Username, http.StatusBadRequest)
+	}
+
 	if err := a.IsPasswordValid(user.Password); user.AuthService == "" && err != nil {
 		return nil, err
 	}
@@ -1005,6 +1009,23 @@ func (a *App) UpdateActive(user *model.User, active bool) (*model.User, *model.A
 
 	a.sendUpdatedUserEvent(*ruser)
 
+	if pluginsEnvironment := a.GetPluginsEnvironment(); pluginsEnvironment != nil {
+		a.Srv.Go(func() {
+			pluginContext := a.PluginContext()
+			if active {
+				pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+					hooks.UserHasBeenUpdated(pluginContext, userUpdate.New, userUpdate.Old)
+					return true
+				}, plugin.UserHasBeenUpdatedId)
+			} else {
+				pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+					hooks.UserHasBeenDeactivated(pluginContext, userUpdate.New)
+					return true
+				}, plugin.UserHasBeenDeactivatedId)
+			}
+		})
+	}
+
 	return ruser, nil
 }
 
@@ -1098,6 +1119,10 @@ func (a *App) UpdateUser(user *model.User, sendNotifications bool) (*model.User,
 		return nil, err
 	}
 
+	if user.Username != prev.Username && model.IsReservedUsername(user.Username) {
+		return nil, model.NewAppError("UpdateUser", "app.user.username_reserved.app_error", nil, "username="+user.Username, http.StatusBadRequest)
+	}
+
 	if !CheckUserDomain(user, *a.Config().TeamSettings.RestrictCreationToDomains) {
 		if !prev.IsLDAPUser() && !prev.IsSAMLUser() && user.Email != prev.Email {
 			return nil, model.NewAppError("UpdateUser", "api.user.create_user.accepted_domain.app_error", nil, "", http.StatusBadRequest)
@@ -1160,6 +1185,16 @@ func (a *App) UpdateUser(user *model.User, sendNotifications bool) (*model.User,
 		})
 	}
 
+	if pluginsEnvironment := a.GetPluginsEnvironment(); pluginsEnvironment != nil {
+		a.Srv.Go(func() {
+			pluginContext := a.PluginContext()
+			pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+				hooks.UserHasBeenUpdated(pluginContext, userUpdate.New, userUpdate.Old)
+				return true
+			}, plugin.UserHasBeenUpdatedId)
+		})
+	}
+
 	return userUpdate.New, nil
 }
 
@@ -1386,7 +1421,8 @@ func (a *App) UpdateUserRoles(userId string, newRoles string, sendWebSocketEvent
 	if result.Err != nil {
 		return nil, result.Err
 	}
-	ruser := result.Data.(*model.UserUpdate).New
+	userUpdate := result.Data.(*model.UserUpdate)
+	ruser := userUpdate.New
 
 	if result := <-schan; result.Err != nil {
 		// soft error since the user roles were still updated
@@ -1402,6 +1438,16 @@ func (a *App) UpdateUserRoles(userId string, newRoles string, sendWebSocketEvent
 		a.Publish(message)
 	}
 
+	if pluginsEnvironment := a.GetPluginsEnvironment(); pluginsEnvironment != nil {
+		a.Srv.Go(func() {
+			pluginContext := a.PluginContext()
+			pluginsEnvironment.RunMultiPluginHook(func(hooks plugin.Hooks) bool {
+				hooks.UserHasBeenUpdated(pluginContext, userUpdate.New, userUpdate.Old)
+				return true
+			}, plugin.UserHasBeenUpdatedId)
+		})
+	}
+
 	return ruser, nil
 }
 
@@ -1700,14 +1746,13 @@ func (a *App) SearchUsersInTeam(teamId string, term string, options *model.UserS
 
 	term = strings.TrimSpace(term)
 
-	esInterface := a.Elasticsearch
-	license := a.License()
-	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableAutocomplete && license != nil && *license.Features.Elasticsearch {
+	if *a.Config().ElasticsearchSettings.EnableAutocomplete && a.isSearchEngineAvailable() {
 		listOfAllowedChannels, err := a.GetViewUsersRestrictionsForTeam(a.Session.UserId, teamId)
 		if err != nil {
 			return nil, err
 		}
-		if len(listOfAllowedChannels) == 0 {
+		// A nil list means that the user isn't restricted to any channels.
+		if listOfAllowedChannels != nil && len(listOfAllowedChannels) == 0 {
 			return []*model.User{}, nil
 		}
 
@@ -1768,9 +1813,7 @@ func (a *App) AutocompleteUsersInChannel(teamId string, channelId string, term s
 
 	term = strings.TrimSpace(term)
 
-	esInterface := a.Elasticsearch
-	license := a.License()
-	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableAutocomplete && license != nil && *license.Features.Elasticsearch {
+	if *a.Config().ElasticsearchSettings.EnableAutocomplete && a.isSearchEngineAvailable() {
 		listOfAllowedChannels, err := a.getListOfAllowedChannelsForTeam(teamId, options.ViewRestrictions)
 		if err != nil {
 			return nil, err
@@ -1830,9 +1873,7 @@ func (a *App) AutocompleteUsersInTeam(teamId string, term string, options *model
 
 	term = strings.TrimSpace(term)
 
-	esInterface := a.Elasticsearch
-	license := a.License()
-	if esInterface != nil && *a.Config().ElasticsearchSettings.EnableAutocomplete && license != nil && *license.Features.Elasticsearch {
+	if *a.Config().ElasticsearchSettings.EnableAutocomplete && a.isSearchEngineAvailable() {
 		listOfAllowedChannels, err := a.getListOfAllowedChannelsForTeam(teamId, options.ViewRestrictions)
 		if err != nil {
 			return nil, err
@@ -69,6 +69,26 @@ func (a *App) TotalWebsocketConnections() int {
 	return int(count)
 }
 
+// HubStats describes the load on a single websocket hub.
+type HubStats struct {
+	Index                int
+	Connections          int64
+	BroadcastQueueLength int
+}
+
+func (a *App) GetHubStats() []HubStats {
+	stats := make([]HubStats, 0, len(a.Srv.Hubs))
+	for _, hub := range a.Srv.Hubs {
+		stats = append(stats, HubStats{
+			Index:                hub.connectionIndex,
+			Connections:          atomic.LoadInt64(&hub.connectionCount),
+			BroadcastQueueLength: len(hub.broadcast),
+		})
+	}
+
+	return stats
+}
+
 func (a *App) HubStart() {
 	// Total number of hubs is twice the number of CPUs.
 	numberOfHubs := runtime.NumCPU() * 2
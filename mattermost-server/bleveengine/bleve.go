@@ -0,0 +1,201 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package bleveengine
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/mapping"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	ejobs "github.com/mattermost/mattermost-server/einterfaces/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	POST_INDEX    = "posts.bleve"
+	CHANNEL_INDEX = "channels.bleve"
+	USER_INDEX    = "users.bleve"
+)
+
+// BleveEngine is an embedded, on-disk implementation of the search interface. It keeps one Bleve
+// index per document type underneath BleveSettings.IndexDir and needs no external services.
+type BleveEngine struct {
+	App *app.App
+
+	mutex        sync.RWMutex
+	indexDir     string
+	postIndex    bleve.Index
+	channelIndex bleve.Index
+	userIndex    bleve.Index
+}
+
+type BleveIndexerInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterElasticsearchInterface(func(a *app.App) einterfaces.ElasticsearchInterface {
+		return &BleveEngine{App: a}
+	})
+	app.RegisterJobsElasticsearchIndexerInterface(func(a *app.App) ejobs.ElasticsearchIndexerInterface {
+		return &BleveIndexerInterfaceImpl{a}
+	})
+}
+
+// IsEmbedded reports that the engine runs inside the server process, so search is available
+// without an Elasticsearch license.
+func (b *BleveEngine) IsEmbedded() bool {
+	return true
+}
+
+// Start opens the indexes, creating them if needed. It is safe to call on an engine that has
+// already been started.
+func (b *BleveEngine) Start() *model.AppError {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	indexDir := *b.App.Config().BleveSettings.IndexDir
+	if b.isStarted() {
+		if indexDir == b.indexDir {
+			return nil
+		}
+		b.closeIndexes()
+	}
+
+	if err := b.openIndexes(indexDir); err != nil {
+		b.closeIndexes()
+		return err
+	}
+
+	mlog.Info("Bleve search engine started", mlog.String("index_dir", indexDir))
+
+	return nil
+}
+
+func (b *BleveEngine) Stop() *model.AppError {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.isStarted() {
+		return nil
+	}
+
+	if err := b.closeIndexes(); err != nil {
+		return err
+	}
+
+	mlog.Info("Bleve search engine stopped")
+
+	return nil
+}
+
+// TestConfig checks that the configured index directory can be created and written to.
+func (b *BleveEngine) TestConfig(cfg *model.Config) *model.AppError {
+	indexDir := *cfg.BleveSettings.IndexDir
+
+	if err := os.MkdirAll(indexDir, 0700); err != nil {
+		return model.NewAppError("Bleve.TestConfig", "bleveengine.test_config.index_dir.app_error", map[string]interface{}{"IndexDir": indexDir}, err.Error(), http.StatusInternalServerError)
+	}
+
+	file, err := os.Create(filepath.Join(indexDir, ".test"))
+	if err != nil {
+		return model.NewAppError("Bleve.TestConfig", "bleveengine.test_config.index_dir.app_error", map[string]interface{}{"IndexDir": indexDir}, err.Error(), http.StatusInternalServerError)
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	return nil
+}
+
+// PurgeIndexes deletes every indexed document by removing the indexes from disk and creating
+// them again from scratch.
+func (b *BleveEngine) PurgeIndexes() *model.AppError {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	indexDir := *b.App.Config().BleveSettings.IndexDir
+	if b.isStarted() {
+		indexDir = b.indexDir
+		if err := b.closeIndexes(); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range []string{POST_INDEX, CHANNEL_INDEX, USER_INDEX} {
+		if err := os.RemoveAll(filepath.Join(indexDir, name)); err != nil {
+			return model.NewAppError("Bleve.PurgeIndexes", "bleveengine.purge_indexes.delete.app_error", map[string]interface{}{"Index": name}, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if err := b.openIndexes(indexDir); err != nil {
+		b.closeIndexes()
+		return err
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) isStarted() bool {
+	return b.postIndex != nil
+}
+
+func (b *BleveEngine) openIndexes(indexDir string) *model.AppError {
+	if err := os.MkdirAll(indexDir, 0700); err != nil {
+		return model.NewAppError("Bleve.Start", "bleveengine.start.index_dir.app_error", map[string]interface{}{"IndexDir": indexDir}, err.Error(), http.StatusInternalServerError)
+	}
+
+	var err *model.AppError
+	if b.postIndex, err = openIndex(indexDir, POST_INDEX, getPostIndexMapping()); err != nil {
+		return err
+	}
+	if b.channelIndex, err = openIndex(indexDir, CHANNEL_INDEX, getChannelIndexMapping()); err != nil {
+		return err
+	}
+	if b.userIndex, err = openIndex(indexDir, USER_INDEX, getUserIndexMapping()); err != nil {
+		return err
+	}
+
+	b.indexDir = indexDir
+
+	return nil
+}
+
+func (b *BleveEngine) closeIndexes() *model.AppError {
+	var result *model.AppError
+
+	for _, index := range []*bleve.Index{&b.postIndex, &b.channelIndex, &b.userIndex} {
+		if *index == nil {
+			continue
+		}
+		if err := (*index).Close(); err != nil && result == nil {
+			result = model.NewAppError("Bleve.Stop", "bleveengine.stop.close.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		*index = nil
+	}
+
+	b.indexDir = ""
+
+	return result
+}
+
+func openIndex(indexDir, name string, indexMapping mapping.IndexMapping) (bleve.Index, *model.AppError) {
+	path := filepath.Join(indexDir, name)
+
+	index, err := bleve.Open(path)
+	if err == bleve.ErrorIndexPathDoesNotExist {
+		index, err = bleve.New(path, indexMapping)
+	}
+	if err != nil {
+		return nil, model.NewAppError("Bleve.Start", "bleveengine.start.open_index.app_error", map[string]interface{}{"Index": name}, err.Error(), http.StatusInternalServerError)
+	}
+
+	return index, nil
+}
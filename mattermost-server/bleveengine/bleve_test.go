@@ -0,0 +1,325 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package bleveengine
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func setupBleveEngine(t *testing.T) (*BleveEngine, func()) {
+	dir, err := ioutil.TempDir("", "bleveengine")
+	require.Nil(t, err)
+
+	engine := &BleveEngine{}
+	require.Nil(t, engine.openIndexes(dir))
+
+	return engine, func() {
+		engine.closeIndexes()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestBuildSuggestions(t *testing.T) {
+	assert.Equal(t, []string{"town-square", "town", "square", "town square"}, buildSuggestions("town-square", "Town Square"))
+	assert.Equal(t, []string{"user.name", "user", "name"}, buildSuggestions("User.Name"))
+	assert.Equal(t, []string{}, buildSuggestions("", " "))
+}
+
+func TestSearchPosts(t *testing.T) {
+	engine, cleanup := setupBleveEngine(t)
+	defer cleanup()
+
+	teamId := model.NewId()
+	channel1 := &model.Channel{Id: model.NewId(), TeamId: teamId}
+	channel2 := &model.Channel{Id: model.NewId(), TeamId: teamId}
+	otherChannel := &model.Channel{Id: model.NewId(), TeamId: teamId}
+	user1 := model.NewId()
+	user2 := model.NewId()
+
+	createAt := time.Date(2019, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	newPost := func(channelId, userId, message, hashtags string, createAt time.Time) *model.Post {
+		post := &model.Post{
+			Id:        model.NewId(),
+			ChannelId: channelId,
+			UserId:    userId,
+			Message:   message,
+			Hashtags:  hashtags,
+			CreateAt:  createAt.UnixNano() / int64(time.Millisecond),
+		}
+		require.Nil(t, engine.IndexPost(post, teamId))
+		return post
+	}
+
+	running := newPost(channel1.Id, user1, "The servers are running smoothly", "", createAt)
+	quick := newPost(channel1.Id, user2, "A quick brown fox jumps", "#animals", createAt.Add(time.Hour))
+	fox := newPost(channel2.Id, user1, "The fox is quick", "", createAt.Add(48*time.Hour))
+	japanese := newPost(channel2.Id, user2, "東京タワーに行きました", "", createAt.Add(72*time.Hour))
+	hidden := newPost(otherChannel.Id, user1, "A quick brown fox from a hidden channel", "", createAt)
+	require.Nil(t, engine.IndexPost(&model.Post{
+		Id:        model.NewId(),
+		ChannelId: channel1.Id,
+		UserId:    user1,
+		Message:   "quick system message",
+		Type:      model.POST_JOIN_CHANNEL,
+	}, teamId))
+
+	channels := &model.ChannelList{channel1, channel2}
+
+	search := func(terms string, isOrSearch bool) ([]string, model.PostSearchMatches) {
+		params := model.ParseSearchParams(terms, 0)
+		for _, p := range params {
+			p.OrTerms = isOrSearch
+		}
+
+		postIds, matches, err := engine.SearchPosts(channels, params, 0, 20)
+		require.Nil(t, err)
+		return postIds, matches
+	}
+
+	t.Run("stemmed terms", func(t *testing.T) {
+		postIds, matches := search("run", false)
+		assert.Equal(t, []string{running.Id}, postIds)
+		assert.Equal(t, []string{"running"}, matches[running.Id])
+	})
+
+	t.Run("only member channels, newest first", func(t *testing.T) {
+		postIds, _ := search("quick fox", false)
+		assert.Equal(t, []string{fox.Id, quick.Id}, postIds)
+		assert.NotContains(t, postIds, hidden.Id)
+	})
+
+	t.Run("phrases", func(t *testing.T) {
+		postIds, _ := search(`"quick brown"`, false)
+		assert.Equal(t, []string{quick.Id}, postIds)
+	})
+
+	t.Run("or terms", func(t *testing.T) {
+		postIds, _ := search("servers jumps", true)
+		assert.Equal(t, []string{quick.Id, running.Id}, postIds)
+
+		postIds, _ = search("servers jumps", false)
+		assert.Empty(t, postIds)
+	})
+
+	t.Run("prefixes", func(t *testing.T) {
+		postIds, _ := search("smoo*", false)
+		assert.Equal(t, []string{running.Id}, postIds)
+	})
+
+	t.Run("hashtags", func(t *testing.T) {
+		postIds, matches := search("#Animals", false)
+		assert.Equal(t, []string{quick.Id}, postIds)
+		assert.Equal(t, []string{"#animals"}, matches[quick.Id])
+	})
+
+	t.Run("cjk", func(t *testing.T) {
+		postIds, _ := search("東京", false)
+		assert.Equal(t, []string{japanese.Id}, postIds)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		params := model.ParseSearchParams("quick in:channel from:user", 0)
+		params[0].InChannels = []string{channel1.Id}
+		params[0].FromUsers = []string{user2}
+
+		postIds, _, err := engine.SearchPosts(channels, params, 0, 20)
+		require.Nil(t, err)
+		assert.Equal(t, []string{quick.Id}, postIds)
+
+		postIds, _ = search("fox after:2019-03-10", false)
+		assert.Equal(t, []string{fox.Id}, postIds)
+
+		postIds, _ = search("quick on:2019-03-10", false)
+		assert.Equal(t, []string{quick.Id}, postIds)
+
+		postIds, _ = search("before:2019-03-11", false)
+		assert.Equal(t, []string{quick.Id, running.Id}, postIds)
+	})
+
+	t.Run("paging", func(t *testing.T) {
+		params := model.ParseSearchParams("quick", 0)
+
+		postIds, _, err := engine.SearchPosts(channels, params, 1, 1)
+		require.Nil(t, err)
+		assert.Equal(t, []string{quick.Id}, postIds)
+	})
+
+	t.Run("no channels", func(t *testing.T) {
+		postIds, _, err := engine.SearchPosts(&model.ChannelList{}, model.ParseSearchParams("quick", 0), 0, 20)
+		require.Nil(t, err)
+		assert.Empty(t, postIds)
+	})
+}
+
+func TestSearchChannels(t *testing.T) {
+	engine, cleanup := setupBleveEngine(t)
+	defer cleanup()
+
+	teamId := model.NewId()
+	townSquare := &model.Channel{Id: model.NewId(), TeamId: teamId, Name: "town-square", DisplayName: "Town Square", Type: model.CHANNEL_OPEN}
+	offTopic := &model.Channel{Id: model.NewId(), TeamId: teamId, Name: "off-topic", DisplayName: "Off-Topic", Type: model.CHANNEL_OPEN}
+	private := &model.Channel{Id: model.NewId(), TeamId: teamId, Name: "town-private", DisplayName: "Town Private", Type: model.CHANNEL_PRIVATE}
+	otherTeam := &model.Channel{Id: model.NewId(), TeamId: model.NewId(), Name: "town-hall", DisplayName: "Town Hall", Type: model.CHANNEL_OPEN}
+
+	for _, channel := range []*model.Channel{townSquare, offTopic, private, otherTeam} {
+		require.Nil(t, engine.IndexChannel(channel))
+	}
+
+	channelIds, err := engine.SearchChannels(teamId, "tow")
+	require.Nil(t, err)
+	assert.Equal(t, []string{townSquare.Id}, channelIds)
+
+	channelIds, err = engine.SearchChannels(teamId, "squ")
+	require.Nil(t, err)
+	assert.Equal(t, []string{townSquare.Id}, channelIds)
+
+	channelIds, err = engine.SearchChannels(teamId, "Town Sq")
+	require.Nil(t, err)
+	assert.Equal(t, []string{townSquare.Id}, channelIds)
+
+	channelIds, err = engine.SearchChannels(teamId, "")
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{townSquare.Id, offTopic.Id}, channelIds)
+
+	require.Nil(t, engine.DeleteChannel(townSquare))
+
+	channelIds, err = engine.SearchChannels(teamId, "tow")
+	require.Nil(t, err)
+	assert.Empty(t, channelIds)
+}
+
+func TestSearchUsers(t *testing.T) {
+	engine, cleanup := setupBleveEngine(t)
+	defer cleanup()
+
+	teamId := model.NewId()
+	channel1 := model.NewId()
+	channel2 := model.NewId()
+
+	alice := &model.User{Id: model.NewId(), Username: "alice", FirstName: "Alice", LastName: "Smith", Nickname: "Al"}
+	bob := &model.User{Id: model.NewId(), Username: "bob.smith", FirstName: "Robert", LastName: "Jones"}
+	carol := &model.User{Id: model.NewId(), Username: "carol", FirstName: "Carol", LastName: "Smith", DeleteAt: model.GetMillis()}
+	dave := &model.User{Id: model.NewId(), Username: "dave", FirstName: "Dave", LastName: "Smith"}
+
+	require.Nil(t, engine.IndexUser(alice, []string{teamId}, []string{channel1}))
+	require.Nil(t, engine.IndexUser(bob, []string{teamId}, []string{channel1, channel2}))
+	require.Nil(t, engine.IndexUser(carol, []string{teamId}, []string{channel1}))
+	require.Nil(t, engine.IndexUser(dave, []string{model.NewId()}, []string{}))
+
+	t.Run("usernames and nicknames", func(t *testing.T) {
+		userIds, err := engine.SearchUsersInTeam(teamId, nil, "smi", &model.UserSearchOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, []string{bob.Id}, userIds)
+
+		userIds, err = engine.SearchUsersInTeam(teamId, nil, "@al", &model.UserSearchOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, []string{alice.Id}, userIds)
+	})
+
+	t.Run("full names", func(t *testing.T) {
+		userIds, err := engine.SearchUsersInTeam(teamId, nil, "smi", &model.UserSearchOptions{AllowFullNames: true})
+		require.Nil(t, err)
+		assert.Equal(t, []string{alice.Id, bob.Id}, userIds)
+
+		userIds, err = engine.SearchUsersInTeam(teamId, nil, "smi", &model.UserSearchOptions{AllowFullNames: true, AllowInactive: true})
+		require.Nil(t, err)
+		assert.Equal(t, []string{alice.Id, bob.Id, carol.Id}, userIds)
+
+		userIds, err = engine.SearchUsersInTeam("", nil, "smi", &model.UserSearchOptions{AllowFullNames: true})
+		require.Nil(t, err)
+		assert.Equal(t, []string{alice.Id, bob.Id, dave.Id}, userIds)
+	})
+
+	t.Run("restricted to channels", func(t *testing.T) {
+		userIds, err := engine.SearchUsersInTeam(teamId, []string{channel2}, "", &model.UserSearchOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, []string{bob.Id}, userIds)
+
+		userIds, err = engine.SearchUsersInTeam(teamId, []string{}, "", &model.UserSearchOptions{})
+		require.Nil(t, err)
+		assert.Empty(t, userIds)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		userIds, err := engine.SearchUsersInTeam(teamId, nil, "", &model.UserSearchOptions{Limit: 1})
+		require.Nil(t, err)
+		assert.Equal(t, []string{alice.Id}, userIds)
+	})
+
+	t.Run("in channel", func(t *testing.T) {
+		uchanIds, nuchanIds, err := engine.SearchUsersInChannel(teamId, channel2, nil, "", &model.UserSearchOptions{})
+		require.Nil(t, err)
+		assert.Equal(t, []string{bob.Id}, uchanIds)
+		assert.Equal(t, []string{alice.Id}, nuchanIds)
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		require.Nil(t, engine.DeleteUser(alice))
+
+		userIds, err := engine.SearchUsersInTeam(teamId, nil, "al", &model.UserSearchOptions{})
+		require.Nil(t, err)
+		assert.Empty(t, userIds)
+	})
+}
+
+func TestDataRetentionDeleteIndexes(t *testing.T) {
+	engine, cleanup := setupBleveEngine(t)
+	defer cleanup()
+
+	teamId := model.NewId()
+	channel := &model.Channel{Id: model.NewId(), TeamId: teamId}
+	cutoff := time.Now().Add(-24 * time.Hour)
+
+	old := &model.Post{Id: model.NewId(), ChannelId: channel.Id, Message: "retention test", CreateAt: cutoff.Add(-time.Hour).UnixNano() / int64(time.Millisecond)}
+	recent := &model.Post{Id: model.NewId(), ChannelId: channel.Id, Message: "retention test", CreateAt: cutoff.Add(time.Hour).UnixNano() / int64(time.Millisecond)}
+	require.Nil(t, engine.IndexPost(old, teamId))
+	require.Nil(t, engine.IndexPost(recent, teamId))
+
+	require.Nil(t, engine.DataRetentionDeleteIndexes(cutoff))
+
+	postIds, _, err := engine.SearchPosts(&model.ChannelList{channel}, model.ParseSearchParams("retention", 0), 0, 20)
+	require.Nil(t, err)
+	assert.Equal(t, []string{recent.Id}, postIds)
+}
+
+func TestIndexingProgress(t *testing.T) {
+	progress, err := newIndexingProgress(map[string]string{
+		JOB_DATA_KEY_START_TIME: "1000",
+		JOB_DATA_KEY_END_TIME:   "2000",
+	})
+	require.Nil(t, err)
+	assert.Equal(t, int64(1000), progress.Cursor)
+	assert.Equal(t, int64(0), progress.Percent())
+
+	progress.nextCursor(1500, INDEXING_BATCH_SIZE)
+	assert.Equal(t, indexingStagePosts, progress.Stage)
+	assert.Equal(t, int64(1500), progress.Cursor)
+	assert.Equal(t, int64(16), progress.Percent())
+
+	// A full batch sharing a single timestamp must still move the cursor forward.
+	progress.nextCursor(1500, INDEXING_BATCH_SIZE)
+	assert.Equal(t, int64(1501), progress.Cursor)
+
+	progress.nextCursor(1600, 10)
+	assert.Equal(t, indexingStageChannels, progress.Stage)
+	assert.Equal(t, int64(1000), progress.Cursor)
+	assert.Equal(t, int64(33), progress.Percent())
+
+	progress.nextCursor(0, 0)
+	progress.nextCursor(0, 0)
+	assert.Equal(t, indexingStageDone, progress.Stage)
+	assert.Equal(t, int64(100), progress.Percent())
+
+	_, err = newIndexingProgress(map[string]string{JOB_DATA_KEY_START_TIME: "abc"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,429 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package bleveengine
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/analysis/lang/en"
+	"github.com/blevesearch/bleve/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/analysis/token/porter"
+	"github.com/blevesearch/bleve/analysis/tokenizer/single"
+	unicodetokenizer "github.com/blevesearch/bleve/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/mapping"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	MESSAGE_ANALYZER       = "mattermost_message"
+	KEYWORD_LOWER_ANALYZER = "mattermost_keyword_lower"
+
+	DELETE_BATCH_SIZE = 1000
+)
+
+type blevePost struct {
+	Id        string
+	TeamId    string
+	ChannelId string
+	UserId    string
+	CreateAt  int64
+	Message   string
+	Hashtags  []string
+}
+
+type bleveChannel struct {
+	Id          string
+	TeamId      string
+	Suggestions []string
+}
+
+type bleveUser struct {
+	Id                         string
+	Username                   string
+	DeleteAt                   int64
+	SuggestionsWithFullname    []string
+	SuggestionsWithoutFullname []string
+	TeamsIds                   []string
+	ChannelsIds                []string
+}
+
+func newIndexMapping(documentMapping *mapping.DocumentMapping) *mapping.IndexMappingImpl {
+	indexMapping := bleve.NewIndexMapping()
+
+	// Messages are split on unicode word boundaries, with CJK text broken into bigrams since it
+	// isn't separated by spaces, and English words reduced to their stems.
+	indexMapping.AddCustomAnalyzer(MESSAGE_ANALYZER, map[string]interface{}{
+		"type":      custom.Name,
+		"tokenizer": unicodetokenizer.Name,
+		"token_filters": []string{
+			cjk.WidthName,
+			lowercase.Name,
+			en.PossessiveName,
+			cjk.BigramName,
+			porter.Name,
+		},
+	})
+
+	indexMapping.AddCustomAnalyzer(KEYWORD_LOWER_ANALYZER, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+
+	indexMapping.DefaultAnalyzer = keyword.Name
+	indexMapping.DefaultMapping = documentMapping
+
+	return indexMapping
+}
+
+func newKeywordFieldMapping(analyzer string) *mapping.FieldMapping {
+	fieldMapping := bleve.NewTextFieldMapping()
+	fieldMapping.Analyzer = analyzer
+	fieldMapping.Store = false
+	fieldMapping.IncludeTermVectors = false
+	fieldMapping.IncludeInAll = false
+
+	return fieldMapping
+}
+
+func newNumericFieldMapping() *mapping.FieldMapping {
+	fieldMapping := bleve.NewNumericFieldMapping()
+	fieldMapping.Store = false
+	fieldMapping.IncludeInAll = false
+
+	return fieldMapping
+}
+
+func getPostIndexMapping() mapping.IndexMapping {
+	messageMapping := bleve.NewTextFieldMapping()
+	messageMapping.Analyzer = MESSAGE_ANALYZER
+	messageMapping.IncludeInAll = false
+
+	// Term vectors on hashtags let searches report which hashtags matched.
+	hashtagsMapping := newKeywordFieldMapping(KEYWORD_LOWER_ANALYZER)
+	hashtagsMapping.IncludeTermVectors = true
+
+	documentMapping := bleve.NewDocumentStaticMapping()
+	documentMapping.AddFieldMappingsAt("Id", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("TeamId", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("ChannelId", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("UserId", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("CreateAt", newNumericFieldMapping())
+	documentMapping.AddFieldMappingsAt("Message", messageMapping)
+	documentMapping.AddFieldMappingsAt("Hashtags", hashtagsMapping)
+
+	return newIndexMapping(documentMapping)
+}
+
+func getChannelIndexMapping() mapping.IndexMapping {
+	documentMapping := bleve.NewDocumentStaticMapping()
+	documentMapping.AddFieldMappingsAt("Id", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("TeamId", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("Suggestions", newKeywordFieldMapping(KEYWORD_LOWER_ANALYZER))
+
+	return newIndexMapping(documentMapping)
+}
+
+func getUserIndexMapping() mapping.IndexMapping {
+	documentMapping := bleve.NewDocumentStaticMapping()
+	documentMapping.AddFieldMappingsAt("Id", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("Username", newKeywordFieldMapping(KEYWORD_LOWER_ANALYZER))
+	documentMapping.AddFieldMappingsAt("DeleteAt", newNumericFieldMapping())
+	documentMapping.AddFieldMappingsAt("SuggestionsWithFullname", newKeywordFieldMapping(KEYWORD_LOWER_ANALYZER))
+	documentMapping.AddFieldMappingsAt("SuggestionsWithoutFullname", newKeywordFieldMapping(KEYWORD_LOWER_ANALYZER))
+	documentMapping.AddFieldMappingsAt("TeamsIds", newKeywordFieldMapping(keyword.Name))
+	documentMapping.AddFieldMappingsAt("ChannelsIds", newKeywordFieldMapping(keyword.Name))
+
+	return newIndexMapping(documentMapping)
+}
+
+func blevePostFromPost(post *model.Post, teamId string) *blevePost {
+	return &blevePost{
+		Id:        post.Id,
+		TeamId:    teamId,
+		ChannelId: post.ChannelId,
+		UserId:    post.UserId,
+		CreateAt:  post.CreateAt,
+		Message:   post.Message,
+		Hashtags:  strings.Fields(post.Hashtags),
+	}
+}
+
+func bleveChannelFromChannel(channel *model.Channel) *bleveChannel {
+	return &bleveChannel{
+		Id:          channel.Id,
+		TeamId:      channel.TeamId,
+		Suggestions: buildSuggestions(channel.Name, channel.DisplayName),
+	}
+}
+
+func bleveUserFromUserAndMemberships(user *model.User, teamsIds, channelsIds []string) *bleveUser {
+	withoutFullname := buildSuggestions(user.Username, user.Nickname)
+	withFullname := append(buildSuggestions(user.FirstName, user.LastName, user.GetFullName()), withoutFullname...)
+
+	return &bleveUser{
+		Id:                         user.Id,
+		Username:                   user.Username,
+		DeleteAt:                   user.DeleteAt,
+		SuggestionsWithFullname:    withFullname,
+		SuggestionsWithoutFullname: withoutFullname,
+		TeamsIds:                   teamsIds,
+		ChannelsIds:                channelsIds,
+	}
+}
+
+// buildSuggestions returns the lowercased values along with every word contained in them, so that
+// a prefix search matches both the start of the whole value and the start of any of its words.
+func buildSuggestions(values ...string) []string {
+	suggestions := []string{}
+	seen := map[string]bool{}
+
+	add := func(suggestion string) {
+		if suggestion != "" && !seen[suggestion] {
+			seen[suggestion] = true
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	for _, value := range values {
+		value = strings.ToLower(strings.TrimSpace(value))
+		add(value)
+
+		for _, word := range strings.FieldsFunc(value, isWordSeparator) {
+			add(word)
+		}
+	}
+
+	return suggestions
+}
+
+func isWordSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func (b *BleveEngine) IndexPost(post *model.Post, teamId string) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.IndexPost", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	if post.IsSystemMessage() {
+		return nil
+	}
+
+	if err := b.postIndex.Index(post.Id, blevePostFromPost(post, teamId)); err != nil {
+		return model.NewAppError("Bleve.IndexPost", "bleveengine.index_post.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) DeletePost(post *model.Post) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.DeletePost", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	if err := b.postIndex.Delete(post.Id); err != nil {
+		return model.NewAppError("Bleve.DeletePost", "bleveengine.delete_post.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) IndexChannel(channel *model.Channel) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.IndexChannel", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	// Only public channels are offered through autocomplete.
+	if channel.Type != model.CHANNEL_OPEN {
+		return nil
+	}
+
+	if err := b.channelIndex.Index(channel.Id, bleveChannelFromChannel(channel)); err != nil {
+		return model.NewAppError("Bleve.IndexChannel", "bleveengine.index_channel.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) DeleteChannel(channel *model.Channel) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.DeleteChannel", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	if err := b.channelIndex.Delete(channel.Id); err != nil {
+		return model.NewAppError("Bleve.DeleteChannel", "bleveengine.delete_channel.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) IndexUser(user *model.User, teamsIds, channelsIds []string) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.IndexUser", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	if err := b.userIndex.Index(user.Id, bleveUserFromUserAndMemberships(user, teamsIds, channelsIds)); err != nil {
+		return model.NewAppError("Bleve.IndexUser", "bleveengine.index_user.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) DeleteUser(user *model.User) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.DeleteUser", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	if err := b.userIndex.Delete(user.Id); err != nil {
+		return model.NewAppError("Bleve.DeleteUser", "bleveengine.delete_user.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// indexPostsBatch, indexChannelsBatch and indexUsersBatch add many documents in a single commit
+// for use by the indexing job.
+func (b *BleveEngine) indexPostsBatch(posts []*model.PostForIndexing) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.IndexPostsBatch", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	batch := b.postIndex.NewBatch()
+	for _, post := range posts {
+		if post.DeleteAt > 0 || post.IsSystemMessage() {
+			batch.Delete(post.Id)
+			continue
+		}
+		if err := batch.Index(post.Id, blevePostFromPost(&post.Post, post.TeamId)); err != nil {
+			return model.NewAppError("Bleve.IndexPostsBatch", "bleveengine.index_post.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if err := b.postIndex.Batch(batch); err != nil {
+		return model.NewAppError("Bleve.IndexPostsBatch", "bleveengine.index_post.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) indexChannelsBatch(channels []*model.Channel) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.IndexChannelsBatch", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	batch := b.channelIndex.NewBatch()
+	for _, channel := range channels {
+		if channel.Type != model.CHANNEL_OPEN {
+			continue
+		}
+		if err := batch.Index(channel.Id, bleveChannelFromChannel(channel)); err != nil {
+			return model.NewAppError("Bleve.IndexChannelsBatch", "bleveengine.index_channel.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if err := b.channelIndex.Batch(batch); err != nil {
+		return model.NewAppError("Bleve.IndexChannelsBatch", "bleveengine.index_channel.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (b *BleveEngine) indexUsersBatch(users []*model.UserForIndexing) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.IndexUsersBatch", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	batch := b.userIndex.NewBatch()
+	for _, user := range users {
+		doc := bleveUserFromUserAndMemberships(&model.User{
+			Id:        user.Id,
+			Username:  user.Username,
+			Nickname:  user.Nickname,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			DeleteAt:  user.DeleteAt,
+		}, user.TeamsIds, user.ChannelsIds)
+
+		if err := batch.Index(user.Id, doc); err != nil {
+			return model.NewAppError("Bleve.IndexUsersBatch", "bleveengine.index_user.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+
+	if err := b.userIndex.Batch(batch); err != nil {
+		return model.NewAppError("Bleve.IndexUsersBatch", "bleveengine.index_user.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// DataRetentionDeleteIndexes removes every post created before the cutoff from the index.
+func (b *BleveEngine) DataRetentionDeleteIndexes(cutoff time.Time) *model.AppError {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return model.NewAppError("Bleve.DataRetentionDeleteIndexes", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	endTime := float64(cutoff.UnixNano() / int64(time.Millisecond))
+	exclusive := false
+	q := bleve.NewNumericRangeInclusiveQuery(nil, &endTime, nil, &exclusive)
+	q.SetField("CreateAt")
+
+	for {
+		result, err := b.postIndex.Search(bleve.NewSearchRequestOptions(q, DELETE_BATCH_SIZE, 0, false))
+		if err != nil {
+			return model.NewAppError("Bleve.DataRetentionDeleteIndexes", "bleveengine.data_retention_delete_indexes.search.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		if len(result.Hits) == 0 {
+			return nil
+		}
+
+		batch := b.postIndex.NewBatch()
+		for _, hit := range result.Hits {
+			batch.Delete(hit.ID)
+		}
+
+		if err := b.postIndex.Batch(batch); err != nil {
+			return model.NewAppError("Bleve.DataRetentionDeleteIndexes", "bleveengine.data_retention_delete_indexes.delete.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package bleveengine
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	TIME_BETWEEN_BATCHES = 100
+	INDEXING_BATCH_SIZE  = 1000
+
+	JOB_DATA_KEY_START_TIME       = "start_time"
+	JOB_DATA_KEY_END_TIME         = "end_time"
+	JOB_DATA_KEY_POSTS_INDEXED    = "posts_indexed"
+	JOB_DATA_KEY_CHANNELS_INDEXED = "channels_indexed"
+	JOB_DATA_KEY_USERS_INDEXED    = "users_indexed"
+)
+
+const (
+	indexingStagePosts = iota
+	indexingStageChannels
+	indexingStageUsers
+	indexingStageDone
+)
+
+type Worker struct {
+	name      string
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *jobs.JobServer
+	app       *app.App
+}
+
+func (b *BleveIndexerInterfaceImpl) MakeWorker() model.Worker {
+	worker := Worker{
+		name:      "BleveIndexer",
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job),
+		jobServer: b.App.Srv.Jobs,
+		app:       b.App,
+	}
+
+	return &worker
+}
+
+func (worker *Worker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.name))
+
+	defer func() {
+		mlog.Debug("Worker finished", mlog.String("worker", worker.name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *Worker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *Worker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+// indexingProgress tracks how far through the posts, channels and users an indexing job is. Each
+// stage walks its table in order of CreateAt from the job's start time up to its end time.
+type indexingProgress struct {
+	StartTime int64
+	EndTime   int64
+	Stage     int
+	Cursor    int64
+
+	PostsIndexed    int64
+	ChannelsIndexed int64
+	UsersIndexed    int64
+}
+
+func newIndexingProgress(data map[string]string) (*indexingProgress, *model.AppError) {
+	progress := &indexingProgress{
+		EndTime: model.GetMillis(),
+	}
+
+	if value, ok := data[JOB_DATA_KEY_START_TIME]; ok && value != "" {
+		startTime, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, model.NewAppError("BleveIndexerWorker", "bleveengine.indexer.do_job.parse_start_time.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+		progress.StartTime = startTime
+	}
+
+	if value, ok := data[JOB_DATA_KEY_END_TIME]; ok && value != "" {
+		endTime, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, model.NewAppError("BleveIndexerWorker", "bleveengine.indexer.do_job.parse_end_time.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+		progress.EndTime = endTime
+	}
+
+	progress.Cursor = progress.StartTime
+
+	return progress, nil
+}
+
+// Percent estimates overall completion from the stage being worked on and the position of the
+// cursor within the time window.
+func (p *indexingProgress) Percent() int64 {
+	if p.Stage >= indexingStageDone {
+		return 100
+	}
+
+	var stagePercent int64
+	if p.EndTime > p.StartTime {
+		stagePercent = (p.Cursor - p.StartTime) * 100 / (p.EndTime - p.StartTime)
+	}
+
+	return (int64(p.Stage)*100 + stagePercent) / indexingStageDone
+}
+
+// nextCursor moves the cursor to the last CreateAt that was indexed. Since batches include
+// documents created at exactly the cursor, a full batch that didn't move it forward is skipped
+// past to avoid indexing the same documents forever.
+func (p *indexingProgress) nextCursor(lastCreateAt int64, count int) {
+	if count < INDEXING_BATCH_SIZE {
+		p.Stage++
+		p.Cursor = p.StartTime
+		return
+	}
+
+	if lastCreateAt <= p.Cursor {
+		lastCreateAt = p.Cursor + 1
+	}
+	p.Cursor = lastCreateAt
+}
+
+func (worker *Worker) DoJob(job *model.Job) {
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	cancelCtx, cancelCancelWatcher := context.WithCancel(context.Background())
+	cancelWatcherChan := make(chan interface{}, 1)
+	go worker.app.Srv.Jobs.CancellationWatcher(cancelCtx, job.Id, cancelWatcherChan)
+
+	defer cancelCancelWatcher()
+
+	if job.Data == nil {
+		job.Data = make(map[string]string)
+	}
+
+	engine, ok := worker.app.Elasticsearch.(*BleveEngine)
+	if !ok {
+		worker.setJobError(job, model.NewAppError("BleveIndexerWorker", "bleveengine.indexer.do_job.engine_unavailable.app_error", nil, "", http.StatusNotImplemented))
+		return
+	}
+
+	progress, err := newIndexingProgress(job.Data)
+	if err != nil {
+		worker.setJobError(job, err)
+		return
+	}
+
+	for {
+		select {
+		case <-cancelWatcherChan:
+			mlog.Debug("Worker: Job has been canceled via CancellationWatcher", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-worker.stop:
+			mlog.Debug("Worker: Job has been canceled via Worker Stop", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-time.After(TIME_BETWEEN_BATCHES * time.Millisecond):
+			if err := worker.indexBatch(engine, progress); err != nil {
+				mlog.Error("Worker: Failed to index batch", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			job.Data[JOB_DATA_KEY_POSTS_INDEXED] = strconv.FormatInt(progress.PostsIndexed, 10)
+			job.Data[JOB_DATA_KEY_CHANNELS_INDEXED] = strconv.FormatInt(progress.ChannelsIndexed, 10)
+			job.Data[JOB_DATA_KEY_USERS_INDEXED] = strconv.FormatInt(progress.UsersIndexed, 10)
+
+			if err := worker.app.Srv.Jobs.UpdateInProgressJobData(job); err != nil {
+				mlog.Error("Worker: Failed to update indexing status data for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			if progress.Stage >= indexingStageDone {
+				mlog.Info("Worker: Job is complete", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.Int64("posts_indexed", progress.PostsIndexed), mlog.Int64("channels_indexed", progress.ChannelsIndexed), mlog.Int64("users_indexed", progress.UsersIndexed))
+				worker.setJobSuccess(job)
+				return
+			}
+
+			if err := worker.jobServer.SetJobProgress(job, progress.Percent()); err != nil {
+				mlog.Error("Worker: Failed to set progress for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+		}
+	}
+}
+
+func (worker *Worker) indexBatch(engine *BleveEngine, progress *indexingProgress) *model.AppError {
+	switch progress.Stage {
+	case indexingStagePosts:
+		result := <-worker.app.Srv.Store.Post().GetPostsBatchForIndexing(progress.Cursor, progress.EndTime, INDEXING_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+		posts := result.Data.([]*model.PostForIndexing)
+
+		if len(posts) > 0 {
+			if err := engine.indexPostsBatch(posts); err != nil {
+				return err
+			}
+			progress.PostsIndexed += int64(len(posts))
+			progress.nextCursor(posts[len(posts)-1].CreateAt, len(posts))
+		} else {
+			progress.nextCursor(progress.Cursor, 0)
+		}
+
+	case indexingStageChannels:
+		result := <-worker.app.Srv.Store.Channel().GetChannelsBatchForIndexing(progress.Cursor, progress.EndTime, INDEXING_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+		channels := result.Data.([]*model.Channel)
+
+		if len(channels) > 0 {
+			if err := engine.indexChannelsBatch(channels); err != nil {
+				return err
+			}
+			progress.ChannelsIndexed += int64(len(channels))
+			progress.nextCursor(channels[len(channels)-1].CreateAt, len(channels))
+		} else {
+			progress.nextCursor(progress.Cursor, 0)
+		}
+
+	case indexingStageUsers:
+		result := <-worker.app.Srv.Store.User().GetUsersBatchForIndexing(progress.Cursor, progress.EndTime, INDEXING_BATCH_SIZE)
+		if result.Err != nil {
+			return result.Err
+		}
+		users := result.Data.([]*model.UserForIndexing)
+
+		if len(users) > 0 {
+			if err := engine.indexUsersBatch(users); err != nil {
+				return err
+			}
+			progress.UsersIndexed += int64(len(users))
+			progress.nextCursor(users[len(users)-1].CreateAt, len(users))
+		} else {
+			progress.nextCursor(progress.Cursor, 0)
+		}
+	}
+
+	return nil
+}
+
+func (worker *Worker) setJobSuccess(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *Worker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.app.Srv.Jobs.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
+
+func (worker *Worker) setJobCanceled(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobCanceled(job); err != nil {
+		mlog.Error("Worker: Failed to mark job as canceled", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package bleveengine
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/search/query"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+var searchTermsRegexp = regexp.MustCompile(`"[^"]*"|\S+`)
+
+func newTermQuery(field, term string) query.Query {
+	q := bleve.NewTermQuery(term)
+	q.SetField(field)
+	return q
+}
+
+func newAnyTermQuery(field string, terms []string) query.Query {
+	queries := []query.Query{}
+	for _, term := range terms {
+		queries = append(queries, newTermQuery(field, term))
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+func newPrefixQuery(field, prefix string) query.Query {
+	q := bleve.NewPrefixQuery(prefix)
+	q.SetField(field)
+	return q
+}
+
+func newCreateAtRangeQuery(start, end int64) query.Query {
+	inclusive := true
+
+	var min, max *float64
+	if start != 0 {
+		value := float64(start)
+		min = &value
+	}
+	if end != 0 {
+		value := float64(end)
+		max = &value
+	}
+
+	q := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+	q.SetField("CreateAt")
+	return q
+}
+
+func newActiveUserQuery() query.Query {
+	inclusive := true
+	zero := float64(0)
+
+	q := bleve.NewNumericRangeInclusiveQuery(&zero, &zero, &inclusive, &inclusive)
+	q.SetField("DeleteAt")
+	return q
+}
+
+func combineQueries(queries []query.Query, or bool) query.Query {
+	if len(queries) == 0 {
+		return bleve.NewMatchAllQuery()
+	} else if len(queries) == 1 {
+		return queries[0]
+	} else if or {
+		return bleve.NewDisjunctionQuery(queries...)
+	}
+	return bleve.NewConjunctionQuery(queries...)
+}
+
+// buildTermsQuery turns the terms of a single set of search params into a query. Quoted sections
+// are matched as phrases and words ending with an asterisk are matched as prefixes.
+func buildTermsQuery(params *model.SearchParams) query.Query {
+	queries := []query.Query{}
+
+	for _, word := range searchTermsRegexp.FindAllString(params.Terms, -1) {
+		if params.IsHashtag {
+			queries = append(queries, newTermQuery("Hashtags", strings.ToLower(word)))
+		} else if strings.HasPrefix(word, "\"") && strings.HasSuffix(word, "\"") && len(word) > 1 {
+			phrase := strings.TrimSpace(word[1 : len(word)-1])
+			if phrase == "" {
+				continue
+			}
+
+			q := bleve.NewMatchPhraseQuery(phrase)
+			q.SetField("Message")
+			queries = append(queries, q)
+		} else if strings.HasSuffix(word, "*") {
+			prefix := strings.ToLower(strings.TrimRight(word, "*"))
+			if prefix == "" {
+				continue
+			}
+
+			queries = append(queries, newPrefixQuery("Message", prefix))
+		} else {
+			q := bleve.NewMatchQuery(word)
+			q.SetField("Message")
+			q.SetOperator(query.MatchQueryOperatorAnd)
+			queries = append(queries, q)
+		}
+	}
+
+	if len(queries) == 0 {
+		return nil
+	}
+
+	return combineQueries(queries, params.OrTerms)
+}
+
+func buildSearchParamsQuery(params *model.SearchParams) query.Query {
+	queries := []query.Query{}
+
+	if termsQuery := buildTermsQuery(params); termsQuery != nil {
+		queries = append(queries, termsQuery)
+	}
+
+	if len(params.InChannels) > 0 {
+		queries = append(queries, newAnyTermQuery("ChannelId", params.InChannels))
+	}
+
+	if len(params.FromUsers) > 0 {
+		queries = append(queries, newAnyTermQuery("UserId", params.FromUsers))
+	}
+
+	if params.OnDate != "" {
+		queries = append(queries, newCreateAtRangeQuery(params.GetOnDateMillis()))
+	} else {
+		if params.AfterDate != "" {
+			queries = append(queries, newCreateAtRangeQuery(params.GetAfterDateMillis(), 0))
+		}
+		if params.BeforeDate != "" {
+			queries = append(queries, newCreateAtRangeQuery(0, params.GetBeforeDateMillis()))
+		}
+	}
+
+	return combineQueries(queries, false)
+}
+
+func (b *BleveEngine) SearchPosts(channels *model.ChannelList, searchParams []*model.SearchParams, page, perPage int) ([]string, model.PostSearchMatches, *model.AppError) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return nil, nil, model.NewAppError("Bleve.SearchPosts", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	channelIds := []string{}
+	for _, channel := range *channels {
+		channelIds = append(channelIds, channel.Id)
+	}
+
+	if len(channelIds) == 0 || len(searchParams) == 0 {
+		return []string{}, model.PostSearchMatches{}, nil
+	}
+
+	paramsQueries := []query.Query{}
+	for _, params := range searchParams {
+		paramsQueries = append(paramsQueries, buildSearchParamsQuery(params))
+	}
+
+	q := bleve.NewConjunctionQuery(
+		newAnyTermQuery("ChannelId", channelIds),
+		combineQueries(paramsQueries, searchParams[0].OrTerms),
+	)
+
+	request := bleve.NewSearchRequestOptions(q, perPage, page*perPage, false)
+	request.SortBy([]string{"-CreateAt"})
+	request.Fields = []string{"Message"}
+	request.IncludeLocations = true
+
+	result, err := b.postIndex.Search(request)
+	if err != nil {
+		return nil, nil, model.NewAppError("Bleve.SearchPosts", "bleveengine.search_posts.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	postIds := []string{}
+	matches := model.PostSearchMatches{}
+
+	for _, hit := range result.Hits {
+		postIds = append(postIds, hit.ID)
+
+		message, _ := hit.Fields["Message"].(string)
+
+		hitMatches := []string{}
+		seen := map[string]bool{}
+		add := func(match string) {
+			if match != "" && !seen[match] {
+				seen[match] = true
+				hitMatches = append(hitMatches, match)
+			}
+		}
+
+		for _, locations := range hit.Locations["Message"] {
+			for _, location := range locations {
+				if location.Start < location.End && location.End <= uint64(len(message)) {
+					add(message[location.Start:location.End])
+				}
+			}
+		}
+
+		for hashtag := range hit.Locations["Hashtags"] {
+			add(hashtag)
+		}
+
+		if len(hitMatches) > 0 {
+			matches[hit.ID] = hitMatches
+		}
+	}
+
+	return postIds, matches, nil
+}
+
+func (b *BleveEngine) SearchChannels(teamId, term string) ([]string, *model.AppError) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return nil, model.NewAppError("Bleve.SearchChannels", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	queries := []query.Query{newTermQuery("TeamId", teamId)}
+	if term = strings.ToLower(strings.TrimSpace(term)); term != "" {
+		queries = append(queries, newPrefixQuery("Suggestions", term))
+	}
+
+	request := bleve.NewSearchRequestOptions(combineQueries(queries, false), model.CHANNEL_SEARCH_DEFAULT_LIMIT, 0, false)
+
+	result, err := b.channelIndex.Search(request)
+	if err != nil {
+		return nil, model.NewAppError("Bleve.SearchChannels", "bleveengine.search_channels.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	channelIds := []string{}
+	for _, hit := range result.Hits {
+		channelIds = append(channelIds, hit.ID)
+	}
+
+	return channelIds, nil
+}
+
+// searchUsers returns the ids of the users matching the term, optionally limited to the members of
+// a team. A nil restrictedToChannels means that every user may be returned, otherwise only users
+// sharing one of the given channels are.
+func (b *BleveEngine) searchUsers(teamId string, restrictedToChannels []string, term string, options *model.UserSearchOptions, must []query.Query, mustNot []query.Query) ([]string, *model.AppError) {
+	if restrictedToChannels != nil && len(restrictedToChannels) == 0 {
+		return []string{}, nil
+	}
+
+	if teamId != "" {
+		must = append(must, newTermQuery("TeamsIds", teamId))
+	}
+
+	if restrictedToChannels != nil {
+		must = append(must, newAnyTermQuery("ChannelsIds", restrictedToChannels))
+	}
+
+	if term = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(term), "@")); term != "" {
+		if options.AllowFullNames {
+			must = append(must, newPrefixQuery("SuggestionsWithFullname", term))
+		} else {
+			must = append(must, newPrefixQuery("SuggestionsWithoutFullname", term))
+		}
+	}
+
+	if !options.AllowInactive {
+		must = append(must, newActiveUserQuery())
+	}
+
+	if len(must) == 0 {
+		must = append(must, bleve.NewMatchAllQuery())
+	}
+
+	q := bleve.NewBooleanQuery()
+	q.AddMust(must...)
+	if len(mustNot) > 0 {
+		q.AddMustNot(mustNot...)
+	}
+
+	limit := options.Limit
+	if limit <= 0 {
+		limit = model.USER_SEARCH_DEFAULT_LIMIT
+	}
+
+	request := bleve.NewSearchRequestOptions(q, limit, 0, false)
+	request.SortBy([]string{"Username"})
+
+	result, err := b.userIndex.Search(request)
+	if err != nil {
+		return nil, model.NewAppError("Bleve.SearchUsers", "bleveengine.search_users.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	userIds := []string{}
+	for _, hit := range result.Hits {
+		userIds = append(userIds, hit.ID)
+	}
+
+	return userIds, nil
+}
+
+func (b *BleveEngine) SearchUsersInChannel(teamId, channelId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, []string, *model.AppError) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return nil, nil, model.NewAppError("Bleve.SearchUsersInChannel", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	inChannel := newTermQuery("ChannelsIds", channelId)
+
+	uchanIds, err := b.searchUsers("", restrictedToChannels, term, options, []query.Query{inChannel}, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nuchanIds, err := b.searchUsers(teamId, restrictedToChannels, term, options, nil, []query.Query{inChannel})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return uchanIds, nuchanIds, nil
+}
+
+func (b *BleveEngine) SearchUsersInTeam(teamId string, restrictedToChannels []string, term string, options *model.UserSearchOptions) ([]string, *model.AppError) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if !b.isStarted() {
+		return nil, model.NewAppError("Bleve.SearchUsersInTeam", "bleveengine.not_started.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	return b.searchUsers(teamId, restrictedToChannels, term, options, nil, nil)
+}
@@ -0,0 +1,435 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	REJOIN_INTERVAL     = 30 * time.Second
+	LEAVE_TIMEOUT       = 5 * time.Second
+	UPDATE_NODE_TIMEOUT = 5 * time.Second
+
+	// How many received messages can wait for their handler before receiving blocks gossip.
+	MESSAGE_QUEUE_SIZE = 1000
+)
+
+func init() {
+	app.RegisterClusterInterface(func(s *app.Server) einterfaces.ClusterInterface {
+		return NewMemberlistCluster(s)
+	})
+}
+
+// MemberlistCluster implements inter-node communication on top of hashicorp/memberlist. Nodes find
+// each other through the cluster discovery table, gossip their membership and exchange cluster
+// messages directly. The longest running node of the cluster is its leader.
+type MemberlistCluster struct {
+	srv     *app.Server
+	id      string
+	startAt int64
+
+	// leaderChanged is called whenever the leader of the cluster changes.
+	leaderChanged func()
+
+	handlersMutex sync.RWMutex
+	handlers      map[string]einterfaces.ClusterMessageHandler
+
+	mutex       sync.RWMutex
+	memberlist  *memberlist.Memberlist
+	clusterName string
+	meta        *nodeMeta
+	members     map[string]*nodeMeta
+	leaderId    string
+	discovery   *app.ClusterDiscoveryService
+	messages    chan *model.ClusterMessage
+	stop        chan struct{}
+	stopped     chan struct{}
+
+	requestsMutex sync.Mutex
+	requests      map[string]chan *envelope
+}
+
+func NewMemberlistCluster(s *app.Server) *MemberlistCluster {
+	return &MemberlistCluster{
+		srv:           s,
+		id:            model.NewId(),
+		startAt:       model.GetMillis(),
+		leaderChanged: s.InvokeClusterLeaderChangedListeners,
+		handlers:      make(map[string]einterfaces.ClusterMessageHandler),
+		requests:      make(map[string]chan *envelope),
+	}
+}
+
+// nodeSettings holds what is needed to join the gossip pool, independently of the server config.
+type nodeSettings struct {
+	ClusterName   string
+	BindAddr      string
+	BindPort      int
+	AdvertiseAddr string
+	SecretKey     []byte
+	Info          *model.ClusterInfo
+}
+
+func (c *MemberlistCluster) StartInterNodeCommunication() {
+	cfg := c.srv.Config()
+	if !*cfg.ClusterSettings.Enable {
+		return
+	}
+
+	a := c.srv.FakeApp()
+	hostname := getHostname(cfg)
+
+	settings := nodeSettings{
+		ClusterName: *cfg.ClusterSettings.ClusterName,
+		BindAddr:    "0.0.0.0",
+		BindPort:    *cfg.ClusterSettings.GossipPort,
+		Info: &model.ClusterInfo{
+			Id:         c.id,
+			Version:    model.CurrentVersion,
+			ConfigHash: a.ClientConfigHash(),
+			IpAddress:  model.GetServerIpAddress(),
+			Hostname:   hostname,
+		},
+	}
+
+	if net.ParseIP(hostname) != nil {
+		settings.AdvertiseAddr = hostname
+	}
+
+	// Every node must share the gossip secret, which is used to encrypt and authenticate all the
+	// traffic between nodes. Without it, anybody able to reach the gossip port could join.
+	if *cfg.ClusterSettings.GossipSecret != "" {
+		key := sha256.Sum256([]byte(*cfg.ClusterSettings.GossipSecret))
+		settings.SecretKey = key[:]
+	}
+
+	if err := c.start(settings); err != nil {
+		mlog.Error("Failed to start inter-node communication", mlog.String("cluster_name", settings.ClusterName), mlog.Err(err))
+		return
+	}
+
+	discovery := a.NewClusterDiscoveryService()
+	discovery.Type = model.CDS_TYPE_APP
+	discovery.ClusterName = settings.ClusterName
+	discovery.Hostname = hostname
+	discovery.GossipPort = int32(*cfg.ClusterSettings.GossipPort)
+	discovery.Port = int32(*cfg.ClusterSettings.StreamingPort)
+	discovery.Start()
+
+	c.mutex.Lock()
+	c.discovery = discovery
+	c.mutex.Unlock()
+
+	go c.joinLoop()
+
+	mlog.Info("Started inter-node communication", mlog.String("cluster_name", settings.ClusterName), mlog.String("node_id", c.id), mlog.String("hostname", hostname))
+}
+
+// start creates the local memberlist node. The node starts out as a cluster of one until join is
+// called with the addresses of other nodes.
+func (c *MemberlistCluster) start(settings nodeSettings) error {
+	if len(settings.SecretKey) == 0 {
+		return errors.New("a gossip secret is required to start inter-node communication")
+	}
+
+	c.mutex.Lock()
+	if c.memberlist != nil {
+		c.mutex.Unlock()
+		return nil
+	}
+
+	c.clusterName = settings.ClusterName
+	c.meta = &nodeMeta{
+		ClusterName: settings.ClusterName,
+		StartAt:     c.startAt,
+		Info:        settings.Info,
+	}
+	c.members = map[string]*nodeMeta{c.id: c.meta}
+	c.leaderId = c.id
+	c.messages = make(chan *model.ClusterMessage, MESSAGE_QUEUE_SIZE)
+	c.stop = make(chan struct{})
+	c.stopped = make(chan struct{})
+	go c.dispatchMessages(c.messages, c.stop)
+	c.mutex.Unlock()
+
+	// Creating the memberlist calls back into the delegates, so the lock can't be held.
+	config := memberlist.DefaultLANConfig()
+	config.Name = c.id
+	config.BindAddr = settings.BindAddr
+	config.BindPort = settings.BindPort
+	config.AdvertisePort = settings.BindPort
+	if settings.AdvertiseAddr != "" {
+		config.AdvertiseAddr = settings.AdvertiseAddr
+	}
+	config.SecretKey = settings.SecretKey
+	config.Delegate = &delegate{cluster: c}
+	config.Events = &eventDelegate{cluster: c}
+	config.Alive = &aliveDelegate{cluster: c}
+	config.LogOutput = &logWriter{}
+
+	list, err := memberlist.Create(config)
+	if err != nil {
+		c.mutex.Lock()
+		close(c.stop)
+		c.mutex.Unlock()
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.memberlist = list
+
+	return nil
+}
+
+// join contacts the given nodes, formatted as host:port, to merge with the rest of the cluster.
+func (c *MemberlistCluster) join(addresses []string) (int, error) {
+	c.mutex.RLock()
+	list := c.memberlist
+	c.mutex.RUnlock()
+
+	if list == nil || len(addresses) == 0 {
+		return 0, nil
+	}
+
+	return list.Join(addresses)
+}
+
+// joinLoop periodically looks for nodes in the cluster discovery table that aren't members yet, so
+// that nodes started at the same time or separated by a network partition eventually find each
+// other.
+func (c *MemberlistCluster) joinLoop() {
+	c.mutex.RLock()
+	stop, stopped := c.stop, c.stopped
+	c.mutex.RUnlock()
+
+	defer close(stopped)
+
+	ticker := time.NewTicker(REJOIN_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		c.joinDiscoveredNodes()
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c *MemberlistCluster) joinDiscoveredNodes() {
+	c.mutex.RLock()
+	list, discovery := c.memberlist, c.discovery
+	c.mutex.RUnlock()
+
+	if list == nil || discovery == nil {
+		return
+	}
+
+	discovered, err := c.srv.Store.ClusterDiscovery().GetAll(discovery.Type, discovery.ClusterName)
+	if err != nil {
+		mlog.Error("Failed to get the nodes of the cluster", mlog.String("cluster_name", discovery.ClusterName), mlog.Err(err))
+		return
+	}
+
+	var addresses []string
+	for _, node := range discovered {
+		if node.Hostname == discovery.Hostname && node.GossipPort == discovery.GossipPort {
+			continue
+		}
+		addresses = append(addresses, net.JoinHostPort(node.Hostname, strconv.Itoa(int(node.GossipPort))))
+	}
+
+	if len(addresses) <= list.NumMembers()-1 {
+		return
+	}
+
+	if joined, err := c.join(addresses); err != nil {
+		mlog.Warn("Failed to join some nodes of the cluster", mlog.Int("joined", joined), mlog.Any("addresses", addresses), mlog.Err(err))
+	}
+}
+
+func (c *MemberlistCluster) StopInterNodeCommunication() {
+	c.mutex.Lock()
+	list, discovery, stop, stopped := c.memberlist, c.discovery, c.stop, c.stopped
+	c.memberlist = nil
+	c.discovery = nil
+	c.members = nil
+	c.leaderId = ""
+	c.mutex.Unlock()
+
+	if list == nil {
+		return
+	}
+
+	close(stop)
+	if discovery != nil {
+		<-stopped
+		discovery.Stop()
+	}
+
+	if err := list.Leave(LEAVE_TIMEOUT); err != nil {
+		mlog.Warn("Failed to leave the cluster gracefully", mlog.Err(err))
+	}
+	if err := list.Shutdown(); err != nil {
+		mlog.Error("Failed to stop inter-node communication", mlog.Err(err))
+	}
+
+	mlog.Info("Stopped inter-node communication", mlog.String("node_id", c.id))
+}
+
+func (c *MemberlistCluster) RegisterClusterMessageHandler(event string, crm einterfaces.ClusterMessageHandler) {
+	c.handlersMutex.Lock()
+	defer c.handlersMutex.Unlock()
+
+	c.handlers[event] = crm
+}
+
+func (c *MemberlistCluster) getHandler(event string) einterfaces.ClusterMessageHandler {
+	c.handlersMutex.RLock()
+	defer c.handlersMutex.RUnlock()
+
+	return c.handlers[event]
+}
+
+func (c *MemberlistCluster) GetClusterId() string {
+	return c.id
+}
+
+// IsLeader reports whether this node should run the work that only one node of the cluster may do,
+// such as scheduling jobs. A node that isn't part of a cluster is always its own leader.
+func (c *MemberlistCluster) IsLeader() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.memberlist == nil {
+		return true
+	}
+
+	return c.leaderId == c.id
+}
+
+func (c *MemberlistCluster) GetMyClusterInfo() *model.ClusterInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.meta == nil || c.meta.Info == nil {
+		return &model.ClusterInfo{Id: c.id}
+	}
+
+	info := *c.meta.Info
+	return &info
+}
+
+func (c *MemberlistCluster) GetClusterInfos() []*model.ClusterInfo {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	infos := make([]*model.ClusterInfo, 0, len(c.members))
+	for _, meta := range c.members {
+		if meta.Info != nil {
+			info := *meta.Info
+			infos = append(infos, &info)
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Hostname < infos[j].Hostname
+	})
+
+	return infos
+}
+
+// isMember returns whether the given node is a member of this cluster.
+func (c *MemberlistCluster) isMember(name string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	_, ok := c.members[name]
+	return ok
+}
+
+// setMember records the metadata gossiped by a node, or forgets the node if meta is nil, and
+// elects a new leader if needed.
+func (c *MemberlistCluster) setMember(name string, meta *nodeMeta) {
+	c.mutex.Lock()
+
+	if c.members == nil {
+		c.mutex.Unlock()
+		return
+	}
+
+	if meta == nil {
+		delete(c.members, name)
+	} else {
+		c.members[name] = meta
+	}
+
+	previousLeaderId := c.leaderId
+	c.leaderId = electLeader(c.members)
+	leaderId := c.leaderId
+	changed := previousLeaderId != leaderId
+
+	c.mutex.Unlock()
+
+	if changed {
+		mlog.Info("Cluster leader changed", mlog.String("leader_id", leaderId), mlog.Bool("is_leader", leaderId == c.id))
+		if c.leaderChanged != nil {
+			c.leaderChanged()
+		}
+	}
+}
+
+// electLeader picks the node that has been running the longest, breaking ties by name, so that
+// leadership only moves when the leader leaves the cluster.
+func electLeader(members map[string]*nodeMeta) string {
+	var leaderId string
+	var leader *nodeMeta
+
+	for name, meta := range members {
+		if leader == nil || meta.StartAt < leader.StartAt || (meta.StartAt == leader.StartAt && name < leaderId) {
+			leaderId = name
+			leader = meta
+		}
+	}
+
+	return leaderId
+}
+
+func getHostname(cfg *model.Config) string {
+	if *cfg.ClusterSettings.OverrideHostname != "" {
+		return *cfg.ClusterSettings.OverrideHostname
+	}
+
+	if *cfg.ClusterSettings.UseIpAddress {
+		return model.GetServerIpAddress()
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		mlog.Warn(fmt.Sprintf("Failed to get the hostname, falling back to the IP address err=%v", err))
+		return model.GetServerIpAddress()
+	}
+
+	return hostname
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const testSecretKey = "0123456789abcdef0123456789abcdef"
+
+func newTestNode(t *testing.T, clusterName string, startAt int64) *MemberlistCluster {
+	return newTestNodeWithKey(t, clusterName, startAt, []byte(testSecretKey))
+}
+
+func newTestNodeWithKey(t *testing.T, clusterName string, startAt int64, secretKey []byte) *MemberlistCluster {
+	c := &MemberlistCluster{
+		id:       model.NewId(),
+		startAt:  startAt,
+		handlers: make(map[string]einterfaces.ClusterMessageHandler),
+		requests: make(map[string]chan *envelope),
+	}
+
+	err := c.start(nodeSettings{
+		ClusterName: clusterName,
+		BindAddr:    "127.0.0.1",
+		SecretKey:   secretKey,
+		Info:        &model.ClusterInfo{Id: c.id, Hostname: "node-" + c.id},
+	})
+	require.Nil(t, err)
+
+	return c
+}
+
+func (c *MemberlistCluster) address() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.memberlist.LocalNode().Address()
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		if condition() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	require.FailNow(t, "condition not met in time")
+}
+
+func TestElectLeader(t *testing.T) {
+	assert.Equal(t, "", electLeader(map[string]*nodeMeta{}))
+
+	assert.Equal(t, "b", electLeader(map[string]*nodeMeta{
+		"a": {StartAt: 20},
+		"b": {StartAt: 10},
+		"c": {StartAt: 30},
+	}))
+
+	assert.Equal(t, "a", electLeader(map[string]*nodeMeta{
+		"b": {StartAt: 10},
+		"a": {StartAt: 10},
+	}))
+}
+
+func TestStartRequiresSecretKey(t *testing.T) {
+	c := &MemberlistCluster{id: model.NewId()}
+
+	err := c.start(nodeSettings{
+		ClusterName: "test",
+		BindAddr:    "127.0.0.1",
+		Info:        &model.ClusterInfo{Id: c.id},
+	})
+	assert.NotNil(t, err)
+	assert.Nil(t, c.memberlist)
+}
+
+func TestMemberlistCluster(t *testing.T) {
+	node1 := newTestNode(t, "test", 1000)
+	defer node1.StopInterNodeCommunication()
+
+	node2 := newTestNode(t, "test", 2000)
+	defer node2.StopInterNodeCommunication()
+
+	var leaderChanges int32
+	node2.leaderChanged = func() { atomic.AddInt32(&leaderChanges, 1) }
+
+	assert.True(t, node2.IsLeader(), "a node on its own leads itself")
+
+	joined, err := node2.join([]string{node1.address()})
+	require.Nil(t, err)
+	assert.Equal(t, 1, joined)
+
+	waitFor(t, func() bool {
+		return len(node1.GetClusterInfos()) == 2 && len(node2.GetClusterInfos()) == 2
+	})
+
+	t.Run("leader election", func(t *testing.T) {
+		assert.True(t, node1.IsLeader())
+		assert.False(t, node2.IsLeader())
+		assert.Equal(t, int32(1), atomic.LoadInt32(&leaderChanges))
+	})
+
+	t.Run("messages", func(t *testing.T) {
+		received := make(chan *model.ClusterMessage, 2)
+		node1.RegisterClusterMessageHandler(model.CLUSTER_EVENT_PUBLISH, func(msg *model.ClusterMessage) {
+			received <- msg
+		})
+
+		node2.SendClusterMessage(&model.ClusterMessage{
+			Event:            model.CLUSTER_EVENT_PUBLISH,
+			SendType:         model.CLUSTER_SEND_BEST_EFFORT,
+			WaitForAllToSend: true,
+			Data:             "small",
+		})
+
+		// Too large for a single packet, so it must be sent reliably.
+		node2.SendClusterMessage(&model.ClusterMessage{
+			Event:    model.CLUSTER_EVENT_PUBLISH,
+			SendType: model.CLUSTER_SEND_BEST_EFFORT,
+			Data:     strings.Repeat("large", 1000),
+		})
+
+		var data []string
+		for i := 0; i < 2; i++ {
+			select {
+			case msg := <-received:
+				data = append(data, msg.Data)
+			case <-time.After(10 * time.Second):
+				require.FailNow(t, "cluster message not received")
+			}
+		}
+		assert.ElementsMatch(t, []string{"small", strings.Repeat("large", 1000)}, data)
+	})
+
+	t.Run("other clusters are rejected", func(t *testing.T) {
+		other := newTestNode(t, "other", 500)
+		defer other.StopInterNodeCommunication()
+
+		other.join([]string{node1.address()})
+
+		time.Sleep(500 * time.Millisecond)
+		assert.Len(t, node1.GetClusterInfos(), 2)
+		assert.True(t, node1.IsLeader())
+	})
+
+	t.Run("nodes with another secret are rejected", func(t *testing.T) {
+		other := newTestNodeWithKey(t, "test", 500, []byte("fedcba9876543210fedcba9876543210"))
+		defer other.StopInterNodeCommunication()
+
+		other.join([]string{node1.address()})
+
+		time.Sleep(500 * time.Millisecond)
+		assert.Len(t, node1.GetClusterInfos(), 2)
+		assert.True(t, node1.IsLeader())
+		assert.False(t, node1.isMember(other.id))
+	})
+
+	t.Run("leader leaves", func(t *testing.T) {
+		node1.StopInterNodeCommunication()
+
+		waitFor(t, func() bool {
+			return node2.IsLeader()
+		})
+		assert.Len(t, node2.GetClusterInfos(), 1)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&leaderChanges))
+	})
+}
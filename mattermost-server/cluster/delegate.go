@@ -0,0 +1,148 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/memberlist"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// nodeMeta is gossiped along with the membership of each node. It must fit in
+// memberlist.MetaMaxSize once encoded.
+type nodeMeta struct {
+	ClusterName string             `json:"cluster_name"`
+	StartAt     int64              `json:"start_at"`
+	Info        *model.ClusterInfo `json:"info"`
+}
+
+func (m *nodeMeta) ToJson() []byte {
+	b, _ := json.Marshal(m)
+	return b
+}
+
+func nodeMetaFromJson(data []byte) (*nodeMeta, error) {
+	var meta *nodeMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("missing node metadata")
+	}
+	return meta, nil
+}
+
+// delegate hooks into memberlist to exchange node metadata and receive cluster messages.
+type delegate struct {
+	cluster *MemberlistCluster
+}
+
+func (d *delegate) NodeMeta(limit int) []byte {
+	d.cluster.mutex.RLock()
+	defer d.cluster.mutex.RUnlock()
+
+	if d.cluster.meta == nil {
+		return nil
+	}
+
+	buf := d.cluster.meta.ToJson()
+	if len(buf) > limit {
+		mlog.Error("Cluster node metadata is too large to be gossiped", mlog.Int("size", len(buf)), mlog.Int("limit", limit))
+		return nil
+	}
+
+	return buf
+}
+
+func (d *delegate) NotifyMsg(buf []byte) {
+	d.cluster.NotifyMsg(buf)
+}
+
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte {
+	return nil
+}
+
+func (d *delegate) LocalState(join bool) []byte {
+	return nil
+}
+
+func (d *delegate) MergeRemoteState(buf []byte, join bool) {
+}
+
+// eventDelegate keeps track of the members of the cluster. It's called with memberlist's locks
+// held, so it must not call back into the memberlist.
+type eventDelegate struct {
+	cluster *MemberlistCluster
+}
+
+func (e *eventDelegate) NotifyJoin(node *memberlist.Node) {
+	meta, err := nodeMetaFromJson(node.Meta)
+	if err != nil {
+		mlog.Error("Ignoring cluster node with invalid metadata", mlog.String("node_id", node.Name), mlog.Err(err))
+		return
+	}
+
+	mlog.Info("Cluster node joined", mlog.String("node_id", node.Name), mlog.String("address", node.Address()))
+	e.cluster.setMember(node.Name, meta)
+}
+
+func (e *eventDelegate) NotifyLeave(node *memberlist.Node) {
+	mlog.Info("Cluster node left", mlog.String("node_id", node.Name), mlog.String("address", node.Address()))
+	e.cluster.setMember(node.Name, nil)
+}
+
+func (e *eventDelegate) NotifyUpdate(node *memberlist.Node) {
+	meta, err := nodeMetaFromJson(node.Meta)
+	if err != nil {
+		mlog.Error("Ignoring cluster node with invalid metadata", mlog.String("node_id", node.Name), mlog.Err(err))
+		return
+	}
+
+	e.cluster.setMember(node.Name, meta)
+}
+
+// aliveDelegate keeps nodes of other clusters sharing the same network from merging with ours.
+type aliveDelegate struct {
+	cluster *MemberlistCluster
+}
+
+func (a *aliveDelegate) NotifyAlive(node *memberlist.Node) error {
+	meta, err := nodeMetaFromJson(node.Meta)
+	if err != nil {
+		return err
+	}
+
+	a.cluster.mutex.RLock()
+	clusterName := a.cluster.clusterName
+	a.cluster.mutex.RUnlock()
+
+	if meta.ClusterName != clusterName {
+		return fmt.Errorf("node %v belongs to cluster %q instead of %q", node.Name, meta.ClusterName, clusterName)
+	}
+
+	return nil
+}
+
+// logWriter forwards the output of memberlist's standard logger to our logger.
+type logWriter struct{}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	message := strings.TrimSpace(string(p))
+
+	switch {
+	case strings.Contains(message, "[ERR]"):
+		mlog.Error(message, mlog.String("source", "memberlist"))
+	case strings.Contains(message, "[WARN]"):
+		mlog.Warn(message, mlog.String("source", "memberlist"))
+	default:
+		mlog.Debug(message, mlog.String("source", "memberlist"))
+	}
+
+	return len(p), nil
+}
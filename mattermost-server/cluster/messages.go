@@ -0,0 +1,416 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package cluster
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	CLUSTER_EVENT_CONFIG_CHANGED      = "cluster_config_changed"
+	CLUSTER_EVENT_GET_CLUSTER_STATS   = "cluster_get_stats"
+	CLUSTER_EVENT_GET_LOGS            = "cluster_get_logs"
+	CLUSTER_EVENT_GET_PLUGIN_STATUSES = "cluster_get_plugin_statuses"
+	CLUSTER_EVENT_RESPONSE            = "cluster_response"
+
+	// Best effort messages larger than this are sent reliably instead since they wouldn't fit in a
+	// single UDP packet once encrypted.
+	MAX_BEST_EFFORT_MESSAGE_SIZE = 1024
+
+	DEFAULT_REQUEST_TIMEOUT = 2000 * time.Millisecond
+)
+
+// envelope wraps the cluster messages exchanged between nodes with what is needed to answer them.
+type envelope struct {
+	Sender    string                `json:"sender"`
+	RequestId string                `json:"request_id,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	Message   *model.ClusterMessage `json:"message"`
+}
+
+func (e *envelope) ToJson() []byte {
+	b, _ := json.Marshal(e)
+	return b
+}
+
+func envelopeFromJson(data []byte) *envelope {
+	var e *envelope
+	json.Unmarshal(data, &e)
+	return e
+}
+
+// peers returns the memberlist along with the other live nodes of the cluster, or a nil memberlist
+// if inter-node communication isn't running.
+func (c *MemberlistCluster) peers() (*memberlist.Memberlist, []*memberlist.Node) {
+	c.mutex.RLock()
+	list := c.memberlist
+	c.mutex.RUnlock()
+
+	if list == nil {
+		return nil, nil
+	}
+
+	var nodes []*memberlist.Node
+	for _, node := range list.Members() {
+		if node.Name != c.id {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return list, nodes
+}
+
+func (c *MemberlistCluster) sendToNode(list *memberlist.Memberlist, node *memberlist.Node, sendType string, buf []byte) error {
+	if sendType == model.CLUSTER_SEND_BEST_EFFORT && len(buf) <= MAX_BEST_EFFORT_MESSAGE_SIZE {
+		return list.SendBestEffort(node, buf)
+	}
+
+	return list.SendReliable(node, buf)
+}
+
+func (c *MemberlistCluster) SendClusterMessage(msg *model.ClusterMessage) {
+	list, nodes := c.peers()
+	if list == nil || len(nodes) == 0 {
+		return
+	}
+
+	if c.srv != nil && c.srv.Metrics != nil {
+		c.srv.Metrics.IncrementClusterEventType(msg.Event)
+	}
+
+	buf := (&envelope{Sender: c.id, Message: msg}).ToJson()
+
+	send := func() {
+		for _, node := range nodes {
+			if err := c.sendToNode(list, node, msg.SendType, buf); err != nil {
+				mlog.Warn("Failed to send cluster message", mlog.String("event", msg.Event), mlog.String("node_id", node.Name), mlog.Err(err))
+			}
+		}
+	}
+
+	if msg.WaitForAllToSend {
+		send()
+	} else {
+		go send()
+	}
+}
+
+// NotifyMsg is called by memberlist with the messages sent to this node by other nodes.
+func (c *MemberlistCluster) NotifyMsg(buf []byte) {
+	e := envelopeFromJson(buf)
+	if e == nil || e.Message == nil {
+		mlog.Warn("Ignoring invalid cluster message", mlog.Int("size", len(buf)))
+		return
+	}
+
+	switch e.Message.Event {
+	case CLUSTER_EVENT_RESPONSE:
+		c.handleResponse(e)
+	case CLUSTER_EVENT_GET_CLUSTER_STATS, CLUSTER_EVENT_GET_LOGS, CLUSTER_EVENT_GET_PLUGIN_STATUSES:
+		go c.handleRequest(e)
+	case CLUSTER_EVENT_CONFIG_CHANGED:
+		go c.handleConfigChanged(e)
+	default:
+		c.enqueueMessage(e.Message)
+	}
+}
+
+// enqueueMessage queues a message for its handler, since memberlist delivers messages from a
+// single goroutine that a slow handler would otherwise stall.
+func (c *MemberlistCluster) enqueueMessage(msg *model.ClusterMessage) {
+	c.mutex.RLock()
+	messages, stop := c.messages, c.stop
+	c.mutex.RUnlock()
+
+	if messages == nil {
+		return
+	}
+
+	select {
+	case messages <- msg:
+	case <-stop:
+	}
+}
+
+// dispatchMessages runs the handlers of the queued messages, in the order they were received,
+// until inter-node communication stops.
+func (c *MemberlistCluster) dispatchMessages(messages <-chan *model.ClusterMessage, stop <-chan struct{}) {
+	for {
+		select {
+		case msg := <-messages:
+			handler := c.getHandler(msg.Event)
+			if handler == nil {
+				mlog.Debug("No handler registered for cluster message", mlog.String("event", msg.Event))
+				continue
+			}
+			handler(msg)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// request sends a request to every other node and waits for their answers until the cluster log
+// timeout expires. Nodes that don't answer in time are left out of the result.
+func (c *MemberlistCluster) request(event string, props map[string]string) ([]*model.ClusterMessage, *model.AppError) {
+	list, nodes := c.peers()
+	if list == nil || len(nodes) == 0 {
+		return nil, nil
+	}
+
+	if c.srv != nil && c.srv.Metrics != nil {
+		c.srv.Metrics.IncrementClusterRequest()
+		defer func(start time.Time) {
+			c.srv.Metrics.ObserveClusterRequestDuration(time.Since(start).Seconds())
+		}(time.Now())
+	}
+
+	requestId := model.NewId()
+	responses := make(chan *envelope, len(nodes))
+
+	c.requestsMutex.Lock()
+	c.requests[requestId] = responses
+	c.requestsMutex.Unlock()
+
+	defer func() {
+		c.requestsMutex.Lock()
+		delete(c.requests, requestId)
+		c.requestsMutex.Unlock()
+	}()
+
+	buf := (&envelope{
+		Sender:    c.id,
+		RequestId: requestId,
+		Message:   &model.ClusterMessage{Event: event, Props: props},
+	}).ToJson()
+
+	expected := 0
+	for _, node := range nodes {
+		if err := list.SendReliable(node, buf); err != nil {
+			mlog.Warn("Failed to send cluster request", mlog.String("event", event), mlog.String("node_id", node.Name), mlog.Err(err))
+			continue
+		}
+		expected++
+	}
+
+	timeout := time.After(c.requestTimeout())
+
+	var messages []*model.ClusterMessage
+	for len(messages) < expected {
+		select {
+		case response := <-responses:
+			if response.Error != "" {
+				return nil, model.NewAppError("MemberlistCluster.request", "cluster.request.remote_error.app_error", map[string]interface{}{"NodeId": response.Sender}, response.Error, http.StatusInternalServerError)
+			}
+			messages = append(messages, response.Message)
+		case <-timeout:
+			mlog.Warn("Timed out waiting for cluster nodes to answer", mlog.String("event", event), mlog.Int("expected", expected), mlog.Int("received", len(messages)))
+			return messages, nil
+		}
+	}
+
+	return messages, nil
+}
+
+func (c *MemberlistCluster) requestTimeout() time.Duration {
+	if c.srv == nil {
+		return DEFAULT_REQUEST_TIMEOUT
+	}
+
+	return time.Duration(*c.srv.Config().ServiceSettings.ClusterLogTimeoutMilliseconds) * time.Millisecond
+}
+
+func (c *MemberlistCluster) handleResponse(e *envelope) {
+	c.requestsMutex.Lock()
+	responses, ok := c.requests[e.RequestId]
+	c.requestsMutex.Unlock()
+
+	if !ok {
+		mlog.Debug("Ignoring late cluster response", mlog.String("node_id", e.Sender))
+		return
+	}
+
+	// The channel is buffered for one response per node, so this never blocks.
+	responses <- e
+}
+
+func (c *MemberlistCluster) handleRequest(e *envelope) {
+	response := &envelope{
+		Sender:    c.id,
+		RequestId: e.RequestId,
+		Message:   &model.ClusterMessage{Event: CLUSTER_EVENT_RESPONSE},
+	}
+
+	if data, err := c.answerRequest(e.Message); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Message.Data = data
+	}
+
+	list, nodes := c.peers()
+	for _, node := range nodes {
+		if node.Name == e.Sender {
+			if err := list.SendReliable(node, response.ToJson()); err != nil {
+				mlog.Warn("Failed to answer cluster request", mlog.String("event", e.Message.Event), mlog.String("node_id", node.Name), mlog.Err(err))
+			}
+			return
+		}
+	}
+
+	mlog.Warn("Unable to answer cluster request from unknown node", mlog.String("event", e.Message.Event), mlog.String("node_id", e.Sender))
+}
+
+func (c *MemberlistCluster) answerRequest(msg *model.ClusterMessage) (string, *model.AppError) {
+	a := c.srv.FakeApp()
+
+	switch msg.Event {
+	case CLUSTER_EVENT_GET_CLUSTER_STATS:
+		stats := &model.ClusterStats{
+			Id:                        c.id,
+			TotalWebsocketConnections: a.TotalWebsocketConnections(),
+			TotalReadDbConnections:    a.Srv.Store.TotalReadDbConnections(),
+			TotalMasterDbConnections:  a.Srv.Store.TotalMasterDbConnections(),
+		}
+		return stats.ToJson(), nil
+
+	case CLUSTER_EVENT_GET_LOGS:
+		page, _ := strconv.Atoi(msg.Props["page"])
+		perPage, _ := strconv.Atoi(msg.Props["per_page"])
+
+		lines, err := a.GetLogsSkipSend(page, perPage)
+		if err != nil {
+			return "", err
+		}
+
+		separator := strings.Repeat("-", 107)
+		header := []string{separator, separator, c.GetMyClusterInfo().Hostname, separator, separator}
+
+		return model.ArrayToJson(append(header, lines...)), nil
+
+	case CLUSTER_EVENT_GET_PLUGIN_STATUSES:
+		statuses, err := a.GetPluginStatuses()
+		if err != nil {
+			return "", err
+		}
+		return statuses.ToJson(), nil
+	}
+
+	return "", model.NewAppError("MemberlistCluster.answerRequest", "cluster.request.unknown_event.app_error", map[string]interface{}{"Event": msg.Event}, "", http.StatusBadRequest)
+}
+
+func (c *MemberlistCluster) GetClusterStats() ([]*model.ClusterStats, *model.AppError) {
+	messages, err := c.request(CLUSTER_EVENT_GET_CLUSTER_STATS, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*model.ClusterStats, 0, len(messages))
+	for _, msg := range messages {
+		if stat := model.ClusterStatsFromJson(strings.NewReader(msg.Data)); stat != nil {
+			stats = append(stats, stat)
+		}
+	}
+
+	return stats, nil
+}
+
+func (c *MemberlistCluster) GetLogs(page, perPage int) ([]string, *model.AppError) {
+	messages, err := c.request(CLUSTER_EVENT_GET_LOGS, map[string]string{
+		"page":     strconv.Itoa(page),
+		"per_page": strconv.Itoa(perPage),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, msg := range messages {
+		lines = append(lines, model.ArrayFromJson(strings.NewReader(msg.Data))...)
+	}
+
+	return lines, nil
+}
+
+func (c *MemberlistCluster) GetPluginStatuses() (model.PluginStatuses, *model.AppError) {
+	messages, err := c.request(CLUSTER_EVENT_GET_PLUGIN_STATUSES, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses model.PluginStatuses
+	for _, msg := range messages {
+		statuses = append(statuses, model.PluginStatusesFromJson(strings.NewReader(msg.Data))...)
+	}
+
+	return statuses, nil
+}
+
+// ConfigChanged gossips the new config hash of this node and, unless the change came from another
+// node, sends the new configuration to the rest of the cluster.
+func (c *MemberlistCluster) ConfigChanged(previousConfig *model.Config, newConfig *model.Config, sendToOtherServer bool) *model.AppError {
+	c.mutex.Lock()
+	list := c.memberlist
+	if list != nil && c.meta != nil && c.meta.Info != nil {
+		info := *c.meta.Info
+		info.ConfigHash = c.srv.FakeApp().ClientConfigHash()
+		c.meta = &nodeMeta{ClusterName: c.meta.ClusterName, StartAt: c.meta.StartAt, Info: &info}
+		c.members[c.id] = c.meta
+	}
+	c.mutex.Unlock()
+
+	if list == nil {
+		return nil
+	}
+
+	go func() {
+		if err := list.UpdateNode(UPDATE_NODE_TIMEOUT); err != nil {
+			mlog.Warn("Failed to gossip the updated cluster node metadata", mlog.Err(err))
+		}
+	}()
+
+	if previousConfig != nil && !reflect.DeepEqual(previousConfig.ClusterSettings, newConfig.ClusterSettings) {
+		mlog.Warn("Cluster configuration has changed. The cluster may become unstable and a restart is required. To ensure the cluster is configured correctly you should perform a rolling restart immediately.", mlog.String("node_id", c.id))
+	}
+
+	if sendToOtherServer {
+		c.SendClusterMessage(&model.ClusterMessage{
+			Event:            CLUSTER_EVENT_CONFIG_CHANGED,
+			SendType:         model.CLUSTER_SEND_RELIABLE,
+			WaitForAllToSend: true,
+			Data:             newConfig.ToJson(),
+		})
+	}
+
+	return nil
+}
+
+// handleConfigChanged saves the configuration sent by another node. Messages can only come from
+// nodes holding the gossip secret since memberlist authenticates them, but the sender must also
+// be a current member of this cluster.
+func (c *MemberlistCluster) handleConfigChanged(e *envelope) {
+	if e.Sender == c.id || !c.isMember(e.Sender) {
+		mlog.Warn("Ignoring configuration received from an unknown node", mlog.String("node_id", e.Sender))
+		return
+	}
+
+	cfg := model.ConfigFromJson(strings.NewReader(e.Message.Data))
+	if cfg == nil {
+		mlog.Error("Ignoring invalid configuration received from the cluster")
+		return
+	}
+
+	if err := c.srv.FakeApp().SaveConfig(cfg, false); err != nil {
+		mlog.Error("Failed to apply the configuration received from the cluster", mlog.Err(err))
+	}
+}
@@ -45,6 +45,14 @@ var ActianceExportCmd = &cobra.Command{
 	RunE:    buildExportCmdF("actiance"),
 }
 
+var EmlExportCmd = &cobra.Command{
+	Use:     "eml",
+	Short:   "Export data from Mattermost as RFC 5322 messages in mbox format",
+	Long:    "Export data from Mattermost as RFC 5322 messages, with their attachments, in mbox format",
+	Example: "export eml --exportFrom=12345",
+	RunE:    buildExportCmdF("eml"),
+}
+
 var BulkExportCmd = &cobra.Command{
 	Use:     "bulk [file]",
 	Short:   "Export bulk data.",
@@ -55,7 +63,7 @@ var BulkExportCmd = &cobra.Command{
 }
 
 func init() {
-	ScheduleExportCmd.Flags().String("format", "actiance", "The format to export data")
+	ScheduleExportCmd.Flags().String("format", model.COMPLIANCE_EXPORT_TYPE_CSV, "The format to export data, either csv or eml")
 	ScheduleExportCmd.Flags().Int64("exportFrom", -1, "The timestamp of the earliest post to export, expressed in seconds since the unix epoch.")
 	ScheduleExportCmd.Flags().Int("timeoutSeconds", -1, "The maximum number of seconds to wait for the job to complete before timing out.")
 
@@ -63,11 +71,14 @@ func init() {
 
 	ActianceExportCmd.Flags().Int64("exportFrom", -1, "The timestamp of the earliest post to export, expressed in seconds since the unix epoch.")
 
+	EmlExportCmd.Flags().Int64("exportFrom", -1, "The timestamp of the earliest post to export, expressed in seconds since the unix epoch.")
+
 	BulkExportCmd.Flags().Bool("all-teams", false, "Export all teams from the server.")
 
 	ExportCmd.AddCommand(ScheduleExportCmd)
 	ExportCmd.AddCommand(CsvExportCmd)
 	ExportCmd.AddCommand(ActianceExportCmd)
+	ExportCmd.AddCommand(EmlExportCmd)
 	ExportCmd.AddCommand(BulkExportCmd)
 
 	RootCmd.AddCommand(ExportCmd)
@@ -84,12 +95,11 @@ func scheduleExportCmdF(command *cobra.Command, args []string) error {
 		return errors.New("ERROR: The message export feature is not enabled")
 	}
 
-	// for now, format is hard-coded to actiance. In time, we'll have to support other formats and inject them into job data
 	format, err := command.Flags().GetString("format")
 	if err != nil {
 		return errors.New("format flag error")
 	}
-	if format != "actiance" {
+	if format != model.COMPLIANCE_EXPORT_TYPE_CSV && format != model.COMPLIANCE_EXPORT_TYPE_EML {
 		return errors.New("unsupported export format")
 	}
 
@@ -117,7 +127,7 @@ func scheduleExportCmdF(command *cobra.Command, args []string) error {
 			defer cancel()
 		}
 
-		job, err := messageExportI.StartSynchronizeJob(ctx, startTime)
+		job, err := messageExportI.StartSynchronizeJob(ctx, startTime, format)
 		if err != nil || job.Status == model.JOB_STATUS_ERROR || job.Status == model.JOB_STATUS_CANCELED {
 			CommandPrintErrorln("ERROR: Message export job failed. Please check the server logs")
 		} else {
@@ -146,6 +156,7 @@ func buildExportCmdF(format string) func(command *cobra.Command, args []string)
 
 		if a.MessageExport == nil {
 			CommandPrettyPrintln("MessageExport feature not available")
+			return nil
 		}
 
 		err2 := a.MessageExport.RunExport(format, startTime)
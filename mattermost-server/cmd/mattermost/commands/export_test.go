@@ -36,7 +36,7 @@ func TestMessageExportInvalidFormat(t *testing.T) {
 	th.SetConfig(config)
 
 	// should fail fast because format isn't supported
-	require.Error(t, th.RunCommand(t, "--format", "not_actiance", "export", "schedule"))
+	require.Error(t, th.RunCommand(t, "--format", "actiance", "export", "schedule"))
 }
 
 func TestMessageExportNegativeExportFrom(t *testing.T) {
@@ -48,7 +48,7 @@ func TestMessageExportNegativeExportFrom(t *testing.T) {
 	th.SetConfig(config)
 
 	// should fail fast because export from must be a valid timestamp
-	require.Error(t, th.RunCommand(t, "--format", "actiance", "--exportFrom", "-1", "export", "schedule"))
+	require.Error(t, th.RunCommand(t, "--format", "csv", "--exportFrom", "-1", "export", "schedule"))
 }
 
 func TestMessageExportNegativeTimeoutSeconds(t *testing.T) {
@@ -60,5 +60,5 @@ func TestMessageExportNegativeTimeoutSeconds(t *testing.T) {
 	th.SetConfig(config)
 
 	// should fail fast because timeout seconds must be a positive int
-	require.Error(t, th.RunCommand(t, "--format", "actiance", "--exportFrom", "0", "--timeoutSeconds", "-1", "export", "schedule"))
+	require.Error(t, th.RunCommand(t, "--format", "csv", "--exportFrom", "0", "--timeoutSeconds", "-1", "export", "schedule"))
 }
@@ -5,8 +5,10 @@ package commands
 
 import (
 	"errors"
+	"io"
 	"os"
 
+	"github.com/mattermost/mattermost-server/app"
 	"github.com/spf13/cobra"
 )
 
@@ -18,11 +20,35 @@ var PluginCmd = &cobra.Command{
 var PluginAddCmd = &cobra.Command{
 	Use:     "add [plugins]",
 	Short:   "Add plugins",
-	Long:    "Add plugins to your Mattermost server.",
+	Long:    "Add plugins to your Mattermost server. A detached signature found next to a plugin bundle, e.g. hovercardexample.tar.gz.sig, is verified along with it.",
 	Example: `  plugin add hovercardexample.tar.gz pluginexample.tar.gz`,
 	RunE:    pluginAddCmdF,
 }
 
+var PluginInstallURLCmd = &cobra.Command{
+	Use:     "install-url [urls]",
+	Short:   "Install plugins from URLs",
+	Long:    "Download plugins over HTTPS and install them on your Mattermost server. A detached signature found alongside a plugin bundle, e.g. at bundle.tar.gz.sig, is verified along with it.",
+	Example: `  plugin install-url https://example.com/hovercardexample.tar.gz`,
+	RunE:    pluginInstallURLCmdF,
+}
+
+var PluginUpgradesCmd = &cobra.Command{
+	Use:     "upgrades",
+	Short:   "List plugin upgrades",
+	Long:    "List the installed plugins for which the plugin marketplace configured in PluginSettings.MarketplaceUrl lists a newer version.",
+	Example: `  plugin upgrades`,
+	RunE:    pluginUpgradesCmdF,
+}
+
+var PluginUpgradeCmd = &cobra.Command{
+	Use:     "upgrade [plugins]",
+	Short:   "Upgrade plugins",
+	Long:    "Install the latest version of plugins listed in the plugin marketplace configured in PluginSettings.MarketplaceUrl.",
+	Example: `  plugin upgrade hovercardexample pluginexample`,
+	RunE:    pluginUpgradeCmdF,
+}
+
 var PluginDeleteCmd = &cobra.Command{
 	Use:     "delete [plugins]",
 	Short:   "Delete plugins",
@@ -56,8 +82,13 @@ var PluginListCmd = &cobra.Command{
 }
 
 func init() {
+	PluginInstallURLCmd.Flags().Bool("force", false, "Replace plugins that are already installed")
+
 	PluginCmd.AddCommand(
 		PluginAddCmd,
+		PluginInstallURLCmd,
+		PluginUpgradesCmd,
+		PluginUpgradeCmd,
 		PluginDeleteCmd,
 		PluginEnableCmd,
 		PluginDisableCmd,
@@ -83,12 +114,89 @@ func pluginAddCmdF(command *cobra.Command, args []string) error {
 			return err
 		}
 
-		if _, err := a.InstallPlugin(fileReader, false); err != nil {
+		var signature io.Reader
+		signatureReader, err := os.Open(plugin + app.PLUGIN_SIGNATURE_EXTENSION)
+		if err == nil {
+			signature = signatureReader
+		} else if !os.IsNotExist(err) {
+			fileReader.Close()
+			return err
+		}
+
+		if _, err := a.InstallPluginWithSignature(fileReader, signature, false); err != nil {
 			CommandPrintErrorln("Unable to add plugin: " + args[i] + ". Error: " + err.Error())
 		} else {
 			CommandPrettyPrintln("Added plugin: " + plugin)
 		}
 		fileReader.Close()
+		if signatureReader != nil {
+			signatureReader.Close()
+		}
+	}
+
+	return nil
+}
+
+func pluginInstallURLCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	if len(args) < 1 {
+		return errors.New("Expected at least one argument. See help text for details.")
+	}
+
+	force, _ := command.Flags().GetBool("force")
+
+	for _, downloadURL := range args {
+		if _, err := a.InstallPluginFromUrl(downloadURL, force); err != nil {
+			CommandPrintErrorln("Unable to install plugin from URL: " + downloadURL + ". Error: " + err.Error())
+		} else {
+			CommandPrettyPrintln("Installed plugin from URL: " + downloadURL)
+		}
+	}
+
+	return nil
+}
+
+func pluginUpgradesCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	upgrades, appErr := a.GetMarketplacePluginUpgrades()
+	if appErr != nil {
+		return errors.New("Unable to list plugin upgrades. Error: " + appErr.Error())
+	}
+
+	for _, upgrade := range upgrades {
+		CommandPrettyPrintln(upgrade.Id + ", Installed version: " + upgrade.InstalledVersion + ", Latest version: " + upgrade.Latest.Version)
+	}
+
+	return nil
+}
+
+func pluginUpgradeCmdF(command *cobra.Command, args []string) error {
+	a, err := InitDBCommandContextCobra(command)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	if len(args) < 1 {
+		return errors.New("Expected at least one argument. See help text for details.")
+	}
+
+	for _, plugin := range args {
+		if manifest, err := a.InstallMarketplacePlugin(plugin, ""); err != nil {
+			CommandPrintErrorln("Unable to upgrade plugin: " + plugin + ". Error: " + err.Error())
+		} else {
+			CommandPrettyPrintln("Upgraded plugin: " + plugin + " to version " + manifest.Version)
+		}
 	}
 
 	return nil
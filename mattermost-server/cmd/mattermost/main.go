@@ -10,6 +10,7 @@ import (
 
 	// Plugins
 	_ "github.com/mattermost/mattermost-server/model/gitlab"
+	_ "github.com/mattermost/mattermost-server/model/openid"
 
 	// Enterprise Imports
 	_ "github.com/mattermost/mattermost-server/imports"
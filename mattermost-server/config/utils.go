@@ -32,6 +32,10 @@ func desanitize(actual, target *model.Config) {
 		target.GitLabSettings.Secret = actual.GitLabSettings.Secret
 	}
 
+	if *target.OpenIdSettings.Secret == model.FAKE_SETTING {
+		target.OpenIdSettings.Secret = actual.OpenIdSettings.Secret
+	}
+
 	if *target.SqlSettings.DataSource == model.FAKE_SETTING {
 		*target.SqlSettings.DataSource = *actual.SqlSettings.DataSource
 	}
@@ -39,6 +43,10 @@ func desanitize(actual, target *model.Config) {
 		target.SqlSettings.AtRestEncryptKey = actual.SqlSettings.AtRestEncryptKey
 	}
 
+	if *target.ClusterSettings.GossipSecret == model.FAKE_SETTING {
+		target.ClusterSettings.GossipSecret = actual.ClusterSettings.GossipSecret
+	}
+
 	if *target.ElasticsearchSettings.Password == model.FAKE_SETTING {
 		*target.ElasticsearchSettings.Password = *actual.ElasticsearchSettings.Password
 	}
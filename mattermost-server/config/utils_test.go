@@ -29,6 +29,7 @@ func TestDesanitize(t *testing.T) {
 	actual.SqlSettings.DataSource = sToP("data_source")
 	actual.SqlSettings.AtRestEncryptKey = sToP("at_rest_encrypt_key")
 	actual.ElasticsearchSettings.Password = sToP("password")
+	actual.ClusterSettings.GossipSecret = sToP("gossip_secret")
 	actual.SqlSettings.DataSourceReplicas = append(actual.SqlSettings.DataSourceReplicas, "replica0")
 	actual.SqlSettings.DataSourceReplicas = append(actual.SqlSettings.DataSourceReplicas, "replica1")
 	actual.SqlSettings.DataSourceSearchReplicas = append(actual.SqlSettings.DataSourceSearchReplicas, "search_replica0")
@@ -50,6 +51,7 @@ func TestDesanitize(t *testing.T) {
 	target.SqlSettings.DataSource = sToP(model.FAKE_SETTING)
 	target.SqlSettings.AtRestEncryptKey = sToP(model.FAKE_SETTING)
 	target.ElasticsearchSettings.Password = sToP(model.FAKE_SETTING)
+	target.ClusterSettings.GossipSecret = sToP(model.FAKE_SETTING)
 	target.SqlSettings.DataSourceReplicas = append(target.SqlSettings.DataSourceReplicas, "old_replica0")
 	target.SqlSettings.DataSourceSearchReplicas = append(target.SqlSettings.DataSourceReplicas, "old_search_replica0")
 
@@ -70,10 +72,29 @@ func TestDesanitize(t *testing.T) {
 	assert.Equal(t, *actual.SqlSettings.DataSource, *target.SqlSettings.DataSource)
 	assert.Equal(t, *actual.SqlSettings.AtRestEncryptKey, *target.SqlSettings.AtRestEncryptKey)
 	assert.Equal(t, *actual.ElasticsearchSettings.Password, *target.ElasticsearchSettings.Password)
+	assert.Equal(t, *actual.ClusterSettings.GossipSecret, *target.ClusterSettings.GossipSecret)
 	assert.Equal(t, actual.SqlSettings.DataSourceReplicas, target.SqlSettings.DataSourceReplicas)
 	assert.Equal(t, actual.SqlSettings.DataSourceSearchReplicas, target.SqlSettings.DataSourceSearchReplicas)
 }
 
+func TestDesanitizeSanitizedConfig(t *testing.T) {
+	actual := &model.Config{}
+	actual.SetDefaults()
+	actual.LdapSettings.BindPassword = sToP("bind_password")
+	actual.FileSettings.AmazonS3SecretAccessKey = sToP("amazon_s3_secret_access_key")
+	actual.GitLabSettings.Secret = sToP("secret")
+	actual.OpenIdSettings.Secret = sToP("openid_secret")
+	actual.ClusterSettings.GossipSecret = sToP("0123456789abcdef0123456789abcdef")
+	actual.SqlSettings.DataSourceReplicas = []string{"replica0"}
+
+	// Saving a config read back from the System Console shouldn't change any secret.
+	target := actual.Clone()
+	target.Sanitize()
+	desanitize(actual, target)
+
+	assert.Equal(t, actual, target)
+}
+
 func TestFixInvalidLocales(t *testing.T) {
 	utils.TranslationsPreInit()
 
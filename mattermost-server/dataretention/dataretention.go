@@ -0,0 +1,58 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package dataretention
+
+import (
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	ejobs "github.com/mattermost/mattermost-server/einterfaces/jobs"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	DAY_IN_MILLISECONDS = 24 * 60 * 60 * 1000
+
+	JOB_DATA_KEY_MESSAGES_DELETED = "messages_deleted"
+	JOB_DATA_KEY_FILES_DELETED    = "files_deleted"
+)
+
+type DataRetentionInterfaceImpl struct {
+	App *app.App
+}
+
+type DataRetentionJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterDataRetentionInterface(func(a *app.App) einterfaces.DataRetentionInterface {
+		return &DataRetentionInterfaceImpl{a}
+	})
+	app.RegisterJobsDataRetentionJobInterface(func(a *app.App) ejobs.DataRetentionJobInterface {
+		return &DataRetentionJobInterfaceImpl{a}
+	})
+}
+
+// GetPolicy returns the global retention policy. Team and channel policies, which take precedence
+// over it, are managed separately through the app layer.
+func (d *DataRetentionInterfaceImpl) GetPolicy() (*model.DataRetentionPolicy, *model.AppError) {
+	return getGlobalPolicy(d.App.Config(), model.GetMillis()), nil
+}
+
+func getGlobalPolicy(cfg *model.Config, now int64) *model.DataRetentionPolicy {
+	policy := &model.DataRetentionPolicy{
+		MessageDeletionEnabled: *cfg.DataRetentionSettings.EnableMessageDeletion,
+		FileDeletionEnabled:    *cfg.DataRetentionSettings.EnableFileDeletion,
+	}
+
+	if policy.MessageDeletionEnabled {
+		policy.MessageRetentionCutoff = now - int64(*cfg.DataRetentionSettings.MessageRetentionDays)*DAY_IN_MILLISECONDS
+	}
+
+	if policy.FileDeletionEnabled {
+		policy.FileRetentionCutoff = now - int64(*cfg.DataRetentionSettings.FileRetentionDays)*DAY_IN_MILLISECONDS
+	}
+
+	return policy
+}
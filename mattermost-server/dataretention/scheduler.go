@@ -0,0 +1,66 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package dataretention
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+type Scheduler struct {
+	App *app.App
+}
+
+func (d *DataRetentionJobInterfaceImpl) MakeScheduler() model.Scheduler {
+	return &Scheduler{d.App}
+}
+
+func (scheduler *Scheduler) Name() string {
+	return "DataRetentionScheduler"
+}
+
+func (scheduler *Scheduler) JobType() string {
+	return model.JOB_TYPE_DATA_RETENTION
+}
+
+// Enabled always returns true since team and channel policies apply even while global deletion
+// is disabled, and can be added at any time. ScheduleJob skips the runs with nothing to delete.
+func (scheduler *Scheduler) Enabled(cfg *model.Config) bool {
+	return true
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, now time.Time, pendingJobs bool, lastSuccessfulJob *model.Job) *time.Time {
+	parsedTime, err := time.Parse("15:04", *cfg.DataRetentionSettings.DeletionJobStartTime)
+	if err != nil {
+		mlog.Error("Cannot determine next schedule time for data retention. DeletionJobStartTime config value is invalid.", mlog.String("scheduler", scheduler.Name()), mlog.String("error", err.Error()))
+		return nil
+	}
+
+	return jobs.GenerateNextStartDateTime(now, parsedTime)
+}
+
+func (scheduler *Scheduler) ScheduleJob(cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	mlog.Debug("Scheduling Job", mlog.String("scheduler", scheduler.Name()))
+
+	// A deletion run that is still pending covers everything a new one would, so don't queue another.
+	if pendingJobs {
+		return nil, nil
+	}
+
+	if !*cfg.DataRetentionSettings.EnableMessageDeletion && !*cfg.DataRetentionSettings.EnableFileDeletion {
+		hasPolicies, err := scheduler.App.Srv.Store.DataRetentionPolicy().HasPolicies()
+		if err != nil {
+			return nil, err
+		}
+		if !hasPolicies {
+			return nil, nil
+		}
+	}
+
+	return scheduler.App.Srv.Jobs.CreateJob(model.JOB_TYPE_DATA_RETENTION, nil)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package dataretention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestSchedulerNextScheduleTime(t *testing.T) {
+	scheduler := &Scheduler{}
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+
+	assert.True(t, scheduler.Enabled(cfg), "team and channel policies apply even while global deletion is disabled")
+
+	*cfg.DataRetentionSettings.DeletionJobStartTime = "03:30"
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, time.Date(2019, 5, 2, 3, 30, 0, 0, time.Local), *scheduler.NextScheduleTime(cfg, now, false, nil))
+
+	*cfg.DataRetentionSettings.DeletionJobStartTime = "junk"
+	assert.Nil(t, scheduler.NextScheduleTime(cfg, now, false, nil))
+}
+
+func TestGetGlobalPolicy(t *testing.T) {
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	*cfg.DataRetentionSettings.EnableMessageDeletion = true
+	*cfg.DataRetentionSettings.MessageRetentionDays = 10
+
+	policy := getGlobalPolicy(cfg, 20*DAY_IN_MILLISECONDS)
+	assert.True(t, policy.MessageDeletionEnabled)
+	assert.False(t, policy.FileDeletionEnabled)
+	assert.Equal(t, int64(10*DAY_IN_MILLISECONDS), policy.MessageRetentionCutoff)
+	assert.Zero(t, policy.FileRetentionCutoff)
+}
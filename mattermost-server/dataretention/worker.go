@@ -0,0 +1,266 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package dataretention
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	TIME_BETWEEN_BATCHES = 100
+)
+
+type Worker struct {
+	name      string
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *jobs.JobServer
+	app       *app.App
+}
+
+func (d *DataRetentionJobInterfaceImpl) MakeWorker() model.Worker {
+	worker := Worker{
+		name:      "DataRetention",
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job),
+		jobServer: d.App.Srv.Jobs,
+		app:       d.App,
+	}
+
+	return &worker
+}
+
+func (worker *Worker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.name))
+
+	defer func() {
+		mlog.Debug("Worker finished", mlog.String("worker", worker.name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *Worker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *Worker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *Worker) DoJob(job *model.Job) {
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	cancelCtx, cancelCancelWatcher := context.WithCancel(context.Background())
+	cancelWatcherChan := make(chan interface{}, 1)
+	go worker.app.Srv.Jobs.CancellationWatcher(cancelCtx, job.Id, cancelWatcherChan)
+
+	defer cancelCancelWatcher()
+
+	if job.Data == nil {
+		job.Data = make(map[string]string)
+	}
+
+	// Every batch is evaluated against the time at which the job started so that a long running
+	// job doesn't keep chasing posts that only just became old enough to be deleted.
+	now := model.GetMillis()
+	cfg := worker.app.Config()
+	progress := &deletionProgress{}
+
+	for {
+		select {
+		case <-cancelWatcherChan:
+			mlog.Debug("Worker: Job has been canceled via CancellationWatcher", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-worker.stop:
+			mlog.Debug("Worker: Job has been canceled via Worker Stop", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-time.After(TIME_BETWEEN_BATCHES * time.Millisecond):
+			if err := worker.deleteBatch(cfg, now, progress); err != nil {
+				mlog.Error("Worker: Failed to delete data", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			job.Data[JOB_DATA_KEY_MESSAGES_DELETED] = strconv.FormatInt(progress.messagesDeleted, 10)
+			job.Data[JOB_DATA_KEY_FILES_DELETED] = strconv.FormatInt(progress.filesDeleted, 10)
+
+			if err := worker.app.Srv.Jobs.UpdateInProgressJobData(job); err != nil {
+				mlog.Error("Worker: Failed to update data retention status data for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			if progress.messagesDone && progress.filesDone {
+				worker.finish(cfg, now)
+				mlog.Info("Worker: Job is complete", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.Int64("messages_deleted", progress.messagesDeleted), mlog.Int64("files_deleted", progress.filesDeleted))
+				worker.setJobSuccess(job)
+				return
+			}
+		}
+	}
+}
+
+type deletionProgress struct {
+	filesDone       bool
+	filesDeleted    int64
+	messagesDone    bool
+	messagesDeleted int64
+}
+
+// deleteBatch deletes the next batch of expired files, and then of expired messages. Both are
+// always gone through since team and channel policies apply even while global deletion is
+// disabled. Files go first because the policy that applies to a file is found through its post.
+func (worker *Worker) deleteBatch(cfg *model.Config, now int64, progress *deletionProgress) *model.AppError {
+	batchSize := int64(*cfg.DataRetentionSettings.BatchSize)
+
+	if !progress.filesDone {
+		deleted, err := worker.deleteFilesBatch(cfg, now)
+		if err != nil {
+			return err
+		}
+		progress.filesDone = deleted < batchSize
+		progress.filesDeleted += deleted
+	} else if !progress.messagesDone {
+		deleted, err := worker.deleteMessagesBatch(cfg, now)
+		if err != nil {
+			return err
+		}
+		progress.messagesDone = deleted < batchSize
+		progress.messagesDeleted += deleted
+	}
+
+	return nil
+}
+
+// deleteMessagesBatch permanently deletes a batch of expired posts and removes them from the search
+// index, since the indexes dropped once the job is done only cover the global retention period.
+func (worker *Worker) deleteMessagesBatch(cfg *model.Config, now int64) (int64, *model.AppError) {
+	globalRetentionDays := 0
+	if *cfg.DataRetentionSettings.EnableMessageDeletion {
+		globalRetentionDays = *cfg.DataRetentionSettings.MessageRetentionDays
+	}
+
+	postIds, err := worker.app.Srv.Store.DataRetentionPolicy().PermanentDeletePostsBatch(now, globalRetentionDays, int64(*cfg.DataRetentionSettings.BatchSize))
+	if err != nil {
+		return 0, err
+	}
+
+	if worker.app.Elasticsearch != nil && *cfg.ElasticsearchSettings.EnableIndexing {
+		for _, postId := range postIds {
+			if err := worker.app.Elasticsearch.DeletePost(&model.Post{Id: postId}); err != nil {
+				mlog.Error("Worker: Failed to remove deleted post from the search index", mlog.String("worker", worker.name), mlog.String("post_id", postId), mlog.String("error", err.Error()))
+			}
+		}
+	}
+
+	return int64(len(postIds)), nil
+}
+
+// deleteFilesBatch removes a batch of expired files from the file backend along with their
+// FileInfos. A FileInfo is only deleted once all of its backing files are gone so that a failure
+// in the file backend never leaves orphaned files behind.
+func (worker *Worker) deleteFilesBatch(cfg *model.Config, now int64) (int64, *model.AppError) {
+	globalRetentionDays := 0
+	if *cfg.DataRetentionSettings.EnableFileDeletion {
+		globalRetentionDays = *cfg.DataRetentionSettings.FileRetentionDays
+	}
+
+	infos, err := worker.app.Srv.Store.DataRetentionPolicy().GetFileInfosBatchForDeletion(now, globalRetentionDays, int64(*cfg.DataRetentionSettings.BatchSize))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, info := range infos {
+		for _, path := range []string{info.Path, info.ThumbnailPath, info.PreviewPath} {
+			if path == "" {
+				continue
+			}
+
+			exists, err := worker.app.FileExists(path)
+			if err != nil {
+				return 0, err
+			}
+
+			if exists {
+				if err := worker.app.RemoveFile(path); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		if err := worker.app.Srv.Store.FileInfo().PermanentDelete(info.Id); err != nil {
+			return 0, err
+		}
+
+		if info.PostId != "" {
+			worker.app.Srv.Store.FileInfo().InvalidateFileInfosForPostCache(info.PostId)
+		}
+	}
+
+	return int64(len(infos)), nil
+}
+
+func (worker *Worker) finish(cfg *model.Config, now int64) {
+	worker.app.Srv.Store.Post().ClearCaches()
+
+	if *cfg.DataRetentionSettings.EnableMessageDeletion && worker.app.Elasticsearch != nil && *cfg.ElasticsearchSettings.EnableIndexing {
+		cutoff := getGlobalPolicy(cfg, now).MessageRetentionCutoff
+		if err := worker.app.Elasticsearch.DataRetentionDeleteIndexes(time.Unix(0, cutoff*int64(time.Millisecond))); err != nil {
+			mlog.Error("Worker: Failed to delete expired search indexes", mlog.String("worker", worker.name), mlog.String("error", err.Error()))
+		}
+	}
+}
+
+func (worker *Worker) setJobSuccess(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *Worker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.app.Srv.Jobs.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
+
+func (worker *Worker) setJobCanceled(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobCanceled(job); err != nil {
+		mlog.Error("Worker: Failed to mark job as canceled", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
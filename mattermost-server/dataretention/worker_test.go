@@ -0,0 +1,47 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package dataretention
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store/storetest"
+)
+
+func TestWorkerDeleteBatchDeletesFilesFirst(t *testing.T) {
+	mockStore := &storetest.Store{}
+	worker := &Worker{name: "DataRetention", app: &app.App{Srv: &app.Server{Store: mockStore}}}
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+	*cfg.DataRetentionSettings.BatchSize = 2
+	now := model.GetMillis()
+
+	// The channel of a file is found through its post, so the posts must still be there.
+	var calls []string
+	mockStore.DataRetentionPolicyStore.On("GetFileInfosBatchForDeletion", now, 0, int64(2)).Run(func(args mock.Arguments) {
+		calls = append(calls, "files")
+	}).Return([]*model.FileInfo{}, nil).Once()
+	mockStore.DataRetentionPolicyStore.On("PermanentDeletePostsBatch", now, 0, int64(2)).Run(func(args mock.Arguments) {
+		calls = append(calls, "messages")
+	}).Return([]string{"post1"}, nil).Once()
+
+	progress := &deletionProgress{}
+	require.Nil(t, worker.deleteBatch(cfg, now, progress))
+	assert.True(t, progress.filesDone)
+	assert.False(t, progress.messagesDone)
+
+	require.Nil(t, worker.deleteBatch(cfg, now, progress))
+	assert.True(t, progress.messagesDone)
+	assert.Equal(t, int64(1), progress.messagesDeleted)
+
+	assert.Equal(t, []string{"files", "messages"}, calls)
+	mockStore.DataRetentionPolicyStore.AssertExpectations(t)
+}
@@ -26,3 +26,9 @@ type ElasticsearchInterface interface {
 	PurgeIndexes() *model.AppError
 	DataRetentionDeleteIndexes(cutoff time.Time) *model.AppError
 }
+
+// EmbeddedSearchInterface is implemented by search engines that run inside the server process
+// rather than against an external cluster, and so don't require an Elasticsearch license.
+type EmbeddedSearchInterface interface {
+	IsEmbedded() bool
+}
@@ -10,6 +10,6 @@ import (
 )
 
 type MessageExportInterface interface {
-	StartSynchronizeJob(ctx context.Context, exportFromTimestamp int64) (*model.Job, *model.AppError)
+	StartSynchronizeJob(ctx context.Context, exportFromTimestamp int64, format string) (*model.Job, *model.AppError)
 	RunExport(format string, since int64) *model.AppError
 }
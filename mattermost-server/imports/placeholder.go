@@ -6,6 +6,15 @@ package imports
 // This is a placeholder so this package can be imported in Team Edition when it will be otherwise empty
 
 import (
+	_ "github.com/mattermost/mattermost-server/bleveengine"
+	_ "github.com/mattermost/mattermost-server/cluster"
+	_ "github.com/mattermost/mattermost-server/dataretention"
+	_ "github.com/mattermost/mattermost-server/ldap"
+	_ "github.com/mattermost/mattermost-server/messageexport"
+	_ "github.com/mattermost/mattermost-server/metrics"
 	_ "github.com/mattermost/mattermost-server/migrations"
 	_ "github.com/mattermost/mattermost-server/plugin/scheduler"
+	_ "github.com/mattermost/mattermost-server/postreminders"
+	_ "github.com/mattermost/mattermost-server/saml"
+	_ "github.com/mattermost/mattermost-server/scheduledposts"
 )
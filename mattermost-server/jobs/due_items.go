@@ -0,0 +1,214 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	// How often to check for due items. Jobs are only created when an item is due, and are then
+	// picked up within the polling interval of the job watcher.
+	DUE_ITEMS_SCHEDULE_INTERVAL = 30 * time.Second
+
+	DUE_ITEMS_BATCH_SIZE           = 100
+	DUE_ITEMS_TIME_BETWEEN_BATCHES = 100
+)
+
+// DueItems describes items, such as scheduled posts, that a job handles once they're due.
+type DueItems struct {
+	// Name identifies the scheduler and worker of the items in the logs.
+	Name    string
+	JobType string
+
+	// DataKey is the key of the job data counting the items handled by a job.
+	DataKey string
+
+	// HasDue returns whether any item is due at the given time.
+	HasDue func(now int64) (bool, *model.AppError)
+
+	// HandleDue handles up to limit items due at the given time, and returns how many were
+	// handled. Handled items mustn't be returned again.
+	HandleDue func(now int64, limit int) (int, *model.AppError)
+}
+
+type DueItemsScheduler struct {
+	items     DueItems
+	jobServer *JobServer
+}
+
+func (srv *JobServer) MakeDueItemsScheduler(items DueItems) model.Scheduler {
+	return &DueItemsScheduler{items, srv}
+}
+
+func (scheduler *DueItemsScheduler) Name() string {
+	return scheduler.items.Name + "Scheduler"
+}
+
+func (scheduler *DueItemsScheduler) JobType() string {
+	return scheduler.items.JobType
+}
+
+func (scheduler *DueItemsScheduler) Enabled(cfg *model.Config) bool {
+	return true
+}
+
+func (scheduler *DueItemsScheduler) NextScheduleTime(cfg *model.Config, now time.Time, pendingJobs bool, lastSuccessfulJob *model.Job) *time.Time {
+	nextTime := now.Add(DUE_ITEMS_SCHEDULE_INTERVAL)
+	return &nextTime
+}
+
+func (scheduler *DueItemsScheduler) ScheduleJob(cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	// A pending run handles every item that's due by the time it starts, so don't queue another.
+	if pendingJobs {
+		return nil, nil
+	}
+
+	if due, err := scheduler.items.HasDue(model.GetMillis()); err != nil {
+		return nil, err
+	} else if !due {
+		return nil, nil
+	}
+
+	mlog.Debug("Scheduling Job", mlog.String("scheduler", scheduler.Name()))
+
+	return scheduler.jobServer.CreateJob(scheduler.items.JobType, nil)
+}
+
+type DueItemsWorker struct {
+	items     DueItems
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *JobServer
+}
+
+func (srv *JobServer) MakeDueItemsWorker(items DueItems) model.Worker {
+	worker := DueItemsWorker{
+		items:     items,
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job),
+		jobServer: srv,
+	}
+
+	return &worker
+}
+
+func (worker *DueItemsWorker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.items.Name))
+
+	defer func() {
+		mlog.Debug("Worker finished", mlog.String("worker", worker.items.Name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.items.Name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.items.Name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *DueItemsWorker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.items.Name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *DueItemsWorker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *DueItemsWorker) DoJob(job *model.Job) {
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.items.Name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	cancelCtx, cancelCancelWatcher := context.WithCancel(context.Background())
+	cancelWatcherChan := make(chan interface{}, 1)
+	go worker.jobServer.CancellationWatcher(cancelCtx, job.Id, cancelWatcherChan)
+
+	defer cancelCancelWatcher()
+
+	if job.Data == nil {
+		job.Data = make(map[string]string)
+	}
+
+	// Only the items due when the job started are handled, so that the job always ends.
+	now := model.GetMillis()
+	handled := 0
+
+	for {
+		select {
+		case <-cancelWatcherChan:
+			mlog.Debug("Worker: Job has been canceled via CancellationWatcher", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-worker.stop:
+			mlog.Debug("Worker: Job has been canceled via Worker Stop", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-time.After(DUE_ITEMS_TIME_BETWEEN_BATCHES * time.Millisecond):
+			count, err := worker.items.HandleDue(now, DUE_ITEMS_BATCH_SIZE)
+			if err != nil {
+				mlog.Error("Worker: Failed to handle due items", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			handled += count
+			job.Data[worker.items.DataKey] = strconv.Itoa(handled)
+
+			if count < DUE_ITEMS_BATCH_SIZE {
+				mlog.Debug("Worker: Job is complete", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id), mlog.Int(worker.items.DataKey, handled))
+				worker.setJobSuccess(job)
+				return
+			}
+
+			if err := worker.jobServer.UpdateInProgressJobData(job); err != nil {
+				mlog.Error("Worker: Failed to update status data for job", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+		}
+	}
+}
+
+func (worker *DueItemsWorker) setJobSuccess(job *model.Job) {
+	if err := worker.jobServer.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *DueItemsWorker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.jobServer.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
+
+func (worker *DueItemsWorker) setJobCanceled(job *model.Job) {
+	if err := worker.jobServer.SetJobCanceled(job); err != nil {
+		mlog.Error("Worker: Failed to mark job as canceled", mlog.String("worker", worker.items.Name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
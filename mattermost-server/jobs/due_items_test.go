@@ -0,0 +1,63 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package jobs
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestDueItemsScheduler(t *testing.T) {
+	due := false
+	var dueErr *model.AppError
+
+	srv := &JobServer{}
+	scheduler := srv.MakeDueItemsScheduler(DueItems{
+		Name:    "Test",
+		JobType: "test",
+		HasDue: func(now int64) (bool, *model.AppError) {
+			return due, dueErr
+		},
+	})
+
+	cfg := &model.Config{}
+	cfg.SetDefaults()
+
+	assert.Equal(t, "TestScheduler", scheduler.Name())
+	assert.Equal(t, "test", scheduler.JobType())
+	assert.True(t, scheduler.Enabled(cfg))
+
+	now := time.Date(2019, 5, 1, 12, 0, 0, 0, time.Local)
+	assert.Equal(t, now.Add(DUE_ITEMS_SCHEDULE_INTERVAL), *scheduler.NextScheduleTime(cfg, now, false, nil))
+
+	t.Run("nothing due", func(t *testing.T) {
+		job, err := scheduler.ScheduleJob(cfg, false, nil)
+		require.Nil(t, err)
+		assert.Nil(t, job)
+	})
+
+	t.Run("pending job", func(t *testing.T) {
+		due = true
+		defer func() { due = false }()
+
+		job, err := scheduler.ScheduleJob(cfg, true, nil)
+		require.Nil(t, err)
+		assert.Nil(t, job)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dueErr = model.NewAppError("test", "test", nil, "", http.StatusInternalServerError)
+		defer func() { dueErr = nil }()
+
+		job, err := scheduler.ScheduleJob(cfg, false, nil)
+		assert.NotNil(t, err)
+		assert.Nil(t, job)
+	})
+}
@@ -8,4 +8,5 @@ import "github.com/mattermost/mattermost-server/model"
 type PluginsJobInterface interface {
 	MakeWorker() model.Worker
 	MakeScheduler() model.Scheduler
+	MakeScheduledJobWorker() model.Worker
 }
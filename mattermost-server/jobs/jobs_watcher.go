@@ -121,6 +121,28 @@ func (watcher *Watcher) PollAndNotify() {
 					default:
 					}
 				}
+			} else if job.Type == model.JOB_TYPE_PLUGIN_SCHEDULED_JOB {
+				// The runs of scheduled jobs are saved ahead of time, and only handed over once due.
+				if _, runAt := model.PluginScheduledJobFromJob(job); watcher.workers.PluginScheduledJobs != nil && runAt <= model.GetMillis() {
+					select {
+					case watcher.workers.PluginScheduledJobs.JobChannel() <- *job:
+					default:
+					}
+				}
+			} else if job.Type == model.JOB_TYPE_SCHEDULED_POSTS {
+				if watcher.workers.ScheduledPosts != nil {
+					select {
+					case watcher.workers.ScheduledPosts.JobChannel() <- *job:
+					default:
+					}
+				}
+			} else if job.Type == model.JOB_TYPE_POST_REMINDERS {
+				if watcher.workers.PostReminders != nil {
+					select {
+					case watcher.workers.PostReminders.JobChannel() <- *job:
+					default:
+					}
+				}
 			}
 		}
 	}
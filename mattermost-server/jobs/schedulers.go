@@ -60,6 +60,14 @@ func (srv *JobServer) InitSchedulers() *Schedulers {
 		schedulers.schedulers = append(schedulers.schedulers, pluginsInterface.MakeScheduler())
 	}
 
+	if scheduledPostsInterface := srv.ScheduledPosts; scheduledPostsInterface != nil {
+		schedulers.schedulers = append(schedulers.schedulers, scheduledPostsInterface.MakeScheduler())
+	}
+
+	if postRemindersInterface := srv.PostReminders; postRemindersInterface != nil {
+		schedulers.schedulers = append(schedulers.schedulers, postRemindersInterface.MakeScheduler())
+	}
+
 	schedulers.nextRunTimes = make([]*time.Time, len(schedulers.schedulers))
 	return schedulers
 }
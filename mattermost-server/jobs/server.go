@@ -24,6 +24,8 @@ type JobServer struct {
 	LdapSync                ejobs.LdapSyncInterface
 	Migrations              tjobs.MigrationsJobInterface
 	Plugins                 tjobs.PluginsJobInterface
+	ScheduledPosts          tjobs.ScheduledPostsJobInterface
+	PostReminders           tjobs.PostRemindersJobInterface
 }
 
 func NewJobServer(configService configservice.ConfigService, store store.Store) *JobServer {
@@ -23,6 +23,9 @@ type Workers struct {
 	LdapSync                 model.Worker
 	Migrations               model.Worker
 	Plugins                  model.Worker
+	PluginScheduledJobs      model.Worker
+	ScheduledPosts           model.Worker
+	PostReminders            model.Worker
 
 	listenerId string
 }
@@ -59,6 +62,15 @@ func (srv *JobServer) InitWorkers() *Workers {
 
 	if pluginsInterface := srv.Plugins; pluginsInterface != nil {
 		workers.Plugins = pluginsInterface.MakeWorker()
+		workers.PluginScheduledJobs = pluginsInterface.MakeScheduledJobWorker()
+	}
+
+	if scheduledPostsInterface := srv.ScheduledPosts; scheduledPostsInterface != nil {
+		workers.ScheduledPosts = scheduledPostsInterface.MakeWorker()
+	}
+
+	if postRemindersInterface := srv.PostReminders; postRemindersInterface != nil {
+		workers.PostReminders = postRemindersInterface.MakeWorker()
 	}
 
 	return workers
@@ -96,6 +108,18 @@ func (workers *Workers) Start() *Workers {
 			go workers.Plugins.Run()
 		}
 
+		if workers.PluginScheduledJobs != nil {
+			go workers.PluginScheduledJobs.Run()
+		}
+
+		if workers.ScheduledPosts != nil {
+			go workers.ScheduledPosts.Run()
+		}
+
+		if workers.PostReminders != nil {
+			go workers.PostReminders.Run()
+		}
+
 		go workers.Watcher.Start()
 	})
 
@@ -181,6 +205,18 @@ func (workers *Workers) Stop() *Workers {
 		workers.Plugins.Stop()
 	}
 
+	if workers.PluginScheduledJobs != nil {
+		workers.PluginScheduledJobs.Stop()
+	}
+
+	if workers.ScheduledPosts != nil {
+		workers.ScheduledPosts.Stop()
+	}
+
+	if workers.PostReminders != nil {
+		workers.PostReminders.Stop()
+	}
+
 	mlog.Info("Stopped workers")
 
 	return workers
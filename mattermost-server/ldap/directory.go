@@ -0,0 +1,415 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-ldap/ldap"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	DEFAULT_GROUP_FILTER = "(|(objectClass=group)(objectClass=groupOfNames)(objectClass=groupOfUniqueNames))"
+
+	DIAL_TIMEOUT = 10 * time.Second
+)
+
+// GROUP_MEMBER_ATTRIBUTES are the attributes of a group entry that hold the DNs of its members,
+// which may themselves be groups.
+var GROUP_MEMBER_ATTRIBUTES = []string{"member", "uniqueMember"}
+
+// directory gives access to the users and groups of the AD/LDAP server described by the
+// LdapSettings. It knows nothing about Mattermost users, which is left to the callers.
+type directory struct {
+	settings model.LdapSettings
+}
+
+func newDirectory(settings model.LdapSettings) *directory {
+	return &directory{settings: settings}
+}
+
+func (d *directory) dial() (*ldap.Conn, error) {
+	address := net.JoinHostPort(*d.settings.LdapServer, strconv.Itoa(*d.settings.LdapPort))
+	tlsConfig := &tls.Config{
+		ServerName:         *d.settings.LdapServer,
+		InsecureSkipVerify: *d.settings.SkipCertificateVerification,
+	}
+
+	var conn *ldap.Conn
+	if *d.settings.ConnectionSecurity == model.CONN_SECURITY_TLS {
+		netConn, err := tls.DialWithDialer(&net.Dialer{Timeout: DIAL_TIMEOUT}, "tcp", address, tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+		conn = ldap.NewConn(netConn, true)
+	} else {
+		netConn, err := net.DialTimeout("tcp", address, DIAL_TIMEOUT)
+		if err != nil {
+			return nil, err
+		}
+		conn = ldap.NewConn(netConn, false)
+	}
+	conn.Start()
+
+	if *d.settings.QueryTimeout > 0 {
+		conn.SetTimeout(time.Duration(*d.settings.QueryTimeout) * time.Second)
+	}
+
+	if *d.settings.ConnectionSecurity == model.CONN_SECURITY_STARTTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+// connect opens a connection to the server, bound as the configured bind user.
+func (d *directory) connect() (*ldap.Conn, *model.AppError) {
+	conn, err := d.dial()
+	if err != nil {
+		return nil, model.NewAppError("connect", "ent.ldap.do_login.unable_to_connect.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if *d.settings.BindUsername != "" {
+		err = conn.Bind(*d.settings.BindUsername, *d.settings.BindPassword)
+	} else {
+		err = conn.UnauthenticatedBind("")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, model.NewAppError("connect", "ent.ldap.do_login.bind_admin_user.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return conn, nil
+}
+
+// checkPassword binds as the given entry on a connection of its own so that the password of a user
+// can be checked without affecting the bind user's connection.
+func (d *directory) checkPassword(dn, password string) *model.AppError {
+	// An empty password would make for an unauthenticated bind, which most servers accept.
+	if password == "" {
+		return model.NewAppError("checkPassword", "ent.ldap.do_login.invalid_password.app_error", nil, "", http.StatusUnauthorized)
+	}
+
+	conn, err := d.dial()
+	if err != nil {
+		return model.NewAppError("checkPassword", "ent.ldap.do_login.unable_to_connect.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer conn.Close()
+
+	if err := conn.Bind(dn, password); err != nil {
+		return model.NewAppError("checkPassword", "ent.ldap.do_login.invalid_password.app_error", nil, err.Error(), http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+// test checks that the server can be reached with the configured credentials and that the base DN
+// exists.
+func (d *directory) test() *model.AppError {
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return appErr
+	}
+	defer conn.Close()
+
+	request := ldap.NewSearchRequest(*d.settings.BaseDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)", []string{"dn"}, nil)
+	if _, err := conn.Search(request); err != nil {
+		return model.NewAppError("test", "ent.ldap.do_login.search_ldap_server.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (d *directory) search(conn *ldap.Conn, filter string, attributes []string) ([]*ldap.Entry, error) {
+	request := ldap.NewSearchRequest(*d.settings.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, filter, attributes, nil)
+
+	var result *ldap.SearchResult
+	var err error
+	if *d.settings.MaxPageSize > 0 {
+		result, err = conn.SearchWithPaging(request, uint32(*d.settings.MaxPageSize))
+	} else {
+		result, err = conn.Search(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Entries, nil
+}
+
+func (d *directory) searchError(where string, err error) *model.AppError {
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return model.NewAppError(where, "ent.ldap.syncronize.search_failure_size_exceeded.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return model.NewAppError(where, "ent.ldap.syncronize.search_failure.app_error", nil, err.Error(), http.StatusInternalServerError)
+}
+
+// userFilter restricts the given filter to the users allowed by the UserFilter.
+func (d *directory) userFilter(filter string) string {
+	if *d.settings.UserFilter == "" {
+		return filter
+	}
+
+	return "(&" + wrapFilter(*d.settings.UserFilter) + filter + ")"
+}
+
+func (d *directory) userAttributes() []string {
+	var attributes []string
+	for _, attribute := range []string{
+		*d.settings.IdAttribute,
+		*d.settings.LoginIdAttribute,
+		*d.settings.UsernameAttribute,
+		*d.settings.EmailAttribute,
+		*d.settings.FirstNameAttribute,
+		*d.settings.LastNameAttribute,
+		*d.settings.NicknameAttribute,
+		*d.settings.PositionAttribute,
+	} {
+		if attribute != "" {
+			attributes = append(attributes, attribute)
+		}
+	}
+	return attributes
+}
+
+// findUser returns the single user whose attribute has the given value.
+func (d *directory) findUser(conn *ldap.Conn, attribute, value string, extraAttributes ...string) (*ldap.Entry, *model.AppError) {
+	filter := "(" + attribute + "=" + ldap.EscapeFilter(value) + ")"
+
+	entries, err := d.search(conn, d.userFilter(filter), append(d.userAttributes(), extraAttributes...))
+	if err != nil {
+		return nil, model.NewAppError("findUser", "ent.ldap.do_login.search_ldap_server.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(entries) > 1 {
+		return nil, model.NewAppError("findUser", "ent.ldap.do_login.matched_to_many_users.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(entries) == 0 {
+		// Tell users who exist but aren't allowed in by the user filter apart from users that don't
+		// exist at all.
+		if *d.settings.UserFilter != "" {
+			if unfiltered, err := d.search(conn, filter, []string{"dn"}); err == nil && len(unfiltered) > 0 {
+				return nil, model.NewAppError("findUser", "ent.ldap.do_login.user_filtered.app_error", nil, "", http.StatusForbidden)
+			}
+		}
+
+		return nil, model.NewAppError("findUser", "ent.ldap.do_login.user_not_registered.app_error", nil, "", http.StatusNotFound)
+	}
+
+	return entries[0], nil
+}
+
+// getAllUsers returns every user allowed by the UserFilter.
+func (d *directory) getAllUsers(conn *ldap.Conn, extraAttributes ...string) ([]*ldap.Entry, *model.AppError) {
+	entries, err := d.search(conn, d.userFilter("("+*d.settings.IdAttribute+"=*)"), append(d.userAttributes(), extraAttributes...))
+	if err != nil {
+		return nil, d.searchError("getAllUsers", err)
+	}
+
+	return entries, nil
+}
+
+// userFromEntry maps the attributes of a user entry to a Mattermost user. Attributes that aren't
+// configured are left empty.
+func (d *directory) userFromEntry(entry *ldap.Entry) *model.User {
+	authData := getAttributeValue(entry, *d.settings.IdAttribute)
+
+	user := &model.User{
+		AuthService: model.USER_AUTH_SERVICE_LDAP,
+		AuthData:    &authData,
+		Email:       strings.ToLower(getAttributeValue(entry, *d.settings.EmailAttribute)),
+		FirstName:   getAttributeValue(entry, *d.settings.FirstNameAttribute),
+		LastName:    getAttributeValue(entry, *d.settings.LastNameAttribute),
+		Nickname:    getAttributeValue(entry, *d.settings.NicknameAttribute),
+		Position:    getAttributeValue(entry, *d.settings.PositionAttribute),
+	}
+
+	if username := getAttributeValue(entry, *d.settings.UsernameAttribute); username != "" {
+		user.Username = model.CleanUsername(username)
+	}
+
+	return user
+}
+
+func (d *directory) groupFilter(filter string) string {
+	groupFilter := *d.settings.GroupFilter
+	if groupFilter == "" {
+		groupFilter = DEFAULT_GROUP_FILTER
+	}
+
+	if filter == "" {
+		return wrapFilter(groupFilter)
+	}
+
+	return "(&" + wrapFilter(groupFilter) + filter + ")"
+}
+
+func (d *directory) groupAttributes() []string {
+	return append([]string{*d.settings.GroupIdAttribute, *d.settings.GroupDisplayNameAttribute}, GROUP_MEMBER_ATTRIBUTES...)
+}
+
+func (d *directory) checkGroupSettings() error {
+	if *d.settings.GroupIdAttribute == "" || *d.settings.GroupDisplayNameAttribute == "" {
+		return fmt.Errorf("the group id and display name attributes must be set")
+	}
+
+	return nil
+}
+
+// searchGroups returns the groups allowed by the GroupFilter whose display name contains the given
+// term, or all of them if it is empty.
+func (d *directory) searchGroups(conn *ldap.Conn, term string) ([]*ldap.Entry, *model.AppError) {
+	if err := d.checkGroupSettings(); err != nil {
+		return nil, model.NewAppError("searchGroups", "ent.ldap_groups.groups_search_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	filter := ""
+	if term != "" {
+		filter = "(" + *d.settings.GroupDisplayNameAttribute + "=*" + ldap.EscapeFilter(term) + "*)"
+	}
+
+	entries, err := d.search(conn, d.groupFilter(filter), d.groupAttributes())
+	if err != nil {
+		return nil, model.NewAppError("searchGroups", "ent.ldap_groups.groups_search_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return entries, nil
+}
+
+// findGroup returns the group with the given remote id, or nil if there isn't one.
+func (d *directory) findGroup(conn *ldap.Conn, remoteId string) (*ldap.Entry, *model.AppError) {
+	if err := d.checkGroupSettings(); err != nil {
+		return nil, model.NewAppError("findGroup", "ent.ldap_groups.group_search_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	filter := "(" + *d.settings.GroupIdAttribute + "=" + ldap.EscapeFilter(remoteId) + ")"
+
+	entries, err := d.search(conn, d.groupFilter(filter), d.groupAttributes())
+	if err != nil {
+		return nil, model.NewAppError("findGroup", "ent.ldap_groups.group_search_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return entries[0], nil
+}
+
+func (d *directory) groupFromEntry(entry *ldap.Entry) *model.Group {
+	return &model.Group{
+		DisplayName: getAttributeValue(entry, *d.settings.GroupDisplayNameAttribute),
+		RemoteId:    getAttributeValue(entry, *d.settings.GroupIdAttribute),
+		Source:      model.GroupSourceLdap,
+	}
+}
+
+// groupMemberDNs returns the normalized DNs of the members of the group, including the members of
+// nested groups but not the nested groups themselves. groupsByDN holds every known group by
+// normalized DN.
+func groupMemberDNs(group *ldap.Entry, groupsByDN map[string]*ldap.Entry) map[string]bool {
+	members := map[string]bool{}
+	visited := map[string]bool{normalizeDN(group.DN): true}
+
+	pending := []*ldap.Entry{group}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		for _, attribute := range GROUP_MEMBER_ATTRIBUTES {
+			for _, memberDN := range getAttributeValues(current, attribute) {
+				dn := normalizeDN(memberDN)
+
+				if nested, ok := groupsByDN[dn]; ok {
+					if !visited[dn] {
+						visited[dn] = true
+						pending = append(pending, nested)
+					}
+					continue
+				}
+
+				members[dn] = true
+			}
+		}
+	}
+
+	return members
+}
+
+// normalizeDN returns a form of the DN that can be compared with others, since servers don't
+// necessarily preserve the case or spacing of the DNs they're given.
+func normalizeDN(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil {
+		return strings.ToLower(dn)
+	}
+
+	rdns := make([]string, 0, len(parsed.RDNs))
+	for _, rdn := range parsed.RDNs {
+		attributes := make([]string, 0, len(rdn.Attributes))
+		for _, attribute := range rdn.Attributes {
+			attributes = append(attributes, strings.ToLower(attribute.Type)+"="+strings.ToLower(attribute.Value))
+		}
+		rdns = append(rdns, strings.Join(attributes, "+"))
+	}
+
+	return strings.Join(rdns, ",")
+}
+
+func wrapFilter(filter string) string {
+	filter = strings.TrimSpace(filter)
+	if strings.HasPrefix(filter, "(") {
+		return filter
+	}
+
+	return "(" + filter + ")"
+}
+
+// getAttributeValues returns the values of the attribute, whose name is matched without regard to
+// case like the server does. Binary values, such as those of objectGUID, are hex encoded.
+func getAttributeValues(entry *ldap.Entry, name string) []string {
+	if name == "" {
+		return nil
+	}
+
+	for _, attribute := range entry.Attributes {
+		if !strings.EqualFold(attribute.Name, name) {
+			continue
+		}
+
+		values := make([]string, 0, len(attribute.Values))
+		for i, value := range attribute.Values {
+			if !utf8.ValidString(value) && i < len(attribute.ByteValues) {
+				value = hex.EncodeToString(attribute.ByteValues[i])
+			}
+			values = append(values, value)
+		}
+		return values
+	}
+
+	return nil
+}
+
+func getAttributeValue(entry *ldap.Entry, name string) string {
+	if values := getAttributeValues(entry, name); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,321 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"testing"
+
+	"github.com/go-ldap/ldap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func testEntries() []*testEntry {
+	return []*testEntry{
+		{
+			dn: TEST_BASE_DN,
+			attributes: map[string][]string{
+				"objectClass": {"dcObject", "organization"},
+			},
+		},
+		{
+			dn:       "uid=test.one,ou=testusers," + TEST_BASE_DN,
+			password: "Password1",
+			attributes: map[string][]string{
+				"objectClass": {"iNetOrgPerson"},
+				"uid":         {"test.one"},
+				"cn":          {"Test"},
+				"sn":          {"One"},
+				"mail":        {"Success+TestOne@SimulatorAmazonSES.com"},
+				"title":       {"Tester"},
+			},
+		},
+		{
+			dn:       "uid=test.two,ou=testusers," + TEST_BASE_DN,
+			password: "Password1",
+			attributes: map[string][]string{
+				"objectClass": {"iNetOrgPerson"},
+				"uid":         {"test.two"},
+				"cn":          {"Test"},
+				"sn":          {"Two"},
+				"mail":        {"success+testtwo@simulatoramazonses.com"},
+			},
+		},
+		{
+			dn:       "uid=dev.one,ou=devusers," + TEST_BASE_DN,
+			password: "Password1",
+			attributes: map[string][]string{
+				"objectClass": {"iNetOrgPerson"},
+				"uid":         {"dev.one"},
+				"cn":          {"Dev"},
+				"sn":          {"One"},
+				"mail":        {"success+devone@simulatoramazonses.com"},
+			},
+		},
+		{
+			dn: "cn=tgroup-a,ou=testgroups," + TEST_BASE_DN,
+			attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"tgroup-a"},
+				"entryUUID":   {"a"},
+				"member": {
+					"uid=test.one,ou=testusers," + TEST_BASE_DN,
+					"cn=tgroup-b,ou=testgroups," + TEST_BASE_DN,
+				},
+			},
+		},
+		{
+			dn: "cn=tgroup-b,ou=testgroups," + TEST_BASE_DN,
+			attributes: map[string][]string{
+				"objectClass": {"groupOfNames"},
+				"cn":          {"tgroup-b"},
+				"entryUUID":   {"b"},
+				"member": {
+					"UID=Test.Two, OU=TestUsers, DC=mm, DC=test, DC=com",
+					"cn=tgroup-a,ou=testgroups," + TEST_BASE_DN,
+				},
+			},
+		},
+		{
+			dn: "cn=dgroup,ou=testgroups," + TEST_BASE_DN,
+			attributes: map[string][]string{
+				"objectClass": {"groupOfUniqueNames"},
+				"cn":          {"dgroup"},
+				"entryUUID":   {"d"},
+				"uniqueMember": {
+					"uid=dev.one,ou=devusers," + TEST_BASE_DN,
+				},
+			},
+		},
+	}
+}
+
+func TestDirectoryConnect(t *testing.T) {
+	server := newTestServer(t, testEntries()...)
+	defer server.Close()
+
+	t.Run("valid credentials", func(t *testing.T) {
+		d := newDirectory(server.settings())
+		require.Nil(t, d.test())
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		settings := server.settings()
+		*settings.BindPassword = "wrong"
+
+		appErr := newDirectory(settings).test()
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap.do_login.bind_admin_user.app_error", appErr.Id)
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		settings := server.settings()
+		*settings.LdapPort = 1
+
+		appErr := newDirectory(settings).test()
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap.do_login.unable_to_connect.app_error", appErr.Id)
+	})
+}
+
+func TestDirectoryFindUser(t *testing.T) {
+	server := newTestServer(t, testEntries()...)
+	defer server.Close()
+
+	settings := server.settings()
+	d := newDirectory(settings)
+
+	conn, appErr := d.connect()
+	require.Nil(t, appErr)
+	defer conn.Close()
+
+	t.Run("found", func(t *testing.T) {
+		entry, appErr := d.findUser(conn, "uid", "test.one")
+		require.Nil(t, appErr)
+		assert.Equal(t, "uid=test.one,ou=testusers,"+TEST_BASE_DN, entry.DN)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, appErr := d.findUser(conn, "uid", "nobody")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap.do_login.user_not_registered.app_error", appErr.Id)
+	})
+
+	t.Run("escaped", func(t *testing.T) {
+		_, appErr := d.findUser(conn, "uid", "*")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap.do_login.user_not_registered.app_error", appErr.Id)
+	})
+
+	t.Run("matched to many users", func(t *testing.T) {
+		_, appErr := d.findUser(conn, "cn", "test")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap.do_login.matched_to_many_users.app_error", appErr.Id)
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		filtered := server.settings()
+		*filtered.UserFilter = "(sn=Two)"
+		d := newDirectory(filtered)
+
+		_, appErr := d.findUser(conn, "uid", "test.one")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap.do_login.user_filtered.app_error", appErr.Id)
+
+		entry, appErr := d.findUser(conn, "uid", "test.two")
+		require.Nil(t, appErr)
+		assert.Equal(t, "test.two", getAttributeValue(entry, "uid"))
+	})
+
+	t.Run("get all users", func(t *testing.T) {
+		filtered := server.settings()
+		*filtered.BaseDN = "ou=testusers," + TEST_BASE_DN
+
+		entries, appErr := newDirectory(filtered).getAllUsers(conn)
+		require.Nil(t, appErr)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestDirectoryCheckPassword(t *testing.T) {
+	server := newTestServer(t, testEntries()...)
+	defer server.Close()
+
+	d := newDirectory(server.settings())
+	dn := "uid=test.one,ou=testusers," + TEST_BASE_DN
+
+	assert.Nil(t, d.checkPassword(dn, "Password1"))
+
+	appErr := d.checkPassword(dn, "wrong")
+	require.NotNil(t, appErr)
+	assert.Equal(t, "ent.ldap.do_login.invalid_password.app_error", appErr.Id)
+
+	// An empty password must never be taken for an unauthenticated bind.
+	appErr = d.checkPassword(dn, "")
+	require.NotNil(t, appErr)
+	assert.Equal(t, "ent.ldap.do_login.invalid_password.app_error", appErr.Id)
+}
+
+func TestDirectoryUserFromEntry(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	settings := server.settings()
+	*settings.PositionAttribute = "title"
+	d := newDirectory(settings)
+
+	entry := ldap.NewEntry("uid=test.one,ou=testusers,"+TEST_BASE_DN, map[string][]string{
+		"UID":   {"Test.One"},
+		"cn":    {"Test"},
+		"sn":    {"One"},
+		"mail":  {"Success+TestOne@SimulatorAmazonSES.com"},
+		"title": {"Tester"},
+	})
+
+	user := d.userFromEntry(entry)
+	assert.Equal(t, model.USER_AUTH_SERVICE_LDAP, user.AuthService)
+	assert.Equal(t, "Test.One", *user.AuthData)
+	assert.Equal(t, "test.one", user.Username)
+	assert.Equal(t, "success+testone@simulatoramazonses.com", user.Email)
+	assert.Equal(t, "Test", user.FirstName)
+	assert.Equal(t, "One", user.LastName)
+	assert.Equal(t, "", user.Nickname)
+	assert.Equal(t, "Tester", user.Position)
+
+	binary := ldap.NewEntry("cn=binary", map[string][]string{"objectGUID": {"\xff\x00\x10"}})
+	assert.Equal(t, "ff0010", getAttributeValue(binary, "objectguid"))
+}
+
+func TestDirectoryUpdateUserAttributes(t *testing.T) {
+	settings := model.LdapSettings{}
+	settings.SetDefaults()
+	*settings.UsernameAttribute = "uid"
+	*settings.EmailAttribute = "mail"
+	*settings.FirstNameAttribute = "cn"
+	*settings.LastNameAttribute = "sn"
+	d := newDirectory(settings)
+
+	user := &model.User{Username: "test.one", Email: "test.one@example.com", FirstName: "Test", Nickname: "Local"}
+
+	assert.False(t, d.updateUserAttributes(user, &model.User{Username: "test.one", Email: "test.one@example.com", FirstName: "Test"}))
+	assert.Equal(t, "Local", user.Nickname, "attributes that aren't configured are left alone")
+
+	assert.True(t, d.updateUserAttributes(user, &model.User{Username: "", Email: "new@example.com", FirstName: "New"}))
+	assert.Equal(t, "test.one", user.Username, "an empty username is ignored")
+	assert.Equal(t, "new@example.com", user.Email)
+	assert.Equal(t, "New", user.FirstName)
+	assert.Equal(t, "", user.LastName)
+}
+
+func TestDirectoryGroups(t *testing.T) {
+	server := newTestServer(t, testEntries()...)
+	defer server.Close()
+
+	d := newDirectory(server.settings())
+
+	conn, appErr := d.connect()
+	require.Nil(t, appErr)
+	defer conn.Close()
+
+	t.Run("search", func(t *testing.T) {
+		entries, appErr := d.searchGroups(conn, "")
+		require.Nil(t, appErr)
+		assert.Len(t, entries, 3)
+
+		entries, appErr = d.searchGroups(conn, "GROUP-")
+		require.Nil(t, appErr)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("find", func(t *testing.T) {
+		entry, appErr := d.findGroup(conn, "b")
+		require.Nil(t, appErr)
+		require.NotNil(t, entry)
+
+		group := d.groupFromEntry(entry)
+		assert.Equal(t, "b", group.RemoteId)
+		assert.Equal(t, "tgroup-b", group.DisplayName)
+		assert.Equal(t, model.GroupSourceLdap, group.Source)
+
+		entry, appErr = d.findGroup(conn, "missing")
+		require.Nil(t, appErr)
+		assert.Nil(t, entry)
+	})
+
+	t.Run("nested memberships", func(t *testing.T) {
+		entries, appErr := d.searchGroups(conn, "")
+		require.Nil(t, appErr)
+
+		testOne := normalizeDN("uid=test.one,ou=testusers," + TEST_BASE_DN)
+		testTwo := normalizeDN("uid=test.two,ou=testusers," + TEST_BASE_DN)
+		devOne := normalizeDN("uid=dev.one,ou=devusers," + TEST_BASE_DN)
+
+		// The two test groups contain each other, so they end up with the same members.
+		memberships := getGroupMemberships(d, entries)
+		assert.Equal(t, map[string]bool{testOne: true, testTwo: true}, memberships["a"])
+		assert.Equal(t, map[string]bool{testOne: true, testTwo: true}, memberships["b"])
+		assert.Equal(t, map[string]bool{devOne: true}, memberships["d"])
+	})
+
+	t.Run("missing settings", func(t *testing.T) {
+		settings := server.settings()
+		*settings.GroupIdAttribute = ""
+
+		_, appErr := newDirectory(settings).searchGroups(conn, "")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.ldap_groups.groups_search_error", appErr.Id)
+	})
+}
+
+func TestNormalizeDN(t *testing.T) {
+	assert.Equal(t, "uid=test.one,ou=testusers,dc=mm,dc=test,dc=com", normalizeDN("UID=Test.One, OU=TestUsers,DC=mm,DC=test,DC=com"))
+	assert.Equal(t, "not a dn", normalizeDN("Not a DN"))
+}
+
+func TestWrapFilter(t *testing.T) {
+	assert.Equal(t, "(objectClass=user)", wrapFilter("objectClass=user"))
+	assert.Equal(t, "(objectClass=user)", wrapFilter(" (objectClass=user) "))
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-ldap/ldap"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// GetGroup returns the AD/LDAP group with the given remote id, or nil if there isn't one.
+func (l *LdapInterfaceImpl) GetGroup(groupUID string) (*model.Group, *model.AppError) {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findGroup(conn, groupUID)
+	if appErr != nil || entry == nil {
+		return nil, appErr
+	}
+
+	return d.groupFromEntry(entry), nil
+}
+
+// GetAllGroupsPage returns a page of the AD/LDAP groups sorted by display name, along with the total
+// number of groups matching the options. Groups that are linked to a Mattermost group carry the id of
+// that group.
+func (l *LdapInterfaceImpl) GetAllGroupsPage(page int, perPage int, opts model.LdapGroupSearchOpts) ([]*model.Group, int, *model.AppError) {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, 0, appErr
+	}
+	defer conn.Close()
+
+	entries, appErr := d.searchGroups(conn, opts.Q)
+	if appErr != nil {
+		return nil, 0, appErr
+	}
+
+	linkedGroups, appErr := l.App.GetGroupsBySource(model.GroupSourceLdap)
+	if appErr != nil {
+		return nil, 0, model.NewAppError("GetAllGroupsPage", "ent.ldap.syncronize.get_all_groups.app_error", nil, appErr.Error(), http.StatusInternalServerError)
+	}
+
+	linkedGroupsByRemoteId := make(map[string]*model.Group, len(linkedGroups))
+	for _, group := range linkedGroups {
+		linkedGroupsByRemoteId[group.RemoteId] = group
+	}
+
+	var groups []*model.Group
+	for _, entry := range entries {
+		group := d.groupFromEntry(entry)
+
+		if linkedGroup, ok := linkedGroupsByRemoteId[group.RemoteId]; ok {
+			group.Id = linkedGroup.Id
+			group.Name = linkedGroup.Name
+			if group.HasSyncables, appErr = l.hasSyncables(group.Id); appErr != nil {
+				return nil, 0, appErr
+			}
+		}
+
+		isLinked := group.Id != ""
+		if opts.IsLinked != nil && *opts.IsLinked != isLinked {
+			continue
+		}
+		if opts.IsConfigured != nil && *opts.IsConfigured != group.HasSyncables {
+			continue
+		}
+
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i].DisplayName) < strings.ToLower(groups[j].DisplayName)
+	})
+
+	total := len(groups)
+	start := page * perPage
+	if start >= total {
+		return []*model.Group{}, total, nil
+	}
+
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+
+	return groups[start:end], total, nil
+}
+
+func (l *LdapInterfaceImpl) hasSyncables(groupId string) (bool, *model.AppError) {
+	for _, syncableType := range []model.GroupSyncableType{model.GroupSyncableTypeTeam, model.GroupSyncableTypeChannel} {
+		syncables, appErr := l.App.GetGroupSyncables(groupId, syncableType)
+		if appErr != nil {
+			return false, appErr
+		}
+
+		if len(syncables) > 0 {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getGroupMemberships returns the normalized DNs of the members of each group by remote id,
+// including the members of the groups nested in it.
+func getGroupMemberships(d *directory, entries []*ldap.Entry) map[string]map[string]bool {
+	groupsByDN := make(map[string]*ldap.Entry, len(entries))
+	for _, entry := range entries {
+		groupsByDN[normalizeDN(entry.DN)] = entry
+	}
+
+	memberships := make(map[string]map[string]bool, len(entries))
+	for _, entry := range entries {
+		memberships[getAttributeValue(entry, *d.settings.GroupIdAttribute)] = groupMemberDNs(entry, groupsByDN)
+	}
+
+	return memberships
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	ejobs "github.com/mattermost/mattermost-server/einterfaces/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+const (
+	WAIT_FOR_JOB_POLL_INTERVAL = 500 * time.Millisecond
+)
+
+type LdapInterfaceImpl struct {
+	App *app.App
+}
+
+type LdapSyncInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterLdapInterface(func(a *app.App) einterfaces.LdapInterface {
+		return &LdapInterfaceImpl{a}
+	})
+	app.RegisterJobsLdapSyncInterface(func(a *app.App) ejobs.LdapSyncInterface {
+		return &LdapSyncInterfaceImpl{a}
+	})
+}
+
+func (l *LdapInterfaceImpl) directory() *directory {
+	return newDirectory(l.App.Config().LdapSettings)
+}
+
+// DoLogin checks the credentials of the user with the given login id and returns the matching
+// Mattermost user, creating it the first time they log in.
+func (l *LdapInterfaceImpl) DoLogin(id string, password string) (*model.User, *model.AppError) {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findUser(conn, *d.settings.LoginIdAttribute, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr = d.checkPassword(entry.DN, password); appErr != nil {
+		return nil, appErr
+	}
+
+	ldapUser := d.userFromEntry(entry)
+	if *ldapUser.AuthData == "" {
+		return nil, model.NewAppError("DoLogin", "ent.ldap.do_login.user_not_registered.app_error", nil, "missing id attribute", http.StatusBadRequest)
+	}
+
+	user, appErr := l.App.GetUserByAuth(ldapUser.AuthData, model.USER_AUTH_SERVICE_LDAP)
+	if appErr != nil {
+		if appErr.Id != store.MISSING_AUTH_ACCOUNT_ERROR {
+			return nil, appErr
+		}
+
+		return l.createUser(ldapUser)
+	}
+
+	// Deactivated users are left alone so that they still can't log in. Synchronization takes care
+	// of reactivating them if need be.
+	if user.DeleteAt != 0 {
+		return user, nil
+	}
+
+	changed, appErr := l.updateUser(user, ldapUser)
+	if appErr != nil {
+		mlog.Warn("Failed to update AD/LDAP user attributes at login", mlog.String("user_id", user.Id), mlog.Err(appErr))
+	}
+	if !changed {
+		return user, nil
+	}
+
+	return l.App.GetUser(user.Id)
+}
+
+func (l *LdapInterfaceImpl) createUser(ldapUser *model.User) (*model.User, *model.AppError) {
+	if ldapUser.Username == "" || ldapUser.Email == "" {
+		return nil, model.NewAppError("DoLogin", "ent.ldap.create_fail", nil, "missing username or email attribute", http.StatusBadRequest)
+	}
+
+	ldapUser.EmailVerified = true
+
+	user, appErr := l.App.CreateUser(ldapUser)
+	if appErr != nil {
+		return nil, model.NewAppError("DoLogin", "ent.ldap.create_fail", nil, appErr.Error(), appErr.StatusCode)
+	}
+
+	if appErr := l.FirstLoginSync(user.Id, model.USER_AUTH_SERVICE_LDAP, *ldapUser.AuthData); appErr != nil {
+		mlog.Warn("Failed to sync the AD/LDAP groups of a new user", mlog.String("user_id", user.Id), mlog.Err(appErr))
+	}
+
+	// The created user comes back sanitized.
+	return l.App.GetUser(user.Id)
+}
+
+// GetUser returns the user with the given login id as described by the AD/LDAP server, without
+// looking it up in Mattermost.
+func (l *LdapInterfaceImpl) GetUser(id string) (*model.User, *model.AppError) {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findUser(conn, *d.settings.LoginIdAttribute, id)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	return d.userFromEntry(entry), nil
+}
+
+// GetUserAttributes returns the first value of each of the given attributes of the user with the
+// given id, as stored in its AuthData.
+func (l *LdapInterfaceImpl) GetUserAttributes(id string, attributes []string) (map[string]string, *model.AppError) {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findUser(conn, *d.settings.IdAttribute, id, attributes...)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	values := make(map[string]string, len(attributes))
+	for _, attribute := range attributes {
+		values[attribute] = getAttributeValue(entry, attribute)
+	}
+
+	return values, nil
+}
+
+// CheckPassword checks the password of the user with the given login id.
+func (l *LdapInterfaceImpl) CheckPassword(id string, password string) *model.AppError {
+	return l.checkPassword(*l.App.Config().LdapSettings.LoginIdAttribute, id, password)
+}
+
+// CheckPasswordAuthData checks the password of the user with the given id, as stored in its
+// AuthData.
+func (l *LdapInterfaceImpl) CheckPasswordAuthData(authData string, password string) *model.AppError {
+	return l.checkPassword(*l.App.Config().LdapSettings.IdAttribute, authData, password)
+}
+
+func (l *LdapInterfaceImpl) checkPassword(attribute, value, password string) *model.AppError {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findUser(conn, attribute, value)
+	if appErr != nil {
+		return appErr
+	}
+
+	return d.checkPassword(entry.DN, password)
+}
+
+// SwitchToLdap turns the email account of the given user into the AD/LDAP account with the given
+// login id once its password is checked.
+func (l *LdapInterfaceImpl) SwitchToLdap(userId, ldapId, ldapPassword string) *model.AppError {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findUser(conn, *d.settings.LoginIdAttribute, ldapId)
+	if appErr != nil {
+		return appErr
+	}
+
+	if appErr = d.checkPassword(entry.DN, ldapPassword); appErr != nil {
+		return appErr
+	}
+
+	authData := d.userFromEntry(entry).AuthData
+	if user, _ := l.App.GetUserByAuth(authData, model.USER_AUTH_SERVICE_LDAP); user != nil {
+		return model.NewAppError("SwitchToLdap", "ent.ldap.do_login.matched_to_many_users.app_error", nil, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	if result := <-l.App.Srv.Store.User().UpdateAuthData(userId, model.USER_AUTH_SERVICE_LDAP, authData, "", false); result.Err != nil {
+		return result.Err
+	}
+
+	l.App.InvalidateCacheForUser(userId)
+
+	return nil
+}
+
+// StartSynchronizeJob queues a synchronization job. When asked to wait for it, the job is run right
+// away unless a job server claims it first, so that it completes even where no workers are running.
+func (l *LdapInterfaceImpl) StartSynchronizeJob(waitForJobToFinish bool) (*model.Job, *model.AppError) {
+	job, appErr := l.App.Srv.Jobs.CreateJob(model.JOB_TYPE_LDAP_SYNC, nil)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if !waitForJobToFinish {
+		return job, nil
+	}
+
+	worker := (&LdapSyncInterfaceImpl{l.App}).MakeWorker().(*Worker)
+	worker.DoJob(job)
+
+	for {
+		result := <-l.App.Srv.Store.Job().Get(job.Id)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+
+		job = result.Data.(*model.Job)
+		if job.Status != model.JOB_STATUS_PENDING && job.Status != model.JOB_STATUS_IN_PROGRESS && job.Status != model.JOB_STATUS_CANCEL_REQUESTED {
+			return job, nil
+		}
+
+		time.Sleep(WAIT_FOR_JOB_POLL_INTERVAL)
+	}
+}
+
+// RunTest checks that the AD/LDAP server can be reached and searched with the configured settings.
+func (l *LdapInterfaceImpl) RunTest() *model.AppError {
+	return l.directory().test()
+}
+
+// GetAllLdapUsers returns every user allowed by the user filter, as described by the AD/LDAP
+// server.
+func (l *LdapInterfaceImpl) GetAllLdapUsers() ([]*model.User, *model.AppError) {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer conn.Close()
+
+	entries, appErr := d.getAllUsers(conn)
+	if appErr != nil {
+		return nil, model.NewAppError("GetAllLdapUsers", "ent.ldap.syncronize.get_all.app_error", nil, appErr.Error(), appErr.StatusCode)
+	}
+
+	users := make([]*model.User, 0, len(entries))
+	for _, entry := range entries {
+		users = append(users, d.userFromEntry(entry))
+	}
+
+	return users, nil
+}
+
+// MigrateIDAttribute changes the AuthData of every AD/LDAP user from the value of the current
+// IdAttribute to the value of the given attribute. The IdAttribute is expected to be changed right
+// after.
+func (l *LdapInterfaceImpl) MigrateIDAttribute(toAttribute string) error {
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return appErr
+	}
+	defer conn.Close()
+
+	entries, appErr := d.getAllUsers(conn, toAttribute)
+	if appErr != nil {
+		return appErr
+	}
+
+	newIds := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		newIds[getAttributeValue(entry, *d.settings.IdAttribute)] = getAttributeValue(entry, toAttribute)
+	}
+
+	result := <-l.App.Srv.Store.User().GetAllUsingAuthService(model.USER_AUTH_SERVICE_LDAP)
+	if result.Err != nil {
+		return result.Err
+	}
+
+	for _, user := range result.Data.([]*model.User) {
+		if user.AuthData == nil {
+			continue
+		}
+
+		newId := newIds[*user.AuthData]
+		if newId == "" {
+			mlog.Warn("Skipping the migration of an AD/LDAP user that couldn't be found or is missing the new id attribute", mlog.String("user_id", user.Id))
+			continue
+		}
+
+		if result := <-l.App.Srv.Store.User().UpdateAuthData(user.Id, model.USER_AUTH_SERVICE_LDAP, &newId, "", false); result.Err != nil {
+			return fmt.Errorf("failed to migrate user %v: %v", user.Id, result.Err.Error())
+		}
+
+		l.App.InvalidateCacheForUser(user.Id)
+	}
+
+	return nil
+}
+
+// FirstLoginSync adds a user logging in for the first time to the groups they belong to and, through
+// them, to the teams and channels synced with those groups.
+func (l *LdapInterfaceImpl) FirstLoginSync(userID, userAuthService, userAuthData string) *model.AppError {
+	if userAuthService != model.USER_AUTH_SERVICE_LDAP {
+		return nil
+	}
+
+	groups, appErr := l.App.GetGroupsBySource(model.GroupSourceLdap)
+	if appErr != nil || len(groups) == 0 {
+		return appErr
+	}
+
+	d := l.directory()
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return appErr
+	}
+	defer conn.Close()
+
+	entry, appErr := d.findUser(conn, *d.settings.IdAttribute, userAuthData)
+	if appErr != nil {
+		return appErr
+	}
+
+	ldapGroups, appErr := d.searchGroups(conn, "")
+	if appErr != nil {
+		return appErr
+	}
+
+	memberships := getGroupMemberships(d, ldapGroups)
+	userDN := normalizeDN(entry.DN)
+
+	since := model.GetMillis()
+	for _, group := range groups {
+		if !memberships[group.RemoteId][userDN] {
+			continue
+		}
+
+		if _, appErr := l.App.CreateOrRestoreGroupMember(group.Id, userID); appErr != nil && appErr.Id != "store.sql_group.uniqueness_error" {
+			return appErr
+		}
+	}
+
+	if err := l.App.CreateDefaultMemberships(since); err != nil {
+		return model.NewAppError("FirstLoginSync", "ent.ldap.syncronize.populate_syncables", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+type Scheduler struct {
+	App *app.App
+}
+
+func (l *LdapSyncInterfaceImpl) MakeScheduler() model.Scheduler {
+	return &Scheduler{l.App}
+}
+
+func (scheduler *Scheduler) Name() string {
+	return "LdapSyncScheduler"
+}
+
+func (scheduler *Scheduler) JobType() string {
+	return model.JOB_TYPE_LDAP_SYNC
+}
+
+func (scheduler *Scheduler) Enabled(cfg *model.Config) bool {
+	return *cfg.LdapSettings.EnableSync
+}
+
+func (scheduler *Scheduler) NextScheduleTime(cfg *model.Config, now time.Time, pendingJobs bool, lastSuccessfulJob *model.Job) *time.Time {
+	nextTime := now.Add(time.Duration(*cfg.LdapSettings.SyncIntervalMinutes) * time.Minute)
+	return &nextTime
+}
+
+func (scheduler *Scheduler) ScheduleJob(cfg *model.Config, pendingJobs bool, lastSuccessfulJob *model.Job) (*model.Job, *model.AppError) {
+	mlog.Debug("Scheduling Job", mlog.String("scheduler", scheduler.Name()))
+
+	// A pending synchronization will pick up every change a new one would.
+	if pendingJobs {
+		return nil, nil
+	}
+
+	return scheduler.App.Srv.Jobs.CreateJob(model.JOB_TYPE_LDAP_SYNC, nil)
+}
@@ -0,0 +1,288 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-ldap/ldap"
+	"github.com/stretchr/testify/require"
+	ber "gopkg.in/asn1-ber.v1"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	TEST_BASE_DN       = "dc=mm,dc=test,dc=com"
+	TEST_BIND_DN       = "cn=admin,dc=mm,dc=test,dc=com"
+	TEST_BIND_PASSWORD = "mostest"
+)
+
+type testEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+func (entry *testEntry) values(name string) []string {
+	for attribute, values := range entry.attributes {
+		if strings.EqualFold(attribute, name) {
+			return values
+		}
+	}
+
+	return nil
+}
+
+// testServer is a minimal in-process LDAP server supporting simple binds and searches, enough to
+// exercise the directory without an actual server.
+type testServer struct {
+	listener net.Listener
+	entries  []*testEntry
+}
+
+func newTestServer(t *testing.T, entries ...*testEntry) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	server := &testServer{
+		listener: listener,
+		entries:  entries,
+	}
+	go server.serve()
+
+	return server
+}
+
+func (server *testServer) Close() {
+	server.listener.Close()
+}
+
+func (server *testServer) settings() model.LdapSettings {
+	settings := model.LdapSettings{}
+	settings.SetDefaults()
+
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+
+	*settings.Enable = true
+	*settings.LdapServer = host
+	*settings.LdapPort = portNumber
+	*settings.BaseDN = TEST_BASE_DN
+	*settings.BindUsername = TEST_BIND_DN
+	*settings.BindPassword = TEST_BIND_PASSWORD
+	*settings.IdAttribute = "uid"
+	*settings.LoginIdAttribute = "uid"
+	*settings.UsernameAttribute = "uid"
+	*settings.EmailAttribute = "mail"
+	*settings.FirstNameAttribute = "cn"
+	*settings.LastNameAttribute = "sn"
+	*settings.GroupIdAttribute = "entryUUID"
+	*settings.GroupDisplayNameAttribute = "cn"
+
+	return settings
+}
+
+func (server *testServer) serve() {
+	for {
+		conn, err := server.listener.Accept()
+		if err != nil {
+			return
+		}
+		go server.handle(conn)
+	}
+}
+
+func (server *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+
+		messageId := request.Children[0].Value.(int64)
+		op := request.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+
+			resultCode := ldap.LDAPResultInvalidCredentials
+			if dn == "" && password == "" {
+				resultCode = ldap.LDAPResultSuccess
+			} else if dn == TEST_BIND_DN && password == TEST_BIND_PASSWORD {
+				resultCode = ldap.LDAPResultSuccess
+			} else if entry := server.find(dn); entry != nil && entry.password != "" && entry.password == password {
+				resultCode = ldap.LDAPResultSuccess
+			}
+
+			conn.Write(response(messageId, result(ldap.ApplicationBindResponse, resultCode)).Bytes())
+		case ldap.ApplicationSearchRequest:
+			baseDN := normalizeDN(op.Children[0].Value.(string))
+			scope := op.Children[1].Value.(int64)
+			filter := op.Children[6]
+
+			var attributes []string
+			for _, attribute := range op.Children[7].Children {
+				attributes = append(attributes, attribute.Value.(string))
+			}
+
+			for _, entry := range server.entries {
+				if !inScope(normalizeDN(entry.dn), baseDN, scope) || !matches(entry, filter) {
+					continue
+				}
+				conn.Write(response(messageId, searchResultEntry(entry, attributes)).Bytes())
+			}
+
+			conn.Write(response(messageId, result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)).Bytes())
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (server *testServer) find(dn string) *testEntry {
+	for _, entry := range server.entries {
+		if normalizeDN(entry.dn) == normalizeDN(dn) {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+func inScope(dn, baseDN string, scope int64) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == baseDN
+	case ldap.ScopeSingleLevel:
+		return strings.HasSuffix(dn, ","+baseDN) && !strings.Contains(strings.TrimSuffix(dn, ","+baseDN), ",")
+	default:
+		return dn == baseDN || strings.HasSuffix(dn, ","+baseDN)
+	}
+}
+
+func matches(entry *testEntry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if !matches(entry, child) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matches(entry, child) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !matches(entry, filter.Children[0])
+	case ldap.FilterEqualityMatch:
+		attribute := filter.Children[0].Value.(string)
+		value := filter.Children[1].Value.(string)
+		for _, candidate := range entry.values(attribute) {
+			if strings.EqualFold(candidate, value) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterSubstrings:
+		attribute := filter.Children[0].Value.(string)
+		for _, candidate := range entry.values(attribute) {
+			if matchesSubstrings(strings.ToLower(candidate), filter.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		attribute := filter.Data.String()
+		return strings.EqualFold(attribute, "objectClass") || entry.values(attribute) != nil
+	}
+
+	return false
+}
+
+func matchesSubstrings(value string, parts []*ber.Packet) bool {
+	for _, part := range parts {
+		substring := strings.ToLower(part.Data.String())
+
+		switch part.Tag {
+		case ldap.FilterSubstringsInitial:
+			if !strings.HasPrefix(value, substring) {
+				return false
+			}
+			value = value[len(substring):]
+		case ldap.FilterSubstringsAny:
+			index := strings.Index(value, substring)
+			if index < 0 {
+				return false
+			}
+			value = value[index+len(substring):]
+		case ldap.FilterSubstringsFinal:
+			if !strings.HasSuffix(value, substring) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func response(messageId int64, op *ber.Packet) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageId, "Message ID"))
+	packet.AppendChild(op)
+	return packet
+}
+
+func result(tag ber.Tag, resultCode int) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, resultCode, "Result Code"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return packet
+}
+
+func searchResultEntry(entry *testEntry, attributes []string) *ber.Packet {
+	packet := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	packet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.attributes {
+		if len(attributes) > 0 && !containsFold(attributes, name) {
+			continue
+		}
+
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		list.AppendChild(attribute)
+	}
+	packet.AppendChild(list)
+
+	return packet
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
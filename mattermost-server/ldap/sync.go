@@ -0,0 +1,240 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"net/http"
+
+	"github.com/go-ldap/ldap"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+type syncResult struct {
+	UsersUpdated        int
+	UsersDeactivated    int
+	UsersReactivated    int
+	GroupMembersAdded   int
+	GroupMembersRemoved int
+}
+
+// updateUserAttributes copies the attributes of the AD/LDAP user to the Mattermost user and reports
+// whether any of them changed. Username and email are only ever replaced since they can't be empty.
+func (d *directory) updateUserAttributes(user, ldapUser *model.User) bool {
+	changed := false
+
+	update := func(field *string, value string, attribute string) {
+		if attribute != "" && *field != value {
+			*field = value
+			changed = true
+		}
+	}
+
+	if ldapUser.Username != "" {
+		update(&user.Username, ldapUser.Username, *d.settings.UsernameAttribute)
+	}
+	if ldapUser.Email != "" {
+		update(&user.Email, ldapUser.Email, *d.settings.EmailAttribute)
+	}
+	update(&user.FirstName, ldapUser.FirstName, *d.settings.FirstNameAttribute)
+	update(&user.LastName, ldapUser.LastName, *d.settings.LastNameAttribute)
+	update(&user.Nickname, ldapUser.Nickname, *d.settings.NicknameAttribute)
+	update(&user.Position, ldapUser.Position, *d.settings.PositionAttribute)
+
+	return changed
+}
+
+// updateUser saves the attributes of the AD/LDAP user to the Mattermost user if they changed, and
+// reports whether they did. A new username or email that is already taken by someone else is
+// ignored.
+func (l *LdapInterfaceImpl) updateUser(user, ldapUser *model.User) (bool, *model.AppError) {
+	updatedUser := user.DeepCopy()
+	if !l.directory().updateUserAttributes(updatedUser, ldapUser) {
+		return false, nil
+	}
+
+	if updatedUser.Username != user.Username {
+		if existingUser, _ := l.App.GetUserByUsername(updatedUser.Username); existingUser != nil && existingUser.Id != user.Id {
+			mlog.Warn("Not updating the username of an AD/LDAP user since it is already taken", mlog.String("user_id", user.Id), mlog.String("username", updatedUser.Username))
+			updatedUser.Username = user.Username
+		}
+	}
+
+	if updatedUser.Email != user.Email {
+		if existingUser, _ := l.App.GetUserByEmail(updatedUser.Email); existingUser != nil && existingUser.Id != user.Id {
+			mlog.Warn("Not updating the email of an AD/LDAP user since it is already taken", mlog.String("user_id", user.Id))
+			updatedUser.Email = user.Email
+		}
+	}
+
+	if result := <-l.App.Srv.Store.User().Update(updatedUser, true); result.Err != nil {
+		return false, result.Err
+	}
+
+	l.App.InvalidateCacheForUser(user.Id)
+
+	return true, nil
+}
+
+// synchronize brings the AD/LDAP users and the members of the linked AD/LDAP groups in line with the
+// AD/LDAP server, then updates the members of the teams and channels synced with those groups. since
+// is the time of the last successful synchronization.
+func (l *LdapInterfaceImpl) synchronize(since int64) (*syncResult, *model.AppError) {
+	d := l.directory()
+	syncResult := &syncResult{}
+
+	conn, appErr := d.connect()
+	if appErr != nil {
+		return nil, appErr
+	}
+	defer conn.Close()
+
+	entries, appErr := d.getAllUsers(conn)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	ldapUsers := make(map[string]*model.User, len(entries))
+	dnsByAuthData := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		ldapUser := d.userFromEntry(entry)
+		ldapUsers[*ldapUser.AuthData] = ldapUser
+		dnsByAuthData[*ldapUser.AuthData] = normalizeDN(entry.DN)
+	}
+
+	result := <-l.App.Srv.Store.User().GetAllUsingAuthService(model.USER_AUTH_SERVICE_LDAP)
+	if result.Err != nil {
+		return nil, model.NewAppError("synchronize", "ent.ldap.syncronize.get_all.app_error", nil, result.Err.Error(), http.StatusInternalServerError)
+	}
+	users := result.Data.([]*model.User)
+
+	if len(entries) == 0 && len(users) > 0 {
+		// This is much more likely to come from a misconfiguration than from every user having left.
+		mlog.Warn("No users were found on the AD/LDAP server, not deactivating any user")
+	}
+
+	userIdsByDN := make(map[string]string, len(users))
+	for _, user := range users {
+		if user.AuthData == nil {
+			continue
+		}
+
+		ldapUser, ok := ldapUsers[*user.AuthData]
+		if !ok {
+			if user.DeleteAt == 0 && len(entries) > 0 {
+				if _, appErr := l.App.UpdateActive(user, false); appErr != nil {
+					mlog.Error("Failed to deactivate a user removed from AD/LDAP", mlog.String("user_id", user.Id), mlog.Err(appErr))
+					continue
+				}
+				syncResult.UsersDeactivated++
+			}
+			continue
+		}
+
+		userIdsByDN[dnsByAuthData[*user.AuthData]] = user.Id
+
+		if user.DeleteAt != 0 {
+			if _, appErr := l.App.UpdateActive(user.DeepCopy(), true); appErr != nil {
+				mlog.Error("Failed to reactivate a user present in AD/LDAP", mlog.String("user_id", user.Id), mlog.Err(appErr))
+				continue
+			}
+			user.DeleteAt = 0
+			syncResult.UsersReactivated++
+		}
+
+		changed, appErr := l.updateUser(user, ldapUser)
+		if appErr != nil {
+			mlog.Error("Failed to update an AD/LDAP user", mlog.String("user_id", user.Id), mlog.Err(appErr))
+			continue
+		}
+		if changed {
+			syncResult.UsersUpdated++
+		}
+	}
+
+	if appErr := l.synchronizeGroups(d, conn, userIdsByDN, syncResult); appErr != nil {
+		return nil, appErr
+	}
+
+	if err := l.App.CreateDefaultMemberships(since); err != nil {
+		return nil, model.NewAppError("synchronize", "ent.ldap.syncronize.populate_syncables", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if err := l.App.DeleteGroupConstrainedMemberships(); err != nil {
+		return nil, model.NewAppError("synchronize", "ent.ldap.syncronize.delete_group_constained_memberships", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return syncResult, nil
+}
+
+// synchronizeGroups updates the display name and members of the linked AD/LDAP groups. Groups that
+// no longer exist on the server are left without members.
+func (l *LdapInterfaceImpl) synchronizeGroups(d *directory, conn *ldap.Conn, userIdsByDN map[string]string, syncResult *syncResult) *model.AppError {
+	groups, appErr := l.App.GetGroupsBySource(model.GroupSourceLdap)
+	if appErr != nil {
+		return model.NewAppError("synchronizeGroups", "ent.ldap.syncronize.get_all_groups.app_error", nil, appErr.Error(), http.StatusInternalServerError)
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	entries, appErr := d.searchGroups(conn, "")
+	if appErr != nil {
+		return appErr
+	}
+
+	memberships := getGroupMemberships(d, entries)
+	displayNames := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		group := d.groupFromEntry(entry)
+		displayNames[group.RemoteId] = group.DisplayName
+	}
+
+	for _, group := range groups {
+		if displayName, ok := displayNames[group.RemoteId]; ok && displayName != "" && displayName != group.DisplayName {
+			group.DisplayName = displayName
+			if _, appErr := l.App.UpdateGroup(group); appErr != nil {
+				return appErr
+			}
+		}
+
+		wanted := map[string]bool{}
+		for dn := range memberships[group.RemoteId] {
+			if userId, ok := userIdsByDN[dn]; ok {
+				wanted[userId] = true
+			}
+		}
+
+		members, appErr := l.App.GetGroupMemberUsers(group.Id)
+		if appErr != nil {
+			return appErr
+		}
+
+		for _, member := range members {
+			if wanted[member.Id] {
+				delete(wanted, member.Id)
+				continue
+			}
+
+			if _, appErr := l.App.DeleteGroupMember(group.Id, member.Id); appErr != nil {
+				return appErr
+			}
+			syncResult.GroupMembersRemoved++
+		}
+
+		for userId := range wanted {
+			if _, appErr := l.App.CreateOrRestoreGroupMember(group.Id, userId); appErr != nil {
+				if appErr.Id == "store.sql_group.uniqueness_error" {
+					continue
+				}
+				return appErr
+			}
+			syncResult.GroupMembersAdded++
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package ldap
+
+import (
+	"strconv"
+
+	"github.com/mattermost/mattermost-server/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	JOB_DATA_KEY_USERS_UPDATED         = "users_updated"
+	JOB_DATA_KEY_USERS_DEACTIVATED     = "users_deactivated"
+	JOB_DATA_KEY_USERS_REACTIVATED     = "users_reactivated"
+	JOB_DATA_KEY_GROUP_MEMBERS_ADDED   = "group_members_added"
+	JOB_DATA_KEY_GROUP_MEMBERS_REMOVED = "group_members_removed"
+)
+
+type Worker struct {
+	name      string
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *jobs.JobServer
+	ldap      *LdapInterfaceImpl
+}
+
+func (l *LdapSyncInterfaceImpl) MakeWorker() model.Worker {
+	worker := Worker{
+		name:      "LdapSync",
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job),
+		jobServer: l.App.Srv.Jobs,
+		ldap:      &LdapInterfaceImpl{l.App},
+	}
+
+	return &worker
+}
+
+func (worker *Worker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.name))
+
+	defer func() {
+		mlog.Debug("Worker finished", mlog.String("worker", worker.name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *Worker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *Worker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *Worker) DoJob(job *model.Job) {
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	// Team and channel memberships only need to be created for group members and syncables that
+	// changed since the last successful synchronization.
+	var since int64
+	if result := <-worker.ldap.App.Srv.Store.Job().GetNewestJobByStatusAndType(model.JOB_STATUS_SUCCESS, model.JOB_TYPE_LDAP_SYNC); result.Err == nil {
+		since = result.Data.(*model.Job).StartAt
+	}
+
+	syncResult, err := worker.ldap.synchronize(since)
+	if err != nil {
+		mlog.Error("Worker: Failed to synchronize with AD/LDAP", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+		return
+	}
+
+	if job.Data == nil {
+		job.Data = make(map[string]string)
+	}
+	job.Data[JOB_DATA_KEY_USERS_UPDATED] = strconv.Itoa(syncResult.UsersUpdated)
+	job.Data[JOB_DATA_KEY_USERS_DEACTIVATED] = strconv.Itoa(syncResult.UsersDeactivated)
+	job.Data[JOB_DATA_KEY_USERS_REACTIVATED] = strconv.Itoa(syncResult.UsersReactivated)
+	job.Data[JOB_DATA_KEY_GROUP_MEMBERS_ADDED] = strconv.Itoa(syncResult.GroupMembersAdded)
+	job.Data[JOB_DATA_KEY_GROUP_MEMBERS_REMOVED] = strconv.Itoa(syncResult.GroupMembersRemoved)
+
+	mlog.Info("Worker: Job is complete",
+		mlog.String("worker", worker.name),
+		mlog.String("job_id", job.Id),
+		mlog.Int("users_updated", syncResult.UsersUpdated),
+		mlog.Int("users_deactivated", syncResult.UsersDeactivated),
+		mlog.Int("users_reactivated", syncResult.UsersReactivated))
+	worker.setJobSuccess(job)
+}
+
+func (worker *Worker) setJobSuccess(job *model.Job) {
+	if err := worker.jobServer.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *Worker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.jobServer.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
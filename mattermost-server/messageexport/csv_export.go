@@ -0,0 +1,166 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package messageexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	CSV_ROW_TYPE_MESSAGE           = "message"
+	CSV_ROW_TYPE_ATTACHMENT        = "attachment"
+	CSV_ROW_TYPE_PREVIOUSLY_JOINED = "previously-joined"
+	CSV_ROW_TYPE_ENTER             = "enter"
+	CSV_ROW_TYPE_LEAVE             = "leave"
+)
+
+var csvHeader = []string{
+	"Post Creation Time",
+	"Team Id",
+	"Team Name",
+	"Team Display Name",
+	"Channel Id",
+	"Channel Name",
+	"Channel Display Name",
+	"Channel Type",
+	"User Id",
+	"User Email",
+	"Username",
+	"Post Id",
+	"Edited By Post Id",
+	"Replied to Post Id",
+	"Post Message",
+	"Post Type",
+	"Row Type",
+}
+
+type csvRow struct {
+	timestamp int64
+	fields    []string
+}
+
+// writeCsv writes the posts, their attachments and the channel membership changes that happened between start and
+// end to a CSV file. Attachments are copied next to it, under a directory named after their FileInfo.
+func (e *exporter) writeCsv(baseName string, posts []*model.MessageExport, members map[string][]*model.ChannelMemberHistoryResult, start, end int64) *model.AppError {
+	var rows []csvRow
+
+	// Membership rows come first so that, once sorted, a user is seen joining a channel before posting in it.
+	channels := make(map[string]*model.MessageExport)
+	for _, post := range posts {
+		if _, ok := channels[derefString(post.ChannelId)]; !ok {
+			channels[derefString(post.ChannelId)] = post
+		}
+	}
+
+	for channelId, history := range members {
+		for _, member := range history {
+			rows = append(rows, memberCsvRows(channels[channelId], member, start, end)...)
+		}
+	}
+
+	for _, post := range posts {
+		rows = append(rows, csvRow{*post.PostCreateAt, postCsvFields(post, derefString(post.PostMessage), CSV_ROW_TYPE_MESSAGE)})
+
+		for _, info := range e.getAttachments(post) {
+			attachmentPath := path.Join(baseName+"-files", info.Id, info.Name)
+			if err := e.backend.CopyFile(info.Path, attachmentPath); err != nil {
+				mlog.Warn("Failed to export attachment", mlog.String("file_id", info.Id), mlog.String("error", err.Error()))
+				continue
+			}
+
+			rows = append(rows, csvRow{*post.PostCreateAt, postCsvFields(post, attachmentPath, CSV_ROW_TYPE_ATTACHMENT)})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].timestamp < rows[j].timestamp
+	})
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(csvHeader)
+	for _, row := range rows {
+		w.Write(row.fields)
+	}
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return model.NewAppError("MessageExport.writeCsv", "message_export.csv.write.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, err := e.backend.WriteFile(&buf, baseName+".csv"); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func postCsvFields(post *model.MessageExport, message string, rowType string) []string {
+	return []string{
+		strconv.FormatInt(*post.PostCreateAt, 10),
+		derefString(post.TeamId),
+		derefString(post.TeamName),
+		derefString(post.TeamDisplayName),
+		derefString(post.ChannelId),
+		derefString(post.ChannelName),
+		derefString(post.ChannelDisplayName),
+		derefString(post.ChannelType),
+		derefString(post.UserId),
+		derefString(post.UserEmail),
+		derefString(post.Username),
+		derefString(post.PostId),
+		derefString(post.PostOriginalId),
+		derefString(post.PostRootId),
+		message,
+		derefString(post.PostType),
+		rowType,
+	}
+}
+
+// memberCsvRows describes how a user's membership of a channel changed between start and end. Users that
+// joined before start are reported as having previously joined the channel.
+func memberCsvRows(channel *model.MessageExport, member *model.ChannelMemberHistoryResult, start, end int64) []csvRow {
+	fields := func(timestamp int64, rowType string) []string {
+		return []string{
+			strconv.FormatInt(timestamp, 10),
+			derefString(channel.TeamId),
+			derefString(channel.TeamName),
+			derefString(channel.TeamDisplayName),
+			member.ChannelId,
+			derefString(channel.ChannelName),
+			derefString(channel.ChannelDisplayName),
+			derefString(channel.ChannelType),
+			member.UserId,
+			member.UserEmail,
+			member.Username,
+			"",
+			"",
+			"",
+			"",
+			"",
+			rowType,
+		}
+	}
+
+	var rows []csvRow
+	if member.JoinTime < start {
+		rows = append(rows, csvRow{start, fields(start, CSV_ROW_TYPE_PREVIOUSLY_JOINED)})
+	} else if member.JoinTime <= end {
+		rows = append(rows, csvRow{member.JoinTime, fields(member.JoinTime, CSV_ROW_TYPE_ENTER)})
+	}
+
+	if member.LeaveTime != nil && *member.LeaveTime >= start && *member.LeaveTime <= end {
+		rows = append(rows, csvRow{*member.LeaveTime, fields(*member.LeaveTime, CSV_ROW_TYPE_LEAVE)})
+	}
+
+	return rows
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package messageexport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	EML_MESSAGE_ID_DOMAIN = "mattermost"
+	EML_LINE_LENGTH       = 76
+)
+
+// writeMbox writes every post as an RFC 5322 message, with its attachments as MIME parts, to a single mbox file.
+// Lines of a message that look like an mbox separator are quoted following the mboxrd convention. The file is
+// spooled to disk before being written to the file backend so that attachments are never held in memory.
+func (e *exporter) writeMbox(baseName string, posts []*model.MessageExport, members map[string][]*model.ChannelMemberHistoryResult) *model.AppError {
+	file, err := ioutil.TempFile("", "message-export")
+	if err != nil {
+		return model.NewAppError("MessageExport.writeMbox", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	out := bufio.NewWriter(file)
+	for _, post := range posts {
+		sender := derefString(post.UserEmail)
+		if sender == "" {
+			sender = "MAILER-DAEMON"
+		}
+
+		fmt.Fprintf(out, "From %s %s\n", sender, postTime(post).Format(time.ANSIC))
+		message := &mboxrdWriter{w: out}
+		if appErr := e.writeEml(message, post, members[derefString(post.ChannelId)]); appErr != nil {
+			return appErr
+		}
+		if err := message.Close(); err != nil {
+			return model.NewAppError("MessageExport.writeMbox", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		out.WriteString("\n")
+	}
+
+	if err := out.Flush(); err != nil {
+		return model.NewAppError("MessageExport.writeMbox", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return model.NewAppError("MessageExport.writeMbox", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, appErr := e.backend.WriteFile(file, baseName+".mbox"); appErr != nil {
+		return appErr
+	}
+
+	return nil
+}
+
+// writeEml renders a post as an RFC 5322 message addressed to everybody who was a member of its channel.
+func (e *exporter) writeEml(out io.Writer, post *model.MessageExport, members []*model.ChannelMemberHistoryResult) *model.AppError {
+	w := multipart.NewWriter(out)
+
+	from := mail.Address{Name: derefString(post.Username), Address: derefString(post.UserEmail)}
+
+	var recipients []string
+	for _, member := range members {
+		recipients = append(recipients, (&mail.Address{Name: member.Username, Address: member.UserEmail}).String())
+	}
+	sort.Strings(recipients)
+	to := "undisclosed-recipients:;"
+	if len(recipients) > 0 {
+		to = strings.Join(recipients, ", ")
+	}
+
+	subject := derefString(post.ChannelDisplayName)
+	if teamName := derefString(post.TeamDisplayName); teamName != "" {
+		subject = teamName + " / " + subject
+	}
+
+	headers := [][2]string{
+		{"From", from.String()},
+		{"To", to},
+		{"Date", postTime(post).Format(time.RFC1123Z)},
+		{"Subject", mime.QEncoding.Encode("UTF-8", subject)},
+		{"Message-ID", emlMessageId(derefString(post.PostId))},
+	}
+	if rootId := derefString(post.PostRootId); rootId != "" {
+		headers = append(headers, [2]string{"In-Reply-To", emlMessageId(rootId)}, [2]string{"References", emlMessageId(rootId)})
+	}
+	headers = append(headers,
+		[2]string{"X-Mattermost-Team-Id", derefString(post.TeamId)},
+		[2]string{"X-Mattermost-Channel-Id", derefString(post.ChannelId)},
+		[2]string{"X-Mattermost-Channel-Name", derefString(post.ChannelName)},
+		[2]string{"X-Mattermost-Channel-Type", derefString(post.ChannelType)},
+		[2]string{"X-Mattermost-User-Id", derefString(post.UserId)},
+		[2]string{"X-Mattermost-Post-Id", derefString(post.PostId)},
+	)
+	if originalId := derefString(post.PostOriginalId); originalId != "" {
+		headers = append(headers, [2]string{"X-Mattermost-Original-Post-Id", originalId})
+	}
+	headers = append(headers,
+		[2]string{"MIME-Version", "1.0"},
+		[2]string{"Content-Type", mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": w.Boundary()})},
+	)
+
+	for _, header := range headers {
+		fmt.Fprintf(out, "%s: %s\r\n", header[0], header[1])
+	}
+	io.WriteString(out, "\r\n")
+
+	textHeader := textproto.MIMEHeader{}
+	textHeader.Set("Content-Type", "text/plain; charset=UTF-8")
+	textHeader.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, err := w.CreatePart(textHeader)
+	if err != nil {
+		return model.NewAppError("MessageExport.writeEml", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	qp := quotedprintable.NewWriter(part)
+	qp.Write([]byte(derefString(post.PostMessage)))
+	qp.Close()
+
+	for _, info := range e.getAttachments(post) {
+		if err := e.writeEmlAttachment(w, info); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return model.NewAppError("MessageExport.writeEml", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// writeEmlAttachment streams an attachment from the file backend into a base64 encoded MIME part. Files that
+// can't be opened are logged and skipped.
+func (e *exporter) writeEmlAttachment(w *multipart.Writer, info *model.FileInfo) *model.AppError {
+	reader, appErr := e.backend.Reader(info.Path)
+	if appErr != nil {
+		mlog.Warn("Failed to export attachment", mlog.String("file_id", info.Id), mlog.String("error", appErr.Error()))
+		return nil
+	}
+	defer reader.Close()
+
+	attachmentHeader := textproto.MIMEHeader{}
+	attachmentHeader.Set("Content-Type", mime.FormatMediaType(attachmentMimeType(info), map[string]string{"name": info.Name}))
+	attachmentHeader.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": info.Name}))
+	attachmentHeader.Set("Content-Transfer-Encoding", "base64")
+	attachmentHeader.Set("X-Mattermost-File-Id", info.Id)
+	part, err := w.CreatePart(attachmentHeader)
+	if err != nil {
+		return model.NewAppError("MessageExport.writeEmlAttachment", "message_export.eml.build.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	lines := &lineWrapper{w: part, width: EML_LINE_LENGTH}
+	encoder := base64.NewEncoder(base64.StdEncoding, lines)
+	if _, err := io.Copy(encoder, reader); err != nil {
+		return model.NewAppError("MessageExport.writeEmlAttachment", "message_export.eml.build.app_error", nil, "file_id="+info.Id+", "+err.Error(), http.StatusInternalServerError)
+	}
+	if err := encoder.Close(); err != nil {
+		return model.NewAppError("MessageExport.writeEmlAttachment", "message_export.eml.build.app_error", nil, "file_id="+info.Id+", "+err.Error(), http.StatusInternalServerError)
+	}
+	if err := lines.Close(); err != nil {
+		return model.NewAppError("MessageExport.writeEmlAttachment", "message_export.eml.build.app_error", nil, "file_id="+info.Id+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// lineWrapper breaks what is written to it into CRLF terminated lines of at most width bytes.
+type lineWrapper struct {
+	w      io.Writer
+	width  int
+	column int
+}
+
+func (l *lineWrapper) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := l.width - l.column
+		if n > len(p) {
+			n = len(p)
+		}
+
+		if _, err := l.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		l.column += n
+		p = p[n:]
+
+		if l.column == l.width {
+			if _, err := io.WriteString(l.w, "\r\n"); err != nil {
+				return written, err
+			}
+			l.column = 0
+		}
+	}
+
+	return written, nil
+}
+
+// Close terminates the last line.
+func (l *lineWrapper) Close() error {
+	if l.column == 0 {
+		return nil
+	}
+	l.column = 0
+	_, err := io.WriteString(l.w, "\r\n")
+	return err
+}
+
+// mboxrdWriter converts the CRLF line endings of a message to LF, and quotes the lines that look like an mbox
+// separator, as the message is written.
+type mboxrdWriter struct {
+	w    io.Writer
+	line []byte
+	err  error
+}
+
+func (m *mboxrdWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if c == '\n' {
+			m.writeLine()
+		} else {
+			m.line = append(m.line, c)
+		}
+	}
+
+	if m.err != nil {
+		return 0, m.err
+	}
+	return len(p), nil
+}
+
+func (m *mboxrdWriter) writeLine() {
+	line := bytes.TrimSuffix(m.line, []byte("\r"))
+	if bytes.HasPrefix(bytes.TrimLeft(line, ">"), []byte("From ")) {
+		m.write([]byte(">"))
+	}
+	m.write(line)
+	m.write([]byte("\n"))
+	m.line = m.line[:0]
+}
+
+func (m *mboxrdWriter) write(b []byte) {
+	if m.err == nil {
+		_, m.err = m.w.Write(b)
+	}
+}
+
+// Close writes the last line of the message if it isn't terminated.
+func (m *mboxrdWriter) Close() error {
+	if len(m.line) > 0 {
+		m.writeLine()
+	}
+	return m.err
+}
+
+func attachmentMimeType(info *model.FileInfo) string {
+	if info.MimeType != "" {
+		return info.MimeType
+	}
+	return "application/octet-stream"
+}
+
+func emlMessageId(postId string) string {
+	return "<" + postId + "@" + EML_MESSAGE_ID_DOMAIN + ">"
+}
+
+func postTime(post *model.MessageExport) time.Time {
+	return time.Unix(0, *post.PostCreateAt*int64(time.Millisecond)).UTC()
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package messageexport
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/services/filesstore"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+// exporter writes batches of posts, in the order in which they were created, to files in a directory of the file
+// backend. Every batch is written to its own file, named after the range of post timestamps that it covers and
+// its first post.
+type exporter struct {
+	store     store.Store
+	backend   filesstore.FileBackend
+	format    string
+	directory string
+	batchSize int
+}
+
+// exportCursor is the position of the last exported post. Posts are exported in the order in which they were
+// created, and then of their ids since several posts can share the same creation time.
+type exportCursor struct {
+	createAt int64
+	postId   string
+}
+
+// exportBatch exports up to batchSize posts that come after cursor. It returns the position of the last exported
+// post, which is the cursor for the next batch, along with the number of posts that were exported.
+func (e *exporter) exportBatch(cursor exportCursor) (exportCursor, int, *model.AppError) {
+	if e.format != model.COMPLIANCE_EXPORT_TYPE_CSV && e.format != model.COMPLIANCE_EXPORT_TYPE_EML {
+		return cursor, 0, model.NewAppError("MessageExport.exportBatch", "message_export.unsupported_format.app_error", map[string]interface{}{"Format": e.format}, "", http.StatusNotImplemented)
+	}
+
+	posts, err := e.store.Compliance().MessageExport(cursor.createAt, cursor.postId, e.batchSize)
+	if err != nil {
+		return cursor, 0, err
+	}
+
+	if len(posts) == 0 {
+		return cursor, 0, nil
+	}
+
+	first := posts[0]
+	last := posts[len(posts)-1]
+	start := *first.PostCreateAt
+	end := *last.PostCreateAt
+
+	members, err := e.getChannelMembers(posts, start, end)
+	if err != nil {
+		return cursor, 0, err
+	}
+
+	// Consecutive batches can cover the same timestamps, so the file is also named after its first post.
+	baseName := path.Join(e.directory, fmt.Sprintf("messages-%d-%d-%s", start, end, derefString(first.PostId)))
+	switch e.format {
+	case model.COMPLIANCE_EXPORT_TYPE_CSV:
+		err = e.writeCsv(baseName, posts, members, start, end)
+	case model.COMPLIANCE_EXPORT_TYPE_EML:
+		err = e.writeMbox(baseName, posts, members)
+	}
+	if err != nil {
+		return cursor, 0, err
+	}
+
+	return exportCursor{createAt: end, postId: derefString(last.PostId)}, len(posts), nil
+}
+
+// getChannelMembers returns, for every channel that the given posts were made in, the users that were members of
+// the channel at some point between start and end.
+func (e *exporter) getChannelMembers(posts []*model.MessageExport, start, end int64) (map[string][]*model.ChannelMemberHistoryResult, *model.AppError) {
+	members := make(map[string][]*model.ChannelMemberHistoryResult)
+
+	for _, post := range posts {
+		channelId := derefString(post.ChannelId)
+		if _, ok := members[channelId]; ok || channelId == "" {
+			continue
+		}
+
+		result := <-e.store.ChannelMemberHistory().GetUsersInChannelDuring(start, end, channelId)
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		members[channelId] = result.Data.([]*model.ChannelMemberHistoryResult)
+	}
+
+	return members, nil
+}
+
+// getAttachments returns the FileInfos of the files attached to the given post. Files that can't be found are
+// logged and skipped so that a single missing file doesn't prevent the remaining messages from being exported.
+func (e *exporter) getAttachments(post *model.MessageExport) []*model.FileInfo {
+	if len(post.PostFileIds) == 0 {
+		return nil
+	}
+
+	infos, err := e.store.FileInfo().GetForPost(derefString(post.PostId), false, true)
+	if err != nil {
+		mlog.Warn("Failed to get attachments for exported post", mlog.String("post_id", derefString(post.PostId)), mlog.String("error", err.Error()))
+		return nil
+	}
+
+	return infos
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
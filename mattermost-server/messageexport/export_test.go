@@ -0,0 +1,184 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package messageexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"net/mail"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/services/filesstore"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/mattermost/mattermost-server/store/storetest"
+)
+
+type exportTestData struct {
+	store      *storetest.Store
+	backend    filesstore.FileBackend
+	posts      []*model.MessageExport
+	attachment *model.FileInfo
+}
+
+func setupExportTest(t *testing.T) (*exportTestData, func()) {
+	dir, err := ioutil.TempDir("", "messageexport")
+	require.Nil(t, err)
+
+	backend, appErr := filesstore.NewFileBackend(&model.FileSettings{
+		DriverName: model.NewString(model.IMAGE_DRIVER_LOCAL),
+		Directory:  model.NewString(dir),
+	}, false)
+	require.Nil(t, appErr)
+
+	teamId := model.NewId()
+	channelId := model.NewId()
+	rootId := model.NewId()
+
+	newPost := func(createAt int64, userId, username, message, parentId string, fileIds model.StringArray) *model.MessageExport {
+		return &model.MessageExport{
+			TeamId:             model.NewString(teamId),
+			TeamName:           model.NewString("team"),
+			TeamDisplayName:    model.NewString("Team"),
+			ChannelId:          model.NewString(channelId),
+			ChannelName:        model.NewString("town-square"),
+			ChannelDisplayName: model.NewString("Town Square"),
+			ChannelType:        model.NewString(model.CHANNEL_OPEN),
+			UserId:             model.NewString(userId),
+			UserEmail:          model.NewString(username + "@example.com"),
+			Username:           model.NewString(username),
+			PostId:             model.NewString(model.NewId()),
+			PostCreateAt:       model.NewInt64(createAt),
+			PostMessage:        model.NewString(message),
+			PostType:           model.NewString(""),
+			PostRootId:         model.NewString(parentId),
+			PostOriginalId:     model.NewString(""),
+			PostFileIds:        fileIds,
+		}
+	}
+
+	aliceId := model.NewId()
+	bobId := model.NewId()
+
+	attachment := &model.FileInfo{Id: model.NewId(), Name: "notes.txt", Path: "data/notes.txt", MimeType: "text/plain"}
+	_, appErr = backend.WriteFile(strings.NewReader("attached notes"), attachment.Path)
+	require.Nil(t, appErr)
+
+	root := newPost(1000, aliceId, "alice", "hello world", "", nil)
+	root.PostId = model.NewString(rootId)
+	posts := []*model.MessageExport{
+		root,
+		newPost(2000, bobId, "bob", "From the top", rootId, model.StringArray{attachment.Id}),
+	}
+
+	mockStore := &storetest.Store{}
+	mockStore.ComplianceStore.On("MessageExport", int64(0), "", 10).Return(posts, nil)
+	mockStore.ChannelMemberHistoryStore.On("GetUsersInChannelDuring", int64(1000), int64(2000), channelId).Return(storetest.NewStoreChannel(store.StoreResult{
+		Data: []*model.ChannelMemberHistoryResult{
+			{ChannelId: channelId, UserId: aliceId, UserEmail: "alice@example.com", Username: "alice", JoinTime: 500},
+			{ChannelId: channelId, UserId: bobId, UserEmail: "bob@example.com", Username: "bob", JoinTime: 1500, LeaveTime: model.NewInt64(2500)},
+		},
+	}))
+	mockStore.FileInfoStore.On("GetForPost", *posts[1].PostId, false, true).Return([]*model.FileInfo{attachment}, nil)
+
+	return &exportTestData{
+		store:      mockStore,
+		backend:    backend,
+		posts:      posts,
+		attachment: attachment,
+	}, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestExportBatchCsv(t *testing.T) {
+	data, teardown := setupExportTest(t)
+	defer teardown()
+
+	e := &exporter{store: data.store, backend: data.backend, format: model.COMPLIANCE_EXPORT_TYPE_CSV, directory: "export/job", batchSize: 10}
+
+	next, exported, err := e.exportBatch(exportCursor{})
+	require.Nil(t, err)
+	assert.Equal(t, exportCursor{createAt: 2000, postId: *data.posts[1].PostId}, next)
+	assert.Equal(t, 2, exported)
+
+	baseName := "export/job/messages-1000-2000-" + *data.posts[0].PostId
+	contents, err := data.backend.ReadFile(baseName + ".csv")
+	require.Nil(t, err)
+
+	rows, csvErr := csv.NewReader(bytes.NewReader(contents)).ReadAll()
+	require.Nil(t, csvErr)
+	require.Len(t, rows, 6)
+	assert.Equal(t, csvHeader, rows[0])
+
+	rowTypes := make([]string, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		rowTypes = append(rowTypes, row[len(row)-1])
+	}
+	assert.Equal(t, []string{CSV_ROW_TYPE_PREVIOUSLY_JOINED, CSV_ROW_TYPE_MESSAGE, CSV_ROW_TYPE_ENTER, CSV_ROW_TYPE_MESSAGE, CSV_ROW_TYPE_ATTACHMENT}, rowTypes)
+	assert.Equal(t, "hello world", rows[2][14])
+	assert.Equal(t, "bob", rows[3][10])
+
+	attachmentPath := rows[5][14]
+	assert.Equal(t, baseName+"-files/"+data.attachment.Id+"/notes.txt", attachmentPath)
+	attached, err := data.backend.ReadFile(attachmentPath)
+	require.Nil(t, err)
+	assert.Equal(t, "attached notes", string(attached))
+}
+
+func TestExportBatchEml(t *testing.T) {
+	data, teardown := setupExportTest(t)
+	defer teardown()
+
+	e := &exporter{store: data.store, backend: data.backend, format: model.COMPLIANCE_EXPORT_TYPE_EML, directory: "export/job", batchSize: 10}
+
+	_, exported, err := e.exportBatch(exportCursor{})
+	require.Nil(t, err)
+	assert.Equal(t, 2, exported)
+
+	contents, err := data.backend.ReadFile("export/job/messages-1000-2000-" + *data.posts[0].PostId + ".mbox")
+	require.Nil(t, err)
+
+	messages := strings.Split(string(contents), "\nFrom ")
+	require.Len(t, messages, 2)
+	assert.True(t, strings.HasPrefix(messages[0], "From alice@example.com "))
+	assert.Contains(t, messages[1], "\n>From the top\n", "lines that look like mbox separators should be quoted")
+
+	first := messages[0][strings.Index(messages[0], "\n")+1:]
+	msg, readErr := mail.ReadMessage(strings.NewReader(first))
+	require.Nil(t, readErr)
+	assert.Equal(t, `"alice" <alice@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, `"alice" <alice@example.com>, "bob" <bob@example.com>`, msg.Header.Get("To"))
+	assert.Equal(t, "Team / Town Square", msg.Header.Get("Subject"))
+	assert.Equal(t, "<"+*data.posts[0].PostId+"@mattermost>", msg.Header.Get("Message-ID"))
+
+	assert.Contains(t, messages[1], "In-Reply-To: <"+*data.posts[0].PostId+"@mattermost>")
+	assert.Contains(t, messages[1], `Content-Disposition: attachment; filename=notes.txt`)
+	assert.Contains(t, messages[1], "YXR0YWNoZWQgbm90ZXM=")
+}
+
+func TestExportBatchUnsupportedFormat(t *testing.T) {
+	e := &exporter{store: &storetest.Store{}, format: model.COMPLIANCE_EXPORT_TYPE_ACTIANCE, batchSize: 10}
+
+	next, exported, err := e.exportBatch(exportCursor{createAt: 42})
+	require.NotNil(t, err)
+	assert.Equal(t, exportCursor{createAt: 42}, next)
+	assert.Equal(t, 0, exported)
+}
+
+func TestLineWrapper(t *testing.T) {
+	var buf bytes.Buffer
+	w := &lineWrapper{w: &buf, width: 4}
+
+	w.Write([]byte("abc"))
+	w.Write([]byte("defghij"))
+	require.Nil(t, w.Close())
+	assert.Equal(t, "abcd\r\nefgh\r\nij\r\n", buf.String())
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package messageexport
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	ejobs "github.com/mattermost/mattermost-server/einterfaces/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	JOB_DATA_KEY_EXPORT_FROM_TIMESTAMP = "export_from_timestamp"
+	JOB_DATA_KEY_BATCH_START_TIMESTAMP = "batch_start_timestamp"
+	JOB_DATA_KEY_BATCH_END_TIMESTAMP   = "batch_end_timestamp"
+	JOB_DATA_KEY_BATCH_END_POST_ID     = "batch_end_post_id"
+	JOB_DATA_KEY_EXPORT_FORMAT         = "export_format"
+	JOB_DATA_KEY_MESSAGES_EXPORTED     = "messages_exported"
+	JOB_DATA_KEY_EXPORT_DIRECTORY      = "export_directory"
+
+	SYNCHRONIZE_JOB_POLL_INTERVAL = 1 * time.Second
+)
+
+type MessageExportInterfaceImpl struct {
+	App *app.App
+}
+
+type MessageExportJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterMessageExportInterface(func(a *app.App) einterfaces.MessageExportInterface {
+		return &MessageExportInterfaceImpl{a}
+	})
+	app.RegisterJobsMessageExportJobInterface(func(a *app.App) ejobs.MessageExportJobInterface {
+		return &MessageExportJobInterfaceImpl{a}
+	})
+}
+
+// StartSynchronizeJob creates a message export job that exports every post created after exportFromTimestamp,
+// expressed in seconds since the unix epoch, in the given format, runs it and waits for it to finish or for ctx
+// to be done.
+func (m *MessageExportInterfaceImpl) StartSynchronizeJob(ctx context.Context, exportFromTimestamp int64, format string) (*model.Job, *model.AppError) {
+	job, err := m.App.Srv.Jobs.CreateJob(model.JOB_TYPE_MESSAGE_EXPORT, map[string]string{
+		JOB_DATA_KEY_EXPORT_FROM_TIMESTAMP: strconv.FormatInt(exportFromTimestamp*1000, 10),
+		JOB_DATA_KEY_EXPORT_FORMAT:         format,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The job is run right away rather than waiting for the job server to pick it up, since the server may not
+	// be running any workers. Should a running worker claim it first, this simply becomes a no-op.
+	worker := (&MessageExportJobInterfaceImpl{m.App}).MakeWorker().(*Worker)
+	go worker.DoJob(job)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := m.App.Srv.Jobs.RequestCancellation(job.Id); err != nil {
+				mlog.Warn("Failed to cancel message export job", mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+			}
+			return job, model.NewAppError("MessageExport.StartSynchronizeJob", "message_export.synchronize_job.timeout.app_error", nil, ctx.Err().Error(), http.StatusRequestTimeout)
+
+		case <-time.After(SYNCHRONIZE_JOB_POLL_INTERVAL):
+			job, err = m.App.Srv.Jobs.GetJob(job.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			if job.Status == model.JOB_STATUS_SUCCESS || job.Status == model.JOB_STATUS_ERROR || job.Status == model.JOB_STATUS_CANCELED {
+				return job, nil
+			}
+		}
+	}
+}
+
+// RunExport synchronously exports every post created after since, expressed in seconds since the unix epoch, in
+// the given format without going through the job server.
+func (m *MessageExportInterfaceImpl) RunExport(format string, since int64) *model.AppError {
+	backend, err := m.App.FileBackend()
+	if err != nil {
+		return err
+	}
+
+	exportDirectory := path.Join(*m.App.Config().MessageExportSettings.FileLocation, format+"-"+strconv.FormatInt(model.GetMillis(), 10))
+	e := &exporter{
+		store:     m.App.Srv.Store,
+		backend:   backend,
+		format:    format,
+		directory: exportDirectory,
+		batchSize: *m.App.Config().MessageExportSettings.BatchSize,
+	}
+
+	cursor := exportCursor{createAt: since * 1000}
+	total := 0
+	for {
+		next, exported, err := e.exportBatch(cursor)
+		if err != nil {
+			return err
+		}
+
+		cursor = next
+		total += exported
+		if exported == 0 || exported < e.batchSize {
+			break
+		}
+	}
+
+	mlog.Info("Message export complete", mlog.String("format", format), mlog.String("directory", exportDirectory), mlog.Int("messages_exported", total))
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package messageexport
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	TIME_BETWEEN_BATCHES = 100
+)
+
+type Worker struct {
+	name      string
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *jobs.JobServer
+	app       *app.App
+}
+
+func (m *MessageExportJobInterfaceImpl) MakeWorker() model.Worker {
+	worker := Worker{
+		name:      "MessageExport",
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job),
+		jobServer: m.App.Srv.Jobs,
+		app:       m.App,
+	}
+
+	return &worker
+}
+
+func (worker *Worker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.name))
+
+	defer func() {
+		mlog.Debug("Worker finished", mlog.String("worker", worker.name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *Worker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *Worker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *Worker) DoJob(job *model.Job) {
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	cancelCtx, cancelCancelWatcher := context.WithCancel(context.Background())
+	cancelWatcherChan := make(chan interface{}, 1)
+	go worker.app.Srv.Jobs.CancellationWatcher(cancelCtx, job.Id, cancelWatcherChan)
+
+	defer cancelCancelWatcher()
+
+	if job.Data == nil {
+		job.Data = make(map[string]string)
+	}
+
+	cursor, err := worker.getExportCursor(job)
+	if err != nil {
+		mlog.Error("Worker: Failed to determine where to start exporting from", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+		return
+	}
+
+	backend, err := worker.app.FileBackend()
+	if err != nil {
+		worker.setJobError(job, err)
+		return
+	}
+
+	cfg := worker.app.Config()
+	format := job.Data[JOB_DATA_KEY_EXPORT_FORMAT]
+	if format == "" {
+		format = *cfg.MessageExportSettings.ExportFormat
+	}
+
+	e := &exporter{
+		store:     worker.app.Srv.Store,
+		backend:   backend,
+		format:    format,
+		directory: path.Join(*cfg.MessageExportSettings.FileLocation, job.Id),
+		batchSize: *cfg.MessageExportSettings.BatchSize,
+	}
+
+	job.Data[JOB_DATA_KEY_BATCH_START_TIMESTAMP] = strconv.FormatInt(cursor.createAt, 10)
+	job.Data[JOB_DATA_KEY_BATCH_END_TIMESTAMP] = strconv.FormatInt(cursor.createAt, 10)
+	job.Data[JOB_DATA_KEY_BATCH_END_POST_ID] = cursor.postId
+	job.Data[JOB_DATA_KEY_EXPORT_DIRECTORY] = e.directory
+	total := 0
+
+	for {
+		select {
+		case <-cancelWatcherChan:
+			mlog.Debug("Worker: Job has been canceled via CancellationWatcher", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-worker.stop:
+			mlog.Debug("Worker: Job has been canceled via Worker Stop", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+			worker.setJobCanceled(job)
+			return
+
+		case <-time.After(TIME_BETWEEN_BATCHES * time.Millisecond):
+			next, exported, err := e.exportBatch(cursor)
+			if err != nil {
+				mlog.Error("Worker: Failed to export messages", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			cursor = next
+			total += exported
+			job.Data[JOB_DATA_KEY_BATCH_END_TIMESTAMP] = strconv.FormatInt(cursor.createAt, 10)
+			job.Data[JOB_DATA_KEY_BATCH_END_POST_ID] = cursor.postId
+			job.Data[JOB_DATA_KEY_MESSAGES_EXPORTED] = strconv.Itoa(total)
+
+			// The data is saved even for the last batch since the next export picks up where this one stops.
+			if err := worker.app.Srv.Jobs.UpdateInProgressJobData(job); err != nil {
+				mlog.Error("Worker: Failed to update message export status data for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+				worker.setJobError(job, err)
+				return
+			}
+
+			if exported == 0 || exported < e.batchSize {
+				mlog.Info("Worker: Job is complete", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.Int("messages_exported", total))
+				worker.setJobSuccess(job)
+				return
+			}
+		}
+	}
+}
+
+// getExportCursor returns the position after which posts should be exported. A timestamp given when the job was
+// created takes precedence, followed by the point at which the last successful export stopped, and finally the
+// configured ExportFromTimestamp.
+func (worker *Worker) getExportCursor(job *model.Job) (exportCursor, *model.AppError) {
+	if value, ok := job.Data[JOB_DATA_KEY_EXPORT_FROM_TIMESTAMP]; ok {
+		if timestamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return exportCursor{createAt: timestamp}, nil
+		}
+	}
+
+	lastSuccessfulJob, err := worker.jobServer.GetLastSuccessfulJobByType(model.JOB_TYPE_MESSAGE_EXPORT)
+	if err != nil {
+		return exportCursor{}, err
+	}
+
+	if lastSuccessfulJob != nil {
+		if timestamp, err := strconv.ParseInt(lastSuccessfulJob.Data[JOB_DATA_KEY_BATCH_END_TIMESTAMP], 10, 64); err == nil {
+			return exportCursor{createAt: timestamp, postId: lastSuccessfulJob.Data[JOB_DATA_KEY_BATCH_END_POST_ID]}, nil
+		}
+	}
+
+	return exportCursor{createAt: *worker.app.Config().MessageExportSettings.ExportFromTimestamp}, nil
+}
+
+func (worker *Worker) setJobSuccess(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *Worker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.app.Srv.Jobs.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
+
+func (worker *Worker) setJobCanceled(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobCanceled(job); err != nil {
+		mlog.Error("Worker: Failed to mark job as canceled", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
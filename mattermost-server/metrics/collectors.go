@@ -0,0 +1,147 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+// dbStatsProvider is implemented by the SQL supplier backing the store.
+type dbStatsProvider interface {
+	DBStats() map[string]sql.DBStats
+}
+
+// getDBStats returns the connection pool statistics of the store's databases, or nothing if the
+// store isn't backed by SQL.
+func getDBStats(a *app.App) map[string]sql.DBStats {
+	if a.Srv == nil {
+		return nil
+	}
+
+	var s interface{} = a.Srv.Store
+	if layeredStore, ok := s.(*store.LayeredStore); ok {
+		s = layeredStore.DatabaseLayer
+	}
+
+	if provider, ok := s.(dbStatsProvider); ok {
+		return provider.DBStats()
+	}
+
+	return nil
+}
+
+// dbCollector reports the state of the connection pool of the master database and of each replica,
+// labelled with the name of the connection.
+type dbCollector struct {
+	getStats func() map[string]sql.DBStats
+
+	maxOpenConnections *prometheus.Desc
+	openConnections    *prometheus.Desc
+	inUseConnections   *prometheus.Desc
+	idleConnections    *prometheus.Desc
+	waitCount          *prometheus.Desc
+	waitDuration       *prometheus.Desc
+	maxIdleClosed      *prometheus.Desc
+	maxLifetimeClosed  *prometheus.Desc
+}
+
+func newDBCollector(getStats func() map[string]sql.DBStats) *dbCollector {
+	newDesc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(METRICS_NAMESPACE, METRICS_SUBSYSTEM_DB, name), help, []string{"db"}, nil)
+	}
+
+	return &dbCollector{
+		getStats: getStats,
+
+		maxOpenConnections: newDesc("max_open_connections", "The maximum number of open connections to the database."),
+		openConnections:    newDesc("open_connections", "The number of established connections to the database, both in use and idle."),
+		inUseConnections:   newDesc("in_use_connections", "The number of connections to the database currently in use."),
+		idleConnections:    newDesc("idle_connections", "The number of idle connections to the database."),
+		waitCount:          newDesc("wait_count_total", "The total number of times a connection to the database was waited for."),
+		waitDuration:       newDesc("wait_duration_seconds_total", "The total time spent waiting for a connection to the database, in seconds."),
+		maxIdleClosed:      newDesc("max_idle_closed_total", "The total number of connections to the database closed because there were too many idle connections."),
+		maxLifetimeClosed:  newDesc("max_lifetime_closed_total", "The total number of connections to the database closed because they reached their maximum lifetime."),
+	}
+}
+
+func (c *dbCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpenConnections
+	ch <- c.openConnections
+	ch <- c.inUseConnections
+	ch <- c.idleConnections
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *dbCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, stats := range c.getStats() {
+		ch <- prometheus.MustNewConstMetric(c.maxOpenConnections, prometheus.GaugeValue, float64(stats.MaxOpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections), name)
+		ch <- prometheus.MustNewConstMetric(c.inUseConnections, prometheus.GaugeValue, float64(stats.InUse), name)
+		ch <- prometheus.MustNewConstMetric(c.idleConnections, prometheus.GaugeValue, float64(stats.Idle), name)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount), name)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds(), name)
+		ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed), name)
+		ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), name)
+	}
+}
+
+// websocketCollector reports the number of websocket connections and the load on each hub.
+type websocketCollector struct {
+	getHubStats func() []app.HubStats
+
+	connections          *prometheus.Desc
+	hubConnections       *prometheus.Desc
+	hubBroadcastQueueLen *prometheus.Desc
+}
+
+func newWebsocketCollector(getHubStats func() []app.HubStats) *websocketCollector {
+	return &websocketCollector{
+		getHubStats: getHubStats,
+
+		connections: prometheus.NewDesc(
+			prometheus.BuildFQName(METRICS_NAMESPACE, METRICS_SUBSYSTEM_WEBSOCKET, "connections"),
+			"The number of open websocket connections.",
+			nil, nil,
+		),
+		hubConnections: prometheus.NewDesc(
+			prometheus.BuildFQName(METRICS_NAMESPACE, METRICS_SUBSYSTEM_WEBSOCKET, "hub_connections"),
+			"The number of open websocket connections handled by each hub.",
+			[]string{"hub"}, nil,
+		),
+		hubBroadcastQueueLen: prometheus.NewDesc(
+			prometheus.BuildFQName(METRICS_NAMESPACE, METRICS_SUBSYSTEM_WEBSOCKET, "hub_broadcast_queue_length"),
+			"The number of events waiting to be broadcast by each hub.",
+			[]string{"hub"}, nil,
+		),
+	}
+}
+
+func (c *websocketCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connections
+	ch <- c.hubConnections
+	ch <- c.hubBroadcastQueueLen
+}
+
+func (c *websocketCollector) Collect(ch chan<- prometheus.Metric) {
+	var connections int64
+
+	for _, stats := range c.getHubStats() {
+		hub := strconv.Itoa(stats.Index)
+		connections += stats.Connections
+
+		ch <- prometheus.MustNewConstMetric(c.hubConnections, prometheus.GaugeValue, float64(stats.Connections), hub)
+		ch <- prometheus.MustNewConstMetric(c.hubBroadcastQueueLen, prometheus.GaugeValue, float64(stats.BroadcastQueueLength), hub)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.connections, prometheus.GaugeValue, float64(connections))
+}
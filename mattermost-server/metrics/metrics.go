@@ -0,0 +1,371 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/mlog"
+)
+
+const (
+	METRICS_NAMESPACE = "mattermost"
+
+	METRICS_SUBSYSTEM_POSTS     = "post"
+	METRICS_SUBSYSTEM_HTTP      = "http"
+	METRICS_SUBSYSTEM_CLUSTER   = "cluster"
+	METRICS_SUBSYSTEM_LOGIN     = "login"
+	METRICS_SUBSYSTEM_CACHE     = "cache"
+	METRICS_SUBSYSTEM_WEBSOCKET = "websocket"
+	METRICS_SUBSYSTEM_SEARCH    = "search"
+	METRICS_SUBSYSTEM_DB        = "db"
+
+	METRICS_CACHE_NAME_SESSION = "Session"
+
+	METRICS_SERVER_SHUTDOWN_TIMEOUT = 5 * time.Second
+)
+
+// MetricsInterfaceImpl exposes the server's counters, along with Go runtime, database pool and
+// websocket hub statistics, in the Prometheus exposition format.
+type MetricsInterfaceImpl struct {
+	App *app.App
+
+	Registry *prometheus.Registry
+
+	serverMutex   sync.Mutex
+	server        *http.Server
+	serverAddress string
+
+	PostCreate          prometheus.Counter
+	WebhookPost         prometheus.Counter
+	PostSentEmail       prometheus.Counter
+	PostSentPush        prometheus.Counter
+	PostBroadcast       prometheus.Counter
+	PostFileAttachments prometheus.Counter
+
+	HttpRequests        prometheus.Counter
+	HttpErrors          prometheus.Counter
+	HttpRequestDuration prometheus.Histogram
+
+	ClusterRequests        prometheus.Counter
+	ClusterRequestDuration prometheus.Histogram
+	ClusterEventTypes      *prometheus.CounterVec
+
+	Logins     prometheus.Counter
+	LoginFails prometheus.Counter
+
+	EtagHits             *prometheus.CounterVec
+	EtagMisses           *prometheus.CounterVec
+	MemCacheHits         *prometheus.CounterVec
+	MemCacheMisses       *prometheus.CounterVec
+	MemCacheInvalidation *prometheus.CounterVec
+
+	WebsocketEvents     *prometheus.CounterVec
+	WebsocketBroadcasts *prometheus.CounterVec
+
+	PostsSearches       prometheus.Counter
+	PostsSearchDuration prometheus.Histogram
+}
+
+func init() {
+	app.RegisterMetricsInterface(func(a *app.App) einterfaces.MetricsInterface {
+		return NewMetricsInterface(a)
+	})
+}
+
+func NewMetricsInterface(a *app.App) *MetricsInterfaceImpl {
+	m := &MetricsInterfaceImpl{
+		App:      a,
+		Registry: prometheus.NewRegistry(),
+	}
+
+	m.Registry.MustRegister(prometheus.NewGoCollector())
+	m.Registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+	m.Registry.MustRegister(newDBCollector(func() map[string]sql.DBStats {
+		return getDBStats(a)
+	}))
+	m.Registry.MustRegister(newWebsocketCollector(func() []app.HubStats {
+		if a.Srv == nil {
+			return nil
+		}
+		return a.GetHubStats()
+	}))
+
+	newCounter := func(subsystem, name, help string) prometheus.Counter {
+		counter := prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: METRICS_NAMESPACE,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		})
+		m.Registry.MustRegister(counter)
+		return counter
+	}
+
+	newCounterVec := func(subsystem, name, help string, labels ...string) *prometheus.CounterVec {
+		counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: METRICS_NAMESPACE,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		}, labels)
+		m.Registry.MustRegister(counter)
+		return counter
+	}
+
+	newHistogram := func(subsystem, name, help string) prometheus.Histogram {
+		histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: METRICS_NAMESPACE,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		})
+		m.Registry.MustRegister(histogram)
+		return histogram
+	}
+
+	m.PostCreate = newCounter(METRICS_SUBSYSTEM_POSTS, "total", "The total number of posts created.")
+	m.WebhookPost = newCounter(METRICS_SUBSYSTEM_POSTS, "webhooks_total", "The total number of posts created by webhooks.")
+	m.PostSentEmail = newCounter(METRICS_SUBSYSTEM_POSTS, "emails_sent_total", "The total number of email notifications sent for posts.")
+	m.PostSentPush = newCounter(METRICS_SUBSYSTEM_POSTS, "pushes_sent_total", "The total number of push notifications sent for posts.")
+	m.PostBroadcast = newCounter(METRICS_SUBSYSTEM_POSTS, "broadcasts_total", "The total number of websocket broadcasts sent because a post was created.")
+	m.PostFileAttachments = newCounter(METRICS_SUBSYSTEM_POSTS, "file_attachments_total", "The total number of files attached to posts.")
+
+	m.HttpRequests = newCounter(METRICS_SUBSYSTEM_HTTP, "requests_total", "The total number of HTTP requests.")
+	m.HttpErrors = newCounter(METRICS_SUBSYSTEM_HTTP, "errors_total", "The total number of HTTP requests that resulted in an error.")
+	m.HttpRequestDuration = newHistogram(METRICS_SUBSYSTEM_HTTP, "request_duration_seconds", "The time taken to handle HTTP requests, in seconds.")
+
+	m.ClusterRequests = newCounter(METRICS_SUBSYSTEM_CLUSTER, "requests_total", "The total number of inter-node cluster requests.")
+	m.ClusterRequestDuration = newHistogram(METRICS_SUBSYSTEM_CLUSTER, "request_duration_seconds", "The time taken to handle inter-node cluster requests, in seconds.")
+	m.ClusterEventTypes = newCounterVec(METRICS_SUBSYSTEM_CLUSTER, "events_total", "The total number of cluster events by type.", "type")
+
+	m.Logins = newCounter(METRICS_SUBSYSTEM_LOGIN, "logins_total", "The total number of successful logins.")
+	m.LoginFails = newCounter(METRICS_SUBSYSTEM_LOGIN, "logins_fail_total", "The total number of failed logins.")
+
+	m.EtagHits = newCounterVec(METRICS_SUBSYSTEM_CACHE, "etag_hit_total", "The total number of ETag cache hits by route.", "route")
+	m.EtagMisses = newCounterVec(METRICS_SUBSYSTEM_CACHE, "etag_miss_total", "The total number of ETag cache misses by route.", "route")
+	m.MemCacheHits = newCounterVec(METRICS_SUBSYSTEM_CACHE, "mem_hit_total", "The total number of memory cache hits by cache.", "name")
+	m.MemCacheMisses = newCounterVec(METRICS_SUBSYSTEM_CACHE, "mem_miss_total", "The total number of memory cache misses by cache.", "name")
+	m.MemCacheInvalidation = newCounterVec(METRICS_SUBSYSTEM_CACHE, "mem_invalidation_total", "The total number of memory cache invalidations by cache.", "name")
+
+	m.WebsocketEvents = newCounterVec(METRICS_SUBSYSTEM_WEBSOCKET, "events_total", "The total number of websocket events received by type.", "type")
+	m.WebsocketBroadcasts = newCounterVec(METRICS_SUBSYSTEM_WEBSOCKET, "broadcasts_total", "The total number of websocket broadcasts sent by type.", "type")
+
+	m.PostsSearches = newCounter(METRICS_SUBSYSTEM_SEARCH, "posts_searches_total", "The total number of post searches.")
+	m.PostsSearchDuration = newHistogram(METRICS_SUBSYSTEM_SEARCH, "posts_searches_duration_seconds", "The time taken to search posts, in seconds.")
+
+	return m
+}
+
+// StartServer starts serving the metrics on MetricsSettings.ListenAddress, or restarts the server
+// if that address has changed. It does nothing when metrics are disabled.
+func (m *MetricsInterfaceImpl) StartServer() {
+	settings := m.App.Config().MetricsSettings
+	if !*settings.Enable {
+		return
+	}
+
+	m.serverMutex.Lock()
+	defer m.serverMutex.Unlock()
+
+	if m.server != nil {
+		if m.serverAddress == *settings.ListenAddress {
+			return
+		}
+		m.stopServer()
+	}
+
+	runtime.SetBlockProfileRate(*settings.BlockProfileRate)
+
+	listener, err := net.Listen("tcp", *settings.ListenAddress)
+	if err != nil {
+		mlog.Error("Failed to start metrics server", mlog.String("address", *settings.ListenAddress), mlog.Err(err))
+		return
+	}
+
+	m.server = &http.Server{
+		Handler: m.newHandler(),
+	}
+	m.serverAddress = *settings.ListenAddress
+
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			mlog.Error("Metrics server stopped unexpectedly", mlog.Err(err))
+		}
+	}(m.server)
+
+	mlog.Info("Metrics server is listening", mlog.String("address", listener.Addr().String()))
+}
+
+func (m *MetricsInterfaceImpl) StopServer() {
+	m.serverMutex.Lock()
+	defer m.serverMutex.Unlock()
+
+	m.stopServer()
+}
+
+func (m *MetricsInterfaceImpl) stopServer() {
+	if m.server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), METRICS_SERVER_SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if err := m.server.Shutdown(ctx); err != nil {
+		mlog.Error("Failed to stop metrics server", mlog.Err(err))
+	}
+
+	m.server = nil
+	m.serverAddress = ""
+
+	mlog.Info("Metrics server stopped")
+}
+
+func (m *MetricsInterfaceImpl) newHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.Handle("/metrics", promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{
+		ErrorLog: &errorLogger{},
+	}))
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	return mux
+}
+
+type errorLogger struct{}
+
+func (l *errorLogger) Println(v ...interface{}) {
+	mlog.Error("Failed to serve metrics", mlog.String("error", fmt.Sprint(v...)))
+}
+
+func (m *MetricsInterfaceImpl) IncrementPostCreate() {
+	m.PostCreate.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementWebhookPost() {
+	m.WebhookPost.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementPostSentEmail() {
+	m.PostSentEmail.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementPostSentPush() {
+	m.PostSentPush.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementPostBroadcast() {
+	m.PostBroadcast.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementPostFileAttachment(count int) {
+	m.PostFileAttachments.Add(float64(count))
+}
+
+func (m *MetricsInterfaceImpl) IncrementHttpRequest() {
+	m.HttpRequests.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementHttpError() {
+	m.HttpErrors.Inc()
+}
+
+func (m *MetricsInterfaceImpl) ObserveHttpRequestDuration(elapsed float64) {
+	m.HttpRequestDuration.Observe(elapsed)
+}
+
+func (m *MetricsInterfaceImpl) IncrementClusterRequest() {
+	m.ClusterRequests.Inc()
+}
+
+func (m *MetricsInterfaceImpl) ObserveClusterRequestDuration(elapsed float64) {
+	m.ClusterRequestDuration.Observe(elapsed)
+}
+
+func (m *MetricsInterfaceImpl) IncrementClusterEventType(eventType string) {
+	m.ClusterEventTypes.WithLabelValues(eventType).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementLogin() {
+	m.Logins.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementLoginFail() {
+	m.LoginFails.Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementEtagHitCounter(route string) {
+	m.EtagHits.WithLabelValues(route).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementEtagMissCounter(route string) {
+	m.EtagMisses.WithLabelValues(route).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementMemCacheHitCounter(cacheName string) {
+	m.MemCacheHits.WithLabelValues(cacheName).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementMemCacheMissCounter(cacheName string) {
+	m.MemCacheMisses.WithLabelValues(cacheName).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementMemCacheInvalidationCounter(cacheName string) {
+	m.MemCacheInvalidation.WithLabelValues(cacheName).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementMemCacheMissCounterSession() {
+	m.IncrementMemCacheMissCounter(METRICS_CACHE_NAME_SESSION)
+}
+
+func (m *MetricsInterfaceImpl) IncrementMemCacheHitCounterSession() {
+	m.IncrementMemCacheHitCounter(METRICS_CACHE_NAME_SESSION)
+}
+
+func (m *MetricsInterfaceImpl) IncrementMemCacheInvalidationCounterSession() {
+	m.IncrementMemCacheInvalidationCounter(METRICS_CACHE_NAME_SESSION)
+}
+
+func (m *MetricsInterfaceImpl) IncrementWebsocketEvent(eventType string) {
+	m.WebsocketEvents.WithLabelValues(eventType).Inc()
+}
+
+func (m *MetricsInterfaceImpl) IncrementWebSocketBroadcast(eventType string) {
+	m.WebsocketBroadcasts.WithLabelValues(eventType).Inc()
+}
+
+func (m *MetricsInterfaceImpl) AddMemCacheHitCounter(cacheName string, amount float64) {
+	m.MemCacheHits.WithLabelValues(cacheName).Add(amount)
+}
+
+func (m *MetricsInterfaceImpl) AddMemCacheMissCounter(cacheName string, amount float64) {
+	m.MemCacheMisses.WithLabelValues(cacheName).Add(amount)
+}
+
+func (m *MetricsInterfaceImpl) IncrementPostsSearchCounter() {
+	m.PostsSearches.Inc()
+}
+
+func (m *MetricsInterfaceImpl) ObservePostsSearchDuration(elapsed float64) {
+	m.PostsSearchDuration.Observe(elapsed)
+}
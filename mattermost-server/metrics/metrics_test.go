@@ -0,0 +1,114 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package metrics
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/app"
+)
+
+// getMetricValue returns the value of the metric with the given name and labels, or fails the test
+// if it wasn't gathered.
+func getMetricValue(t *testing.T, gatherer prometheus.Gatherer, name string, labels map[string]string) float64 {
+	families, err := gatherer.Gather()
+	require.Nil(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			if !hasLabels(metric, labels) {
+				continue
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				return metric.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				return metric.GetGauge().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	require.FailNow(t, "metric not found", name)
+	return 0
+}
+
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	found := 0
+	for _, pair := range metric.GetLabel() {
+		if value, ok := labels[pair.GetName()]; ok && value == pair.GetValue() {
+			found++
+		}
+	}
+	return found == len(labels)
+}
+
+func TestCounters(t *testing.T) {
+	m := NewMetricsInterface(&app.App{})
+
+	m.IncrementPostCreate()
+	m.IncrementPostCreate()
+	m.IncrementPostFileAttachment(3)
+	m.ObserveHttpRequestDuration(0.25)
+	m.IncrementClusterEventType("publish")
+	m.IncrementEtagHitCounter("getPosts")
+	m.IncrementMemCacheHitCounter("Channel")
+	m.AddMemCacheHitCounter("Channel", 2)
+	m.IncrementMemCacheMissCounterSession()
+	m.IncrementWebSocketBroadcast("posted")
+
+	assert.Equal(t, float64(2), getMetricValue(t, m.Registry, "mattermost_post_total", nil))
+	assert.Equal(t, float64(3), getMetricValue(t, m.Registry, "mattermost_post_file_attachments_total", nil))
+	assert.Equal(t, float64(1), getMetricValue(t, m.Registry, "mattermost_http_request_duration_seconds", nil))
+	assert.Equal(t, float64(1), getMetricValue(t, m.Registry, "mattermost_cluster_events_total", map[string]string{"type": "publish"}))
+	assert.Equal(t, float64(1), getMetricValue(t, m.Registry, "mattermost_cache_etag_hit_total", map[string]string{"route": "getPosts"}))
+	assert.Equal(t, float64(3), getMetricValue(t, m.Registry, "mattermost_cache_mem_hit_total", map[string]string{"name": "Channel"}))
+	assert.Equal(t, float64(1), getMetricValue(t, m.Registry, "mattermost_cache_mem_miss_total", map[string]string{"name": METRICS_CACHE_NAME_SESSION}))
+	assert.Equal(t, float64(1), getMetricValue(t, m.Registry, "mattermost_websocket_broadcasts_total", map[string]string{"type": "posted"}))
+
+	// Go runtime statistics are always exported.
+	assert.NotZero(t, getMetricValue(t, m.Registry, "go_goroutines", nil))
+}
+
+func TestDBCollector(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newDBCollector(func() map[string]sql.DBStats {
+		return map[string]sql.DBStats{
+			"master":    {MaxOpenConnections: 300, OpenConnections: 10, InUse: 4, Idle: 6, WaitCount: 2, WaitDuration: 1500 * time.Millisecond},
+			"replica-0": {MaxOpenConnections: 300, OpenConnections: 3, InUse: 1, Idle: 2},
+		}
+	}))
+
+	assert.Equal(t, float64(10), getMetricValue(t, registry, "mattermost_db_open_connections", map[string]string{"db": "master"}))
+	assert.Equal(t, float64(4), getMetricValue(t, registry, "mattermost_db_in_use_connections", map[string]string{"db": "master"}))
+	assert.Equal(t, float64(1.5), getMetricValue(t, registry, "mattermost_db_wait_duration_seconds_total", map[string]string{"db": "master"}))
+	assert.Equal(t, float64(2), getMetricValue(t, registry, "mattermost_db_idle_connections", map[string]string{"db": "replica-0"}))
+}
+
+func TestWebsocketCollector(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newWebsocketCollector(func() []app.HubStats {
+		return []app.HubStats{
+			{Index: 0, Connections: 5, BroadcastQueueLength: 1},
+			{Index: 1, Connections: 7},
+		}
+	}))
+
+	assert.Equal(t, float64(12), getMetricValue(t, registry, "mattermost_websocket_connections", nil))
+	assert.Equal(t, float64(7), getMetricValue(t, registry, "mattermost_websocket_hub_connections", map[string]string{"hub": "1"}))
+	assert.Equal(t, float64(1), getMetricValue(t, registry, "mattermost_websocket_hub_broadcast_queue_length", map[string]string{"hub": "0"}))
+}
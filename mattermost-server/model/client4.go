@@ -226,6 +226,18 @@ func (c *Client4) GetPostsEphemeralRoute() string {
 	return fmt.Sprintf("/posts/ephemeral")
 }
 
+func (c *Client4) GetScheduledPostsRoute() string {
+	return fmt.Sprintf("/posts/scheduled")
+}
+
+func (c *Client4) GetScheduledPostRoute(scheduledPostId string) string {
+	return fmt.Sprintf(c.GetScheduledPostsRoute()+"/%v", scheduledPostId)
+}
+
+func (c *Client4) GetDraftsRoute() string {
+	return fmt.Sprintf("/drafts")
+}
+
 func (c *Client4) GetConfigRoute() string {
 	return fmt.Sprintf("/config")
 }
@@ -2430,6 +2442,87 @@ func (c *Client4) GetPostsBefore(channelId, postId string, page, perPage int, et
 	return PostListFromJson(r.Body), BuildResponse(r)
 }
 
+// CreateScheduledPost schedules a post to be created on behalf of the current user at its
+// ScheduledAt time.
+func (c *Client4) CreateScheduledPost(scheduledPost *ScheduledPost) (*ScheduledPost, *Response) {
+	r, err := c.DoApiPost(c.GetScheduledPostsRoute(), scheduledPost.ToJson())
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ScheduledPostFromJson(r.Body), BuildResponse(r)
+}
+
+// GetScheduledPostsForUser returns the posts scheduled by a user that weren't published yet,
+// including those that failed to be.
+func (c *Client4) GetScheduledPostsForUser(userId string) ([]*ScheduledPost, *Response) {
+	r, err := c.DoApiGet(c.GetUserRoute(userId)+"/posts/scheduled", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ScheduledPostListFromJson(r.Body), BuildResponse(r)
+}
+
+// PatchScheduledPost changes a scheduled post. A post that failed to be published is retried.
+func (c *Client4) PatchScheduledPost(scheduledPostId string, patch *ScheduledPostPatch) (*ScheduledPost, *Response) {
+	r, err := c.DoApiPut(c.GetScheduledPostRoute(scheduledPostId), patch.ToJson())
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ScheduledPostFromJson(r.Body), BuildResponse(r)
+}
+
+// DeleteScheduledPost cancels a scheduled post.
+func (c *Client4) DeleteScheduledPost(scheduledPostId string) (bool, *Response) {
+	r, err := c.DoApiDelete(c.GetScheduledPostRoute(scheduledPostId))
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return CheckStatusOK(r), BuildResponse(r)
+}
+
+// Draft Section
+
+// UpsertDraft saves the draft of the current user for a channel, or a thread if it has a RootId,
+// replacing the previous one.
+func (c *Client4) UpsertDraft(draft *Draft) (*Draft, *Response) {
+	r, err := c.DoApiPost(c.GetDraftsRoute(), draft.ToJson())
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return DraftFromJson(r.Body), BuildResponse(r)
+}
+
+// GetDraftsForUser returns the drafts of a user in the channels of a team, including direct and
+// group channels, most recently updated first.
+func (c *Client4) GetDraftsForUser(userId, teamId string) ([]*Draft, *Response) {
+	r, err := c.DoApiGet(c.GetUserRoute(userId)+c.GetTeamRoute(teamId)+"/drafts", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return DraftListFromJson(r.Body), BuildResponse(r)
+}
+
+// DeleteDraft deletes the draft of a user for a channel, or for a thread of it if rootId is set.
+func (c *Client4) DeleteDraft(userId, channelId, rootId string) (bool, *Response) {
+	route := c.GetUserRoute(userId) + c.GetChannelRoute(channelId) + "/drafts"
+	if rootId != "" {
+		route += "/" + rootId
+	}
+
+	r, err := c.DoApiDelete(route)
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return CheckStatusOK(r), BuildResponse(r)
+}
+
 // SearchPosts returns any posts with matching terms string.
 func (c *Client4) SearchPosts(teamId string, terms string, isOrSearch bool) (*PostList, *Response) {
 	params := SearchParameter{
@@ -2675,6 +2768,90 @@ func (c *Client4) GetFileInfosForPost(postId string, etag string) ([]*FileInfo,
 	return FileInfosFromJson(r.Body), BuildResponse(r)
 }
 
+// GetPostSeenBy returns the ids of the members of a direct or group channel who saw a post.
+func (c *Client4) GetPostSeenBy(postId string) ([]string, *Response) {
+	r, err := c.DoApiGet(c.GetPostRoute(postId)+"/seen_by", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ArrayFromJson(r.Body), BuildResponse(r)
+}
+
+// SetPostReminder reminds a user about a post at the given time, in milliseconds since the epoch,
+// replacing any reminder they already set on it.
+func (c *Client4) SetPostReminder(userId, postId string, targetTime int64) (*PostReminder, *Response) {
+	reminder := &PostReminder{TargetTime: targetTime}
+	r, err := c.DoApiPost(c.GetUserRoute(userId)+"/posts/"+postId+"/reminder", reminder.ToJson())
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return PostReminderFromJson(r.Body), BuildResponse(r)
+}
+
+// GetPostRemindersForUser returns the pending reminders of a user, the soonest first.
+func (c *Client4) GetPostRemindersForUser(userId string) ([]*PostReminder, *Response) {
+	r, err := c.DoApiGet(c.GetUserRoute(userId)+"/posts/reminders", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return PostReminderListFromJson(r.Body), BuildResponse(r)
+}
+
+// DeletePostReminder cancels the reminder a user set on a post.
+func (c *Client4) DeletePostReminder(userId, postId string) (bool, *Response) {
+	r, err := c.DoApiDelete(c.GetUserRoute(userId) + "/posts/" + postId + "/reminder")
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return CheckStatusOK(r), BuildResponse(r)
+}
+
+// Thread Section
+
+// GetThreadsForUser returns a page of the threads a user follows in a team, including direct and
+// group channels, most recently updated first.
+func (c *Client4) GetThreadsForUser(userId, teamId string, page, perPage int) ([]*UserThread, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	r, err := c.DoApiGet(c.GetUserRoute(userId)+c.GetTeamRoute(teamId)+"/threads"+query, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return UserThreadListFromJson(r.Body), BuildResponse(r)
+}
+
+// UpdateThreadFollowing starts or stops a user following a thread, identified by its root post.
+func (c *Client4) UpdateThreadFollowing(userId, teamId, postId string, following bool) (*ThreadMembership, *Response) {
+	route := c.GetUserRoute(userId) + c.GetTeamRoute(teamId) + "/threads/" + postId + "/following"
+
+	var r *http.Response
+	var err *AppError
+	if following {
+		r, err = c.DoApiPut(route, "")
+	} else {
+		r, err = c.DoApiDelete(route)
+	}
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+}
+
+// MarkThreadAsRead clears the unread replies and mentions of a thread for a user.
+func (c *Client4) MarkThreadAsRead(userId, teamId, postId string) (*ThreadMembership, *Response) {
+	r, err := c.DoApiPut(c.GetUserRoute(userId)+c.GetTeamRoute(teamId)+"/threads/"+postId+"/read", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ThreadMembershipFromJson(r.Body), BuildResponse(r)
+}
+
 // General/System Section
 
 // GetPing will return ok if the running goRoutines are below the threshold and unhealthy for above.
@@ -3655,6 +3832,90 @@ func (c *Client4) GetDataRetentionPolicy() (*DataRetentionPolicy, *Response) {
 	return DataRetentionPolicyFromJson(r.Body), BuildResponse(r)
 }
 
+// GetTeamRetentionPolicies returns a page of the team data retention policies. Must have manage_system permission.
+func (c *Client4) GetTeamRetentionPolicies(page, perPage int) ([]*TeamRetentionPolicy, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	r, err := c.DoApiGet(c.GetDataRetentionRoute()+"/policies/teams"+query, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return TeamRetentionPolicyListFromJson(r.Body), BuildResponse(r)
+}
+
+// GetTeamRetentionPolicy returns the data retention policy of a team. Must have manage_system permission.
+func (c *Client4) GetTeamRetentionPolicy(teamId string) (*TeamRetentionPolicy, *Response) {
+	r, err := c.DoApiGet(c.GetDataRetentionRoute()+"/policies/teams/"+teamId, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return TeamRetentionPolicyFromJson(r.Body), BuildResponse(r)
+}
+
+// SaveTeamRetentionPolicy creates or updates the data retention policy of a team. Must have manage_system permission.
+func (c *Client4) SaveTeamRetentionPolicy(policy *TeamRetentionPolicy) (*TeamRetentionPolicy, *Response) {
+	r, err := c.DoApiPut(c.GetDataRetentionRoute()+"/policies/teams/"+policy.TeamId, policy.ToJson())
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return TeamRetentionPolicyFromJson(r.Body), BuildResponse(r)
+}
+
+// DeleteTeamRetentionPolicy removes the data retention policy of a team so that the global policy applies
+// to it again. Must have manage_system permission.
+func (c *Client4) DeleteTeamRetentionPolicy(teamId string) (bool, *Response) {
+	r, err := c.DoApiDelete(c.GetDataRetentionRoute() + "/policies/teams/" + teamId)
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return CheckStatusOK(r), BuildResponse(r)
+}
+
+// GetChannelRetentionPolicies returns a page of the channel data retention policies. Must have manage_system permission.
+func (c *Client4) GetChannelRetentionPolicies(page, perPage int) ([]*ChannelRetentionPolicy, *Response) {
+	query := fmt.Sprintf("?page=%v&per_page=%v", page, perPage)
+	r, err := c.DoApiGet(c.GetDataRetentionRoute()+"/policies/channels"+query, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ChannelRetentionPolicyListFromJson(r.Body), BuildResponse(r)
+}
+
+// GetChannelRetentionPolicy returns the data retention policy of a channel. Must have manage_system permission.
+func (c *Client4) GetChannelRetentionPolicy(channelId string) (*ChannelRetentionPolicy, *Response) {
+	r, err := c.DoApiGet(c.GetDataRetentionRoute()+"/policies/channels/"+channelId, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ChannelRetentionPolicyFromJson(r.Body), BuildResponse(r)
+}
+
+// SaveChannelRetentionPolicy creates or updates the data retention policy of a channel. Must have manage_system permission.
+func (c *Client4) SaveChannelRetentionPolicy(policy *ChannelRetentionPolicy) (*ChannelRetentionPolicy, *Response) {
+	r, err := c.DoApiPut(c.GetDataRetentionRoute()+"/policies/channels/"+policy.ChannelId, policy.ToJson())
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ChannelRetentionPolicyFromJson(r.Body), BuildResponse(r)
+}
+
+// DeleteChannelRetentionPolicy removes the data retention policy of a channel so that the team or global
+// policy applies to it again. Must have manage_system permission.
+func (c *Client4) DeleteChannelRetentionPolicy(channelId string) (bool, *Response) {
+	r, err := c.DoApiDelete(c.GetDataRetentionRoute() + "/policies/channels/" + channelId)
+	if err != nil {
+		return false, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return CheckStatusOK(r), BuildResponse(r)
+}
+
 // Commands Section
 
 // CreateCommand will create a new command if the user have the right permissions.
@@ -4145,14 +4406,20 @@ func (c *Client4) GetChannelsForScheme(schemeId string, page int, perPage int) (
 // UploadPlugin takes an io.Reader stream pointing to the contents of a .tar.gz plugin.
 // WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
 func (c *Client4) UploadPlugin(file io.Reader) (*Manifest, *Response) {
-	return c.uploadPlugin(file, false)
+	return c.uploadPlugin(file, nil, false)
 }
 
 func (c *Client4) UploadPluginForced(file io.Reader) (*Manifest, *Response) {
-	return c.uploadPlugin(file, true)
+	return c.uploadPlugin(file, nil, true)
+}
+
+// UploadPluginWithSignature takes io.Reader streams pointing to the contents of a .tar.gz plugin
+// and of its detached signature.
+func (c *Client4) UploadPluginWithSignature(file, signature io.Reader) (*Manifest, *Response) {
+	return c.uploadPlugin(file, signature, false)
 }
 
-func (c *Client4) uploadPlugin(file io.Reader, force bool) (*Manifest, *Response) {
+func (c *Client4) uploadPlugin(file, signature io.Reader, force bool) (*Manifest, *Response) {
 	body := new(bytes.Buffer)
 	writer := multipart.NewWriter(body)
 
@@ -4172,6 +4439,17 @@ func (c *Client4) uploadPlugin(file io.Reader, force bool) (*Manifest, *Response
 		return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
 	}
 
+	if signature != nil {
+		part, err = writer.CreateFormFile("signature", "plugin.tar.gz.sig")
+		if err != nil {
+			return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+		}
+
+		if _, err = io.Copy(part, signature); err != nil {
+			return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
+		}
+	}
+
 	if err = writer.Close(); err != nil {
 		return nil, &Response{Error: NewAppError("UploadPlugin", "model.client.writer.app_error", nil, err.Error(), 0)}
 	}
@@ -4199,6 +4477,55 @@ func (c *Client4) uploadPlugin(file io.Reader, force bool) (*Manifest, *Response
 	return ManifestFromJson(rp.Body), BuildResponse(rp)
 }
 
+// InstallPluginFromUrl will have the server download and install the plugin bundle at the given
+// HTTPS URL.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) InstallPluginFromUrl(downloadUrl string, force bool) (*Manifest, *Response) {
+	query := fmt.Sprintf("?plugin_download_url=%v&force=%v", url.QueryEscape(downloadUrl), force)
+	r, err := c.DoApiPost(c.GetPluginsRoute()+"/install_from_url"+query, "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ManifestFromJson(r.Body), BuildResponse(r)
+}
+
+// GetMarketplacePlugins will return the plugins listed in the marketplace index configured on the
+// server.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetMarketplacePlugins() (*MarketplaceIndex, *Response) {
+	r, err := c.DoApiGet(c.GetPluginsRoute()+"/marketplace", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return MarketplaceIndexFromJson(r.Body), BuildResponse(r)
+}
+
+// GetMarketplacePluginUpgrades will return the installed plugins for which the marketplace lists a
+// newer version.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) GetMarketplacePluginUpgrades() ([]*MarketplacePluginUpgrade, *Response) {
+	r, err := c.DoApiGet(c.GetPluginsRoute()+"/marketplace/upgrades", "")
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return MarketplacePluginUpgradesFromJson(r.Body), BuildResponse(r)
+}
+
+// InstallMarketplacePlugin will install the given version of a plugin listed in the marketplace, or
+// its latest version if the version is empty.
+// WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
+func (c *Client4) InstallMarketplacePlugin(id, version string) (*Manifest, *Response) {
+	r, err := c.DoApiPost(c.GetPluginsRoute()+"/marketplace", MapToJson(map[string]string{"id": id, "version": version}))
+	if err != nil {
+		return nil, BuildErrorResponse(r, err)
+	}
+	defer closeBody(r)
+	return ManifestFromJson(r.Body), BuildResponse(r)
+}
+
 // GetPlugins will return a list of plugin manifests for currently active plugins.
 // WARNING: PLUGINS ARE STILL EXPERIMENTAL. THIS FUNCTION IS SUBJECT TO CHANGE.
 func (c *Client4) GetPlugins() (*PluginsResponse, *Response) {
@@ -43,6 +43,7 @@ const (
 	SERVICE_GITLAB    = "gitlab"
 	SERVICE_GOOGLE    = "google"
 	SERVICE_OFFICE365 = "office365"
+	SERVICE_OPENID    = "openid"
 
 	GENERIC_NO_CHANNEL_NOTIFICATION = "generic_no_channel"
 	GENERIC_NOTIFICATION            = "generic"
@@ -137,6 +138,14 @@ const (
 	SAML_SETTINGS_DEFAULT_LOCALE_ATTRIBUTE     = ""
 	SAML_SETTINGS_DEFAULT_POSITION_ATTRIBUTE   = ""
 
+	OPENID_SETTINGS_DEFAULT_SCOPE                = "openid profile email"
+	OPENID_SETTINGS_DEFAULT_ID_ATTRIBUTE         = "sub"
+	OPENID_SETTINGS_DEFAULT_USERNAME_ATTRIBUTE   = "preferred_username"
+	OPENID_SETTINGS_DEFAULT_EMAIL_ATTRIBUTE      = "email"
+	OPENID_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE = "given_name"
+	OPENID_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE  = "family_name"
+	OPENID_SETTINGS_DEFAULT_BUTTON_TEXT          = "OpenID Connect"
+
 	NATIVEAPP_SETTINGS_DEFAULT_APP_DOWNLOAD_LINK         = "https://about.mattermost.com/downloads/"
 	NATIVEAPP_SETTINGS_DEFAULT_ANDROID_APP_DOWNLOAD_LINK = "https://about.mattermost.com/mattermost-android-app/"
 	NATIVEAPP_SETTINGS_DEFAULT_IOS_APP_DOWNLOAD_LINK     = "https://about.mattermost.com/mattermost-ios-app/"
@@ -166,9 +175,12 @@ const (
 	ELASTICSEARCH_SETTINGS_DEFAULT_BULK_INDEXING_TIME_WINDOW_SECONDS = 3600
 	ELASTICSEARCH_SETTINGS_DEFAULT_REQUEST_TIMEOUT_SECONDS           = 30
 
+	BLEVE_SETTINGS_DEFAULT_INDEX_DIR = "./bleveindexes"
+
 	DATA_RETENTION_SETTINGS_DEFAULT_MESSAGE_RETENTION_DAYS  = 365
 	DATA_RETENTION_SETTINGS_DEFAULT_FILE_RETENTION_DAYS     = 365
 	DATA_RETENTION_SETTINGS_DEFAULT_DELETION_JOB_START_TIME = "02:00"
+	DATA_RETENTION_SETTINGS_DEFAULT_BATCH_SIZE              = 3000
 
 	PLUGIN_SETTINGS_DEFAULT_DIRECTORY        = "./plugins"
 	PLUGIN_SETTINGS_DEFAULT_CLIENT_DIRECTORY = "./client/plugins"
@@ -176,6 +188,7 @@ const (
 	COMPLIANCE_EXPORT_TYPE_CSV         = "csv"
 	COMPLIANCE_EXPORT_TYPE_ACTIANCE    = "actiance"
 	COMPLIANCE_EXPORT_TYPE_GLOBALRELAY = "globalrelay"
+	COMPLIANCE_EXPORT_TYPE_EML         = "eml"
 	GLOBALRELAY_CUSTOMER_TYPE_A9       = "A9"
 	GLOBALRELAY_CUSTOMER_TYPE_A10      = "A10"
 
@@ -650,6 +663,7 @@ type ClusterSettings struct {
 	UseExperimentalGossip       *bool   `restricted:"true"`
 	ReadOnlyConfig              *bool   `restricted:"true"`
 	GossipPort                  *int    `restricted:"true"`
+	GossipSecret                *string `restricted:"true"`
 	StreamingPort               *int    `restricted:"true"`
 	MaxIdleConns                *int    `restricted:"true"`
 	MaxIdleConnsPerHost         *int    `restricted:"true"`
@@ -685,6 +699,10 @@ func (s *ClusterSettings) SetDefaults() {
 		s.GossipPort = NewInt(8074)
 	}
 
+	if s.GossipSecret == nil {
+		s.GossipSecret = NewString("")
+	}
+
 	if s.StreamingPort == nil {
 		s.StreamingPort = NewInt(8075)
 	}
@@ -807,6 +825,79 @@ func (s *SSOSettings) setDefaults() {
 	}
 }
 
+type OpenIdSettings struct {
+	Enable            *bool
+	Secret            *string
+	Id                *string
+	Scope             *string
+	DiscoveryEndpoint *string
+	EnablePKCE        *bool
+
+	// User Mapping
+	IdAttribute        *string
+	UsernameAttribute  *string
+	EmailAttribute     *string
+	FirstNameAttribute *string
+	LastNameAttribute  *string
+
+	ButtonText  *string
+	ButtonColor *string
+}
+
+func (s *OpenIdSettings) SetDefaults() {
+	if s.Enable == nil {
+		s.Enable = NewBool(false)
+	}
+
+	if s.Secret == nil {
+		s.Secret = NewString("")
+	}
+
+	if s.Id == nil {
+		s.Id = NewString("")
+	}
+
+	if s.Scope == nil {
+		s.Scope = NewString(OPENID_SETTINGS_DEFAULT_SCOPE)
+	}
+
+	if s.DiscoveryEndpoint == nil {
+		s.DiscoveryEndpoint = NewString("")
+	}
+
+	if s.EnablePKCE == nil {
+		s.EnablePKCE = NewBool(true)
+	}
+
+	if s.IdAttribute == nil {
+		s.IdAttribute = NewString(OPENID_SETTINGS_DEFAULT_ID_ATTRIBUTE)
+	}
+
+	if s.UsernameAttribute == nil {
+		s.UsernameAttribute = NewString(OPENID_SETTINGS_DEFAULT_USERNAME_ATTRIBUTE)
+	}
+
+	if s.EmailAttribute == nil {
+		s.EmailAttribute = NewString(OPENID_SETTINGS_DEFAULT_EMAIL_ATTRIBUTE)
+	}
+
+	if s.FirstNameAttribute == nil {
+		s.FirstNameAttribute = NewString(OPENID_SETTINGS_DEFAULT_FIRST_NAME_ATTRIBUTE)
+	}
+
+	if s.LastNameAttribute == nil {
+		s.LastNameAttribute = NewString(OPENID_SETTINGS_DEFAULT_LAST_NAME_ATTRIBUTE)
+	}
+
+	if s.ButtonText == nil || *s.ButtonText == "" {
+		s.ButtonText = NewString(OPENID_SETTINGS_DEFAULT_BUTTON_TEXT)
+	}
+
+	if s.ButtonColor == nil {
+		s.ButtonColor = NewString("#145DBF")
+	}
+}
+
 type SqlSettings struct {
 	DriverName                  *string  `restricted:"true"`
 	DataSource                  *string  `restricted:"true"`
@@ -1278,8 +1369,9 @@ func (s *RateLimitSettings) SetDefaults() {
 }
 
 type PrivacySettings struct {
-	ShowEmailAddress *bool
-	ShowFullName     *bool
+	ShowEmailAddress   *bool
+	ShowFullName       *bool
+	EnableReadReceipts *bool
 }
 
 func (s *PrivacySettings) setDefaults() {
@@ -1290,6 +1382,10 @@ func (s *PrivacySettings) setDefaults() {
 	if s.ShowFullName == nil {
 		s.ShowFullName = NewBool(true)
 	}
+
+	if s.EnableReadReceipts == nil {
+		s.EnableReadReceipts = NewBool(true)
+	}
 }
 
 type SupportSettings struct {
@@ -2060,12 +2156,25 @@ func (s *ElasticsearchSettings) SetDefaults() {
 	}
 }
 
+// BleveSettings configures the embedded search engine that is used in place of Elasticsearch when no
+// Elasticsearch implementation is available. It is enabled through the ElasticsearchSettings.
+type BleveSettings struct {
+	IndexDir *string `restricted:"true"`
+}
+
+func (s *BleveSettings) SetDefaults() {
+	if s.IndexDir == nil {
+		s.IndexDir = NewString(BLEVE_SETTINGS_DEFAULT_INDEX_DIR)
+	}
+}
+
 type DataRetentionSettings struct {
 	EnableMessageDeletion *bool
 	EnableFileDeletion    *bool
 	MessageRetentionDays  *int
 	FileRetentionDays     *int
 	DeletionJobStartTime  *string
+	BatchSize             *int
 }
 
 func (s *DataRetentionSettings) SetDefaults() {
@@ -2088,6 +2197,10 @@ func (s *DataRetentionSettings) SetDefaults() {
 	if s.DeletionJobStartTime == nil {
 		s.DeletionJobStartTime = NewString(DATA_RETENTION_SETTINGS_DEFAULT_DELETION_JOB_START_TIME)
 	}
+
+	if s.BatchSize == nil {
+		s.BatchSize = NewInt(DATA_RETENTION_SETTINGS_DEFAULT_BATCH_SIZE)
+	}
 }
 
 type JobSettings struct {
@@ -2109,14 +2222,33 @@ type PluginState struct {
 	Enable bool
 }
 
+// PluginResourceLimits caps the resources available to the server component of a plugin. Zero
+// means no limit.
+type PluginResourceLimits struct {
+	MaxMemoryMB   *int
+	MaxCPUPercent *int // percentage of a single CPU
+}
+
 type PluginSettings struct {
-	Enable            *bool
-	EnableUploads     *bool   `restricted:"true"`
-	EnableHealthCheck *bool   `restricted:"true"`
-	Directory         *string `restricted:"true"`
-	ClientDirectory   *string `restricted:"true"`
-	Plugins           map[string]map[string]interface{}
-	PluginStates      map[string]*PluginState
+	Enable                  *bool
+	EnableUploads           *bool   `restricted:"true"`
+	EnableHealthCheck       *bool   `restricted:"true"`
+	Directory               *string `restricted:"true"`
+	ClientDirectory         *string `restricted:"true"`
+	Plugins                 map[string]map[string]interface{}
+	PluginStates            map[string]*PluginState
+	RequirePluginSignature  *bool    `restricted:"true"`
+	SignaturePublicKeyFiles []string `restricted:"true"`
+	AllowedUnsignedPlugins  []string `restricted:"true"`
+	MarketplaceUrl          *string  `restricted:"true"`
+
+	// MaxMemoryMB and MaxCPUPercent apply to every plugin, unless overridden in ResourceLimits. CPU
+	// limits require ResourceLimitsCgroupPath, a cgroup v2 directory delegated to the server in
+	// which a cgroup is created for each plugin.
+	MaxMemoryMB              *int                             `restricted:"true"`
+	MaxCPUPercent            *int                             `restricted:"true"`
+	ResourceLimits           map[string]*PluginResourceLimits `restricted:"true"`
+	ResourceLimitsCgroupPath *string                          `restricted:"true"`
 }
 
 func (s *PluginSettings) SetDefaults() {
@@ -2159,6 +2291,74 @@ func (s *PluginSettings) SetDefaults() {
 	if s.PluginStates["com.mattermost.nps"] == nil {
 		s.PluginStates["com.mattermost.nps"] = &PluginState{Enable: true}
 	}
+
+	if s.RequirePluginSignature == nil {
+		s.RequirePluginSignature = NewBool(false)
+	}
+
+	if s.SignaturePublicKeyFiles == nil {
+		s.SignaturePublicKeyFiles = []string{}
+	}
+
+	if s.AllowedUnsignedPlugins == nil {
+		s.AllowedUnsignedPlugins = []string{}
+	}
+
+	if s.MarketplaceUrl == nil {
+		s.MarketplaceUrl = NewString("")
+	}
+
+	if s.MaxMemoryMB == nil {
+		s.MaxMemoryMB = NewInt(0)
+	}
+
+	if s.MaxCPUPercent == nil {
+		s.MaxCPUPercent = NewInt(0)
+	}
+
+	if s.ResourceLimits == nil {
+		s.ResourceLimits = make(map[string]*PluginResourceLimits)
+	}
+
+	if s.ResourceLimitsCgroupPath == nil {
+		s.ResourceLimitsCgroupPath = NewString("")
+	}
+}
+
+// ResourceLimitsForPlugin returns the resource limits of the plugin with the given id, taking
+// overrides into account.
+func (s *PluginSettings) ResourceLimitsForPlugin(id string) *PluginResourceLimits {
+	limits := &PluginResourceLimits{
+		MaxMemoryMB:   NewInt(*s.MaxMemoryMB),
+		MaxCPUPercent: NewInt(*s.MaxCPUPercent),
+	}
+
+	if override, ok := s.ResourceLimits[id]; ok && override != nil {
+		if override.MaxMemoryMB != nil {
+			*limits.MaxMemoryMB = *override.MaxMemoryMB
+		}
+		if override.MaxCPUPercent != nil {
+			*limits.MaxCPUPercent = *override.MaxCPUPercent
+		}
+	}
+
+	return limits
+}
+
+// AllowsUnsignedPlugin returns true if the plugin with the given id may be installed without a
+// signature, which is meant for development builds.
+func (s *PluginSettings) AllowsUnsignedPlugin(id string) bool {
+	if !*s.RequirePluginSignature {
+		return true
+	}
+
+	for _, allowed := range s.AllowedUnsignedPlugins {
+		if strings.ToLower(allowed) == id {
+			return true
+		}
+	}
+
+	return false
 }
 
 type GlobalRelayMessageExportSettings struct {
@@ -2188,6 +2388,7 @@ type MessageExportSettings struct {
 	ExportFormat        *string
 	DailyRunTime        *string
 	ExportFromTimestamp *int64
+	FileLocation        *string
 	BatchSize           *int
 
 	// formatter-specific settings - these are only expected to be non-nil if ExportFormat is set to the associated format
@@ -2211,6 +2412,10 @@ func (s *MessageExportSettings) SetDefaults() {
 		s.ExportFromTimestamp = NewInt64(0)
 	}
 
+	if s.FileLocation == nil {
+		s.FileLocation = NewString("export")
+	}
+
 	if s.BatchSize == nil {
 		s.BatchSize = NewInt(10000)
 	}
@@ -2298,6 +2503,7 @@ type Config struct {
 	GitLabSettings          SSOSettings
 	GoogleSettings          SSOSettings
 	Office365Settings       SSOSettings
+	OpenIdSettings          OpenIdSettings
 	LdapSettings            LdapSettings
 	ComplianceSettings      ComplianceSettings
 	LocalizationSettings    LocalizationSettings
@@ -2308,6 +2514,7 @@ type Config struct {
 	ExperimentalSettings    ExperimentalSettings
 	AnalyticsSettings       AnalyticsSettings
 	ElasticsearchSettings   ElasticsearchSettings
+	BleveSettings           BleveSettings
 	DataRetentionSettings   DataRetentionSettings
 	MessageExportSettings   MessageExportSettings
 	JobSettings             JobSettings
@@ -2367,6 +2574,7 @@ func (o *Config) SetDefaults() {
 	o.Office365Settings.setDefaults()
 	o.GitLabSettings.setDefaults()
 	o.GoogleSettings.setDefaults()
+	o.OpenIdSettings.SetDefaults()
 	o.ServiceSettings.SetDefaults()
 	o.PasswordSettings.SetDefaults()
 	o.TeamSettings.SetDefaults()
@@ -2381,6 +2589,7 @@ func (o *Config) SetDefaults() {
 	o.ComplianceSettings.SetDefaults()
 	o.LocalizationSettings.SetDefaults()
 	o.ElasticsearchSettings.SetDefaults()
+	o.BleveSettings.SetDefaults()
 	o.NativeAppSettings.SetDefaults()
 	o.DataRetentionSettings.SetDefaults()
 	o.RateLimitSettings.SetDefaults()
@@ -2413,6 +2622,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.ClusterSettings.isValid(); err != nil {
+		return err
+	}
+
 	if err := o.FileSettings.isValid(); err != nil {
 		return err
 	}
@@ -2429,6 +2642,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.OpenIdSettings.isValid(); err != nil {
+		return err
+	}
+
 	if *o.PasswordSettings.MinimumLength < PASSWORD_MINIMUM_LENGTH || *o.PasswordSettings.MinimumLength > PASSWORD_MAXIMUM_LENGTH {
 		return NewAppError("Config.IsValid", "model.config.is_valid.password_length.app_error", map[string]interface{}{"MinLength": PASSWORD_MINIMUM_LENGTH, "MaxLength": PASSWORD_MAXIMUM_LENGTH}, "", http.StatusBadRequest)
 	}
@@ -2445,6 +2662,10 @@ func (o *Config) IsValid() *AppError {
 		return err
 	}
 
+	if err := o.BleveSettings.isValid(); err != nil {
+		return err
+	}
+
 	if err := o.DataRetentionSettings.isValid(); err != nil {
 		return err
 	}
@@ -2500,6 +2721,14 @@ func (ts *TeamSettings) isValid() *AppError {
 	return nil
 }
 
+func (cs *ClusterSettings) isValid() *AppError {
+	if *cs.Enable && len(*cs.GossipSecret) < 32 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.cluster_gossip_secret.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 func (ss *SqlSettings) isValid() *AppError {
 	if len(*ss.AtRestEncryptKey) < 32 {
 		return NewAppError("Config.IsValid", "model.config.is_valid.encrypt_sql.app_error", nil, "", http.StatusBadRequest)
@@ -2632,6 +2861,36 @@ func (ls *LdapSettings) isValid() *AppError {
 	return nil
 }
 
+func (s *OpenIdSettings) isValid() *AppError {
+	if *s.Enable {
+		if len(*s.Id) == 0 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.openid_id.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if len(*s.DiscoveryEndpoint) == 0 || !IsValidHttpUrl(*s.DiscoveryEndpoint) {
+			return NewAppError("Config.IsValid", "model.config.is_valid.openid_discovery_endpoint.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if !strings.Contains(" "+*s.Scope+" ", " openid ") {
+			return NewAppError("Config.IsValid", "model.config.is_valid.openid_scope.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if len(*s.IdAttribute) == 0 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.openid_id_attribute.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if len(*s.EmailAttribute) == 0 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.openid_email_attribute.app_error", nil, "", http.StatusBadRequest)
+		}
+
+		if len(*s.UsernameAttribute) == 0 {
+			return NewAppError("Config.IsValid", "model.config.is_valid.openid_username_attribute.app_error", nil, "", http.StatusBadRequest)
+		}
+	}
+
+	return nil
+}
+
 func (ss *SamlSettings) isValid() *AppError {
 	if *ss.Enable {
 		if len(*ss.IdpUrl) == 0 || !IsValidHttpUrl(*ss.IdpUrl) {
@@ -2796,6 +3055,14 @@ func (ess *ElasticsearchSettings) isValid() *AppError {
 	return nil
 }
 
+func (bs *BleveSettings) isValid() *AppError {
+	if len(*bs.IndexDir) == 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.bleve_search.index_dir.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	return nil
+}
+
 func (drs *DataRetentionSettings) isValid() *AppError {
 	if *drs.MessageRetentionDays <= 0 {
 		return NewAppError("Config.IsValid", "model.config.is_valid.data_retention.message_retention_days_too_low.app_error", nil, "", http.StatusBadRequest)
@@ -2809,6 +3076,10 @@ func (drs *DataRetentionSettings) isValid() *AppError {
 		return NewAppError("Config.IsValid", "model.config.is_valid.data_retention.deletion_job_start_time.app_error", nil, err.Error(), http.StatusBadRequest)
 	}
 
+	if *drs.BatchSize <= 0 {
+		return NewAppError("Config.IsValid", "model.config.is_valid.data_retention.batch_size.app_error", nil, "", http.StatusBadRequest)
+	}
+
 	return nil
 }
 
@@ -2835,7 +3106,7 @@ func (mes *MessageExportSettings) isValid(fs FileSettings) *AppError {
 			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.daily_runtime.app_error", nil, err.Error(), http.StatusBadRequest)
 		} else if mes.BatchSize == nil || *mes.BatchSize < 0 {
 			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.batch_size.app_error", nil, "", http.StatusBadRequest)
-		} else if mes.ExportFormat == nil || (*mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_ACTIANCE && *mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_GLOBALRELAY && *mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_CSV) {
+		} else if mes.ExportFormat == nil || (*mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_ACTIANCE && *mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_GLOBALRELAY && *mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_CSV && *mes.ExportFormat != COMPLIANCE_EXPORT_TYPE_EML) {
 			return NewAppError("Config.IsValid", "model.config.is_valid.message_export.export_type.app_error", nil, "", http.StatusBadRequest)
 		}
 
@@ -2921,9 +3192,17 @@ func (o *Config) Sanitize() {
 		*o.GitLabSettings.Secret = FAKE_SETTING
 	}
 
+	if len(*o.OpenIdSettings.Secret) > 0 {
+		*o.OpenIdSettings.Secret = FAKE_SETTING
+	}
+
 	*o.SqlSettings.DataSource = FAKE_SETTING
 	*o.SqlSettings.AtRestEncryptKey = FAKE_SETTING
 
+	if len(*o.ClusterSettings.GossipSecret) > 0 {
+		*o.ClusterSettings.GossipSecret = FAKE_SETTING
+	}
+
 	for i := range o.SqlSettings.DataSourceReplicas {
 		o.SqlSettings.DataSourceReplicas[i] = FAKE_SETTING
 	}
@@ -142,6 +142,41 @@ func TestConfigDefaultNPSPluginState(t *testing.T) {
 	}
 }
 
+func TestPluginSettingsAllowsUnsignedPlugin(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+
+	assert.True(t, c1.PluginSettings.AllowsUnsignedPlugin("com.example.plugin"))
+
+	c1.PluginSettings.RequirePluginSignature = NewBool(true)
+	assert.False(t, c1.PluginSettings.AllowsUnsignedPlugin("com.example.plugin"))
+
+	c1.PluginSettings.AllowedUnsignedPlugins = []string{"com.example.Plugin"}
+	assert.True(t, c1.PluginSettings.AllowsUnsignedPlugin("com.example.plugin"))
+	assert.False(t, c1.PluginSettings.AllowsUnsignedPlugin("com.example.other"))
+}
+
+func TestPluginSettingsResourceLimitsForPlugin(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+
+	limits := c1.PluginSettings.ResourceLimitsForPlugin("com.example.plugin")
+	assert.Equal(t, 0, *limits.MaxMemoryMB)
+	assert.Equal(t, 0, *limits.MaxCPUPercent)
+
+	*c1.PluginSettings.MaxMemoryMB = 512
+	*c1.PluginSettings.MaxCPUPercent = 50
+	c1.PluginSettings.ResourceLimits["com.example.plugin"] = &PluginResourceLimits{MaxMemoryMB: NewInt(1024)}
+
+	limits = c1.PluginSettings.ResourceLimitsForPlugin("com.example.plugin")
+	assert.Equal(t, 1024, *limits.MaxMemoryMB)
+	assert.Equal(t, 50, *limits.MaxCPUPercent)
+
+	limits = c1.PluginSettings.ResourceLimitsForPlugin("com.example.other")
+	assert.Equal(t, 512, *limits.MaxMemoryMB)
+	assert.Equal(t, 50, *limits.MaxCPUPercent)
+}
+
 func TestTeamSettingsIsValidSiteNameEmpty(t *testing.T) {
 	c1 := Config{}
 	c1.SetDefaults()
@@ -154,6 +189,24 @@ func TestTeamSettingsIsValidSiteNameEmpty(t *testing.T) {
 	}
 }
 
+func TestClusterSettingsIsValidGossipSecret(t *testing.T) {
+	c1 := Config{}
+	c1.SetDefaults()
+	require.Nil(t, c1.ClusterSettings.isValid())
+
+	*c1.ClusterSettings.Enable = true
+	require.NotNil(t, c1.ClusterSettings.isValid())
+
+	*c1.ClusterSettings.GossipSecret = "too short"
+	require.NotNil(t, c1.ClusterSettings.isValid())
+
+	*c1.ClusterSettings.GossipSecret = NewRandomString(32)
+	require.Nil(t, c1.ClusterSettings.isValid())
+
+	c1.Sanitize()
+	assert.Equal(t, FAKE_SETTING, *c1.ClusterSettings.GossipSecret)
+}
+
 func TestMessageExportSettingsIsValidEnableExportNotSet(t *testing.T) {
 	fs := &FileSettings{}
 	mes := &MessageExportSettings{}
@@ -269,6 +322,22 @@ func TestMessageExportSettingsIsValidActiance(t *testing.T) {
 	require.Nil(t, mes.isValid(*fs))
 }
 
+func TestMessageExportSettingsIsValidEml(t *testing.T) {
+	fs := &FileSettings{
+		DriverName: NewString("foo"), // bypass file location check
+	}
+	mes := &MessageExportSettings{
+		EnableExport:        NewBool(true),
+		ExportFormat:        NewString(COMPLIANCE_EXPORT_TYPE_EML),
+		ExportFromTimestamp: NewInt64(0),
+		DailyRunTime:        NewString("15:04"),
+		BatchSize:           NewInt(100),
+	}
+
+	// should pass because everything is valid
+	require.Nil(t, mes.isValid(*fs))
+}
+
 func TestMessageExportSettingsIsValidGlobalRelaySettingsMissing(t *testing.T) {
 	fs := &FileSettings{
 		DriverName: NewString("foo"), // bypass file location check
@@ -882,3 +951,72 @@ func TestLdapSettingsIsValid(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenIdSettingsIsValid(t *testing.T) {
+	for _, test := range []struct {
+		Name        string
+		Modify      func(s *OpenIdSettings)
+		ExpectError bool
+	}{
+		{
+			Name:        "disabled",
+			Modify:      func(s *OpenIdSettings) {},
+			ExpectError: false,
+		},
+		{
+			Name: "enabled",
+			Modify: func(s *OpenIdSettings) {
+				s.Enable = NewBool(true)
+			},
+			ExpectError: false,
+		},
+		{
+			Name: "missing id",
+			Modify: func(s *OpenIdSettings) {
+				s.Enable = NewBool(true)
+				s.Id = NewString("")
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "invalid discovery endpoint",
+			Modify: func(s *OpenIdSettings) {
+				s.Enable = NewBool(true)
+				s.DiscoveryEndpoint = NewString("keycloak.example.com")
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "scope without openid",
+			Modify: func(s *OpenIdSettings) {
+				s.Enable = NewBool(true)
+				s.Scope = NewString("openidconnect profile email")
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "missing email attribute",
+			Modify: func(s *OpenIdSettings) {
+				s.Enable = NewBool(true)
+				s.EmailAttribute = NewString("")
+			},
+			ExpectError: true,
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			s := &OpenIdSettings{
+				Id:                NewString("mattermost"),
+				DiscoveryEndpoint: NewString("https://keycloak.example.com/auth/realms/example"),
+			}
+			s.SetDefaults()
+			test.Modify(s)
+
+			err := s.isValid()
+			if test.ExpectError {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
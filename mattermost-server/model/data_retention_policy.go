@@ -6,6 +6,11 @@ package model
 import (
 	"encoding/json"
 	"io"
+	"net/http"
+)
+
+const (
+	DATA_RETENTION_POLICY_MAX_RETENTION_DAYS = 365 * 100
 )
 
 type DataRetentionPolicy struct {
@@ -15,6 +20,27 @@ type DataRetentionPolicy struct {
 	FileRetentionCutoff    int64 `json:"file_retention_cutoff"`
 }
 
+// TeamRetentionPolicy overrides the global retention periods for every channel of a team that
+// doesn't have a ChannelRetentionPolicy of its own. A retention period of 0 days means that the
+// corresponding data is kept forever.
+type TeamRetentionPolicy struct {
+	TeamId               string `json:"team_id"`
+	MessageRetentionDays int    `json:"message_retention_days"`
+	FileRetentionDays    int    `json:"file_retention_days"`
+	CreateAt             int64  `json:"create_at"`
+	UpdateAt             int64  `json:"update_at"`
+}
+
+// ChannelRetentionPolicy overrides both the global and the team retention periods for a single
+// channel. A retention period of 0 days means that the corresponding data is kept forever.
+type ChannelRetentionPolicy struct {
+	ChannelId            string `json:"channel_id"`
+	MessageRetentionDays int    `json:"message_retention_days"`
+	FileRetentionDays    int    `json:"file_retention_days"`
+	CreateAt             int64  `json:"create_at"`
+	UpdateAt             int64  `json:"update_at"`
+}
+
 func (me *DataRetentionPolicy) ToJson() string {
 	b, _ := json.Marshal(me)
 	return string(b)
@@ -25,3 +51,97 @@ func DataRetentionPolicyFromJson(data io.Reader) *DataRetentionPolicy {
 	json.NewDecoder(data).Decode(&me)
 	return me
 }
+
+func (p *TeamRetentionPolicy) IsValid() *AppError {
+	if !IsValidId(p.TeamId) {
+		return NewAppError("TeamRetentionPolicy.IsValid", "model.data_retention_policy.is_valid.team_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !isValidRetentionDays(p.MessageRetentionDays) {
+		return NewAppError("TeamRetentionPolicy.IsValid", "model.data_retention_policy.is_valid.message_retention_days.app_error", nil, "team_id="+p.TeamId, http.StatusBadRequest)
+	}
+
+	if !isValidRetentionDays(p.FileRetentionDays) {
+		return NewAppError("TeamRetentionPolicy.IsValid", "model.data_retention_policy.is_valid.file_retention_days.app_error", nil, "team_id="+p.TeamId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (p *TeamRetentionPolicy) PreSave() {
+	if p.CreateAt == 0 {
+		p.CreateAt = GetMillis()
+	}
+	p.UpdateAt = GetMillis()
+}
+
+func (p *TeamRetentionPolicy) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func TeamRetentionPolicyFromJson(data io.Reader) *TeamRetentionPolicy {
+	var p *TeamRetentionPolicy
+	json.NewDecoder(data).Decode(&p)
+	return p
+}
+
+func TeamRetentionPolicyListToJson(policies []*TeamRetentionPolicy) string {
+	b, _ := json.Marshal(policies)
+	return string(b)
+}
+
+func TeamRetentionPolicyListFromJson(data io.Reader) []*TeamRetentionPolicy {
+	var policies []*TeamRetentionPolicy
+	json.NewDecoder(data).Decode(&policies)
+	return policies
+}
+
+func (p *ChannelRetentionPolicy) IsValid() *AppError {
+	if !IsValidId(p.ChannelId) {
+		return NewAppError("ChannelRetentionPolicy.IsValid", "model.data_retention_policy.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !isValidRetentionDays(p.MessageRetentionDays) {
+		return NewAppError("ChannelRetentionPolicy.IsValid", "model.data_retention_policy.is_valid.message_retention_days.app_error", nil, "channel_id="+p.ChannelId, http.StatusBadRequest)
+	}
+
+	if !isValidRetentionDays(p.FileRetentionDays) {
+		return NewAppError("ChannelRetentionPolicy.IsValid", "model.data_retention_policy.is_valid.file_retention_days.app_error", nil, "channel_id="+p.ChannelId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (p *ChannelRetentionPolicy) PreSave() {
+	if p.CreateAt == 0 {
+		p.CreateAt = GetMillis()
+	}
+	p.UpdateAt = GetMillis()
+}
+
+func (p *ChannelRetentionPolicy) ToJson() string {
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+func ChannelRetentionPolicyFromJson(data io.Reader) *ChannelRetentionPolicy {
+	var p *ChannelRetentionPolicy
+	json.NewDecoder(data).Decode(&p)
+	return p
+}
+
+func ChannelRetentionPolicyListToJson(policies []*ChannelRetentionPolicy) string {
+	b, _ := json.Marshal(policies)
+	return string(b)
+}
+
+func ChannelRetentionPolicyListFromJson(data io.Reader) []*ChannelRetentionPolicy {
+	var policies []*ChannelRetentionPolicy
+	json.NewDecoder(data).Decode(&policies)
+	return policies
+}
+
+func isValidRetentionDays(days int) bool {
+	return days >= 0 && days <= DATA_RETENTION_POLICY_MAX_RETENTION_DAYS
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2017-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamRetentionPolicyIsValid(t *testing.T) {
+	policy := TeamRetentionPolicy{TeamId: NewId(), MessageRetentionDays: 30, FileRetentionDays: 0}
+	assert.Nil(t, policy.IsValid())
+
+	policy.TeamId = "junk"
+	assert.NotNil(t, policy.IsValid())
+
+	policy.TeamId = NewId()
+	policy.MessageRetentionDays = -1
+	assert.NotNil(t, policy.IsValid())
+
+	policy.MessageRetentionDays = 30
+	policy.FileRetentionDays = DATA_RETENTION_POLICY_MAX_RETENTION_DAYS + 1
+	assert.NotNil(t, policy.IsValid())
+}
+
+func TestChannelRetentionPolicyIsValid(t *testing.T) {
+	policy := ChannelRetentionPolicy{ChannelId: NewId(), MessageRetentionDays: 0, FileRetentionDays: 7}
+	assert.Nil(t, policy.IsValid())
+
+	policy.ChannelId = ""
+	assert.NotNil(t, policy.IsValid())
+
+	policy.ChannelId = NewId()
+	policy.FileRetentionDays = -7
+	assert.NotNil(t, policy.IsValid())
+}
+
+func TestRetentionPolicyPreSave(t *testing.T) {
+	policy := ChannelRetentionPolicy{ChannelId: NewId()}
+	policy.PreSave()
+	require.NotZero(t, policy.CreateAt)
+	assert.Equal(t, policy.CreateAt, policy.UpdateAt)
+
+	createAt := policy.CreateAt
+	policy.PreSave()
+	assert.Equal(t, createAt, policy.CreateAt)
+}
+
+func TestRetentionPolicyJson(t *testing.T) {
+	teamPolicy := &TeamRetentionPolicy{TeamId: NewId(), MessageRetentionDays: 10, FileRetentionDays: 20}
+	assert.Equal(t, teamPolicy, TeamRetentionPolicyFromJson(strings.NewReader(teamPolicy.ToJson())))
+
+	teamPolicies := []*TeamRetentionPolicy{teamPolicy}
+	assert.Equal(t, teamPolicies, TeamRetentionPolicyListFromJson(strings.NewReader(TeamRetentionPolicyListToJson(teamPolicies))))
+
+	channelPolicy := &ChannelRetentionPolicy{ChannelId: NewId(), MessageRetentionDays: 1, FileRetentionDays: 2}
+	assert.Equal(t, channelPolicy, ChannelRetentionPolicyFromJson(strings.NewReader(channelPolicy.ToJson())))
+
+	channelPolicies := []*ChannelRetentionPolicy{channelPolicy}
+	assert.Equal(t, channelPolicies, ChannelRetentionPolicyListFromJson(strings.NewReader(ChannelRetentionPolicyListToJson(channelPolicies))))
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+// Draft is a message being written by a user, kept on the server so that it follows them from one
+// device to another. A user has at most one draft per channel, and one per thread of that channel.
+type Draft struct {
+	CreateAt  int64           `json:"create_at"`
+	UpdateAt  int64           `json:"update_at"`
+	UserId    string          `json:"user_id"`
+	ChannelId string          `json:"channel_id"`
+	RootId    string          `json:"root_id"`
+	Message   string          `json:"message"`
+	Props     StringInterface `json:"props"`
+	FileIds   StringArray     `json:"file_ids,omitempty"`
+}
+
+func (o *Draft) IsValid(maxDraftSize int) *AppError {
+	if o.CreateAt == 0 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.create_at.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.update_at.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if !IsValidId(o.UserId) {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.user_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(o.ChannelId) {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.channel_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !(IsValidId(o.RootId) || len(o.RootId) == 0) {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.root_id.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Message) > maxDraftSize {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.msg.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if len(o.Message) == 0 && len(o.FileIds) == 0 {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.empty.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.FileIds)) > POST_FILEIDS_MAX_RUNES {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.file_ids.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(StringInterfaceToJson(o.Props)) > POST_PROPS_MAX_USER_RUNES {
+		return NewAppError("Draft.IsValid", "model.draft.is_valid.props.app_error", nil, "channel_id="+o.ChannelId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *Draft) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+	o.UpdateAt = GetMillis()
+
+	if o.Props == nil {
+		o.Props = make(map[string]interface{})
+	}
+
+	if o.FileIds == nil {
+		o.FileIds = []string{}
+	}
+}
+
+func (o *Draft) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func DraftFromJson(data io.Reader) *Draft {
+	var o *Draft
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func DraftListToJson(list []*Draft) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func DraftListFromJson(data io.Reader) []*Draft {
+	var list []*Draft
+	json.NewDecoder(data).Decode(&list)
+	return list
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraftIsValid(t *testing.T) {
+	o := Draft{UserId: NewId(), ChannelId: NewId(), Message: "hello"}
+	o.PreSave()
+	require.Nil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.RootId = "junk"
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.RootId = NewId()
+	assert.Nil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.Message = strings.Repeat("0", POST_MESSAGE_MAX_RUNES_V2+1)
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.Message = ""
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.FileIds = StringArray{NewId()}
+	assert.Nil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.UserId = ""
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+}
+
+func TestDraftJson(t *testing.T) {
+	o := Draft{UserId: NewId(), ChannelId: NewId(), Message: "hello"}
+	ro := DraftFromJson(strings.NewReader(o.ToJson()))
+	assert.Equal(t, o.ChannelId, ro.ChannelId)
+	assert.Equal(t, o.Message, ro.Message)
+
+	list := DraftListFromJson(strings.NewReader(DraftListToJson([]*Draft{&o})))
+	require.Len(t, list, 1)
+	assert.Equal(t, o.UserId, list[0].UserId)
+}
@@ -24,6 +24,7 @@ const (
 	POST_ACTION_TYPE_BUTTON                         = "button"
 	POST_ACTION_TYPE_SELECT                         = "select"
 	INTERACTIVE_DIALOG_TRIGGER_TIMEOUT_MILLISECONDS = 3000
+	PLUGIN_INTEGRATION_URL_SCHEME                   = "plugin"
 )
 
 var PostActionRetainPropKeys = []string{"from_webhook", "override_username", "override_icon_url"}
@@ -138,6 +139,7 @@ func (p *PostAction) Equals(input *PostAction) bool {
 type PostActionCookie struct {
 	Type        string                 `json:"type,omitempty"`
 	PostId      string                 `json:"post_id,omitempty"`
+	UserId      string                 `json:"user_id,omitempty"`
 	RootPostId  string                 `json:"root_post_id,omitempty"`
 	ChannelId   string                 `json:"channel_id,omitempty"`
 	DataSource  string                 `json:"data_source,omitempty"`
@@ -224,6 +226,30 @@ type SubmitDialogResponse struct {
 	Errors map[string]string `json:"errors,omitempty"`
 }
 
+// PluginIntegrationURL returns the integration URL of interactive messages and dialogs handled
+// by the given plugin in-process, through its PostActionTriggered and DialogSubmitted hooks,
+// instead of over HTTP.
+func PluginIntegrationURL(pluginId string) string {
+	return PLUGIN_INTEGRATION_URL_SCHEME + "://" + pluginId
+}
+
+// PluginIdFromIntegrationURL returns the id of the plugin handling the given integration URL, or
+// an empty string if it's not a plugin integration URL. The query of the URL, such as the signature
+// of a dialog, is ignored.
+func PluginIdFromIntegrationURL(rawURL string) string {
+	prefix := PLUGIN_INTEGRATION_URL_SCHEME + "://"
+	if !strings.HasPrefix(rawURL, prefix) {
+		return ""
+	}
+
+	pluginId := strings.TrimPrefix(rawURL, prefix)
+	if i := strings.Index(pluginId, "?"); i >= 0 {
+		pluginId = pluginId[:i]
+	}
+
+	return pluginId
+}
+
 func GenerateTriggerId(userId string, s crypto.Signer) (string, string, *AppError) {
 	clientTriggerId := NewId()
 	triggerData := strings.Join([]string{clientTriggerId, userId, strconv.FormatInt(GetMillis(), 10)}, ":") + ":"
@@ -425,6 +451,7 @@ func AddPostActionCookies(o *Post, secret []byte) *Post {
 			}
 
 			c.PostId = p.Id
+			c.UserId = p.UserId
 			if p.RootId == "" {
 				c.RootPostId = p.Id
 			} else {
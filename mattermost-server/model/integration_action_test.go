@@ -82,6 +82,16 @@ func TestTriggerIdDecodeAndVerification(t *testing.T) {
 	})
 }
 
+func TestPluginIntegrationURL(t *testing.T) {
+	url := PluginIntegrationURL("com.example.plugin")
+	assert.Equal(t, "plugin://com.example.plugin", url)
+	assert.Equal(t, "com.example.plugin", PluginIdFromIntegrationURL(url))
+	assert.Equal(t, "com.example.plugin", PluginIdFromIntegrationURL(url+"?signature=abc"))
+
+	assert.Equal(t, "", PluginIdFromIntegrationURL("http://localhost/plugins/com.example.plugin"))
+	assert.Equal(t, "", PluginIdFromIntegrationURL(""))
+}
+
 func TestPostActionIntegrationRequestToJson(t *testing.T) {
 	o := PostActionIntegrationRequest{UserId: NewId(), Context: StringInterface{"a": "abc"}}
 	j := o.ToJson()
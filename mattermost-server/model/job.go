@@ -18,6 +18,9 @@ const (
 	JOB_TYPE_LDAP_SYNC                      = "ldap_sync"
 	JOB_TYPE_MIGRATIONS                     = "migrations"
 	JOB_TYPE_PLUGINS                        = "plugins"
+	JOB_TYPE_PLUGIN_SCHEDULED_JOB           = "plugin_scheduled_job"
+	JOB_TYPE_SCHEDULED_POSTS                = "scheduled_posts"
+	JOB_TYPE_POST_REMINDERS                 = "post_reminders"
 
 	JOB_STATUS_PENDING          = "pending"
 	JOB_STATUS_IN_PROGRESS      = "in_progress"
@@ -56,6 +59,9 @@ func (j *Job) IsValid() *AppError {
 	case JOB_TYPE_MESSAGE_EXPORT:
 	case JOB_TYPE_MIGRATIONS:
 	case JOB_TYPE_PLUGINS:
+	case JOB_TYPE_PLUGIN_SCHEDULED_JOB:
+	case JOB_TYPE_SCHEDULED_POSTS:
+	case JOB_TYPE_POST_REMINDERS:
 	default:
 		return NewAppError("Job.IsValid", "model.job.is_valid.type.app_error", nil, "id="+j.Id, http.StatusBadRequest)
 	}
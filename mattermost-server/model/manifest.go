@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -93,12 +94,17 @@ type PluginSettingsSchema struct {
 //      "description": "This is my plugin",
 //      "version": "0.1.0",
 //      "min_server_version": "5.6.0",
+//      "requires": [{
+//        "id": "com.mycompany.identity",
+//        "version": ">=1.2.0"
+//      }],
 //      "server": {
 //        "executables": {
 //          "linux-amd64": "server/dist/plugin-linux-amd64",
 //          "darwin-amd64": "server/dist/plugin-darwin-amd64",
 //          "windows-amd64": "server/dist/plugin-windows-amd64.exe"
-//        }
+//        },
+//        "exports": ["getIdentity"]
 //      },
 //      "webapp": {
 //          "bundle_path": "webapp/dist/main.js"
@@ -138,6 +144,12 @@ type Manifest struct {
 	// Minimum server version: 5.6
 	MinServerVersion string `json:"min_server_version,omitempty" yaml:"min_server_version,omitempty"`
 
+	// The other plugins your plugin requires. They are activated before and deactivated after your
+	// plugin, which won't be activated unless all of them are active.
+	//
+	// Minimum server version: 5.12
+	Requires []*ManifestDependency `json:"requires,omitempty" yaml:"requires,omitempty"`
+
 	// Server defines the server-side portion of your plugin.
 	Server *ManifestServer `json:"server,omitempty" yaml:"server,omitempty"`
 
@@ -155,6 +167,15 @@ type Manifest struct {
 	Props map[string]interface{} `json:"props,omitempty" yaml:"props,omitempty"`
 }
 
+type ManifestDependency struct {
+	// The id of the required plugin.
+	Id string `json:"id" yaml:"id"`
+
+	// The versions of the required plugin that are supported, as a range of semantic versions such
+	// as ">=1.2.0 <2.0.0". Any version is accepted if empty.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
 type ManifestServer struct {
 	// Executables are the paths to your executable binaries, specifying multiple entry points
 	// for different platforms when bundled together in a single plugin.
@@ -168,6 +189,12 @@ type ManifestServer struct {
 	// If your plugin is compiled for multiple platforms, consider bundling them together
 	// and using the Executables field instead.
 	Executable string `json:"executable" yaml:"executable"`
+
+	// Exports are the names of the methods other plugins may invoke via the CallPlugin API. Calls
+	// are delivered to the OnPluginCall hook of your plugin.
+	//
+	// Minimum server version: 5.12
+	Exports []string `json:"exports,omitempty" yaml:"exports,omitempty"`
 }
 
 type ManifestExecutables struct {
@@ -272,6 +299,101 @@ func (m *Manifest) HasWebapp() bool {
 	return m.Webapp != nil
 }
 
+// ExportsMethod returns true if other plugins may call the given method of the plugin.
+func (m *Manifest) ExportsMethod(method string) bool {
+	server := m.Server
+
+	// Support the deprecated backend parameter.
+	if server == nil {
+		server = m.Backend
+	}
+
+	if server == nil {
+		return false
+	}
+
+	for _, export := range server.Exports {
+		if export == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsValid checks that the manifest is well-formed. The version ranges of the required plugins must
+// parse and a plugin may require neither itself nor the same plugin twice.
+func (m *Manifest) IsValid() *AppError {
+	if m.Id == "" {
+		return NewAppError("Manifest.IsValid", "model.manifest.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if m.MinServerVersion != "" {
+		if _, err := semver.Parse(m.MinServerVersion); err != nil {
+			return NewAppError("Manifest.IsValid", "model.manifest.is_valid.min_server_version.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	required := map[string]bool{}
+	for _, dependency := range m.Requires {
+		if dependency == nil || dependency.Id == "" {
+			return NewAppError("Manifest.IsValid", "model.manifest.is_valid.requires.app_error", map[string]interface{}{"PluginId": ""}, "missing id", http.StatusBadRequest)
+		}
+
+		params := map[string]interface{}{"PluginId": dependency.Id}
+		if strings.ToLower(dependency.Id) == strings.ToLower(m.Id) {
+			return NewAppError("Manifest.IsValid", "model.manifest.is_valid.requires.app_error", params, "a plugin can't require itself", http.StatusBadRequest)
+		}
+
+		if required[strings.ToLower(dependency.Id)] {
+			return NewAppError("Manifest.IsValid", "model.manifest.is_valid.requires.app_error", params, "required more than once", http.StatusBadRequest)
+		}
+		required[strings.ToLower(dependency.Id)] = true
+
+		if dependency.Version != "" {
+			if _, err := semver.ParseRange(dependency.Version); err != nil {
+				return NewAppError("Manifest.IsValid", "model.manifest.is_valid.requires.app_error", params, err.Error(), http.StatusBadRequest)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RequiresPlugin returns true if the plugin depends on the plugin with the given id.
+func (m *Manifest) RequiresPlugin(id string) bool {
+	for _, dependency := range m.Requires {
+		if dependency != nil && strings.ToLower(dependency.Id) == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Satisfies returns true if the plugin can be used where the given dependency is required.
+func (m *Manifest) Satisfies(dependency *ManifestDependency) bool {
+	if m.Id != strings.ToLower(dependency.Id) {
+		return false
+	}
+
+	if dependency.Version == "" {
+		return true
+	}
+
+	versionRange, err := semver.ParseRange(dependency.Version)
+	if err != nil {
+		return false
+	}
+
+	version, err := semver.ParseTolerant(m.Version)
+	if err != nil {
+		return false
+	}
+
+	return versionRange(version)
+}
+
 func (m *Manifest) MeetMinServerVersion(serverVersion string) (bool, error) {
 	minServerVersion, err := semver.Parse(m.MinServerVersion)
 	if err != nil {
@@ -65,6 +65,9 @@ func TestManifestUnmarshal(t *testing.T) {
 	expected := Manifest{
 		Id:               "theid",
 		MinServerVersion: "5.6.0",
+		Requires: []*ManifestDependency{
+			{Id: "therequiredplugin", Version: ">=1.0.0"},
+		},
 		Server: &ManifestServer{
 			Executable: "theexecutable",
 			Executables: &ManifestExecutables{
@@ -72,6 +75,7 @@ func TestManifestUnmarshal(t *testing.T) {
 				DarwinAmd64:  "theexecutable-darwin-amd64",
 				WindowsAmd64: "theexecutable-windows-amd64",
 			},
+			Exports: []string{"theexport"},
 		},
 		Webapp: &ManifestWebapp{
 			BundlePath: "thebundlepath",
@@ -103,12 +107,17 @@ func TestManifestUnmarshal(t *testing.T) {
 	require.NoError(t, yaml.Unmarshal([]byte(`
 id: theid
 min_server_version: 5.6.0
+requires:
+    - id: therequiredplugin
+      version: ">=1.0.0"
 server:
     executable: theexecutable
     executables:
           linux-amd64: theexecutable-linux-amd64
           darwin-amd64: theexecutable-darwin-amd64
           windows-amd64: theexecutable-windows-amd64
+    exports:
+        - theexport
 webapp:
     bundle_path: thebundlepath
 settings_schema:
@@ -132,13 +141,17 @@ settings_schema:
 	require.NoError(t, json.Unmarshal([]byte(`{
 	"id": "theid",
   "min_server_version": "5.6.0",
+	"requires": [
+		{"id": "therequiredplugin", "version": ">=1.0.0"}
+	],
 	"server": {
 		"executable": "theexecutable",
 		"executables": {
 			"linux-amd64": "theexecutable-linux-amd64",
 			"darwin-amd64": "theexecutable-darwin-amd64",
 			"windows-amd64": "theexecutable-windows-amd64"
-		}
+		},
+		"exports": ["theexport"]
 	},
 	"webapp": {
 		"bundle_path": "thebundlepath"
@@ -626,6 +639,60 @@ func TestManifestHasWebapp(t *testing.T) {
 	}
 }
 
+func TestManifestExportsMethod(t *testing.T) {
+	testCases := []struct {
+		Description string
+		Manifest    *Manifest
+		Expected    bool
+	}{
+		{
+			"no server",
+			&Manifest{},
+			false,
+		},
+		{
+			"no exports",
+			&Manifest{
+				Server: &ManifestServer{},
+			},
+			false,
+		},
+		{
+			"method exported",
+			&Manifest{
+				Server: &ManifestServer{
+					Exports: []string{"other", "method"},
+				},
+			},
+			true,
+		},
+		{
+			"method not exported",
+			&Manifest{
+				Server: &ManifestServer{
+					Exports: []string{"other"},
+				},
+			},
+			false,
+		},
+		{
+			"method exported by deprecated backend",
+			&Manifest{
+				Backend: &ManifestServer{
+					Exports: []string{"method"},
+				},
+			},
+			true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, testCase.Manifest.ExportsMethod("method"))
+		})
+	}
+}
+
 func TestManifestMeetMinServerVersion(t *testing.T) {
 	for name, test := range map[string]struct {
 		MinServerVersion string
@@ -675,3 +742,88 @@ func TestManifestMeetMinServerVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestManifestIsValid(t *testing.T) {
+	testCases := []struct {
+		Description   string
+		Manifest      *Manifest
+		ExpectedError string
+	}{
+		{
+			"valid",
+			&Manifest{
+				Id:               "theid",
+				MinServerVersion: "5.6.0",
+				Requires: []*ManifestDependency{
+					{Id: "other"},
+					{Id: "another", Version: ">=1.0.0 <2.0.0"},
+				},
+			},
+			"",
+		},
+		{
+			"missing id",
+			&Manifest{},
+			"model.manifest.is_valid.id.app_error",
+		},
+		{
+			"invalid min server version",
+			&Manifest{Id: "theid", MinServerVersion: "five"},
+			"model.manifest.is_valid.min_server_version.app_error",
+		},
+		{
+			"required plugin without id",
+			&Manifest{Id: "theid", Requires: []*ManifestDependency{{Version: "1.0.0"}}},
+			"model.manifest.is_valid.requires.app_error",
+		},
+		{
+			"requires itself",
+			&Manifest{Id: "theid", Requires: []*ManifestDependency{{Id: "TheId"}}},
+			"model.manifest.is_valid.requires.app_error",
+		},
+		{
+			"required twice",
+			&Manifest{Id: "theid", Requires: []*ManifestDependency{{Id: "other"}, {Id: "other", Version: "1.0.0"}}},
+			"model.manifest.is_valid.requires.app_error",
+		},
+		{
+			"invalid version range",
+			&Manifest{Id: "theid", Requires: []*ManifestDependency{{Id: "other", Version: ">=one"}}},
+			"model.manifest.is_valid.requires.app_error",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			err := testCase.Manifest.IsValid()
+			if testCase.ExpectedError == "" {
+				assert.Nil(t, err)
+			} else {
+				require.NotNil(t, err)
+				assert.Equal(t, testCase.ExpectedError, err.Id)
+			}
+		})
+	}
+}
+
+func TestManifestSatisfies(t *testing.T) {
+	manifest := &Manifest{Id: "theid", Version: "1.2.3"}
+
+	assert.True(t, manifest.Satisfies(&ManifestDependency{Id: "theid"}))
+	assert.True(t, manifest.Satisfies(&ManifestDependency{Id: "TheId", Version: ">=1.0.0"}))
+	assert.True(t, manifest.Satisfies(&ManifestDependency{Id: "theid", Version: ">=1.0.0 <2.0.0"}))
+	assert.False(t, manifest.Satisfies(&ManifestDependency{Id: "theid", Version: ">=1.3.0"}))
+	assert.False(t, manifest.Satisfies(&ManifestDependency{Id: "other"}))
+	assert.False(t, manifest.Satisfies(&ManifestDependency{Id: "theid", Version: "invalid"}))
+
+	assert.True(t, (&Manifest{Id: "theid", Version: "v1.2"}).Satisfies(&ManifestDependency{Id: "theid", Version: ">=1.2.0"}))
+	assert.False(t, (&Manifest{Id: "theid"}).Satisfies(&ManifestDependency{Id: "theid", Version: ">=1.2.0"}))
+}
+
+func TestManifestRequiresPlugin(t *testing.T) {
+	manifest := &Manifest{Id: "theid", Requires: []*ManifestDependency{{Id: "Other"}}}
+
+	assert.True(t, manifest.RequiresPlugin("other"))
+	assert.False(t, manifest.RequiresPlugin("theid"))
+	assert.False(t, (&Manifest{}).RequiresPlugin("other"))
+}
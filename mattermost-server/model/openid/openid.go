@@ -0,0 +1,128 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+type OpenIdProvider struct {
+}
+
+// OpenIdUser is the user described by the claims of an OpenID Connect identity provider, once
+// mapped according to the OpenIdSettings.
+type OpenIdUser struct {
+	Id        string `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func init() {
+	provider := &OpenIdProvider{}
+	einterfaces.RegisterOauthProvider(model.USER_AUTH_SERVICE_OPENID, provider)
+}
+
+// UserFromClaims maps the claims of an ID token or of the user info endpoint to a user. Attributes
+// may refer to nested claims using dots, such as "address.country".
+func UserFromClaims(claims map[string]interface{}, settings *model.OpenIdSettings) *OpenIdUser {
+	user := &OpenIdUser{
+		Id:        getClaim(claims, *settings.IdAttribute),
+		Username:  getClaim(claims, *settings.UsernameAttribute),
+		Email:     getClaim(claims, *settings.EmailAttribute),
+		FirstName: getClaim(claims, *settings.FirstNameAttribute),
+		LastName:  getClaim(claims, *settings.LastNameAttribute),
+	}
+
+	if user.Username == "" {
+		user.Username = strings.Split(user.Email, "@")[0]
+	}
+
+	return user
+}
+
+func getClaim(claims map[string]interface{}, attribute string) string {
+	if attribute == "" {
+		return ""
+	}
+
+	var value interface{} = claims
+	for _, name := range strings.Split(attribute, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		value = object[name]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func userFromOpenIdUser(oiu *OpenIdUser) *model.User {
+	user := &model.User{}
+	user.Username = model.CleanUsername(oiu.Username)
+	user.FirstName = oiu.FirstName
+	user.LastName = oiu.LastName
+	user.Email = strings.ToLower(oiu.Email)
+	authData := oiu.Id
+	user.AuthData = &authData
+	user.AuthService = model.USER_AUTH_SERVICE_OPENID
+
+	return user
+}
+
+func openIdUserFromJson(data io.Reader) *OpenIdUser {
+	var oiu *OpenIdUser
+	if err := json.NewDecoder(data).Decode(&oiu); err != nil {
+		return nil
+	}
+	return oiu
+}
+
+func (oiu *OpenIdUser) ToJson() string {
+	b, _ := json.Marshal(oiu)
+	return string(b)
+}
+
+func (oiu *OpenIdUser) IsValid() bool {
+	if oiu == nil {
+		return false
+	}
+
+	if len(oiu.Id) == 0 {
+		return false
+	}
+
+	if len(oiu.Email) == 0 {
+		return false
+	}
+
+	return true
+}
+
+func (m *OpenIdProvider) GetUserFromJson(data io.Reader) *model.User {
+	oiu := openIdUserFromJson(data)
+	if oiu.IsValid() {
+		return userFromOpenIdUser(oiu)
+	}
+
+	return &model.User{}
+}
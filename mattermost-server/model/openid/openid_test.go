@@ -0,0 +1,63 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package oauthopenid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestUserFromClaims(t *testing.T) {
+	settings := &model.OpenIdSettings{}
+	settings.SetDefaults()
+
+	claims := map[string]interface{}{
+		"sub":                "1234",
+		"preferred_username": "jdoe",
+		"email":              "John.Doe@example.com",
+		"given_name":         "John",
+		"family_name":        "Doe",
+		"employee":           map[string]interface{}{"number": float64(42)},
+	}
+
+	t.Run("default attributes", func(t *testing.T) {
+		user := UserFromClaims(claims, settings)
+		assert.Equal(t, &OpenIdUser{Id: "1234", Username: "jdoe", Email: "John.Doe@example.com", FirstName: "John", LastName: "Doe"}, user)
+		assert.True(t, user.IsValid())
+	})
+
+	t.Run("nested attribute", func(t *testing.T) {
+		settings := *settings
+		settings.IdAttribute = model.NewString("employee.number")
+		assert.Equal(t, "42", UserFromClaims(claims, &settings).Id)
+
+		settings.IdAttribute = model.NewString("employee.missing")
+		assert.False(t, UserFromClaims(claims, &settings).IsValid())
+	})
+
+	t.Run("username falls back to email", func(t *testing.T) {
+		settings := *settings
+		settings.UsernameAttribute = model.NewString("")
+		assert.Equal(t, "John.Doe", UserFromClaims(claims, &settings).Username)
+	})
+}
+
+func TestGetUserFromJson(t *testing.T) {
+	provider := &OpenIdProvider{}
+
+	oiu := &OpenIdUser{Id: "1234", Username: "John.Doe", Email: "John.Doe@example.com", FirstName: "John"}
+	user := provider.GetUserFromJson(strings.NewReader(oiu.ToJson()))
+	assert.Equal(t, "john.doe", user.Username)
+	assert.Equal(t, "john.doe@example.com", user.Email)
+	assert.Equal(t, "1234", *user.AuthData)
+	assert.Equal(t, model.USER_AUTH_SERVICE_OPENID, user.AuthService)
+
+	user = provider.GetUserFromJson(strings.NewReader(`{"id": "1234"}`))
+	assert.Equal(t, "", user.Email)
+	assert.Nil(t, user.AuthData)
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// PluginIndexEntry describes a plugin bundle kept in the file store.
+type PluginIndexEntry struct {
+	Id      string `json:"id"`
+	Version string `json:"version"`
+
+	// Checksum is the hex encoded SHA-256 digest of the bundle, used by each server to tell
+	// whether its local copy of the plugin is up to date.
+	Checksum string `json:"checksum"`
+
+	// Signed is true if a detached signature was uploaded along with the bundle.
+	Signed bool `json:"signed"`
+
+	UpdateAt int64 `json:"update_at"`
+
+	// DeleteAt is the time the plugin was removed from the file store, so that servers that missed
+	// the removal also remove their local copy.
+	DeleteAt int64 `json:"delete_at"`
+}
+
+func (entry *PluginIndexEntry) ToJson() string {
+	b, _ := json.Marshal(entry)
+	return string(b)
+}
+
+func PluginIndexEntryFromJson(data io.Reader) *PluginIndexEntry {
+	var entry *PluginIndexEntry
+	if err := json.NewDecoder(data).Decode(&entry); err != nil || entry == nil || entry.Id == "" {
+		return nil
+	}
+
+	return entry
+}
+
+// PluginIndex lists the plugin bundles kept in the file store, from which every server in a
+// cluster installs its plugins.
+type PluginIndex struct {
+	Plugins map[string]*PluginIndexEntry `json:"plugins"`
+
+	// RemovedPlugins maps the ids of the plugins removed from the file store to the time they were
+	// removed.
+	RemovedPlugins map[string]int64 `json:"removed_plugins"`
+}
+
+func NewPluginIndex() *PluginIndex {
+	return &PluginIndex{
+		Plugins:        map[string]*PluginIndexEntry{},
+		RemovedPlugins: map[string]int64{},
+	}
+}
+
+// Add records the bundle of a plugin, or its removal if the entry is deleted.
+func (index *PluginIndex) Add(entry *PluginIndexEntry) {
+	if entry.DeleteAt != 0 {
+		delete(index.Plugins, entry.Id)
+		index.RemovedPlugins[entry.Id] = entry.DeleteAt
+		return
+	}
+
+	index.Plugins[entry.Id] = entry
+	delete(index.RemovedPlugins, entry.Id)
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginIndex(t *testing.T) {
+	index := NewPluginIndex()
+
+	index.Add(&PluginIndexEntry{Id: "a", Version: "1.0.0", Checksum: "abc"})
+	index.Add(&PluginIndexEntry{Id: "b", Version: "1.0.0", Checksum: "def"})
+	index.Add(&PluginIndexEntry{Id: "a", DeleteAt: 1})
+
+	assert.Nil(t, index.Plugins["a"])
+	assert.Equal(t, int64(1), index.RemovedPlugins["a"])
+	assert.Equal(t, "def", index.Plugins["b"].Checksum)
+
+	index.Add(&PluginIndexEntry{Id: "a", Version: "1.1.0", Checksum: "ghi"})
+	assert.Equal(t, "1.1.0", index.Plugins["a"].Version)
+	assert.NotContains(t, index.RemovedPlugins, "a")
+}
+
+func TestPluginIndexEntryJson(t *testing.T) {
+	entry := &PluginIndexEntry{Id: "a", Version: "1.0.0", Checksum: "abc", Signed: true, UpdateAt: 1}
+
+	newEntry := PluginIndexEntryFromJson(strings.NewReader(entry.ToJson()))
+	require.NotNil(t, newEntry)
+	assert.Equal(t, entry, newEntry)
+
+	assert.Nil(t, PluginIndexEntryFromJson(strings.NewReader("{}")))
+	assert.Nil(t, PluginIndexEntryFromJson(strings.NewReader("junk")))
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+// MarketplacePlugin describes a version of a plugin listed in a marketplace index.
+type MarketplacePlugin struct {
+	Id          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+
+	// DownloadUrl is the HTTPS URL of the plugin bundle.
+	DownloadUrl string `json:"download_url"`
+
+	// SignatureUrl is the HTTPS URL of the detached signature of the bundle, if it is signed.
+	SignatureUrl string `json:"signature_url,omitempty"`
+
+	// Checksum is the hex encoded SHA-256 digest of the bundle.
+	Checksum string `json:"checksum"`
+
+	// MinServerVersion is the minimum server version supported by this version of the plugin.
+	MinServerVersion string `json:"min_server_version,omitempty"`
+}
+
+// MarketplaceIndex lists the plugins available for installation, possibly in several versions.
+type MarketplaceIndex struct {
+	Plugins []*MarketplacePlugin `json:"plugins"`
+}
+
+// MarketplacePluginUpgrade describes an installed plugin for which a newer version is available.
+type MarketplacePluginUpgrade struct {
+	Id               string             `json:"id"`
+	InstalledVersion string             `json:"installed_version"`
+	Latest           *MarketplacePlugin `json:"latest"`
+}
+
+// Find returns the given version of the plugin with the given id, or its latest supported version
+// if the version is empty.
+func (index *MarketplaceIndex) Find(id, version, serverVersion string) *MarketplacePlugin {
+	if version == "" {
+		return index.Latest(id, serverVersion)
+	}
+
+	for _, plugin := range index.Plugins {
+		if plugin != nil && strings.ToLower(plugin.Id) == strings.ToLower(id) && plugin.Version == version {
+			return plugin
+		}
+	}
+
+	return nil
+}
+
+// Latest returns the highest version of the plugin with the given id supported by the given server
+// version, or nil if there is none.
+func (index *MarketplaceIndex) Latest(id, serverVersion string) *MarketplacePlugin {
+	var latest *MarketplacePlugin
+	var latestVersion semver.Version
+
+	for _, plugin := range index.Plugins {
+		if plugin == nil || strings.ToLower(plugin.Id) != strings.ToLower(id) || !plugin.supportsServerVersion(serverVersion) {
+			continue
+		}
+
+		version, err := semver.ParseTolerant(plugin.Version)
+		if err != nil {
+			continue
+		}
+
+		if latest == nil || version.GT(latestVersion) {
+			latest = plugin
+			latestVersion = version
+		}
+	}
+
+	return latest
+}
+
+// Upgrades returns the installed plugins for which the index lists a newer version supported by the
+// given server version.
+func (index *MarketplaceIndex) Upgrades(installed []*Manifest, serverVersion string) []*MarketplacePluginUpgrade {
+	upgrades := []*MarketplacePluginUpgrade{}
+
+	for _, manifest := range installed {
+		if manifest == nil {
+			continue
+		}
+
+		installedVersion, err := semver.ParseTolerant(manifest.Version)
+		if err != nil {
+			continue
+		}
+
+		latest := index.Latest(manifest.Id, serverVersion)
+		if latest == nil {
+			continue
+		}
+
+		if latestVersion, err := semver.ParseTolerant(latest.Version); err == nil && latestVersion.GT(installedVersion) {
+			upgrades = append(upgrades, &MarketplacePluginUpgrade{
+				Id:               manifest.Id,
+				InstalledVersion: manifest.Version,
+				Latest:           latest,
+			})
+		}
+	}
+
+	return upgrades
+}
+
+func (plugin *MarketplacePlugin) supportsServerVersion(serverVersion string) bool {
+	if plugin.MinServerVersion == "" {
+		return true
+	}
+
+	minServerVersion, err := semver.ParseTolerant(plugin.MinServerVersion)
+	if err != nil {
+		return false
+	}
+
+	version, err := semver.ParseTolerant(serverVersion)
+	if err != nil {
+		return false
+	}
+
+	return version.GTE(minServerVersion)
+}
+
+func (index *MarketplaceIndex) ToJson() string {
+	b, _ := json.Marshal(index)
+	return string(b)
+}
+
+func MarketplaceIndexFromJson(data io.Reader) *MarketplaceIndex {
+	var index *MarketplaceIndex
+	json.NewDecoder(data).Decode(&index)
+	return index
+}
+
+func MarketplacePluginUpgradesToJson(upgrades []*MarketplacePluginUpgrade) string {
+	b, _ := json.Marshal(upgrades)
+	return string(b)
+}
+
+func MarketplacePluginUpgradesFromJson(data io.Reader) []*MarketplacePluginUpgrade {
+	var upgrades []*MarketplacePluginUpgrade
+	json.NewDecoder(data).Decode(&upgrades)
+	return upgrades
+}
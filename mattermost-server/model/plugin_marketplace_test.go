@@ -0,0 +1,70 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketplaceIndex(t *testing.T) {
+	index := &MarketplaceIndex{
+		Plugins: []*MarketplacePlugin{
+			{Id: "jira", Version: "2.0.0"},
+			{Id: "jira", Version: "2.1.0"},
+			{Id: "jira", Version: "3.0.0", MinServerVersion: "5.20.0"},
+			{Id: "zoom", Version: "1.0.0"},
+			{Id: "zoom", Version: "invalid"},
+			{Id: "github", Version: "0.9.0"},
+		},
+	}
+
+	t.Run("latest", func(t *testing.T) {
+		assert.Equal(t, "2.1.0", index.Latest("jira", "5.12.0").Version)
+		assert.Equal(t, "3.0.0", index.Latest("jira", "5.20.0").Version)
+		assert.Equal(t, "3.0.0", index.Latest("JIRA", "5.20.0").Version)
+		assert.Equal(t, "1.0.0", index.Latest("zoom", "5.12.0").Version)
+		assert.Nil(t, index.Latest("missing", "5.12.0"))
+	})
+
+	t.Run("find", func(t *testing.T) {
+		assert.Equal(t, "2.0.0", index.Find("jira", "2.0.0", "5.12.0").Version)
+		assert.Equal(t, "2.1.0", index.Find("jira", "", "5.12.0").Version)
+		assert.Nil(t, index.Find("jira", "1.0.0", "5.12.0"))
+	})
+
+	t.Run("upgrades", func(t *testing.T) {
+		installed := []*Manifest{
+			{Id: "jira", Version: "2.0.0"},
+			{Id: "zoom", Version: "1.0.0"},
+			{Id: "github", Version: "1.0.0"},
+			{Id: "other", Version: "1.0.0"},
+		}
+
+		upgrades := index.Upgrades(installed, "5.12.0")
+		require.Len(t, upgrades, 1)
+		assert.Equal(t, "jira", upgrades[0].Id)
+		assert.Equal(t, "2.0.0", upgrades[0].InstalledVersion)
+		assert.Equal(t, "2.1.0", upgrades[0].Latest.Version)
+
+		assert.Empty(t, index.Upgrades(nil, "5.12.0"))
+	})
+}
+
+func TestMarketplaceIndexJson(t *testing.T) {
+	index := &MarketplaceIndex{
+		Plugins: []*MarketplacePlugin{
+			{Id: "jira", Version: "2.0.0", DownloadUrl: "https://example.com/jira-2.0.0.tar.gz", Checksum: "abc"},
+		},
+	}
+
+	assert.Equal(t, index, MarketplaceIndexFromJson(strings.NewReader(index.ToJson())))
+	assert.Nil(t, MarketplaceIndexFromJson(strings.NewReader("junk")))
+
+	upgrades := []*MarketplacePluginUpgrade{{Id: "jira", InstalledVersion: "1.0.0", Latest: index.Plugins[0]}}
+	assert.Equal(t, upgrades, MarketplacePluginUpgradesFromJson(strings.NewReader(MarketplacePluginUpgradesToJson(upgrades))))
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PLUGIN_SCHEDULED_JOB_NAME_MAX_LENGTH = 64
+	PLUGIN_SCHEDULED_JOB_MIN_INTERVAL    = 60
+	PLUGIN_SCHEDULED_JOB_CRON_MAX_LENGTH = 256
+
+	PLUGIN_SCHEDULED_JOB_DATA_PLUGIN_ID = "plugin_id"
+	PLUGIN_SCHEDULED_JOB_DATA_NAME      = "name"
+	PLUGIN_SCHEDULED_JOB_DATA_CRON      = "cron"
+	PLUGIN_SCHEDULED_JOB_DATA_INTERVAL  = "interval"
+	PLUGIN_SCHEDULED_JOB_DATA_RUN_AT    = "run_at"
+)
+
+// PluginScheduledJob is a named recurring job registered by a plugin. The job runs either on a
+// cron schedule or at a fixed interval, and each run is delivered to a single server of the
+// cluster through the RunScheduledJob hook of the plugin.
+type PluginScheduledJob struct {
+	PluginId string `json:"plugin_id"`
+	Name     string `json:"name"`
+
+	// Cron is a five field cron expression (minute, hour, day of month, month and day of week),
+	// or one of @yearly, @monthly, @weekly, @daily and @hourly, evaluated in UTC.
+	Cron string `json:"cron,omitempty"`
+
+	// Interval is the number of seconds between two runs, counted from the Unix epoch so that
+	// every server of the cluster agrees on the next run.
+	Interval int64 `json:"interval,omitempty"`
+}
+
+func (j *PluginScheduledJob) IsValid() *AppError {
+	if j.PluginId == "" {
+		return NewAppError("PluginScheduledJob.IsValid", "model.plugin_scheduled_job.is_valid.plugin_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if len(j.Name) == 0 || len(j.Name) > PLUGIN_SCHEDULED_JOB_NAME_MAX_LENGTH {
+		return NewAppError("PluginScheduledJob.IsValid", "model.plugin_scheduled_job.is_valid.name.app_error", map[string]interface{}{"Max": PLUGIN_SCHEDULED_JOB_NAME_MAX_LENGTH}, "", http.StatusBadRequest)
+	}
+
+	if (j.Cron == "") == (j.Interval == 0) {
+		return NewAppError("PluginScheduledJob.IsValid", "model.plugin_scheduled_job.is_valid.schedule.app_error", nil, "name="+j.Name, http.StatusBadRequest)
+	}
+
+	if j.Cron != "" {
+		if len(j.Cron) > PLUGIN_SCHEDULED_JOB_CRON_MAX_LENGTH {
+			return NewAppError("PluginScheduledJob.IsValid", "model.plugin_scheduled_job.is_valid.cron.app_error", nil, "name="+j.Name, http.StatusBadRequest)
+		}
+		if _, err := parseCronSchedule(j.Cron); err != nil {
+			return NewAppError("PluginScheduledJob.IsValid", "model.plugin_scheduled_job.is_valid.cron.app_error", nil, "name="+j.Name+", "+err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	if j.Cron == "" && j.Interval < PLUGIN_SCHEDULED_JOB_MIN_INTERVAL {
+		return NewAppError("PluginScheduledJob.IsValid", "model.plugin_scheduled_job.is_valid.interval.app_error", map[string]interface{}{"Min": PLUGIN_SCHEDULED_JOB_MIN_INTERVAL}, "name="+j.Name, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// NextRunTime returns the first time strictly after the given time at which the job is due.
+func (j *PluginScheduledJob) NextRunTime(after time.Time) (time.Time, error) {
+	if j.Cron == "" {
+		if j.Interval <= 0 {
+			return time.Time{}, fmt.Errorf("invalid interval %v", j.Interval)
+		}
+		interval := j.Interval * 1000
+		next := (after.UnixNano()/int64(time.Millisecond)/interval + 1) * interval
+		return time.Unix(0, next*int64(time.Millisecond)).UTC(), nil
+	}
+
+	schedule, err := parseCronSchedule(j.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return schedule.next(after)
+}
+
+// SameSchedule reports whether both jobs run at the same times.
+func (j *PluginScheduledJob) SameSchedule(other *PluginScheduledJob) bool {
+	return j.Cron == other.Cron && j.Interval == other.Interval
+}
+
+// JobId returns the id of the job running the scheduled job at the given time, in milliseconds.
+// The id is the same on every server of the cluster, so a run can only be saved once.
+func (j *PluginScheduledJob) JobId(runAt int64) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{j.PluginId, j.Name, j.Cron, strconv.FormatInt(j.Interval, 10), strconv.FormatInt(runAt, 10)}, "\x00")))
+
+	var b bytes.Buffer
+	encoder := base32.NewEncoder(encoding, &b)
+	encoder.Write(sum[:16])
+	encoder.Close()
+	b.Truncate(26)
+	return b.String()
+}
+
+// ToJob returns the pending job running the scheduled job at the given time, in milliseconds.
+func (j *PluginScheduledJob) ToJob(runAt int64) *Job {
+	return &Job{
+		Id:       j.JobId(runAt),
+		Type:     JOB_TYPE_PLUGIN_SCHEDULED_JOB,
+		CreateAt: GetMillis(),
+		Status:   JOB_STATUS_PENDING,
+		Data: map[string]string{
+			PLUGIN_SCHEDULED_JOB_DATA_PLUGIN_ID: j.PluginId,
+			PLUGIN_SCHEDULED_JOB_DATA_NAME:      j.Name,
+			PLUGIN_SCHEDULED_JOB_DATA_CRON:      j.Cron,
+			PLUGIN_SCHEDULED_JOB_DATA_INTERVAL:  strconv.FormatInt(j.Interval, 10),
+			PLUGIN_SCHEDULED_JOB_DATA_RUN_AT:    strconv.FormatInt(runAt, 10),
+		},
+	}
+}
+
+// PluginScheduledJobFromJob returns the scheduled job run by the given job along with the time
+// of the run, in milliseconds, or nil if the job doesn't run a scheduled job.
+func PluginScheduledJobFromJob(job *Job) (*PluginScheduledJob, int64) {
+	if job.Type != JOB_TYPE_PLUGIN_SCHEDULED_JOB || job.Data == nil {
+		return nil, 0
+	}
+
+	interval, _ := strconv.ParseInt(job.Data[PLUGIN_SCHEDULED_JOB_DATA_INTERVAL], 10, 64)
+	runAt, err := strconv.ParseInt(job.Data[PLUGIN_SCHEDULED_JOB_DATA_RUN_AT], 10, 64)
+	if err != nil {
+		return nil, 0
+	}
+
+	return &PluginScheduledJob{
+		PluginId: job.Data[PLUGIN_SCHEDULED_JOB_DATA_PLUGIN_ID],
+		Name:     job.Data[PLUGIN_SCHEDULED_JOB_DATA_NAME],
+		Cron:     job.Data[PLUGIN_SCHEDULED_JOB_DATA_CRON],
+		Interval: interval,
+	}, runAt
+}
+
+func (j *PluginScheduledJob) ToJson() string {
+	b, _ := json.Marshal(j)
+	return string(b)
+}
+
+func PluginScheduledJobFromJson(data io.Reader) *PluginScheduledJob {
+	var job *PluginScheduledJob
+	json.NewDecoder(data).Decode(&job)
+	return job
+}
+
+// cronSchedule holds the values matched by each field of a cron expression.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+
+	// As in most cron implementations, a day matches either field when both the day of month
+	// and the day of week are restricted.
+	anyDay, anyWeekday bool
+}
+
+var cronFieldBounds = []struct{ min, max int }{
+	{0, 59}, // minute
+	{0, 23}, // hour
+	{1, 31}, // day of month
+	{1, 12}, // month
+	{0, 7},  // day of week, both 0 and 7 being Sunday
+}
+
+var cronShortcuts = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	if shortcut, ok := cronShortcuts[strings.ToLower(strings.TrimSpace(expr))]; ok {
+		expr = shortcut
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFieldBounds) {
+		return nil, fmt.Errorf("expected %v fields in cron expression %q", len(cronFieldBounds), expr)
+	}
+
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		bits, err := parseCronField(field, cronFieldBounds[i].min, cronFieldBounds[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron field %q: %v", field, err)
+		}
+		values[i] = bits
+	}
+
+	// Sunday may be written as 7.
+	if values[4]&(1<<7) != 0 {
+		values[4] |= 1
+	}
+
+	return &cronSchedule{
+		minutes:    values[0],
+		hours:      values[1],
+		days:       values[2],
+		months:     values[3],
+		weekdays:   values[4],
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart = part[:i]
+		}
+
+		start, end := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid value in %q", part)
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("invalid value in %q", part)
+				}
+			} else if step != 1 {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, fmt.Errorf("%q is out of range %v-%v", part, min, max)
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dayMatches := s.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := s.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if s.anyDay || s.anyWeekday {
+		return dayMatches && weekdayMatches
+	}
+
+	return dayMatches || weekdayMatches
+}
+
+// next returns the first matching minute strictly after the given time, in UTC.
+func (s *cronSchedule) next(after time.Time) (time.Time, error) {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.months&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if s.hours&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+
+		if s.minutes&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("no time matches the cron expression within 5 years")
+}
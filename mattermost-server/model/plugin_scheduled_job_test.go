@@ -0,0 +1,103 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginScheduledJobIsValid(t *testing.T) {
+	for name, test := range map[string]struct {
+		Job   PluginScheduledJob
+		Valid bool
+	}{
+		"cron":              {PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: "*/5 * * * *"}, true},
+		"shortcut":          {PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: "@daily"}, true},
+		"interval":          {PluginScheduledJob{PluginId: "plugin", Name: "job", Interval: 3600}, true},
+		"no plugin":         {PluginScheduledJob{Name: "job", Interval: 3600}, false},
+		"no name":           {PluginScheduledJob{PluginId: "plugin", Interval: 3600}, false},
+		"long name":         {PluginScheduledJob{PluginId: "plugin", Name: strings.Repeat("a", 65), Interval: 3600}, false},
+		"no schedule":       {PluginScheduledJob{PluginId: "plugin", Name: "job"}, false},
+		"both schedules":    {PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: "@daily", Interval: 3600}, false},
+		"short interval":    {PluginScheduledJob{PluginId: "plugin", Name: "job", Interval: 59}, false},
+		"invalid cron":      {PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: "* * *"}, false},
+		"cron out of range": {PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: "60 * * * *"}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if test.Valid {
+				assert.Nil(t, test.Job.IsValid())
+			} else {
+				assert.NotNil(t, test.Job.IsValid())
+			}
+		})
+	}
+}
+
+func TestPluginScheduledJobNextRunTime(t *testing.T) {
+	now := time.Date(2019, time.May, 15, 10, 42, 30, 0, time.UTC)
+
+	for cron, expected := range map[string]time.Time{
+		"* * * * *":          time.Date(2019, time.May, 15, 10, 43, 0, 0, time.UTC),
+		"*/15 * * * *":       time.Date(2019, time.May, 15, 10, 45, 0, 0, time.UTC),
+		"0 * * * *":          time.Date(2019, time.May, 15, 11, 0, 0, 0, time.UTC),
+		"30 9 * * *":         time.Date(2019, time.May, 16, 9, 30, 0, 0, time.UTC),
+		"0 0 1 * *":          time.Date(2019, time.June, 1, 0, 0, 0, 0, time.UTC),
+		"0 12 * * 1-5":       time.Date(2019, time.May, 15, 12, 0, 0, 0, time.UTC),
+		"0 12 * * 7":         time.Date(2019, time.May, 19, 12, 0, 0, 0, time.UTC),
+		"0 0 13 * 5":         time.Date(2019, time.May, 17, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":         time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC),
+		"@weekly":            time.Date(2019, time.May, 19, 0, 0, 0, 0, time.UTC),
+		"15,45 8-10/2 * * *": time.Date(2019, time.May, 15, 10, 45, 0, 0, time.UTC),
+	} {
+		t.Run(cron, func(t *testing.T) {
+			job := &PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: cron}
+			next, err := job.NextRunTime(now)
+			require.Nil(t, err)
+			assert.Equal(t, expected, next)
+		})
+	}
+
+	t.Run("impossible date", func(t *testing.T) {
+		job := &PluginScheduledJob{PluginId: "plugin", Name: "job", Cron: "0 0 31 2 *"}
+		_, err := job.NextRunTime(now)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		job := &PluginScheduledJob{PluginId: "plugin", Name: "job", Interval: 3600}
+		next, err := job.NextRunTime(now)
+		require.Nil(t, err)
+		assert.Equal(t, time.Date(2019, time.May, 15, 11, 0, 0, 0, time.UTC), next)
+
+		next, err = job.NextRunTime(next)
+		require.Nil(t, err)
+		assert.Equal(t, time.Date(2019, time.May, 15, 12, 0, 0, 0, time.UTC), next)
+	})
+}
+
+func TestPluginScheduledJobToJob(t *testing.T) {
+	scheduledJob := &PluginScheduledJob{PluginId: "plugin", Name: "job", Interval: 3600}
+
+	job := scheduledJob.ToJob(1557918000000)
+	require.Nil(t, job.IsValid())
+	assert.Equal(t, JOB_TYPE_PLUGIN_SCHEDULED_JOB, job.Type)
+	assert.Equal(t, JOB_STATUS_PENDING, job.Status)
+
+	// Every server must agree on the id of a run.
+	assert.Equal(t, job.Id, scheduledJob.ToJob(1557918000000).Id)
+	assert.NotEqual(t, job.Id, scheduledJob.ToJob(1557921600000).Id)
+	assert.NotEqual(t, job.Id, (&PluginScheduledJob{PluginId: "plugin", Name: "other", Interval: 3600}).ToJob(1557918000000).Id)
+
+	decoded, runAt := PluginScheduledJobFromJob(job)
+	assert.Equal(t, scheduledJob, decoded)
+	assert.Equal(t, int64(1557918000000), runAt)
+
+	decoded, _ = PluginScheduledJobFromJob(&Job{Type: JOB_TYPE_PLUGINS})
+	assert.Nil(t, decoded)
+}
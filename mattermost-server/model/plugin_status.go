@@ -13,7 +13,7 @@ const (
 	PluginStateStarting            = 1 // unused by server
 	PluginStateRunning             = 2
 	PluginStateFailedToStart       = 3
-	PluginStateFailedToStayRunning = 4 // unused by server
+	PluginStateFailedToStayRunning = 4
 	PluginStateStopping            = 5 // unused by server
 )
 
@@ -26,6 +26,22 @@ type PluginStatus struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	Version     string `json:"version"`
+
+	// Error describes why the plugin failed to start, for instance because a plugin it requires
+	// is missing or disabled.
+	Error string `json:"error,omitempty"`
+
+	// RestartHistory lists the times, in milliseconds, at which the plugin was last restarted after
+	// failing its health check, oldest first.
+	RestartHistory []int64 `json:"restart_history,omitempty"`
+
+	// LastCrashReason describes the last health check failure of the plugin, which happened at
+	// LastCrashAt.
+	LastCrashReason string `json:"last_crash_reason,omitempty"`
+	LastCrashAt     int64  `json:"last_crash_at,omitempty"`
+
+	// NextRestartAt is set while a plugin that failed repeatedly waits to be restarted.
+	NextRestartAt int64 `json:"next_restart_at,omitempty"`
 }
 
 type PluginStatuses []*PluginStatus
@@ -0,0 +1,78 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+const (
+	// Reminders are sent by the system bot with buttons, handled by the server itself, to snooze
+	// them or mark them as completed.
+	POST_REMINDER_INTEGRATION_URL = "builtin://post_reminder"
+	POST_REMINDER_ACTION_SNOOZE   = "snooze"
+	POST_REMINDER_ACTION_COMPLETE = "complete"
+	POST_REMINDER_SNOOZE_MINUTES  = 60
+
+	POST_PROPS_REMINDED_POST_ID = "reminded_post_id"
+)
+
+// PostReminder is a reminder a user set about a post, sent to them as a direct message from the
+// system bot at TargetTime. A user has at most one reminder per post.
+type PostReminder struct {
+	PostId     string `json:"post_id"`
+	UserId     string `json:"user_id"`
+	TargetTime int64  `json:"target_time"`
+	CreateAt   int64  `json:"create_at"`
+}
+
+func (o *PostReminder) IsValid() *AppError {
+	if !IsValidId(o.PostId) {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(o.UserId) {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.user_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.TargetTime <= 0 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.target_time.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("PostReminder.IsValid", "model.post_reminder.is_valid.create_at.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *PostReminder) PreSave() {
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+}
+
+func (o *PostReminder) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func PostReminderFromJson(data io.Reader) *PostReminder {
+	var o *PostReminder
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func PostReminderListToJson(list []*PostReminder) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func PostReminderListFromJson(data io.Reader) []*PostReminder {
+	var list []*PostReminder
+	json.NewDecoder(data).Decode(&list)
+	return list
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostReminderIsValid(t *testing.T) {
+	o := PostReminder{PostId: NewId(), UserId: NewId(), TargetTime: GetMillis()}
+	assert.NotNil(t, o.IsValid())
+
+	o.PreSave()
+	require.Nil(t, o.IsValid())
+
+	o.TargetTime = 0
+	assert.NotNil(t, o.IsValid())
+
+	o.TargetTime = GetMillis()
+	o.PostId = "junk"
+	assert.NotNil(t, o.IsValid())
+
+	o.PostId = NewId()
+	o.UserId = ""
+	assert.NotNil(t, o.IsValid())
+}
+
+func TestPostReminderJson(t *testing.T) {
+	o := PostReminder{PostId: NewId(), UserId: NewId(), TargetTime: GetMillis()}
+	ro := PostReminderFromJson(strings.NewReader(o.ToJson()))
+	assert.Equal(t, o, *ro)
+
+	list := PostReminderListFromJson(strings.NewReader(PostReminderListToJson([]*PostReminder{&o})))
+	require.Len(t, list, 1)
+	assert.Equal(t, o.PostId, list[0].PostId)
+}
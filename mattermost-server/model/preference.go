@@ -26,6 +26,7 @@ const (
 	PREFERENCE_NAME_MESSAGE_DISPLAY      = "message_display"
 	PREFERENCE_NAME_NAME_FORMAT          = "name_format"
 	PREFERENCE_NAME_USE_MILITARY_TIME    = "use_military_time"
+	PREFERENCE_NAME_SEND_READ_RECEIPTS   = "send_read_receipts"
 
 	PREFERENCE_CATEGORY_THEME = "theme"
 	// the name for theme props is the team id
@@ -0,0 +1,191 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	// The reasons a scheduled post couldn't be published. The post is then kept, with the reason,
+	// until its author edits or deletes it.
+	SCHEDULED_POST_ERROR_CHANNEL_NOT_FOUND = "channel_not_found"
+	SCHEDULED_POST_ERROR_CHANNEL_ARCHIVED  = "channel_archived"
+	SCHEDULED_POST_ERROR_THREAD_NOT_FOUND  = "thread_not_found"
+	SCHEDULED_POST_ERROR_NO_PERMISSION     = "no_permission"
+	SCHEDULED_POST_ERROR_USER_DEACTIVATED  = "user_deactivated"
+	SCHEDULED_POST_ERROR_UNKNOWN           = "unknown"
+
+	SCHEDULED_POST_ERROR_CODE_MAX_LENGTH = 64
+)
+
+// ScheduledPost is a message written by a user to be posted on their behalf at a later time.
+type ScheduledPost struct {
+	Id          string          `json:"id"`
+	CreateAt    int64           `json:"create_at"`
+	UpdateAt    int64           `json:"update_at"`
+	UserId      string          `json:"user_id"`
+	ChannelId   string          `json:"channel_id"`
+	RootId      string          `json:"root_id"`
+	Message     string          `json:"message"`
+	Props       StringInterface `json:"props"`
+	FileIds     StringArray     `json:"file_ids,omitempty"`
+	ScheduledAt int64           `json:"scheduled_at"`
+	ErrorCode   string          `json:"error_code"`
+}
+
+// ScheduledPostPatch holds the fields of a scheduled post its author may change.
+type ScheduledPostPatch struct {
+	Message     *string          `json:"message"`
+	Props       *StringInterface `json:"props"`
+	FileIds     *StringArray     `json:"file_ids"`
+	ScheduledAt *int64           `json:"scheduled_at"`
+}
+
+func (o *ScheduledPost) IsValid(maxPostSize int) *AppError {
+	if !IsValidId(o.Id) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if o.CreateAt == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.create_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.UpdateAt == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.update_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(o.UserId) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.user_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !IsValidId(o.ChannelId) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.channel_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if !(IsValidId(o.RootId) || len(o.RootId) == 0) {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.root_id.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(o.Message) > maxPostSize {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.msg.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.Message) == 0 && len(o.FileIds) == 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.empty.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(ArrayToJson(o.FileIds)) > POST_FILEIDS_MAX_RUNES {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.file_ids.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if utf8.RuneCountInString(StringInterfaceToJson(o.Props)) > POST_PROPS_MAX_USER_RUNES {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.props.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if o.ScheduledAt <= 0 {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.scheduled_at.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	if len(o.ErrorCode) > SCHEDULED_POST_ERROR_CODE_MAX_LENGTH {
+		return NewAppError("ScheduledPost.IsValid", "model.scheduled_post.is_valid.error_code.app_error", nil, "id="+o.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+func (o *ScheduledPost) PreSave() {
+	if o.Id == "" {
+		o.Id = NewId()
+	}
+
+	if o.CreateAt == 0 {
+		o.CreateAt = GetMillis()
+	}
+	o.UpdateAt = o.CreateAt
+
+	if o.Props == nil {
+		o.Props = make(map[string]interface{})
+	}
+
+	if o.FileIds == nil {
+		o.FileIds = []string{}
+	}
+}
+
+func (o *ScheduledPost) PreUpdate() {
+	o.UpdateAt = GetMillis()
+}
+
+func (o *ScheduledPost) Patch(patch *ScheduledPostPatch) {
+	if patch.Message != nil {
+		o.Message = *patch.Message
+	}
+
+	if patch.Props != nil {
+		o.Props = *patch.Props
+	}
+
+	if patch.FileIds != nil {
+		o.FileIds = *patch.FileIds
+	}
+
+	if patch.ScheduledAt != nil {
+		o.ScheduledAt = *patch.ScheduledAt
+	}
+}
+
+// ToPost returns the post to create once the scheduled post is due.
+func (o *ScheduledPost) ToPost() *Post {
+	post := &Post{
+		UserId:    o.UserId,
+		ChannelId: o.ChannelId,
+		RootId:    o.RootId,
+		Message:   o.Message,
+		FileIds:   o.FileIds,
+	}
+
+	post.Props = make(map[string]interface{}, len(o.Props))
+	for key, value := range o.Props {
+		post.Props[key] = value
+	}
+
+	return post
+}
+
+func (o *ScheduledPost) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func ScheduledPostFromJson(data io.Reader) *ScheduledPost {
+	var o *ScheduledPost
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func (o *ScheduledPostPatch) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func ScheduledPostPatchFromJson(data io.Reader) *ScheduledPostPatch {
+	var o *ScheduledPostPatch
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func ScheduledPostListToJson(list []*ScheduledPost) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func ScheduledPostListFromJson(data io.Reader) []*ScheduledPost {
+	var list []*ScheduledPost
+	json.NewDecoder(data).Decode(&list)
+	return list
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledPostIsValid(t *testing.T) {
+	o := ScheduledPost{UserId: NewId(), ChannelId: NewId(), Message: "hello", ScheduledAt: GetMillis() + 60000}
+	o.PreSave()
+	require.Nil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.RootId = "junk"
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.RootId = NewId()
+	o.Message = strings.Repeat("0", POST_MESSAGE_MAX_RUNES_V2+1)
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.Message = ""
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.FileIds = []string{NewId()}
+	assert.Nil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.ScheduledAt = 0
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+
+	o.ScheduledAt = GetMillis()
+	o.ChannelId = ""
+	assert.NotNil(t, o.IsValid(POST_MESSAGE_MAX_RUNES_V2))
+}
+
+func TestScheduledPostPatch(t *testing.T) {
+	o := ScheduledPost{Message: "hello", ScheduledAt: 1000, FileIds: []string{"a"}}
+
+	message := "updated"
+	scheduledAt := int64(2000)
+	o.Patch(&ScheduledPostPatch{Message: &message, ScheduledAt: &scheduledAt})
+
+	assert.Equal(t, "updated", o.Message)
+	assert.Equal(t, int64(2000), o.ScheduledAt)
+	assert.Equal(t, StringArray{"a"}, o.FileIds)
+}
+
+func TestScheduledPostToPost(t *testing.T) {
+	o := ScheduledPost{
+		UserId:    NewId(),
+		ChannelId: NewId(),
+		RootId:    NewId(),
+		Message:   "hello",
+		Props:     StringInterface{"key": "value"},
+		FileIds:   StringArray{NewId()},
+	}
+
+	post := o.ToPost()
+	assert.Equal(t, "", post.Id)
+	assert.Equal(t, o.UserId, post.UserId)
+	assert.Equal(t, o.ChannelId, post.ChannelId)
+	assert.Equal(t, o.RootId, post.RootId)
+	assert.Equal(t, o.Message, post.Message)
+	assert.Equal(t, o.FileIds, post.FileIds)
+	assert.Equal(t, o.Props, post.Props)
+
+	// The props of the post are its own.
+	post.Props["key"] = "changed"
+	assert.Equal(t, "value", o.Props["key"])
+}
+
+func TestScheduledPostJson(t *testing.T) {
+	o := ScheduledPost{Id: NewId(), Message: "hello", ScheduledAt: 1000}
+	ro := ScheduledPostFromJson(strings.NewReader(o.ToJson()))
+	assert.Equal(t, o.Id, ro.Id)
+	assert.Equal(t, o.ScheduledAt, ro.ScheduledAt)
+
+	list := ScheduledPostListFromJson(strings.NewReader(ScheduledPostListToJson([]*ScheduledPost{&o})))
+	require.Len(t, list, 1)
+	assert.Equal(t, o.Id, list[0].Id)
+}
@@ -18,6 +18,7 @@ const (
 	SYSTEM_ASYMMETRIC_SIGNING_KEY    = "AsymmetricSigningKey"
 	SYSTEM_POST_ACTION_COOKIE_SECRET = "PostActionCookieSecret"
 	SYSTEM_INSTALLATION_DATE_KEY     = "InstallationDate"
+	SYSTEM_BOT_USER_ID_KEY           = "SystemBotUserId"
 )
 
 type System struct {
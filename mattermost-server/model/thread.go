@@ -0,0 +1,75 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// ThreadMembership tracks a user's interest in a thread, identified by its root post, and what they
+// haven't read of it yet. A user follows a thread when they start it, reply to it or are mentioned
+// in it, until they stop following it.
+type ThreadMembership struct {
+	PostId         string `json:"post_id"`
+	UserId         string `json:"user_id"`
+	Following      bool   `json:"following"`
+	LastViewed     int64  `json:"last_viewed"`
+	LastUpdated    int64  `json:"last_updated"`
+	UnreadReplies  int64  `json:"unread_replies"`
+	UnreadMentions int64  `json:"unread_mentions"`
+}
+
+// UserThread is a thread followed by a user, as listed to them.
+type UserThread struct {
+	ThreadMembership
+	ReplyCount int64 `json:"reply_count"`
+	Post       *Post `json:"post"`
+}
+
+func (o *ThreadMembership) IsValid() *AppError {
+	if !IsValidId(o.PostId) {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.post_id.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if !IsValidId(o.UserId) {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.user_id.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	if o.UnreadReplies < 0 || o.UnreadMentions < 0 {
+		return NewAppError("ThreadMembership.IsValid", "model.thread_membership.is_valid.unread.app_error", nil, "post_id="+o.PostId, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// MarkRead marks the thread as read up to the given time.
+func (o *ThreadMembership) MarkRead(viewedAt int64) {
+	o.LastViewed = viewedAt
+	o.UnreadReplies = 0
+	o.UnreadMentions = 0
+}
+
+func (o *ThreadMembership) ToJson() string {
+	b, _ := json.Marshal(o)
+	return string(b)
+}
+
+func ThreadMembershipFromJson(data io.Reader) *ThreadMembership {
+	var o *ThreadMembership
+	json.NewDecoder(data).Decode(&o)
+	return o
+}
+
+func UserThreadListToJson(list []*UserThread) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+func UserThreadListFromJson(data io.Reader) []*UserThread {
+	var list []*UserThread
+	json.NewDecoder(data).Decode(&list)
+	return list
+}
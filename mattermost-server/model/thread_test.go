@@ -0,0 +1,52 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreadMembershipIsValid(t *testing.T) {
+	o := ThreadMembership{PostId: NewId(), UserId: NewId(), Following: true}
+	require.Nil(t, o.IsValid())
+
+	o.UnreadReplies = -1
+	assert.NotNil(t, o.IsValid())
+
+	o.UnreadReplies = 0
+	o.PostId = "junk"
+	assert.NotNil(t, o.IsValid())
+
+	o.PostId = NewId()
+	o.UserId = ""
+	assert.NotNil(t, o.IsValid())
+}
+
+func TestThreadMembershipMarkRead(t *testing.T) {
+	o := ThreadMembership{PostId: NewId(), UserId: NewId(), UnreadReplies: 3, UnreadMentions: 1}
+	o.MarkRead(1234)
+
+	assert.Equal(t, int64(1234), o.LastViewed)
+	assert.Zero(t, o.UnreadReplies)
+	assert.Zero(t, o.UnreadMentions)
+}
+
+func TestUserThreadJson(t *testing.T) {
+	o := ThreadMembership{PostId: NewId(), UserId: NewId(), Following: true, UnreadReplies: 2}
+	ro := ThreadMembershipFromJson(strings.NewReader(o.ToJson()))
+	assert.Equal(t, o, *ro)
+
+	thread := &UserThread{ThreadMembership: o, ReplyCount: 5, Post: &Post{Id: o.PostId, Message: "root"}}
+	assert.Contains(t, UserThreadListToJson([]*UserThread{thread}), `"unread_replies":2`)
+
+	list := UserThreadListFromJson(strings.NewReader(UserThreadListToJson([]*UserThread{thread})))
+	require.Len(t, list, 1)
+	assert.Equal(t, o.PostId, list[0].PostId)
+	assert.Equal(t, int64(5), list[0].ReplyCount)
+	assert.Equal(t, "root", list[0].Post.Message)
+}
@@ -682,6 +682,22 @@ var restrictedUsernames = []string{
 	"system",
 }
 
+// reservedUsernames are valid usernames that only the server's own accounts may take.
+var reservedUsernames = []string{
+	BOT_SYSTEM_BOT_USERNAME,
+}
+
+// IsReservedUsername returns whether the given username is kept for the server's own accounts.
+func IsReservedUsername(s string) bool {
+	for _, reservedUsername := range reservedUsernames {
+		if s == reservedUsername {
+			return true
+		}
+	}
+
+	return false
+}
+
 func IsValidUsername(s string) bool {
 	if len(s) < USER_NAME_MIN_LENGTH || len(s) > USER_NAME_MAX_LENGTH {
 		return false
@@ -282,6 +282,12 @@ func TestValidUsername(t *testing.T) {
 	}
 }
 
+func TestIsReservedUsername(t *testing.T) {
+	assert.True(t, IsReservedUsername(BOT_SYSTEM_BOT_USERNAME))
+	assert.True(t, IsValidUsername(BOT_SYSTEM_BOT_USERNAME))
+	assert.False(t, IsReservedUsername("spin-punch"))
+}
+
 func TestNormalizeUsername(t *testing.T) {
 	if NormalizeUsername("Spin-punch") != "spin-punch" {
 		t.Fatal("didn't normalize username properly")
@@ -51,6 +51,14 @@ const (
 	WEBSOCKET_EVENT_LICENSE_CHANGED         = "license_changed"
 	WEBSOCKET_EVENT_CONFIG_CHANGED          = "config_changed"
 	WEBSOCKET_EVENT_OPEN_DIALOG             = "open_dialog"
+	WEBSOCKET_EVENT_SCHEDULED_POST_FAILED   = "scheduled_post_failed"
+	WEBSOCKET_EVENT_DRAFT_CREATED           = "draft_created"
+	WEBSOCKET_EVENT_DRAFT_UPDATED           = "draft_updated"
+	WEBSOCKET_EVENT_DRAFT_DELETED           = "draft_deleted"
+	WEBSOCKET_EVENT_THREAD_UPDATED          = "thread_updated"
+	WEBSOCKET_EVENT_THREAD_FOLLOW_CHANGED   = "thread_follow_changed"
+	WEBSOCKET_EVENT_THREAD_READ_CHANGED     = "thread_read_changed"
+	WEBSOCKET_EVENT_POST_SEEN               = "post_seen"
 )
 
 type WebSocketMessage interface {
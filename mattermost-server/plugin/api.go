@@ -422,11 +422,20 @@ type API interface {
 
 	// OpenInteractiveDialog will open an interactive dialog on a user's client that
 	// generated the trigger ID. Used with interactive message buttons, menus
-	// and slash commands.
+	// and slash commands. If the URL is empty, the dialog is submitted to the
+	// DialogSubmitted hook of the plugin, starting with server version 5.12. Dialogs
+	// can't be submitted to the hook of another plugin.
 	//
 	// Minimum server version: 5.6
 	OpenInteractiveDialog(dialog model.OpenDialogRequest) *model.AppError
 
+	// OpenInteractiveDialogForUser will open an interactive dialog on the clients of a user
+	// without a trigger ID. If the URL is empty, the dialog is submitted to the DialogSubmitted
+	// hook of the plugin.
+	//
+	// Minimum server version: 5.12
+	OpenInteractiveDialogForUser(userId string, dialog model.OpenDialogRequest) *model.AppError
+
 	// Plugin Section
 
 	// GetPlugins will return a list of plugin manifests for currently active plugins.
@@ -454,6 +463,31 @@ type API interface {
 	// Minimum server version: 5.6
 	GetPluginStatus(id string) (*model.PluginStatus, *model.AppError)
 
+	// CallPlugin invokes a method exported by another active plugin, which receives the call
+	// through its OnPluginCall hook along with the id of the calling plugin. The method must be
+	// listed in the exports of the server section of the other plugin's manifest.
+	//
+	// Minimum server version: 5.12
+	CallPlugin(pluginId, method string, payload []byte) ([]byte, *model.AppError)
+
+	// RegisterScheduledJob registers a named job running on a cron schedule or at a fixed
+	// interval. Each run is delivered to the plugin through its RunScheduledJob hook on a single
+	// server of the cluster. Registering a job again with the same name replaces its schedule, so
+	// plugins typically register their jobs in OnActivate. The plugin id of the job is ignored.
+	//
+	// Minimum server version: 5.12
+	RegisterScheduledJob(job *model.PluginScheduledJob) *model.AppError
+
+	// UnregisterScheduledJob cancels the future runs of a job registered by the plugin.
+	//
+	// Minimum server version: 5.12
+	UnregisterScheduledJob(name string) *model.AppError
+
+	// GetScheduledJobs returns the jobs registered by the plugin.
+	//
+	// Minimum server version: 5.12
+	GetScheduledJobs() ([]*model.PluginScheduledJob, *model.AppError)
+
 	// KV Store Section
 
 	// KVSet will store a key-value pair, unique per plugin.
@@ -470,6 +504,20 @@ type API interface {
 	// Minimum server version: 5.12
 	KVCompareAndSet(key string, oldValue, newValue []byte) (bool, *model.AppError)
 
+	// KVCompareAndDelete will remove a key-value pair, unique per plugin, if the current value == the old value.
+	// Returns (false, err) if DB error occurred
+	// Returns (false, nil) if current value != old value or the key doesn't exist
+	// Returns (true, nil) if current value == old value and the key was removed
+	//
+	// Minimum server version: 5.12
+	KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError)
+
+	// KVSetMany will store several key-value pairs at once, in a single transaction: either all of them
+	// are stored or none is.
+	//
+	// Minimum server version: 5.12
+	KVSetMany(values map[string][]byte) *model.AppError
+
 	// KVSet will store a key-value pair, unique per plugin with an expiry time
 	//
 	// Minimum server version: 5.6
@@ -481,6 +529,12 @@ type API interface {
 	// KVDelete will remove a key-value pair. Returns nil for non-existent keys.
 	KVDelete(key string) *model.AppError
 
+	// KVDeleteMany will remove several key-value pairs at once: either all of them are removed or none is.
+	// Non-existent keys are ignored.
+	//
+	// Minimum server version: 5.12
+	KVDeleteMany(keys []string) *model.AppError
+
 	// KVDeleteAll will remove all key-value pairs for a plugin.
 	//
 	// Minimum server version: 5.6
@@ -491,6 +545,12 @@ type API interface {
 	// Minimum server version: 5.6
 	KVList(page, perPage int) ([]string, *model.AppError)
 
+	// KVListWithPrefix will list the keys for a plugin starting with the given prefix, in order.
+	// Expired keys aren't listed.
+	//
+	// Minimum server version: 5.12
+	KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError)
+
 	// PublishWebSocketEvent sends an event to WebSocket connections.
 	// event is the type and will be prepended with "custom_<pluginid>_".
 	// payload is the data sent with the event. Interface values must be primitive Go types or mattermost-server/model types.
@@ -268,6 +268,10 @@ type Z_ServeHTTPArgs struct {
 	Request              *http.Request
 	Context              *Context
 	RequestBodyStream    uint32
+
+	// RequestBodyStreamed is set when the request body is sent without the plugin asking for each
+	// chunk, which servers only do since 5.12.
+	RequestBodyStreamed bool
 }
 
 func (g *hooksRPCClient) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Request) {
@@ -286,7 +290,7 @@ func (g *hooksRPCClient) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Re
 		defer connection.Close()
 
 		rpcServer := rpc.NewServer()
-		if err := rpcServer.RegisterName("Plugin", &httpResponseWriterRPCServer{w: w}); err != nil {
+		if err := rpcServer.RegisterName("Plugin", &httpResponseWriterRPCServer{w: w, muxBroker: g.muxBroker}); err != nil {
 			g.log.Error("Plugin failed to ServeHTTP, coulden't register RPC name", mlog.Err(err))
 			return
 		}
@@ -296,14 +300,22 @@ func (g *hooksRPCClient) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Re
 	requestBodyStreamId := uint32(0)
 	if r.Body != nil {
 		requestBodyStreamId = g.muxBroker.NextId()
+		served := make(chan struct{})
+		defer close(served)
 		go func() {
 			bodyConnection, err := g.muxBroker.Accept(requestBodyStreamId)
 			if err != nil {
 				g.log.Error("Plugin failed to ServeHTTP, muxBroker couldn't Accept request body connection", mlog.Err(err))
 				return
 			}
-			defer bodyConnection.Close()
-			serveIOReader(r.Body, bodyConnection)
+
+			// Stop sending the body once the plugin is done with the request, whether or not it
+			// read all of it.
+			go func() {
+				<-served
+				bodyConnection.Close()
+			}()
+			streamIOReader(r.Body, bodyConnection)
 		}()
 	}
 
@@ -324,6 +336,7 @@ func (g *hooksRPCClient) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Re
 		ResponseWriterStream: serveHTTPStreamId,
 		Request:              forwardedRequest,
 		RequestBodyStream:    requestBodyStreamId,
+		RequestBodyStreamed:  true,
 	}, nil); err != nil {
 		g.log.Error("Plugin failed to ServeHTTP, RPC call failed", mlog.Err(err))
 		http.Error(w, "500 internal server error", http.StatusInternalServerError)
@@ -336,7 +349,7 @@ func (s *hooksRPCServer) ServeHTTP(args *Z_ServeHTTPArgs, returns *struct{}) err
 		fmt.Fprintf(os.Stderr, "[ERROR] Can't connect to remote response writer stream, error: %v", err.Error())
 		return err
 	}
-	w := connectHTTPResponseWriter(connection)
+	w := connectHTTPResponseWriter(connection, s.muxBroker)
 	defer w.Close()
 
 	r := args.Request
@@ -346,7 +359,11 @@ func (s *hooksRPCServer) ServeHTTP(args *Z_ServeHTTPArgs, returns *struct{}) err
 			fmt.Fprintf(os.Stderr, "[ERROR] Can't connect to remote request body stream, error: %v", err.Error())
 			return err
 		}
-		r.Body = connectIOReader(connection)
+		if args.RequestBodyStreamed {
+			r.Body = connection
+		} else {
+			r.Body = connectIOReader(connection)
+		}
 	} else {
 		r.Body = ioutil.NopCloser(&bytes.Buffer{})
 	}
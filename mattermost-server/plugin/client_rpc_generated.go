@@ -475,6 +475,402 @@ func (s *hooksRPCServer) UserHasLeftTeam(args *Z_UserHasLeftTeamArgs, returns *Z
 	return nil
 }
 
+func init() {
+	hookNameToId["OnPluginCall"] = OnPluginCallId
+}
+
+type Z_OnPluginCallArgs struct {
+	A *Context
+	B string
+	C string
+	D []byte
+}
+
+type Z_OnPluginCallReturns struct {
+	A []byte
+	B *model.AppError
+}
+
+func (g *hooksRPCClient) OnPluginCall(c *Context, sourcePluginId, method string, payload []byte) ([]byte, *model.AppError) {
+	_args := &Z_OnPluginCallArgs{c, sourcePluginId, method, payload}
+	_returns := &Z_OnPluginCallReturns{}
+	if g.implemented[OnPluginCallId] {
+		if err := g.client.Call("Plugin.OnPluginCall", _args, _returns); err != nil {
+			g.log.Error("RPC call OnPluginCall to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *hooksRPCServer) OnPluginCall(args *Z_OnPluginCallArgs, returns *Z_OnPluginCallReturns) error {
+	if hook, ok := s.impl.(interface {
+		OnPluginCall(c *Context, sourcePluginId, method string, payload []byte) ([]byte, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.OnPluginCall(args.A, args.B, args.C, args.D)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook OnPluginCall called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ReactionHasBeenAdded"] = ReactionHasBeenAddedId
+}
+
+type Z_ReactionHasBeenAddedArgs struct {
+	A *Context
+	B *model.Reaction
+}
+
+type Z_ReactionHasBeenAddedReturns struct {
+}
+
+func (g *hooksRPCClient) ReactionHasBeenAdded(c *Context, reaction *model.Reaction) {
+	_args := &Z_ReactionHasBeenAddedArgs{c, reaction}
+	_returns := &Z_ReactionHasBeenAddedReturns{}
+	if g.implemented[ReactionHasBeenAddedId] {
+		if err := g.client.Call("Plugin.ReactionHasBeenAdded", _args, _returns); err != nil {
+			g.log.Error("RPC call ReactionHasBeenAdded to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ReactionHasBeenAdded(args *Z_ReactionHasBeenAddedArgs, returns *Z_ReactionHasBeenAddedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ReactionHasBeenAdded(c *Context, reaction *model.Reaction)
+	}); ok {
+		hook.ReactionHasBeenAdded(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook ReactionHasBeenAdded called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ReactionHasBeenRemoved"] = ReactionHasBeenRemovedId
+}
+
+type Z_ReactionHasBeenRemovedArgs struct {
+	A *Context
+	B *model.Reaction
+}
+
+type Z_ReactionHasBeenRemovedReturns struct {
+}
+
+func (g *hooksRPCClient) ReactionHasBeenRemoved(c *Context, reaction *model.Reaction) {
+	_args := &Z_ReactionHasBeenRemovedArgs{c, reaction}
+	_returns := &Z_ReactionHasBeenRemovedReturns{}
+	if g.implemented[ReactionHasBeenRemovedId] {
+		if err := g.client.Call("Plugin.ReactionHasBeenRemoved", _args, _returns); err != nil {
+			g.log.Error("RPC call ReactionHasBeenRemoved to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ReactionHasBeenRemoved(args *Z_ReactionHasBeenRemovedArgs, returns *Z_ReactionHasBeenRemovedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ReactionHasBeenRemoved(c *Context, reaction *model.Reaction)
+	}); ok {
+		hook.ReactionHasBeenRemoved(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook ReactionHasBeenRemoved called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelHasBeenUpdated"] = ChannelHasBeenUpdatedId
+}
+
+type Z_ChannelHasBeenUpdatedArgs struct {
+	A *Context
+	B *model.Channel
+	C *model.Channel
+}
+
+type Z_ChannelHasBeenUpdatedReturns struct {
+}
+
+func (g *hooksRPCClient) ChannelHasBeenUpdated(c *Context, newChannel, oldChannel *model.Channel) {
+	_args := &Z_ChannelHasBeenUpdatedArgs{c, newChannel, oldChannel}
+	_returns := &Z_ChannelHasBeenUpdatedReturns{}
+	if g.implemented[ChannelHasBeenUpdatedId] {
+		if err := g.client.Call("Plugin.ChannelHasBeenUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelHasBeenUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ChannelHasBeenUpdated(args *Z_ChannelHasBeenUpdatedArgs, returns *Z_ChannelHasBeenUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelHasBeenUpdated(c *Context, newChannel, oldChannel *model.Channel)
+	}); ok {
+		hook.ChannelHasBeenUpdated(args.A, args.B, args.C)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelHasBeenUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["ChannelHasBeenArchived"] = ChannelHasBeenArchivedId
+}
+
+type Z_ChannelHasBeenArchivedArgs struct {
+	A *Context
+	B *model.Channel
+	C *model.User
+}
+
+type Z_ChannelHasBeenArchivedReturns struct {
+}
+
+func (g *hooksRPCClient) ChannelHasBeenArchived(c *Context, channel *model.Channel, actor *model.User) {
+	_args := &Z_ChannelHasBeenArchivedArgs{c, channel, actor}
+	_returns := &Z_ChannelHasBeenArchivedReturns{}
+	if g.implemented[ChannelHasBeenArchivedId] {
+		if err := g.client.Call("Plugin.ChannelHasBeenArchived", _args, _returns); err != nil {
+			g.log.Error("RPC call ChannelHasBeenArchived to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) ChannelHasBeenArchived(args *Z_ChannelHasBeenArchivedArgs, returns *Z_ChannelHasBeenArchivedReturns) error {
+	if hook, ok := s.impl.(interface {
+		ChannelHasBeenArchived(c *Context, channel *model.Channel, actor *model.User)
+	}); ok {
+		hook.ChannelHasBeenArchived(args.A, args.B, args.C)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook ChannelHasBeenArchived called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["UserHasBeenUpdated"] = UserHasBeenUpdatedId
+}
+
+type Z_UserHasBeenUpdatedArgs struct {
+	A *Context
+	B *model.User
+	C *model.User
+}
+
+type Z_UserHasBeenUpdatedReturns struct {
+}
+
+func (g *hooksRPCClient) UserHasBeenUpdated(c *Context, newUser, oldUser *model.User) {
+	_args := &Z_UserHasBeenUpdatedArgs{c, newUser, oldUser}
+	_returns := &Z_UserHasBeenUpdatedReturns{}
+	if g.implemented[UserHasBeenUpdatedId] {
+		if err := g.client.Call("Plugin.UserHasBeenUpdated", _args, _returns); err != nil {
+			g.log.Error("RPC call UserHasBeenUpdated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) UserHasBeenUpdated(args *Z_UserHasBeenUpdatedArgs, returns *Z_UserHasBeenUpdatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		UserHasBeenUpdated(c *Context, newUser, oldUser *model.User)
+	}); ok {
+		hook.UserHasBeenUpdated(args.A, args.B, args.C)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook UserHasBeenUpdated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["UserHasBeenDeactivated"] = UserHasBeenDeactivatedId
+}
+
+type Z_UserHasBeenDeactivatedArgs struct {
+	A *Context
+	B *model.User
+}
+
+type Z_UserHasBeenDeactivatedReturns struct {
+}
+
+func (g *hooksRPCClient) UserHasBeenDeactivated(c *Context, user *model.User) {
+	_args := &Z_UserHasBeenDeactivatedArgs{c, user}
+	_returns := &Z_UserHasBeenDeactivatedReturns{}
+	if g.implemented[UserHasBeenDeactivatedId] {
+		if err := g.client.Call("Plugin.UserHasBeenDeactivated", _args, _returns); err != nil {
+			g.log.Error("RPC call UserHasBeenDeactivated to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) UserHasBeenDeactivated(args *Z_UserHasBeenDeactivatedArgs, returns *Z_UserHasBeenDeactivatedReturns) error {
+	if hook, ok := s.impl.(interface {
+		UserHasBeenDeactivated(c *Context, user *model.User)
+	}); ok {
+		hook.UserHasBeenDeactivated(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook UserHasBeenDeactivated called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["CommandWillBeExecuted"] = CommandWillBeExecutedId
+}
+
+type Z_CommandWillBeExecutedArgs struct {
+	A *Context
+	B *model.CommandArgs
+}
+
+type Z_CommandWillBeExecutedReturns struct {
+	A *model.CommandArgs
+	B string
+}
+
+func (g *hooksRPCClient) CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+	_args := &Z_CommandWillBeExecutedArgs{c, args}
+	_returns := &Z_CommandWillBeExecutedReturns{}
+	if g.implemented[CommandWillBeExecutedId] {
+		if err := g.client.Call("Plugin.CommandWillBeExecuted", _args, _returns); err != nil {
+			g.log.Error("RPC call CommandWillBeExecuted to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *hooksRPCServer) CommandWillBeExecuted(args *Z_CommandWillBeExecutedArgs, returns *Z_CommandWillBeExecutedReturns) error {
+	if hook, ok := s.impl.(interface {
+		CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string)
+	}); ok {
+		returns.A, returns.B = hook.CommandWillBeExecuted(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook CommandWillBeExecuted called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["RunScheduledJob"] = RunScheduledJobId
+}
+
+type Z_RunScheduledJobArgs struct {
+	A *Context
+	B string
+}
+
+type Z_RunScheduledJobReturns struct {
+}
+
+func (g *hooksRPCClient) RunScheduledJob(c *Context, jobName string) {
+	_args := &Z_RunScheduledJobArgs{c, jobName}
+	_returns := &Z_RunScheduledJobReturns{}
+	if g.implemented[RunScheduledJobId] {
+		if err := g.client.Call("Plugin.RunScheduledJob", _args, _returns); err != nil {
+			g.log.Error("RPC call RunScheduledJob to plugin failed.", mlog.Err(err))
+		}
+	}
+
+}
+
+func (s *hooksRPCServer) RunScheduledJob(args *Z_RunScheduledJobArgs, returns *Z_RunScheduledJobReturns) error {
+	if hook, ok := s.impl.(interface {
+		RunScheduledJob(c *Context, jobName string)
+	}); ok {
+		hook.RunScheduledJob(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook RunScheduledJob called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["PostActionTriggered"] = PostActionTriggeredId
+}
+
+type Z_PostActionTriggeredArgs struct {
+	A *Context
+	B *model.PostActionIntegrationRequest
+}
+
+type Z_PostActionTriggeredReturns struct {
+	A *model.PostActionIntegrationResponse
+}
+
+func (g *hooksRPCClient) PostActionTriggered(c *Context, request *model.PostActionIntegrationRequest) *model.PostActionIntegrationResponse {
+	_args := &Z_PostActionTriggeredArgs{c, request}
+	_returns := &Z_PostActionTriggeredReturns{}
+	if g.implemented[PostActionTriggeredId] {
+		if err := g.client.Call("Plugin.PostActionTriggered", _args, _returns); err != nil {
+			g.log.Error("RPC call PostActionTriggered to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) PostActionTriggered(args *Z_PostActionTriggeredArgs, returns *Z_PostActionTriggeredReturns) error {
+	if hook, ok := s.impl.(interface {
+		PostActionTriggered(c *Context, request *model.PostActionIntegrationRequest) *model.PostActionIntegrationResponse
+	}); ok {
+		returns.A = hook.PostActionTriggered(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook PostActionTriggered called but not implemented."))
+	}
+	return nil
+}
+
+func init() {
+	hookNameToId["DialogSubmitted"] = DialogSubmittedId
+}
+
+type Z_DialogSubmittedArgs struct {
+	A *Context
+	B *model.SubmitDialogRequest
+}
+
+type Z_DialogSubmittedReturns struct {
+	A *model.SubmitDialogResponse
+}
+
+func (g *hooksRPCClient) DialogSubmitted(c *Context, request *model.SubmitDialogRequest) *model.SubmitDialogResponse {
+	_args := &Z_DialogSubmittedArgs{c, request}
+	_returns := &Z_DialogSubmittedReturns{}
+	if g.implemented[DialogSubmittedId] {
+		if err := g.client.Call("Plugin.DialogSubmitted", _args, _returns); err != nil {
+			g.log.Error("RPC call DialogSubmitted to plugin failed.", mlog.Err(err))
+		}
+	}
+	return _returns.A
+}
+
+func (s *hooksRPCServer) DialogSubmitted(args *Z_DialogSubmittedArgs, returns *Z_DialogSubmittedReturns) error {
+	if hook, ok := s.impl.(interface {
+		DialogSubmitted(c *Context, request *model.SubmitDialogRequest) *model.SubmitDialogResponse
+	}); ok {
+		returns.A = hook.DialogSubmitted(args.A, args.B)
+
+	} else {
+		return encodableError(fmt.Errorf("Hook DialogSubmitted called but not implemented."))
+	}
+	return nil
+}
+
 type Z_RegisterCommandArgs struct {
 	A *model.Command
 }
@@ -3320,6 +3716,35 @@ func (s *apiRPCServer) OpenInteractiveDialog(args *Z_OpenInteractiveDialogArgs,
 	return nil
 }
 
+type Z_OpenInteractiveDialogForUserArgs struct {
+	A string
+	B model.OpenDialogRequest
+}
+
+type Z_OpenInteractiveDialogForUserReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) OpenInteractiveDialogForUser(userId string, dialog model.OpenDialogRequest) *model.AppError {
+	_args := &Z_OpenInteractiveDialogForUserArgs{userId, dialog}
+	_returns := &Z_OpenInteractiveDialogForUserReturns{}
+	if err := g.client.Call("Plugin.OpenInteractiveDialogForUser", _args, _returns); err != nil {
+		log.Printf("RPC call to OpenInteractiveDialogForUser API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) OpenInteractiveDialogForUser(args *Z_OpenInteractiveDialogForUserArgs, returns *Z_OpenInteractiveDialogForUserReturns) error {
+	if hook, ok := s.impl.(interface {
+		OpenInteractiveDialogForUser(userId string, dialog model.OpenDialogRequest) *model.AppError
+	}); ok {
+		returns.A = hook.OpenInteractiveDialogForUser(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("API OpenInteractiveDialogForUser called but not implemented."))
+	}
+	return nil
+}
+
 type Z_GetPluginsArgs struct {
 }
 
@@ -3461,6 +3886,121 @@ func (s *apiRPCServer) GetPluginStatus(args *Z_GetPluginStatusArgs, returns *Z_G
 	return nil
 }
 
+type Z_CallPluginArgs struct {
+	A string
+	B string
+	C []byte
+}
+
+type Z_CallPluginReturns struct {
+	A []byte
+	B *model.AppError
+}
+
+func (g *apiRPCClient) CallPlugin(pluginId, method string, payload []byte) ([]byte, *model.AppError) {
+	_args := &Z_CallPluginArgs{pluginId, method, payload}
+	_returns := &Z_CallPluginReturns{}
+	if err := g.client.Call("Plugin.CallPlugin", _args, _returns); err != nil {
+		log.Printf("RPC call to CallPlugin API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) CallPlugin(args *Z_CallPluginArgs, returns *Z_CallPluginReturns) error {
+	if hook, ok := s.impl.(interface {
+		CallPlugin(pluginId, method string, payload []byte) ([]byte, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.CallPlugin(args.A, args.B, args.C)
+	} else {
+		return encodableError(fmt.Errorf("API CallPlugin called but not implemented."))
+	}
+	return nil
+}
+
+type Z_RegisterScheduledJobArgs struct {
+	A *model.PluginScheduledJob
+}
+
+type Z_RegisterScheduledJobReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) RegisterScheduledJob(job *model.PluginScheduledJob) *model.AppError {
+	_args := &Z_RegisterScheduledJobArgs{job}
+	_returns := &Z_RegisterScheduledJobReturns{}
+	if err := g.client.Call("Plugin.RegisterScheduledJob", _args, _returns); err != nil {
+		log.Printf("RPC call to RegisterScheduledJob API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) RegisterScheduledJob(args *Z_RegisterScheduledJobArgs, returns *Z_RegisterScheduledJobReturns) error {
+	if hook, ok := s.impl.(interface {
+		RegisterScheduledJob(job *model.PluginScheduledJob) *model.AppError
+	}); ok {
+		returns.A = hook.RegisterScheduledJob(args.A)
+	} else {
+		return encodableError(fmt.Errorf("API RegisterScheduledJob called but not implemented."))
+	}
+	return nil
+}
+
+type Z_UnregisterScheduledJobArgs struct {
+	A string
+}
+
+type Z_UnregisterScheduledJobReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) UnregisterScheduledJob(name string) *model.AppError {
+	_args := &Z_UnregisterScheduledJobArgs{name}
+	_returns := &Z_UnregisterScheduledJobReturns{}
+	if err := g.client.Call("Plugin.UnregisterScheduledJob", _args, _returns); err != nil {
+		log.Printf("RPC call to UnregisterScheduledJob API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) UnregisterScheduledJob(args *Z_UnregisterScheduledJobArgs, returns *Z_UnregisterScheduledJobReturns) error {
+	if hook, ok := s.impl.(interface {
+		UnregisterScheduledJob(name string) *model.AppError
+	}); ok {
+		returns.A = hook.UnregisterScheduledJob(args.A)
+	} else {
+		return encodableError(fmt.Errorf("API UnregisterScheduledJob called but not implemented."))
+	}
+	return nil
+}
+
+type Z_GetScheduledJobsArgs struct {
+}
+
+type Z_GetScheduledJobsReturns struct {
+	A []*model.PluginScheduledJob
+	B *model.AppError
+}
+
+func (g *apiRPCClient) GetScheduledJobs() ([]*model.PluginScheduledJob, *model.AppError) {
+	_args := &Z_GetScheduledJobsArgs{}
+	_returns := &Z_GetScheduledJobsReturns{}
+	if err := g.client.Call("Plugin.GetScheduledJobs", _args, _returns); err != nil {
+		log.Printf("RPC call to GetScheduledJobs API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) GetScheduledJobs(args *Z_GetScheduledJobsArgs, returns *Z_GetScheduledJobsReturns) error {
+	if hook, ok := s.impl.(interface {
+		GetScheduledJobs() ([]*model.PluginScheduledJob, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.GetScheduledJobs()
+	} else {
+		return encodableError(fmt.Errorf("API GetScheduledJobs called but not implemented."))
+	}
+	return nil
+}
+
 type Z_KVSetArgs struct {
 	A string
 	B []byte
@@ -3521,6 +4061,64 @@ func (s *apiRPCServer) KVCompareAndSet(args *Z_KVCompareAndSetArgs, returns *Z_K
 	return nil
 }
 
+type Z_KVCompareAndDeleteArgs struct {
+	A string
+	B []byte
+}
+
+type Z_KVCompareAndDeleteReturns struct {
+	A bool
+	B *model.AppError
+}
+
+func (g *apiRPCClient) KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError) {
+	_args := &Z_KVCompareAndDeleteArgs{key, oldValue}
+	_returns := &Z_KVCompareAndDeleteReturns{}
+	if err := g.client.Call("Plugin.KVCompareAndDelete", _args, _returns); err != nil {
+		log.Printf("RPC call to KVCompareAndDelete API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) KVCompareAndDelete(args *Z_KVCompareAndDeleteArgs, returns *Z_KVCompareAndDeleteReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.KVCompareAndDelete(args.A, args.B)
+	} else {
+		return encodableError(fmt.Errorf("API KVCompareAndDelete called but not implemented."))
+	}
+	return nil
+}
+
+type Z_KVSetManyArgs struct {
+	A map[string][]byte
+}
+
+type Z_KVSetManyReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) KVSetMany(values map[string][]byte) *model.AppError {
+	_args := &Z_KVSetManyArgs{values}
+	_returns := &Z_KVSetManyReturns{}
+	if err := g.client.Call("Plugin.KVSetMany", _args, _returns); err != nil {
+		log.Printf("RPC call to KVSetMany API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) KVSetMany(args *Z_KVSetManyArgs, returns *Z_KVSetManyReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVSetMany(values map[string][]byte) *model.AppError
+	}); ok {
+		returns.A = hook.KVSetMany(args.A)
+	} else {
+		return encodableError(fmt.Errorf("API KVSetMany called but not implemented."))
+	}
+	return nil
+}
+
 type Z_KVSetWithExpiryArgs struct {
 	A string
 	B []byte
@@ -3608,6 +4206,34 @@ func (s *apiRPCServer) KVDelete(args *Z_KVDeleteArgs, returns *Z_KVDeleteReturns
 	return nil
 }
 
+type Z_KVDeleteManyArgs struct {
+	A []string
+}
+
+type Z_KVDeleteManyReturns struct {
+	A *model.AppError
+}
+
+func (g *apiRPCClient) KVDeleteMany(keys []string) *model.AppError {
+	_args := &Z_KVDeleteManyArgs{keys}
+	_returns := &Z_KVDeleteManyReturns{}
+	if err := g.client.Call("Plugin.KVDeleteMany", _args, _returns); err != nil {
+		log.Printf("RPC call to KVDeleteMany API failed: %s", err.Error())
+	}
+	return _returns.A
+}
+
+func (s *apiRPCServer) KVDeleteMany(args *Z_KVDeleteManyArgs, returns *Z_KVDeleteManyReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVDeleteMany(keys []string) *model.AppError
+	}); ok {
+		returns.A = hook.KVDeleteMany(args.A)
+	} else {
+		return encodableError(fmt.Errorf("API KVDeleteMany called but not implemented."))
+	}
+	return nil
+}
+
 type Z_KVDeleteAllArgs struct {
 }
 
@@ -3665,6 +4291,37 @@ func (s *apiRPCServer) KVList(args *Z_KVListArgs, returns *Z_KVListReturns) erro
 	return nil
 }
 
+type Z_KVListWithPrefixArgs struct {
+	A string
+	B int
+	C int
+}
+
+type Z_KVListWithPrefixReturns struct {
+	A []string
+	B *model.AppError
+}
+
+func (g *apiRPCClient) KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError) {
+	_args := &Z_KVListWithPrefixArgs{prefix, page, perPage}
+	_returns := &Z_KVListWithPrefixReturns{}
+	if err := g.client.Call("Plugin.KVListWithPrefix", _args, _returns); err != nil {
+		log.Printf("RPC call to KVListWithPrefix API failed: %s", err.Error())
+	}
+	return _returns.A, _returns.B
+}
+
+func (s *apiRPCServer) KVListWithPrefix(args *Z_KVListWithPrefixArgs, returns *Z_KVListWithPrefixReturns) error {
+	if hook, ok := s.impl.(interface {
+		KVListWithPrefix(prefix string, page, perPage int) ([]string, *model.AppError)
+	}); ok {
+		returns.A, returns.B = hook.KVListWithPrefix(args.A, args.B, args.C)
+	} else {
+		return encodableError(fmt.Errorf("API KVListWithPrefix called but not implemented."))
+	}
+	return nil
+}
+
 type Z_PublishWebSocketEventArgs struct {
 	A string
 	B map[string]interface{}
@@ -0,0 +1,153 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// sortByDependencies orders the plugins so that every plugin comes after the plugins it requires,
+// keeping the given order otherwise. Plugins whose manifest could not be loaded, as well as plugins
+// that are part of a dependency cycle, are kept but their position is otherwise unspecified.
+func sortByDependencies(plugins []*model.BundleInfo) []*model.BundleInfo {
+	byId := make(map[string]*model.BundleInfo, len(plugins))
+	for _, plugin := range plugins {
+		if plugin.Manifest != nil {
+			byId[plugin.Manifest.Id] = plugin
+		}
+	}
+
+	sorted := make([]*model.BundleInfo, 0, len(plugins))
+	visited := make(map[*model.BundleInfo]bool, len(plugins))
+
+	var visit func(plugin *model.BundleInfo)
+	visit = func(plugin *model.BundleInfo) {
+		if visited[plugin] {
+			return
+		}
+		visited[plugin] = true
+
+		if plugin.Manifest != nil {
+			for _, dependency := range plugin.Manifest.Requires {
+				if dependency == nil {
+					continue
+				}
+				if required, ok := byId[strings.ToLower(dependency.Id)]; ok {
+					visit(required)
+				}
+			}
+		}
+
+		sorted = append(sorted, plugin)
+	}
+
+	for _, plugin := range plugins {
+		visit(plugin)
+	}
+
+	return sorted
+}
+
+// findDependencyCycle returns the ids of the plugins forming a cycle of requirements that includes
+// the plugin with the given id, or nil if there isn't one.
+func findDependencyCycle(id string, plugins []*model.BundleInfo) []string {
+	byId := make(map[string]*model.Manifest, len(plugins))
+	for _, plugin := range plugins {
+		if plugin.Manifest != nil {
+			byId[plugin.Manifest.Id] = plugin.Manifest
+		}
+	}
+
+	visited := map[string]bool{}
+
+	var walk func(current string, path []string) []string
+	walk = func(current string, path []string) []string {
+		manifest, ok := byId[current]
+		if !ok || visited[current] {
+			return nil
+		}
+		visited[current] = true
+
+		for _, dependency := range manifest.Requires {
+			if dependency == nil {
+				continue
+			}
+
+			dependencyId := strings.ToLower(dependency.Id)
+			if dependencyId == id {
+				return append(path, current)
+			}
+			if cycle := walk(dependencyId, append(path, current)); cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return walk(id, nil)
+}
+
+// checkDependencies verifies that every plugin required by the given plugin is active and at a
+// supported version. Since plugins are activated in dependency order, a required plugin that isn't
+// active by then is either disabled or failed to start.
+func (env *Environment) checkDependencies(manifest *model.Manifest, plugins []*model.BundleInfo) error {
+	if len(manifest.Requires) == 0 {
+		return nil
+	}
+
+	if cycle := findDependencyCycle(manifest.Id, plugins); cycle != nil {
+		return fmt.Errorf("circular plugin dependency: %v", strings.Join(append(cycle, manifest.Id), " -> "))
+	}
+
+	for _, dependency := range manifest.Requires {
+		dependencyId := strings.ToLower(dependency.Id)
+
+		var installed *model.Manifest
+		for _, p := range plugins {
+			if p.Manifest != nil && p.Manifest.Id == dependencyId {
+				installed = p.Manifest
+				break
+			}
+		}
+
+		if installed == nil {
+			return fmt.Errorf("required plugin %v is not installed", dependencyId)
+		}
+
+		if !installed.Satisfies(dependency) {
+			return fmt.Errorf("required plugin %v is at version %v, but %v is required", dependencyId, installed.Version, dependency.Version)
+		}
+
+		p, ok := env.activePlugins.Load(dependencyId)
+		if !ok {
+			return fmt.Errorf("required plugin %v is disabled", dependencyId)
+		}
+		if p.(activePlugin).State != model.PluginStateRunning {
+			return fmt.Errorf("required plugin %v failed to start", dependencyId)
+		}
+	}
+
+	return nil
+}
+
+// activeDependents returns the ids of the running plugins requiring the plugin with the given id.
+// Since a plugin only starts once the plugins it requires are running, there are no cycles among
+// them.
+func (env *Environment) activeDependents(id string) []string {
+	var dependents []string
+	env.activePlugins.Range(func(key, value interface{}) bool {
+		ap := value.(activePlugin)
+		if ap.State == model.PluginStateRunning && ap.BundleInfo.Manifest.RequiresPlugin(id) {
+			dependents = append(dependents, ap.BundleInfo.Manifest.Id)
+		}
+
+		return true
+	})
+
+	return dependents
+}
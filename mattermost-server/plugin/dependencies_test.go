@@ -0,0 +1,299 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func bundleRequiring(id string, requires ...string) *model.BundleInfo {
+	manifest := &model.Manifest{Id: id}
+	for _, dependency := range requires {
+		manifest.Requires = append(manifest.Requires, &model.ManifestDependency{Id: dependency})
+	}
+
+	return &model.BundleInfo{Manifest: manifest}
+}
+
+func bundleIds(plugins []*model.BundleInfo) []string {
+	var ids []string
+	for _, plugin := range plugins {
+		if plugin.Manifest == nil {
+			ids = append(ids, "")
+		} else {
+			ids = append(ids, plugin.Manifest.Id)
+		}
+	}
+
+	return ids
+}
+
+func TestSortByDependencies(t *testing.T) {
+	t.Run("no dependencies", func(t *testing.T) {
+		plugins := []*model.BundleInfo{bundleRequiring("a"), bundleRequiring("b"), {}, bundleRequiring("c")}
+		assert.Equal(t, []string{"a", "b", "", "c"}, bundleIds(sortByDependencies(plugins)))
+	})
+
+	t.Run("dependencies first", func(t *testing.T) {
+		plugins := []*model.BundleInfo{
+			bundleRequiring("a", "c"),
+			bundleRequiring("b"),
+			bundleRequiring("c", "d", "b"),
+			bundleRequiring("d"),
+		}
+		assert.Equal(t, []string{"d", "b", "c", "a"}, bundleIds(sortByDependencies(plugins)))
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		plugins := []*model.BundleInfo{bundleRequiring("a", "missing"), bundleRequiring("b")}
+		assert.Equal(t, []string{"a", "b"}, bundleIds(sortByDependencies(plugins)))
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		plugins := []*model.BundleInfo{bundleRequiring("a", "b"), bundleRequiring("b", "a"), bundleRequiring("c")}
+		assert.ElementsMatch(t, []string{"a", "b", "c"}, bundleIds(sortByDependencies(plugins)))
+	})
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	plugins := []*model.BundleInfo{
+		bundleRequiring("a", "b"),
+		bundleRequiring("b", "c"),
+		bundleRequiring("c", "a"),
+		bundleRequiring("d", "a"),
+		bundleRequiring("e"),
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, findDependencyCycle("a", plugins))
+	assert.Equal(t, []string{"c", "a", "b"}, findDependencyCycle("c", plugins))
+	assert.Nil(t, findDependencyCycle("d", plugins))
+	assert.Nil(t, findDependencyCycle("e", plugins))
+	assert.Nil(t, findDependencyCycle("missing", plugins))
+}
+
+type dependencyTestEnvironment struct {
+	*Environment
+	pluginDir string
+}
+
+func newDependencyTestEnvironment(t *testing.T) *dependencyTestEnvironment {
+	pluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	webappPluginDir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+
+	logger := mlog.NewLogger(&mlog.LoggerConfiguration{
+		EnableConsole: true,
+		ConsoleLevel:  "error",
+	})
+
+	env, err := NewEnvironment(nil, pluginDir, webappPluginDir, logger)
+	require.NoError(t, err)
+
+	return &dependencyTestEnvironment{Environment: env, pluginDir: pluginDir}
+}
+
+func (env *dependencyTestEnvironment) tearDown() {
+	env.Shutdown()
+	os.RemoveAll(env.pluginDir)
+	os.RemoveAll(env.webappPluginDir)
+}
+
+// install adds a webapp only plugin, which can be activated without starting a process.
+func (env *dependencyTestEnvironment) install(t *testing.T, id, version string, requires ...string) {
+	dir := filepath.Join(env.pluginDir, id)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "webapp"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "webapp", "main.js"), []byte("// "+id), 0600))
+
+	var dependencies []string
+	for _, dependency := range requires {
+		parts := strings.SplitN(dependency, "@", 2)
+		if len(parts) == 1 {
+			dependencies = append(dependencies, fmt.Sprintf(`{"id": "%s"}`, parts[0]))
+		} else {
+			dependencies = append(dependencies, fmt.Sprintf(`{"id": "%s", "version": "%s"}`, parts[0], parts[1]))
+		}
+	}
+
+	manifest := fmt.Sprintf(`{"id": "%s", "version": "%s", "requires": [%s], "webapp": {"bundle_path": "webapp/main.js"}}`, id, version, strings.Join(dependencies, ", "))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "plugin.json"), []byte(manifest), 0600))
+}
+
+func (env *dependencyTestEnvironment) status(t *testing.T, id string) *model.PluginStatus {
+	statuses, err := env.Statuses()
+	require.NoError(t, err)
+
+	for _, status := range statuses {
+		if status.PluginId == id {
+			return status
+		}
+	}
+
+	require.FailNow(t, "missing plugin status", id)
+	return nil
+}
+
+func (env *dependencyTestEnvironment) running(id string) bool {
+	p, ok := env.activePlugins.Load(id)
+	return ok && p.(activePlugin).State == model.PluginStateRunning
+}
+
+func TestEnvironmentDependencies(t *testing.T) {
+	t.Run("activate after dependencies", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "identity", "1.2.0")
+		env.install(t, "profile", "1.0.0", "identity@>=1.0.0")
+
+		_, _, err := env.Activate("profile")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required plugin identity is disabled")
+
+		status := env.status(t, "profile")
+		assert.Equal(t, model.PluginStateFailedToStart, status.State)
+		assert.Contains(t, status.Error, "required plugin identity is disabled")
+		assert.Equal(t, "", env.status(t, "identity").Error)
+
+		_, activated, err := env.Activate("identity")
+		require.NoError(t, err)
+		assert.True(t, activated)
+
+		// The plugin failed for lack of a dependency, so it's activated again.
+		_, activated, err = env.Activate("profile")
+		require.NoError(t, err)
+		assert.True(t, activated)
+
+		status = env.status(t, "profile")
+		assert.Equal(t, model.PluginStateRunning, status.State)
+		assert.Equal(t, "", status.Error)
+	})
+
+	t.Run("missing dependency", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "profile", "1.0.0", "identity")
+
+		_, _, err := env.Activate("profile")
+		require.Error(t, err)
+		assert.Contains(t, env.status(t, "profile").Error, "required plugin identity is not installed")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "identity", "0.9.0")
+		env.install(t, "profile", "1.0.0", "identity@>=1.0.0")
+
+		_, _, err := env.Activate("identity")
+		require.NoError(t, err)
+
+		_, _, err = env.Activate("profile")
+		require.Error(t, err)
+		assert.Contains(t, env.status(t, "profile").Error, "required plugin identity is at version 0.9.0, but >=1.0.0 is required")
+	})
+
+	t.Run("dependency failed to start", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "identity", "1.0.0")
+		env.install(t, "profile", "1.0.0", "identity")
+		require.NoError(t, os.Remove(filepath.Join(env.pluginDir, "identity", "webapp", "main.js")))
+
+		_, _, err := env.Activate("identity")
+		require.Error(t, err)
+
+		_, _, err = env.Activate("profile")
+		require.Error(t, err)
+		assert.Contains(t, env.status(t, "profile").Error, "required plugin identity failed to start")
+	})
+
+	t.Run("circular dependency", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "a", "1.0.0", "b")
+		env.install(t, "b", "1.0.0", "a")
+
+		_, _, err := env.Activate("a")
+		require.Error(t, err)
+		assert.Contains(t, env.status(t, "a").Error, "circular plugin dependency: a -> b -> a")
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "profile", "1.0.0", "profile")
+
+		_, _, err := env.Activate("profile")
+		require.Error(t, err)
+		assert.Contains(t, env.status(t, "profile").Error, "invalid manifest")
+	})
+
+	t.Run("available in dependency order", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "a", "1.0.0", "c")
+		env.install(t, "b", "1.0.0")
+		env.install(t, "c", "1.0.0", "b")
+
+		plugins, err := env.Available()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "c", "a"}, bundleIds(plugins))
+	})
+
+	t.Run("deactivate dependents", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "identity", "1.0.0")
+		env.install(t, "profile", "1.0.0", "identity")
+		env.install(t, "directory", "1.0.0", "profile")
+		env.install(t, "other", "1.0.0")
+
+		for _, id := range []string{"identity", "profile", "directory", "other"} {
+			_, _, err := env.Activate(id)
+			require.NoError(t, err)
+		}
+
+		assert.True(t, env.Deactivate("identity"))
+		assert.False(t, env.IsActive("identity"))
+		assert.False(t, env.IsActive("profile"))
+		assert.False(t, env.IsActive("directory"))
+		assert.True(t, env.running("other"))
+	})
+
+	t.Run("restart dependents", func(t *testing.T) {
+		env := newDependencyTestEnvironment(t)
+		defer env.tearDown()
+
+		env.install(t, "identity", "1.0.0")
+		env.install(t, "profile", "1.0.0", "identity")
+
+		for _, id := range []string{"identity", "profile"} {
+			_, _, err := env.Activate(id)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, env.RestartPlugin("identity"))
+		assert.True(t, env.running("identity"))
+		assert.True(t, env.running("profile"))
+	})
+}
@@ -22,8 +22,13 @@ type apiImplCreatorFunc func(*model.Manifest) API
 type activePlugin struct {
 	BundleInfo *model.BundleInfo
 	State      int
+	Error      error
 
 	supervisor *supervisor
+
+	// missingDependencies is set if the plugin failed to start because a plugin it requires
+	// wasn't active, in which case activation is attempted again.
+	missingDependencies bool
 }
 
 // Environment represents the execution environment of active plugins.
@@ -36,6 +41,7 @@ type Environment struct {
 	pluginHealthCheckJob *PluginHealthCheckJob
 	logger               *mlog.Logger
 	newAPIImpl           apiImplCreatorFunc
+	resourceLimits       func(pluginId string) ResourceLimits
 	pluginDir            string
 	webappPluginDir      string
 }
@@ -73,9 +79,15 @@ func scanSearchPath(path string) ([]*model.BundleInfo, error) {
 	return ret, nil
 }
 
-// Returns a list of all plugins within the environment.
+// Returns a list of all plugins within the environment, ordered so that plugins come after the
+// plugins they require.
 func (env *Environment) Available() ([]*model.BundleInfo, error) {
-	return scanSearchPath(env.pluginDir)
+	plugins, err := scanSearchPath(env.pluginDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return sortByDependencies(plugins), nil
 }
 
 // Returns a list of all currently active plugins within the environment.
@@ -120,8 +132,12 @@ func (env *Environment) Statuses() (model.PluginStatuses, error) {
 		}
 
 		pluginState := model.PluginStateNotRunning
+		pluginError := ""
 		if plugin, ok := env.activePlugins.Load(plugin.Manifest.Id); ok {
 			pluginState = plugin.(activePlugin).State
+			if err := plugin.(activePlugin).Error; err != nil {
+				pluginError = err.Error()
+			}
 		}
 
 		status := &model.PluginStatus{
@@ -131,6 +147,11 @@ func (env *Environment) Statuses() (model.PluginStatuses, error) {
 			Name:        plugin.Manifest.Name,
 			Description: plugin.Manifest.Description,
 			Version:     plugin.Manifest.Version,
+			Error:       pluginError,
+		}
+
+		if health, ok := env.pluginHealthStatuses.Load(plugin.Manifest.Id); ok {
+			health.(*PluginHealthStatus).fillStatus(status)
 		}
 
 		pluginStatuses = append(pluginStatuses, status)
@@ -139,10 +160,16 @@ func (env *Environment) Statuses() (model.PluginStatuses, error) {
 	return pluginStatuses, nil
 }
 
+// Activate activates the plugin with the given id, provided that the plugins it requires are
+// active. Plugins that failed to start for lack of a required plugin are activated again once
+// their requirements are met.
 func (env *Environment) Activate(id string) (manifest *model.Manifest, activated bool, reterr error) {
 	// Check if we are already active
-	if _, ok := env.activePlugins.Load(id); ok {
-		return nil, false, nil
+	if p, ok := env.activePlugins.Load(id); ok {
+		if !p.(activePlugin).missingDependencies {
+			return nil, false, nil
+		}
+		env.activePlugins.Delete(id)
 	}
 
 	plugins, err := env.Available()
@@ -168,10 +195,15 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 			ap.State = model.PluginStateRunning
 		} else {
 			ap.State = model.PluginStateFailedToStart
+			ap.Error = reterr
 		}
 		env.activePlugins.Store(pluginInfo.Manifest.Id, ap)
 	}()
 
+	if appErr := pluginInfo.Manifest.IsValid(); appErr != nil {
+		return nil, false, errors.Wrapf(appErr, "invalid manifest: %v", id)
+	}
+
 	if pluginInfo.Manifest.MinServerVersion != "" {
 		fulfilled, err := pluginInfo.Manifest.MeetMinServerVersion(model.CurrentVersion)
 		if err != nil {
@@ -182,6 +214,11 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 		}
 	}
 
+	if err := env.checkDependencies(pluginInfo.Manifest, plugins); err != nil {
+		ap.missingDependencies = true
+		return nil, false, errors.Wrapf(err, "unable to start plugin: %v", id)
+	}
+
 	componentActivated := false
 
 	if pluginInfo.Manifest.HasWebapp() {
@@ -222,7 +259,12 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 	}
 
 	if pluginInfo.Manifest.HasServer() {
-		sup, err := newSupervisor(pluginInfo, env.logger, env.newAPIImpl(pluginInfo.Manifest))
+		var limits ResourceLimits
+		if env.resourceLimits != nil {
+			limits = env.resourceLimits(id)
+		}
+
+		sup, err := newSupervisor(pluginInfo, env.logger, env.newAPIImpl(pluginInfo.Manifest), limits)
 		if err != nil {
 			return nil, false, errors.Wrapf(err, "unable to start plugin: %v", id)
 		}
@@ -230,14 +272,11 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 
 		componentActivated = true
 
-		var h *PluginHealthStatus
-		if health, ok := env.pluginHealthStatuses.Load(id); ok {
-			h = health.(*PluginHealthStatus)
-		} else {
-			h = newPluginHealthStatus()
-			env.pluginHealthStatuses.Store(id, h)
-		}
+		health, _ := env.pluginHealthStatuses.LoadOrStore(id, newPluginHealthStatus())
+		h := health.(*PluginHealthStatus)
+		h.mutex.Lock()
 		h.Crashed = false
+		h.mutex.Unlock()
 	}
 
 	if !componentActivated {
@@ -247,13 +286,18 @@ func (env *Environment) Activate(id string) (manifest *model.Manifest, activated
 	return pluginInfo.Manifest, true, nil
 }
 
-// Deactivates the plugin with the given id.
+// Deactivates the plugin with the given id, after deactivating the plugins requiring it.
 func (env *Environment) Deactivate(id string) bool {
 	p, ok := env.activePlugins.Load(id)
 	if !ok {
 		return false
 	}
 
+	// Plugins requiring this one may still use it while being deactivated.
+	for _, dependent := range env.activeDependents(id) {
+		env.Deactivate(dependent)
+	}
+
 	env.activePlugins.Delete(id)
 
 	ap := p.(activePlugin)
@@ -267,43 +311,82 @@ func (env *Environment) Deactivate(id string) bool {
 	return true
 }
 
-// RestartPlugin deactivates, then activates the plugin with the given id.
+// RestartPlugin deactivates, then activates the plugin with the given id, along with the plugins
+// requiring it.
 func (env *Environment) RestartPlugin(id string) error {
+	wasActive := map[string]bool{}
+	for _, plugin := range env.Active() {
+		wasActive[plugin.Manifest.Id] = true
+	}
+
 	env.Deactivate(id)
-	_, _, err := env.Activate(id)
-	return err
+	if _, _, err := env.Activate(id); err != nil {
+		return err
+	}
+
+	plugins, err := env.Available()
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range plugins {
+		if plugin.Manifest != nil && wasActive[plugin.Manifest.Id] && !env.IsActive(plugin.Manifest.Id) {
+			if _, _, err := env.Activate(plugin.Manifest.Id); err != nil {
+				env.logger.Error("Unable to activate plugin after restarting a plugin it requires", mlog.String("plugin_id", plugin.Manifest.Id), mlog.Err(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// stopCrashedPlugin shuts down the server component of a plugin that failed its health check. The
+// plugin is kept active, in a failed state, until the health check job restarts it.
+func (env *Environment) stopCrashedPlugin(id string, err error) {
+	p, ok := env.activePlugins.Load(id)
+	if !ok {
+		return
+	}
+
+	ap := p.(activePlugin)
+	if ap.supervisor != nil {
+		ap.supervisor.Shutdown()
+		ap.supervisor = nil
+	}
+	ap.State = model.PluginStateFailedToStayRunning
+	ap.Error = err
+
+	env.activePlugins.Store(id, ap)
 }
 
 // UpdatePluginHealthStatus accepts a callback to edit the stored health status of the plugin.
 func (env *Environment) UpdatePluginHealthStatus(id string, callback func(*PluginHealthStatus)) {
 	if h, ok := env.pluginHealthStatuses.Load(id); ok {
-		callback(h.(*PluginHealthStatus))
+		health := h.(*PluginHealthStatus)
+		health.mutex.Lock()
+		defer health.mutex.Unlock()
+		callback(health)
 	}
 }
 
 // CheckPluginHealthStatus checks if the plugin is in a failed state, based on information gathered from previous health checks.
 func (env *Environment) CheckPluginHealthStatus(id string) error {
 	if h, ok := env.pluginHealthStatuses.Load(id); ok {
-		if h.(*PluginHealthStatus).Crashed {
-			return h.(*PluginHealthStatus).lastError
+		health := h.(*PluginHealthStatus)
+		health.mutex.Lock()
+		defer health.mutex.Unlock()
+		if health.Crashed {
+			return health.lastError
 		}
 	}
 	return nil
 }
 
-// Shutdown deactivates all plugins and gracefully shuts down the environment.
+// Shutdown deactivates all plugins, each after the plugins requiring it, and gracefully shuts down
+// the environment.
 func (env *Environment) Shutdown() {
 	env.activePlugins.Range(func(key, value interface{}) bool {
-		ap := value.(activePlugin)
-
-		if ap.supervisor != nil {
-			if err := ap.supervisor.Hooks().OnDeactivate(); err != nil {
-				env.logger.Error("Plugin OnDeactivate() error", mlog.String("plugin_id", ap.BundleInfo.Manifest.Id), mlog.Err(err))
-			}
-			ap.supervisor.Shutdown()
-		}
-
-		env.activePlugins.Delete(key)
+		env.Deactivate(key.(string))
 
 		return true
 	})
@@ -323,6 +406,16 @@ func (env *Environment) HooksForPlugin(id string) (Hooks, error) {
 	return nil, fmt.Errorf("plugin not found: %v", id)
 }
 
+// PluginImplements returns whether the plugin with the given id is active and implements the given hook.
+func (env *Environment) PluginImplements(id string, hookId int) bool {
+	if p, ok := env.activePlugins.Load(id); ok {
+		ap := p.(activePlugin)
+		return ap.supervisor != nil && ap.supervisor.Implements(hookId)
+	}
+
+	return false
+}
+
 // RunMultiPluginHook invokes hookRunnerFunc for each plugin that implements the given hookId.
 //
 // If hookRunnerFunc returns false, iteration will not continue. The iteration order among active
@@ -5,16 +5,20 @@ package plugin
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
 )
 
 const (
-	HEALTH_CHECK_INTERVAL         = 30 // seconds. How often the health check should run
-	HEALTH_CHECK_DISABLE_DURATION = 60 // minutes. How long we wait for num fails to incur before disabling the plugin
-	HEALTH_CHECK_PING_FAIL_LIMIT  = 3  // How many times we call RPC ping in a row before it is considered a failure
-	HEALTH_CHECK_RESTART_LIMIT    = 3  // How many times we restart a plugin before we disable it
+	HEALTH_CHECK_INTERVAL        = 30 // seconds. How often the health check should run
+	HEALTH_CHECK_PING_FAIL_LIMIT = 3  // How many times we call RPC ping in a row before it is considered a failure
+	HEALTH_CHECK_BACKOFF_BASE    = 1  // minutes. How long we wait before restarting a plugin that failed twice in a row
+	HEALTH_CHECK_BACKOFF_MAX     = 60 // minutes. The longest we wait before restarting a plugin
+	HEALTH_CHECK_BACKOFF_RESET   = 60 // minutes. How long a plugin must stay healthy for its failures to be forgotten
+	HEALTH_CHECK_RESTART_HISTORY = 10 // How many restarts are kept in the history of a plugin
 )
 
 type PluginHealthCheckJob struct {
@@ -24,9 +28,16 @@ type PluginHealthCheckJob struct {
 }
 
 type PluginHealthStatus struct {
-	Crashed        bool
-	failTimeStamps []time.Time
-	lastError      error
+	mutex sync.Mutex
+
+	// Crashed is set while the plugin is waiting to be restarted.
+	Crashed bool
+
+	consecutiveFailures int
+	lastFailure         time.Time
+	lastError           error
+	nextRestart         time.Time
+	restarts            []time.Time
 }
 
 // InitPluginHealthCheckJob starts a new job if one is not running and is set to enabled, or kills an existing one if set to disabled.
@@ -72,7 +83,8 @@ func (job *PluginHealthCheckJob) Start() {
 	}()
 }
 
-// checkPlugin determines the plugin's health status, then handles the error or success case.
+// checkPlugin determines the plugin's health status, then handles the error or success case. A
+// crashed plugin is restarted once its backoff delay has elapsed.
 func (job *PluginHealthCheckJob) checkPlugin(id string) {
 	p, ok := job.env.activePlugins.Load(id)
 	if !ok {
@@ -80,8 +92,19 @@ func (job *PluginHealthCheckJob) checkPlugin(id string) {
 	}
 	ap := p.(activePlugin)
 
-	if _, ok := job.env.pluginHealthStatuses.Load(id); !ok {
-		job.env.pluginHealthStatuses.Store(id, newPluginHealthStatus())
+	health, _ := job.env.pluginHealthStatuses.LoadOrStore(id, newPluginHealthStatus())
+	h := health.(*PluginHealthStatus)
+
+	h.mutex.Lock()
+	crashed, nextRestart := h.Crashed, h.nextRestart
+	h.mutex.Unlock()
+
+	if crashed {
+		if time.Now().Before(nextRestart) {
+			return
+		}
+		job.restartPlugin(id, h)
+		return
 	}
 
 	sup := ap.supervisor
@@ -94,10 +117,14 @@ func (job *PluginHealthCheckJob) checkPlugin(id string) {
 	if pluginErr != nil {
 		mlog.Error(fmt.Sprintf("Health check failed for plugin %s, error: %s", id, pluginErr.Error()))
 		job.handleHealthCheckFail(id, pluginErr)
+		return
 	}
+
+	h.recordSuccess(time.Now())
 }
 
-// handleHealthCheckFail restarts or deactivates the plugin based on how many times it has failed in a configured amount of time.
+// handleHealthCheckFail restarts the plugin, right away if it was healthy until now, or after a
+// delay growing exponentially with the number of failures in a row otherwise.
 func (job *PluginHealthCheckJob) handleHealthCheckFail(id string, err error) {
 	health, ok := job.env.pluginHealthStatuses.Load(id)
 	if !ok {
@@ -105,20 +132,25 @@ func (job *PluginHealthCheckJob) handleHealthCheckFail(id string, err error) {
 	}
 	h := health.(*PluginHealthStatus)
 
-	// Append current failure before checking for deactivate vs restart action
-	h.failTimeStamps = append(h.failTimeStamps, time.Now())
-	h.lastError = err
+	delay := h.recordFailure(time.Now(), err)
+	if delay == 0 {
+		job.restartPlugin(id, h)
+		return
+	}
 
-	if shouldDeactivatePlugin(h) {
-		h.failTimeStamps = []time.Time{}
-		h.Crashed = true
-		mlog.Debug(fmt.Sprintf("Deactivating plugin due to multiple crashes `%s`", id))
-		job.env.Deactivate(id)
-	} else {
-		mlog.Debug(fmt.Sprintf("Restarting plugin due to failed health check `%s`", id))
-		if err := job.env.RestartPlugin(id); err != nil {
-			mlog.Error(fmt.Sprintf("Failed to restart plugin `%s`: %s", id, err.Error()))
-		}
+	mlog.Debug(fmt.Sprintf("Restarting plugin `%s` in %v due to repeated failures", id, delay))
+	job.env.stopCrashedPlugin(id, err)
+}
+
+// restartPlugin restarts a plugin that failed, scheduling another attempt if it fails to start.
+func (job *PluginHealthCheckJob) restartPlugin(id string, h *PluginHealthStatus) {
+	mlog.Debug(fmt.Sprintf("Restarting plugin due to failed health check `%s`", id))
+
+	h.recordRestart(time.Now())
+
+	if err := job.env.RestartPlugin(id); err != nil {
+		mlog.Error(fmt.Sprintf("Failed to restart plugin `%s`: %s", id, err.Error()))
+		h.recordFailure(time.Now(), err)
 	}
 }
 
@@ -136,21 +168,82 @@ func (job *PluginHealthCheckJob) Cancel() {
 }
 
 func newPluginHealthStatus() *PluginHealthStatus {
-	return &PluginHealthStatus{failTimeStamps: []time.Time{}, Crashed: false}
+	return &PluginHealthStatus{restarts: []time.Time{}, Crashed: false}
 }
 
-// shouldDeactivatePlugin determines if a plugin needs to be deactivated after certain criteria is met.
-//
-// The criteria is based on if the plugin has consistently failed during the configured number of restarts, within the configured time window.
-func shouldDeactivatePlugin(h *PluginHealthStatus) bool {
-	if len(h.failTimeStamps) >= HEALTH_CHECK_RESTART_LIMIT {
-		index := len(h.failTimeStamps) - HEALTH_CHECK_RESTART_LIMIT
-		t := h.failTimeStamps[index]
-		now := time.Now()
-		elapsed := now.Sub(t).Minutes()
-		if elapsed <= HEALTH_CHECK_DISABLE_DURATION {
-			return true
-		}
+// recordFailure records a failure of the plugin and returns how long to wait before restarting it.
+func (h *PluginHealthStatus) recordFailure(now time.Time, err error) time.Duration {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.consecutiveFailures++
+	h.lastFailure = now
+	h.lastError = err
+
+	delay := restartBackoff(h.consecutiveFailures)
+	h.Crashed = delay > 0
+	h.nextRestart = now.Add(delay)
+
+	return delay
+}
+
+// recordSuccess forgets the past failures of a plugin that stayed healthy long enough.
+func (h *PluginHealthStatus) recordSuccess(now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.consecutiveFailures > 0 && now.Sub(h.lastFailure) >= HEALTH_CHECK_BACKOFF_RESET*time.Minute {
+		h.consecutiveFailures = 0
+	}
+}
+
+// recordRestart adds a restart to the history of the plugin.
+func (h *PluginHealthStatus) recordRestart(now time.Time) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.Crashed = false
+	h.restarts = append(h.restarts, now)
+	if len(h.restarts) > HEALTH_CHECK_RESTART_HISTORY {
+		h.restarts = h.restarts[len(h.restarts)-HEALTH_CHECK_RESTART_HISTORY:]
+	}
+}
+
+// fillStatus reports the restart history and last failure of the plugin.
+func (h *PluginHealthStatus) fillStatus(status *model.PluginStatus) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, restart := range h.restarts {
+		status.RestartHistory = append(status.RestartHistory, restart.UnixNano()/int64(time.Millisecond))
+	}
+
+	if h.lastError != nil {
+		status.LastCrashReason = h.lastError.Error()
+		status.LastCrashAt = h.lastFailure.UnixNano() / int64(time.Millisecond)
+	}
+
+	if h.Crashed {
+		status.NextRestartAt = h.nextRestart.UnixNano() / int64(time.Millisecond)
+	}
+}
+
+// restartBackoff returns how long to wait before restarting a plugin after the given number of
+// failures in a row: a plugin is restarted right away after its first failure, then after a delay
+// doubling with each failure, up to HEALTH_CHECK_BACKOFF_MAX.
+func restartBackoff(consecutiveFailures int) time.Duration {
+	if consecutiveFailures <= 1 {
+		return 0
 	}
-	return false
+
+	delay := HEALTH_CHECK_BACKOFF_BASE * time.Minute
+	for i := 2; i < consecutiveFailures && delay < HEALTH_CHECK_BACKOFF_MAX*time.Minute; i++ {
+		delay *= 2
+	}
+
+	if delay > HEALTH_CHECK_BACKOFF_MAX*time.Minute {
+		delay = HEALTH_CHECK_BACKOFF_MAX * time.Minute
+	}
+
+	return delay
 }
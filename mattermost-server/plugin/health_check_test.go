@@ -4,6 +4,7 @@
 package plugin
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -59,7 +60,7 @@ func testPluginHealthCheck_Success(t *testing.T) {
 		EnableFile:    false,
 	})
 
-	supervisor, err := newSupervisor(bundle, log, nil)
+	supervisor, err := newSupervisor(bundle, log, nil, ResourceLimits{})
 	require.Nil(t, err)
 	require.NotNil(t, supervisor)
 
@@ -105,7 +106,7 @@ func testPluginHealthCheck_PluginPanicProcessCheck(t *testing.T) {
 		EnableFile:    false,
 	})
 
-	supervisor, err := newSupervisor(bundle, log, nil)
+	supervisor, err := newSupervisor(bundle, log, nil, ResourceLimits{})
 	require.Nil(t, err)
 	require.NotNil(t, supervisor)
 
@@ -153,7 +154,7 @@ func testPluginHealthCheck_RPCPingFail(t *testing.T) {
 		EnableFile:    false,
 	})
 
-	supervisor, err := newSupervisor(bundle, log, nil)
+	supervisor, err := newSupervisor(bundle, log, nil, ResourceLimits{})
 	require.Nil(t, err)
 	require.NotNil(t, supervisor)
 
@@ -169,39 +170,51 @@ func testPluginHealthCheck_RPCPingFail(t *testing.T) {
 	require.Equal(t, "Plugin RPC connection is not responding", err.Error())
 }
 
-func TestShouldDeactivatePlugin(t *testing.T) {
-	h := newPluginHealthStatus()
-	require.NotNil(t, h)
-
-	// No failures, don't restart
-	result := shouldDeactivatePlugin(h)
-	require.Equal(t, false, result)
+func TestRestartBackoff(t *testing.T) {
+	require.Equal(t, time.Duration(0), restartBackoff(0))
+	require.Equal(t, time.Duration(0), restartBackoff(1))
+	require.Equal(t, 1*time.Minute, restartBackoff(2))
+	require.Equal(t, 2*time.Minute, restartBackoff(3))
+	require.Equal(t, 4*time.Minute, restartBackoff(4))
+	require.Equal(t, 32*time.Minute, restartBackoff(7))
+	require.Equal(t, HEALTH_CHECK_BACKOFF_MAX*time.Minute, restartBackoff(8))
+	require.Equal(t, HEALTH_CHECK_BACKOFF_MAX*time.Minute, restartBackoff(1000))
+}
 
+func TestPluginHealthStatus(t *testing.T) {
 	now := time.Now()
+	h := newPluginHealthStatus()
 
-	// Failures are recent enough to restart
-	h = newPluginHealthStatus()
-	h.failTimeStamps = append(h.failTimeStamps, now.Add(-HEALTH_CHECK_DISABLE_DURATION*0.2*time.Minute))
-	h.failTimeStamps = append(h.failTimeStamps, now.Add(-HEALTH_CHECK_DISABLE_DURATION*0.1*time.Minute))
-	h.failTimeStamps = append(h.failTimeStamps, now)
-
-	result = shouldDeactivatePlugin(h)
-	require.Equal(t, true, result)
-
-	// Failures are too spaced out to warrant a restart
-	h = newPluginHealthStatus()
-	h.failTimeStamps = append(h.failTimeStamps, now.Add(-HEALTH_CHECK_DISABLE_DURATION*2*time.Minute))
-	h.failTimeStamps = append(h.failTimeStamps, now.Add(-HEALTH_CHECK_DISABLE_DURATION*1*time.Minute))
-	h.failTimeStamps = append(h.failTimeStamps, now)
-
-	result = shouldDeactivatePlugin(h)
-	require.Equal(t, false, result)
-
-	// Not enough failures are present to warrant a restart
-	h = newPluginHealthStatus()
-	h.failTimeStamps = append(h.failTimeStamps, now.Add(-HEALTH_CHECK_DISABLE_DURATION*0.1*time.Minute))
-	h.failTimeStamps = append(h.failTimeStamps, now)
-
-	result = shouldDeactivatePlugin(h)
-	require.Equal(t, false, result)
+	// The first failure restarts the plugin right away.
+	require.Equal(t, time.Duration(0), h.recordFailure(now, errors.New("first")))
+	require.False(t, h.Crashed)
+	h.recordRestart(now)
+
+	// Failures in a row delay the restart.
+	require.Equal(t, 1*time.Minute, h.recordFailure(now.Add(time.Minute), errors.New("second")))
+	require.True(t, h.Crashed)
+
+	status := &model.PluginStatus{}
+	h.fillStatus(status)
+	require.Equal(t, "second", status.LastCrashReason)
+	require.Len(t, status.RestartHistory, 1)
+	require.Equal(t, now.Add(2*time.Minute).UnixNano()/int64(time.Millisecond), status.NextRestartAt)
+
+	h.recordRestart(now.Add(2 * time.Minute))
+	require.False(t, h.Crashed)
+
+	// Staying healthy for a while resets the backoff.
+	h.recordSuccess(now.Add(2 * time.Minute))
+	require.Equal(t, 2*time.Minute, h.recordFailure(now.Add(3*time.Minute), errors.New("third")))
+	h.recordRestart(now.Add(5 * time.Minute))
+	h.recordSuccess(now.Add(3*time.Minute + HEALTH_CHECK_BACKOFF_RESET*time.Minute))
+	require.Equal(t, time.Duration(0), h.recordFailure(now.Add(4*time.Hour), errors.New("fourth")))
+
+	// The restart history is bounded.
+	for i := 0; i < 2*HEALTH_CHECK_RESTART_HISTORY; i++ {
+		h.recordRestart(now)
+	}
+	status = &model.PluginStatus{}
+	h.fillStatus(status)
+	require.Len(t, status.RestartHistory, HEALTH_CHECK_RESTART_HISTORY)
 }
@@ -15,25 +15,36 @@ import (
 // Feel free to add more, but do not change existing assignments. Follow the naming convention of
 // <HookName>Id as the autogenerated glue code depends on that.
 const (
-	OnActivateId            = 0
-	OnDeactivateId          = 1
-	ServeHTTPId             = 2
-	OnConfigurationChangeId = 3
-	ExecuteCommandId        = 4
-	MessageWillBePostedId   = 5
-	MessageWillBeUpdatedId  = 6
-	MessageHasBeenPostedId  = 7
-	MessageHasBeenUpdatedId = 8
-	UserHasJoinedChannelId  = 9
-	UserHasLeftChannelId    = 10
-	UserHasJoinedTeamId     = 11
-	UserHasLeftTeamId       = 12
-	ChannelHasBeenCreatedId = 13
-	FileWillBeUploadedId    = 14
-	UserWillLogInId         = 15
-	UserHasLoggedInId       = 16
-	UserHasBeenCreatedId    = 17
-	TotalHooksId            = iota
+	OnActivateId             = 0
+	OnDeactivateId           = 1
+	ServeHTTPId              = 2
+	OnConfigurationChangeId  = 3
+	ExecuteCommandId         = 4
+	MessageWillBePostedId    = 5
+	MessageWillBeUpdatedId   = 6
+	MessageHasBeenPostedId   = 7
+	MessageHasBeenUpdatedId  = 8
+	UserHasJoinedChannelId   = 9
+	UserHasLeftChannelId     = 10
+	UserHasJoinedTeamId      = 11
+	UserHasLeftTeamId        = 12
+	ChannelHasBeenCreatedId  = 13
+	FileWillBeUploadedId     = 14
+	UserWillLogInId          = 15
+	UserHasLoggedInId        = 16
+	UserHasBeenCreatedId     = 17
+	OnPluginCallId           = 18
+	ReactionHasBeenAddedId   = 19
+	ReactionHasBeenRemovedId = 20
+	ChannelHasBeenUpdatedId  = 21
+	ChannelHasBeenArchivedId = 22
+	UserHasBeenUpdatedId     = 23
+	UserHasBeenDeactivatedId = 24
+	CommandWillBeExecutedId  = 25
+	RunScheduledJobId        = 26
+	PostActionTriggeredId    = 27
+	DialogSubmittedId        = 28
+	TotalHooksId             = iota
 )
 
 // Hooks describes the methods a plugin may implement to automatically receive the corresponding
@@ -149,4 +160,94 @@ type Hooks interface {
 	// Note that this method will be called for files uploaded by plugins, including the plugin that uploaded the post.
 	// FileInfo.Size will be automatically set properly if you modify the file.
 	FileWillBeUploaded(c *Context, info *model.FileInfo, file io.Reader, output io.Writer) (*model.FileInfo, string)
+
+	// OnPluginCall is invoked when another plugin calls one of the methods exported by this plugin
+	// via the CallPlugin API. Only the methods listed in the exports of the server section of the
+	// manifest are routed to the plugin. The meaning of the payload and of the returned data is up
+	// to the method, JSON being a good choice.
+	//
+	// Minimum server version: 5.12
+	OnPluginCall(c *Context, sourcePluginId, method string, payload []byte) ([]byte, *model.AppError)
+
+	// ReactionHasBeenAdded is invoked after a reaction has been committed to the database.
+	//
+	// Note that this method will be called for reactions added by plugins, including the plugin that
+	// added the reaction.
+	//
+	// Minimum server version: 5.12
+	ReactionHasBeenAdded(c *Context, reaction *model.Reaction)
+
+	// ReactionHasBeenRemoved is invoked after a reaction has been removed from the database.
+	//
+	// Note that this method will be called for reactions removed by plugins, including the plugin
+	// that removed the reaction.
+	//
+	// Minimum server version: 5.12
+	ReactionHasBeenRemoved(c *Context, reaction *model.Reaction)
+
+	// ChannelHasBeenUpdated is invoked after a channel has been updated in the database, such as
+	// when it's renamed, its header or purpose is changed or it's converted to a private channel.
+	// If you need to act on archived channels, see ChannelHasBeenArchived.
+	//
+	// Minimum server version: 5.12
+	ChannelHasBeenUpdated(c *Context, newChannel, oldChannel *model.Channel)
+
+	// ChannelHasBeenArchived is invoked after a channel has been archived in the database.
+	// If actor is not nil, the channel was archived by the actor.
+	//
+	// Minimum server version: 5.12
+	ChannelHasBeenArchived(c *Context, channel *model.Channel, actor *model.User)
+
+	// UserHasBeenUpdated is invoked after a user's profile or roles have been updated in the
+	// database. If you need to act on deactivated users, see UserHasBeenDeactivated.
+	//
+	// Minimum server version: 5.12
+	UserHasBeenUpdated(c *Context, newUser, oldUser *model.User)
+
+	// UserHasBeenDeactivated is invoked after a user has been deactivated in the database.
+	//
+	// Minimum server version: 5.12
+	UserHasBeenDeactivated(c *Context, user *model.User)
+
+	// CommandWillBeExecuted is invoked before any slash command is executed, including built-in
+	// commands, commands registered by plugins and custom commands. Return values should be the
+	// modified command arguments or nil if rejected and an explanation for the user. The
+	// arguments returned by one plugin are passed on to the next one. The user running the
+	// command can't be changed, and the user's permissions are checked again if the channel or
+	// team is.
+	//
+	// If you only need to handle your own commands, use RegisterCommand and ExecuteCommand instead.
+	//
+	// Note that this method will be called for commands executed by plugins, including the plugin
+	// that executed the command.
+	//
+	// Minimum server version: 5.12
+	CommandWillBeExecuted(c *Context, args *model.CommandArgs) (*model.CommandArgs, string)
+
+	// RunScheduledJob is invoked when a job registered through RegisterScheduledJob is due. Each
+	// run is delivered to a single server of the cluster.
+	//
+	// Minimum server version: 5.12
+	RunScheduledJob(c *Context, jobName string)
+
+	// PostActionTriggered is invoked when a user clicks a button or selects an option of an
+	// interactive message whose integration URL is model.PluginIntegrationURL of this plugin.
+	// Only messages posted by one of the bots of this plugin are routed to it. The user, post and
+	// channel of the request are checked by the server, but the context and selected option come
+	// from the client and must be treated as untrusted input.
+	// The returned response is applied as for integrations reached over HTTP, and may update the
+	// post, including ephemeral ones, or send an ephemeral message to the user. Return nil to do
+	// neither.
+	//
+	// Minimum server version: 5.12
+	PostActionTriggered(c *Context, request *model.PostActionIntegrationRequest) *model.PostActionIntegrationResponse
+
+	// DialogSubmitted is invoked when a user submits or cancels an interactive dialog whose URL is
+	// model.PluginIntegrationURL of this plugin. Only dialogs opened by this plugin for the user are
+	// routed to it, with the callback ID and state they were opened with. The submitted values come
+	// from the client and must be treated as untrusted input. Return errors keyed by element name to
+	// keep the dialog open and show them to the user, or nil to close it.
+	//
+	// Minimum server version: 5.12
+	DialogSubmitted(c *Context, request *model.SubmitDialogRequest) *model.SubmitDialogResponse
 }
@@ -4,13 +4,23 @@
 package plugin
 
 import (
+	"bufio"
+	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/rpc"
+
+	plugin "github.com/hashicorp/go-plugin"
 )
 
+// Writes to the response are batched up to this size before being sent to the server, so that
+// plugins writing in small chunks don't pay for a round trip each time.
+const httpResponseBufferSize = 64 * 1024
+
 type httpResponseWriterRPCServer struct {
-	w http.ResponseWriter
+	w         http.ResponseWriter
+	muxBroker *plugin.MuxBroker
 }
 
 func (w *httpResponseWriterRPCServer) Header(args struct{}, reply *http.Header) error {
@@ -41,12 +51,92 @@ func (w *httpResponseWriterRPCServer) SyncHeader(args http.Header, reply *struct
 	return nil
 }
 
-type httpResponseWriterRPCClient struct {
+func (w *httpResponseWriterRPCServer) Flush(args struct{}, reply *struct{}) error {
+	if flusher, ok := w.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+// Hijack takes over the connection of the client and replies with the id of a stream the plugin
+// dials to use it. Everything is then copied between the stream and the connection until either
+// side closes.
+func (w *httpResponseWriterRPCServer) Hijack(args struct{}, reply *uint32) error {
+	hijacker, ok := w.w.(http.Hijacker)
+	if !ok {
+		return errors.New("the response writer doesn't support hijacking")
+	}
+
+	conn, bufrw, err := hijacker.Hijack()
+	if err != nil {
+		return err
+	}
+
+	hijackedStreamId := w.muxBroker.NextId()
+	go func() {
+		defer conn.Close()
+
+		stream, err := w.muxBroker.Accept(hijackedStreamId)
+		if err != nil {
+			return
+		}
+		defer stream.Close()
+
+		if err := bufrw.Flush(); err != nil {
+			return
+		}
+
+		done := make(chan struct{}, 2)
+		go func() {
+			// Reading from bufrw first returns what the client sent after the request, if any.
+			io.Copy(stream, bufrw)
+			done <- struct{}{}
+		}()
+		go func() {
+			io.Copy(conn, stream)
+			done <- struct{}{}
+		}()
+		<-done
+	}()
+
+	*reply = hijackedStreamId
+	return nil
+}
+
+// httpResponseBodyWriter sends the response body to the server. Large writes are split, so that
+// the server starts sending them to the client early and neither side holds all of it in memory.
+type httpResponseBodyWriter struct {
 	client *rpc.Client
-	header http.Header
+}
+
+func (w *httpResponseBodyWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > httpResponseBufferSize {
+			chunk = chunk[:httpResponseBufferSize]
+		}
+		if err := w.client.Call("Plugin.Write", chunk, nil); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		b = b[len(chunk):]
+	}
+	return written, nil
+}
+
+type httpResponseWriterRPCClient struct {
+	client      *rpc.Client
+	muxBroker   *plugin.MuxBroker
+	header      http.Header
+	body        *bufio.Writer
+	wroteHeader bool
+	hijacked    bool
 }
 
 var _ http.ResponseWriter = (*httpResponseWriterRPCClient)(nil)
+var _ http.Flusher = (*httpResponseWriterRPCClient)(nil)
+var _ http.Hijacker = (*httpResponseWriterRPCClient)(nil)
 
 func (w *httpResponseWriterRPCClient) Header() http.Header {
 	if w.header == nil {
@@ -56,28 +146,81 @@ func (w *httpResponseWriterRPCClient) Header() http.Header {
 }
 
 func (w *httpResponseWriterRPCClient) Write(b []byte) (int, error) {
-	if err := w.client.Call("Plugin.SyncHeader", w.header, nil); err != nil {
-		return 0, err
+	if w.hijacked {
+		return 0, http.ErrHijacked
 	}
-	if err := w.client.Call("Plugin.Write", b, nil); err != nil {
-		return 0, err
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
 	}
-	return len(b), nil
+	return w.body.Write(b)
 }
 
 func (w *httpResponseWriterRPCClient) WriteHeader(statusCode int) {
-	if err := w.client.Call("Plugin.SyncHeader", w.header, nil); err != nil {
+	if w.hijacked || w.wroteHeader {
 		return
 	}
+	w.wroteHeader = true
+
+	// The headers can only have changed if the plugin asked for them.
+	if w.header != nil {
+		if err := w.client.Call("Plugin.SyncHeader", w.header, nil); err != nil {
+			return
+		}
+	}
 	w.client.Call("Plugin.WriteHeader", statusCode, nil)
 }
 
-func (h *httpResponseWriterRPCClient) Close() error {
-	return h.client.Close()
+func (w *httpResponseWriterRPCClient) Flush() {
+	if w.hijacked {
+		return
+	}
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if err := w.body.Flush(); err != nil {
+		return
+	}
+
+	// Servers older than this method simply don't flush until the response is complete.
+	w.client.Call("Plugin.Flush", struct{}{}, nil)
+}
+
+func (w *httpResponseWriterRPCClient) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.hijacked {
+		return nil, nil, http.ErrHijacked
+	}
+	if w.wroteHeader {
+		if err := w.body.Flush(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var hijackedStreamId uint32
+	if err := w.client.Call("Plugin.Hijack", struct{}{}, &hijackedStreamId); err != nil {
+		return nil, nil, err
+	}
+	w.hijacked = true
+
+	conn, err := w.muxBroker.Dial(hijackedStreamId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn)), nil
+}
+
+func (w *httpResponseWriterRPCClient) Close() error {
+	if w.wroteHeader && !w.hijacked {
+		w.body.Flush()
+	}
+	return w.client.Close()
 }
 
-func connectHTTPResponseWriter(conn io.ReadWriteCloser) *httpResponseWriterRPCClient {
+func connectHTTPResponseWriter(conn io.ReadWriteCloser, muxBroker *plugin.MuxBroker) *httpResponseWriterRPCClient {
+	client := rpc.NewClient(conn)
 	return &httpResponseWriterRPCClient{
-		client: rpc.NewClient(conn),
+		client:    client,
+		muxBroker: muxBroker,
+		body:      bufio.NewWriterSize(&httpResponseBodyWriter{client}, httpResponseBufferSize),
 	}
 }
@@ -0,0 +1,324 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	plugin "github.com/hashicorp/go-plugin"
+	testinginterface "github.com/mitchellh/go-testing-interface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/mlog"
+)
+
+type httpTestHooks struct {
+	handler http.HandlerFunc
+}
+
+func (h *httpTestHooks) ServeHTTP(c *Context, w http.ResponseWriter, r *http.Request) {
+	h.handler(w, r)
+}
+
+// newServeHTTPTestServer serves requests through the ServeHTTP hook of a plugin connected over
+// RPC, as in production, but within the test process.
+func newServeHTTPTestServer(t testinginterface.T, handler http.HandlerFunc) (*httptest.Server, func()) {
+	logger := mlog.NewLogger(&mlog.LoggerConfiguration{
+		EnableConsole: true,
+		ConsoleLevel:  "error",
+	})
+
+	client, _ := plugin.TestPluginRPCConn(t, map[string]plugin.Plugin{
+		"hooks": &hooksPlugin{hooks: &httpTestHooks{handler}, log: logger},
+	}, nil)
+
+	raw, err := client.Dispense("hooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks := raw.(*hooksRPCClient)
+	if _, err = hooks.Implemented(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hooks.ServeHTTP(&Context{}, w, r)
+	}))
+
+	return server, func() {
+		server.Close()
+		client.Close()
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	t.Run("response", func(t *testing.T) {
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Path", r.URL.Path)
+			w.WriteHeader(http.StatusTeapot)
+			fmt.Fprint(w, "hello, ")
+			fmt.Fprint(w, "world")
+		})
+		defer tearDown()
+
+		resp, err := http.Get(server.URL + "/path")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+		assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "/path", resp.Header.Get("X-Path"))
+		assert.Equal(t, "hello, world", string(body))
+	})
+
+	t.Run("headers after write are ignored", func(t *testing.T) {
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("body"))
+			w.Header().Set("X-Late", "true")
+			w.WriteHeader(http.StatusNotFound)
+		})
+		defer tearDown()
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "", resp.Header.Get("X-Late"))
+	})
+
+	t.Run("streamed request body", func(t *testing.T) {
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			hash := sha256.New()
+			n, err := io.Copy(hash, r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintf(w, "%d %x", n, hash.Sum(nil))
+		})
+		defer tearDown()
+
+		data := bytes.Repeat([]byte("0123456789abcdef"), 1024*1024)
+		resp, err := http.Post(server.URL, "application/octet-stream", bytes.NewReader(data))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%d %x", len(data), sha256.Sum256(data)), string(body))
+	})
+
+	t.Run("unread request body", func(t *testing.T) {
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("ignored"))
+		})
+		defer tearDown()
+
+		resp, err := http.Post(server.URL, "application/octet-stream", bytes.NewReader(make([]byte, 8*1024*1024)))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ignored", string(body))
+	})
+
+	t.Run("range", func(t *testing.T) {
+		content := bytes.Repeat([]byte("0123456789"), 1000)
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "file.txt", time.Time{}, bytes.NewReader(content))
+		})
+		defer tearDown()
+
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Range", "bytes=5-14")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+		assert.Equal(t, "bytes 5-14/10000", resp.Header.Get("Content-Range"))
+		assert.Equal(t, "5678901234", string(body))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		next := make(chan struct{})
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; i < 3; i++ {
+				fmt.Fprintf(w, "data: %d\n\n", i)
+				w.(http.Flusher).Flush()
+
+				// Only send the next event once the client received this one.
+				select {
+				case <-next:
+				case <-time.After(5 * time.Second):
+					return
+				}
+			}
+		})
+		defer tearDown()
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		reader := bufio.NewReader(resp.Body)
+		for i := 0; i < 3; i++ {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, fmt.Sprintf("data: %d\n", i), line)
+			_, err = reader.ReadString('\n')
+			require.NoError(t, err)
+			next <- struct{}{}
+		}
+	})
+
+	t.Run("hijack", func(t *testing.T) {
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer conn.Close()
+
+			rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+			rw.Flush()
+
+			for {
+				line, err := rw.ReadString('\n')
+				if err != nil {
+					return
+				}
+				rw.WriteString(strings.ToUpper(line))
+				rw.Flush()
+			}
+		})
+		defer tearDown()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n")
+		reader := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(reader, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+		for _, message := range []string{"hello\n", "world\n"} {
+			fmt.Fprint(conn, message)
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			assert.Equal(t, strings.ToUpper(message), line)
+		}
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		server, tearDown := newServeHTTPTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+
+			for {
+				messageType, message, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if err := conn.WriteMessage(messageType, bytes.ToUpper(message)); err != nil {
+					return
+				}
+			}
+		})
+		defer tearDown()
+
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+		_, message, err := conn.ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO", string(message))
+	})
+}
+
+func BenchmarkServeHTTP(b *testing.B) {
+	const size = 16 * 1024 * 1024
+	data := bytes.Repeat([]byte("0123456789abcdef"), size/16)
+
+	b.Run("download", func(b *testing.B) {
+		server, tearDown := newServeHTTPTestServer(b, func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(w, bytes.NewReader(data))
+		})
+		defer tearDown()
+
+		b.SetBytes(size)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			resp, err := http.Get(server.URL)
+			require.NoError(b, err)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	})
+
+	b.Run("small writes", func(b *testing.B) {
+		server, tearDown := newServeHTTPTestServer(b, func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < 1000; i++ {
+				fmt.Fprintf(w, "line %d\n", i)
+			}
+		})
+		defer tearDown()
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			resp, err := http.Get(server.URL)
+			require.NoError(b, err)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	})
+
+	b.Run("upload", func(b *testing.B) {
+		server, tearDown := newServeHTTPTestServer(b, func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(ioutil.Discard, r.Body)
+		})
+		defer tearDown()
+
+		b.SetBytes(size)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			resp, err := http.Post(server.URL, "application/octet-stream", bytes.NewReader(data))
+			require.NoError(b, err)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"bufio"
 	"encoding/binary"
 	"io"
+	"io/ioutil"
 )
 
 type remoteIOReader struct {
@@ -44,3 +45,12 @@ func serveIOReader(r io.Reader, conn io.ReadWriteCloser) {
 		}
 	}
 }
+
+// streamIOReader sends everything read from r over the connection, without waiting for the
+// reader on the other end to ask for it. What the other end writes is discarded, so that readers
+// connected with connectIOReader work as well.
+func streamIOReader(r io.Reader, conn io.ReadWriteCloser) {
+	defer conn.Close()
+	go io.Copy(ioutil.Discard, conn)
+	io.Copy(conn, r)
+}
@@ -62,6 +62,31 @@ func (_m *API) AddReaction(reaction *model.Reaction) (*model.Reaction, *model.Ap
 	return r0, r1
 }
 
+// CallPlugin provides a mock function with given fields: pluginId, method, payload
+func (_m *API) CallPlugin(pluginId string, method string, payload []byte) ([]byte, *model.AppError) {
+	ret := _m.Called(pluginId, method, payload)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string, string, []byte) []byte); ok {
+		r0 = rf(pluginId, method, payload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, []byte) *model.AppError); ok {
+		r1 = rf(pluginId, method, payload)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // CopyFileInfos provides a mock function with given fields: userId, fileIds
 func (_m *API) CopyFileInfos(userId string, fileIds []string) ([]string, *model.AppError) {
 	ret := _m.Called(userId, fileIds)
@@ -1283,6 +1308,31 @@ func (_m *API) GetReactions(postId string) ([]*model.Reaction, *model.AppError)
 	return r0, r1
 }
 
+// GetScheduledJobs provides a mock function with given fields:
+func (_m *API) GetScheduledJobs() ([]*model.PluginScheduledJob, *model.AppError) {
+	ret := _m.Called()
+
+	var r0 []*model.PluginScheduledJob
+	if rf, ok := ret.Get(0).(func() []*model.PluginScheduledJob); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.PluginScheduledJob)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func() *model.AppError); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // GetServerVersion provides a mock function with given fields:
 func (_m *API) GetServerVersion() string {
 	ret := _m.Called()
@@ -1862,6 +1912,29 @@ func (_m *API) HasPermissionToTeam(userId string, teamId string, permission *mod
 	return r0
 }
 
+// KVCompareAndDelete provides a mock function with given fields: key, oldValue
+func (_m *API) KVCompareAndDelete(key string, oldValue []byte) (bool, *model.AppError) {
+	ret := _m.Called(key, oldValue)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string, []byte) bool); ok {
+		r0 = rf(key, oldValue)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, []byte) *model.AppError); ok {
+		r1 = rf(key, oldValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // KVCompareAndSet provides a mock function with given fields: key, oldValue, newValue
 func (_m *API) KVCompareAndSet(key string, oldValue []byte, newValue []byte) (bool, *model.AppError) {
 	ret := _m.Called(key, oldValue, newValue)
@@ -1917,6 +1990,22 @@ func (_m *API) KVDeleteAll() *model.AppError {
 	return r0
 }
 
+// KVDeleteMany provides a mock function with given fields: keys
+func (_m *API) KVDeleteMany(keys []string) *model.AppError {
+	ret := _m.Called(keys)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func([]string) *model.AppError); ok {
+		r0 = rf(keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // KVGet provides a mock function with given fields: key
 func (_m *API) KVGet(key string) ([]byte, *model.AppError) {
 	ret := _m.Called(key)
@@ -1967,6 +2056,31 @@ func (_m *API) KVList(page int, perPage int) ([]string, *model.AppError) {
 	return r0, r1
 }
 
+// KVListWithPrefix provides a mock function with given fields: prefix, page, perPage
+func (_m *API) KVListWithPrefix(prefix string, page int, perPage int) ([]string, *model.AppError) {
+	ret := _m.Called(prefix, page, perPage)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, int, int) []string); ok {
+		r0 = rf(prefix, page, perPage)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, int, int) *model.AppError); ok {
+		r1 = rf(prefix, page, perPage)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // KVSet provides a mock function with given fields: key, value
 func (_m *API) KVSet(key string, value []byte) *model.AppError {
 	ret := _m.Called(key, value)
@@ -1983,6 +2097,22 @@ func (_m *API) KVSet(key string, value []byte) *model.AppError {
 	return r0
 }
 
+// KVSetMany provides a mock function with given fields: values
+func (_m *API) KVSetMany(values map[string][]byte) *model.AppError {
+	ret := _m.Called(values)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(map[string][]byte) *model.AppError); ok {
+		r0 = rf(values)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // KVSetWithExpiry provides a mock function with given fields: key, value, expireInSeconds
 func (_m *API) KVSetWithExpiry(key string, value []byte, expireInSeconds int64) *model.AppError {
 	ret := _m.Called(key, value, expireInSeconds)
@@ -2061,6 +2191,22 @@ func (_m *API) OpenInteractiveDialog(dialog model.OpenDialogRequest) *model.AppE
 	return r0
 }
 
+// OpenInteractiveDialogForUser provides a mock function with given fields: userId, dialog
+func (_m *API) OpenInteractiveDialogForUser(userId string, dialog model.OpenDialogRequest) *model.AppError {
+	ret := _m.Called(userId, dialog)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, model.OpenDialogRequest) *model.AppError); ok {
+		r0 = rf(userId, dialog)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // PatchBot provides a mock function with given fields: botUserId, botPatch
 func (_m *API) PatchBot(botUserId string, botPatch *model.BotPatch) (*model.Bot, *model.AppError) {
 	ret := _m.Called(botUserId, botPatch)
@@ -2146,6 +2292,22 @@ func (_m *API) RegisterCommand(command *model.Command) error {
 	return r0
 }
 
+// RegisterScheduledJob provides a mock function with given fields: job
+func (_m *API) RegisterScheduledJob(job *model.PluginScheduledJob) *model.AppError {
+	ret := _m.Called(job)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(*model.PluginScheduledJob) *model.AppError); ok {
+		r0 = rf(job)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // RemovePlugin provides a mock function with given fields: id
 func (_m *API) RemovePlugin(id string) *model.AppError {
 	ret := _m.Called(id)
@@ -2404,6 +2566,22 @@ func (_m *API) UnregisterCommand(teamId string, trigger string) error {
 	return r0
 }
 
+// UnregisterScheduledJob provides a mock function with given fields: name
+func (_m *API) UnregisterScheduledJob(name string) *model.AppError {
+	ret := _m.Called(name)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string) *model.AppError); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // UpdateBotActive provides a mock function with given fields: botUserId, active
 func (_m *API) UpdateBotActive(botUserId string, active bool) (*model.Bot, *model.AppError) {
 	ret := _m.Called(botUserId, active)
@@ -15,11 +15,60 @@ type Hooks struct {
 	mock.Mock
 }
 
+// ChannelHasBeenArchived provides a mock function with given fields: c, channel, actor
+func (_m *Hooks) ChannelHasBeenArchived(c *plugin.Context, channel *model.Channel, actor *model.User) {
+	_m.Called(c, channel, actor)
+}
+
 // ChannelHasBeenCreated provides a mock function with given fields: c, channel
 func (_m *Hooks) ChannelHasBeenCreated(c *plugin.Context, channel *model.Channel) {
 	_m.Called(c, channel)
 }
 
+// ChannelHasBeenUpdated provides a mock function with given fields: c, newChannel, oldChannel
+func (_m *Hooks) ChannelHasBeenUpdated(c *plugin.Context, newChannel *model.Channel, oldChannel *model.Channel) {
+	_m.Called(c, newChannel, oldChannel)
+}
+
+// CommandWillBeExecuted provides a mock function with given fields: c, args
+func (_m *Hooks) CommandWillBeExecuted(c *plugin.Context, args *model.CommandArgs) (*model.CommandArgs, string) {
+	ret := _m.Called(c, args)
+
+	var r0 *model.CommandArgs
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.CommandArgs) *model.CommandArgs); ok {
+		r0 = rf(c, args)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CommandArgs)
+		}
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(*plugin.Context, *model.CommandArgs) string); ok {
+		r1 = rf(c, args)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	return r0, r1
+}
+
+// DialogSubmitted provides a mock function with given fields: c, request
+func (_m *Hooks) DialogSubmitted(c *plugin.Context, request *model.SubmitDialogRequest) *model.SubmitDialogResponse {
+	ret := _m.Called(c, request)
+
+	var r0 *model.SubmitDialogResponse
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.SubmitDialogRequest) *model.SubmitDialogResponse); ok {
+		r0 = rf(c, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.SubmitDialogResponse)
+		}
+	}
+
+	return r0
+}
+
 // ExecuteCommand provides a mock function with given fields: c, args
 func (_m *Hooks) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
 	ret := _m.Called(c, args)
@@ -189,6 +238,62 @@ func (_m *Hooks) OnDeactivate() error {
 	return r0
 }
 
+// OnPluginCall provides a mock function with given fields: c, sourcePluginId, method, payload
+func (_m *Hooks) OnPluginCall(c *plugin.Context, sourcePluginId string, method string, payload []byte) ([]byte, *model.AppError) {
+	ret := _m.Called(c, sourcePluginId, method, payload)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(*plugin.Context, string, string, []byte) []byte); ok {
+		r0 = rf(c, sourcePluginId, method, payload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(*plugin.Context, string, string, []byte) *model.AppError); ok {
+		r1 = rf(c, sourcePluginId, method, payload)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
+// PostActionTriggered provides a mock function with given fields: c, request
+func (_m *Hooks) PostActionTriggered(c *plugin.Context, request *model.PostActionIntegrationRequest) *model.PostActionIntegrationResponse {
+	ret := _m.Called(c, request)
+
+	var r0 *model.PostActionIntegrationResponse
+	if rf, ok := ret.Get(0).(func(*plugin.Context, *model.PostActionIntegrationRequest) *model.PostActionIntegrationResponse); ok {
+		r0 = rf(c, request)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PostActionIntegrationResponse)
+		}
+	}
+
+	return r0
+}
+
+// ReactionHasBeenAdded provides a mock function with given fields: c, reaction
+func (_m *Hooks) ReactionHasBeenAdded(c *plugin.Context, reaction *model.Reaction) {
+	_m.Called(c, reaction)
+}
+
+// ReactionHasBeenRemoved provides a mock function with given fields: c, reaction
+func (_m *Hooks) ReactionHasBeenRemoved(c *plugin.Context, reaction *model.Reaction) {
+	_m.Called(c, reaction)
+}
+
+// RunScheduledJob provides a mock function with given fields: c, jobName
+func (_m *Hooks) RunScheduledJob(c *plugin.Context, jobName string) {
+	_m.Called(c, jobName)
+}
+
 // ServeHTTP provides a mock function with given fields: c, w, r
 func (_m *Hooks) ServeHTTP(c *plugin.Context, w http.ResponseWriter, r *http.Request) {
 	_m.Called(c, w, r)
@@ -199,6 +304,16 @@ func (_m *Hooks) UserHasBeenCreated(c *plugin.Context, user *model.User) {
 	_m.Called(c, user)
 }
 
+// UserHasBeenDeactivated provides a mock function with given fields: c, user
+func (_m *Hooks) UserHasBeenDeactivated(c *plugin.Context, user *model.User) {
+	_m.Called(c, user)
+}
+
+// UserHasBeenUpdated provides a mock function with given fields: c, newUser, oldUser
+func (_m *Hooks) UserHasBeenUpdated(c *plugin.Context, newUser *model.User, oldUser *model.User) {
+	_m.Called(c, newUser, oldUser)
+}
+
 // UserHasJoinedChannel provides a mock function with given fields: c, channelMember, actor
 func (_m *Hooks) UserHasJoinedChannel(c *plugin.Context, channelMember *model.ChannelMember, actor *model.User) {
 	_m.Called(c, channelMember, actor)
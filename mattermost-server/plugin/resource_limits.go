@@ -0,0 +1,29 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+// ResourceLimits caps the resources available to the server component of a plugin. Zero means no
+// limit.
+type ResourceLimits struct {
+	// MemoryBytes caps the memory used by the plugin process.
+	MemoryBytes int64
+
+	// CPUPercent caps the CPU time used by the plugin process, as a percentage of a single CPU.
+	CPUPercent int
+
+	// CgroupPath is a cgroup v2 directory, delegated to the server, in which a cgroup is created
+	// for each plugin. Without it, only memory can be limited, through the data segment rlimit.
+	CgroupPath string
+}
+
+// IsZero returns true if no limit applies.
+func (limits ResourceLimits) IsZero() bool {
+	return limits.MemoryBytes <= 0 && limits.CPUPercent <= 0
+}
+
+// SetResourceLimits sets the function returning the resource limits of each plugin, applied when
+// the plugin is activated.
+func (env *Environment) SetResourceLimits(resourceLimits func(pluginId string) ResourceLimits) {
+	env.resourceLimits = resourceLimits
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// cpuPeriod is the cgroup CPU accounting period, in microseconds.
+const cpuPeriod = 100000
+
+// applyResourceLimits limits the resources available to the plugin process with the given pid. The
+// returned function, if any, releases what was set up once the process exited.
+func applyResourceLimits(id string, pid int, limits ResourceLimits) (func(), error) {
+	if limits.IsZero() {
+		return nil, nil
+	}
+
+	if limits.CgroupPath != "" {
+		return applyCgroupLimits(id, pid, limits)
+	}
+
+	if limits.CPUPercent > 0 {
+		return nil, errors.New("limiting the CPU usage of plugins requires a cgroup")
+	}
+
+	rlimit := &syscall.Rlimit{Cur: uint64(limits.MemoryBytes), Max: uint64(limits.MemoryBytes)}
+	if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRLIMIT64, uintptr(pid), uintptr(syscall.RLIMIT_DATA), uintptr(unsafe.Pointer(rlimit)), 0, 0, 0); errno != 0 {
+		return nil, errors.Wrap(errno, "unable to set the memory limit")
+	}
+
+	return nil, nil
+}
+
+// applyCgroupLimits moves the plugin process to a cgroup of its own, created within the cgroup
+// delegated to the server.
+func applyCgroupLimits(id string, pid int, limits ResourceLimits) (func(), error) {
+	if err := ioutil.WriteFile(filepath.Join(limits.CgroupPath, "cgroup.subtree_control"), []byte("+memory +cpu"), 0644); err != nil {
+		return nil, errors.Wrap(err, "unable to enable the memory and cpu controllers")
+	}
+
+	dir := filepath.Join(limits.CgroupPath, "plugin-"+id)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return nil, errors.Wrap(err, "unable to create the plugin cgroup")
+	}
+
+	memoryMax := "max"
+	if limits.MemoryBytes > 0 {
+		memoryMax = strconv.FormatInt(limits.MemoryBytes, 10)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte(memoryMax), 0644); err != nil {
+		return nil, errors.Wrap(err, "unable to set the memory limit")
+	}
+
+	cpuMax := "max"
+	if limits.CPUPercent > 0 {
+		cpuMax = strconv.Itoa(limits.CPUPercent * cpuPeriod / 100)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cpu.max"), []byte(fmt.Sprintf("%s %d", cpuMax, cpuPeriod)), 0644); err != nil {
+		return nil, errors.Wrap(err, "unable to set the CPU limit")
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644); err != nil {
+		return nil, errors.Wrap(err, "unable to move the plugin to its cgroup")
+	}
+
+	return func() {
+		os.Remove(dir)
+	}, nil
+}
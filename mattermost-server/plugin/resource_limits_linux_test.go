@@ -0,0 +1,68 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package plugin
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyResourceLimits(t *testing.T) {
+	cmd := exec.Command("sleep", "10")
+	require.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	t.Run("no limits", func(t *testing.T) {
+		release, err := applyResourceLimits("foo", cmd.Process.Pid, ResourceLimits{})
+		require.NoError(t, err)
+		assert.Nil(t, release)
+	})
+
+	t.Run("memory rlimit", func(t *testing.T) {
+		_, err := applyResourceLimits("foo", cmd.Process.Pid, ResourceLimits{MemoryBytes: 512 * 1024 * 1024})
+		require.NoError(t, err)
+
+		limits, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(cmd.Process.Pid), "limits"))
+		require.NoError(t, err)
+		for _, line := range strings.Split(string(limits), "\n") {
+			if strings.HasPrefix(line, "Max data size") {
+				assert.Contains(t, line, "536870912")
+			}
+		}
+	})
+
+	t.Run("cpu without cgroup", func(t *testing.T) {
+		_, err := applyResourceLimits("foo", cmd.Process.Pid, ResourceLimits{CPUPercent: 50})
+		assert.Error(t, err)
+	})
+
+	t.Run("cgroup", func(t *testing.T) {
+		// A plain directory stands in for the delegated cgroup.
+		cgroupPath, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		defer os.RemoveAll(cgroupPath)
+
+		release, err := applyResourceLimits("foo", cmd.Process.Pid, ResourceLimits{CPUPercent: 50, CgroupPath: cgroupPath})
+		require.NoError(t, err)
+		require.NotNil(t, release)
+
+		read := func(name string) string {
+			data, err := ioutil.ReadFile(filepath.Join(cgroupPath, "plugin-foo", name))
+			require.NoError(t, err)
+			return string(data)
+		}
+
+		assert.Equal(t, "max", read("memory.max"))
+		assert.Equal(t, "50000 100000", read("cpu.max"))
+		assert.Equal(t, strconv.Itoa(cmd.Process.Pid), read("cgroup.procs"))
+	})
+}
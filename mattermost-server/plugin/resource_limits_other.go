@@ -0,0 +1,21 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+//go:build !linux
+// +build !linux
+
+package plugin
+
+import (
+	"github.com/pkg/errors"
+)
+
+// applyResourceLimits limits the resources available to the plugin process with the given pid,
+// which is only supported on Linux.
+func applyResourceLimits(id string, pid int, limits ResourceLimits) (func(), error) {
+	if limits.IsZero() {
+		return nil, nil
+	}
+
+	return nil, errors.New("plugin resource limits are only supported on Linux")
+}
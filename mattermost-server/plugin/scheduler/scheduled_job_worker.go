@@ -0,0 +1,113 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// Several jobs registered by plugins may be due at once, so the watcher can hand over that many
+// jobs in a single poll.
+const SCHEDULED_JOB_WORKER_QUEUE_SIZE = 100
+
+// ScheduledJobWorker runs the jobs registered by plugins. Each claimed job runs concurrently, so
+// that a slow plugin doesn't hold up the jobs of other plugins.
+type ScheduledJobWorker struct {
+	name      string
+	stop      chan bool
+	stopped   chan bool
+	jobs      chan model.Job
+	jobServer *jobs.JobServer
+	app       *app.App
+	running   sync.WaitGroup
+}
+
+func (m *PluginsJobInterfaceImpl) MakeScheduledJobWorker() model.Worker {
+	worker := ScheduledJobWorker{
+		name:      "PluginScheduledJobs",
+		stop:      make(chan bool, 1),
+		stopped:   make(chan bool, 1),
+		jobs:      make(chan model.Job, SCHEDULED_JOB_WORKER_QUEUE_SIZE),
+		jobServer: m.App.Srv.Jobs,
+		app:       m.App,
+	}
+
+	return &worker
+}
+
+func (worker *ScheduledJobWorker) Run() {
+	mlog.Debug("Worker started", mlog.String("worker", worker.name))
+
+	defer func() {
+		worker.running.Wait()
+		mlog.Debug("Worker finished", mlog.String("worker", worker.name))
+		worker.stopped <- true
+	}()
+
+	for {
+		select {
+		case <-worker.stop:
+			mlog.Debug("Worker received stop signal", mlog.String("worker", worker.name))
+			return
+		case job := <-worker.jobs:
+			mlog.Debug("Worker received a new candidate job.", mlog.String("worker", worker.name))
+			worker.DoJob(&job)
+		}
+	}
+}
+
+func (worker *ScheduledJobWorker) Stop() {
+	mlog.Debug("Worker stopping", mlog.String("worker", worker.name))
+	worker.stop <- true
+	<-worker.stopped
+}
+
+func (worker *ScheduledJobWorker) JobChannel() chan<- model.Job {
+	return worker.jobs
+}
+
+func (worker *ScheduledJobWorker) DoJob(job *model.Job) {
+	// Every server of the cluster is handed the job, but only one of them claims it.
+	if claimed, err := worker.jobServer.ClaimJob(job); err != nil {
+		mlog.Info("Worker experienced an error while trying to claim job",
+			mlog.String("worker", worker.name),
+			mlog.String("job_id", job.Id),
+			mlog.String("error", err.Error()))
+		return
+	} else if !claimed {
+		return
+	}
+
+	worker.running.Add(1)
+	go func() {
+		defer worker.running.Done()
+
+		if err := worker.app.RunPluginScheduledJob(job); err != nil {
+			mlog.Error("Worker: Failed to run scheduled job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+			worker.setJobError(job, err)
+			return
+		}
+
+		mlog.Debug("Worker: Job is complete", mlog.String("worker", worker.name), mlog.String("job_id", job.Id))
+		worker.setJobSuccess(job)
+	}()
+}
+
+func (worker *ScheduledJobWorker) setJobSuccess(job *model.Job) {
+	if err := worker.app.Srv.Jobs.SetJobSuccess(job); err != nil {
+		mlog.Error("Worker: Failed to set success for job", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+		worker.setJobError(job, err)
+	}
+}
+
+func (worker *ScheduledJobWorker) setJobError(job *model.Job, appError *model.AppError) {
+	if err := worker.app.Srv.Jobs.SetJobError(job, appError); err != nil {
+		mlog.Error("Worker: Failed to set job error", mlog.String("worker", worker.name), mlog.String("job_id", job.Id), mlog.String("error", err.Error()))
+	}
+}
@@ -4,7 +4,6 @@
 package plugin
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -17,6 +16,7 @@ import (
 	plugin "github.com/hashicorp/go-plugin"
 	"github.com/mattermost/mattermost-server/mlog"
 	"github.com/mattermost/mattermost-server/model"
+	"github.com/pkg/errors"
 )
 
 type supervisor struct {
@@ -24,9 +24,12 @@ type supervisor struct {
 	hooks       Hooks
 	implemented [TotalHooksId]bool
 	pid         int
+
+	// releaseResourceLimits, if set, releases what was set up to limit the plugin resources.
+	releaseResourceLimits func()
 }
 
-func newSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiImpl API) (retSupervisor *supervisor, retErr error) {
+func newSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiImpl API, limits ResourceLimits) (retSupervisor *supervisor, retErr error) {
 	sup := supervisor{}
 	defer func() {
 		if retErr != nil {
@@ -76,6 +79,11 @@ func newSupervisor(pluginInfo *model.BundleInfo, parentLogger *mlog.Logger, apiI
 
 	sup.pid = cmd.Process.Pid
 
+	sup.releaseResourceLimits, err = applyResourceLimits(pluginInfo.Manifest.Id, sup.pid, limits)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to limit plugin resources")
+	}
+
 	raw, err := rpcClient.Dispense("hooks")
 	if err != nil {
 		return nil, err
@@ -105,6 +113,10 @@ func (sup *supervisor) Shutdown() {
 	if sup.client != nil {
 		sup.client.Kill()
 	}
+
+	if sup.releaseResourceLimits != nil {
+		sup.releaseResourceLimits()
+	}
 }
 
 func (sup *supervisor) Hooks() Hooks {
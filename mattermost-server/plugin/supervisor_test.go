@@ -40,7 +40,7 @@ func testSupervisor_InvalidExecutablePath(t *testing.T) {
 		ConsoleLevel:  "error",
 		EnableFile:    false,
 	})
-	supervisor, err := newSupervisor(bundle, log, nil)
+	supervisor, err := newSupervisor(bundle, log, nil, ResourceLimits{})
 	assert.Nil(t, supervisor)
 	assert.Error(t, err)
 }
@@ -59,7 +59,7 @@ func testSupervisor_NonExistentExecutablePath(t *testing.T) {
 		ConsoleLevel:  "error",
 		EnableFile:    false,
 	})
-	supervisor, err := newSupervisor(bundle, log, nil)
+	supervisor, err := newSupervisor(bundle, log, nil, ResourceLimits{})
 	require.Error(t, err)
 	require.Nil(t, supervisor)
 }
@@ -89,7 +89,7 @@ func testSupervisor_StartTimeout(t *testing.T) {
 		ConsoleLevel:  "error",
 		EnableFile:    false,
 	})
-	supervisor, err := newSupervisor(bundle, log, nil)
+	supervisor, err := newSupervisor(bundle, log, nil, ResourceLimits{})
 	require.Error(t, err)
 	require.Nil(t, supervisor)
 }
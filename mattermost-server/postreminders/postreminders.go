@@ -0,0 +1,43 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package postreminders
+
+import (
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	tjobs "github.com/mattermost/mattermost-server/jobs/interfaces"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	JOB_DATA_KEY_REMINDERS_SENT = "reminders_sent"
+)
+
+type PostRemindersJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsPostRemindersJobInterface(func(a *app.App) tjobs.PostRemindersJobInterface {
+		return &PostRemindersJobInterfaceImpl{a}
+	})
+}
+
+func (s *PostRemindersJobInterfaceImpl) dueItems() jobs.DueItems {
+	return jobs.DueItems{
+		Name:      "PostReminders",
+		JobType:   model.JOB_TYPE_POST_REMINDERS,
+		DataKey:   JOB_DATA_KEY_REMINDERS_SENT,
+		HasDue:    s.App.HasDuePostReminders,
+		HandleDue: s.App.SendDuePostReminders,
+	}
+}
+
+func (s *PostRemindersJobInterfaceImpl) MakeScheduler() model.Scheduler {
+	return s.App.Srv.Jobs.MakeDueItemsScheduler(s.dueItems())
+}
+
+func (s *PostRemindersJobInterfaceImpl) MakeWorker() model.Worker {
+	return s.App.Srv.Jobs.MakeDueItemsWorker(s.dueItems())
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// The XML Encryption algorithms that assertions may be encrypted with.
+const (
+	AES128_CBC     = "http://www.w3.org/2001/04/xmlenc#aes128-cbc"
+	AES192_CBC     = "http://www.w3.org/2001/04/xmlenc#aes192-cbc"
+	AES256_CBC     = "http://www.w3.org/2001/04/xmlenc#aes256-cbc"
+	TRIPLEDES_CBC  = "http://www.w3.org/2001/04/xmlenc#tripledes-cbc"
+	AES128_GCM     = "http://www.w3.org/2009/xmlenc11#aes128-gcm"
+	AES192_GCM     = "http://www.w3.org/2009/xmlenc11#aes192-gcm"
+	AES256_GCM     = "http://www.w3.org/2009/xmlenc11#aes256-gcm"
+	RSA_1_5        = "http://www.w3.org/2001/04/xmlenc#rsa-1_5"
+	RSA_OAEP_MGF1P = "http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p"
+	RSA_OAEP       = "http://www.w3.org/2009/xmlenc11#rsa-oaep"
+)
+
+var digestMethods = map[string]crypto.Hash{
+	"http://www.w3.org/2000/09/xmldsig#sha1":        crypto.SHA1,
+	"http://www.w3.org/2001/04/xmlenc#sha256":       crypto.SHA256,
+	"http://www.w3.org/2001/04/xmlenc#sha512":       crypto.SHA512,
+	"http://www.w3.org/2001/04/xmldsig-more#sha384": crypto.SHA384,
+}
+
+// decryptAssertion decrypts an EncryptedAssertion with the service provider's private key and
+// returns the Assertion it holds, parsed but detached from any document.
+func (sp *serviceProvider) decryptAssertion(encryptedAssertion *etree.Element) (*etree.Element, error) {
+	if sp.privateKey == nil {
+		return nil, fmt.Errorf("no private key to decrypt the assertion with")
+	}
+
+	encryptedData := childElement(encryptedAssertion, XMLENC_NAMESPACE, "EncryptedData")
+	if encryptedData == nil {
+		return nil, fmt.Errorf("missing EncryptedData")
+	}
+
+	// The key is usually found in the KeyInfo of the data, but may also be a sibling of it.
+	var encryptedKey *etree.Element
+	if keyInfo := childElement(encryptedData, dsig.Namespace, "KeyInfo"); keyInfo != nil {
+		encryptedKey = childElement(keyInfo, XMLENC_NAMESPACE, "EncryptedKey")
+	}
+	if encryptedKey == nil {
+		encryptedKey = childElement(encryptedAssertion, XMLENC_NAMESPACE, "EncryptedKey")
+	}
+	if encryptedKey == nil {
+		return nil, fmt.Errorf("missing EncryptedKey")
+	}
+
+	key, err := sp.decryptKey(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decryptData(encryptedData, key)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := etree.NewDocument()
+	if err := doc.ReadFromBytes(plaintext); err != nil {
+		return nil, err
+	}
+	if doc.Root() == nil {
+		return nil, fmt.Errorf("the decrypted assertion is empty")
+	}
+
+	return doc.Root(), nil
+}
+
+func (sp *serviceProvider) decryptKey(encryptedKey *etree.Element) ([]byte, error) {
+	method := childElement(encryptedKey, XMLENC_NAMESPACE, "EncryptionMethod")
+	if method == nil {
+		return nil, fmt.Errorf("missing EncryptionMethod on EncryptedKey")
+	}
+
+	cipherValue, err := cipherValue(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	switch algorithm := method.SelectAttrValue("Algorithm", ""); algorithm {
+	case RSA_1_5:
+		return rsa.DecryptPKCS1v15(rand.Reader, sp.privateKey, cipherValue)
+	case RSA_OAEP_MGF1P, RSA_OAEP:
+		hash := crypto.SHA1
+		if digestMethod := childElement(method, dsig.Namespace, "DigestMethod"); digestMethod != nil {
+			var ok bool
+			if hash, ok = digestMethods[digestMethod.SelectAttrValue("Algorithm", "")]; !ok {
+				return nil, fmt.Errorf("unsupported key digest method %s", digestMethod.SelectAttrValue("Algorithm", ""))
+			}
+		}
+		return rsa.DecryptOAEP(hash.New(), rand.Reader, sp.privateKey, cipherValue, nil)
+	default:
+		return nil, fmt.Errorf("unsupported key encryption method %s", algorithm)
+	}
+}
+
+func decryptData(encryptedData *etree.Element, key []byte) ([]byte, error) {
+	method := childElement(encryptedData, XMLENC_NAMESPACE, "EncryptionMethod")
+	if method == nil {
+		return nil, fmt.Errorf("missing EncryptionMethod on EncryptedData")
+	}
+
+	ciphertext, err := cipherValue(encryptedData)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm := method.SelectAttrValue("Algorithm", "")
+
+	keySizes := map[string]int{
+		AES128_CBC:    16,
+		AES192_CBC:    24,
+		AES256_CBC:    32,
+		TRIPLEDES_CBC: 24,
+		AES128_GCM:    16,
+		AES192_GCM:    24,
+		AES256_GCM:    32,
+	}
+	if keySize, ok := keySizes[algorithm]; !ok {
+		return nil, fmt.Errorf("unsupported data encryption method %s", algorithm)
+	} else if len(key) != keySize {
+		return nil, fmt.Errorf("the key doesn't fit %s", algorithm)
+	}
+
+	var block cipher.Block
+	if algorithm == TRIPLEDES_CBC {
+		block, err = des.NewTripleDESCipher(key)
+	} else {
+		block, err = aes.NewCipher(key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch algorithm {
+	case AES128_GCM, AES192_GCM, AES256_GCM:
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ciphertext) < gcm.NonceSize() {
+			return nil, fmt.Errorf("the ciphertext is too short")
+		}
+		return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+	default:
+		// The initialization vector is the first block of the ciphertext.
+		blockSize := block.BlockSize()
+		if len(ciphertext) < 2*blockSize || len(ciphertext)%blockSize != 0 {
+			return nil, fmt.Errorf("the ciphertext isn't a whole number of blocks")
+		}
+
+		plaintext := make([]byte, len(ciphertext)-blockSize)
+		cipher.NewCBCDecrypter(block, ciphertext[:blockSize]).CryptBlocks(plaintext, ciphertext[blockSize:])
+
+		// The last byte gives the length of the padding, whose other bytes are arbitrary.
+		padding := int(plaintext[len(plaintext)-1])
+		if padding == 0 || padding > blockSize {
+			return nil, fmt.Errorf("invalid padding")
+		}
+		return plaintext[:len(plaintext)-padding], nil
+	}
+}
+
+func cipherValue(el *etree.Element) ([]byte, error) {
+	cipherData := childElement(el, XMLENC_NAMESPACE, "CipherData")
+	if cipherData == nil {
+		return nil, fmt.Errorf("missing CipherData")
+	}
+
+	value := childElement(cipherData, XMLENC_NAMESPACE, "CipherValue")
+	if value == nil {
+		return nil, fmt.Errorf("missing CipherValue")
+	}
+
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value.Text()), ""))
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	TEST_IDP_URL    = "https://idp.example.com/sso"
+	TEST_IDP_ISSUER = "https://idp.example.com/metadata"
+	TEST_SP_URL     = "https://mattermost.example.com/login/sso/saml"
+)
+
+type testKeyPair struct {
+	key         *rsa.PrivateKey
+	certificate *x509.Certificate
+}
+
+func newTestKeyPair(t *testing.T, commonName string) *testKeyPair {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+
+	return &testKeyPair{key: key, certificate: certificate}
+}
+
+func (kp *testKeyPair) GetKeyPair() (*rsa.PrivateKey, []byte, error) {
+	return kp.key, kp.certificate.Raw, nil
+}
+
+func (kp *testKeyPair) certificatePEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: kp.certificate.Raw})
+}
+
+func (kp *testKeyPair) keyPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(kp.key)})
+}
+
+type testEnvironment struct {
+	idp      *testKeyPair
+	sp       *testKeyPair
+	files    map[string][]byte
+	settings model.SamlSettings
+	messages *testMessageTracker
+}
+
+func newTestEnvironment(t *testing.T) *testEnvironment {
+	env := &testEnvironment{
+		idp:      newTestKeyPair(t, "idp"),
+		sp:       newTestKeyPair(t, "sp"),
+		messages: newTestMessageTracker(),
+	}
+
+	env.files = map[string][]byte{
+		"saml-idp.crt":     env.idp.certificatePEM(),
+		"saml-public.crt":  env.sp.certificatePEM(),
+		"saml-private.key": env.sp.keyPEM(),
+	}
+
+	env.settings.SetDefaults()
+	*env.settings.Enable = true
+	*env.settings.Verify = true
+	*env.settings.Encrypt = false
+	*env.settings.IdpUrl = TEST_IDP_URL
+	*env.settings.IdpDescriptorUrl = TEST_IDP_ISSUER
+	*env.settings.AssertionConsumerServiceURL = TEST_SP_URL
+	*env.settings.IdpCertificateFile = "saml-idp.crt"
+	*env.settings.PublicCertificateFile = "saml-public.crt"
+	*env.settings.PrivateKeyFile = "saml-private.key"
+	*env.settings.IdAttribute = "objectGUID"
+	*env.settings.EmailAttribute = "Email"
+	*env.settings.UsernameAttribute = "Username"
+	*env.settings.FirstNameAttribute = "FirstName"
+	*env.settings.LastNameAttribute = "LastName"
+	*env.settings.PositionAttribute = "Position"
+
+	return env
+}
+
+func (env *testEnvironment) getFile(name string) ([]byte, error) {
+	data, ok := env.files[name]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	return data, nil
+}
+
+func (env *testEnvironment) serviceProvider(t *testing.T) *serviceProvider {
+	sp, appErr := newServiceProvider(env.settings, env.getFile, env.messages)
+	require.Nil(t, appErr)
+	return sp
+}
+
+// testMessageTracker keeps the ids of SAML messages in memory.
+type testMessageTracker struct {
+	mutex      sync.Mutex
+	requests   map[string]bool
+	assertions map[string]bool
+}
+
+func newTestMessageTracker() *testMessageTracker {
+	return &testMessageTracker{
+		requests:   map[string]bool{},
+		assertions: map[string]bool{},
+	}
+}
+
+func (m *testMessageTracker) addRequest(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.requests[id] = true
+	return nil
+}
+
+func (m *testMessageTracker) useRequest(id string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if !m.requests[id] {
+		return false
+	}
+	delete(m.requests, id)
+	return true
+}
+
+func (m *testMessageTracker) useAssertion(id string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.assertions[id] {
+		return false
+	}
+	m.assertions[id] = true
+	return true
+}
+
+type testResponseOptions struct {
+	status       string
+	destination  string
+	audience     string
+	recipient    string
+	notBefore    time.Time
+	notOnOrAfter time.Time
+	assertionId  string
+	inResponseTo string
+	attributes   map[string]string
+}
+
+func defaultResponseOptions() testResponseOptions {
+	return testResponseOptions{
+		status:       STATUS_SUCCESS,
+		destination:  TEST_SP_URL,
+		audience:     TEST_SP_URL,
+		recipient:    TEST_SP_URL,
+		notBefore:    time.Now().Add(-time.Minute),
+		notOnOrAfter: time.Now().Add(5 * time.Minute),
+		assertionId:  "_" + model.NewId(),
+		attributes: map[string]string{
+			"objectGUID": "guid1",
+			"Email":      "User1@Example.com",
+			"Username":   "User1",
+			"FirstName":  "First",
+			"LastName":   "Last",
+			"Position":   "Tester",
+		},
+	}
+}
+
+// newTestResponse builds a response whose assertion relies on the namespaces declared by the
+// response, as most Identity Providers do.
+func newTestResponse(t *testing.T, options testResponseOptions) *etree.Document {
+	attributes := ""
+	for name, value := range options.attributes {
+		attributes += fmt.Sprintf(`<saml:Attribute Name="%s"><saml:AttributeValue>%s</saml:AttributeValue></saml:Attribute>`, name, value)
+	}
+
+	inResponseTo := ""
+	if options.inResponseTo != "" {
+		inResponseTo = fmt.Sprintf(` InResponseTo="%s"`, options.inResponseTo)
+	}
+
+	xml := fmt.Sprintf(`<samlp:Response xmlns:samlp="%[1]s" xmlns:saml="%[2]s" ID="_response" Version="2.0" IssueInstant="%[3]s" Destination="%[4]s"%[13]s>`+
+		`<saml:Issuer>%[5]s</saml:Issuer>`+
+		`<samlp:Status><samlp:StatusCode Value="%[6]s"/></samlp:Status>`+
+		`<saml:Assertion ID="%[12]s" Version="2.0" IssueInstant="%[3]s">`+
+		`<saml:Issuer>%[5]s</saml:Issuer>`+
+		`<saml:Subject><saml:NameID>user1</saml:NameID><saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">`+
+		`<saml:SubjectConfirmationData NotOnOrAfter="%[8]s" Recipient="%[9]s"%[13]s/></saml:SubjectConfirmation></saml:Subject>`+
+		`<saml:Conditions NotBefore="%[7]s" NotOnOrAfter="%[8]s"><saml:AudienceRestriction><saml:Audience>%[10]s</saml:Audience></saml:AudienceRestriction></saml:Conditions>`+
+		`<saml:AttributeStatement>%[11]s</saml:AttributeStatement>`+
+		`</saml:Assertion></samlp:Response>`,
+		PROTOCOL_NAMESPACE, ASSERTION_NAMESPACE, time.Now().UTC().Format(time.RFC3339), options.destination, TEST_IDP_ISSUER,
+		options.status, options.notBefore.UTC().Format(time.RFC3339), options.notOnOrAfter.UTC().Format(time.RFC3339),
+		options.recipient, options.audience, attributes, options.assertionId, inResponseTo)
+
+	doc := etree.NewDocument()
+	require.Nil(t, doc.ReadFromString(xml))
+	return doc
+}
+
+func assertionOf(doc *etree.Document) *etree.Element {
+	return childElement(doc.Root(), ASSERTION_NAMESPACE, "Assertion")
+}
+
+// sign adds an enveloped signature to the element, computed as the Identity Provider would.
+func sign(t *testing.T, keyPair *testKeyPair, el *etree.Element) {
+	ctx, err := etreeutils.NSBuildParentContext(el)
+	require.Nil(t, err)
+	detached, err := etreeutils.NSDetatch(ctx, el)
+	require.Nil(t, err)
+
+	signingContext := dsig.NewDefaultSigningContext(keyPair)
+	signingContext.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+
+	signature, err := signingContext.ConstructSignature(detached, true)
+	require.Nil(t, err)
+
+	// The signature goes right after the issuer, as the SAML schema requires.
+	el.InsertChildAt(1, signature)
+}
+
+// encrypt replaces the assertion of the response with an EncryptedAssertion for the service
+// provider, using the given data encryption algorithm.
+func encrypt(t *testing.T, keyPair *testKeyPair, doc *etree.Document, algorithm string) {
+	assertion := assertionOf(doc)
+
+	ctx, err := etreeutils.NSBuildParentContext(assertion)
+	require.Nil(t, err)
+	detached, err := etreeutils.NSDetatch(ctx, assertion)
+	require.Nil(t, err)
+
+	assertionDoc := etree.NewDocument()
+	assertionDoc.SetRoot(detached)
+	plaintext, err := assertionDoc.WriteToBytes()
+	require.Nil(t, err)
+
+	key := make([]byte, 16)
+	_, err = rand.Read(key)
+	require.Nil(t, err)
+
+	block, err := aes.NewCipher(key)
+	require.Nil(t, err)
+
+	var ciphertext []byte
+	switch algorithm {
+	case AES128_GCM:
+		gcm, err := cipher.NewGCM(block)
+		require.Nil(t, err)
+		nonce := make([]byte, gcm.NonceSize())
+		_, err = rand.Read(nonce)
+		require.Nil(t, err)
+		ciphertext = gcm.Seal(nonce, nonce, plaintext, nil)
+	default:
+		padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+		for i := 0; i < padding; i++ {
+			plaintext = append(plaintext, byte(padding))
+		}
+		ciphertext = make([]byte, aes.BlockSize+len(plaintext))
+		_, err = rand.Read(ciphertext[:aes.BlockSize])
+		require.Nil(t, err)
+		cipher.NewCBCEncrypter(block, ciphertext[:aes.BlockSize]).CryptBlocks(ciphertext[aes.BlockSize:], plaintext)
+	}
+
+	encryptedKey, err := rsa.EncryptOAEP(sha1.New(), rand.Reader, &keyPair.key.PublicKey, key, nil)
+	require.Nil(t, err)
+
+	encryptedAssertion := etree.NewElement("saml:EncryptedAssertion")
+	encryptedData := encryptedAssertion.CreateElement("xenc:EncryptedData")
+	encryptedData.CreateAttr("xmlns:xenc", XMLENC_NAMESPACE)
+	encryptedData.CreateElement("xenc:EncryptionMethod").CreateAttr("Algorithm", algorithm)
+
+	keyInfo := encryptedData.CreateElement("ds:KeyInfo")
+	keyInfo.CreateAttr("xmlns:ds", dsig.Namespace)
+	keyElement := keyInfo.CreateElement("xenc:EncryptedKey")
+	keyElement.CreateElement("xenc:EncryptionMethod").CreateAttr("Algorithm", RSA_OAEP_MGF1P)
+	keyElement.CreateElement("xenc:CipherData").CreateElement("xenc:CipherValue").SetText(base64.StdEncoding.EncodeToString(encryptedKey))
+
+	encryptedData.CreateElement("xenc:CipherData").CreateElement("xenc:CipherValue").SetText(base64.StdEncoding.EncodeToString(ciphertext))
+
+	doc.Root().InsertChild(assertion, encryptedAssertion)
+	doc.Root().RemoveChild(assertion)
+}
+
+func encode(t *testing.T, doc *etree.Document) string {
+	xml, err := doc.WriteToBytes()
+	require.Nil(t, err)
+	return base64.StdEncoding.EncodeToString(xml)
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	STATUS_SUCCESS = "urn:oasis:names:tc:SAML:2.0:status:Success"
+
+	// MAX_CLOCK_SKEW is how far the clocks of Mattermost and the Identity Provider may drift apart
+	// before assertions are rejected as not yet or no longer valid.
+	MAX_CLOCK_SKEW = 3 * time.Minute
+)
+
+// parseResponse validates a base64 encoded Response posted by the Identity Provider and maps the
+// attributes of its assertion to a Mattermost user. When Verify is enabled, the response or its
+// assertion must be signed by the Identity Provider, and the assertion must be meant for Mattermost
+// and currently valid. When Encrypt is enabled, the assertion must be encrypted.
+func (sp *serviceProvider) parseResponse(encodedXML string) (*model.User, *model.AppError) {
+	if encodedXML == "" {
+		return nil, model.NewAppError("parseResponse", "ent.saml.do_login.empty_response.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encodedXML), ""))
+	if err != nil {
+		return nil, model.NewAppError("parseResponse", "ent.saml.do_login.parse.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	doc := etree.NewDocument()
+	if err = doc.ReadFromBytes(data); err != nil {
+		return nil, model.NewAppError("parseResponse", "ent.saml.do_login.parse.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	response := doc.Root()
+	if response == nil || response.Tag != "Response" || response.NamespaceURI() != PROTOCOL_NAMESPACE {
+		return nil, model.NewAppError("parseResponse", "ent.saml.do_login.parse.app_error", nil, "not a SAML response", http.StatusBadRequest)
+	}
+
+	// Only the parts of the document covered by a signature can be trusted from here on, so the
+	// validated copy replaces the original.
+	responseSigned := false
+	if *sp.settings.Verify && childElement(response, dsig.Namespace, "Signature") != nil {
+		if response, err = sp.validateSignature(response); err != nil {
+			return nil, model.NewAppError("parseResponse", "ent.saml.do_login.validate.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+		responseSigned = true
+	}
+
+	if err = sp.validateResponse(response); err != nil {
+		return nil, model.NewAppError("parseResponse", "ent.saml.do_login.validate.app_error", nil, err.Error(), http.StatusBadRequest)
+	}
+
+	assertion := childElement(response, ASSERTION_NAMESPACE, "Assertion")
+	if encryptedAssertion := childElement(response, ASSERTION_NAMESPACE, "EncryptedAssertion"); encryptedAssertion != nil {
+		decrypted, err := sp.decryptAssertion(encryptedAssertion)
+		if err != nil {
+			return nil, model.NewAppError("parseResponse", "ent.saml.do_login.decrypt.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+
+		// Put the assertion in place of its encrypted form so that it can rely on the namespaces
+		// declared by the response.
+		response.InsertChild(encryptedAssertion, decrypted)
+		response.RemoveChild(encryptedAssertion)
+		assertion = decrypted
+	} else if *sp.settings.Encrypt {
+		return nil, model.NewAppError("parseResponse", "ent.saml.configure.not_encrypted_response.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	if assertion == nil || assertion.Tag != "Assertion" || assertion.NamespaceURI() != ASSERTION_NAMESPACE {
+		return nil, model.NewAppError("parseResponse", "ent.saml.do_login.parse.app_error", nil, "missing assertion", http.StatusBadRequest)
+	}
+
+	if *sp.settings.Verify {
+		if !responseSigned || childElement(assertion, dsig.Namespace, "Signature") != nil {
+			if assertion, err = sp.validateSignature(assertion); err != nil {
+				return nil, model.NewAppError("parseResponse", "ent.saml.do_login.validate.app_error", nil, err.Error(), http.StatusBadRequest)
+			}
+		}
+
+		if err = sp.validateAssertion(assertion); err != nil {
+			return nil, model.NewAppError("parseResponse", "ent.saml.do_login.validate.app_error", nil, err.Error(), http.StatusBadRequest)
+		}
+	}
+
+	return sp.userFromAssertion(assertion)
+}
+
+// validateSignature checks the enveloped signature of the element against the certificate of the
+// Identity Provider and returns the signed content.
+func (sp *serviceProvider) validateSignature(el *etree.Element) (*etree.Element, error) {
+	if sp.idpCertificate == nil {
+		return nil, fmt.Errorf("the Identity Provider certificate wasn't uploaded")
+	}
+
+	ctx, err := etreeutils.NSBuildParentContext(el)
+	if err != nil {
+		return nil, err
+	}
+
+	detached, err := etreeutils.NSDetatch(ctx, el)
+	if err != nil {
+		return nil, err
+	}
+
+	validationContext := dsig.NewDefaultValidationContext(&dsig.MemoryX509CertificateStore{
+		Roots: []*x509.Certificate{sp.idpCertificate},
+	})
+	validationContext.Clock = sp.clock
+
+	return validationContext.Validate(detached)
+}
+
+func (sp *serviceProvider) validateResponse(response *etree.Element) error {
+	status := childElement(childElement(response, PROTOCOL_NAMESPACE, "Status"), PROTOCOL_NAMESPACE, "StatusCode")
+	if status == nil {
+		return fmt.Errorf("missing status")
+	}
+	if value := status.SelectAttrValue("Value", ""); value != STATUS_SUCCESS {
+		return fmt.Errorf("the Identity Provider responded with %s", value)
+	}
+
+	if destination := response.SelectAttrValue("Destination", ""); destination != "" && *sp.settings.AssertionConsumerServiceURL != "" && destination != *sp.settings.AssertionConsumerServiceURL {
+		return fmt.Errorf("the response is meant for %s", destination)
+	}
+
+	if issuer := childElement(response, ASSERTION_NAMESPACE, "Issuer"); issuer != nil && *sp.settings.IdpDescriptorUrl != "" && strings.TrimSpace(issuer.Text()) != *sp.settings.IdpDescriptorUrl {
+		return fmt.Errorf("the response was issued by %s", strings.TrimSpace(issuer.Text()))
+	}
+
+	return nil
+}
+
+// validateAssertion checks that the assertion was issued by the Identity Provider for Mattermost,
+// that it is currently valid and that it wasn't used before. An assertion answering a request must
+// answer one that Mattermost sent and that wasn't answered yet, while unsolicited ones are allowed.
+func (sp *serviceProvider) validateAssertion(assertion *etree.Element) error {
+	now := sp.clock.Now()
+
+	id := assertion.SelectAttrValue("ID", "")
+	if id == "" {
+		return fmt.Errorf("missing assertion id")
+	}
+
+	if issuer := childElement(assertion, ASSERTION_NAMESPACE, "Issuer"); issuer == nil {
+		return fmt.Errorf("missing assertion issuer")
+	} else if *sp.settings.IdpDescriptorUrl != "" && strings.TrimSpace(issuer.Text()) != *sp.settings.IdpDescriptorUrl {
+		return fmt.Errorf("the assertion was issued by %s", strings.TrimSpace(issuer.Text()))
+	}
+
+	if conditions := childElement(assertion, ASSERTION_NAMESPACE, "Conditions"); conditions != nil {
+		if err := checkValidity(conditions, now); err != nil {
+			return err
+		}
+
+		for _, restriction := range childElements(conditions, ASSERTION_NAMESPACE, "AudienceRestriction") {
+			found := false
+			for _, audience := range childElements(restriction, ASSERTION_NAMESPACE, "Audience") {
+				if strings.TrimSpace(audience.Text()) == sp.entityId() {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("the assertion isn't meant for %s", sp.entityId())
+			}
+		}
+	}
+
+	inResponseTo := ""
+	subject := childElement(assertion, ASSERTION_NAMESPACE, "Subject")
+	for _, confirmation := range childElements(subject, ASSERTION_NAMESPACE, "SubjectConfirmation") {
+		data := childElement(confirmation, ASSERTION_NAMESPACE, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+
+		if err := checkValidity(data, now); err != nil {
+			return err
+		}
+
+		if recipient := data.SelectAttrValue("Recipient", ""); recipient != "" && recipient != *sp.settings.AssertionConsumerServiceURL {
+			return fmt.Errorf("the assertion is meant for %s", recipient)
+		}
+
+		if requestId := data.SelectAttrValue("InResponseTo", ""); requestId != "" {
+			if inResponseTo != "" && requestId != inResponseTo {
+				return fmt.Errorf("the assertion answers several requests")
+			}
+			inResponseTo = requestId
+		}
+	}
+
+	// The assertion is only marked as used once it's otherwise known to be valid.
+	if !sp.messages.useAssertion(id) {
+		return fmt.Errorf("the assertion %s was already used", id)
+	}
+
+	if inResponseTo != "" && !sp.messages.useRequest(inResponseTo) {
+		return fmt.Errorf("the assertion answers the unknown or already answered request %s", inResponseTo)
+	}
+
+	return nil
+}
+
+// checkValidity checks the NotBefore and NotOnOrAfter attributes of the element, if any.
+func checkValidity(el *etree.Element, now time.Time) error {
+	if notBefore := el.SelectAttrValue("NotBefore", ""); notBefore != "" {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil {
+			return err
+		}
+		if now.Add(MAX_CLOCK_SKEW).Before(t) {
+			return fmt.Errorf("the assertion is not valid before %s", notBefore)
+		}
+	}
+
+	if notOnOrAfter := el.SelectAttrValue("NotOnOrAfter", ""); notOnOrAfter != "" {
+		t, err := time.Parse(time.RFC3339, notOnOrAfter)
+		if err != nil {
+			return err
+		}
+		if !now.Add(-MAX_CLOCK_SKEW).Before(t) {
+			return fmt.Errorf("the assertion expired at %s", notOnOrAfter)
+		}
+	}
+
+	return nil
+}
+
+// userFromAssertion maps the attributes of the assertion to a Mattermost user as configured by the
+// SamlSettings. The user is identified by the id attribute if one is configured, or by email.
+func (sp *serviceProvider) userFromAssertion(assertion *etree.Element) (*model.User, *model.AppError) {
+	attributes := map[string]string{}
+	for _, statement := range childElements(assertion, ASSERTION_NAMESPACE, "AttributeStatement") {
+		for _, attribute := range childElements(statement, ASSERTION_NAMESPACE, "Attribute") {
+			if value := childElement(attribute, ASSERTION_NAMESPACE, "AttributeValue"); value != nil {
+				attributes[attribute.SelectAttrValue("Name", "")] = strings.TrimSpace(value.Text())
+			}
+		}
+	}
+
+	attribute := func(name string) string {
+		if name == "" {
+			return ""
+		}
+		return attributes[name]
+	}
+
+	user := &model.User{
+		AuthService: model.USER_AUTH_SERVICE_SAML,
+		Email:       strings.ToLower(attribute(*sp.settings.EmailAttribute)),
+		Username:    model.CleanUsername(attribute(*sp.settings.UsernameAttribute)),
+		FirstName:   attribute(*sp.settings.FirstNameAttribute),
+		LastName:    attribute(*sp.settings.LastNameAttribute),
+		Nickname:    attribute(*sp.settings.NicknameAttribute),
+		Locale:      attribute(*sp.settings.LocaleAttribute),
+		Position:    attribute(*sp.settings.PositionAttribute),
+	}
+
+	if user.Email == "" || attribute(*sp.settings.UsernameAttribute) == "" {
+		return nil, model.NewAppError("userFromAssertion", "ent.saml.attribute.app_error", nil, "missing email or username attribute", http.StatusBadRequest)
+	}
+
+	authData := user.Email
+	if *sp.settings.IdAttribute != "" {
+		if authData = attribute(*sp.settings.IdAttribute); authData == "" {
+			return nil, model.NewAppError("userFromAssertion", "ent.saml.attribute.app_error", nil, "missing id attribute", http.StatusBadRequest)
+		}
+	}
+	user.AuthData = &authData
+
+	return user, nil
+}
+
+// childElement returns the first child of the element with the given namespace and tag, or nil if
+// there isn't one. el may be nil to allow for chaining.
+func childElement(el *etree.Element, namespace, tag string) *etree.Element {
+	if children := childElements(el, namespace, tag); len(children) > 0 {
+		return children[0]
+	}
+
+	return nil
+}
+
+func childElements(el *etree.Element, namespace, tag string) []*etree.Element {
+	if el == nil {
+		return nil
+	}
+
+	var children []*etree.Element
+	for _, child := range el.ChildElements() {
+		if child.Tag == tag && child.NamespaceURI() == namespace {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
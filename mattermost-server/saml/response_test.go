@@ -0,0 +1,260 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestParseResponse(t *testing.T) {
+	env := newTestEnvironment(t)
+	sp := env.serviceProvider(t)
+
+	t.Run("signed assertion", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, assertionOf(doc))
+
+		user, appErr := sp.parseResponse(encode(t, doc))
+		require.Nil(t, appErr)
+		assert.Equal(t, model.USER_AUTH_SERVICE_SAML, user.AuthService)
+		assert.Equal(t, "guid1", *user.AuthData)
+		assert.Equal(t, "user1@example.com", user.Email)
+		assert.Equal(t, "user1", user.Username)
+		assert.Equal(t, "First", user.FirstName)
+		assert.Equal(t, "Last", user.LastName)
+		assert.Equal(t, "Tester", user.Position)
+		assert.Equal(t, "", user.Nickname)
+	})
+
+	t.Run("signed response", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, doc.Root())
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.Nil(t, appErr)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+
+		settings := env.settings
+		settings.Verify = model.NewBool(false)
+		unverified, appErr := newServiceProvider(settings, env.getFile, env.messages)
+		require.Nil(t, appErr)
+
+		_, appErr = unverified.parseResponse(encode(t, doc))
+		require.Nil(t, appErr)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, assertionOf(doc))
+
+		attributeValue := assertionOf(doc).FindElement(".//AttributeValue")
+		attributeValue.SetText(attributeValue.Text() + "x")
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+	})
+
+	t.Run("signed by someone else", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, newTestKeyPair(t, "other"), assertionOf(doc))
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+	})
+
+	t.Run("unsigned assertion added to a signed response", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, assertionOf(doc))
+
+		// Only the signed assertion may be used, not one slipped in front of it.
+		forged := assertionOf(doc).Copy()
+		forged.RemoveChildAt(1)
+		forged.FindElement(".//AttributeValue").SetText("forged")
+		doc.Root().InsertChild(assertionOf(doc), forged)
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+	})
+
+	for name, modify := range map[string]func(options *testResponseOptions){
+		"failed status":     func(options *testResponseOptions) { options.status = "urn:oasis:names:tc:SAML:2.0:status:Requester" },
+		"wrong destination": func(options *testResponseOptions) { options.destination = "https://other.example.com" },
+		"wrong audience":    func(options *testResponseOptions) { options.audience = "https://other.example.com" },
+		"wrong recipient":   func(options *testResponseOptions) { options.recipient = "https://other.example.com" },
+		"expired":           func(options *testResponseOptions) { options.notOnOrAfter = time.Now().Add(-time.Hour) },
+		"not yet valid":     func(options *testResponseOptions) { options.notBefore = time.Now().Add(time.Hour) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			options := defaultResponseOptions()
+			modify(&options)
+
+			doc := newTestResponse(t, options)
+			sign(t, env.idp, assertionOf(doc))
+
+			_, appErr := sp.parseResponse(encode(t, doc))
+			require.NotNil(t, appErr)
+			assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+		})
+	}
+
+	t.Run("clock skew", func(t *testing.T) {
+		options := defaultResponseOptions()
+		options.notBefore = time.Now().Add(time.Minute)
+
+		doc := newTestResponse(t, options)
+		sign(t, env.idp, assertionOf(doc))
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.Nil(t, appErr)
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, assertionOf(doc))
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.Nil(t, appErr)
+
+		_, appErr = sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+	})
+
+	t.Run("in response to a request", func(t *testing.T) {
+		request, err := sp.buildRequest("")
+		require.Nil(t, err)
+
+		xml, err := base64.StdEncoding.DecodeString(request.Base64AuthRequest)
+		require.Nil(t, err)
+		requestDoc := etree.NewDocument()
+		require.Nil(t, requestDoc.ReadFromBytes(xml))
+
+		options := defaultResponseOptions()
+		options.inResponseTo = requestDoc.Root().SelectAttrValue("ID", "")
+		doc := newTestResponse(t, options)
+		sign(t, env.idp, assertionOf(doc))
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.Nil(t, appErr)
+
+		// Each request may only be answered once.
+		options.assertionId = "_" + model.NewId()
+		doc = newTestResponse(t, options)
+		sign(t, env.idp, assertionOf(doc))
+
+		_, appErr = sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+	})
+
+	t.Run("in response to an unknown request", func(t *testing.T) {
+		options := defaultResponseOptions()
+		options.inResponseTo = "_" + model.NewId()
+		doc := newTestResponse(t, options)
+		sign(t, env.idp, assertionOf(doc))
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.validate.app_error", appErr.Id)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, appErr := sp.parseResponse("")
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.empty_response.app_error", appErr.Id)
+	})
+
+	t.Run("not a response", func(t *testing.T) {
+		for _, encodedXML := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("<not xml")), base64.StdEncoding.EncodeToString([]byte("<Response/>"))} {
+			_, appErr := sp.parseResponse(encodedXML)
+			require.NotNil(t, appErr)
+			assert.Equal(t, "ent.saml.do_login.parse.app_error", appErr.Id)
+		}
+	})
+}
+
+func TestParseEncryptedResponse(t *testing.T) {
+	env := newTestEnvironment(t)
+	*env.settings.Encrypt = true
+	sp := env.serviceProvider(t)
+
+	for _, algorithm := range []string{AES128_CBC, AES128_GCM} {
+		t.Run(algorithm, func(t *testing.T) {
+			doc := newTestResponse(t, defaultResponseOptions())
+			sign(t, env.idp, assertionOf(doc))
+			encrypt(t, env.sp, doc, algorithm)
+
+			user, appErr := sp.parseResponse(encode(t, doc))
+			require.Nil(t, appErr)
+			assert.Equal(t, "guid1", *user.AuthData)
+		})
+	}
+
+	t.Run("for someone else", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, assertionOf(doc))
+		encrypt(t, newTestKeyPair(t, "other"), doc, AES128_CBC)
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.do_login.decrypt.app_error", appErr.Id)
+	})
+
+	t.Run("not encrypted", func(t *testing.T) {
+		doc := newTestResponse(t, defaultResponseOptions())
+		sign(t, env.idp, assertionOf(doc))
+
+		_, appErr := sp.parseResponse(encode(t, doc))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.configure.not_encrypted_response.app_error", appErr.Id)
+	})
+}
+
+func TestUserFromAssertion(t *testing.T) {
+	env := newTestEnvironment(t)
+	*env.settings.Verify = false
+
+	t.Run("missing username", func(t *testing.T) {
+		options := defaultResponseOptions()
+		delete(options.attributes, "Username")
+
+		_, appErr := env.serviceProvider(t).parseResponse(encode(t, newTestResponse(t, options)))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.attribute.app_error", appErr.Id)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		options := defaultResponseOptions()
+		delete(options.attributes, "objectGUID")
+
+		_, appErr := env.serviceProvider(t).parseResponse(encode(t, newTestResponse(t, options)))
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.attribute.app_error", appErr.Id)
+	})
+
+	t.Run("identified by email", func(t *testing.T) {
+		*env.settings.IdAttribute = ""
+
+		user, appErr := env.serviceProvider(t).parseResponse(encode(t, newTestResponse(t, defaultResponseOptions())))
+		require.Nil(t, appErr)
+		assert.Equal(t, "user1@example.com", *user.AuthData)
+	})
+}
@@ -0,0 +1,319 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/einterfaces"
+	"github.com/mattermost/mattermost-server/mlog"
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SamlInterfaceImpl struct {
+	App *app.App
+
+	mutex           sync.RWMutex
+	serviceProvider *serviceProvider
+}
+
+const (
+	TOKEN_TYPE_SAML_REQUEST   = "saml_request"
+	TOKEN_TYPE_SAML_ASSERTION = "saml_assertion"
+)
+
+func init() {
+	app.RegisterSamlInterface(func(a *app.App) einterfaces.SamlInterface {
+		return &SamlInterfaceImpl{App: a}
+	})
+}
+
+// ConfigureSP loads the certificates named by the SamlSettings. It's called whenever the
+// configuration changes.
+func (s *SamlInterfaceImpl) ConfigureSP() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.serviceProvider = nil
+
+	settings := s.App.Config().SamlSettings
+	if !*settings.Enable {
+		return nil
+	}
+
+	sp, appErr := newServiceProvider(settings, s.App.GetConfigFile, &tokenMessageTracker{store: s.App.Srv.Store.Token()})
+	if appErr != nil {
+		return appErr
+	}
+	s.serviceProvider = sp
+
+	return nil
+}
+
+// getServiceProvider returns the configured service provider, configuring it first if that wasn't
+// done yet or failed the last time.
+func (s *SamlInterfaceImpl) getServiceProvider() (*serviceProvider, *model.AppError) {
+	if !*s.App.Config().SamlSettings.Enable {
+		return nil, model.NewAppError("getServiceProvider", "ent.saml.service_disable.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	s.mutex.RLock()
+	sp := s.serviceProvider
+	s.mutex.RUnlock()
+
+	if sp != nil {
+		return sp, nil
+	}
+
+	if err := s.ConfigureSP(); err != nil {
+		if appErr, ok := err.(*model.AppError); ok {
+			return nil, appErr
+		}
+		return nil, model.NewAppError("getServiceProvider", "ent.saml.service_disable.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.serviceProvider == nil {
+		return nil, model.NewAppError("getServiceProvider", "ent.saml.service_disable.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	return s.serviceProvider, nil
+}
+
+func (s *SamlInterfaceImpl) BuildRequest(relayState string) (*model.SamlAuthRequest, *model.AppError) {
+	sp, appErr := s.getServiceProvider()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	request, err := sp.buildRequest(relayState)
+	if err != nil {
+		return nil, model.NewAppError("BuildRequest", "ent.saml.build_request.encoding.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return request, nil
+}
+
+// GetMetadata describes Mattermost as configured by the current settings, even if SAML isn't
+// enabled yet, since the Identity Provider usually needs it before logins can be enabled.
+func (s *SamlInterfaceImpl) GetMetadata() (string, *model.AppError) {
+	sp, appErr := newServiceProvider(s.App.Config().SamlSettings, s.App.GetConfigFile, &tokenMessageTracker{store: s.App.Srv.Store.Token()})
+	if appErr != nil {
+		return "", model.NewAppError("GetMetadata", "ent.saml.metadata.app_error", nil, appErr.Error(), http.StatusInternalServerError)
+	}
+
+	metadata, err := sp.metadata()
+	if err != nil {
+		return "", model.NewAppError("GetMetadata", "ent.saml.metadata.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return metadata, nil
+}
+
+// DoLogin validates the response of the Identity Provider and returns the Mattermost user it
+// identifies, which is created on first login and kept up to date with the attributes of the
+// assertion afterwards. Users switching from email to SAML are attached to the SAML account instead.
+func (s *SamlInterfaceImpl) DoLogin(encodedXML string, relayState map[string]string) (*model.User, *model.AppError) {
+	sp, appErr := s.getServiceProvider()
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	samlUser, appErr := sp.parseResponse(encodedXML)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if relayState["action"] == model.OAUTH_ACTION_EMAIL_TO_SSO {
+		return s.switchToSaml(relayState["email"], samlUser)
+	}
+
+	user, appErr := s.App.GetUserByAuth(samlUser.AuthData, model.USER_AUTH_SERVICE_SAML)
+	if appErr != nil {
+		if appErr.Id != store.MISSING_AUTH_ACCOUNT_ERROR {
+			return nil, appErr
+		}
+		return s.createUser(samlUser)
+	}
+
+	if user.DeleteAt != 0 {
+		return user, nil
+	}
+
+	if changed, appErr := s.updateUser(user, samlUser); appErr != nil {
+		return nil, appErr
+	} else if changed {
+		return s.App.GetUser(user.Id)
+	}
+
+	return user, nil
+}
+
+func (s *SamlInterfaceImpl) createUser(samlUser *model.User) (*model.User, *model.AppError) {
+	if !*s.App.Config().TeamSettings.EnableUserCreation {
+		return nil, model.NewAppError("createUser", "api.user.create_user.disabled.app_error", nil, "", http.StatusNotImplemented)
+	}
+
+	if existingUser, _ := s.App.GetUserByEmail(samlUser.Email); existingUser != nil {
+		authService := existingUser.AuthService
+		if authService == "" {
+			authService = model.USER_AUTH_SERVICE_EMAIL
+		}
+		return nil, model.NewAppError("createUser", "api.user.create_oauth_user.already_attached.app_error", map[string]interface{}{"Service": model.USER_AUTH_SERVICE_SAML_TEXT, "Auth": authService}, "email="+samlUser.Email, http.StatusBadRequest)
+	}
+
+	username := samlUser.Username
+	for count := 0; s.App.IsUsernameTaken(samlUser.Username); count++ {
+		samlUser.Username = username + strconv.Itoa(count)
+	}
+
+	samlUser.EmailVerified = true
+
+	return s.App.CreateUser(samlUser)
+}
+
+// switchToSaml attaches the user with the given email, who is switching from email login, to the
+// SAML account.
+func (s *SamlInterfaceImpl) switchToSaml(email string, samlUser *model.User) (*model.User, *model.AppError) {
+	if email == "" {
+		return nil, model.NewAppError("switchToSaml", "api.user.complete_switch_with_oauth.blank_email.app_error", nil, "", http.StatusBadRequest)
+	}
+
+	user, appErr := s.App.GetUserByEmail(email)
+	if appErr != nil {
+		return nil, appErr
+	}
+
+	if appErr := checkSwitchToSaml(user, samlUser); appErr != nil {
+		return nil, appErr
+	}
+
+	if result := <-s.App.Srv.Store.User().UpdateAuthData(user.Id, model.USER_AUTH_SERVICE_SAML, samlUser.AuthData, samlUser.Email, true); result.Err != nil {
+		return nil, result.Err
+	}
+
+	s.App.InvalidateCacheForUser(user.Id)
+
+	return s.App.GetUser(user.Id)
+}
+
+// checkSwitchToSaml returns an error unless the SAML account may be attached to the user. The
+// email of the user only comes from the relay state, which anyone starting the switch chooses, so
+// the Identity Provider must vouch for it, and users signing in with another service already keep
+// doing so.
+func checkSwitchToSaml(user, samlUser *model.User) *model.AppError {
+	if !strings.EqualFold(samlUser.Email, user.Email) {
+		return model.NewAppError("switchToSaml", "ent.saml.switch.email_mismatch.app_error", nil, "user_id="+user.Id, http.StatusForbidden)
+	}
+
+	if user.AuthService != "" && user.AuthService != model.USER_AUTH_SERVICE_EMAIL {
+		return model.NewAppError("switchToSaml", "api.user.create_oauth_user.already_attached.app_error", map[string]interface{}{"Service": model.USER_AUTH_SERVICE_SAML_TEXT, "Auth": user.AuthService}, "user_id="+user.Id, http.StatusBadRequest)
+	}
+
+	return nil
+}
+
+// updateUser saves the attributes of the SAML user to the Mattermost user if they changed, and
+// reports whether they did. A new username or email that is already taken by someone else is
+// ignored.
+func (s *SamlInterfaceImpl) updateUser(user, samlUser *model.User) (bool, *model.AppError) {
+	updatedUser := user.DeepCopy()
+	changed := false
+
+	update := func(field *string, value string, attribute string) {
+		if attribute != "" && *field != value {
+			*field = value
+			changed = true
+		}
+	}
+
+	settings := s.App.Config().SamlSettings
+	update(&updatedUser.FirstName, samlUser.FirstName, *settings.FirstNameAttribute)
+	update(&updatedUser.LastName, samlUser.LastName, *settings.LastNameAttribute)
+	update(&updatedUser.Nickname, samlUser.Nickname, *settings.NicknameAttribute)
+	update(&updatedUser.Position, samlUser.Position, *settings.PositionAttribute)
+	if samlUser.Locale != "" {
+		update(&updatedUser.Locale, samlUser.Locale, *settings.LocaleAttribute)
+	}
+
+	if samlUser.Username != user.Username {
+		if existingUser, _ := s.App.GetUserByUsername(samlUser.Username); existingUser == nil {
+			updatedUser.Username = samlUser.Username
+			changed = true
+		} else {
+			mlog.Warn("Not updating the username of a SAML user since it is already taken", mlog.String("user_id", user.Id), mlog.String("username", samlUser.Username))
+		}
+	}
+
+	if samlUser.Email != user.Email {
+		if existingUser, _ := s.App.GetUserByEmail(samlUser.Email); existingUser == nil {
+			updatedUser.Email = samlUser.Email
+			changed = true
+		} else {
+			mlog.Warn("Not updating the email of a SAML user since it is already taken", mlog.String("user_id", user.Id))
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	if result := <-s.App.Srv.Store.User().Update(updatedUser, true); result.Err != nil {
+		return false, result.Err
+	}
+
+	s.App.InvalidateCacheForUser(user.Id)
+
+	return true, nil
+}
+
+// tokenMessageTracker keeps the ids of SAML messages as tokens, which every server of the cluster
+// sees and which are cleaned up with the other expired tokens.
+type tokenMessageTracker struct {
+	store store.TokenStore
+}
+
+// messageToken hashes the id of the message, which the Identity Provider chooses, to the size of a
+// token.
+func messageToken(tokenType, id string) *model.Token {
+	hash := sha256.Sum256([]byte(id))
+	return &model.Token{
+		Token:    hex.EncodeToString(hash[:]),
+		CreateAt: model.GetMillis(),
+		Type:     tokenType,
+	}
+}
+
+func (t *tokenMessageTracker) addRequest(id string) error {
+	if result := <-t.store.Save(messageToken(TOKEN_TYPE_SAML_REQUEST, id)); result.Err != nil {
+		return result.Err
+	}
+	return nil
+}
+
+func (t *tokenMessageTracker) useRequest(id string) bool {
+	token := messageToken(TOKEN_TYPE_SAML_REQUEST, id)
+
+	result := <-t.store.GetByToken(token.Token)
+	if result.Err != nil || result.Data.(*model.Token).Type != TOKEN_TYPE_SAML_REQUEST {
+		return false
+	}
+
+	return (<-t.store.Delete(token.Token)).Err == nil
+}
+
+// useAssertion relies on the token of the assertion only being saved once, so that two servers
+// receiving the same assertion at the same time can't both accept it.
+func (t *tokenMessageTracker) useAssertion(id string) bool {
+	return (<-t.store.Save(messageToken(TOKEN_TYPE_SAML_ASSERTION, id))).Err == nil
+}
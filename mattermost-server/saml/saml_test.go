@@ -0,0 +1,35 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func TestCheckSwitchToSaml(t *testing.T) {
+	user := &model.User{Id: model.NewId(), Email: "user@example.com"}
+
+	t.Run("same email", func(t *testing.T) {
+		assert.Nil(t, checkSwitchToSaml(user, &model.User{Email: "User@Example.com"}))
+	})
+
+	t.Run("mismatched email", func(t *testing.T) {
+		appErr := checkSwitchToSaml(user, &model.User{Email: "attacker@example.com"})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.switch.email_mismatch.app_error", appErr.Id)
+	})
+
+	t.Run("attached to another service", func(t *testing.T) {
+		ldapUser := &model.User{Id: model.NewId(), Email: "user@example.com", AuthService: model.USER_AUTH_SERVICE_LDAP}
+
+		appErr := checkSwitchToSaml(ldapUser, &model.User{Email: "user@example.com"})
+		require.NotNil(t, appErr)
+		assert.Equal(t, "api.user.create_oauth_user.already_attached.app_error", appErr.Id)
+	})
+}
@@ -0,0 +1,302 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+	dsig "github.com/russellhaering/goxmldsig"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	PROTOCOL_NAMESPACE  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	ASSERTION_NAMESPACE = "urn:oasis:names:tc:SAML:2.0:assertion"
+	METADATA_NAMESPACE  = "urn:oasis:names:tc:SAML:2.0:metadata"
+	XMLENC_NAMESPACE    = "http://www.w3.org/2001/04/xmlenc#"
+
+	HTTP_POST_BINDING   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	NAME_ID_UNSPECIFIED = "urn:oasis:names:tc:SAML:1.1:nameid-format:unspecified"
+
+	METADATA_VALIDITY = 48 * time.Hour
+)
+
+// messageTracker remembers the requests sent to the Identity Provider and the assertions received
+// from it, across the servers of a cluster, so that responses only answer requests of Mattermost
+// and assertions can't be replayed.
+type messageTracker interface {
+	addRequest(id string) error
+
+	// useRequest reports whether the request was sent and not answered yet, and marks it answered.
+	useRequest(id string) bool
+
+	// useAssertion reports whether the assertion wasn't received before, and marks it received.
+	useAssertion(id string) bool
+}
+
+// serviceProvider holds everything needed to talk to the Identity Provider described by the
+// SamlSettings. It is rebuilt whenever the configuration changes and knows nothing about Mattermost
+// users, which is left to the callers.
+type serviceProvider struct {
+	settings model.SamlSettings
+
+	idpCertificate    *x509.Certificate
+	privateKey        *rsa.PrivateKey
+	publicCertificate *x509.Certificate
+
+	messages messageTracker
+	clock    *dsig.Clock
+}
+
+// newServiceProvider loads the certificates named by the settings using getFile, which reads a file
+// uploaded to the configuration store.
+func newServiceProvider(settings model.SamlSettings, getFile func(name string) ([]byte, error), messages messageTracker) (*serviceProvider, *model.AppError) {
+	sp := &serviceProvider{
+		settings: settings,
+		messages: messages,
+		clock:    dsig.NewRealClock(),
+	}
+
+	if *settings.IdpCertificateFile != "" {
+		certificate, err := loadCertificate(getFile, *settings.IdpCertificateFile)
+		if err != nil {
+			return nil, model.NewAppError("newServiceProvider", "ent.saml.configure.load_idp_cert.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+		sp.idpCertificate = certificate
+	}
+
+	// Requests are signed, and assertions can be encrypted, once both halves of the key pair were
+	// uploaded.
+	if *settings.PrivateKeyFile != "" && *settings.PublicCertificateFile != "" {
+		privateKey, err := loadPrivateKey(getFile, *settings.PrivateKeyFile)
+		if err != nil {
+			return nil, model.NewAppError("newServiceProvider", "ent.saml.configure.load_private_key.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		certificate, err := loadCertificate(getFile, *settings.PublicCertificateFile)
+		if err != nil {
+			return nil, model.NewAppError("newServiceProvider", "ent.saml.configure.load_public_cert.app_error", nil, err.Error(), http.StatusInternalServerError)
+		}
+
+		if publicKey, ok := certificate.PublicKey.(*rsa.PublicKey); !ok || publicKey.N.Cmp(privateKey.N) != 0 || publicKey.E != privateKey.E {
+			return nil, model.NewAppError("newServiceProvider", "ent.saml.configure.load_public_cert.app_error", nil, "the public certificate doesn't match the private key", http.StatusInternalServerError)
+		}
+
+		sp.privateKey = privateKey
+		sp.publicCertificate = certificate
+	}
+
+	if *settings.Encrypt && sp.privateKey == nil {
+		return nil, model.NewAppError("newServiceProvider", "ent.saml.configure.encryption_not_enabled.app_error", nil, "", http.StatusInternalServerError)
+	}
+
+	return sp, nil
+}
+
+func loadCertificate(getFile func(name string) ([]byte, error), name string) (*x509.Certificate, error) {
+	block, err := loadPEM(getFile, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func loadPrivateKey(getFile func(name string) ([]byte, error), name string) (*rsa.PrivateKey, error) {
+	block, err := loadPEM(getFile, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the private key must be an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+func loadPEM(getFile func(name string) ([]byte, error), name string) (*pem.Block, error) {
+	if name == "" {
+		return nil, fmt.Errorf("no file was uploaded")
+	}
+
+	data, err := getFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not PEM encoded", name)
+	}
+
+	return block, nil
+}
+
+// entityId identifies Mattermost to the Identity Provider, which is expected to use it as the
+// audience of its assertions.
+func (sp *serviceProvider) entityId() string {
+	return *sp.settings.AssertionConsumerServiceURL
+}
+
+func (sp *serviceProvider) canSign() bool {
+	return sp.privateKey != nil
+}
+
+// GetKeyPair implements dsig.X509KeyStore so that requests are signed with the service provider's key.
+func (sp *serviceProvider) GetKeyPair() (*rsa.PrivateKey, []byte, error) {
+	return sp.privateKey, sp.publicCertificate.Raw, nil
+}
+
+// metadata describes the service provider so that it can be registered with the Identity Provider.
+func (sp *serviceProvider) metadata() (string, error) {
+	if sp.entityId() == "" {
+		return "", fmt.Errorf("the Service Provider Login URL must be set")
+	}
+
+	doc := etree.NewDocument()
+	doc.CreateProcInst("xml", `version="1.0" encoding="UTF-8"`)
+
+	entity := doc.CreateElement("md:EntityDescriptor")
+	entity.CreateAttr("xmlns:md", METADATA_NAMESPACE)
+	entity.CreateAttr("xmlns:ds", dsig.Namespace)
+	entity.CreateAttr("entityID", sp.entityId())
+	entity.CreateAttr("validUntil", sp.clock.Now().UTC().Add(METADATA_VALIDITY).Format(time.RFC3339))
+
+	descriptor := entity.CreateElement("md:SPSSODescriptor")
+	descriptor.CreateAttr("protocolSupportEnumeration", PROTOCOL_NAMESPACE)
+	descriptor.CreateAttr("AuthnRequestsSigned", fmt.Sprint(sp.canSign()))
+	descriptor.CreateAttr("WantAssertionsSigned", fmt.Sprint(*sp.settings.Verify))
+
+	if sp.canSign() {
+		sp.addKeyDescriptor(descriptor, "signing")
+	}
+
+	if *sp.settings.Encrypt {
+		keyDescriptor := sp.addKeyDescriptor(descriptor, "encryption")
+		for _, algorithm := range []string{AES128_CBC, AES256_CBC, AES128_GCM, AES256_GCM, RSA_OAEP_MGF1P} {
+			keyDescriptor.CreateElement("md:EncryptionMethod").CreateAttr("Algorithm", algorithm)
+		}
+	}
+
+	descriptor.CreateElement("md:NameIDFormat").SetText(NAME_ID_UNSPECIFIED)
+
+	service := descriptor.CreateElement("md:AssertionConsumerService")
+	service.CreateAttr("Binding", HTTP_POST_BINDING)
+	service.CreateAttr("Location", *sp.settings.AssertionConsumerServiceURL)
+	service.CreateAttr("index", "0")
+
+	doc.Indent(2)
+	return doc.WriteToString()
+}
+
+func (sp *serviceProvider) addKeyDescriptor(descriptor *etree.Element, use string) *etree.Element {
+	keyDescriptor := descriptor.CreateElement("md:KeyDescriptor")
+	keyDescriptor.CreateAttr("use", use)
+	keyDescriptor.CreateElement("ds:KeyInfo").CreateElement("ds:X509Data").CreateElement("ds:X509Certificate").SetText(base64.StdEncoding.EncodeToString(sp.publicCertificate.Raw))
+	return keyDescriptor
+}
+
+// buildRequest builds an AuthnRequest asking for the response to be posted back to the Assertion
+// Consumer Service, and the URL sending it to the Identity Provider using the HTTP-Redirect binding.
+// The request is signed whenever a private key is available.
+func (sp *serviceProvider) buildRequest(relayState string) (*model.SamlAuthRequest, error) {
+	doc := etree.NewDocument()
+
+	id := "_" + model.NewId()
+	if err := sp.messages.addRequest(id); err != nil {
+		return nil, err
+	}
+
+	request := doc.CreateElement("samlp:AuthnRequest")
+	request.CreateAttr("xmlns:samlp", PROTOCOL_NAMESPACE)
+	request.CreateAttr("xmlns:saml", ASSERTION_NAMESPACE)
+	request.CreateAttr("ID", id)
+	request.CreateAttr("Version", "2.0")
+	request.CreateAttr("IssueInstant", sp.clock.Now().UTC().Format(time.RFC3339))
+	request.CreateAttr("Destination", *sp.settings.IdpUrl)
+	request.CreateAttr("ProtocolBinding", HTTP_POST_BINDING)
+	request.CreateAttr("AssertionConsumerServiceURL", *sp.settings.AssertionConsumerServiceURL)
+
+	request.CreateElement("saml:Issuer").SetText(sp.entityId())
+
+	nameIdPolicy := request.CreateElement("samlp:NameIDPolicy")
+	nameIdPolicy.CreateAttr("Format", NAME_ID_UNSPECIFIED)
+	nameIdPolicy.CreateAttr("AllowCreate", "true")
+
+	if *sp.settings.ScopingIDPProviderId != "" {
+		entry := request.CreateElement("samlp:Scoping").CreateElement("samlp:IDPList").CreateElement("samlp:IDPEntry")
+		entry.CreateAttr("ProviderID", *sp.settings.ScopingIDPProviderId)
+		if *sp.settings.ScopingIDPName != "" {
+			entry.CreateAttr("Name", *sp.settings.ScopingIDPName)
+		}
+	}
+
+	xml, err := doc.WriteToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var deflated bytes.Buffer
+	writer, err := flate.NewWriter(&deflated, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(xml); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	query := "SAMLRequest=" + url.QueryEscape(base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		query += "&RelayState=" + url.QueryEscape(relayState)
+	}
+
+	// With the HTTP-Redirect binding, the query string is signed rather than the request itself.
+	if sp.canSign() {
+		signingContext := dsig.NewDefaultSigningContext(sp)
+		query += "&SigAlg=" + url.QueryEscape(signingContext.GetSignatureMethodIdentifier())
+
+		signature, err := signingContext.SignString(query)
+		if err != nil {
+			return nil, err
+		}
+		query += "&Signature=" + url.QueryEscape(base64.StdEncoding.EncodeToString(signature))
+	}
+
+	separator := "?"
+	if strings.Contains(*sp.settings.IdpUrl, "?") {
+		separator = "&"
+	}
+
+	return &model.SamlAuthRequest{
+		Base64AuthRequest: base64.StdEncoding.EncodeToString(xml),
+		URL:               *sp.settings.IdpUrl + separator + query,
+		RelayState:        relayState,
+	}, nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/beevik/etree"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServiceProvider(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		env := newTestEnvironment(t)
+		sp := env.serviceProvider(t)
+		assert.True(t, sp.canSign())
+		assert.Equal(t, "idp", sp.idpCertificate.Subject.CommonName)
+	})
+
+	t.Run("without key pair", func(t *testing.T) {
+		env := newTestEnvironment(t)
+		*env.settings.PrivateKeyFile = ""
+
+		sp := env.serviceProvider(t)
+		assert.False(t, sp.canSign())
+
+		*env.settings.Encrypt = true
+		_, appErr := newServiceProvider(env.settings, env.getFile, env.messages)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.configure.encryption_not_enabled.app_error", appErr.Id)
+	})
+
+	t.Run("missing idp certificate", func(t *testing.T) {
+		env := newTestEnvironment(t)
+		delete(env.files, "saml-idp.crt")
+
+		_, appErr := newServiceProvider(env.settings, env.getFile, env.messages)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.configure.load_idp_cert.app_error", appErr.Id)
+	})
+
+	t.Run("invalid private key", func(t *testing.T) {
+		env := newTestEnvironment(t)
+		env.files["saml-private.key"] = []byte("not a key")
+
+		_, appErr := newServiceProvider(env.settings, env.getFile, env.messages)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.configure.load_private_key.app_error", appErr.Id)
+	})
+
+	t.Run("mismatched key pair", func(t *testing.T) {
+		env := newTestEnvironment(t)
+		env.files["saml-public.crt"] = env.idp.certificatePEM()
+
+		_, appErr := newServiceProvider(env.settings, env.getFile, env.messages)
+		require.NotNil(t, appErr)
+		assert.Equal(t, "ent.saml.configure.load_public_cert.app_error", appErr.Id)
+	})
+}
+
+func TestMetadata(t *testing.T) {
+	env := newTestEnvironment(t)
+	*env.settings.Encrypt = true
+
+	metadata, err := env.serviceProvider(t).metadata()
+	require.Nil(t, err)
+
+	doc := etree.NewDocument()
+	require.Nil(t, doc.ReadFromString(metadata))
+
+	entity := doc.Root()
+	assert.Equal(t, METADATA_NAMESPACE, entity.NamespaceURI())
+	assert.Equal(t, TEST_SP_URL, entity.SelectAttrValue("entityID", ""))
+
+	descriptor := childElement(entity, METADATA_NAMESPACE, "SPSSODescriptor")
+	require.NotNil(t, descriptor)
+	assert.Equal(t, "true", descriptor.SelectAttrValue("AuthnRequestsSigned", ""))
+	assert.Equal(t, "true", descriptor.SelectAttrValue("WantAssertionsSigned", ""))
+
+	var uses []string
+	for _, keyDescriptor := range childElements(descriptor, METADATA_NAMESPACE, "KeyDescriptor") {
+		uses = append(uses, keyDescriptor.SelectAttrValue("use", ""))
+	}
+	assert.Equal(t, []string{"signing", "encryption"}, uses)
+
+	service := childElement(descriptor, METADATA_NAMESPACE, "AssertionConsumerService")
+	require.NotNil(t, service)
+	assert.Equal(t, HTTP_POST_BINDING, service.SelectAttrValue("Binding", ""))
+	assert.Equal(t, TEST_SP_URL, service.SelectAttrValue("Location", ""))
+
+	*env.settings.AssertionConsumerServiceURL = ""
+	_, err = env.serviceProvider(t).metadata()
+	assert.NotNil(t, err)
+}
+
+func TestBuildRequest(t *testing.T) {
+	env := newTestEnvironment(t)
+	*env.settings.ScopingIDPProviderId = "provider"
+
+	request, err := env.serviceProvider(t).buildRequest("state")
+	require.Nil(t, err)
+	assert.Equal(t, "state", request.RelayState)
+	require.True(t, strings.HasPrefix(request.URL, TEST_IDP_URL+"?"))
+
+	query, err := url.ParseQuery(strings.TrimPrefix(request.URL, TEST_IDP_URL+"?"))
+	require.Nil(t, err)
+	assert.Equal(t, "state", query.Get("RelayState"))
+
+	deflated, err := base64.StdEncoding.DecodeString(query.Get("SAMLRequest"))
+	require.Nil(t, err)
+	xml, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	require.Nil(t, err)
+
+	plain, err := base64.StdEncoding.DecodeString(request.Base64AuthRequest)
+	require.Nil(t, err)
+	assert.Equal(t, plain, xml)
+
+	doc := etree.NewDocument()
+	require.Nil(t, doc.ReadFromBytes(xml))
+
+	authnRequest := doc.Root()
+	assert.Equal(t, "AuthnRequest", authnRequest.Tag)
+	assert.Equal(t, PROTOCOL_NAMESPACE, authnRequest.NamespaceURI())
+	assert.Equal(t, TEST_IDP_URL, authnRequest.SelectAttrValue("Destination", ""))
+	assert.Equal(t, TEST_SP_URL, authnRequest.SelectAttrValue("AssertionConsumerServiceURL", ""))
+	assert.Equal(t, TEST_SP_URL, childElement(authnRequest, ASSERTION_NAMESPACE, "Issuer").Text())
+	assert.Equal(t, "provider", authnRequest.FindElement("./Scoping/IDPList/IDPEntry").SelectAttrValue("ProviderID", ""))
+
+	t.Run("signature", func(t *testing.T) {
+		rawQuery := strings.TrimPrefix(request.URL, TEST_IDP_URL+"?")
+		signed := rawQuery[:strings.Index(rawQuery, "&Signature=")]
+
+		signature, err := base64.StdEncoding.DecodeString(query.Get("Signature"))
+		require.Nil(t, err)
+
+		digest := sha256.Sum256([]byte(signed))
+		assert.Nil(t, rsa.VerifyPKCS1v15(&env.sp.key.PublicKey, crypto.SHA256, digest[:], signature))
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		*env.settings.PrivateKeyFile = ""
+
+		request, err := env.serviceProvider(t).buildRequest("")
+		require.Nil(t, err)
+		assert.NotContains(t, request.URL, "Signature=")
+		assert.NotContains(t, request.URL, "RelayState=")
+	})
+
+	t.Run("idp url with query", func(t *testing.T) {
+		*env.settings.IdpUrl = TEST_IDP_URL + "?tenant=1"
+
+		request, err := env.serviceProvider(t).buildRequest("")
+		require.Nil(t, err)
+		assert.True(t, strings.HasPrefix(request.URL, TEST_IDP_URL+"?tenant=1&SAMLRequest="))
+	})
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package scheduledposts
+
+import (
+	"github.com/mattermost/mattermost-server/app"
+	"github.com/mattermost/mattermost-server/jobs"
+	tjobs "github.com/mattermost/mattermost-server/jobs/interfaces"
+	"github.com/mattermost/mattermost-server/model"
+)
+
+const (
+	JOB_DATA_KEY_POSTS_HANDLED = "posts_handled"
+)
+
+type ScheduledPostsJobInterfaceImpl struct {
+	App *app.App
+}
+
+func init() {
+	app.RegisterJobsScheduledPostsJobInterface(func(a *app.App) tjobs.ScheduledPostsJobInterface {
+		return &ScheduledPostsJobInterfaceImpl{a}
+	})
+}
+
+func (s *ScheduledPostsJobInterfaceImpl) dueItems() jobs.DueItems {
+	return jobs.DueItems{
+		Name:      "ScheduledPosts",
+		JobType:   model.JOB_TYPE_SCHEDULED_POSTS,
+		DataKey:   JOB_DATA_KEY_POSTS_HANDLED,
+		HasDue:    s.App.HasDueScheduledPosts,
+		HandleDue: s.App.PublishDueScheduledPosts,
+	}
+}
+
+func (s *ScheduledPostsJobInterfaceImpl) MakeScheduler() model.Scheduler {
+	return s.App.Srv.Jobs.MakeDueItemsScheduler(s.dueItems())
+}
+
+func (s *ScheduledPostsJobInterfaceImpl) MakeWorker() model.Worker {
+	return s.App.Srv.Jobs.MakeDueItemsWorker(s.dueItems())
+}
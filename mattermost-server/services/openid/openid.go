@@ -0,0 +1,231 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package openid
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DISCOVERY_PATH = "/.well-known/openid-configuration"
+
+	// Discovery documents and key sets are refreshed periodically so that changes made to the
+	// identity provider are eventually picked up without a restart.
+	DISCOVERY_CACHE_DURATION = 1 * time.Hour
+
+	// Key sets are refetched when a token is signed with an unknown key, but no more often than
+	// this to avoid hammering the identity provider with bad tokens.
+	KEYS_MIN_REFRESH_INTERVAL = 1 * time.Minute
+
+	MAX_RESPONSE_SIZE = 1024 * 1024
+)
+
+// DiscoveryDocument holds the parts of an identity provider's metadata that are needed to log
+// users in. See https://openid.net/specs/openid-connect-discovery-1_0.html.
+type DiscoveryDocument struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	UserinfoEndpoint              string   `json:"userinfo_endpoint"`
+	JwksUri                       string   `json:"jwks_uri"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported"`
+}
+
+func (d *DiscoveryDocument) isValid() error {
+	if d.Issuer == "" {
+		return fmt.Errorf("discovery document is missing the issuer")
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" {
+		return fmt.Errorf("discovery document is missing the authorization or token endpoint")
+	}
+
+	if d.JwksUri == "" {
+		return fmt.Errorf("discovery document is missing the key set URI")
+	}
+
+	return nil
+}
+
+// Provider is an OpenID Connect identity provider, configured from its discovery document.
+type Provider struct {
+	client            *http.Client
+	discoveryEndpoint string
+
+	mutex             sync.Mutex
+	document          *DiscoveryDocument
+	documentFetchedAt time.Time
+	keys              *keySet
+	keysFetchedAt     time.Time
+}
+
+// NewProvider creates a provider for the given discovery endpoint, which may either be the issuer
+// URL or the full URL of its discovery document.
+func NewProvider(client *http.Client, discoveryEndpoint string) *Provider {
+	return &Provider{
+		client:            client,
+		discoveryEndpoint: discoveryEndpoint,
+	}
+}
+
+func (p *Provider) DiscoveryEndpoint() string {
+	return p.discoveryEndpoint
+}
+
+func (p *Provider) discoveryDocumentURL() string {
+	if strings.Contains(p.discoveryEndpoint, "/.well-known/") {
+		return p.discoveryEndpoint
+	}
+
+	return strings.TrimRight(p.discoveryEndpoint, "/") + DISCOVERY_PATH
+}
+
+// Discover returns the provider's discovery document, fetching it if it isn't cached.
+func (p *Provider) Discover() (*DiscoveryDocument, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.discover()
+}
+
+func (p *Provider) discover() (*DiscoveryDocument, error) {
+	if p.document != nil && time.Since(p.documentFetchedAt) < DISCOVERY_CACHE_DURATION {
+		return p.document, nil
+	}
+
+	var document *DiscoveryDocument
+	if err := p.getJson(p.discoveryDocumentURL(), "", &document); err != nil {
+		return nil, err
+	}
+
+	if document == nil {
+		return nil, fmt.Errorf("discovery document is empty")
+	}
+
+	if err := document.isValid(); err != nil {
+		return nil, err
+	}
+
+	p.document = document
+	p.documentFetchedAt = time.Now()
+
+	return document, nil
+}
+
+// getKeys returns the provider's signing keys. If refresh is set, the key set is refetched unless
+// it was fetched very recently.
+func (p *Provider) getKeys(refresh bool) (*keySet, error) {
+	document, err := p.discover()
+	if err != nil {
+		return nil, err
+	}
+
+	age := time.Since(p.keysFetchedAt)
+	if p.keys != nil && age < DISCOVERY_CACHE_DURATION && (!refresh || age < KEYS_MIN_REFRESH_INTERVAL) {
+		return p.keys, nil
+	}
+
+	var raw *rawKeySet
+	if err := p.getJson(document.JwksUri, "", &raw); err != nil {
+		return nil, err
+	}
+
+	if raw == nil {
+		return nil, fmt.Errorf("key set is empty")
+	}
+
+	p.keys = raw.parse()
+	p.keysFetchedAt = time.Now()
+
+	return p.keys, nil
+}
+
+// UserInfo fetches the claims about the user that the given access token was issued for.
+func (p *Provider) UserInfo(accessToken string) (map[string]interface{}, error) {
+	document, err := p.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	if document.UserinfoEndpoint == "" {
+		return nil, nil
+	}
+
+	var claims map[string]interface{}
+	if err := p.getJson(document.UserinfoEndpoint, accessToken, &claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) getJson(url, accessToken string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %v from %v", resp.StatusCode, url)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, MAX_RESPONSE_SIZE)).Decode(v); err != nil {
+		return fmt.Errorf("invalid response from %v: %v", url, err)
+	}
+
+	return nil
+}
+
+// SupportsPKCE reports whether the provider advertises support for S256 code challenges. Providers
+// that don't advertise the methods they support are assumed to support it.
+func (d *DiscoveryDocument) SupportsPKCE() bool {
+	if len(d.CodeChallengeMethodsSupported) == 0 {
+		return true
+	}
+
+	for _, method := range d.CodeChallengeMethodsSupported {
+		if method == "S256" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewCodeVerifier generates a random PKCE code verifier. See RFC 7636.
+func NewCodeVerifier() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CodeChallenge returns the S256 code challenge sent along with the authorization request for the
+// given code verifier.
+func CodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
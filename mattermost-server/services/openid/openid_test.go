@@ -0,0 +1,263 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package openid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	server   *httptest.Server
+	keys     []rawKey
+	jwksHits int32
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	tp := &testProvider{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(DISCOVERY_PATH, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(&DiscoveryDocument{
+			Issuer:                tp.server.URL,
+			AuthorizationEndpoint: tp.server.URL + "/auth",
+			TokenEndpoint:         tp.server.URL + "/token",
+			UserinfoEndpoint:      tp.server.URL + "/userinfo",
+			JwksUri:               tp.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tp.jwksHits, 1)
+		json.NewEncoder(w).Encode(&rawKeySet{Keys: tp.keys})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"sub": "user1", "email": "user1@example.com"}`))
+	})
+
+	tp.server = httptest.NewServer(mux)
+	return tp
+}
+
+func (tp *testProvider) addRSAKey(t *testing.T, kid string) *rsa.PrivateKey {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	tp.keys = append(tp.keys, rawKey{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+	})
+
+	return privateKey
+}
+
+func (tp *testProvider) addECKey(t *testing.T, kid string) *ecdsa.PrivateKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	tp.keys = append(tp.keys, rawKey{
+		Kty: "EC",
+		Kid: kid,
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(privateKey.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(privateKey.Y.Bytes()),
+	})
+
+	return privateKey
+}
+
+func (tp *testProvider) claims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   tp.server.URL,
+		"aud":   "client",
+		"sub":   "user1",
+		"nonce": "nonce",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func signToken(t *testing.T, alg, kid string, privateKey crypto.Signer, claims map[string]interface{}) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.Nil(t, err)
+	payload, err := json.Marshal(claims)
+	require.Nil(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signed))
+
+	var signature []byte
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+		require.Nil(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		require.Nil(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestDiscover(t *testing.T) {
+	tp := newTestProvider(t)
+	defer tp.server.Close()
+
+	for _, endpoint := range []string{tp.server.URL, tp.server.URL + "/", tp.server.URL + DISCOVERY_PATH} {
+		document, err := NewProvider(http.DefaultClient, endpoint).Discover()
+		require.Nil(t, err, endpoint)
+		assert.Equal(t, tp.server.URL+"/token", document.TokenEndpoint)
+		assert.True(t, document.SupportsPKCE())
+	}
+
+	_, err := NewProvider(http.DefaultClient, tp.server.URL+"/missing").Discover()
+	assert.NotNil(t, err)
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	tp := newTestProvider(t)
+	defer tp.server.Close()
+
+	rsaKey := tp.addRSAKey(t, "rsa")
+	ecKey := tp.addECKey(t, "ec")
+	provider := NewProvider(http.DefaultClient, tp.server.URL)
+
+	t.Run("valid RSA token", func(t *testing.T) {
+		claims, err := provider.VerifyIdToken(signToken(t, "RS256", "rsa", rsaKey, tp.claims()), "client", "nonce")
+		require.Nil(t, err)
+		assert.Equal(t, "user1", claims["sub"])
+	})
+
+	t.Run("valid EC token", func(t *testing.T) {
+		_, err := provider.VerifyIdToken(signToken(t, "ES256", "ec", ecKey, tp.claims()), "client", "nonce")
+		require.Nil(t, err)
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.Nil(t, err)
+
+		_, err = provider.VerifyIdToken(signToken(t, "RS256", "rsa", otherKey, tp.claims()), "client", "nonce")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		parts := strings.Split(signToken(t, "RS256", "rsa", rsaKey, tp.claims()), ".")
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+
+		_, err := provider.VerifyIdToken(header+"."+parts[1]+".", "client", "nonce")
+		assert.NotNil(t, err)
+	})
+
+	for name, modify := range map[string]func(claims map[string]interface{}){
+		"wrong issuer":   func(claims map[string]interface{}) { claims["iss"] = "https://example.com" },
+		"wrong audience": func(claims map[string]interface{}) { claims["aud"] = "other" },
+		"wrong azp": func(claims map[string]interface{}) {
+			claims["aud"] = []string{"client", "other"}
+			claims["azp"] = "other"
+		},
+		"expired":             func(claims map[string]interface{}) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"issued in future":    func(claims map[string]interface{}) { claims["iat"] = time.Now().Add(time.Hour).Unix() },
+		"wrong nonce":         func(claims map[string]interface{}) { claims["nonce"] = "other" },
+		"missing nonce":       func(claims map[string]interface{}) { delete(claims, "nonce") },
+		"missing subject":     func(claims map[string]interface{}) { delete(claims, "sub") },
+		"missing expiry time": func(claims map[string]interface{}) { delete(claims, "exp") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			claims := tp.claims()
+			modify(claims)
+
+			_, err := provider.VerifyIdToken(signToken(t, "RS256", "rsa", rsaKey, claims), "client", "nonce")
+			assert.NotNil(t, err)
+		})
+	}
+
+	t.Run("no expected nonce", func(t *testing.T) {
+		_, err := provider.VerifyIdToken(signToken(t, "RS256", "rsa", rsaKey, tp.claims()), "client", "")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("multiple audiences", func(t *testing.T) {
+		claims := tp.claims()
+		claims["aud"] = []string{"client", "other"}
+		claims["azp"] = "client"
+
+		_, err := provider.VerifyIdToken(signToken(t, "RS256", "rsa", rsaKey, claims), "client", "nonce")
+		assert.Nil(t, err)
+	})
+}
+
+func TestVerifyIdTokenKeyRotation(t *testing.T) {
+	tp := newTestProvider(t)
+	defer tp.server.Close()
+
+	oldKey := tp.addRSAKey(t, "old")
+	provider := NewProvider(http.DefaultClient, tp.server.URL)
+
+	_, err := provider.VerifyIdToken(signToken(t, "RS256", "old", oldKey, tp.claims()), "client", "nonce")
+	require.Nil(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tp.jwksHits))
+
+	// A key that appeared since the key set was fetched is picked up by fetching it again, once the
+	// minimum refresh interval has passed.
+	newKey := tp.addRSAKey(t, "new")
+	provider.keysFetchedAt = provider.keysFetchedAt.Add(-KEYS_MIN_REFRESH_INTERVAL)
+
+	_, err = provider.VerifyIdToken(signToken(t, "RS256", "new", newKey, tp.claims()), "client", "nonce")
+	require.Nil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tp.jwksHits))
+
+	// Unknown keys don't cause the key set to be fetched again right away.
+	_, err = provider.VerifyIdToken(signToken(t, "RS256", "unknown", newKey, tp.claims()), "client", "nonce")
+	assert.NotNil(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tp.jwksHits))
+}
+
+func TestUserInfo(t *testing.T) {
+	tp := newTestProvider(t)
+	defer tp.server.Close()
+
+	provider := NewProvider(http.DefaultClient, tp.server.URL)
+
+	claims, err := provider.UserInfo("access-token")
+	require.Nil(t, err)
+	assert.Equal(t, "user1@example.com", claims["email"])
+
+	_, err = provider.UserInfo("wrong-token")
+	assert.NotNil(t, err)
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// From appendix B of RFC 7636.
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	verifier := NewCodeVerifier()
+	assert.Len(t, verifier, 43)
+	assert.NotEqual(t, verifier, NewCodeVerifier())
+}
@@ -0,0 +1,341 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package openid
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/mlog"
+)
+
+// ALLOWED_CLOCK_SKEW is how far the clocks of the identity provider and this server may drift apart
+// before tokens are rejected as expired or issued in the future.
+const ALLOWED_CLOCK_SKEW = 5 * time.Minute
+
+// rawKeySet is a JSON Web Key Set as served by the provider. See RFC 7517.
+type rawKeySet struct {
+	Keys []rawKey `json:"keys"`
+}
+
+type rawKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA keys
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// Elliptic curve keys
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type keySet struct {
+	keys []*key
+}
+
+type key struct {
+	id        string
+	publicKey crypto.PublicKey
+}
+
+// parse decodes the signing keys of the set, skipping the ones it doesn't support.
+func (r *rawKeySet) parse() *keySet {
+	set := &keySet{}
+
+	for _, raw := range r.Keys {
+		if raw.Use != "" && raw.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := raw.publicKey()
+		if err != nil {
+			mlog.Warn("Skipping unsupported OpenID Connect signing key", mlog.String("kid", raw.Kid), mlog.Err(err))
+			continue
+		}
+
+		set.keys = append(set.keys, &key{id: raw.Kid, publicKey: publicKey})
+	}
+
+	return set
+}
+
+func (r *rawKey) publicKey() (crypto.PublicKey, error) {
+	switch r.Kty {
+	case "RSA":
+		n, err := decodeBigInt(r.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(r.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch r.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", r.Crv)
+		}
+
+		x, err := decodeBigInt(r.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(r.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid elliptic curve point")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", r.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// find returns the keys that may have been used to sign a token with the given key id.
+func (s *keySet) find(kid string) []*key {
+	var keys []*key
+	for _, k := range s.keys {
+		if kid == "" || k.id == kid {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// VerifyIdToken checks the signature of an ID token against the provider's keys along with its
+// issuer, audience, expiry and nonce, and returns its claims.
+func (p *Provider) VerifyIdToken(rawToken, clientId, nonce string) (map[string]interface{}, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header tokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %v", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %v", err)
+	}
+
+	p.mutex.Lock()
+	document, err := p.discover()
+	if err != nil {
+		p.mutex.Unlock()
+		return nil, err
+	}
+
+	keys, err := p.getKeys(false)
+	if err == nil && len(keys.find(header.Kid)) == 0 {
+		// The provider may have rotated its keys since they were fetched.
+		keys, err = p.getKeys(true)
+	}
+	p.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+
+	verified := false
+	for _, k := range keys.find(header.Kid) {
+		if verifySignature(header.Alg, k.publicKey, signed, signature) == nil {
+			verified = true
+			break
+		}
+	}
+
+	if !verified {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %v", err)
+	}
+
+	if err := validateClaims(claims, document.Issuer, clientId, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func verifySignature(alg string, publicKey crypto.PublicKey, signed, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		if rsaKey, ok := publicKey.(*rsa.PublicKey); ok {
+			return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+		}
+
+	case "PS":
+		if rsaKey, ok := publicKey.(*rsa.PublicKey); ok {
+			return rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+
+	case "ES":
+		if ecKey, ok := publicKey.(*ecdsa.PublicKey); ok {
+			size := (ecKey.Curve.Params().BitSize + 7) / 8
+			if len(signature) != 2*size {
+				return fmt.Errorf("invalid signature length")
+			}
+
+			r := new(big.Int).SetBytes(signature[:size])
+			s := new(big.Int).SetBytes(signature[size:])
+			if ecdsa.Verify(ecKey, digest, r, s) {
+				return nil
+			}
+			return fmt.Errorf("invalid signature")
+		}
+
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	return fmt.Errorf("key doesn't match algorithm %q", alg)
+}
+
+func validateClaims(claims map[string]interface{}, issuer, clientId, nonce string, now time.Time) error {
+	if iss, _ := claims["iss"].(string); iss != issuer {
+		return fmt.Errorf("token was issued by %q instead of %q", iss, issuer)
+	}
+
+	var audiences []string
+	switch aud := claims["aud"].(type) {
+	case string:
+		audiences = []string{aud}
+	case []interface{}:
+		for _, a := range aud {
+			if s, ok := a.(string); ok {
+				audiences = append(audiences, s)
+			}
+		}
+	}
+
+	found := false
+	for _, aud := range audiences {
+		if aud == clientId {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("token wasn't issued for client %q", clientId)
+	}
+
+	if len(audiences) > 1 {
+		if azp, _ := claims["azp"].(string); azp != clientId {
+			return fmt.Errorf("token was authorized for %q instead of %q", azp, clientId)
+		}
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("token has no expiry")
+	}
+	if now.Add(-ALLOWED_CLOCK_SKEW).After(time.Unix(int64(exp), 0)) {
+		return fmt.Errorf("token has expired")
+	}
+
+	if iat, ok := claims["iat"].(float64); ok && now.Add(ALLOWED_CLOCK_SKEW).Before(time.Unix(int64(iat), 0)) {
+		return fmt.Errorf("token was issued in the future")
+	}
+
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return fmt.Errorf("token has no subject")
+	}
+
+	// The nonce binds the token to the authorization request, so it's always required.
+	if nonce == "" {
+		return fmt.Errorf("no nonce to verify the token against")
+	}
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return fmt.Errorf("token nonce doesn't match")
+	}
+
+	return nil
+}
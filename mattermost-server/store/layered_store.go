@@ -195,6 +195,26 @@ func (s *LayeredStore) LinkMetadata() LinkMetadataStore {
 	return s.DatabaseLayer.LinkMetadata()
 }
 
+func (s *LayeredStore) DataRetentionPolicy() DataRetentionPolicyStore {
+	return s.DatabaseLayer.DataRetentionPolicy()
+}
+
+func (s *LayeredStore) ScheduledPost() ScheduledPostStore {
+	return s.DatabaseLayer.ScheduledPost()
+}
+
+func (s *LayeredStore) Draft() DraftStore {
+	return s.DatabaseLayer.Draft()
+}
+
+func (s *LayeredStore) Thread() ThreadStore {
+	return s.DatabaseLayer.Thread()
+}
+
+func (s *LayeredStore) PostReminder() PostReminderStore {
+	return s.DatabaseLayer.PostReminder()
+}
+
 func (s *LayeredStore) MarkSystemRanUnitTests() {
 	s.DatabaseLayer.MarkSystemRanUnitTests()
 }
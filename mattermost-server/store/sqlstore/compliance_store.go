@@ -201,8 +201,11 @@ func (s SqlComplianceStore) ComplianceExport(job *model.Compliance) ([]*model.Co
 	return cposts, nil
 }
 
-func (s SqlComplianceStore) MessageExport(after int64, limit int) ([]*model.MessageExport, *model.AppError) {
-	props := map[string]interface{}{"StartTime": after, "Limit": limit}
+// MessageExport returns up to limit posts created after the given post, ordered by creation time and then by id
+// so that posts sharing a creation time are never skipped from one batch to the next. An empty afterPostId
+// includes every post created at exactly after.
+func (s SqlComplianceStore) MessageExport(after int64, afterPostId string, limit int) ([]*model.MessageExport, *model.AppError) {
+	props := map[string]interface{}{"StartTime": after, "StartPostId": afterPostId, "Limit": limit}
 	query :=
 		`SELECT
 			Posts.Id AS PostId,
@@ -232,9 +235,9 @@ func (s SqlComplianceStore) MessageExport(after int64, limit int) ([]*model.Mess
 			LEFT OUTER JOIN Teams ON Channels.TeamId = Teams.Id
 			LEFT OUTER JOIN Users ON Posts.UserId = Users.Id
 		WHERE
-			Posts.CreateAt > :StartTime AND
+			(Posts.CreateAt > :StartTime OR (Posts.CreateAt = :StartTime AND Posts.Id > :StartPostId)) AND
 			Posts.Type = ''
-		ORDER BY PostCreateAt
+		ORDER BY Posts.CreateAt, Posts.Id
 		LIMIT :Limit`
 
 	var cposts []*model.MessageExport
@@ -0,0 +1,239 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+const (
+	RETENTION_DAY_IN_MILLISECONDS = 24 * 60 * 60 * 1000
+)
+
+type SqlDataRetentionPolicyStore struct {
+	SqlStore
+}
+
+func NewSqlDataRetentionPolicyStore(sqlStore SqlStore) store.DataRetentionPolicyStore {
+	s := &SqlDataRetentionPolicyStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		teamTable := db.AddTableWithName(model.TeamRetentionPolicy{}, "TeamRetentionPolicies").SetKeys(false, "TeamId")
+		teamTable.ColMap("TeamId").SetMaxSize(26)
+
+		channelTable := db.AddTableWithName(model.ChannelRetentionPolicy{}, "ChannelRetentionPolicies").SetKeys(false, "ChannelId")
+		channelTable.ColMap("ChannelId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlDataRetentionPolicyStore) CreateIndexesIfNotExists() {
+}
+
+func (s SqlDataRetentionPolicyStore) SaveTeamPolicy(policy *model.TeamRetentionPolicy) (*model.TeamRetentionPolicy, *model.AppError) {
+	policy.PreSave()
+	if err := policy.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if err := s.upsert(policy); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.SaveTeamPolicy", "store.sql_data_retention_policy.save.app_error", nil, "team_id="+policy.TeamId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return policy, nil
+}
+
+func (s SqlDataRetentionPolicyStore) GetTeamPolicy(teamId string) (*model.TeamRetentionPolicy, *model.AppError) {
+	var policy model.TeamRetentionPolicy
+	if err := s.GetReplica().SelectOne(&policy, "SELECT * FROM TeamRetentionPolicies WHERE TeamId = :TeamId", map[string]interface{}{"TeamId": teamId}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetTeamPolicy", "store.sql_data_retention_policy.get.app_error", nil, "team_id="+teamId, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetTeamPolicy", "store.sql_data_retention_policy.get.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return &policy, nil
+}
+
+func (s SqlDataRetentionPolicyStore) GetTeamPolicies(offset, limit int) ([]*model.TeamRetentionPolicy, *model.AppError) {
+	var policies []*model.TeamRetentionPolicy
+	if _, err := s.GetReplica().Select(&policies, "SELECT * FROM TeamRetentionPolicies ORDER BY TeamId LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetTeamPolicies", "store.sql_data_retention_policy.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return policies, nil
+}
+
+func (s SqlDataRetentionPolicyStore) DeleteTeamPolicy(teamId string) *model.AppError {
+	if _, err := s.GetMaster().Exec("DELETE FROM TeamRetentionPolicies WHERE TeamId = :TeamId", map[string]interface{}{"TeamId": teamId}); err != nil {
+		return model.NewAppError("SqlDataRetentionPolicyStore.DeleteTeamPolicy", "store.sql_data_retention_policy.delete.app_error", nil, "team_id="+teamId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (s SqlDataRetentionPolicyStore) SaveChannelPolicy(policy *model.ChannelRetentionPolicy) (*model.ChannelRetentionPolicy, *model.AppError) {
+	policy.PreSave()
+	if err := policy.IsValid(); err != nil {
+		return nil, err
+	}
+
+	if err := s.upsert(policy); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.SaveChannelPolicy", "store.sql_data_retention_policy.save.app_error", nil, "channel_id="+policy.ChannelId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return policy, nil
+}
+
+func (s SqlDataRetentionPolicyStore) GetChannelPolicy(channelId string) (*model.ChannelRetentionPolicy, *model.AppError) {
+	var policy model.ChannelRetentionPolicy
+	if err := s.GetReplica().SelectOne(&policy, "SELECT * FROM ChannelRetentionPolicies WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetChannelPolicy", "store.sql_data_retention_policy.get.app_error", nil, "channel_id="+channelId, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetChannelPolicy", "store.sql_data_retention_policy.get.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return &policy, nil
+}
+
+func (s SqlDataRetentionPolicyStore) GetChannelPolicies(offset, limit int) ([]*model.ChannelRetentionPolicy, *model.AppError) {
+	var policies []*model.ChannelRetentionPolicy
+	if _, err := s.GetReplica().Select(&policies, "SELECT * FROM ChannelRetentionPolicies ORDER BY ChannelId LIMIT :Limit OFFSET :Offset", map[string]interface{}{"Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetChannelPolicies", "store.sql_data_retention_policy.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return policies, nil
+}
+
+func (s SqlDataRetentionPolicyStore) DeleteChannelPolicy(channelId string) *model.AppError {
+	if _, err := s.GetMaster().Exec("DELETE FROM ChannelRetentionPolicies WHERE ChannelId = :ChannelId", map[string]interface{}{"ChannelId": channelId}); err != nil {
+		return model.NewAppError("SqlDataRetentionPolicyStore.DeleteChannelPolicy", "store.sql_data_retention_policy.delete.app_error", nil, "channel_id="+channelId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// HasPolicies returns whether any team or channel overrides the global retention period.
+func (s SqlDataRetentionPolicyStore) HasPolicies() (bool, *model.AppError) {
+	count, err := s.GetReplica().SelectInt("SELECT (SELECT COUNT(*) FROM TeamRetentionPolicies) + (SELECT COUNT(*) FROM ChannelRetentionPolicies)")
+	if err != nil {
+		return false, model.NewAppError("SqlDataRetentionPolicyStore.HasPolicies", "store.sql_data_retention_policy.get_all.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return count > 0, nil
+}
+
+// PermanentDeletePostsBatch deletes up to limit posts that have outlived the retention period
+// that applies to their channel: the channel's own policy if it has one, otherwise the policy of
+// its team, otherwise the global retention period. A global retention period of 0 days disables
+// deletion for channels without an override. It returns the ids of the deleted posts.
+func (s SqlDataRetentionPolicyStore) PermanentDeletePostsBatch(now int64, globalRetentionDays int, limit int64) ([]string, *model.AppError) {
+	query := `
+		SELECT
+			Posts.Id
+		FROM
+			Posts
+			LEFT JOIN Channels ON Channels.Id = Posts.ChannelId
+			LEFT JOIN ChannelRetentionPolicies ON ChannelRetentionPolicies.ChannelId = Posts.ChannelId
+			LEFT JOIN TeamRetentionPolicies ON TeamRetentionPolicies.TeamId = Channels.TeamId
+		WHERE ` + retentionPolicyWhereClause("Posts.CreateAt", "MessageRetentionDays", globalRetentionDays) + `
+		LIMIT :Limit`
+
+	// The posts are selected from the master so that they're the same as the ones being deleted.
+	var postIds []string
+	if _, err := s.GetMaster().Select(&postIds, query, retentionPolicyQueryParams(now, globalRetentionDays, limit)); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.PermanentDeletePostsBatch", "store.sql_data_retention_policy.permanent_delete_posts_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(postIds) == 0 {
+		return postIds, nil
+	}
+
+	keys, params := MapStringsToQueryParams(postIds, "PostId")
+	if _, err := s.GetMaster().Exec("DELETE FROM Posts WHERE Id IN "+keys, params); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.PermanentDeletePostsBatch", "store.sql_data_retention_policy.permanent_delete_posts_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	if _, err := s.GetMaster().Exec("DELETE FROM Reactions WHERE PostId IN "+keys, params); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.PermanentDeletePostsBatch", "store.sql_data_retention_policy.permanent_delete_posts_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return postIds, nil
+}
+
+// GetFileInfosBatchForDeletion returns up to limit file infos that have outlived the retention
+// period that applies to them, resolved the same way as for posts. Files whose post no longer
+// exists fall back to the global retention period, so expired files must be deleted before the
+// posts of the same run. The rows are left in place so that the caller can remove the backing
+// files first.
+func (s SqlDataRetentionPolicyStore) GetFileInfosBatchForDeletion(now int64, globalRetentionDays int, limit int64) ([]*model.FileInfo, *model.AppError) {
+	query := `
+		SELECT
+			FileInfo.*
+		FROM
+			FileInfo
+			LEFT JOIN Posts ON Posts.Id = FileInfo.PostId
+			LEFT JOIN Channels ON Channels.Id = Posts.ChannelId
+			LEFT JOIN ChannelRetentionPolicies ON ChannelRetentionPolicies.ChannelId = Posts.ChannelId
+			LEFT JOIN TeamRetentionPolicies ON TeamRetentionPolicies.TeamId = Channels.TeamId
+		WHERE ` + retentionPolicyWhereClause("FileInfo.CreateAt", "FileRetentionDays", globalRetentionDays) + `
+		LIMIT :Limit`
+
+	var infos []*model.FileInfo
+	if _, err := s.GetMaster().Select(&infos, query, retentionPolicyQueryParams(now, globalRetentionDays, limit)); err != nil {
+		return nil, model.NewAppError("SqlDataRetentionPolicyStore.GetFileInfosBatchForDeletion", "store.sql_data_retention_policy.get_file_infos_batch.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return infos, nil
+}
+
+func (s SqlDataRetentionPolicyStore) upsert(policy interface{}) error {
+	rowsAffected, err := s.GetMaster().Update(policy)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		if err := s.GetMaster().Insert(policy); err != nil && !IsUniqueConstraintError(err, []string{"PRIMARY", "pkey"}) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func retentionPolicyWhereClause(createAtColumn string, daysColumn string, globalRetentionDays int) string {
+	clause := `
+			(ChannelRetentionPolicies.ChannelId IS NOT NULL
+				AND ChannelRetentionPolicies.` + daysColumn + ` > 0
+				AND ` + createAtColumn + ` < :Now - ChannelRetentionPolicies.` + daysColumn + ` * :DayInMillis)
+			OR (ChannelRetentionPolicies.ChannelId IS NULL
+				AND TeamRetentionPolicies.TeamId IS NOT NULL
+				AND TeamRetentionPolicies.` + daysColumn + ` > 0
+				AND ` + createAtColumn + ` < :Now - TeamRetentionPolicies.` + daysColumn + ` * :DayInMillis)`
+
+	if globalRetentionDays > 0 {
+		clause += `
+			OR (ChannelRetentionPolicies.ChannelId IS NULL
+				AND TeamRetentionPolicies.TeamId IS NULL
+				AND ` + createAtColumn + ` < :GlobalCutoff)`
+	}
+
+	return clause
+}
+
+func retentionPolicyQueryParams(now int64, globalRetentionDays int, limit int64) map[string]interface{} {
+	return map[string]interface{}{
+		"Now":          now,
+		"DayInMillis":  int64(RETENTION_DAY_IN_MILLISECONDS),
+		"GlobalCutoff": now - int64(globalRetentionDays)*RETENTION_DAY_IN_MILLISECONDS,
+		"Limit":        limit,
+	}
+}
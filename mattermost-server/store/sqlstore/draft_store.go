@@ -0,0 +1,155 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlDraftStore struct {
+	SqlStore
+}
+
+func NewSqlDraftStore(sqlStore SqlStore) store.DraftStore {
+	s := &SqlDraftStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.Draft{}, "Drafts").SetKeys(false, "UserId", "ChannelId", "RootId")
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.POST_MESSAGE_MAX_BYTES_V2)
+		table.ColMap("Props").SetMaxSize(model.POST_PROPS_MAX_RUNES)
+		table.ColMap("FileIds").SetMaxSize(model.POST_FILEIDS_MAX_RUNES)
+	}
+
+	return s
+}
+
+func (s SqlDraftStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_drafts_update_at", "Drafts", "UpdateAt")
+}
+
+// Save creates the draft of the user for its channel and thread, or replaces the existing one.
+func (s SqlDraftStore) Save(draft *model.Draft) (*model.Draft, *model.AppError) {
+	draft.PreSave()
+	if err := draft.IsValid(model.POST_MESSAGE_MAX_RUNES_V2); err != nil {
+		return nil, err
+	}
+
+	// The creation time of an existing draft is kept.
+	update := func() (int64, error) {
+		result, err := s.GetMaster().Exec(
+			`UPDATE
+				Drafts
+			SET
+				UpdateAt = :UpdateAt,
+				Message = :Message,
+				Props = :Props,
+				FileIds = :FileIds
+			WHERE
+				UserId = :UserId
+				AND ChannelId = :ChannelId
+				AND RootId = :RootId`,
+			map[string]interface{}{
+				"UpdateAt":  draft.UpdateAt,
+				"Message":   draft.Message,
+				"Props":     model.StringInterfaceToJson(draft.Props),
+				"FileIds":   model.ArrayToJson(draft.FileIds),
+				"UserId":    draft.UserId,
+				"ChannelId": draft.ChannelId,
+				"RootId":    draft.RootId,
+			})
+		if err != nil {
+			return 0, err
+		}
+		return result.RowsAffected()
+	}
+
+	inserted := false
+	insert := func() error {
+		if err := s.GetMaster().Insert(draft); err != nil {
+			return err
+		}
+		inserted = true
+		return nil
+	}
+
+	if err := upsert(update, insert, []string{"PRIMARY", "drafts_pkey"}); err != nil {
+		return nil, model.NewAppError("SqlDraftStore.Save", "store.sql_draft.save.app_error", nil, "channel_id="+draft.ChannelId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if inserted {
+		return draft, nil
+	}
+
+	return s.Get(draft.UserId, draft.ChannelId, draft.RootId)
+}
+
+func (s SqlDraftStore) Get(userId, channelId, rootId string) (*model.Draft, *model.AppError) {
+	var draft model.Draft
+	if err := s.GetMaster().SelectOne(&draft,
+		`SELECT
+			*
+		FROM
+			Drafts
+		WHERE
+			UserId = :UserId
+			AND ChannelId = :ChannelId
+			AND RootId = :RootId`, map[string]interface{}{"UserId": userId, "ChannelId": channelId, "RootId": rootId}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlDraftStore.Get", "store.sql_draft.get.app_error", nil, "channel_id="+channelId+", root_id="+rootId, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlDraftStore.Get", "store.sql_draft.get.app_error", nil, "channel_id="+channelId+", root_id="+rootId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return &draft, nil
+}
+
+// GetForUser returns the drafts of a user in the channels of a team, including direct and group
+// messages, most recently updated first. The drafts in archived channels are left out.
+func (s SqlDraftStore) GetForUser(userId, teamId string) ([]*model.Draft, *model.AppError) {
+	var drafts []*model.Draft
+	if _, err := s.GetReplica().Select(&drafts,
+		`SELECT
+			Drafts.*
+		FROM
+			Drafts
+			INNER JOIN Channels ON Channels.Id = Drafts.ChannelId
+		WHERE
+			Drafts.UserId = :UserId
+			AND (Channels.TeamId = :TeamId OR Channels.TeamId = '')
+			AND Channels.DeleteAt = 0
+		ORDER BY
+			Drafts.UpdateAt DESC`, map[string]interface{}{"UserId": userId, "TeamId": teamId}); err != nil {
+		return nil, model.NewAppError("SqlDraftStore.GetForUser", "store.sql_draft.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return drafts, nil
+}
+
+// Delete removes the draft of the user for a channel or thread, and reports whether there was one.
+func (s SqlDraftStore) Delete(userId, channelId, rootId string) (bool, *model.AppError) {
+	result, err := s.GetMaster().Exec(
+		`DELETE FROM
+			Drafts
+		WHERE
+			UserId = :UserId
+			AND ChannelId = :ChannelId
+			AND RootId = :RootId`, map[string]interface{}{"UserId": userId, "ChannelId": channelId, "RootId": rootId})
+	if err != nil {
+		return false, model.NewAppError("SqlDraftStore.Delete", "store.sql_draft.delete.app_error", nil, "channel_id="+channelId+", root_id="+rootId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, model.NewAppError("SqlDraftStore.Delete", "store.sql_draft.delete.app_error", nil, "channel_id="+channelId+", root_id="+rootId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return rowsAffected > 0, nil
+}
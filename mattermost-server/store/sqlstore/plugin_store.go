@@ -7,6 +7,11 @@ import (
 	"database/sql"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattermost/gorp"
 
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
@@ -14,6 +19,9 @@ import (
 
 const (
 	DEFAULT_PLUGIN_KEY_FETCH_LIMIT = 10
+
+	PLUGIN_KEY_VALUE_CLEANUP_BATCH_SIZE         = 1000
+	PLUGIN_KEY_VALUE_CLEANUP_DELAY_MILLISECONDS = 100
 )
 
 type SqlPluginStore struct {
@@ -34,6 +42,7 @@ func NewSqlPluginStore(sqlStore SqlStore) store.PluginStore {
 }
 
 func (ps SqlPluginStore) CreateIndexesIfNotExists() {
+	ps.CreateIndexIfNotExists("idx_pluginkeyvaluestore_expire_at", "PluginKeyValueStore", "ExpireAt")
 }
 
 func (ps SqlPluginStore) SaveOrUpdate(kv *model.PluginKeyValue) store.StoreChannel {
@@ -116,6 +125,94 @@ func (ps SqlPluginStore) CompareAndSet(kv *model.PluginKeyValue, oldValue []byte
 	return true, nil
 }
 
+// SaveOrUpdateMany saves the given key-value pairs in a single transaction, so that either all of
+// them are saved or none is. The keys are written in order, so that concurrent batches touching the
+// same keys can't deadlock.
+func (ps SqlPluginStore) SaveOrUpdateMany(kvs []*model.PluginKeyValue) *model.AppError {
+	for _, kv := range kvs {
+		if err := kv.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	sorted := make([]*model.PluginKeyValue, len(kvs))
+	copy(sorted, kvs)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].PluginId != sorted[j].PluginId {
+			return sorted[i].PluginId < sorted[j].PluginId
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	transaction, err := ps.GetMaster().Begin()
+	if err != nil {
+		return model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save_many.open_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+	defer finalizeTransaction(transaction)
+
+	for _, kv := range sorted {
+		if err := ps.saveOrUpdateT(transaction, kv); err != nil {
+			return model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", kv.PluginId, kv.Key, err.Error()), http.StatusInternalServerError)
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return model.NewAppError("SqlPluginStore.SaveOrUpdateMany", "store.sql_plugin_store.save_many.commit_transaction.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+func (ps SqlPluginStore) saveOrUpdateT(transaction *gorp.Transaction, kv *model.PluginKeyValue) error {
+	if ps.DriverName() == model.DATABASE_DRIVER_MYSQL {
+		_, err := transaction.Exec("INSERT INTO PluginKeyValueStore (PluginId, PKey, PValue, ExpireAt) VALUES(:PluginId, :Key, :Value, :ExpireAt) ON DUPLICATE KEY UPDATE PValue = :Value, ExpireAt = :ExpireAt", map[string]interface{}{"PluginId": kv.PluginId, "Key": kv.Key, "Value": kv.Value, "ExpireAt": kv.ExpireAt})
+		return err
+	}
+
+	// Within a transaction, PostgreSQL can't recover from the unique constraint violation of a
+	// concurrent insert, so the whole batch fails instead.
+	rowsAffected, err := transaction.Update(kv)
+	if err != nil {
+		return err
+	} else if rowsAffected == 0 {
+		return transaction.Insert(kv)
+	}
+
+	return nil
+}
+
+// CompareAndDelete deletes the key-value pair only if it still has the given value.
+func (ps SqlPluginStore) CompareAndDelete(kv *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	if err := kv.IsValid(); err != nil {
+		return false, err
+	}
+
+	if oldValue == nil {
+		// There can't be anything to delete if nothing is expected
+		return false, nil
+	}
+
+	deleteResult, err := ps.GetMaster().Exec(
+		`DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey = :Key AND PValue = :Old`,
+		map[string]interface{}{
+			"PluginId": kv.PluginId,
+			"Key":      kv.Key,
+			"Old":      oldValue,
+		},
+	)
+	if err != nil {
+		return false, model.NewAppError("SqlPluginStore.CompareAndDelete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", kv.PluginId, kv.Key, err.Error()), http.StatusInternalServerError)
+	}
+
+	if rowsAffected, err := deleteResult.RowsAffected(); err != nil {
+		return false, model.NewAppError("SqlPluginStore.CompareAndDelete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("plugin_id=%v, key=%v, err=%v", kv.PluginId, kv.Key, err.Error()), http.StatusInternalServerError)
+	} else if rowsAffected == 0 {
+		return false, nil
+	}
+
+	return true, nil
+}
+
 func (ps SqlPluginStore) Get(pluginId, key string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		var kv *model.PluginKeyValue
@@ -142,6 +239,28 @@ func (ps SqlPluginStore) Delete(pluginId, key string) store.StoreChannel {
 	})
 }
 
+// DeleteMany deletes the given keys of a plugin with a single statement, so that either all of
+// them are deleted or none is.
+func (ps SqlPluginStore) DeleteMany(pluginId string, keys []string) *model.AppError {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	params := map[string]interface{}{"PluginId": pluginId}
+	placeholders := make([]string, len(keys))
+	for i, key := range keys {
+		param := fmt.Sprintf("Key%d", i)
+		params[param] = key
+		placeholders[i] = ":" + param
+	}
+
+	if _, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey IN ("+strings.Join(placeholders, ", ")+")", params); err != nil {
+		return model.NewAppError("SqlPluginStore.DeleteMany", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("plugin_id=%v, err=%v", pluginId, err.Error()), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
 func (ps SqlPluginStore) DeleteAllForPlugin(pluginId string) store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
 		if _, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE PluginId = :PluginId", map[string]interface{}{"PluginId": pluginId}); err != nil {
@@ -152,14 +271,37 @@ func (ps SqlPluginStore) DeleteAllForPlugin(pluginId string) store.StoreChannel
 	})
 }
 
+// DeleteAllExpired purges the expired key-value pairs in batches, so that a large number of them
+// doesn't lock the table for long.
 func (ps SqlPluginStore) DeleteAllExpired() store.StoreChannel {
 	return store.Do(func(result *store.StoreResult) {
-		currentTime := model.GetMillis()
-		if _, err := ps.GetMaster().Exec("DELETE FROM PluginKeyValueStore WHERE ExpireAt != 0 AND ExpireAt < :CurrentTime", map[string]interface{}{"CurrentTime": currentTime}); err != nil {
-			result.Err = model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("current_time=%v, err=%v", currentTime, err.Error()), http.StatusInternalServerError)
+		var query string
+		if ps.DriverName() == model.DATABASE_DRIVER_POSTGRES {
+			query = "DELETE FROM PluginKeyValueStore WHERE (PluginId, PKey) IN (SELECT PluginId, PKey FROM PluginKeyValueStore WHERE ExpireAt != 0 AND ExpireAt < :CurrentTime LIMIT :Limit)"
 		} else {
-			result.Data = true
+			query = "DELETE FROM PluginKeyValueStore WHERE ExpireAt != 0 AND ExpireAt < :CurrentTime LIMIT :Limit"
 		}
+
+		currentTime := model.GetMillis()
+		for {
+			sqlResult, err := ps.GetMaster().Exec(query, map[string]interface{}{"CurrentTime": currentTime, "Limit": PLUGIN_KEY_VALUE_CLEANUP_BATCH_SIZE})
+			if err != nil {
+				result.Err = model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("current_time=%v, err=%v", currentTime, err.Error()), http.StatusInternalServerError)
+				return
+			}
+
+			rowsAffected, err := sqlResult.RowsAffected()
+			if err != nil {
+				result.Err = model.NewAppError("SqlPluginStore.Delete", "store.sql_plugin_store.delete.app_error", nil, fmt.Sprintf("current_time=%v, err=%v", currentTime, err.Error()), http.StatusInternalServerError)
+				return
+			} else if rowsAffected < PLUGIN_KEY_VALUE_CLEANUP_BATCH_SIZE {
+				break
+			}
+
+			time.Sleep(PLUGIN_KEY_VALUE_CLEANUP_DELAY_MILLISECONDS * time.Millisecond)
+		}
+
+		result.Data = true
 	})
 }
 
@@ -182,3 +324,24 @@ func (ps SqlPluginStore) List(pluginId string, offset int, limit int) store.Stor
 		}
 	})
 }
+
+// ListWithPrefix lists the keys of a plugin starting with the given prefix, skipping the expired
+// ones.
+func (ps SqlPluginStore) ListWithPrefix(pluginId, prefix string, offset, limit int) ([]string, *model.AppError) {
+	if limit <= 0 {
+		limit = DEFAULT_PLUGIN_KEY_FETCH_LIMIT
+	}
+
+	if offset <= 0 {
+		offset = 0
+	}
+
+	escaper := strings.NewReplacer("*", "**", "%", "*%", "_", "*_")
+
+	var keys []string
+	if _, err := ps.GetReplica().Select(&keys, "SELECT PKey FROM PluginKeyValueStore WHERE PluginId = :PluginId AND PKey LIKE :Prefix ESCAPE '*' AND (ExpireAt = 0 OR ExpireAt > :CurrentTime) ORDER BY PKey LIMIT :Limit OFFSET :Offset", map[string]interface{}{"PluginId": pluginId, "Prefix": escaper.Replace(prefix) + "%", "CurrentTime": model.GetMillis(), "Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlPluginStore.ListWithPrefix", "store.sql_plugin_store.list.app_error", nil, fmt.Sprintf("plugin_id=%v, prefix=%v, err=%v", pluginId, prefix, err.Error()), http.StatusInternalServerError)
+	}
+
+	return keys, nil
+}
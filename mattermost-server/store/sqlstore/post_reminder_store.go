@@ -0,0 +1,105 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlPostReminderStore struct {
+	SqlStore
+}
+
+func NewSqlPostReminderStore(sqlStore SqlStore) store.PostReminderStore {
+	s := &SqlPostReminderStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.PostReminder{}, "PostReminders").SetKeys(false, "PostId", "UserId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlPostReminderStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_postreminders_user_id", "PostReminders", "UserId")
+	s.CreateIndexIfNotExists("idx_postreminders_target_time", "PostReminders", "TargetTime")
+}
+
+// Save sets the reminder of a user about a post, replacing the existing one.
+func (s SqlPostReminderStore) Save(reminder *model.PostReminder) (*model.PostReminder, *model.AppError) {
+	reminder.PreSave()
+	if err := reminder.IsValid(); err != nil {
+		return nil, err
+	}
+
+	update := func() (int64, error) { return s.GetMaster().Update(reminder) }
+	insert := func() error { return s.GetMaster().Insert(reminder) }
+	if err := upsert(update, insert, []string{"PRIMARY", "postreminders_pkey"}); err != nil {
+		return nil, model.NewAppError("SqlPostReminderStore.Save", "store.sql_post_reminder.save.app_error", nil, "post_id="+reminder.PostId+", user_id="+reminder.UserId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return reminder, nil
+}
+
+func (s SqlPostReminderStore) Get(postId, userId string) (*model.PostReminder, *model.AppError) {
+	var reminder model.PostReminder
+	if err := s.GetMaster().SelectOne(&reminder, "SELECT * FROM PostReminders WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlPostReminderStore.Get", "store.sql_post_reminder.get.app_error", nil, "post_id="+postId+", user_id="+userId, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlPostReminderStore.Get", "store.sql_post_reminder.get.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return &reminder, nil
+}
+
+// Delete removes the reminder of a user about a post and reports whether it still existed, so that
+// only one of the callers racing to send or cancel it goes ahead.
+func (s SqlPostReminderStore) Delete(postId, userId string) (bool, *model.AppError) {
+	result, err := s.GetMaster().Exec("DELETE FROM PostReminders WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId})
+	if err != nil {
+		return false, model.NewAppError("SqlPostReminderStore.Delete", "store.sql_post_reminder.delete.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, model.NewAppError("SqlPostReminderStore.Delete", "store.sql_post_reminder.delete.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return count > 0, nil
+}
+
+func (s SqlPostReminderStore) GetForUser(userId string) ([]*model.PostReminder, *model.AppError) {
+	var reminders []*model.PostReminder
+	if _, err := s.GetReplica().Select(&reminders, "SELECT * FROM PostReminders WHERE UserId = :UserId ORDER BY TargetTime, PostId", map[string]interface{}{"UserId": userId}); err != nil {
+		return nil, model.NewAppError("SqlPostReminderStore.GetForUser", "store.sql_post_reminder.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return reminders, nil
+}
+
+// GetDue returns the reminders due at the given time, oldest first.
+func (s SqlPostReminderStore) GetDue(now int64, limit int) ([]*model.PostReminder, *model.AppError) {
+	var reminders []*model.PostReminder
+	if _, err := s.GetMaster().Select(&reminders,
+		`SELECT
+			*
+		FROM
+			PostReminders
+		WHERE
+			TargetTime <= :Now
+		ORDER BY
+			TargetTime, PostId, UserId
+		LIMIT :Limit`, map[string]interface{}{"Now": now, "Limit": limit}); err != nil {
+		return nil, model.NewAppError("SqlPostReminderStore.GetDue", "store.sql_post_reminder.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return reminders, nil
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlScheduledPostStore struct {
+	SqlStore
+}
+
+func NewSqlScheduledPostStore(sqlStore SqlStore) store.ScheduledPostStore {
+	s := &SqlScheduledPostStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ScheduledPost{}, "ScheduledPosts").SetKeys(false, "Id")
+		table.ColMap("Id").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+		table.ColMap("ChannelId").SetMaxSize(26)
+		table.ColMap("RootId").SetMaxSize(26)
+		table.ColMap("Message").SetMaxSize(model.POST_MESSAGE_MAX_BYTES_V2)
+		table.ColMap("Props").SetMaxSize(model.POST_PROPS_MAX_RUNES)
+		table.ColMap("FileIds").SetMaxSize(model.POST_FILEIDS_MAX_RUNES)
+		table.ColMap("ErrorCode").SetMaxSize(model.SCHEDULED_POST_ERROR_CODE_MAX_LENGTH)
+	}
+
+	return s
+}
+
+func (s SqlScheduledPostStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_scheduledposts_user_id", "ScheduledPosts", "UserId")
+	s.CreateIndexIfNotExists("idx_scheduledposts_scheduled_at", "ScheduledPosts", "ScheduledAt")
+}
+
+func (s SqlScheduledPostStore) Save(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	scheduledPost.PreSave()
+	if err := scheduledPost.IsValid(model.POST_MESSAGE_MAX_RUNES_V2); err != nil {
+		return nil, err
+	}
+
+	if err := s.GetMaster().Insert(scheduledPost); err != nil {
+		return nil, model.NewAppError("SqlScheduledPostStore.Save", "store.sql_scheduled_post.save.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return scheduledPost, nil
+}
+
+func (s SqlScheduledPostStore) Get(id string) (*model.ScheduledPost, *model.AppError) {
+	var scheduledPost model.ScheduledPost
+	if err := s.GetMaster().SelectOne(&scheduledPost, "SELECT * FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlScheduledPostStore.Get", "store.sql_scheduled_post.get.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return &scheduledPost, nil
+}
+
+func (s SqlScheduledPostStore) Update(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError) {
+	scheduledPost.PreUpdate()
+	if err := scheduledPost.IsValid(model.POST_MESSAGE_MAX_RUNES_V2); err != nil {
+		return nil, err
+	}
+
+	count, err := s.GetMaster().Update(scheduledPost)
+	if err != nil {
+		return nil, model.NewAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.update.app_error", nil, "id="+scheduledPost.Id+", "+err.Error(), http.StatusInternalServerError)
+	}
+	if count == 0 {
+		return nil, model.NewAppError("SqlScheduledPostStore.Update", "store.sql_scheduled_post.get.app_error", nil, "id="+scheduledPost.Id, http.StatusNotFound)
+	}
+
+	return scheduledPost, nil
+}
+
+// Delete removes a scheduled post and reports whether it still existed, so that only one of the
+// callers racing to publish or delete it goes ahead.
+func (s SqlScheduledPostStore) Delete(id string) (bool, *model.AppError) {
+	result, err := s.GetMaster().Exec("DELETE FROM ScheduledPosts WHERE Id = :Id", map[string]interface{}{"Id": id})
+	if err != nil {
+		return false, model.NewAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, model.NewAppError("SqlScheduledPostStore.Delete", "store.sql_scheduled_post.delete.app_error", nil, "id="+id+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return count > 0, nil
+}
+
+func (s SqlScheduledPostStore) GetForUser(userId string) ([]*model.ScheduledPost, *model.AppError) {
+	var scheduledPosts []*model.ScheduledPost
+	if _, err := s.GetReplica().Select(&scheduledPosts, "SELECT * FROM ScheduledPosts WHERE UserId = :UserId ORDER BY ScheduledAt, Id", map[string]interface{}{"UserId": userId}); err != nil {
+		return nil, model.NewAppError("SqlScheduledPostStore.GetForUser", "store.sql_scheduled_post.get_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return scheduledPosts, nil
+}
+
+// GetDue returns the scheduled posts due at the given time, oldest first. The posts that failed to
+// be published are left out until their author edits them.
+func (s SqlScheduledPostStore) GetDue(now int64, limit int) ([]*model.ScheduledPost, *model.AppError) {
+	var scheduledPosts []*model.ScheduledPost
+	if _, err := s.GetMaster().Select(&scheduledPosts,
+		`SELECT
+			*
+		FROM
+			ScheduledPosts
+		WHERE
+			ScheduledAt <= :Now
+			AND ErrorCode = ''
+		ORDER BY
+			ScheduledAt, Id
+		LIMIT :Limit`, map[string]interface{}{"Now": now, "Limit": limit}); err != nil {
+		return nil, model.NewAppError("SqlScheduledPostStore.GetDue", "store.sql_scheduled_post.get_due.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	return scheduledPosts, nil
+}
@@ -98,5 +98,10 @@ type SqlStore interface {
 	TermsOfService() store.TermsOfServiceStore
 	UserTermsOfService() store.UserTermsOfServiceStore
 	LinkMetadata() store.LinkMetadataStore
+	DataRetentionPolicy() store.DataRetentionPolicyStore
+	ScheduledPost() store.ScheduledPostStore
+	Draft() store.DraftStore
+	Thread() store.ThreadStore
+	PostReminder() store.PostReminderStore
 	getQueryBuilder() sq.StatementBuilderType
 }
@@ -99,6 +99,11 @@ type SqlSupplierOldStores struct {
 	group                store.GroupStore
 	UserTermsOfService   store.UserTermsOfServiceStore
 	linkMetadata         store.LinkMetadataStore
+	dataRetentionPolicy  store.DataRetentionPolicyStore
+	scheduledPost        store.ScheduledPostStore
+	draft                store.DraftStore
+	thread               store.ThreadStore
+	postReminder         store.PostReminderStore
 }
 
 type SqlSupplier struct {
@@ -151,6 +156,11 @@ func NewSqlSupplier(settings model.SqlSettings, metrics einterfaces.MetricsInter
 	supplier.oldStores.TermsOfService = NewSqlTermsOfServiceStore(supplier, metrics)
 	supplier.oldStores.UserTermsOfService = NewSqlUserTermsOfServiceStore(supplier)
 	supplier.oldStores.linkMetadata = NewSqlLinkMetadataStore(supplier)
+	supplier.oldStores.dataRetentionPolicy = NewSqlDataRetentionPolicyStore(supplier)
+	supplier.oldStores.scheduledPost = NewSqlScheduledPostStore(supplier)
+	supplier.oldStores.draft = NewSqlDraftStore(supplier)
+	supplier.oldStores.thread = NewSqlThreadStore(supplier)
+	supplier.oldStores.postReminder = NewSqlPostReminderStore(supplier)
 
 	initSqlSupplierReactions(supplier)
 	initSqlSupplierRoles(supplier)
@@ -196,6 +206,11 @@ func NewSqlSupplier(settings model.SqlSettings, metrics einterfaces.MetricsInter
 	supplier.oldStores.TermsOfService.(SqlTermsOfServiceStore).CreateIndexesIfNotExists()
 	supplier.oldStores.UserTermsOfService.(SqlUserTermsOfServiceStore).CreateIndexesIfNotExists()
 	supplier.oldStores.linkMetadata.(*SqlLinkMetadataStore).CreateIndexesIfNotExists()
+	supplier.oldStores.dataRetentionPolicy.(*SqlDataRetentionPolicyStore).CreateIndexesIfNotExists()
+	supplier.oldStores.scheduledPost.(*SqlScheduledPostStore).CreateIndexesIfNotExists()
+	supplier.oldStores.draft.(*SqlDraftStore).CreateIndexesIfNotExists()
+	supplier.oldStores.thread.(*SqlThreadStore).CreateIndexesIfNotExists()
+	supplier.oldStores.postReminder.(*SqlPostReminderStore).CreateIndexesIfNotExists()
 
 	supplier.CreateIndexesIfNotExistsGroups()
 
@@ -908,6 +923,24 @@ func IsUniqueConstraintError(err error, indexName []string) bool {
 	return unique && field
 }
 
+// DBStats returns the connection pool statistics of the master and of every replica, keyed by the
+// same names used to identify the connections in the logs.
+func (ss *SqlSupplier) DBStats() map[string]dbsql.DBStats {
+	stats := map[string]dbsql.DBStats{
+		"master": ss.master.Db.Stats(),
+	}
+
+	for i, replica := range ss.replicas {
+		stats[fmt.Sprintf("replica-%v", i)] = replica.Db.Stats()
+	}
+
+	for i, replica := range ss.searchReplicas {
+		stats[fmt.Sprintf("search-replica-%v", i)] = replica.Db.Stats()
+	}
+
+	return stats
+}
+
 func (ss *SqlSupplier) GetAllConns() []*gorp.DbMap {
 	all := make([]*gorp.DbMap, len(ss.replicas)+1)
 	copy(all, ss.replicas)
@@ -1055,6 +1088,26 @@ func (ss *SqlSupplier) LinkMetadata() store.LinkMetadataStore {
 	return ss.oldStores.linkMetadata
 }
 
+func (ss *SqlSupplier) DataRetentionPolicy() store.DataRetentionPolicyStore {
+	return ss.oldStores.dataRetentionPolicy
+}
+
+func (ss *SqlSupplier) ScheduledPost() store.ScheduledPostStore {
+	return ss.oldStores.scheduledPost
+}
+
+func (ss *SqlSupplier) Draft() store.DraftStore {
+	return ss.oldStores.draft
+}
+
+func (ss *SqlSupplier) Thread() store.ThreadStore {
+	return ss.oldStores.thread
+}
+
+func (ss *SqlSupplier) PostReminder() store.PostReminderStore {
+	return ss.oldStores.postReminder
+}
+
 func (ss *SqlSupplier) DropAllTables() {
 	ss.master.TruncateTables()
 }
@@ -0,0 +1,167 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package sqlstore
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+)
+
+type SqlThreadStore struct {
+	SqlStore
+}
+
+type threadReplyCount struct {
+	RootId     string
+	ReplyCount int64
+}
+
+func NewSqlThreadStore(sqlStore SqlStore) store.ThreadStore {
+	s := &SqlThreadStore{sqlStore}
+
+	for _, db := range sqlStore.GetAllConns() {
+		table := db.AddTableWithName(model.ThreadMembership{}, "ThreadMemberships").SetKeys(false, "PostId", "UserId")
+		table.ColMap("PostId").SetMaxSize(26)
+		table.ColMap("UserId").SetMaxSize(26)
+	}
+
+	return s
+}
+
+func (s SqlThreadStore) CreateIndexesIfNotExists() {
+	s.CreateIndexIfNotExists("idx_threadmemberships_user_id", "ThreadMemberships", "UserId")
+	s.CreateIndexIfNotExists("idx_threadmemberships_last_updated", "ThreadMemberships", "LastUpdated")
+}
+
+// SaveMembership creates the membership of a user to a thread, or replaces the existing one.
+func (s SqlThreadStore) SaveMembership(membership *model.ThreadMembership) (*model.ThreadMembership, *model.AppError) {
+	if err := membership.IsValid(); err != nil {
+		return nil, err
+	}
+
+	update := func() (int64, error) { return s.GetMaster().Update(membership) }
+	insert := func() error { return s.GetMaster().Insert(membership) }
+	if err := upsert(update, insert, []string{"PRIMARY", "threadmemberships_pkey"}); err != nil {
+		return nil, model.NewAppError("SqlThreadStore.SaveMembership", "store.sql_thread.save_membership.app_error", nil, "post_id="+membership.PostId+", user_id="+membership.UserId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return membership, nil
+}
+
+func (s SqlThreadStore) GetMembership(postId, userId string) (*model.ThreadMembership, *model.AppError) {
+	var membership model.ThreadMembership
+	if err := s.GetMaster().SelectOne(&membership, "SELECT * FROM ThreadMemberships WHERE PostId = :PostId AND UserId = :UserId", map[string]interface{}{"PostId": postId, "UserId": userId}); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.NewAppError("SqlThreadStore.GetMembership", "store.sql_thread.get_membership.app_error", nil, "post_id="+postId+", user_id="+userId, http.StatusNotFound)
+		}
+		return nil, model.NewAppError("SqlThreadStore.GetMembership", "store.sql_thread.get_membership.app_error", nil, "post_id="+postId+", user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return &membership, nil
+}
+
+func (s SqlThreadStore) GetMembershipsForThread(postId string) ([]*model.ThreadMembership, *model.AppError) {
+	var memberships []*model.ThreadMembership
+	if _, err := s.GetMaster().Select(&memberships, "SELECT * FROM ThreadMemberships WHERE PostId = :PostId", map[string]interface{}{"PostId": postId}); err != nil {
+		return nil, model.NewAppError("SqlThreadStore.GetMembershipsForThread", "store.sql_thread.get_memberships_for_thread.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return memberships, nil
+}
+
+// GetFollowedForUser returns a page of the threads a user follows in the channels of a team,
+// including direct and group channels, most recently updated first. The threads whose root post or
+// channel was deleted are left out.
+func (s SqlThreadStore) GetFollowedForUser(userId, teamId string, offset, limit int) ([]*model.ThreadMembership, *model.AppError) {
+	var memberships []*model.ThreadMembership
+	if _, err := s.GetReplica().Select(&memberships,
+		`SELECT
+			ThreadMemberships.*
+		FROM
+			ThreadMemberships
+			INNER JOIN Posts ON Posts.Id = ThreadMemberships.PostId
+			INNER JOIN Channels ON Channels.Id = Posts.ChannelId
+			INNER JOIN ChannelMembers ON ChannelMembers.ChannelId = Posts.ChannelId
+				AND ChannelMembers.UserId = ThreadMemberships.UserId
+		WHERE
+			ThreadMemberships.UserId = :UserId
+			AND ThreadMemberships.Following = :Following
+			AND Posts.DeleteAt = 0
+			AND (Channels.TeamId = :TeamId OR Channels.TeamId = '')
+			AND Channels.DeleteAt = 0
+		ORDER BY
+			ThreadMemberships.LastUpdated DESC, ThreadMemberships.PostId
+		LIMIT :Limit
+		OFFSET :Offset`, map[string]interface{}{"UserId": userId, "Following": true, "TeamId": teamId, "Limit": limit, "Offset": offset}); err != nil {
+		return nil, model.NewAppError("SqlThreadStore.GetFollowedForUser", "store.sql_thread.get_followed_for_user.app_error", nil, "user_id="+userId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return memberships, nil
+}
+
+// IncrementUnreads counts a new reply to a thread, sent by senderId, as unread for the other users
+// following it, and as an unread mention for those of them it mentions.
+func (s SqlThreadStore) IncrementUnreads(postId, senderId string, mentionedUserIds []string, lastUpdated int64) *model.AppError {
+	if _, err := s.GetMaster().Exec(
+		`UPDATE
+			ThreadMemberships
+		SET
+			UnreadReplies = UnreadReplies + 1,
+			LastUpdated = :LastUpdated
+		WHERE
+			PostId = :PostId
+			AND UserId != :SenderId
+			AND Following = :Following`, map[string]interface{}{"LastUpdated": lastUpdated, "PostId": postId, "SenderId": senderId, "Following": true}); err != nil {
+		return model.NewAppError("SqlThreadStore.IncrementUnreads", "store.sql_thread.increment_unreads.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	if len(mentionedUserIds) == 0 {
+		return nil
+	}
+
+	keys, params := MapStringsToQueryParams(mentionedUserIds, "UserId")
+	params["PostId"] = postId
+	params["SenderId"] = senderId
+	params["Following"] = true
+
+	if _, err := s.GetMaster().Exec(
+		`UPDATE
+			ThreadMemberships
+		SET
+			UnreadMentions = UnreadMentions + 1
+		WHERE
+			PostId = :PostId
+			AND UserId != :SenderId
+			AND Following = :Following
+			AND UserId IN `+keys, params); err != nil {
+		return model.NewAppError("SqlThreadStore.IncrementUnreads", "store.sql_thread.increment_unreads.app_error", nil, "post_id="+postId+", "+err.Error(), http.StatusInternalServerError)
+	}
+
+	return nil
+}
+
+// GetReplyCounts returns the number of replies, not counting deleted ones, of each of the given
+// root posts that has any.
+func (s SqlThreadStore) GetReplyCounts(postIds []string) (map[string]int64, *model.AppError) {
+	replyCounts := make(map[string]int64, len(postIds))
+	if len(postIds) == 0 {
+		return replyCounts, nil
+	}
+
+	keys, params := MapStringsToQueryParams(postIds, "PostId")
+
+	var rows []threadReplyCount
+	if _, err := s.GetReplica().Select(&rows, "SELECT RootId, COUNT(*) AS ReplyCount FROM Posts WHERE RootId IN "+keys+" AND DeleteAt = 0 GROUP BY RootId", params); err != nil {
+		return nil, model.NewAppError("SqlThreadStore.GetReplyCounts", "store.sql_thread.get_reply_counts.app_error", nil, err.Error(), http.StatusInternalServerError)
+	}
+
+	for _, row := range rows {
+		replyCounts[row.RootId] = row.ReplyCount
+	}
+
+	return replyCounts, nil
+}
@@ -38,3 +38,21 @@ func finalizeTransaction(transaction *gorp.Transaction) {
 		mlog.Error("Failed to rollback transaction", mlog.Err(err))
 	}
 }
+
+// upsert runs update and, if it changed no row, insert. An insert that violates one of the
+// given unique constraints means the row exists after all, either because another session
+// inserted it in the meantime or because MySQL doesn't count the rows an update leaves
+// unchanged, so the update is run once more.
+func upsert(update func() (int64, error), insert func() error, constraintNames []string) error {
+	count, err := update()
+	if err != nil || count > 0 {
+		return err
+	}
+
+	if err := insert(); err == nil || !IsUniqueConstraintError(err, constraintNames) {
+		return err
+	}
+
+	_, err = update()
+	return err
+}
@@ -1,7 +1,11 @@
 package sqlstore
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMapStringsToQueryParams(t *testing.T) {
@@ -30,3 +34,76 @@ func TestMapStringsToQueryParams(t *testing.T) {
 		}
 	})
 }
+
+func TestUpsert(t *testing.T) {
+	constraintNames := []string{"PRIMARY", "drafts_pkey"}
+
+	t.Run("updated", func(t *testing.T) {
+		updates, inserts := 0, 0
+		err := upsert(func() (int64, error) {
+			updates++
+			return 1, nil
+		}, func() error {
+			inserts++
+			return nil
+		}, constraintNames)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, updates)
+		assert.Equal(t, 0, inserts)
+	})
+
+	t.Run("inserted", func(t *testing.T) {
+		updates, inserts := 0, 0
+		err := upsert(func() (int64, error) {
+			updates++
+			return 0, nil
+		}, func() error {
+			inserts++
+			return nil
+		}, constraintNames)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, updates)
+		assert.Equal(t, 1, inserts)
+	})
+
+	t.Run("inserted concurrently", func(t *testing.T) {
+		updates := 0
+		err := upsert(func() (int64, error) {
+			updates++
+			return 0, nil
+		}, func() error {
+			return &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint \"drafts_pkey\""}
+		}, constraintNames)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, updates)
+	})
+
+	t.Run("insert failed", func(t *testing.T) {
+		updates := 0
+		err := upsert(func() (int64, error) {
+			updates++
+			return 0, nil
+		}, func() error {
+			return errors.New("connection lost")
+		}, constraintNames)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 1, updates)
+	})
+
+	t.Run("update failed", func(t *testing.T) {
+		inserts := 0
+		err := upsert(func() (int64, error) {
+			return 0, errors.New("connection lost")
+		}, func() error {
+			inserts++
+			return nil
+		}, constraintNames)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, inserts)
+	})
+}
@@ -70,6 +70,11 @@ type Store interface {
 	Group() GroupStore
 	UserTermsOfService() UserTermsOfServiceStore
 	LinkMetadata() LinkMetadataStore
+	DataRetentionPolicy() DataRetentionPolicyStore
+	ScheduledPost() ScheduledPostStore
+	Draft() DraftStore
+	Thread() ThreadStore
+	PostReminder() PostReminderStore
 	MarkSystemRanUnitTests()
 	Close()
 	LockToMaster()
@@ -350,7 +355,7 @@ type ComplianceStore interface {
 	Get(id string) (*model.Compliance, *model.AppError)
 	GetAll(offset, limit int) (model.Compliances, *model.AppError)
 	ComplianceExport(compliance *model.Compliance) ([]*model.CompliancePost, *model.AppError)
-	MessageExport(after int64, limit int) ([]*model.MessageExport, *model.AppError)
+	MessageExport(after int64, afterPostId string, limit int) ([]*model.MessageExport, *model.AppError)
 }
 
 type OAuthStore interface {
@@ -531,12 +536,16 @@ type UserAccessTokenStore interface {
 
 type PluginStore interface {
 	SaveOrUpdate(keyVal *model.PluginKeyValue) StoreChannel
+	SaveOrUpdateMany(keyVals []*model.PluginKeyValue) *model.AppError
 	CompareAndSet(keyVal *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError)
+	CompareAndDelete(keyVal *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError)
 	Get(pluginId, key string) StoreChannel
 	Delete(pluginId, key string) StoreChannel
+	DeleteMany(pluginId string, keys []string) *model.AppError
 	DeleteAllForPlugin(PluginId string) StoreChannel
 	DeleteAllExpired() StoreChannel
 	List(pluginId string, page, perPage int) StoreChannel
+	ListWithPrefix(pluginId, prefix string, offset, limit int) ([]string, *model.AppError)
 }
 
 type RoleStore interface {
@@ -609,3 +618,50 @@ type LinkMetadataStore interface {
 	Save(linkMetadata *model.LinkMetadata) StoreChannel
 	Get(url string, timestamp int64) StoreChannel
 }
+
+type DataRetentionPolicyStore interface {
+	SaveTeamPolicy(policy *model.TeamRetentionPolicy) (*model.TeamRetentionPolicy, *model.AppError)
+	GetTeamPolicy(teamId string) (*model.TeamRetentionPolicy, *model.AppError)
+	GetTeamPolicies(offset, limit int) ([]*model.TeamRetentionPolicy, *model.AppError)
+	DeleteTeamPolicy(teamId string) *model.AppError
+	SaveChannelPolicy(policy *model.ChannelRetentionPolicy) (*model.ChannelRetentionPolicy, *model.AppError)
+	GetChannelPolicy(channelId string) (*model.ChannelRetentionPolicy, *model.AppError)
+	GetChannelPolicies(offset, limit int) ([]*model.ChannelRetentionPolicy, *model.AppError)
+	DeleteChannelPolicy(channelId string) *model.AppError
+	HasPolicies() (bool, *model.AppError)
+	PermanentDeletePostsBatch(now int64, globalRetentionDays int, limit int64) ([]string, *model.AppError)
+	GetFileInfosBatchForDeletion(now int64, globalRetentionDays int, limit int64) ([]*model.FileInfo, *model.AppError)
+}
+
+type ScheduledPostStore interface {
+	Save(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError)
+	Get(id string) (*model.ScheduledPost, *model.AppError)
+	Update(scheduledPost *model.ScheduledPost) (*model.ScheduledPost, *model.AppError)
+	Delete(id string) (bool, *model.AppError)
+	GetForUser(userId string) ([]*model.ScheduledPost, *model.AppError)
+	GetDue(now int64, limit int) ([]*model.ScheduledPost, *model.AppError)
+}
+
+type DraftStore interface {
+	Save(draft *model.Draft) (*model.Draft, *model.AppError)
+	Get(userId, channelId, rootId string) (*model.Draft, *model.AppError)
+	GetForUser(userId, teamId string) ([]*model.Draft, *model.AppError)
+	Delete(userId, channelId, rootId string) (bool, *model.AppError)
+}
+
+type ThreadStore interface {
+	SaveMembership(membership *model.ThreadMembership) (*model.ThreadMembership, *model.AppError)
+	GetMembership(postId, userId string) (*model.ThreadMembership, *model.AppError)
+	GetMembershipsForThread(postId string) ([]*model.ThreadMembership, *model.AppError)
+	GetFollowedForUser(userId, teamId string, offset, limit int) ([]*model.ThreadMembership, *model.AppError)
+	GetReplyCounts(postIds []string) (map[string]int64, *model.AppError)
+	IncrementUnreads(postId, senderId string, mentionedUserIds []string, lastUpdated int64) *model.AppError
+}
+
+type PostReminderStore interface {
+	Save(reminder *model.PostReminder) (*model.PostReminder, *model.AppError)
+	Get(postId, userId string) (*model.PostReminder, *model.AppError)
+	Delete(postId, userId string) (bool, *model.AppError)
+	GetForUser(userId string) ([]*model.PostReminder, *model.AppError)
+	GetDue(now int64, limit int) ([]*model.PostReminder, *model.AppError)
+}
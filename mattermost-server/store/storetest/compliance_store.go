@@ -21,6 +21,7 @@ func TestComplianceStore(t *testing.T, ss store.Store) {
 	t.Run("MessageExportPrivateChannel", func(t *testing.T) { testMessageExportPrivateChannel(t, ss) })
 	t.Run("MessageExportDirectMessageChannel", func(t *testing.T) { testMessageExportDirectMessageChannel(t, ss) })
 	t.Run("MessageExportGroupMessageChannel", func(t *testing.T) { testMessageExportGroupMessageChannel(t, ss) })
+	t.Run("MessageExportSameCreateAt", func(t *testing.T) { testMessageExportSameCreateAt(t, ss) })
 }
 
 func testComplianceStore(t *testing.T, ss store.Store) {
@@ -242,7 +243,7 @@ func testComplianceExportDirectMessages(t *testing.T, ss store.Store) {
 func testMessageExportPublicChannel(t *testing.T, ss store.Store) {
 	// get the starting number of message export entries
 	startTime := model.GetMillis()
-	messages, err := ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err := ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	numMessageExports := len(messages)
 
@@ -305,7 +306,7 @@ func testMessageExportPublicChannel(t *testing.T, ss store.Store) {
 
 	// fetch the message exports for both posts that user1 sent
 	messageExportMap := map[string]model.MessageExport{}
-	messages, err = ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err = ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	assert.Equal(t, numMessageExports+2, len(messages))
 
@@ -337,7 +338,7 @@ func testMessageExportPublicChannel(t *testing.T, ss store.Store) {
 func testMessageExportPrivateChannel(t *testing.T, ss store.Store) {
 	// get the starting number of message export entries
 	startTime := model.GetMillis()
-	messages, err := ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err := ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	numMessageExports := len(messages)
 
@@ -400,7 +401,7 @@ func testMessageExportPrivateChannel(t *testing.T, ss store.Store) {
 
 	// fetch the message exports for both posts that user1 sent
 	messageExportMap := map[string]model.MessageExport{}
-	messages, err = ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err = ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	assert.Equal(t, numMessageExports+2, len(messages))
 
@@ -434,7 +435,7 @@ func testMessageExportPrivateChannel(t *testing.T, ss store.Store) {
 func testMessageExportDirectMessageChannel(t *testing.T, ss store.Store) {
 	// get the starting number of message export entries
 	startTime := model.GetMillis()
-	messages, err := ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err := ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	numMessageExports := len(messages)
 
@@ -484,7 +485,7 @@ func testMessageExportDirectMessageChannel(t *testing.T, ss store.Store) {
 
 	// fetch the message export for the post that user1 sent
 	messageExportMap := map[string]model.MessageExport{}
-	messages, err = ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err = ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 
 	assert.Equal(t, numMessageExports+1, len(messages))
@@ -508,7 +509,7 @@ func testMessageExportDirectMessageChannel(t *testing.T, ss store.Store) {
 func testMessageExportGroupMessageChannel(t *testing.T, ss store.Store) {
 	// get the starting number of message export entries
 	startTime := model.GetMillis()
-	messages, err := ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err := ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	numMessageExports := len(messages)
 
@@ -572,7 +573,7 @@ func testMessageExportGroupMessageChannel(t *testing.T, ss store.Store) {
 
 	// fetch the message export for the post that user1 sent
 	messageExportMap := map[string]model.MessageExport{}
-	messages, err = ss.Compliance().MessageExport(startTime-10, 10)
+	messages, err = ss.Compliance().MessageExport(startTime-10, "", 10)
 	require.Nil(t, err)
 	assert.Equal(t, numMessageExports+1, len(messages))
 
@@ -591,3 +592,43 @@ func testMessageExportGroupMessageChannel(t *testing.T, ss store.Store) {
 	assert.Equal(t, user1.Email, *messageExportMap[post.Id].UserEmail)
 	assert.Equal(t, user1.Username, *messageExportMap[post.Id].Username)
 }
+
+func testMessageExportSameCreateAt(t *testing.T, ss store.Store) {
+	// Keep the posts well in the past so that posts created by other tests are never in the way.
+	createAt := int64(1000)
+
+	channel := store.Must(ss.Channel().Save(&model.Channel{
+		TeamId: model.NewId(),
+		Name:   model.NewId(),
+		Type:   model.CHANNEL_OPEN,
+	}, -1)).(*model.Channel)
+
+	var postIds []string
+	for i := 0; i < 3; i++ {
+		post := store.Must(ss.Post().Save(&model.Post{
+			ChannelId: channel.Id,
+			UserId:    model.NewId(),
+			CreateAt:  createAt,
+			Message:   "zz" + model.NewId(),
+		})).(*model.Post)
+		postIds = append(postIds, post.Id)
+	}
+
+	// Paging through the posts one at a time mustn't skip the ones sharing the creation time of the last one.
+	var exported []string
+	cursor, cursorPostId := createAt-1, ""
+	for {
+		messages, err := ss.Compliance().MessageExport(cursor, cursorPostId, 1)
+		require.Nil(t, err)
+		if len(messages) == 0 || *messages[0].PostCreateAt > createAt {
+			break
+		}
+
+		if *messages[0].ChannelId == channel.Id {
+			exported = append(exported, *messages[0].PostId)
+		}
+		cursor, cursorPostId = *messages[0].PostCreateAt, *messages[0].PostId
+	}
+
+	assert.ElementsMatch(t, postIds, exported)
+}
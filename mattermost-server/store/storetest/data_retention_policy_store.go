@@ -0,0 +1,209 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const retentionDay = int64(24 * 60 * 60 * 1000)
+
+func TestDataRetentionPolicyStore(t *testing.T, ss store.Store) {
+	t.Run("TeamPolicies", func(t *testing.T) { testDataRetentionPolicyStoreTeamPolicies(t, ss) })
+	t.Run("ChannelPolicies", func(t *testing.T) { testDataRetentionPolicyStoreChannelPolicies(t, ss) })
+	t.Run("PermanentDeletePostsBatch", func(t *testing.T) { testDataRetentionPolicyStorePermanentDeletePostsBatch(t, ss) })
+	t.Run("GetFileInfosBatchForDeletion", func(t *testing.T) { testDataRetentionPolicyStoreGetFileInfosBatchForDeletion(t, ss) })
+}
+
+func testDataRetentionPolicyStoreTeamPolicies(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+
+	_, err := ss.DataRetentionPolicy().GetTeamPolicy(teamId)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, err = ss.DataRetentionPolicy().SaveTeamPolicy(&model.TeamRetentionPolicy{TeamId: teamId, MessageRetentionDays: -1})
+	require.NotNil(t, err)
+
+	policy, err := ss.DataRetentionPolicy().SaveTeamPolicy(&model.TeamRetentionPolicy{TeamId: teamId, MessageRetentionDays: 30, FileRetentionDays: 60})
+	require.Nil(t, err)
+	defer ss.DataRetentionPolicy().DeleteTeamPolicy(teamId)
+
+	received, err := ss.DataRetentionPolicy().GetTeamPolicy(teamId)
+	require.Nil(t, err)
+	assert.Equal(t, policy, received)
+
+	policy.MessageRetentionDays = 15
+	_, err = ss.DataRetentionPolicy().SaveTeamPolicy(policy)
+	require.Nil(t, err)
+
+	received, err = ss.DataRetentionPolicy().GetTeamPolicy(teamId)
+	require.Nil(t, err)
+	assert.Equal(t, 15, received.MessageRetentionDays)
+	assert.Equal(t, 60, received.FileRetentionDays)
+
+	policies, err := ss.DataRetentionPolicy().GetTeamPolicies(0, 1000)
+	require.Nil(t, err)
+	found := false
+	for _, p := range policies {
+		if p.TeamId == teamId {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	require.Nil(t, ss.DataRetentionPolicy().DeleteTeamPolicy(teamId))
+
+	_, err = ss.DataRetentionPolicy().GetTeamPolicy(teamId)
+	require.NotNil(t, err)
+}
+
+func testDataRetentionPolicyStoreChannelPolicies(t *testing.T, ss store.Store) {
+	channelId := model.NewId()
+
+	_, err := ss.DataRetentionPolicy().GetChannelPolicy(channelId)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	policy, err := ss.DataRetentionPolicy().SaveChannelPolicy(&model.ChannelRetentionPolicy{ChannelId: channelId, MessageRetentionDays: 7})
+	require.Nil(t, err)
+	defer ss.DataRetentionPolicy().DeleteChannelPolicy(channelId)
+
+	received, err := ss.DataRetentionPolicy().GetChannelPolicy(channelId)
+	require.Nil(t, err)
+	assert.Equal(t, policy, received)
+
+	policies, err := ss.DataRetentionPolicy().GetChannelPolicies(0, 1000)
+	require.Nil(t, err)
+	found := false
+	for _, p := range policies {
+		if p.ChannelId == channelId {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	require.Nil(t, ss.DataRetentionPolicy().DeleteChannelPolicy(channelId))
+
+	_, err = ss.DataRetentionPolicy().GetChannelPolicy(channelId)
+	require.NotNil(t, err)
+}
+
+func makeRetentionTestChannel(t *testing.T, ss store.Store, teamId string) *model.Channel {
+	result := <-ss.Channel().Save(&model.Channel{
+		TeamId:      teamId,
+		DisplayName: "Retention",
+		Name:        "zz" + model.NewId() + "b",
+		Type:        model.CHANNEL_OPEN,
+	}, -1)
+	require.Nil(t, result.Err)
+	return result.Data.(*model.Channel)
+}
+
+func makeRetentionTestPost(t *testing.T, ss store.Store, channelId string, createAt int64) *model.Post {
+	result := <-ss.Post().Save(&model.Post{
+		ChannelId: channelId,
+		UserId:    model.NewId(),
+		Message:   "zz" + model.NewId() + "b",
+		CreateAt:  createAt,
+	})
+	require.Nil(t, result.Err)
+	return result.Data.(*model.Post)
+}
+
+func testDataRetentionPolicyStorePermanentDeletePostsBatch(t *testing.T, ss store.Store) {
+	// Keep the clock well in the past so that posts created by other tests are never affected.
+	now := 100 * retentionDay
+
+	teamWithPolicy := model.NewId()
+	_, err := ss.DataRetentionPolicy().SaveTeamPolicy(&model.TeamRetentionPolicy{TeamId: teamWithPolicy, MessageRetentionDays: 10})
+	require.Nil(t, err)
+	defer ss.DataRetentionPolicy().DeleteTeamPolicy(teamWithPolicy)
+
+	teamChannel := makeRetentionTestChannel(t, ss, teamWithPolicy)
+	keepForeverChannel := makeRetentionTestChannel(t, ss, teamWithPolicy)
+	_, err = ss.DataRetentionPolicy().SaveChannelPolicy(&model.ChannelRetentionPolicy{ChannelId: keepForeverChannel.Id, MessageRetentionDays: 0})
+	require.Nil(t, err)
+	defer ss.DataRetentionPolicy().DeleteChannelPolicy(keepForeverChannel.Id)
+
+	shortChannel := makeRetentionTestChannel(t, ss, model.NewId())
+	_, err = ss.DataRetentionPolicy().SaveChannelPolicy(&model.ChannelRetentionPolicy{ChannelId: shortChannel.Id, MessageRetentionDays: 5})
+	require.Nil(t, err)
+	defer ss.DataRetentionPolicy().DeleteChannelPolicy(shortChannel.Id)
+
+	globalChannel := makeRetentionTestChannel(t, ss, model.NewId())
+
+	teamOld := makeRetentionTestPost(t, ss, teamChannel.Id, now-20*retentionDay)
+	teamNew := makeRetentionTestPost(t, ss, teamChannel.Id, now-retentionDay)
+	keepForeverOld := makeRetentionTestPost(t, ss, keepForeverChannel.Id, now-20*retentionDay)
+	shortOld := makeRetentionTestPost(t, ss, shortChannel.Id, now-6*retentionDay)
+	globalOld := makeRetentionTestPost(t, ss, globalChannel.Id, now-20*retentionDay)
+
+	hasPolicies, err := ss.DataRetentionPolicy().HasPolicies()
+	require.Nil(t, err)
+	assert.True(t, hasPolicies)
+
+	deleted, err := ss.DataRetentionPolicy().PermanentDeletePostsBatch(now, 0, 1000)
+	require.Nil(t, err)
+	assert.ElementsMatch(t, []string{teamOld.Id, shortOld.Id}, deleted)
+
+	_, err = ss.Post().Get(teamOld.Id)
+	assert.NotNil(t, err, "post older than the team policy should have been deleted")
+	_, err = ss.Post().Get(shortOld.Id)
+	assert.NotNil(t, err, "post older than the channel policy should have been deleted")
+	_, err = ss.Post().Get(teamNew.Id)
+	assert.Nil(t, err, "post newer than the team policy should have been kept")
+	_, err = ss.Post().Get(keepForeverOld.Id)
+	assert.Nil(t, err, "channel policy should override the team policy")
+	_, err = ss.Post().Get(globalOld.Id)
+	assert.Nil(t, err, "post without a policy should be kept while global deletion is disabled")
+
+	deleted, err = ss.DataRetentionPolicy().PermanentDeletePostsBatch(now, 30, 1000)
+	require.Nil(t, err)
+	assert.Empty(t, deleted)
+
+	deleted, err = ss.DataRetentionPolicy().PermanentDeletePostsBatch(now, 15, 1000)
+	require.Nil(t, err)
+	assert.Equal(t, []string{globalOld.Id}, deleted)
+
+	_, err = ss.Post().Get(globalOld.Id)
+	assert.NotNil(t, err, "post older than the global policy should have been deleted")
+	_, err = ss.Post().Get(keepForeverOld.Id)
+	assert.Nil(t, err)
+}
+
+func testDataRetentionPolicyStoreGetFileInfosBatchForDeletion(t *testing.T, ss store.Store) {
+	now := 100 * retentionDay
+
+	channel := makeRetentionTestChannel(t, ss, model.NewId())
+	_, err := ss.DataRetentionPolicy().SaveChannelPolicy(&model.ChannelRetentionPolicy{ChannelId: channel.Id, FileRetentionDays: 3})
+	require.Nil(t, err)
+	defer ss.DataRetentionPolicy().DeleteChannelPolicy(channel.Id)
+
+	post := makeRetentionTestPost(t, ss, channel.Id, now-10*retentionDay)
+
+	expired, err := ss.FileInfo().Save(&model.FileInfo{CreatorId: model.NewId(), PostId: post.Id, Path: "expired.txt", CreateAt: now - 4*retentionDay})
+	require.Nil(t, err)
+	defer ss.FileInfo().PermanentDelete(expired.Id)
+
+	recent, err := ss.FileInfo().Save(&model.FileInfo{CreatorId: model.NewId(), PostId: post.Id, Path: "recent.txt", CreateAt: now - retentionDay})
+	require.Nil(t, err)
+	defer ss.FileInfo().PermanentDelete(recent.Id)
+
+	infos, err := ss.DataRetentionPolicy().GetFileInfosBatchForDeletion(now, 0, 1000)
+	require.Nil(t, err)
+
+	ids := make([]string, 0, len(infos))
+	for _, info := range infos {
+		ids = append(ids, info.Id)
+	}
+	assert.Contains(t, ids, expired.Id)
+	assert.NotContains(t, ids, recent.Id)
+}
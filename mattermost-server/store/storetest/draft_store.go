@@ -0,0 +1,121 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraftStore(t *testing.T, ss store.Store) {
+	t.Run("SaveGetDelete", func(t *testing.T) { testDraftStoreSaveGetDelete(t, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testDraftStoreGetForUser(t, ss) })
+}
+
+func testDraftStoreSaveGetDelete(t *testing.T, ss store.Store) {
+	draft := &model.Draft{
+		UserId:    model.NewId(),
+		ChannelId: model.NewId(),
+		Message:   "hello",
+	}
+
+	_, err := ss.Draft().Get(draft.UserId, draft.ChannelId, "")
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	saved, err := ss.Draft().Save(draft)
+	require.Nil(t, err)
+	defer ss.Draft().Delete(draft.UserId, draft.ChannelId, "")
+
+	received, err := ss.Draft().Get(draft.UserId, draft.ChannelId, "")
+	require.Nil(t, err)
+	assert.Equal(t, "hello", received.Message)
+
+	// Replacing the draft keeps its creation time.
+	_, err = ss.Draft().Save(&model.Draft{UserId: draft.UserId, ChannelId: draft.ChannelId, Message: "updated", FileIds: []string{model.NewId()}})
+	require.Nil(t, err)
+
+	received, err = ss.Draft().Get(draft.UserId, draft.ChannelId, "")
+	require.Nil(t, err)
+	assert.Equal(t, "updated", received.Message)
+	assert.Len(t, received.FileIds, 1)
+	assert.Equal(t, saved.CreateAt, received.CreateAt)
+
+	// The draft of a thread is separate from the one of its channel.
+	rootId := model.NewId()
+	_, err = ss.Draft().Save(&model.Draft{UserId: draft.UserId, ChannelId: draft.ChannelId, RootId: rootId, Message: "reply"})
+	require.Nil(t, err)
+	defer ss.Draft().Delete(draft.UserId, draft.ChannelId, rootId)
+
+	received, err = ss.Draft().Get(draft.UserId, draft.ChannelId, rootId)
+	require.Nil(t, err)
+	assert.Equal(t, "reply", received.Message)
+
+	deleted, err := ss.Draft().Delete(draft.UserId, draft.ChannelId, "")
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = ss.Draft().Delete(draft.UserId, draft.ChannelId, "")
+	require.Nil(t, err)
+	assert.False(t, deleted)
+
+	_, err = ss.Draft().Get(draft.UserId, draft.ChannelId, rootId)
+	assert.Nil(t, err, "deleting the draft of a channel should keep the ones of its threads")
+}
+
+func testDraftStoreGetForUser(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	channel := &model.Channel{TeamId: teamId, DisplayName: "Display " + model.NewId(), Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+	channel = store.Must(ss.Channel().Save(channel, -1)).(*model.Channel)
+
+	archived := &model.Channel{TeamId: teamId, DisplayName: "Display " + model.NewId(), Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+	archived = store.Must(ss.Channel().Save(archived, -1)).(*model.Channel)
+
+	otherTeam := &model.Channel{TeamId: model.NewId(), DisplayName: "Display " + model.NewId(), Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+	otherTeam = store.Must(ss.Channel().Save(otherTeam, -1)).(*model.Channel)
+
+	direct, err := ss.Channel().CreateDirectChannel(userId, model.NewId())
+	require.Nil(t, err)
+
+	for _, channelId := range []string{channel.Id, archived.Id, otherTeam.Id, direct.Id} {
+		_, err = ss.Draft().Save(&model.Draft{UserId: userId, ChannelId: channelId, Message: "draft"})
+		require.Nil(t, err)
+		defer ss.Draft().Delete(userId, channelId, "")
+	}
+
+	// Saved last, so listed first.
+	time.Sleep(2 * time.Millisecond)
+	rootId := model.NewId()
+	_, err = ss.Draft().Save(&model.Draft{UserId: userId, ChannelId: channel.Id, RootId: rootId, Message: "reply"})
+	require.Nil(t, err)
+	defer ss.Draft().Delete(userId, channel.Id, rootId)
+
+	require.Nil(t, ss.Channel().Delete(archived.Id, model.GetMillis()))
+
+	drafts, err := ss.Draft().GetForUser(userId, teamId)
+	require.Nil(t, err)
+	require.Len(t, drafts, 3)
+	assert.Equal(t, "reply", drafts[0].Message)
+
+	channelIds := []string{}
+	for _, draft := range drafts {
+		channelIds = append(channelIds, draft.ChannelId)
+	}
+	assert.Contains(t, channelIds, channel.Id)
+	assert.Contains(t, channelIds, direct.Id)
+	assert.NotContains(t, channelIds, archived.Id)
+	assert.NotContains(t, channelIds, otherTeam.Id)
+
+	drafts, err = ss.Draft().GetForUser(model.NewId(), teamId)
+	require.Nil(t, err)
+	assert.Empty(t, drafts)
+}
@@ -87,13 +87,13 @@ func (_m *ComplianceStore) GetAll(offset int, limit int) (model.Compliances, *mo
 	return r0, r1
 }
 
-// MessageExport provides a mock function with given fields: after, limit
-func (_m *ComplianceStore) MessageExport(after int64, limit int) ([]*model.MessageExport, *model.AppError) {
-	ret := _m.Called(after, limit)
+// MessageExport provides a mock function with given fields: after, afterPostId, limit
+func (_m *ComplianceStore) MessageExport(after int64, afterPostId string, limit int) ([]*model.MessageExport, *model.AppError) {
+	ret := _m.Called(after, afterPostId, limit)
 
 	var r0 []*model.MessageExport
-	if rf, ok := ret.Get(0).(func(int64, int) []*model.MessageExport); ok {
-		r0 = rf(after, limit)
+	if rf, ok := ret.Get(0).(func(int64, string, int) []*model.MessageExport); ok {
+		r0 = rf(after, afterPostId, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*model.MessageExport)
@@ -101,8 +101,8 @@ func (_m *ComplianceStore) MessageExport(after int64, limit int) ([]*model.Messa
 	}
 
 	var r1 *model.AppError
-	if rf, ok := ret.Get(1).(func(int64, int) *model.AppError); ok {
-		r1 = rf(after, limit)
+	if rf, ok := ret.Get(1).(func(int64, string, int) *model.AppError); ok {
+		r1 = rf(after, afterPostId, limit)
 	} else {
 		if ret.Get(1) != nil {
 			r1 = ret.Get(1).(*model.AppError)
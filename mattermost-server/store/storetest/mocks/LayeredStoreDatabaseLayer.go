@@ -239,6 +239,38 @@ func (_m *LayeredStoreDatabaseLayer) CountGroupsByTeam(ctx context.Context, team
 	return r0
 }
 
+// DataRetentionPolicy provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) DataRetentionPolicy() store.DataRetentionPolicyStore {
+	ret := _m.Called()
+
+	var r0 store.DataRetentionPolicyStore
+	if rf, ok := ret.Get(0).(func() store.DataRetentionPolicyStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.DataRetentionPolicyStore)
+		}
+	}
+
+	return r0
+}
+
+// Draft provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) Draft() store.DraftStore {
+	ret := _m.Called()
+
+	var r0 store.DraftStore
+	if rf, ok := ret.Get(0).(func() store.DraftStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.DraftStore)
+		}
+	}
+
+	return r0
+}
+
 // DropAllTables provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) DropAllTables() {
 	_m.Called()
@@ -851,6 +883,22 @@ func (_m *LayeredStoreDatabaseLayer) Post() store.PostStore {
 	return r0
 }
 
+// PostReminder provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) PostReminder() store.PostReminderStore {
+	ret := _m.Called()
+
+	var r0 store.PostReminderStore
+	if rf, ok := ret.Get(0).(func() store.PostReminderStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PostReminderStore)
+		}
+	}
+
+	return r0
+}
+
 // Preference provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Preference() store.PreferenceStore {
 	ret := _m.Called()
@@ -1295,6 +1343,22 @@ func (_m *LayeredStoreDatabaseLayer) RoleSave(ctx context.Context, role *model.R
 	return r0, r1
 }
 
+// ScheduledPost provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) ScheduledPost() store.ScheduledPostStore {
+	ret := _m.Called()
+
+	var r0 store.ScheduledPostStore
+	if rf, ok := ret.Get(0).(func() store.ScheduledPostStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.ScheduledPostStore)
+		}
+	}
+
+	return r0
+}
+
 // Scheme provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Scheme() store.SchemeStore {
 	ret := _m.Called()
@@ -1580,6 +1644,22 @@ func (_m *LayeredStoreDatabaseLayer) TermsOfService() store.TermsOfServiceStore
 	return r0
 }
 
+// Thread provides a mock function with given fields:
+func (_m *LayeredStoreDatabaseLayer) Thread() store.ThreadStore {
+	ret := _m.Called()
+
+	var r0 store.ThreadStore
+	if rf, ok := ret.Get(0).(func() store.ThreadStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.ThreadStore)
+		}
+	}
+
+	return r0
+}
+
 // Token provides a mock function with given fields:
 func (_m *LayeredStoreDatabaseLayer) Token() store.TokenStore {
 	ret := _m.Called()
@@ -13,6 +13,29 @@ type PluginStore struct {
 	mock.Mock
 }
 
+// CompareAndDelete provides a mock function with given fields: keyVal, oldValue
+func (_m *PluginStore) CompareAndDelete(keyVal *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
+	ret := _m.Called(keyVal, oldValue)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(*model.PluginKeyValue, []byte) bool); ok {
+		r0 = rf(keyVal, oldValue)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(*model.PluginKeyValue, []byte) *model.AppError); ok {
+		r1 = rf(keyVal, oldValue)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // CompareAndSet provides a mock function with given fields: keyVal, oldValue
 func (_m *PluginStore) CompareAndSet(keyVal *model.PluginKeyValue, oldValue []byte) (bool, *model.AppError) {
 	ret := _m.Called(keyVal, oldValue)
@@ -84,6 +107,22 @@ func (_m *PluginStore) DeleteAllForPlugin(PluginId string) store.StoreChannel {
 	return r0
 }
 
+// DeleteMany provides a mock function with given fields: pluginId, keys
+func (_m *PluginStore) DeleteMany(pluginId string, keys []string) *model.AppError {
+	ret := _m.Called(pluginId, keys)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func(string, []string) *model.AppError); ok {
+		r0 = rf(pluginId, keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
+
 // Get provides a mock function with given fields: pluginId, key
 func (_m *PluginStore) Get(pluginId string, key string) store.StoreChannel {
 	ret := _m.Called(pluginId, key)
@@ -116,6 +155,31 @@ func (_m *PluginStore) List(pluginId string, page int, perPage int) store.StoreC
 	return r0
 }
 
+// ListWithPrefix provides a mock function with given fields: pluginId, prefix, offset, limit
+func (_m *PluginStore) ListWithPrefix(pluginId string, prefix string, offset int, limit int) ([]string, *model.AppError) {
+	ret := _m.Called(pluginId, prefix, offset, limit)
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func(string, string, int, int) []string); ok {
+		r0 = rf(pluginId, prefix, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	var r1 *model.AppError
+	if rf, ok := ret.Get(1).(func(string, string, int, int) *model.AppError); ok {
+		r1 = rf(pluginId, prefix, offset, limit)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*model.AppError)
+		}
+	}
+
+	return r0, r1
+}
+
 // SaveOrUpdate provides a mock function with given fields: keyVal
 func (_m *PluginStore) SaveOrUpdate(keyVal *model.PluginKeyValue) store.StoreChannel {
 	ret := _m.Called(keyVal)
@@ -131,3 +195,19 @@ func (_m *PluginStore) SaveOrUpdate(keyVal *model.PluginKeyValue) store.StoreCha
 
 	return r0
 }
+
+// SaveOrUpdateMany provides a mock function with given fields: keyVals
+func (_m *PluginStore) SaveOrUpdateMany(keyVals []*model.PluginKeyValue) *model.AppError {
+	ret := _m.Called(keyVals)
+
+	var r0 *model.AppError
+	if rf, ok := ret.Get(0).(func([]*model.PluginKeyValue) *model.AppError); ok {
+		r0 = rf(keyVals)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.AppError)
+		}
+	}
+
+	return r0
+}
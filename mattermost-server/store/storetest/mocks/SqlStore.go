@@ -244,6 +244,22 @@ func (_m *SqlStore) CreateUniqueIndexIfNotExists(indexName string, tableName str
 	return r0
 }
 
+// DataRetentionPolicy provides a mock function with given fields:
+func (_m *SqlStore) DataRetentionPolicy() store.DataRetentionPolicyStore {
+	ret := _m.Called()
+
+	var r0 store.DataRetentionPolicyStore
+	if rf, ok := ret.Get(0).(func() store.DataRetentionPolicyStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.DataRetentionPolicyStore)
+		}
+	}
+
+	return r0
+}
+
 // DoesColumnExist provides a mock function with given fields: tableName, columName
 func (_m *SqlStore) DoesColumnExist(tableName string, columName string) bool {
 	ret := _m.Called(tableName, columName)
@@ -286,6 +302,22 @@ func (_m *SqlStore) DoesTriggerExist(triggerName string) bool {
 	return r0
 }
 
+// Draft provides a mock function with given fields:
+func (_m *SqlStore) Draft() store.DraftStore {
+	ret := _m.Called()
+
+	var r0 store.DraftStore
+	if rf, ok := ret.Get(0).(func() store.DraftStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.DraftStore)
+		}
+	}
+
+	return r0
+}
+
 // DriverName provides a mock function with given fields:
 func (_m *SqlStore) DriverName() string {
 	ret := _m.Called()
@@ -530,6 +562,22 @@ func (_m *SqlStore) Post() store.PostStore {
 	return r0
 }
 
+// PostReminder provides a mock function with given fields:
+func (_m *SqlStore) PostReminder() store.PostReminderStore {
+	ret := _m.Called()
+
+	var r0 store.PostReminderStore
+	if rf, ok := ret.Get(0).(func() store.PostReminderStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.PostReminderStore)
+		}
+	}
+
+	return r0
+}
+
 // Preference provides a mock function with given fields:
 func (_m *SqlStore) Preference() store.PreferenceStore {
 	ret := _m.Called()
@@ -634,6 +682,22 @@ func (_m *SqlStore) Role() store.RoleStore {
 	return r0
 }
 
+// ScheduledPost provides a mock function with given fields:
+func (_m *SqlStore) ScheduledPost() store.ScheduledPostStore {
+	ret := _m.Called()
+
+	var r0 store.ScheduledPostStore
+	if rf, ok := ret.Get(0).(func() store.ScheduledPostStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.ScheduledPostStore)
+		}
+	}
+
+	return r0
+}
+
 // Scheme provides a mock function with given fields:
 func (_m *SqlStore) Scheme() store.SchemeStore {
 	ret := _m.Called()
@@ -730,6 +794,22 @@ func (_m *SqlStore) TermsOfService() store.TermsOfServiceStore {
 	return r0
 }
 
+// Thread provides a mock function with given fields:
+func (_m *SqlStore) Thread() store.ThreadStore {
+	ret := _m.Called()
+
+	var r0 store.ThreadStore
+	if rf, ok := ret.Get(0).(func() store.ThreadStore); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(store.ThreadStore)
+		}
+	}
+
+	return r0
+}
+
 // Token provides a mock function with given fields:
 func (_m *SqlStore) Token() store.TokenStore {
 	ret := _m.Called()
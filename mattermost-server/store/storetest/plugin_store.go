@@ -9,6 +9,7 @@ import (
 	"github.com/mattermost/mattermost-server/model"
 	"github.com/mattermost/mattermost-server/store"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPluginStore(t *testing.T, ss store.Store) {
@@ -17,6 +18,10 @@ func TestPluginStore(t *testing.T, ss store.Store) {
 	t.Run("PluginDelete", func(t *testing.T) { testPluginDelete(t, ss) })
 	t.Run("PluginDeleteAll", func(t *testing.T) { testPluginDeleteAll(t, ss) })
 	t.Run("PluginDeleteExpired", func(t *testing.T) { testPluginDeleteExpired(t, ss) })
+	t.Run("PluginSaveOrUpdateMany", func(t *testing.T) { testPluginSaveOrUpdateMany(t, ss) })
+	t.Run("PluginCompareAndDelete", func(t *testing.T) { testPluginCompareAndDelete(t, ss) })
+	t.Run("PluginDeleteMany", func(t *testing.T) { testPluginDeleteMany(t, ss) })
+	t.Run("PluginListWithPrefix", func(t *testing.T) { testPluginListWithPrefix(t, ss) })
 }
 
 func testPluginSaveGet(t *testing.T, ss store.Store) {
@@ -182,3 +187,101 @@ func testPluginDeleteExpired(t *testing.T, ss store.Store) {
 		assert.Equal(t, kv2.ExpireAt, received.ExpireAt)
 	}
 }
+
+func testPluginSaveOrUpdateMany(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		<-ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	store.Must(ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "a", Value: []byte("old")}))
+
+	require.Nil(t, ss.Plugin().SaveOrUpdateMany([]*model.PluginKeyValue{
+		{PluginId: pluginId, Key: "b", Value: []byte("2")},
+		{PluginId: pluginId, Key: "a", Value: []byte("1")},
+	}))
+
+	for key, value := range map[string]string{"a": "1", "b": "2"} {
+		result := <-ss.Plugin().Get(pluginId, key)
+		require.Nil(t, result.Err)
+		assert.Equal(t, value, string(result.Data.(*model.PluginKeyValue).Value))
+	}
+
+	t.Run("nothing is saved if a pair is invalid", func(t *testing.T) {
+		assert.NotNil(t, ss.Plugin().SaveOrUpdateMany([]*model.PluginKeyValue{
+			{PluginId: pluginId, Key: "c", Value: []byte("3")},
+			{PluginId: pluginId, Key: "", Value: []byte("invalid")},
+		}))
+
+		result := <-ss.Plugin().Get(pluginId, "c")
+		assert.NotNil(t, result.Err)
+	})
+}
+
+func testPluginCompareAndDelete(t *testing.T, ss store.Store) {
+	kv := store.Must(ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{
+		PluginId: model.NewId(),
+		Key:      model.NewId(),
+		Value:    []byte("value"),
+	})).(*model.PluginKeyValue)
+
+	defer func() {
+		<-ss.Plugin().Delete(kv.PluginId, kv.Key)
+	}()
+
+	deleted, err := ss.Plugin().CompareAndDelete(kv, []byte("other"))
+	require.Nil(t, err)
+	assert.False(t, deleted)
+
+	result := <-ss.Plugin().Get(kv.PluginId, kv.Key)
+	require.Nil(t, result.Err)
+
+	deleted, err = ss.Plugin().CompareAndDelete(kv, []byte("value"))
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	result = <-ss.Plugin().Get(kv.PluginId, kv.Key)
+	assert.NotNil(t, result.Err)
+}
+
+func testPluginDeleteMany(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		<-ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	for _, key := range []string{"a", "b", "c"} {
+		store.Must(ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: key, Value: []byte(key)}))
+	}
+
+	require.Nil(t, ss.Plugin().DeleteMany(pluginId, []string{"a", "c", "missing"}))
+	require.Nil(t, ss.Plugin().DeleteMany(pluginId, nil))
+
+	result := <-ss.Plugin().List(pluginId, 0, 10)
+	require.Nil(t, result.Err)
+	assert.Equal(t, []string{"b"}, result.Data.([]string))
+}
+
+func testPluginListWithPrefix(t *testing.T, ss store.Store) {
+	pluginId := model.NewId()
+	defer func() {
+		<-ss.Plugin().DeleteAllForPlugin(pluginId)
+	}()
+
+	for _, key := range []string{"user_1", "user_2", "user_3", "userx", "team_1"} {
+		store.Must(ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: key, Value: []byte(key)}))
+	}
+	store.Must(ss.Plugin().SaveOrUpdate(&model.PluginKeyValue{PluginId: pluginId, Key: "user_expired", Value: []byte("expired"), ExpireAt: model.GetMillis() - 1000}))
+
+	keys, err := ss.Plugin().ListWithPrefix(pluginId, "user_", 0, 10)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"user_1", "user_2", "user_3"}, keys)
+
+	keys, err = ss.Plugin().ListWithPrefix(pluginId, "user_", 1, 1)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"user_2"}, keys)
+
+	keys, err = ss.Plugin().ListWithPrefix(pluginId, "", 0, 10)
+	require.Nil(t, err)
+	assert.Len(t, keys, 5)
+}
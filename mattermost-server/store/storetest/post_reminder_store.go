@@ -0,0 +1,101 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostReminderStore(t *testing.T, ss store.Store) {
+	t.Run("SaveGetDelete", func(t *testing.T) { testPostReminderStoreSaveGetDelete(t, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testPostReminderStoreGetForUser(t, ss) })
+	t.Run("GetDue", func(t *testing.T) { testPostReminderStoreGetDue(t, ss) })
+}
+
+func testPostReminderStoreSaveGetDelete(t *testing.T, ss store.Store) {
+	reminder := &model.PostReminder{PostId: model.NewId(), UserId: model.NewId(), TargetTime: model.GetMillis() + 1000}
+
+	_, err := ss.PostReminder().Get(reminder.PostId, reminder.UserId)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, err = ss.PostReminder().Save(reminder)
+	require.Nil(t, err)
+
+	// Saving an unchanged reminder again is fine.
+	_, err = ss.PostReminder().Save(reminder)
+	require.Nil(t, err)
+
+	_, err = ss.PostReminder().Save(&model.PostReminder{PostId: reminder.PostId, UserId: reminder.UserId, TargetTime: 1234})
+	require.Nil(t, err)
+
+	received, err := ss.PostReminder().Get(reminder.PostId, reminder.UserId)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1234), received.TargetTime)
+
+	_, err = ss.PostReminder().Save(&model.PostReminder{PostId: reminder.PostId, UserId: reminder.UserId})
+	assert.NotNil(t, err)
+
+	deleted, err := ss.PostReminder().Delete(reminder.PostId, reminder.UserId)
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = ss.PostReminder().Delete(reminder.PostId, reminder.UserId)
+	require.Nil(t, err)
+	assert.False(t, deleted)
+}
+
+func testPostReminderStoreGetForUser(t *testing.T, ss store.Store) {
+	userId := model.NewId()
+
+	later, err := ss.PostReminder().Save(&model.PostReminder{PostId: model.NewId(), UserId: userId, TargetTime: 2000})
+	require.Nil(t, err)
+	defer ss.PostReminder().Delete(later.PostId, userId)
+
+	sooner, err := ss.PostReminder().Save(&model.PostReminder{PostId: model.NewId(), UserId: userId, TargetTime: 1000})
+	require.Nil(t, err)
+	defer ss.PostReminder().Delete(sooner.PostId, userId)
+
+	other, err := ss.PostReminder().Save(&model.PostReminder{PostId: sooner.PostId, UserId: model.NewId(), TargetTime: 1000})
+	require.Nil(t, err)
+	defer ss.PostReminder().Delete(other.PostId, other.UserId)
+
+	reminders, err := ss.PostReminder().GetForUser(userId)
+	require.Nil(t, err)
+	require.Len(t, reminders, 2)
+	assert.Equal(t, sooner.PostId, reminders[0].PostId)
+	assert.Equal(t, later.PostId, reminders[1].PostId)
+}
+
+func testPostReminderStoreGetDue(t *testing.T, ss store.Store) {
+	now := model.GetMillis()
+
+	due, err := ss.PostReminder().Save(&model.PostReminder{PostId: model.NewId(), UserId: model.NewId(), TargetTime: now - 1000})
+	require.Nil(t, err)
+	defer ss.PostReminder().Delete(due.PostId, due.UserId)
+
+	notDue, err := ss.PostReminder().Save(&model.PostReminder{PostId: model.NewId(), UserId: model.NewId(), TargetTime: now + 60*60*1000})
+	require.Nil(t, err)
+	defer ss.PostReminder().Delete(notDue.PostId, notDue.UserId)
+
+	reminders, err := ss.PostReminder().GetDue(now, 1000)
+	require.Nil(t, err)
+
+	postIds := []string{}
+	for _, reminder := range reminders {
+		postIds = append(postIds, reminder.PostId)
+	}
+	assert.Contains(t, postIds, due.PostId)
+	assert.NotContains(t, postIds, notDue.PostId)
+
+	reminders, err = ss.PostReminder().GetDue(now, 1)
+	require.Nil(t, err)
+	assert.Len(t, reminders, 1)
+}
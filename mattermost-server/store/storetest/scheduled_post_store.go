@@ -0,0 +1,144 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledPostStore(t *testing.T, ss store.Store) {
+	t.Run("SaveGetUpdateDelete", func(t *testing.T) { testScheduledPostStoreSaveGetUpdateDelete(t, ss) })
+	t.Run("GetForUser", func(t *testing.T) { testScheduledPostStoreGetForUser(t, ss) })
+	t.Run("GetDue", func(t *testing.T) { testScheduledPostStoreGetDue(t, ss) })
+}
+
+func testScheduledPostStoreSaveGetUpdateDelete(t *testing.T, ss store.Store) {
+	scheduledPost := &model.ScheduledPost{
+		UserId:      model.NewId(),
+		ChannelId:   model.NewId(),
+		Message:     "hello",
+		Props:       model.StringInterface{"key": "value"},
+		ScheduledAt: model.GetMillis() + 60000,
+	}
+
+	_, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: model.NewId(), ChannelId: model.NewId(), ScheduledAt: 1})
+	require.NotNil(t, err, "an empty message shouldn't be saved")
+
+	saved, err := ss.ScheduledPost().Save(scheduledPost)
+	require.Nil(t, err)
+	require.NotEmpty(t, saved.Id)
+	defer ss.ScheduledPost().Delete(saved.Id)
+
+	received, err := ss.ScheduledPost().Get(saved.Id)
+	require.Nil(t, err)
+	assert.Equal(t, saved.Message, received.Message)
+	assert.Equal(t, saved.ScheduledAt, received.ScheduledAt)
+	assert.Equal(t, "value", received.Props["key"])
+
+	received.Message = "updated"
+	received.ErrorCode = model.SCHEDULED_POST_ERROR_CHANNEL_ARCHIVED
+	_, err = ss.ScheduledPost().Update(received)
+	require.Nil(t, err)
+
+	received, err = ss.ScheduledPost().Get(saved.Id)
+	require.Nil(t, err)
+	assert.Equal(t, "updated", received.Message)
+	assert.Equal(t, model.SCHEDULED_POST_ERROR_CHANNEL_ARCHIVED, received.ErrorCode)
+
+	deleted, err := ss.ScheduledPost().Delete(saved.Id)
+	require.Nil(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = ss.ScheduledPost().Delete(saved.Id)
+	require.Nil(t, err)
+	assert.False(t, deleted, "a scheduled post should only be deleted once")
+
+	_, err = ss.ScheduledPost().Get(saved.Id)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, err = ss.ScheduledPost().Update(received)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+}
+
+func testScheduledPostStoreGetForUser(t *testing.T, ss store.Store) {
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	later, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "later", ScheduledAt: now + 120000})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(later.Id)
+
+	sooner, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "sooner", ScheduledAt: now + 60000})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(sooner.Id)
+
+	other, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: model.NewId(), ChannelId: model.NewId(), Message: "other", ScheduledAt: now + 60000})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(other.Id)
+
+	scheduledPosts, err := ss.ScheduledPost().GetForUser(userId)
+	require.Nil(t, err)
+	require.Len(t, scheduledPosts, 2)
+	assert.Equal(t, sooner.Id, scheduledPosts[0].Id)
+	assert.Equal(t, later.Id, scheduledPosts[1].Id)
+
+	scheduledPosts, err = ss.ScheduledPost().GetForUser(model.NewId())
+	require.Nil(t, err)
+	assert.Empty(t, scheduledPosts)
+}
+
+func testScheduledPostStoreGetDue(t *testing.T, ss store.Store) {
+	userId := model.NewId()
+	now := model.GetMillis()
+
+	due, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "due", ScheduledAt: now - 2000})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(due.Id)
+
+	alsoDue, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "also due", ScheduledAt: now - 1000})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(alsoDue.Id)
+
+	failed, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "failed", ScheduledAt: now - 3000, ErrorCode: model.SCHEDULED_POST_ERROR_NO_PERMISSION})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(failed.Id)
+
+	notDue, err := ss.ScheduledPost().Save(&model.ScheduledPost{UserId: userId, ChannelId: model.NewId(), Message: "not due", ScheduledAt: now + 60000})
+	require.Nil(t, err)
+	defer ss.ScheduledPost().Delete(notDue.Id)
+
+	isDue := func(scheduledPosts []*model.ScheduledPost) map[string]bool {
+		ids := map[string]bool{}
+		for _, scheduledPost := range scheduledPosts {
+			ids[scheduledPost.Id] = true
+		}
+		return ids
+	}
+
+	scheduledPosts, err := ss.ScheduledPost().GetDue(now, 1000)
+	require.Nil(t, err)
+	ids := isDue(scheduledPosts)
+	assert.True(t, ids[due.Id])
+	assert.True(t, ids[alsoDue.Id])
+	assert.False(t, ids[failed.Id], "failed posts shouldn't be due until edited")
+	assert.False(t, ids[notDue.Id])
+
+	scheduledPosts, err = ss.ScheduledPost().GetDue(now-1500, 1000)
+	require.Nil(t, err)
+	ids = isDue(scheduledPosts)
+	assert.True(t, ids[due.Id])
+	assert.False(t, ids[alsoDue.Id])
+
+	scheduledPosts, err = ss.ScheduledPost().GetDue(now, 1)
+	require.Nil(t, err)
+	assert.Len(t, scheduledPosts, 1)
+}
@@ -50,6 +50,11 @@ type Store struct {
 	GroupStore                mocks.GroupStore
 	UserTermsOfServiceStore   mocks.UserTermsOfServiceStore
 	LinkMetadataStore         mocks.LinkMetadataStore
+	DataRetentionPolicyStore  mocks.DataRetentionPolicyStore
+	ScheduledPostStore        mocks.ScheduledPostStore
+	DraftStore                mocks.DraftStore
+	ThreadStore               mocks.ThreadStore
+	PostReminderStore         mocks.PostReminderStore
 }
 
 func (s *Store) Team() store.TeamStore                             { return &s.TeamStore }
@@ -85,14 +90,25 @@ func (s *Store) ChannelMemberHistory() store.ChannelMemberHistoryStore {
 }
 func (s *Store) Group() store.GroupStore               { return &s.GroupStore }
 func (s *Store) LinkMetadata() store.LinkMetadataStore { return &s.LinkMetadataStore }
-func (s *Store) MarkSystemRanUnitTests()               { /* do nothing */ }
-func (s *Store) Close()                                { /* do nothing */ }
-func (s *Store) LockToMaster()                         { /* do nothing */ }
-func (s *Store) UnlockFromMaster()                     { /* do nothing */ }
-func (s *Store) DropAllTables()                        { /* do nothing */ }
-func (s *Store) TotalMasterDbConnections() int         { return 1 }
-func (s *Store) TotalReadDbConnections() int           { return 1 }
-func (s *Store) TotalSearchDbConnections() int         { return 1 }
+func (s *Store) DataRetentionPolicy() store.DataRetentionPolicyStore {
+	return &s.DataRetentionPolicyStore
+}
+func (s *Store) ScheduledPost() store.ScheduledPostStore {
+	return &s.ScheduledPostStore
+}
+func (s *Store) PostReminder() store.PostReminderStore {
+	return &s.PostReminderStore
+}
+func (s *Store) Draft() store.DraftStore       { return &s.DraftStore }
+func (s *Store) Thread() store.ThreadStore     { return &s.ThreadStore }
+func (s *Store) MarkSystemRanUnitTests()       { /* do nothing */ }
+func (s *Store) Close()                        { /* do nothing */ }
+func (s *Store) LockToMaster()                 { /* do nothing */ }
+func (s *Store) UnlockFromMaster()             { /* do nothing */ }
+func (s *Store) DropAllTables()                { /* do nothing */ }
+func (s *Store) TotalMasterDbConnections() int { return 1 }
+func (s *Store) TotalReadDbConnections() int   { return 1 }
+func (s *Store) TotalSearchDbConnections() int { return 1 }
 
 func (s *Store) AssertExpectations(t mock.TestingT) bool {
 	return mock.AssertExpectationsForObjects(t,
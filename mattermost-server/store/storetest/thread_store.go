@@ -0,0 +1,185 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package storetest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/model"
+	"github.com/mattermost/mattermost-server/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThreadStore(t *testing.T, ss store.Store) {
+	t.Run("SaveGetMembership", func(t *testing.T) { testThreadStoreSaveGetMembership(t, ss) })
+	t.Run("GetFollowedForUser", func(t *testing.T) { testThreadStoreGetFollowedForUser(t, ss) })
+	t.Run("GetReplyCounts", func(t *testing.T) { testThreadStoreGetReplyCounts(t, ss) })
+	t.Run("IncrementUnreads", func(t *testing.T) { testThreadStoreIncrementUnreads(t, ss) })
+}
+
+func testThreadStoreSaveGetMembership(t *testing.T, ss store.Store) {
+	membership := &model.ThreadMembership{
+		PostId:      model.NewId(),
+		UserId:      model.NewId(),
+		Following:   true,
+		LastUpdated: model.GetMillis(),
+	}
+
+	_, err := ss.Thread().GetMembership(membership.PostId, membership.UserId)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusNotFound, err.StatusCode)
+
+	_, err = ss.Thread().SaveMembership(membership)
+	require.Nil(t, err)
+
+	// Saving an unchanged membership again is fine.
+	_, err = ss.Thread().SaveMembership(membership)
+	require.Nil(t, err)
+
+	membership.UnreadReplies = 2
+	membership.UnreadMentions = 1
+	_, err = ss.Thread().SaveMembership(membership)
+	require.Nil(t, err)
+
+	received, err := ss.Thread().GetMembership(membership.PostId, membership.UserId)
+	require.Nil(t, err)
+	assert.Equal(t, membership, received)
+
+	other := &model.ThreadMembership{PostId: membership.PostId, UserId: model.NewId()}
+	_, err = ss.Thread().SaveMembership(other)
+	require.Nil(t, err)
+
+	memberships, err := ss.Thread().GetMembershipsForThread(membership.PostId)
+	require.Nil(t, err)
+	assert.Len(t, memberships, 2)
+
+	_, err = ss.Thread().SaveMembership(&model.ThreadMembership{PostId: "junk", UserId: model.NewId()})
+	assert.NotNil(t, err)
+}
+
+func testThreadStoreGetFollowedForUser(t *testing.T, ss store.Store) {
+	teamId := model.NewId()
+	userId := model.NewId()
+
+	channel := &model.Channel{TeamId: teamId, DisplayName: "Display " + model.NewId(), Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+	channel = store.Must(ss.Channel().Save(channel, -1)).(*model.Channel)
+
+	otherTeam := &model.Channel{TeamId: model.NewId(), DisplayName: "Display " + model.NewId(), Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+	otherTeam = store.Must(ss.Channel().Save(otherTeam, -1)).(*model.Channel)
+
+	left := &model.Channel{TeamId: teamId, DisplayName: "Display " + model.NewId(), Name: "zz" + model.NewId() + "b", Type: model.CHANNEL_OPEN}
+	left = store.Must(ss.Channel().Save(left, -1)).(*model.Channel)
+
+	for _, channelId := range []string{channel.Id, otherTeam.Id} {
+		store.Must(ss.Channel().SaveMember(&model.ChannelMember{ChannelId: channelId, UserId: userId, NotifyProps: model.GetDefaultChannelNotifyProps()}))
+	}
+
+	direct, err := ss.Channel().CreateDirectChannel(userId, model.NewId())
+	require.Nil(t, err)
+
+	savePost := func(channelId string) *model.Post {
+		return store.Must(ss.Post().Save(&model.Post{UserId: userId, ChannelId: channelId, Message: "root"})).(*model.Post)
+	}
+
+	followed := savePost(channel.Id)
+	inDirect := savePost(direct.Id)
+	unfollowed := savePost(channel.Id)
+	deleted := savePost(channel.Id)
+	elsewhere := savePost(otherTeam.Id)
+	notMember := savePost(left.Id)
+
+	now := model.GetMillis()
+	for i, post := range []*model.Post{followed, inDirect, unfollowed, deleted, elsewhere, notMember} {
+		_, err = ss.Thread().SaveMembership(&model.ThreadMembership{
+			PostId:      post.Id,
+			UserId:      userId,
+			Following:   post != unfollowed,
+			LastUpdated: now + int64(i),
+		})
+		require.Nil(t, err)
+	}
+
+	require.Nil(t, ss.Post().Delete(deleted.Id, model.GetMillis(), userId))
+
+	memberships, err := ss.Thread().GetFollowedForUser(userId, teamId, 0, 10)
+	require.Nil(t, err)
+	require.Len(t, memberships, 2)
+	assert.Equal(t, inDirect.Id, memberships[0].PostId)
+	assert.Equal(t, followed.Id, memberships[1].PostId)
+
+	memberships, err = ss.Thread().GetFollowedForUser(userId, teamId, 1, 10)
+	require.Nil(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, followed.Id, memberships[0].PostId)
+
+	memberships, err = ss.Thread().GetFollowedForUser(model.NewId(), teamId, 0, 10)
+	require.Nil(t, err)
+	assert.Empty(t, memberships)
+}
+
+func testThreadStoreGetReplyCounts(t *testing.T, ss store.Store) {
+	userId := model.NewId()
+	channelId := model.NewId()
+
+	root := store.Must(ss.Post().Save(&model.Post{UserId: userId, ChannelId: channelId, Message: "root"})).(*model.Post)
+	lonely := store.Must(ss.Post().Save(&model.Post{UserId: userId, ChannelId: channelId, Message: "root"})).(*model.Post)
+
+	for i := 0; i < 3; i++ {
+		store.Must(ss.Post().Save(&model.Post{UserId: userId, ChannelId: channelId, RootId: root.Id, ParentId: root.Id, Message: "reply"}))
+	}
+	reply := store.Must(ss.Post().Save(&model.Post{UserId: userId, ChannelId: channelId, RootId: root.Id, ParentId: root.Id, Message: "deleted reply"})).(*model.Post)
+	require.Nil(t, ss.Post().Delete(reply.Id, model.GetMillis(), userId))
+
+	replyCounts, err := ss.Thread().GetReplyCounts([]string{root.Id, lonely.Id})
+	require.Nil(t, err)
+	assert.Equal(t, map[string]int64{root.Id: 3}, replyCounts)
+
+	replyCounts, err = ss.Thread().GetReplyCounts(nil)
+	require.Nil(t, err)
+	assert.Empty(t, replyCounts)
+}
+
+func testThreadStoreIncrementUnreads(t *testing.T, ss store.Store) {
+	postId := model.NewId()
+	sender := &model.ThreadMembership{PostId: postId, UserId: model.NewId(), Following: true}
+	follower := &model.ThreadMembership{PostId: postId, UserId: model.NewId(), Following: true}
+	mentioned := &model.ThreadMembership{PostId: postId, UserId: model.NewId(), Following: true, UnreadReplies: 1}
+	unfollowed := &model.ThreadMembership{PostId: postId, UserId: model.NewId()}
+
+	for _, membership := range []*model.ThreadMembership{sender, follower, mentioned, unfollowed} {
+		_, err := ss.Thread().SaveMembership(membership)
+		require.Nil(t, err)
+	}
+
+	require.Nil(t, ss.Thread().IncrementUnreads(postId, sender.UserId, []string{mentioned.UserId, unfollowed.UserId, sender.UserId}, 1234))
+
+	memberships, err := ss.Thread().GetMembershipsForThread(postId)
+	require.Nil(t, err)
+	require.Len(t, memberships, 4)
+
+	for _, membership := range memberships {
+		switch membership.UserId {
+		case sender.UserId, unfollowed.UserId:
+			assert.Zero(t, membership.UnreadReplies)
+			assert.Zero(t, membership.UnreadMentions)
+			assert.Zero(t, membership.LastUpdated)
+		case follower.UserId:
+			assert.Equal(t, int64(1), membership.UnreadReplies)
+			assert.Zero(t, membership.UnreadMentions)
+			assert.Equal(t, int64(1234), membership.LastUpdated)
+		case mentioned.UserId:
+			assert.Equal(t, int64(2), membership.UnreadReplies)
+			assert.Equal(t, int64(1), membership.UnreadMentions)
+		}
+	}
+
+	require.Nil(t, ss.Thread().IncrementUnreads(postId, sender.UserId, nil, 5678))
+
+	received, err := ss.Thread().GetMembership(postId, follower.UserId)
+	require.Nil(t, err)
+	assert.Equal(t, int64(2), received.UnreadReplies)
+	assert.Equal(t, int64(5678), received.LastUpdated)
+}
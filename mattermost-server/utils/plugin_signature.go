@@ -0,0 +1,114 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// ParsePluginPublicKey parses a PEM encoded RSA or ECDSA public key trusted to sign plugins.
+func ParsePluginPublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+
+	var publicKey crypto.PublicKey
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse public key")
+		}
+		publicKey = key
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse public key")
+		}
+		publicKey = key
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse certificate")
+		}
+		publicKey = certificate.PublicKey
+	default:
+		return nil, errors.Errorf("unsupported PEM block %v", block.Type)
+	}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, errors.New("only RSA and ECDSA public keys are supported")
+	}
+}
+
+// VerifyPluginSignature checks that the detached signature of the plugin bundle was made by one of
+// the given public keys. The signature is a SHA-256 RSA PKCS #1 v1.5 or ECDSA signature, as made by
+// `openssl dgst -sha256 -sign key.pem -out bundle.tar.gz.sig bundle.tar.gz`, optionally base64
+// encoded.
+func VerifyPluginSignature(publicKeys []crypto.PublicKey, bundle io.Reader, signature []byte) error {
+	if len(publicKeys) == 0 {
+		return errors.New("no public keys are trusted to sign plugins")
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, bundle); err != nil {
+		return errors.Wrap(err, "unable to read plugin bundle")
+	}
+	digest := hash.Sum(nil)
+
+	signatures := [][]byte{signature}
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(signature), nil))); err == nil {
+		signatures = append(signatures, decoded)
+	}
+
+	for _, publicKey := range publicKeys {
+		for _, signature := range signatures {
+			switch key := publicKey.(type) {
+			case *rsa.PublicKey:
+				if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil {
+					return nil
+				}
+			case *ecdsa.PublicKey:
+				if verifyECDSA(key, digest, signature) {
+					return nil
+				}
+			}
+		}
+	}
+
+	return errors.New("the signature doesn't match the plugin bundle or wasn't made by a trusted key")
+}
+
+// verifyECDSA checks an ASN.1 DER encoded ECDSA signature.
+func verifyECDSA(publicKey *ecdsa.PublicKey, digest, signature []byte) bool {
+	var parsed struct {
+		R, S *big.Int
+	}
+
+	if rest, err := asn1.Unmarshal(signature, &parsed); err != nil || len(rest) != 0 {
+		return false
+	}
+
+	if parsed.R == nil || parsed.S == nil || parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 {
+		return false
+	}
+
+	return ecdsa.Verify(publicKey, digest, parsed.R, parsed.S)
+}
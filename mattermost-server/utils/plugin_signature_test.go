@@ -0,0 +1,113 @@
+// Copyright (c) 2019-present Mattermost, Inc. All Rights Reserved.
+// See License.txt for license information.
+
+package utils
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePluginPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	pkix := func(key crypto.PublicKey) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		require.Nil(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	t.Run("rsa", func(t *testing.T) {
+		publicKey, err := ParsePluginPublicKey(pkix(&rsaKey.PublicKey))
+		require.Nil(t, err)
+		assert.Equal(t, &rsaKey.PublicKey, publicKey)
+	})
+
+	t.Run("pkcs1 rsa", func(t *testing.T) {
+		publicKey, err := ParsePluginPublicKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)}))
+		require.Nil(t, err)
+		assert.Equal(t, &rsaKey.PublicKey, publicKey)
+	})
+
+	t.Run("ecdsa", func(t *testing.T) {
+		publicKey, err := ParsePluginPublicKey(pkix(&ecdsaKey.PublicKey))
+		require.Nil(t, err)
+		assert.Equal(t, &ecdsaKey.PublicKey, publicKey)
+	})
+
+	t.Run("not pem", func(t *testing.T) {
+		_, err := ParsePluginPublicKey([]byte("not a key"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("private key", func(t *testing.T) {
+		_, err := ParsePluginPublicKey(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestVerifyPluginSignature(t *testing.T) {
+	bundle := []byte("plugin bundle")
+	digest := sha256.Sum256(bundle)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.Nil(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	ecdsaSignature, err := ecdsaKey.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.Nil(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+
+	publicKeys := []crypto.PublicKey{&otherKey.PublicKey, &rsaKey.PublicKey, &ecdsaKey.PublicKey}
+
+	t.Run("rsa", func(t *testing.T) {
+		assert.Nil(t, VerifyPluginSignature(publicKeys, bytes.NewReader(bundle), rsaSignature))
+	})
+
+	t.Run("ecdsa", func(t *testing.T) {
+		assert.Nil(t, VerifyPluginSignature(publicKeys, bytes.NewReader(bundle), ecdsaSignature))
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		encoded := base64.StdEncoding.EncodeToString(rsaSignature)
+		encoded = encoded[:64] + "\n" + encoded[64:] + "\n"
+		assert.Nil(t, VerifyPluginSignature(publicKeys, bytes.NewReader(bundle), []byte(encoded)))
+	})
+
+	t.Run("untrusted key", func(t *testing.T) {
+		assert.NotNil(t, VerifyPluginSignature([]crypto.PublicKey{&otherKey.PublicKey}, bytes.NewReader(bundle), rsaSignature))
+	})
+
+	t.Run("modified bundle", func(t *testing.T) {
+		assert.NotNil(t, VerifyPluginSignature(publicKeys, bytes.NewReader([]byte("modified bundle")), rsaSignature))
+		assert.NotNil(t, VerifyPluginSignature(publicKeys, bytes.NewReader([]byte("modified bundle")), ecdsaSignature))
+	})
+
+	t.Run("no keys", func(t *testing.T) {
+		assert.NotNil(t, VerifyPluginSignature(nil, bytes.NewReader(bundle), rsaSignature))
+	})
+
+	t.Run("garbage signature", func(t *testing.T) {
+		assert.NotNil(t, VerifyPluginSignature(publicKeys, bytes.NewReader(bundle), []byte("garbage")))
+	})
+}